@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"golang.binggl.net/monorepo/internal/combined"
+)
+
+var (
+	// Version exports the application version
+	Version = "1.0.0"
+	// Build provides information about the application build
+	Build = "localbuild"
+	// AppName specifies the application itself
+	AppName = "combined"
+)
+
+func main() {
+	if err := combined.Run(Version, Build, AppName); err != nil {
+		fmt.Fprintf(os.Stderr, "<< ERROR-RESULT >> '%s'\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,17 @@
+// The preview command creates the thumbnails of documents which were stored without a preview,
+// e.g. documents uploaded before the previews were introduced. It uses the configuration of mydms.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"golang.binggl.net/monorepo/internal/mydms"
+)
+
+func main() {
+	if err := mydms.BackfillPreviews(); err != nil {
+		fmt.Fprintf(os.Stderr, "<< ERROR-RESULT >> '%s'\n", err)
+		os.Exit(1)
+	}
+}
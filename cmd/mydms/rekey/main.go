@@ -0,0 +1,22 @@
+// The rekey command wraps the data keys of all stored documents using the current master key.
+// The former master key has to be configured as a previous key:
+//
+//	MY_FILESTORE__ENCRYPTION__MASTERKEY=<new key>
+//	MY_FILESTORE__ENCRYPTION__PREVIOUSKEYS=<old keys, comma-separated>
+//
+// Afterwards the previous key can be removed from the configuration.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"golang.binggl.net/monorepo/internal/mydms"
+)
+
+func main() {
+	if err := mydms.Rekey(); err != nil {
+		fmt.Fprintf(os.Stderr, "<< ERROR-RESULT >> '%s'\n", err)
+		os.Exit(1)
+	}
+}
@@ -1,6 +1,7 @@
 package bookmarks
 
 import (
+	"context"
 	"crypto/sha1"
 	"encoding/hex"
 	"fmt"
@@ -39,7 +40,7 @@ type Application struct {
 const ExistingFavicon = "existing://"
 
 // CreateBookmark stores a new bookmark
-func (s *Application) CreateBookmark(bm Bookmark, user security.User) (*Bookmark, error) {
+func (s *Application) CreateBookmark(ctx context.Context, bm Bookmark, user security.User) (*Bookmark, error) {
 	var (
 		savedItem store.Bookmark
 		t         store.NodeType
@@ -88,8 +89,8 @@ func (s *Application) CreateBookmark(bm Bookmark, user security.User) (*Bookmark
 		fileID = &savedFileId
 	}
 
-	if err := s.BookmarkStore.InUnitOfWork(func(repo store.BookmarkRepository) error {
-		item, err := repo.Create(store.Bookmark{
+	if err := s.BookmarkStore.InUnitOfWork(ctx, func(repo store.BookmarkRepository) error {
+		item, err := repo.Create(ctx, store.Bookmark{
 			DisplayName:        bm.DisplayName,
 			Path:               bm.Path,
 			Type:               t,
@@ -116,7 +117,7 @@ func (s *Application) CreateBookmark(bm Bookmark, user security.User) (*Bookmark
 }
 
 // GetBookmarkByID retrieves a bookmark for the given user
-func (s *Application) GetBookmarkByID(id string, user security.User) (*Bookmark, error) {
+func (s *Application) GetBookmarkByID(ctx context.Context, id string, user security.User) (*Bookmark, error) {
 	if id == "" {
 		return nil, app.ErrValidation("no id supplied to fetch bookmark")
 	}
@@ -125,7 +126,7 @@ func (s *Application) GetBookmarkByID(id string, user security.User) (*Bookmark,
 		err error
 	)
 
-	bm, err = s.BookmarkStore.GetBookmarkByID(id, user.Username)
+	bm, err = s.BookmarkStore.GetBookmarkByID(ctx, id, user.Username)
 	if err != nil {
 		return nil, app.ErrNotFound(fmt.Sprintf("could not fetch bookmark; %v", err))
 	}
@@ -133,7 +134,7 @@ func (s *Application) GetBookmarkByID(id string, user security.User) (*Bookmark,
 }
 
 // GetBookmarkPayloadByID returns the payload of a given bookmark
-func (s *Application) GetBookmarkPayloadByID(id string, user security.User) ([]byte, error) {
+func (s *Application) GetBookmarkPayloadByID(ctx context.Context, id string, user security.User) ([]byte, error) {
 	if id == "" {
 		return nil, app.ErrValidation("no id supplied to fetch bookmark")
 	}
@@ -142,7 +143,7 @@ func (s *Application) GetBookmarkPayloadByID(id string, user security.User) ([]b
 		err error
 	)
 
-	bm, err = s.BookmarkStore.GetBookmarkByID(id, user.Username)
+	bm, err = s.BookmarkStore.GetBookmarkByID(ctx, id, user.Username)
 	if err != nil {
 		return nil, app.ErrNotFound(fmt.Sprintf("could not fetch bookmark; %v", err))
 	}
@@ -166,14 +167,14 @@ func (s *Application) GetBookmarkPayloadByID(id string, user security.User) ([]b
 }
 
 // GetBookmarksByPath retrieves bookmarks by a given path
-func (s *Application) GetBookmarksByPath(path string, user security.User) ([]Bookmark, error) {
+func (s *Application) GetBookmarksByPath(ctx context.Context, path string, user security.User) ([]Bookmark, error) {
 	if path == "" {
 		return nil, app.ErrValidation("missing path")
 	}
 
 	s.Logger.Info(fmt.Sprintf("get bookmarks by path: '%s' for user: '%s'", path, user.Username))
 
-	bms, err := s.BookmarkStore.GetBookmarksByPath(path, user.Username)
+	bms, err := s.BookmarkStore.GetBookmarksByPath(ctx, path, user.Username)
 	if err != nil {
 		s.Logger.Error(fmt.Sprintf("cannot get bookmark by path: '%s', %v", path, err))
 	}
@@ -181,7 +182,7 @@ func (s *Application) GetBookmarksByPath(path string, user security.User) ([]Boo
 }
 
 // GetBookmarksFolderByPath returns the folder identified by the given path
-func (s *Application) GetBookmarksFolderByPath(path string, user security.User) (*Bookmark, error) {
+func (s *Application) GetBookmarksFolderByPath(ctx context.Context, path string, user security.User) (*Bookmark, error) {
 	if path == "" {
 		return nil, app.ErrValidation("missing path parameter")
 	}
@@ -197,7 +198,7 @@ func (s *Application) GetBookmarksFolderByPath(path string, user security.User)
 			ID:          fmt.Sprintf("%s_ROOT", user.Username),
 		}, nil
 	}
-	bm, err := s.BookmarkStore.GetFolderByPath(path, user.Username)
+	bm, err := s.BookmarkStore.GetFolderByPath(ctx, path, user.Username)
 	if err != nil {
 		s.Logger.Error(fmt.Sprintf("cannot get bookmark folder by path: '%s', %v", path, err))
 		return nil, app.ErrNotFound(fmt.Sprintf("no folder for path '%s' found", path))
@@ -207,8 +208,8 @@ func (s *Application) GetBookmarksFolderByPath(path string, user security.User)
 }
 
 // GetAllPaths returns all available stored paths
-func (s *Application) GetAllPaths(user security.User) ([]string, error) {
-	paths, err := s.BookmarkStore.GetAllPaths(user.Username)
+func (s *Application) GetAllPaths(ctx context.Context, user security.User) ([]string, error) {
+	paths, err := s.BookmarkStore.GetAllPaths(ctx, user.Username)
 	if err != nil {
 		s.Logger.Error(fmt.Sprintf("cannot get all bookmark paths: %v", err))
 		return make([]string, 0), fmt.Errorf("cannot get all bookmark paths")
@@ -217,13 +218,13 @@ func (s *Application) GetAllPaths(user security.User) ([]string, error) {
 }
 
 // GetBookmarksByName returns the given bookmark(s) by the supplied name
-func (s *Application) GetBookmarksByName(name string, user security.User) ([]Bookmark, error) {
+func (s *Application) GetBookmarksByName(ctx context.Context, name string, user security.User) ([]Bookmark, error) {
 	if name == "" {
 		return make([]Bookmark, 0), app.ErrValidation("missing name parameter")
 	}
 
 	s.Logger.Info(fmt.Sprintf("get bookmarks by name: '%s' for user: '%s'", name, user.Username))
-	bms, err := s.BookmarkStore.GetBookmarksByName(name, user.Username)
+	bms, err := s.BookmarkStore.GetBookmarksByName(ctx, name, user.Username)
 	var bookmarks []Bookmark
 	if err != nil {
 		s.Logger.Error(fmt.Sprintf("cannot get bookmark by name: '%s', %v", name, err))
@@ -233,15 +234,15 @@ func (s *Application) GetBookmarksByName(name string, user security.User) ([]Boo
 }
 
 // FetchAndForward retrieves a bookmark by id and forwards to the url of the bookmark
-func (s *Application) FetchAndForward(id string, user security.User) (string, error) {
+func (s *Application) FetchAndForward(ctx context.Context, id string, user security.User) (string, error) {
 	if id == "" {
 		return "", app.ErrValidation("missing id parameter")
 	}
 
 	s.Logger.Info(fmt.Sprintf("try to fetch bookmark with ID '%s'", id))
 	redirectURL := ""
-	if err := s.BookmarkStore.InUnitOfWork(func(repo store.BookmarkRepository) error {
-		existing, err := repo.GetBookmarkByID(id, user.Username)
+	if err := s.BookmarkStore.InUnitOfWork(ctx, func(repo store.BookmarkRepository) error {
+		existing, err := repo.GetBookmarkByID(ctx, id, user.Username)
 		if err != nil {
 			s.Logger.Error(fmt.Sprintf("could not find bookmark by id '%s': %v", id, err))
 			return err
@@ -254,7 +255,7 @@ func (s *Application) FetchAndForward(id string, user security.User) (string, er
 
 		// once accessed the highlight flag is removed
 		existing.Highlight = 0
-		if _, err := repo.Update(existing); err != nil {
+		if _, err := repo.Update(ctx, existing); err != nil {
 			s.Logger.Error(fmt.Sprintf("could not update bookmark '%s': %v", id, err))
 			return err
 		}
@@ -270,16 +271,16 @@ func (s *Application) FetchAndForward(id string, user security.User) (string, er
 }
 
 // Delete a bookmark by id
-func (s *Application) Delete(id string, user security.User) error {
+func (s *Application) Delete(ctx context.Context, id string, user security.User) error {
 	if id == "" {
 		return app.ErrValidation("missing id parameter")
 	}
 
 	faviconId := ""
 	s.Logger.Info(fmt.Sprintf("will try to delete bookmark with ID '%s'", id))
-	if err := s.BookmarkStore.InUnitOfWork(func(repo store.BookmarkRepository) error {
+	if err := s.BookmarkStore.InUnitOfWork(ctx, func(repo store.BookmarkRepository) error {
 		// 1) fetch the existing bookmark by id
-		existing, err := repo.GetBookmarkByID(id, user.Username)
+		existing, err := repo.GetBookmarkByID(ctx, id, user.Username)
 		if err != nil {
 			s.Logger.Error(fmt.Sprintf("could not find bookmark by id '%s': %v", id, err))
 			return err
@@ -292,7 +293,7 @@ func (s *Application) Delete(id string, user security.User) error {
 			return fmt.Errorf("cannot delete folder '%s' because of existing child-elements %d",
 				ensureFolderPath(existing.Path, existing.DisplayName), existing.ChildCount)
 		}
-		err = repo.Delete(existing)
+		err = repo.Delete(ctx, existing)
 		if err != nil {
 			return err
 		}
@@ -305,7 +306,7 @@ func (s *Application) Delete(id string, user security.User) error {
 
 	// when a favicon is available remove it, if it is the only remaining one
 	if faviconId != "" {
-		numFavicon, err := s.BookmarkStore.NumBookmarksReferencingFavicon(faviconId, user.Username)
+		numFavicon, err := s.BookmarkStore.NumBookmarksReferencingFavicon(ctx, faviconId, user.Username)
 		if err != nil {
 			s.Logger.Error(fmt.Sprintf("could not check favicon references; %v", err))
 			return fmt.Errorf("error counting favicon references; %v", err)
@@ -332,7 +333,7 @@ func (s *Application) Delete(id string, user security.User) error {
 }
 
 // DeletePath is used for folders to remove a whole "structure" of bookmarks
-func (s *Application) DeletePath(id string, user security.User) error {
+func (s *Application) DeletePath(ctx context.Context, id string, user security.User) error {
 	if id == "" {
 		return app.ErrValidation("missing id parameter")
 	}
@@ -340,9 +341,9 @@ func (s *Application) DeletePath(id string, user security.User) error {
 	faviconIDs := make([]string, 0)
 
 	s.Logger.Info(fmt.Sprintf("will try to delete bookmark-path for ID '%s'", id))
-	if err := s.BookmarkStore.InUnitOfWork(func(repo store.BookmarkRepository) error {
+	if err := s.BookmarkStore.InUnitOfWork(ctx, func(repo store.BookmarkRepository) error {
 		// 1) fetch the existing bookmark by id
-		existing, err := repo.GetBookmarkByID(id, user.Username)
+		existing, err := repo.GetBookmarkByID(ctx, id, user.Username)
 		if err != nil {
 			s.Logger.Error(fmt.Sprintf("could not find bookmark by id '%s': %v", id, err))
 			return err
@@ -354,7 +355,7 @@ func (s *Application) DeletePath(id string, user security.User) error {
 
 		// 2) fetch all items which start with the given path
 		startPath := ensureFolderPath(existing.Path, existing.DisplayName)
-		bookmarks, err := repo.GetBookmarksByPathStart(startPath, user.Username)
+		bookmarks, err := repo.GetBookmarksByPathStart(ctx, startPath, user.Username)
 		if err != nil {
 			s.Logger.Error(fmt.Sprintf("could not find bookmark starting by path '%s': %v", startPath, err))
 			return err
@@ -371,7 +372,7 @@ func (s *Application) DeletePath(id string, user security.User) error {
 		}
 
 		// 4) delete the bookmark path
-		err = repo.DeletePath(startPath, user.Username)
+		err = repo.DeletePath(ctx, startPath, user.Username)
 		if err != nil {
 			s.Logger.Error(fmt.Sprintf("could not delete bookmark-path for '%s': %v", startPath, err))
 			return err
@@ -386,7 +387,7 @@ func (s *Application) DeletePath(id string, user security.User) error {
 	// favicon cleanup
 	for _, fi := range faviconIDs {
 		// when a favicon is available remove it, if it is the only remaining one
-		numFavicon, err := s.BookmarkStore.NumBookmarksReferencingFavicon(fi, user.Username)
+		numFavicon, err := s.BookmarkStore.NumBookmarksReferencingFavicon(ctx, fi, user.Username)
 		if err != nil {
 			s.Logger.Error(fmt.Sprintf("could not check favicon references; %v", err))
 			return fmt.Errorf("error counting favicon references; %v", err)
@@ -413,12 +414,12 @@ func (s *Application) DeletePath(id string, user security.User) error {
 }
 
 // DeleteBookmarkFile removes an existing file stored with a bookmark
-func (s *Application) DeleteBookmarkFile(bookmarkID string, user security.User) error {
+func (s *Application) DeleteBookmarkFile(ctx context.Context, bookmarkID string, user security.User) error {
 	if bookmarkID == "" {
 		return app.ErrValidation("missing bookmarkId parameter")
 	}
 
-	bm, err := s.BookmarkStore.GetBookmarkByID(bookmarkID, user.Username)
+	bm, err := s.BookmarkStore.GetBookmarkByID(ctx, bookmarkID, user.Username)
 	if err != nil {
 		return app.ErrNotFound(fmt.Sprintf("could not fetch bookmark; %v", err))
 	}
@@ -444,22 +445,22 @@ func (s *Application) DeleteBookmarkFile(bookmarkID string, user security.User)
 }
 
 // UpdateSortOrder modifies the display sort-order
-func (s *Application) UpdateSortOrder(sort BookmarksSortOrder, user security.User) (int, error) {
+func (s *Application) UpdateSortOrder(ctx context.Context, sort BookmarksSortOrder, user security.User) (int, error) {
 	if len(sort.IDs) != len(sort.SortOrder) {
 		return 0, app.ErrValidation(fmt.Sprintf("the number of IDs (%d) does not correspond the number of SortOrder entries (%d)", len(sort.IDs), len(sort.SortOrder)))
 	}
 
 	var updates int
-	if err := s.BookmarkStore.InUnitOfWork(func(repo store.BookmarkRepository) error {
+	if err := s.BookmarkStore.InUnitOfWork(ctx, func(repo store.BookmarkRepository) error {
 		for i, item := range sort.IDs {
-			bm, err := repo.GetBookmarkByID(item, user.Username)
+			bm, err := repo.GetBookmarkByID(ctx, item, user.Username)
 			if err != nil {
 				s.Logger.Error(fmt.Sprintf("could not get bookmark by id '%s', %v", item, err))
 				return err
 			}
 			s.Logger.Info(fmt.Sprintf("will update sortOrder of bookmark '%s' with value %d", bm.DisplayName, sort.SortOrder[i]))
 			bm.SortOrder = sort.SortOrder[i]
-			_, err = repo.Update(bm)
+			_, err = repo.Update(ctx, bm)
 			if err != nil {
 				s.Logger.Error(fmt.Sprintf("could not update bookmark: %v", err))
 				return err
@@ -475,7 +476,7 @@ func (s *Application) UpdateSortOrder(sort BookmarksSortOrder, user security.Use
 }
 
 // UpdateBookmark a bookmark
-func (s *Application) UpdateBookmark(bm Bookmark, user security.User) (*Bookmark, error) {
+func (s *Application) UpdateBookmark(ctx context.Context, bm Bookmark, user security.User) (*Bookmark, error) {
 	var (
 		id     string
 		item   store.Bookmark
@@ -488,7 +489,7 @@ func (s *Application) UpdateBookmark(bm Bookmark, user security.User) (*Bookmark
 	}
 
 	// 1) fetch the existing bookmark by id
-	existing, err := s.BookmarkStore.GetBookmarkByID(bm.ID, user.Username)
+	existing, err := s.BookmarkStore.GetBookmarkByID(ctx, bm.ID, user.Username)
 	if err != nil {
 		s.Logger.Error(fmt.Sprintf("could not find bookmark by id '%s': %v", bm.ID, err))
 		return nil, err
@@ -524,7 +525,7 @@ func (s *Application) UpdateBookmark(bm Bookmark, user security.User) (*Bookmark
 	}
 
 	s.Logger.Info(fmt.Sprintf("will try to update existing bookmark entry: '%s (%s)'", bm.DisplayName, bm.ID))
-	if err := s.BookmarkStore.InUnitOfWork(func(repo store.BookmarkRepository) error {
+	if err := s.BookmarkStore.InUnitOfWork(ctx, func(repo store.BookmarkRepository) error {
 		childCount := existing.ChildCount
 		if existing.Type == store.Folder {
 			// 2) ensure that the existing folder is not moved to itself
@@ -538,7 +539,7 @@ func (s *Application) UpdateBookmark(bm Bookmark, user security.User) (*Bookmark
 			// on save of a folder, update the child-count
 			parentPath := existing.Path
 			path := ensureFolderPath(parentPath, existing.DisplayName)
-			nodeCount, err := repo.GetPathChildCount(path, user.Username)
+			nodeCount, err := repo.GetPathChildCount(ctx, path, user.Username)
 			if err != nil {
 				s.Logger.Error(fmt.Sprintf("could not get child-count of path '%s': %v", path, err))
 				return err
@@ -557,7 +558,7 @@ func (s *Application) UpdateBookmark(bm Bookmark, user security.User) (*Bookmark
 		existingPath := existing.Path
 
 		// 4) update the bookmark
-		item, err = repo.Update(store.Bookmark{
+		item, err = repo.Update(ctx, store.Bookmark{
 			ID:                 bm.ID,
 			Created:            existing.Created,
 			DisplayName:        bm.DisplayName,
@@ -594,7 +595,7 @@ func (s *Application) UpdateBookmark(bm Bookmark, user security.User) (*Bookmark
 			// the "solution" is to get rid of one bookmark-folder. it is not important which one, because
 			// we just address by path (/A/B).
 			// the idea is to keep the folder which we are working on (this one) and remove the "other one"
-			sameFolders, err := repo.GetBookmarksByPath(bm.Path, user.Username)
+			sameFolders, err := repo.GetBookmarksByPath(ctx, bm.Path, user.Username)
 			if err != nil {
 				s.Logger.Error(fmt.Sprintf("could not get bookmarks by path '%s': %v", oldPath, err))
 				return err
@@ -608,7 +609,7 @@ func (s *Application) UpdateBookmark(bm Bookmark, user security.User) (*Bookmark
 			if len(sameFolderBookmarks) > 0 {
 				// we have found folders with the same name, remove the folder to have only one remaining
 				for _, f := range sameFolderBookmarks {
-					err = repo.Delete(f)
+					err = repo.Delete(ctx, f)
 					if err != nil {
 						s.Logger.Error(fmt.Sprintf("could not merge folders '%s': %v", f.DisplayName, err))
 						return err
@@ -617,7 +618,7 @@ func (s *Application) UpdateBookmark(bm Bookmark, user security.User) (*Bookmark
 			}
 
 			s.Logger.Info(fmt.Sprintf("will update all old paths '%s' to new path '%s'", oldPath, newPath))
-			bookmarks, err := repo.GetBookmarksByPathStart(oldPath, user.Username)
+			bookmarks, err := repo.GetBookmarksByPathStart(ctx, oldPath, user.Username)
 			if err != nil {
 				s.Logger.Error(fmt.Sprintf("could not get bookmarks by path '%s': %v", oldPath, err))
 				return err
@@ -632,7 +633,7 @@ func (s *Application) UpdateBookmark(bm Bookmark, user security.User) (*Bookmark
 
 			for _, updateBm := range bookmarks {
 				updatePath := strings.ReplaceAll(updateBm.Path, oldPath, newPath)
-				if _, err := repo.Update(store.Bookmark{
+				if _, err := repo.Update(ctx, store.Bookmark{
 					ID:                 updateBm.ID,
 					Created:            updateBm.Created,
 					DisplayName:        updateBm.DisplayName,
@@ -653,7 +654,7 @@ func (s *Application) UpdateBookmark(bm Bookmark, user security.User) (*Bookmark
 			}
 
 			// after the update above, correct the child-count of this folder
-			if err := s.updateChildCountOfPath(newPath, user.Username, repo); err != nil {
+			if err := s.updateChildCountOfPath(ctx, newPath, user.Username, repo); err != nil {
 				s.Logger.Error(fmt.Sprintf("could not update child-count of folder-path '%s': %v", newPath, err))
 				return err
 			}
@@ -662,12 +663,12 @@ func (s *Application) UpdateBookmark(bm Bookmark, user security.User) (*Bookmark
 		// if the path has changed - update the child-count of affected paths
 		if existingPath != bm.Path {
 			// the affected paths are the origin-path and the destination-path
-			if err := s.updateChildCountOfPath(existingPath, user.Username, repo); err != nil {
+			if err := s.updateChildCountOfPath(ctx, existingPath, user.Username, repo); err != nil {
 				s.Logger.Error(fmt.Sprintf("could not update child-count of origin-path '%s': %v", existingPath, err))
 				return err
 			}
 			// and the destination-path
-			if err := s.updateChildCountOfPath(bm.Path, user.Username, repo); err != nil {
+			if err := s.updateChildCountOfPath(ctx, bm.Path, user.Username, repo); err != nil {
 				s.Logger.Error(fmt.Sprintf("could not update child-count of destination-path '%s': %v", bm.Path, err))
 				return err
 			}
@@ -683,19 +684,19 @@ func (s *Application) UpdateBookmark(bm Bookmark, user security.User) (*Bookmark
 	return entityToModel(item), nil
 }
 
-func (s *Application) updateChildCountOfPath(path, username string, repo store.BookmarkRepository) error {
+func (s *Application) updateChildCountOfPath(ctx context.Context, path, username string, repo store.BookmarkRepository) error {
 	if path == "/" {
 		s.Logger.Info("skip the ROOT path '/'")
 		return nil
 	}
 
-	folder, err := repo.GetFolderByPath(path, username)
+	folder, err := repo.GetFolderByPath(ctx, path, username)
 	if err != nil {
 		s.Logger.Error(fmt.Sprintf("cannot get the folder for path '%s': %v", path, err))
 		return err
 	}
 
-	nodeCount, err := repo.GetPathChildCount(path, username)
+	nodeCount, err := repo.GetPathChildCount(ctx, path, username)
 	if err != nil {
 		s.Logger.Error(fmt.Sprintf("could not get the child-count of the path '%s': %v", path, err))
 		return err
@@ -709,7 +710,7 @@ func (s *Application) updateChildCountOfPath(path, username string, repo store.B
 
 	folder.ChildCount = childCount
 
-	if _, err := repo.Update(folder); err != nil {
+	if _, err := repo.Update(ctx, folder); err != nil {
 		s.Logger.Error(fmt.Sprintf("could not update the child-count of folder '%s': %v", path, err))
 		return err
 	}
@@ -722,13 +723,13 @@ var defaultFaviconModTime = time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC)
 const defaultFaviconName = "default_bookmark_favicon.svg"
 
 // GetBookmarkFavicon returns the payload of the found favicon or the default favicon
-func (s *Application) GetBookmarkFavicon(bookmarkID string, user security.User) (*ObjectInfo, error) {
+func (s *Application) GetBookmarkFavicon(ctx context.Context, bookmarkID string, user security.User) (*ObjectInfo, error) {
 	if bookmarkID == "" {
 		return nil, app.ErrValidation("missing bookmark id parameter")
 	}
 
 	s.Logger.Info(fmt.Sprintf("try to fetch bookmark with ID '%s'", bookmarkID))
-	existing, err := s.BookmarkStore.GetBookmarkByID(bookmarkID, user.Username)
+	existing, err := s.BookmarkStore.GetBookmarkByID(ctx, bookmarkID, user.Username)
 	if err != nil {
 		s.Logger.Error(fmt.Sprintf("could not find bookmark by id '%s': %v", bookmarkID, err))
 		return nil, app.ErrNotFound(fmt.Sprintf("could not find bookmark with ID '%s'", bookmarkID))
@@ -770,15 +771,15 @@ func (s *Application) GetBookmarkFavicon(bookmarkID string, user security.User)
 // GetAvailableFavicons retrieves all available favicons of the stored bookmarks.
 // It does not return duplicates, but focuses on unique favicons by comparing the stored payload.
 // A optional search-term is provided to filter bookmarks by name and as a result the given favicons.
-func (s *Application) GetAvailableFavicons(user security.User, search string) ([]ObjectInfo, error) {
+func (s *Application) GetAvailableFavicons(ctx context.Context, user security.User, search string) ([]ObjectInfo, error) {
 	var (
 		bookmarks []store.Bookmark
 		err       error
 	)
 	if search != "" {
-		bookmarks, err = s.BookmarkStore.GetBookmarksByName(search, user.Username)
+		bookmarks, err = s.BookmarkStore.GetBookmarksByName(ctx, search, user.Username)
 	} else {
-		bookmarks, err = s.BookmarkStore.GetAllBookmarks(user.Username)
+		bookmarks, err = s.BookmarkStore.GetAllBookmarks(ctx, user.Username)
 
 	}
 	if err != nil {
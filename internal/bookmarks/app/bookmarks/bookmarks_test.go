@@ -2,6 +2,7 @@ package bookmarks_test
 
 import (
 	"bytes"
+	"context"
 	_ "embed"
 	"fmt"
 	"net/http"
@@ -103,12 +104,12 @@ func repositories(t *testing.T) (store.BookmarkRepository, store.FaviconReposito
 func Test_GetBookmark_NotFound(t *testing.T) {
 	svc := app(t)
 
-	_, err := svc.GetBookmarkByID("", security.User{})
+	_, err := svc.GetBookmarkByID(context.TODO(), "", security.User{})
 	if err == nil {
 		t.Errorf("error expected if no id supplied")
 	}
 
-	_, err = svc.GetBookmarkByID("not-found-id", security.User{})
+	_, err = svc.GetBookmarkByID(context.TODO(), "not-found-id", security.User{})
 	if err == nil {
 		t.Errorf("error expected if no id supplied")
 	}
@@ -117,7 +118,7 @@ func Test_GetBookmark_NotFound(t *testing.T) {
 func Test_CreateBookmark(t *testing.T) {
 	svc := app(t)
 
-	folder, err := svc.CreateBookmark(bookmarks.Bookmark{
+	folder, err := svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Folder,
 		Path:        "/",
 		DisplayName: "a",
@@ -126,7 +127,7 @@ func Test_CreateBookmark(t *testing.T) {
 		t.Errorf("could not create bookmark; %v", err)
 	}
 
-	bm, err := svc.CreateBookmark(bookmarks.Bookmark{
+	bm, err := svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:               bookmarks.Node,
 		Path:               "/" + folder.DisplayName,
 		DisplayName:        "test",
@@ -137,7 +138,7 @@ func Test_CreateBookmark(t *testing.T) {
 		t.Errorf("could not create bookmark; %v", err)
 	}
 
-	fetched, err := svc.GetBookmarkByID(bm.ID, user)
+	fetched, err := svc.GetBookmarkByID(context.TODO(), bm.ID, user)
 	if err != nil {
 		t.Errorf("could not fetch bookmark; %v", err)
 	}
@@ -152,7 +153,7 @@ func Test_CreateBookmark(t *testing.T) {
 	// ---- error case ----
 
 	// missing path
-	_, err = svc.CreateBookmark(bookmarks.Bookmark{
+	_, err = svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Folder,
 		Path:        "",
 		DisplayName: "a",
@@ -162,7 +163,7 @@ func Test_CreateBookmark(t *testing.T) {
 	}
 
 	// missing name
-	_, err = svc.CreateBookmark(bookmarks.Bookmark{
+	_, err = svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Folder,
 		Path:        "/",
 		DisplayName: "",
@@ -172,7 +173,7 @@ func Test_CreateBookmark(t *testing.T) {
 	}
 
 	// missing URL
-	_, err = svc.CreateBookmark(bookmarks.Bookmark{
+	_, err = svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Node,
 		Path:        "/",
 		DisplayName: "test",
@@ -182,7 +183,7 @@ func Test_CreateBookmark(t *testing.T) {
 	}
 
 	// missing File
-	_, err = svc.CreateBookmark(bookmarks.Bookmark{
+	_, err = svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.FileItem,
 		Path:        "/",
 		DisplayName: "test",
@@ -221,7 +222,7 @@ func Test_CreateBookmark_File(t *testing.T) {
 		t.Errorf("could not save file; %v", err)
 	}
 
-	bm, err := svc.CreateBookmark(bookmarks.Bookmark{
+	bm, err := svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.FileItem,
 		Path:        "/",
 		DisplayName: "test",
@@ -239,7 +240,7 @@ func Test_CreateBookmark_File(t *testing.T) {
 	}
 
 	// retrieve the bookmark again
-	fetched, err := svc.GetBookmarkByID(bm.ID, user)
+	fetched, err := svc.GetBookmarkByID(context.TODO(), bm.ID, user)
 	if err != nil {
 		t.Errorf("could not fetch bookmark; %v", err)
 	}
@@ -248,7 +249,7 @@ func Test_CreateBookmark_File(t *testing.T) {
 		t.Errorf("the fetched bookmark is missing a fileID")
 	}
 
-	filePayload, err := svc.GetBookmarkPayloadByID(fetched.ID, user)
+	filePayload, err := svc.GetBookmarkPayloadByID(context.TODO(), fetched.ID, user)
 	if err != nil {
 		t.Errorf("cannot fetch bookmark file payload; %v", err)
 	}
@@ -260,7 +261,7 @@ func Test_CreateBookmark_File(t *testing.T) {
 	// ---- error case ----
 
 	// missing path
-	_, err = svc.CreateBookmark(bookmarks.Bookmark{
+	_, err = svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Node,
 		Path:        "/",
 		DisplayName: "a",
@@ -285,7 +286,7 @@ func Test_Create_And_Delete_Bookmark_File(t *testing.T) {
 		t.Errorf("could not save file; %v", err)
 	}
 
-	bm, err := svc.CreateBookmark(bookmarks.Bookmark{
+	bm, err := svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.FileItem,
 		Path:        "/",
 		DisplayName: "test",
@@ -296,12 +297,12 @@ func Test_Create_And_Delete_Bookmark_File(t *testing.T) {
 	}
 
 	// retrieve the bookmark again
-	fetched, err := svc.GetBookmarkByID(bm.ID, user)
+	fetched, err := svc.GetBookmarkByID(context.TODO(), bm.ID, user)
 	if err != nil {
 		t.Errorf("could not fetch bookmark; %v", err)
 	}
 
-	err = svc.DeleteBookmarkFile(fetched.ID, user)
+	err = svc.DeleteBookmarkFile(context.TODO(), fetched.ID, user)
 	if err != nil {
 		t.Errorf("could not delete bookmark file; %v", err)
 	}
@@ -312,19 +313,19 @@ func Test_GetBookmarksByPath(t *testing.T) {
 	// create a bookmark path and retrieve the bookmarks of the given path
 	// Path: /a
 	path := uuid.NewString()
-	svc.CreateBookmark(bookmarks.Bookmark{
+	svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Folder,
 		DisplayName: path,
 		Path:        "/",
 	}, user)
 	// create two nodes
-	svc.CreateBookmark(bookmarks.Bookmark{
+	svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Node,
 		DisplayName: "test1",
 		Path:        "/" + path,
 		URL:         "http://localhost",
 	}, user)
-	svc.CreateBookmark(bookmarks.Bookmark{
+	svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Node,
 		DisplayName: "test2",
 		Path:        "/" + path,
@@ -332,7 +333,7 @@ func Test_GetBookmarksByPath(t *testing.T) {
 	}, user)
 
 	// ---- fetch the nodes by path ----
-	bms, err := svc.GetBookmarksByPath("/"+path, user)
+	bms, err := svc.GetBookmarksByPath(context.TODO(), "/"+path, user)
 	if err != nil {
 		t.Errorf("could not fetch bookmarks by path; %v", err)
 	}
@@ -341,11 +342,11 @@ func Test_GetBookmarksByPath(t *testing.T) {
 	}
 
 	// ---- error case ----
-	_, err = svc.GetBookmarksByPath("", user)
+	_, err = svc.GetBookmarksByPath(context.TODO(), "", user)
 	if err == nil {
 		t.Error("error expected")
 	}
-	bms, _ = svc.GetBookmarksByPath("we-will-not-find-this-path", user)
+	bms, _ = svc.GetBookmarksByPath(context.TODO(), "we-will-not-find-this-path", user)
 	if len(bms) != 0 {
 		t.Error("there should be no bookmarks found by path")
 	}
@@ -356,20 +357,20 @@ func Test_GetBookmarksFolderByPath(t *testing.T) {
 	// create a bookmark path and retrieve the bookmarks of the given path
 	// Path: /folder/folder1
 	folder := uuid.NewString()
-	svc.CreateBookmark(bookmarks.Bookmark{
+	svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Folder,
 		DisplayName: folder,
 		Path:        "/",
 	}, user)
 	folder1 := uuid.NewString()
-	svc.CreateBookmark(bookmarks.Bookmark{
+	svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Folder,
 		DisplayName: folder1,
 		Path:        "/" + folder,
 	}, user)
 
 	// get the folder by path
-	f, err := svc.GetBookmarksFolderByPath("/"+folder+"/"+folder1, user)
+	f, err := svc.GetBookmarksFolderByPath(context.TODO(), "/"+folder+"/"+folder1, user)
 	if err != nil {
 		t.Errorf("did not expect error for GetBookmarksFolderByPath; %v", err)
 	}
@@ -378,7 +379,7 @@ func Test_GetBookmarksFolderByPath(t *testing.T) {
 	}
 
 	// ---- ROOT ----
-	f, err = svc.GetBookmarksFolderByPath("/", user)
+	f, err = svc.GetBookmarksFolderByPath(context.TODO(), "/", user)
 	if err != nil {
 		t.Errorf("did not expect error for GetBookmarksFolderByPath; %v", err)
 	}
@@ -387,12 +388,12 @@ func Test_GetBookmarksFolderByPath(t *testing.T) {
 	}
 
 	// ---- error ----
-	_, err = svc.GetBookmarksFolderByPath("", user)
+	_, err = svc.GetBookmarksFolderByPath(context.TODO(), "", user)
 	if err == nil {
 		t.Error("error expected for empty path")
 	}
 
-	_, err = svc.GetBookmarksFolderByPath("/unknown", user)
+	_, err = svc.GetBookmarksFolderByPath(context.TODO(), "/unknown", user)
 	if err == nil {
 		t.Error("error expected for unknown path")
 	}
@@ -404,31 +405,31 @@ func Test_GetAllPaths(t *testing.T) {
 	// /folder/folder2
 	// /folder3
 	folder := uuid.NewString()
-	svc.CreateBookmark(bookmarks.Bookmark{
+	svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Folder,
 		DisplayName: folder,
 		Path:        "/",
 	}, user)
 	folder1 := uuid.NewString()
-	svc.CreateBookmark(bookmarks.Bookmark{
+	svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Folder,
 		DisplayName: folder1,
 		Path:        "/" + folder,
 	}, user)
 	folder2 := uuid.NewString()
-	svc.CreateBookmark(bookmarks.Bookmark{
+	svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Folder,
 		DisplayName: folder2,
 		Path:        "/" + folder,
 	}, user)
 	folder3 := uuid.NewString()
-	svc.CreateBookmark(bookmarks.Bookmark{
+	svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Folder,
 		DisplayName: folder3,
 		Path:        "/",
 	}, user)
 
-	paths, err := svc.GetAllPaths(user)
+	paths, err := svc.GetAllPaths(context.TODO(), user)
 	if err != nil {
 		t.Errorf("did not expect error for GetAllPaths; %v", err)
 	}
@@ -437,7 +438,7 @@ func Test_GetAllPaths(t *testing.T) {
 	}
 
 	// ---- error ----
-	paths, _ = svc.GetAllPaths(security.User{Username: "unknown"})
+	paths, _ = svc.GetAllPaths(context.TODO(), security.User{Username: "unknown"})
 	if len(paths) != 1 { // we always get "/"
 		t.Errorf("expected to get no results for unknown user - got %d!", len(paths))
 	}
@@ -450,41 +451,41 @@ func Test_GetBookmarksByName(t *testing.T) {
 	// /folder/$bbb
 	// /folder/$aab
 	folder := uuid.NewString()
-	svc.CreateBookmark(bookmarks.Bookmark{
+	svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Folder,
 		DisplayName: folder,
 		Path:        "/",
 	}, user)
 	aaa := uuid.NewString()
-	svc.CreateBookmark(bookmarks.Bookmark{
+	svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Node,
 		DisplayName: "$aaa" + aaa,
 		URL:         "http://www.aaa.com",
 		Path:        "/" + folder,
 	}, user)
 	bbb := uuid.NewString()
-	svc.CreateBookmark(bookmarks.Bookmark{
+	svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Node,
 		DisplayName: "$bbb" + bbb,
 		URL:         "http://www.bbb.com",
 		Path:        "/" + folder,
 	}, user)
 	aab := uuid.NewString()
-	svc.CreateBookmark(bookmarks.Bookmark{
+	svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Node,
 		DisplayName: "$aab" + aab,
 		URL:         "http://www.aab.com",
 		Path:        "/" + folder,
 	}, user)
 
-	bms, err := svc.GetBookmarksByName("$aa", user)
+	bms, err := svc.GetBookmarksByName(context.TODO(), "$aa", user)
 	if err != nil {
 		t.Errorf("did not expect an error for GetBookmarksByName; %v", err)
 	}
 	if len(bms) != 2 {
 		t.Errorf("expected 2 entries but got %d", len(bms))
 	}
-	bms, err = svc.GetBookmarksByName("$bbb", user)
+	bms, err = svc.GetBookmarksByName(context.TODO(), "$bbb", user)
 	if err != nil {
 		t.Errorf("did not expect an error for GetBookmarksByName; %v", err)
 	}
@@ -493,7 +494,7 @@ func Test_GetBookmarksByName(t *testing.T) {
 	}
 
 	// ---- error ----
-	_, err = svc.GetBookmarksByName("", user)
+	_, err = svc.GetBookmarksByName(context.TODO(), "", user)
 	if err == nil {
 		t.Errorf("expected error for empty name")
 	}
@@ -564,7 +565,7 @@ func Test_FetchBookmark(t *testing.T) {
 	// /folder/bookmark
 
 	folder := uuid.NewString()
-	f, _ := svc.CreateBookmark(bookmarks.Bookmark{
+	f, _ := svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Folder,
 		DisplayName: folder,
 		Path:        "/",
@@ -572,7 +573,7 @@ func Test_FetchBookmark(t *testing.T) {
 
 	url := "http://www.example.com"
 	bookmark := uuid.NewString()
-	bm, err := svc.CreateBookmark(bookmarks.Bookmark{
+	bm, err := svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Node,
 		DisplayName: bookmark,
 		URL:         url,
@@ -585,7 +586,7 @@ func Test_FetchBookmark(t *testing.T) {
 	}
 
 	// fetch-and-forward
-	bmURL, err := svc.FetchAndForward(bm.ID, user)
+	bmURL, err := svc.FetchAndForward(context.TODO(), bm.ID, user)
 	if err != nil {
 		t.Errorf("should not return an error for fetching ID: %v", err)
 	}
@@ -593,24 +594,24 @@ func Test_FetchBookmark(t *testing.T) {
 		t.Errorf("expected the url %s - got url %s", url, bmURL)
 	}
 
-	bm, _ = svc.GetBookmarkByID(bm.ID, user)
+	bm, _ = svc.GetBookmarkByID(context.TODO(), bm.ID, user)
 	if bm.Highlight == 1 {
 		t.Errorf("expected the highlight flag to be 0 after a fetch")
 	}
 
 	// ---- error ----
 
-	_, err = svc.FetchAndForward("", user)
+	_, err = svc.FetchAndForward(context.TODO(), "", user)
 	if err == nil {
 		t.Errorf("expected an error when empty ID supplied")
 	}
 
-	_, err = svc.FetchAndForward("unknown-id", user)
+	_, err = svc.FetchAndForward(context.TODO(), "unknown-id", user)
 	if err == nil {
 		t.Errorf("expected an error for unknown ID supplied")
 	}
 
-	_, err = svc.FetchAndForward(f.ID, user)
+	_, err = svc.FetchAndForward(context.TODO(), f.ID, user)
 	if err == nil {
 		t.Errorf("expected an error for supplied folder ID")
 	}
@@ -629,7 +630,7 @@ func Test_GetFavicon(t *testing.T) {
 	// /folder/bookmark
 
 	folder := uuid.NewString()
-	svc.CreateBookmark(bookmarks.Bookmark{
+	svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Folder,
 		DisplayName: folder,
 		Path:        "/",
@@ -637,7 +638,7 @@ func Test_GetFavicon(t *testing.T) {
 
 	url := "http://localhost"
 	bookmark := uuid.NewString()
-	bm, err := svc.CreateBookmark(bookmarks.Bookmark{
+	bm, err := svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Node,
 		DisplayName: bookmark,
 		URL:         url,
@@ -648,7 +649,7 @@ func Test_GetFavicon(t *testing.T) {
 		t.Errorf("could not store bookmark; %v", err)
 	}
 
-	favicon, err := svc.GetBookmarkFavicon(bm.ID, user)
+	favicon, err := svc.GetBookmarkFavicon(context.TODO(), bm.ID, user)
 	if err != nil {
 		t.Errorf("could not get path of favicon for ID: %s; %v", bm.ID, err)
 	}
@@ -659,12 +660,12 @@ func Test_GetFavicon(t *testing.T) {
 
 	// ---- error ----
 
-	_, err = svc.GetBookmarkFavicon("", user)
+	_, err = svc.GetBookmarkFavicon(context.TODO(), "", user)
 	if err == nil {
 		t.Errorf("expected error for empty id")
 	}
 
-	_, err = svc.GetBookmarkFavicon("unknown-id", user)
+	_, err = svc.GetBookmarkFavicon(context.TODO(), "unknown-id", user)
 	if err == nil {
 		t.Errorf("expected error for unknown id")
 	}
@@ -683,14 +684,14 @@ func Test_DeleteBookmark(t *testing.T) {
 	// /folder/bookmark
 
 	folder := uuid.NewString()
-	bmF, _ := svc.CreateBookmark(bookmarks.Bookmark{
+	bmF, _ := svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Folder,
 		DisplayName: folder,
 		Path:        "/",
 	}, user)
 
 	bookmark := uuid.NewString()
-	bm, _ := svc.CreateBookmark(bookmarks.Bookmark{
+	bm, _ := svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Node,
 		DisplayName: bookmark,
 		URL:         "http://localhost",
@@ -699,39 +700,39 @@ func Test_DeleteBookmark(t *testing.T) {
 	}, user)
 
 	// try to delete the folder which should not work because of the child-node
-	err = svc.Delete(bmF.ID, user)
+	err = svc.Delete(context.TODO(), bmF.ID, user)
 	if err == nil {
 		t.Error("expected an error because cannot delete a bookmark with child entries")
 	}
 
-	err = svc.Delete(bm.ID, user)
+	err = svc.Delete(context.TODO(), bm.ID, user)
 	if err != nil {
 		t.Errorf("could not delete bookmark with ID; %s; %v", bm.ID, err)
 	}
 
-	err = svc.Delete(bmF.ID, user)
+	err = svc.Delete(context.TODO(), bmF.ID, user)
 	if err != nil {
 		t.Errorf("could not delete bookmark with ID; %s; %v", bmF.ID, err)
 	}
 
-	_, err = svc.GetBookmarkByID(bm.ID, user)
+	_, err = svc.GetBookmarkByID(context.TODO(), bm.ID, user)
 	if err == nil {
 		t.Errorf("expected an error because deleted bookmark with id %s", bm.ID)
 	}
 
-	_, err = svc.GetBookmarkByID(bmF.ID, user)
+	_, err = svc.GetBookmarkByID(context.TODO(), bmF.ID, user)
 	if err == nil {
 		t.Errorf("expected an error because deleted bookmark with id %s", bmF.ID)
 	}
 
 	// ---- error ----
 
-	err = svc.Delete("", user)
+	err = svc.Delete(context.TODO(), "", user)
 	if err == nil {
 		t.Errorf("expected error for empty id")
 	}
 
-	err = svc.Delete("unknown-id", user)
+	err = svc.Delete(context.TODO(), "unknown-id", user)
 	if err == nil {
 		t.Errorf("expected error for unknown id")
 	}
@@ -750,14 +751,14 @@ func Test_DeleteBookmarkPath(t *testing.T) {
 	// /folder/bookmark
 
 	folder := uuid.NewString()
-	bmF, _ := svc.CreateBookmark(bookmarks.Bookmark{
+	bmF, _ := svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Folder,
 		DisplayName: folder,
 		Path:        "/",
 	}, user)
 
 	bookmark := uuid.NewString()
-	bm, _ := svc.CreateBookmark(bookmarks.Bookmark{
+	bm, _ := svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Node,
 		DisplayName: bookmark,
 		URL:         "http://localhost",
@@ -765,29 +766,29 @@ func Test_DeleteBookmarkPath(t *testing.T) {
 		Favicon:     obj.Name,
 	}, user)
 
-	err = svc.DeletePath(bmF.ID, user)
+	err = svc.DeletePath(context.TODO(), bmF.ID, user)
 	if err != nil {
 		t.Error("could not delete the folder-path", err)
 	}
 
-	_, err = svc.GetBookmarkByID(bm.ID, user)
+	_, err = svc.GetBookmarkByID(context.TODO(), bm.ID, user)
 	if err == nil {
 		t.Errorf("expected an error because deleted bookmark with id %s", bm.ID)
 	}
 
-	_, err = svc.GetBookmarkByID(bmF.ID, user)
+	_, err = svc.GetBookmarkByID(context.TODO(), bmF.ID, user)
 	if err == nil {
 		t.Errorf("expected an error because deleted bookmark with id %s", bmF.ID)
 	}
 
 	// ---- error ----
 
-	err = svc.DeletePath("", user)
+	err = svc.DeletePath(context.TODO(), "", user)
 	if err == nil {
 		t.Errorf("expected error for empty id")
 	}
 
-	err = svc.DeletePath("unknown-id", user)
+	err = svc.DeletePath(context.TODO(), "unknown-id", user)
 	if err == nil {
 		t.Errorf("expected error for unknown id")
 	}
@@ -795,13 +796,13 @@ func Test_DeleteBookmarkPath(t *testing.T) {
 	// ---- Delete Item ----
 
 	bookmark = uuid.NewString()
-	bm, _ = svc.CreateBookmark(bookmarks.Bookmark{
+	bm, _ = svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Node,
 		DisplayName: bookmark,
 		URL:         "http://localhost",
 		Path:        "/",
 	}, user)
-	err = svc.DeletePath(bm.ID, user)
+	err = svc.DeletePath(context.TODO(), bm.ID, user)
 	if err == nil {
 		t.Errorf("expected error for type Node")
 	}
@@ -817,27 +818,27 @@ func Test_SortOrder(t *testing.T) {
 	url := "http://www.example.com"
 
 	sortFolder := uuid.NewString()
-	svc.CreateBookmark(bookmarks.Bookmark{
+	svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Folder,
 		DisplayName: sortFolder,
 		Path:        "/",
 	}, user)
 	bookmark1 := uuid.NewString()
-	b1, _ := svc.CreateBookmark(bookmarks.Bookmark{
+	b1, _ := svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Node,
 		DisplayName: "1_" + bookmark1,
 		Path:        "/" + sortFolder,
 		URL:         url,
 	}, user)
 	bookmark2 := uuid.NewString()
-	b2, _ := svc.CreateBookmark(bookmarks.Bookmark{
+	b2, _ := svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Node,
 		DisplayName: "2_" + bookmark2,
 		Path:        "/" + sortFolder,
 		URL:         url,
 	}, user)
 	bookmark3 := uuid.NewString()
-	b3, _ := svc.CreateBookmark(bookmarks.Bookmark{
+	b3, _ := svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Node,
 		DisplayName: "3_" + bookmark3,
 		Path:        "/" + sortFolder,
@@ -845,7 +846,7 @@ func Test_SortOrder(t *testing.T) {
 	}, user)
 
 	// get the initial sort-order
-	bms, err := svc.GetBookmarksByPath("/"+sortFolder, user)
+	bms, err := svc.GetBookmarksByPath(context.TODO(), "/"+sortFolder, user)
 	if err != nil {
 		t.Errorf("could not get bookmarks unsorted! %v", err)
 	}
@@ -860,14 +861,14 @@ func Test_SortOrder(t *testing.T) {
 
 	// 1) reverse
 	so.SortOrder = []int{2, 1, 0}
-	count, err := svc.UpdateSortOrder(so, user)
+	count, err := svc.UpdateSortOrder(context.TODO(), so, user)
 	if err != nil {
 		t.Errorf("could not update the sortorder of bookmarks; %v", err)
 	}
 	if count != 3 {
 		t.Errorf("should have updated 3 bookmarks, but the number was %d", count)
 	}
-	bms, _ = svc.GetBookmarksByPath("/"+sortFolder, user)
+	bms, _ = svc.GetBookmarksByPath(context.TODO(), "/"+sortFolder, user)
 	if len(bms) != 3 {
 		t.Errorf("should have received 3 bookmarks got %d", len(bms))
 	}
@@ -877,11 +878,11 @@ func Test_SortOrder(t *testing.T) {
 
 	// 2) other
 	so.SortOrder = []int{0, 2, 1}
-	_, err = svc.UpdateSortOrder(so, user)
+	_, err = svc.UpdateSortOrder(context.TODO(), so, user)
 	if err != nil {
 		t.Errorf("could not update the sortorder of bookmarks; %v", err)
 	}
-	bms, _ = svc.GetBookmarksByPath("/"+sortFolder, user)
+	bms, _ = svc.GetBookmarksByPath(context.TODO(), "/"+sortFolder, user)
 	if len(bms) != 3 {
 		t.Errorf("should have received 3 bookmarks got %d", len(bms))
 	}
@@ -892,7 +893,7 @@ func Test_SortOrder(t *testing.T) {
 	// empty slice, no updates
 	so.IDs = make([]string, 0)
 	so.SortOrder = make([]int, 0)
-	count, _ = svc.UpdateSortOrder(so, user)
+	count, _ = svc.UpdateSortOrder(context.TODO(), so, user)
 	if count != 0 {
 		t.Errorf("expected 0 updates but got %d", count)
 	}
@@ -902,7 +903,7 @@ func Test_SortOrder(t *testing.T) {
 	// unkown ID
 	so.IDs = []string{b1.ID, b2.ID, "unknownd-ID"}
 	so.SortOrder = []int{2, 1, 0}
-	_, err = svc.UpdateSortOrder(so, user)
+	_, err = svc.UpdateSortOrder(context.TODO(), so, user)
 	if err == nil {
 		t.Errorf("expected an error for unknown-ID")
 	}
@@ -910,7 +911,7 @@ func Test_SortOrder(t *testing.T) {
 	// unbalanced slices
 	so.IDs = []string{b1.ID, b2.ID, b3.ID}
 	so.SortOrder = []int{2, 1}
-	_, err = svc.UpdateSortOrder(so, user)
+	_, err = svc.UpdateSortOrder(context.TODO(), so, user)
 	if err == nil {
 		t.Errorf("expected an error for unknown-ID")
 	}
@@ -923,19 +924,19 @@ func Test_UpdateBookmark(t *testing.T) {
 	url := "http://www.example.com"
 
 	updateFolder := uuid.NewString()
-	f, _ := svc.CreateBookmark(bookmarks.Bookmark{
+	f, _ := svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Folder,
 		DisplayName: updateFolder,
 		Path:        "/",
 	}, user)
 	updateFolder2 := uuid.NewString()
-	svc.CreateBookmark(bookmarks.Bookmark{
+	svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Folder,
 		DisplayName: updateFolder2,
 		Path:        "/",
 	}, user)
 	bookmark := uuid.NewString()
-	bm, _ := svc.CreateBookmark(bookmarks.Bookmark{
+	bm, _ := svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Node,
 		DisplayName: bookmark,
 		Path:        "/" + updateFolder,
@@ -943,7 +944,7 @@ func Test_UpdateBookmark(t *testing.T) {
 	}, user)
 
 	// get the bookmark
-	b, _ := svc.GetBookmarkByID(bm.ID, user)
+	b, _ := svc.GetBookmarkByID(context.TODO(), bm.ID, user)
 	if b == nil {
 		t.Fatalf("could not get bookmark by id %s", bm.ID)
 	}
@@ -951,11 +952,11 @@ func Test_UpdateBookmark(t *testing.T) {
 	// update the bookmark
 	b.DisplayName = bookmark + "_update"
 	b.InvertFaviconColor = 1
-	_, err := svc.UpdateBookmark(*b, user)
+	_, err := svc.UpdateBookmark(context.TODO(), *b, user)
 	if err != nil {
 		t.Errorf("could not update bookmark, %v", err)
 	}
-	b, _ = svc.GetBookmarkByID(bm.ID, user)
+	b, _ = svc.GetBookmarkByID(context.TODO(), bm.ID, user)
 
 	if b.DisplayName != bookmark+"_update" {
 		t.Errorf("the bookmark was not correctly updated, got %s", b.DisplayName)
@@ -965,34 +966,34 @@ func Test_UpdateBookmark(t *testing.T) {
 	}
 
 	// update the folder
-	f, _ = svc.GetBookmarkByID(f.ID, user)
+	f, _ = svc.GetBookmarkByID(context.TODO(), f.ID, user)
 	f.DisplayName = f.DisplayName + "_update"
-	_, err = svc.UpdateBookmark(*f, user)
+	_, err = svc.UpdateBookmark(context.TODO(), *f, user)
 	if err != nil {
 		t.Errorf("could not update bookmark, %v", err)
 	}
 
 	// fetch the bookmark again
-	b, _ = svc.GetBookmarkByID(bm.ID, user)
+	b, _ = svc.GetBookmarkByID(context.TODO(), bm.ID, user)
 	if b.Path != "/"+updateFolder+"_update" {
 		t.Errorf("the bookmark-path was not correctly updated; %s", b.Path)
 	}
 
 	// change the path of the bookmark
-	b, _ = svc.GetBookmarkByID(bm.ID, user)
+	b, _ = svc.GetBookmarkByID(context.TODO(), bm.ID, user)
 	b.Path = "/" + updateFolder2
-	_, err = svc.UpdateBookmark(*b, user)
+	_, err = svc.UpdateBookmark(context.TODO(), *b, user)
 	if err != nil {
 		t.Errorf("could not update bookmark, %v", err)
 	}
 
 	// ---- error: empty ----
-	_, err = svc.UpdateBookmark(bookmarks.Bookmark{}, user)
+	_, err = svc.UpdateBookmark(context.TODO(), bookmarks.Bookmark{}, user)
 	if err == nil {
 		t.Errorf("could not update bookmark, %v", err)
 	}
 	// ---- error: unknown-id ----
-	_, err = svc.UpdateBookmark(bookmarks.Bookmark{
+	_, err = svc.UpdateBookmark(context.TODO(), bookmarks.Bookmark{
 		Path:        "/",
 		DisplayName: "abc",
 		ID:          "unknown-ID",
@@ -1001,9 +1002,9 @@ func Test_UpdateBookmark(t *testing.T) {
 		t.Error("expected an error because of unknown-ID")
 	}
 	// ---- error: move folder to self ----
-	f, _ = svc.GetBookmarkByID(f.ID, user)
+	f, _ = svc.GetBookmarkByID(context.TODO(), f.ID, user)
 	f.Path = f.Path + f.DisplayName
-	_, err = svc.UpdateBookmark(*f, user)
+	_, err = svc.UpdateBookmark(context.TODO(), *f, user)
 	if err == nil {
 		t.Errorf("could not update bookmark, %v", err)
 	}
@@ -1022,7 +1023,7 @@ func Test_UpateBookmarks_WithFavicons(t *testing.T) {
 	// /bookmark
 
 	bookmark := uuid.NewString()
-	bm, err := svc.CreateBookmark(bookmarks.Bookmark{
+	bm, err := svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Node,
 		DisplayName: bookmark,
 		URL:         "http://localhost",
@@ -1040,7 +1041,7 @@ func Test_UpateBookmarks_WithFavicons(t *testing.T) {
 	}
 	bm.Favicon = obj.Name
 
-	bm, err = svc.UpdateBookmark(*bm, user)
+	bm, err = svc.UpdateBookmark(context.TODO(), *bm, user)
 	if err != nil {
 		t.Errorf("could not update bookmark; %v", err)
 	}
@@ -1056,7 +1057,7 @@ func Test_UpdateBookmarks_WithFile(t *testing.T) {
 		File:     bytes.NewReader([]byte(pdfPayload)),
 		Size:     int64(len([]byte(pdfPayload))),
 	})
-	bm, _ := svc.CreateBookmark(bookmarks.Bookmark{
+	bm, _ := svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.FileItem,
 		Path:        "/",
 		DisplayName: "test.pdf",
@@ -1064,7 +1065,7 @@ func Test_UpdateBookmarks_WithFile(t *testing.T) {
 	}, user)
 
 	// get the bookmark
-	b, _ := svc.GetBookmarkByID(bm.ID, user)
+	b, _ := svc.GetBookmarkByID(context.TODO(), bm.ID, user)
 	if b == nil {
 		t.Fatalf("could not get bookmark by id %s", bm.ID)
 	}
@@ -1078,13 +1079,13 @@ func Test_UpdateBookmarks_WithFile(t *testing.T) {
 	})
 	b.FileID = fileID
 
-	bm, err := svc.UpdateBookmark(*b, user)
+	bm, err := svc.UpdateBookmark(context.TODO(), *b, user)
 	if err != nil {
 		t.Errorf("could not update bookmark, %v", err)
 	}
 
 	// retrieve the bookmark again
-	fetched, err := svc.GetBookmarkByID(bm.ID, user)
+	fetched, err := svc.GetBookmarkByID(context.TODO(), bm.ID, user)
 	if err != nil {
 		t.Errorf("could not fetch bookmark; %v", err)
 	}
@@ -1096,7 +1097,7 @@ func Test_UpdateBookmarks_WithFile(t *testing.T) {
 		t.Errorf("the file payload of the bookmark was not saved")
 	}
 
-	filePayload, err := svc.GetBookmarkPayloadByID(fetched.ID, user)
+	filePayload, err := svc.GetBookmarkPayloadByID(context.TODO(), fetched.ID, user)
 	if err != nil {
 		t.Errorf("cannot fetch bookmark file payload; %v", err)
 	}
@@ -1123,7 +1124,7 @@ func Test_GetAllAvailableFavicons(t *testing.T) {
 		t.Errorf("error fetching favicon: %v", err)
 	}
 
-	_, err = svc.CreateBookmark(bookmarks.Bookmark{
+	_, err = svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Node,
 		DisplayName: uuid.NewString(),
 		URL:         "http://localhost1",
@@ -1140,7 +1141,7 @@ func Test_GetAllAvailableFavicons(t *testing.T) {
 		t.Errorf("error fetching favicon: %v", err)
 	}
 
-	_, err = svc.CreateBookmark(bookmarks.Bookmark{
+	_, err = svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Node,
 		DisplayName: uuid.NewString(),
 		URL:         "http://localhost2",
@@ -1157,7 +1158,7 @@ func Test_GetAllAvailableFavicons(t *testing.T) {
 		t.Errorf("error fetching favicon: %v", err)
 	}
 
-	_, err = svc.CreateBookmark(bookmarks.Bookmark{
+	_, err = svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Node,
 		DisplayName: uuid.NewString(),
 		URL:         "http://localhost3",
@@ -1174,7 +1175,7 @@ func Test_GetAllAvailableFavicons(t *testing.T) {
 		t.Errorf("error fetching favicon: %v", err)
 	}
 
-	_, err = svc.CreateBookmark(bookmarks.Bookmark{
+	_, err = svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Node,
 		DisplayName: uuid.NewString(),
 		URL:         "http://localhost4",
@@ -1186,7 +1187,7 @@ func Test_GetAllAvailableFavicons(t *testing.T) {
 	}
 
 	// 5)
-	_, err = svc.CreateBookmark(bookmarks.Bookmark{
+	_, err = svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Folder,
 		DisplayName: uuid.NewString(),
 		Path:        "/",
@@ -1198,7 +1199,7 @@ func Test_GetAllAvailableFavicons(t *testing.T) {
 	// we created 4 bookmarks with 2 different favicons. The result of all available favicons should be just 2
 	// 4were saved but 2 used the same favicon, therefor the unique result should be only 2.
 
-	favicons, err := svc.GetAvailableFavicons(favUser, "")
+	favicons, err := svc.GetAvailableFavicons(context.TODO(), favUser, "")
 	if err != nil {
 		t.Errorf("could not get available favicons; %v", err)
 	}
@@ -1234,7 +1235,7 @@ func Test_GetAllAvailableFavicons(t *testing.T) {
 		Email:       "a.b@c.de",
 		DisplayName: "Unknown_" + uuid.NewString(),
 	}
-	favicons, err = svc.GetAvailableFavicons(unknownUser, "")
+	favicons, err = svc.GetAvailableFavicons(context.TODO(), unknownUser, "")
 	if err != nil {
 		t.Errorf("could not get available favicons; %v", err)
 	}
@@ -1301,7 +1302,7 @@ func Test_MoveSameNamedFolder(t *testing.T) {
 	//    - /SubFolder
 	//	- Bookmark1
 	targetRoot := "TARGET-ROOT"
-	_, err := svc.CreateBookmark(bookmarks.Bookmark{
+	_, err := svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Folder,
 		DisplayName: targetRoot,
 		Path:        "/",
@@ -1310,7 +1311,7 @@ func Test_MoveSameNamedFolder(t *testing.T) {
 		t.Errorf("could not create targetRoot; %v", err)
 	}
 	subFolderName := "Subfolder"
-	_, err = svc.CreateBookmark(bookmarks.Bookmark{
+	_, err = svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Folder,
 		DisplayName: subFolderName,
 		Path:        "/" + targetRoot,
@@ -1318,7 +1319,7 @@ func Test_MoveSameNamedFolder(t *testing.T) {
 	if err != nil {
 		t.Errorf("could not create subFolderName in targetRoot; %v", err)
 	}
-	_, err = svc.CreateBookmark(bookmarks.Bookmark{
+	_, err = svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Node,
 		DisplayName: "Bookmark1",
 		URL:         "http://example.com",
@@ -1331,7 +1332,7 @@ func Test_MoveSameNamedFolder(t *testing.T) {
 	// create the same subfolder in ROOT
 	//   /SubFolder		<- move this folder to TARGET-ROOT
 	//	- Bookmark2
-	rootSubFolder, err := svc.CreateBookmark(bookmarks.Bookmark{
+	rootSubFolder, err := svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Folder,
 		DisplayName: subFolderName,
 		Path:        "/", // <-- create the same folder in ROOT
@@ -1339,7 +1340,7 @@ func Test_MoveSameNamedFolder(t *testing.T) {
 	if err != nil {
 		t.Errorf("could not create subFolderName in targetRoot; %v", err)
 	}
-	_, err = svc.CreateBookmark(bookmarks.Bookmark{
+	_, err = svc.CreateBookmark(context.TODO(), bookmarks.Bookmark{
 		Type:        bookmarks.Node,
 		DisplayName: "Bookmark2",
 		URL:         "http://example.com",
@@ -1355,12 +1356,12 @@ func Test_MoveSameNamedFolder(t *testing.T) {
 	// with the same name need to be merged and the one remaining folder should have
 	// all the bookmarks of the two folders combined
 	rootSubFolder.Path = "/" + targetRoot
-	_, err = svc.UpdateBookmark(*rootSubFolder, user)
+	_, err = svc.UpdateBookmark(context.TODO(), *rootSubFolder, user)
 	if err != nil {
 		t.Errorf("could not move rootSubFolder to targetRoot; %v", err)
 	}
 
-	folders, err := svc.GetBookmarksByPath("/"+targetRoot, user)
+	folders, err := svc.GetBookmarksByPath(context.TODO(), "/"+targetRoot, user)
 	if err != nil {
 		t.Errorf("could not get bookmark folders for path /TARGET-ROOT; %v", err)
 	}
@@ -1380,7 +1381,7 @@ func Test_MoveSameNamedFolder(t *testing.T) {
 	//    - /SubFolder
 	//	- Bookmark1
 	//	- Bookmark2
-	bookmarks, err := svc.GetBookmarksByPath("/"+targetRoot+"/"+subFolderName, user)
+	bookmarks, err := svc.GetBookmarksByPath(context.TODO(), "/"+targetRoot+"/"+subFolderName, user)
 	if err != nil {
 		t.Errorf("could not get bookmark folders for path /TARGET-ROOT/Subfolder; %v", err)
 	}
@@ -1399,7 +1400,7 @@ func Test_MoveSameNamedFolder(t *testing.T) {
 	}
 
 	// the child-count of the SubFolder should be 2
-	subFolder, err := svc.GetBookmarkByID(rootSubFolder.ID, user)
+	subFolder, err := svc.GetBookmarkByID(context.TODO(), rootSubFolder.ID, user)
 	if err != nil {
 		t.Fatalf("could not get bookmark folder 'SubFolder' by ID; %v", err)
 	}
@@ -7,23 +7,23 @@ import "golang.binggl.net/monorepo/pkg/config"
 type AppConfig struct {
 	config.BaseConfig
 	Database          Database
-	FaviconUploadPath string
+	FaviconUploadPath string `validate:"required"`
 	DefaultFavicon    string
 	Upload            UploadSettings
 }
 
 // Database defines the connection string
 type Database struct {
-	ConnectionString string
+	ConnectionString string `validate:"required"`
 	Dialect          string
 }
 
 // UploadSettings defines relevant values for the upload logic
 type UploadSettings struct {
 	// AllowedFileTypes is a list of mime-types allowed to be uploaded
-	AllowedFileTypes []string
+	AllowedFileTypes []string `validate:"required"`
 	// MaxUploadSize defines the maximum permissible file-size
-	MaxUploadSize int64
+	MaxUploadSize int64 `validate:"min=1"`
 	// UploadPath defines a directory where uploaded files are stored
-	UploadPath string
+	UploadPath string `validate:"required"`
 }
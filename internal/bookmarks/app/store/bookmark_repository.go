@@ -3,6 +3,7 @@
 package store
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
@@ -16,30 +17,32 @@ import (
 
 // BookmarkRepository defines methods to interact with a store
 type BookmarkRepository interface {
-	Create(item Bookmark) (Bookmark, error)
-	Update(item Bookmark) (Bookmark, error)
-	Delete(item Bookmark) error
-	DeletePath(path, username string) error
-	InUnitOfWork(handle func(repo BookmarkRepository) error) error
-
-	GetAllBookmarks(username string) ([]Bookmark, error)
-	GetBookmarksByPath(path, username string) ([]Bookmark, error)
-	GetBookmarksByPathStart(path, username string) ([]Bookmark, error)
-	GetBookmarksByName(name, username string) ([]Bookmark, error)
-	GetPathChildCount(path, username string) ([]NodeCount, error)
-	GetAllPaths(username string) ([]string, error)
-
-	GetBookmarkByID(id, username string) (Bookmark, error)
-	GetFolderByPath(path, username string) (Bookmark, error)
-	NumBookmarksReferencingFavicon(faviconID, username string) (int, error)
+	Create(ctx context.Context, item Bookmark) (Bookmark, error)
+	Update(ctx context.Context, item Bookmark) (Bookmark, error)
+	Delete(ctx context.Context, item Bookmark) error
+	DeletePath(ctx context.Context, path, username string) error
+	InUnitOfWork(ctx context.Context, handle func(repo BookmarkRepository) error) error
+
+	GetAllBookmarks(ctx context.Context, username string) ([]Bookmark, error)
+	GetBookmarksByPath(ctx context.Context, path, username string) ([]Bookmark, error)
+	GetBookmarksByPathStart(ctx context.Context, path, username string) ([]Bookmark, error)
+	GetBookmarksByName(ctx context.Context, name, username string) ([]Bookmark, error)
+	GetPathChildCount(ctx context.Context, path, username string) ([]NodeCount, error)
+	GetAllPaths(ctx context.Context, username string) ([]string, error)
+
+	GetBookmarkByID(ctx context.Context, id, username string) (Bookmark, error)
+	GetFolderByPath(ctx context.Context, path, username string) (Bookmark, error)
+	NumBookmarksReferencingFavicon(ctx context.Context, faviconID, username string) (int, error)
 }
 
 // CreateBookmarkRepo creates a new repository using read and write connections
 func CreateBookmarkRepo(con persistence.Connection, logger logging.Logger) BookmarkRepository {
-	return &dbBookmarkRepository{
+	var repo BookmarkRepository = &dbBookmarkRepository{
 		con:    con,
 		logger: logger,
 	}
+	repo = BookmarkRepositoryTracingMiddleware()(repo)
+	return repo
 }
 
 // --------------------------------------------------------------------------
@@ -55,16 +58,16 @@ type dbBookmarkRepository struct {
 // --------------------------------------------------------------------------
 
 // GetAllBookmarks retrieves all available bookmarks for the given user
-func (r *dbBookmarkRepository) GetAllBookmarks(username string) ([]Bookmark, error) {
+func (r *dbBookmarkRepository) GetAllBookmarks(ctx context.Context, username string) ([]Bookmark, error) {
 	var bookmarks []Bookmark
-	h := r.con.R().Joins("File").Order("sort_order").Order("display_name").Where(&Bookmark{UserName: username}).Find(&bookmarks)
+	h := r.con.R().WithContext(ctx).Joins("File").Order("sort_order").Order("display_name").Where(&Bookmark{UserName: username}).Find(&bookmarks)
 	return bookmarks, h.Error
 }
 
 // GetBookmarksByPath return the bookmark elements which have the given path
-func (r *dbBookmarkRepository) GetBookmarksByPath(path, username string) ([]Bookmark, error) {
+func (r *dbBookmarkRepository) GetBookmarksByPath(ctx context.Context, path, username string) ([]Bookmark, error) {
 	var bookmarks []Bookmark
-	h := r.con.R().Joins("File").Order("sort_order").Order("display_name").Where(&Bookmark{
+	h := r.con.R().WithContext(ctx).Joins("File").Order("sort_order").Order("display_name").Where(&Bookmark{
 		UserName: username,
 		Path:     path,
 	}).Find(&bookmarks)
@@ -72,9 +75,9 @@ func (r *dbBookmarkRepository) GetBookmarksByPath(path, username string) ([]Book
 }
 
 // GetBookmarksByPathStart return the bookmark elements which path starts with
-func (r *dbBookmarkRepository) GetBookmarksByPathStart(path, username string) ([]Bookmark, error) {
+func (r *dbBookmarkRepository) GetBookmarksByPathStart(ctx context.Context, path, username string) ([]Bookmark, error) {
 	var bookmarks []Bookmark
-	h := r.con.R().Joins("File").
+	h := r.con.R().WithContext(ctx).Joins("File").
 		Order("type desc").
 		Order("sort_order").
 		Order("display_name").
@@ -83,22 +86,22 @@ func (r *dbBookmarkRepository) GetBookmarksByPathStart(path, username string) ([
 }
 
 // GetBookmarksByName searches for bookmarks by the given name
-func (r *dbBookmarkRepository) GetBookmarksByName(name, username string) ([]Bookmark, error) {
+func (r *dbBookmarkRepository) GetBookmarksByName(ctx context.Context, name, username string) ([]Bookmark, error) {
 	var bookmarks []Bookmark
-	h := r.con.R().Joins("File").Order("sort_order").Order("display_name").
+	h := r.con.R().WithContext(ctx).Joins("File").Order("sort_order").Order("display_name").
 		Where("user_name = ? AND lower(display_name) LIKE ?", username, "%"+strings.ToLower(name)+"%").Find(&bookmarks)
 	return bookmarks, h.Error
 }
 
 // GetBookmarkByID returns the bookmark specified by the given id - for the user
-func (r *dbBookmarkRepository) GetBookmarkByID(id, username string) (Bookmark, error) {
+func (r *dbBookmarkRepository) GetBookmarkByID(ctx context.Context, id, username string) (Bookmark, error) {
 	var bookmark Bookmark
-	h := r.con.R().Joins("File").Where(&Bookmark{ID: id, UserName: username}).First(&bookmark)
+	h := r.con.R().WithContext(ctx).Joins("File").Where(&Bookmark{ID: id, UserName: username}).First(&bookmark)
 	return bookmark, h.Error
 }
 
 // GetFolderByPath returns the bookmark folder elements specified by path
-func (r *dbBookmarkRepository) GetFolderByPath(path, username string) (Bookmark, error) {
+func (r *dbBookmarkRepository) GetFolderByPath(ctx context.Context, path, username string) (Bookmark, error) {
 	var bookmark Bookmark
 
 	// ROOT path is virtual
@@ -111,7 +114,7 @@ func (r *dbBookmarkRepository) GetFolderByPath(path, username string) (Bookmark,
 		return Bookmark{}, fmt.Errorf("could not get parent/folder of path '%s'", path)
 	}
 
-	h := r.con.R().Where(&Bookmark{
+	h := r.con.R().WithContext(ctx).Where(&Bookmark{
 		UserName:    username,
 		Path:        parent,
 		DisplayName: folderName,
@@ -122,7 +125,7 @@ func (r *dbBookmarkRepository) GetFolderByPath(path, username string) (Bookmark,
 }
 
 // GetPathChildCount returns the number of child-elements for a given path
-func (r *dbBookmarkRepository) GetPathChildCount(path, username string) ([]NodeCount, error) {
+func (r *dbBookmarkRepository) GetPathChildCount(ctx context.Context, path, username string) ([]NodeCount, error) {
 	if path == "" {
 		return nil, fmt.Errorf("no path supplied")
 	}
@@ -135,7 +138,7 @@ func (r *dbBookmarkRepository) GetPathChildCount(path, username string) ([]NodeC
 
 	// Folder, Username, Path
 	var nodes []NodeCount
-	h := r.con.R().Raw(query, Folder, username, path).Scan(&nodes)
+	h := r.con.R().WithContext(ctx).Raw(query, Folder, username, path).Scan(&nodes)
 	if h.Error != nil {
 		return nil, fmt.Errorf("could not get node-count for path '%s': %v", path, h.Error)
 	}
@@ -144,14 +147,14 @@ func (r *dbBookmarkRepository) GetPathChildCount(path, username string) ([]NodeC
 }
 
 // GetAllPaths returns all available paths for the given username
-func (r *dbBookmarkRepository) GetAllPaths(username string) ([]string, error) {
-	return r.availablePaths(username)
+func (r *dbBookmarkRepository) GetAllPaths(ctx context.Context, username string) ([]string, error) {
+	return r.availablePaths(ctx, username)
 }
 
 // NumBookmarksReferencingFavicon returns the number of bookmarks which use the same favicon
-func (r *dbBookmarkRepository) NumBookmarksReferencingFavicon(faviconID, username string) (int, error) {
+func (r *dbBookmarkRepository) NumBookmarksReferencingFavicon(ctx context.Context, faviconID, username string) (int, error) {
 	var num int64
-	h := r.con.R().Model(&Bookmark{}).Where("favicon = ?", faviconID).Count(&num)
+	h := r.con.R().WithContext(ctx).Model(&Bookmark{}).Where("favicon = ?", faviconID).Count(&num)
 	return int(num), h.Error
 
 }
@@ -159,16 +162,16 @@ func (r *dbBookmarkRepository) NumBookmarksReferencingFavicon(faviconID, usernam
 // modify data
 // --------------------------------------------------------------------------
 
-// InUnitOfWork is used to perform logic in a transactional context
-func (r *dbBookmarkRepository) InUnitOfWork(handle func(repo BookmarkRepository) error) error {
-	return r.con.Begin(func(con persistence.Connection) error {
+// InUnitOfWork is used to perform logic in a transactional context, the transaction is bound to ctx
+func (r *dbBookmarkRepository) InUnitOfWork(ctx context.Context, handle func(repo BookmarkRepository) error) error {
+	return r.con.BeginContext(ctx, func(con persistence.Connection) error {
 		repo := CreateBookmarkRepo(con, r.logger)
 		return handle(repo)
 	})
 }
 
 // Create is used to save a new bookmark entry
-func (r *dbBookmarkRepository) Create(item Bookmark) (Bookmark, error) {
+func (r *dbBookmarkRepository) Create(ctx context.Context, item Bookmark) (Bookmark, error) {
 	var (
 		err       error
 		hierarchy []string
@@ -190,7 +193,7 @@ func (r *dbBookmarkRepository) Create(item Bookmark) (Bookmark, error) {
 	// the solution is to query the whole hierarchy and check if the given path is there
 
 	if item.Path != "/" {
-		hierarchy, err = r.availablePaths(item.UserName)
+		hierarchy, err = r.availablePaths(ctx, item.UserName)
 		if err != nil {
 			return Bookmark{}, err
 		}
@@ -207,7 +210,7 @@ func (r *dbBookmarkRepository) Create(item Bookmark) (Bookmark, error) {
 		}
 	}
 
-	if h := r.con.W().Create(&item); h.Error != nil {
+	if h := r.con.W().WithContext(ctx).Create(&item); h.Error != nil {
 		return Bookmark{}, h.Error
 	}
 
@@ -215,7 +218,7 @@ func (r *dbBookmarkRepository) Create(item Bookmark) (Bookmark, error) {
 	// for this given path, and update the "parent" directory entry.
 	// exception: if the path is ROOT, '/' no update needs to be done, because no dedicated ROOT, '/' entry
 	if item.Path != "/" {
-		err = r.calcChildCount(item.Path, item.UserName, func(c int) int {
+		err = r.calcChildCount(ctx, item.Path, item.UserName, func(c int) int {
 			return c + 1
 		})
 		if err != nil {
@@ -227,7 +230,7 @@ func (r *dbBookmarkRepository) Create(item Bookmark) (Bookmark, error) {
 }
 
 // Update changes an existing bookmark item
-func (r *dbBookmarkRepository) Update(item Bookmark) (Bookmark, error) {
+func (r *dbBookmarkRepository) Update(ctx context.Context, item Bookmark) (Bookmark, error) {
 	var (
 		err       error
 		hierarchy []string
@@ -238,7 +241,7 @@ func (r *dbBookmarkRepository) Update(item Bookmark) (Bookmark, error) {
 		return Bookmark{}, fmt.Errorf("path is empty")
 	}
 
-	h := r.con.R().Where(&Bookmark{ID: item.ID, UserName: item.UserName}).First(&bm)
+	h := r.con.R().WithContext(ctx).Where(&Bookmark{ID: item.ID, UserName: item.UserName}).First(&bm)
 	if h.Error != nil {
 		return Bookmark{}, fmt.Errorf("cannot get bookmark by id '%s': %v", item.ID, h.Error)
 	}
@@ -250,7 +253,7 @@ func (r *dbBookmarkRepository) Update(item Bookmark) (Bookmark, error) {
 	// the solution is to query the whole hierarchy and check if the given path is there
 
 	if item.Path != "/" {
-		hierarchy, err = r.availablePaths(item.UserName)
+		hierarchy, err = r.availablePaths(ctx, item.UserName)
 		if err != nil {
 			return Bookmark{}, err
 		}
@@ -279,7 +282,7 @@ func (r *dbBookmarkRepository) Update(item Bookmark) (Bookmark, error) {
 	bm.InvertFaviconColor = item.InvertFaviconColor
 	bm.FileID = item.FileID
 
-	h = r.con.W().Save(&bm)
+	h = r.con.W().WithContext(ctx).Save(&bm)
 	if h.Error != nil {
 		return Bookmark{}, fmt.Errorf("cannot update bookmark with id '%s': %v", item.ID, h.Error)
 	}
@@ -287,13 +290,13 @@ func (r *dbBookmarkRepository) Update(item Bookmark) (Bookmark, error) {
 }
 
 // Delete removes the bookmark identified by id
-func (r *dbBookmarkRepository) Delete(item Bookmark) error {
+func (r *dbBookmarkRepository) Delete(ctx context.Context, item Bookmark) error {
 	var (
 		bm  Bookmark
 		err error
 	)
 
-	h := r.con.R().Where(&Bookmark{ID: item.ID, UserName: item.UserName}).First(&bm)
+	h := r.con.R().WithContext(ctx).Where(&Bookmark{ID: item.ID, UserName: item.UserName}).First(&bm)
 	if h.Error != nil {
 		return fmt.Errorf("cannot get bookmark by id '%s': %v", item.ID, h.Error)
 	}
@@ -303,7 +306,7 @@ func (r *dbBookmarkRepository) Delete(item Bookmark) error {
 	// one item is removed from a given path, decrement the child-count for
 	// the folder / path this item is located in
 	if item.Path != "/" {
-		err = r.calcChildCount(item.Path, item.UserName, func(c int) int {
+		err = r.calcChildCount(ctx, item.Path, item.UserName, func(c int) int {
 			return c - 1
 		})
 		if err != nil {
@@ -311,7 +314,7 @@ func (r *dbBookmarkRepository) Delete(item Bookmark) error {
 		}
 	}
 
-	h = r.con.W().Delete(&bm)
+	h = r.con.W().WithContext(ctx).Delete(&bm)
 	if h.Error != nil {
 		return fmt.Errorf("cannot delete bookmark by id '%s': %v", item.ID, h.Error)
 	}
@@ -319,7 +322,7 @@ func (r *dbBookmarkRepository) Delete(item Bookmark) error {
 }
 
 // DeletePath removes all bookmarks having the same path
-func (r *dbBookmarkRepository) DeletePath(path, username string) error {
+func (r *dbBookmarkRepository) DeletePath(ctx context.Context, path, username string) error {
 
 	if path == "" {
 		return fmt.Errorf("path is empty")
@@ -328,16 +331,16 @@ func (r *dbBookmarkRepository) DeletePath(path, username string) error {
 		return fmt.Errorf("cannot delete the root path")
 	}
 
-	h := r.con.R().Where("user_name = ? AND path LIKE ?", username, path+"%").Delete(Bookmark{})
+	h := r.con.R().WithContext(ctx).Where("user_name = ? AND path LIKE ?", username, path+"%").Delete(Bookmark{})
 	if h.Error != nil {
 		return fmt.Errorf("no bookmarks available for path '%s': %v", path, h.Error)
 	}
 
-	folder, err := r.GetFolderByPath(path, username)
+	folder, err := r.GetFolderByPath(ctx, path, username)
 	if err != nil {
 		return fmt.Errorf("could not get folder of given path '%s'", path)
 	}
-	h = r.con.W().Delete(&folder)
+	h = r.con.W().WithContext(ctx).Delete(&folder)
 	if h.Error != nil {
 		return fmt.Errorf("cannot delete folder '%s': %v", path, h.Error)
 	}
@@ -349,11 +352,11 @@ func (r *dbBookmarkRepository) DeletePath(path, username string) error {
 	}
 
 	if parentPath != "/" {
-		parentFolder, err := r.GetFolderByPath(parentPath, username)
+		parentFolder, err := r.GetFolderByPath(ctx, parentPath, username)
 		if err != nil {
 			return fmt.Errorf("could not get folder of given path '%s'", parentPath)
 		}
-		nodes, err := r.GetPathChildCount(parentPath, username)
+		nodes, err := r.GetPathChildCount(ctx, parentPath, username)
 		if err != nil {
 			return fmt.Errorf("could not get child-count of given path '%s'", parentPath)
 		}
@@ -363,7 +366,7 @@ func (r *dbBookmarkRepository) DeletePath(path, username string) error {
 			count = nodes[0].Count
 		}
 
-		return r.updateChildCount(&parentFolder, count)
+		return r.updateChildCount(ctx, &parentFolder, count)
 	}
 
 	return nil
@@ -373,8 +376,8 @@ func (r *dbBookmarkRepository) DeletePath(path, username string) error {
 // internal logic / helpers
 // --------------------------------------------------------------------------
 
-func (r *dbBookmarkRepository) updateChildCount(folder *Bookmark, count int) error {
-	if h := r.con.W().Model(folder).Updates(
+func (r *dbBookmarkRepository) updateChildCount(ctx context.Context, folder *Bookmark, count int) error {
+	if h := r.con.W().WithContext(ctx).Model(folder).Updates(
 		map[string]interface{}{"child_count": count, "modified": time.Now().UTC()}); h.Error != nil {
 		return fmt.Errorf("cannot update item '%+v': %v", *folder, h.Error)
 	}
@@ -397,12 +400,12 @@ SELECT a.path || '/' || a.display_name FROM (
 ) a
 GROUP BY a.path || '/' || a.display_name`
 
-func (r *dbBookmarkRepository) availablePaths(username string) (paths []string, err error) {
+func (r *dbBookmarkRepository) availablePaths(ctx context.Context, username string) (paths []string, err error) {
 	var (
 		rows *sql.Rows
 	)
 
-	rows, err = r.con.R().Raw(nativeHierarchyQuery, Folder, username).Rows() // (*sql.Rows, error)
+	rows, err = r.con.R().WithContext(ctx).Raw(nativeHierarchyQuery, Folder, username).Rows() // (*sql.Rows, error)
 	defer func(ro *sql.Rows) {
 		if ro != nil {
 			err = ro.Close()
@@ -423,7 +426,7 @@ func (r *dbBookmarkRepository) availablePaths(username string) (paths []string,
 	return paths, nil
 }
 
-func (r *dbBookmarkRepository) calcChildCount(path, username string, fn func(i int) int) error {
+func (r *dbBookmarkRepository) calcChildCount(ctx context.Context, path, username string, fn func(i int) int) error {
 	// the supplied path is of the form
 	// /A/B/C => get the entry C (which is a folder) and inc/dec the child-count
 	parentPath, parentName, ok := pathAndFolder(path)
@@ -431,7 +434,7 @@ func (r *dbBookmarkRepository) calcChildCount(path, username string, fn func(i i
 		return fmt.Errorf("invalid path encountered '%s'", path)
 	}
 	var bm Bookmark
-	if h := r.con.R().Where(&Bookmark{
+	if h := r.con.R().WithContext(ctx).Where(&Bookmark{
 		UserName:    username,
 		Path:        parentPath,
 		Type:        Folder,
@@ -441,7 +444,7 @@ func (r *dbBookmarkRepository) calcChildCount(path, username string, fn func(i i
 
 	// update the found item
 	count := fn(bm.ChildCount)
-	return r.updateChildCount(&bm, count)
+	return r.updateChildCount(ctx, &bm, count)
 }
 
 func pathAndFolder(fullPath string) (path string, folder string, valid bool) {
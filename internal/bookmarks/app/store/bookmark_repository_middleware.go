@@ -0,0 +1,116 @@
+package store
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"golang.binggl.net/monorepo/pkg/tracing"
+)
+
+// BookmarkRepositoryMiddleware is used to intercept the repository methods
+type BookmarkRepositoryMiddleware func(BookmarkRepository) BookmarkRepository
+
+// BookmarkRepositoryTracingMiddleware creates a child-span for every repository call
+func BookmarkRepositoryTracingMiddleware() BookmarkRepositoryMiddleware {
+	return func(next BookmarkRepository) BookmarkRepository {
+		return tracingMiddleware{next}
+	}
+}
+
+// compile guard for BookmarkRepository implementation
+var (
+	_ BookmarkRepository = &tracingMiddleware{}
+)
+
+type tracingMiddleware struct {
+	next BookmarkRepository
+}
+
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, func(err error)) {
+	ctx, span := tracing.Start(ctx, "BookmarkRepository."+name, attrs...)
+	return ctx, func(err error) { tracing.End(span, err) }
+}
+
+func (t tracingMiddleware) Create(ctx context.Context, item Bookmark) (b Bookmark, err error) {
+	ctx, end := startSpan(ctx, "Create", attribute.String("bookmark.path", item.Path))
+	defer func() { end(err) }()
+	return t.next.Create(ctx, item)
+}
+
+func (t tracingMiddleware) Update(ctx context.Context, item Bookmark) (b Bookmark, err error) {
+	ctx, end := startSpan(ctx, "Update", attribute.String("bookmark.id", item.ID))
+	defer func() { end(err) }()
+	return t.next.Update(ctx, item)
+}
+
+func (t tracingMiddleware) Delete(ctx context.Context, item Bookmark) (err error) {
+	ctx, end := startSpan(ctx, "Delete", attribute.String("bookmark.id", item.ID))
+	defer func() { end(err) }()
+	return t.next.Delete(ctx, item)
+}
+
+func (t tracingMiddleware) DeletePath(ctx context.Context, path, username string) (err error) {
+	ctx, end := startSpan(ctx, "DeletePath", attribute.String("bookmark.path", path))
+	defer func() { end(err) }()
+	return t.next.DeletePath(ctx, path, username)
+}
+
+func (t tracingMiddleware) InUnitOfWork(ctx context.Context, handle func(repo BookmarkRepository) error) (err error) {
+	ctx, end := startSpan(ctx, "InUnitOfWork")
+	defer func() { end(err) }()
+	return t.next.InUnitOfWork(ctx, handle)
+}
+
+func (t tracingMiddleware) GetAllBookmarks(ctx context.Context, username string) (b []Bookmark, err error) {
+	ctx, end := startSpan(ctx, "GetAllBookmarks")
+	defer func() { end(err) }()
+	return t.next.GetAllBookmarks(ctx, username)
+}
+
+func (t tracingMiddleware) GetBookmarksByPath(ctx context.Context, path, username string) (b []Bookmark, err error) {
+	ctx, end := startSpan(ctx, "GetBookmarksByPath", attribute.String("bookmark.path", path))
+	defer func() { end(err) }()
+	return t.next.GetBookmarksByPath(ctx, path, username)
+}
+
+func (t tracingMiddleware) GetBookmarksByPathStart(ctx context.Context, path, username string) (b []Bookmark, err error) {
+	ctx, end := startSpan(ctx, "GetBookmarksByPathStart", attribute.String("bookmark.path", path))
+	defer func() { end(err) }()
+	return t.next.GetBookmarksByPathStart(ctx, path, username)
+}
+
+func (t tracingMiddleware) GetBookmarksByName(ctx context.Context, name, username string) (b []Bookmark, err error) {
+	ctx, end := startSpan(ctx, "GetBookmarksByName")
+	defer func() { end(err) }()
+	return t.next.GetBookmarksByName(ctx, name, username)
+}
+
+func (t tracingMiddleware) GetPathChildCount(ctx context.Context, path, username string) (n []NodeCount, err error) {
+	ctx, end := startSpan(ctx, "GetPathChildCount", attribute.String("bookmark.path", path))
+	defer func() { end(err) }()
+	return t.next.GetPathChildCount(ctx, path, username)
+}
+
+func (t tracingMiddleware) GetAllPaths(ctx context.Context, username string) (p []string, err error) {
+	ctx, end := startSpan(ctx, "GetAllPaths")
+	defer func() { end(err) }()
+	return t.next.GetAllPaths(ctx, username)
+}
+
+func (t tracingMiddleware) GetBookmarkByID(ctx context.Context, id, username string) (b Bookmark, err error) {
+	ctx, end := startSpan(ctx, "GetBookmarkByID", attribute.String("bookmark.id", id))
+	defer func() { end(err) }()
+	return t.next.GetBookmarkByID(ctx, id, username)
+}
+
+func (t tracingMiddleware) GetFolderByPath(ctx context.Context, path, username string) (b Bookmark, err error) {
+	ctx, end := startSpan(ctx, "GetFolderByPath", attribute.String("bookmark.path", path))
+	defer func() { end(err) }()
+	return t.next.GetFolderByPath(ctx, path, username)
+}
+
+func (t tracingMiddleware) NumBookmarksReferencingFavicon(ctx context.Context, faviconID, username string) (n int, err error) {
+	ctx, end := startSpan(ctx, "NumBookmarksReferencingFavicon", attribute.String("favicon.id", faviconID))
+	defer func() { end(err) }()
+	return t.next.NumBookmarksReferencingFavicon(ctx, faviconID, username)
+}
@@ -1,6 +1,7 @@
 package store_test
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"math/rand"
@@ -44,7 +45,7 @@ func TestGetAllBookmarks(t *testing.T) {
 	defer db.Close()
 
 	userName := "test"
-	bookmarks, err := repo.GetAllBookmarks(userName)
+	bookmarks, err := repo.GetAllBookmarks(context.TODO(), userName)
 	if err != nil {
 		t.Errorf("Could not get bookmarks: %v", err)
 	}
@@ -67,7 +68,7 @@ func TestCreateBookmark(t *testing.T) {
 		UserName:           userName,
 		InvertFaviconColor: 1,
 	}
-	bm, err := repo.Create(item)
+	bm, err := repo.Create(context.TODO(), item)
 	if err != nil {
 		t.Errorf("Could not create bookmarks: %v", err)
 	}
@@ -79,7 +80,7 @@ func TestCreateBookmark(t *testing.T) {
 
 	// check item.Path empty error
 	item.Path = ""
-	bm, err = repo.Create(item)
+	bm, err = repo.Create(context.TODO(), item)
 	if err == nil {
 		t.Errorf("Expected error on empty path!")
 	}
@@ -102,13 +103,13 @@ func TestCreatBookmarkAndHierarchy(t *testing.T) {
 		Type:        store.Folder,
 		UserName:    userName,
 	}
-	bm, err := repo.Create(folder)
+	bm, err := repo.Create(context.TODO(), folder)
 	if err != nil {
 		t.Errorf("Could not create bookmarks: %v", err)
 	}
 	assert.NotEmpty(t, bm.ID)
 
-	_, err = repo.GetBookmarkByID(bm.ID, userName)
+	_, err = repo.GetBookmarkByID(context.TODO(), bm.ID, userName)
 	if err != nil {
 		t.Errorf("Could not read bookmarks: %v", err)
 	}
@@ -121,13 +122,13 @@ func TestCreatBookmarkAndHierarchy(t *testing.T) {
 		URL:         "http://url",
 		UserName:    userName,
 	}
-	bm, err = repo.Create(node)
+	bm, err = repo.Create(context.TODO(), node)
 	if err != nil {
 		t.Errorf("Could not create bookmarks: %v", err)
 	}
 	assert.NotEmpty(t, bm.ID)
 
-	n, err := repo.GetBookmarkByID(bm.ID, userName)
+	n, err := repo.GetBookmarkByID(context.TODO(), bm.ID, userName)
 	if err != nil {
 		t.Errorf("Could not read bookmarks: %v", err)
 	}
@@ -140,7 +141,7 @@ func TestCreatBookmarkAndHierarchy(t *testing.T) {
 
 	// error creating node because of missing folder
 	node.Path = "/unknown/folder"
-	bm, err = repo.Create(node)
+	bm, err = repo.Create(context.TODO(), node)
 	if err == nil {
 		t.Errorf("Expected error for unknown path!")
 	}
@@ -161,14 +162,14 @@ func TestCreateBookmarkInUnitOfWork(t *testing.T) {
 	}
 
 	id := ""
-	err := repo.InUnitOfWork(func(r store.BookmarkRepository) error {
-		bm, err := r.Create(folder)
+	err := repo.InUnitOfWork(context.TODO(), func(r store.BookmarkRepository) error {
+		bm, err := r.Create(context.TODO(), folder)
 		if err != nil {
 			return err
 		}
 		assert.NotEmpty(t, bm.ID)
 		id = bm.ID
-		folder, err = r.GetBookmarkByID(bm.ID, bm.UserName)
+		folder, err = r.GetBookmarkByID(context.TODO(), bm.ID, bm.UserName)
 		if err != nil {
 			return err
 		}
@@ -180,7 +181,7 @@ func TestCreateBookmarkInUnitOfWork(t *testing.T) {
 	}
 
 	// get the created entry "out-of" the transaction
-	folder, err = repo.GetBookmarkByID(id, userName)
+	folder, err = repo.GetBookmarkByID(context.TODO(), id, userName)
 	if err != nil {
 		t.Errorf("could not get the created folder by id: %v", err)
 	}
@@ -195,14 +196,14 @@ func TestCreateBookmarkInUnitOfWork(t *testing.T) {
 		UserName:    userName,
 	}
 	var bm store.Bookmark
-	err = repo.InUnitOfWork(func(r store.BookmarkRepository) error {
-		bm, err = r.Create(folderRollback)
+	err = repo.InUnitOfWork(context.TODO(), func(r store.BookmarkRepository) error {
+		bm, err = r.Create(context.TODO(), folderRollback)
 		if err != nil {
 			return err
 		}
 		assert.NotEmpty(t, bm.ID)
 		id = bm.ID
-		folder, err = r.GetBookmarkByID(bm.ID, bm.UserName)
+		folder, err = r.GetBookmarkByID(context.TODO(), bm.ID, bm.UserName)
 		if err != nil {
 			return err
 		}
@@ -214,10 +215,21 @@ func TestCreateBookmarkInUnitOfWork(t *testing.T) {
 	}
 
 	// get the created entry "out-of" the transaction
-	folder, err = repo.GetBookmarkByID(id, userName)
+	folder, err = repo.GetBookmarkByID(context.TODO(), id, userName)
 	if err == nil {
 		t.Errorf("expected an error because item not available!")
 	}
+
+	// the transaction is bound to the context
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	called := false
+	err = repo.InUnitOfWork(ctx, func(r store.BookmarkRepository) error {
+		called = true
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, called)
 }
 
 func TestUpdateBookmark(t *testing.T) {
@@ -233,7 +245,7 @@ func TestUpdateBookmark(t *testing.T) {
 		URL:         "http://url",
 		UserName:    userName,
 	}
-	bm, err := repo.Create(item)
+	bm, err := repo.Create(context.TODO(), item)
 	if err != nil {
 		t.Errorf("Could not create bookmarks: %v", err)
 	}
@@ -244,7 +256,7 @@ func TestUpdateBookmark(t *testing.T) {
 
 	bm.DisplayName = bm.DisplayName + "_update"
 
-	bm, err = repo.Update(bm)
+	bm, err = repo.Update(context.TODO(), bm)
 	if err != nil {
 		t.Errorf("Could not update bookmarks: %v", err)
 	}
@@ -252,14 +264,14 @@ func TestUpdateBookmark(t *testing.T) {
 
 	// update with empty-path
 	bm.Path = ""
-	bm, err = repo.Update(bm)
+	bm, err = repo.Update(context.TODO(), bm)
 	if err == nil {
 		t.Errorf("Expected error because of empty path")
 	}
 
 	// update with unknown-path
 	bm.Path = "/unknown/path"
-	bm, err = repo.Update(bm)
+	bm, err = repo.Update(context.TODO(), bm)
 	if err == nil {
 		t.Errorf("Expected error because of unknown path")
 	}
@@ -282,7 +294,7 @@ func TestDeleteBookmark(t *testing.T) {
 		Type:        store.Folder,
 		UserName:    userName,
 	}
-	folder, err := repo.Create(f)
+	folder, err := repo.Create(context.TODO(), f)
 	if err != nil {
 		t.Errorf("Could not create bookmarks: %v", err)
 	}
@@ -296,26 +308,26 @@ func TestDeleteBookmark(t *testing.T) {
 		URL:         "http://url",
 		UserName:    userName,
 	}
-	node, err := repo.Create(n)
+	node, err := repo.Create(context.TODO(), n)
 	if err != nil {
 		t.Errorf("Could not create bookmarks: %v", err)
 	}
 	assert.NotEmpty(t, node.ID)
 
-	folder, err = repo.GetBookmarkByID(folder.ID, folder.UserName)
+	folder, err = repo.GetBookmarkByID(context.TODO(), folder.ID, folder.UserName)
 	if err != nil {
 		t.Errorf("Could not read bookmark folder: %v", err)
 	}
 	assert.Equal(t, 1, folder.ChildCount)
 
 	// remove the node
-	err = repo.Delete(node)
+	err = repo.Delete(context.TODO(), node)
 	if err != nil {
 		t.Errorf("Could not delete bookmark: %v", err)
 	}
 
 	// child-count has to be 0 again
-	folder, err = repo.GetBookmarkByID(folder.ID, folder.UserName)
+	folder, err = repo.GetBookmarkByID(context.TODO(), folder.ID, folder.UserName)
 	if err != nil {
 		t.Errorf("Could not read bookmark folder: %v", err)
 	}
@@ -331,7 +343,7 @@ func TestGetPathChildCount(t *testing.T) {
 	userName := "userName"
 
 	// create a folder structure
-	_, err := repo.Create(store.Bookmark{
+	_, err := repo.Create(context.TODO(), store.Bookmark{
 		Type:        store.Folder,
 		DisplayName: "Folder",
 		UserName:    userName,
@@ -340,7 +352,7 @@ func TestGetPathChildCount(t *testing.T) {
 	if err != nil {
 		t.Errorf(errStr, err)
 	}
-	_, err = repo.Create(store.Bookmark{
+	_, err = repo.Create(context.TODO(), store.Bookmark{
 		Type:        store.Folder,
 		DisplayName: "Folder1",
 		UserName:    userName,
@@ -349,7 +361,7 @@ func TestGetPathChildCount(t *testing.T) {
 	if err != nil {
 		t.Errorf(errStr, err)
 	}
-	_, err = repo.Create(store.Bookmark{
+	_, err = repo.Create(context.TODO(), store.Bookmark{
 		Type:        store.Node,
 		DisplayName: "Node",
 		UserName:    userName,
@@ -359,7 +371,7 @@ func TestGetPathChildCount(t *testing.T) {
 	if err != nil {
 		t.Errorf(errStr, err)
 	}
-	_, err = repo.Create(store.Bookmark{
+	_, err = repo.Create(context.TODO(), store.Bookmark{
 		Type:        store.Node,
 		DisplayName: "Node1",
 		UserName:    userName,
@@ -371,7 +383,7 @@ func TestGetPathChildCount(t *testing.T) {
 	}
 
 	// Root
-	nodes, err := repo.GetPathChildCount("/", userName)
+	nodes, err := repo.GetPathChildCount(context.TODO(), "/", userName)
 	if err != nil {
 		t.Errorf(errStrNodes, err)
 	}
@@ -380,7 +392,7 @@ func TestGetPathChildCount(t *testing.T) {
 	assert.Equal(t, 2, nodes[0].Count)
 
 	// Folder
-	nodes, err = repo.GetPathChildCount("/Folder", userName)
+	nodes, err = repo.GetPathChildCount(context.TODO(), "/Folder", userName)
 	if err != nil {
 		t.Errorf(errStrNodes, err)
 	}
@@ -389,7 +401,7 @@ func TestGetPathChildCount(t *testing.T) {
 	assert.Equal(t, 1, nodes[0].Count)
 
 	// Folder1
-	nodes, err = repo.GetPathChildCount("/Folder1", userName)
+	nodes, err = repo.GetPathChildCount(context.TODO(), "/Folder1", userName)
 	if err != nil {
 		t.Errorf(errStrNodes, err)
 	}
@@ -398,7 +410,7 @@ func TestGetPathChildCount(t *testing.T) {
 	assert.Equal(t, 1, nodes[0].Count)
 
 	// no path
-	_, err = repo.GetPathChildCount("", userName)
+	_, err = repo.GetPathChildCount(context.TODO(), "", userName)
 	if err == nil {
 		t.Errorf("expected error because of missing path")
 	}
@@ -412,7 +424,7 @@ func TestDeletePath(t *testing.T) {
 	userName := "userName"
 
 	// create a folder structure
-	_, err := repo.Create(store.Bookmark{
+	_, err := repo.Create(context.TODO(), store.Bookmark{
 		Type:        store.Folder,
 		DisplayName: "Folder",
 		UserName:    userName,
@@ -421,7 +433,7 @@ func TestDeletePath(t *testing.T) {
 	if err != nil {
 		t.Errorf(errStr, err)
 	}
-	_, err = repo.Create(store.Bookmark{
+	_, err = repo.Create(context.TODO(), store.Bookmark{
 		Type:        store.Folder,
 		DisplayName: "Folder1",
 		UserName:    userName,
@@ -430,7 +442,7 @@ func TestDeletePath(t *testing.T) {
 	if err != nil {
 		t.Errorf(errStr, err)
 	}
-	_, err = repo.Create(store.Bookmark{
+	_, err = repo.Create(context.TODO(), store.Bookmark{
 		Type:        store.Node,
 		DisplayName: "Node",
 		UserName:    userName,
@@ -440,7 +452,7 @@ func TestDeletePath(t *testing.T) {
 	if err != nil {
 		t.Errorf(errStr, err)
 	}
-	_, err = repo.Create(store.Bookmark{
+	_, err = repo.Create(context.TODO(), store.Bookmark{
 		Type:        store.Node,
 		DisplayName: "Node1",
 		UserName:    userName,
@@ -451,31 +463,31 @@ func TestDeletePath(t *testing.T) {
 		t.Errorf(errStr, err)
 	}
 
-	all, err := repo.GetAllBookmarks(userName)
+	all, err := repo.GetAllBookmarks(context.TODO(), userName)
 	if err != nil {
 		t.Errorf("could not get all bookmarks: %v", err)
 	}
 	assert.Equal(t, 4, len(all))
 
 	// Delete the whole folder-path /Folder1 (1 x Node + 1 x Folder1)
-	err = repo.DeletePath("/Folder1", userName)
+	err = repo.DeletePath(context.TODO(), "/Folder1", userName)
 	if err != nil {
 		t.Errorf("could not delete folder path /Folder1: %v", err)
 	}
 
-	all, err = repo.GetAllBookmarks(userName)
+	all, err = repo.GetAllBookmarks(context.TODO(), userName)
 	if err != nil {
 		t.Errorf("could not get all bookmarks: %v", err)
 	}
 	assert.Equal(t, 2, len(all))
 
 	// Delete the whole folder-path /Folder (1 x Node + 1 x Folder)
-	err = repo.DeletePath("/Folder", userName)
+	err = repo.DeletePath(context.TODO(), "/Folder", userName)
 	if err != nil {
 		t.Errorf("could not delete folder path /Folder: %v", err)
 	}
 
-	all, err = repo.GetAllBookmarks(userName)
+	all, err = repo.GetAllBookmarks(context.TODO(), userName)
 	if err != nil {
 		t.Errorf("could not get all bookmarks: %v", err)
 	}
@@ -490,7 +502,7 @@ func TestDeletePath2(t *testing.T) {
 	userName := "userName"
 
 	// create a folder structure
-	folder, err := repo.Create(store.Bookmark{
+	folder, err := repo.Create(context.TODO(), store.Bookmark{
 		Type:        store.Folder,
 		DisplayName: "Folder",
 		UserName:    userName,
@@ -499,7 +511,7 @@ func TestDeletePath2(t *testing.T) {
 	if err != nil {
 		t.Errorf(errStr, err)
 	}
-	_, err = repo.Create(store.Bookmark{
+	_, err = repo.Create(context.TODO(), store.Bookmark{
 		Type:        store.Folder,
 		DisplayName: "Folder1",
 		UserName:    userName,
@@ -508,7 +520,7 @@ func TestDeletePath2(t *testing.T) {
 	if err != nil {
 		t.Errorf(errStr, err)
 	}
-	_, err = repo.Create(store.Bookmark{
+	_, err = repo.Create(context.TODO(), store.Bookmark{
 		Type:        store.Node,
 		DisplayName: "Node",
 		UserName:    userName,
@@ -518,7 +530,7 @@ func TestDeletePath2(t *testing.T) {
 	if err != nil {
 		t.Errorf(errStr, err)
 	}
-	_, err = repo.Create(store.Bookmark{
+	_, err = repo.Create(context.TODO(), store.Bookmark{
 		Type:        store.Node,
 		DisplayName: "Node1",
 		UserName:    userName,
@@ -529,37 +541,37 @@ func TestDeletePath2(t *testing.T) {
 		t.Errorf(errStr, err)
 	}
 
-	all, err := repo.GetAllBookmarks(userName)
+	all, err := repo.GetAllBookmarks(context.TODO(), userName)
 	if err != nil {
 		t.Errorf("could not get all bookmarks: %v", err)
 	}
 	assert.Equal(t, 4, len(all))
 
 	// get the child-count of folder
-	bm, err := repo.GetBookmarkByID(folder.ID, folder.UserName)
+	bm, err := repo.GetBookmarkByID(context.TODO(), folder.ID, folder.UserName)
 	if err != nil {
 		t.Errorf("could not get bookmark by id %s: %v", folder.ID, err)
 	}
 	assert.Equal(t, 2, bm.ChildCount)
 
 	// Delete the whole folder-path /Folder/Folder1 (1 x Node + 1 x Folder1)
-	err = repo.DeletePath("/Folder/Folder1", userName)
+	err = repo.DeletePath(context.TODO(), "/Folder/Folder1", userName)
 	if err != nil {
 		t.Errorf("could not delete folder path /Folder1: %v", err)
 	}
 
-	bm, err = repo.GetBookmarkByID(folder.ID, folder.UserName)
+	bm, err = repo.GetBookmarkByID(context.TODO(), folder.ID, folder.UserName)
 	if err != nil {
 		t.Errorf("could not get bookmark by id %s: %v", folder.ID, err)
 	}
 	assert.Equal(t, 1, bm.ChildCount)
 
 	// error checks
-	err = repo.DeletePath("", userName)
+	err = repo.DeletePath(context.TODO(), "", userName)
 	if err == nil {
 		t.Errorf("expected error empty path")
 	}
-	err = repo.DeletePath("/", userName)
+	err = repo.DeletePath(context.TODO(), "/", userName)
 	if err == nil {
 		t.Errorf("expected error ROOT path")
 	}
@@ -573,7 +585,7 @@ func TestGetBookmarksByPath(t *testing.T) {
 	userName := "userName"
 
 	// create a folder structure
-	_, err := repo.Create(store.Bookmark{
+	_, err := repo.Create(context.TODO(), store.Bookmark{
 		Type:        store.Folder,
 		DisplayName: "Folder",
 		UserName:    userName,
@@ -582,7 +594,7 @@ func TestGetBookmarksByPath(t *testing.T) {
 	if err != nil {
 		t.Errorf(errStr, err)
 	}
-	_, err = repo.Create(store.Bookmark{
+	_, err = repo.Create(context.TODO(), store.Bookmark{
 		Type:        store.Folder,
 		DisplayName: "Folder1",
 		UserName:    userName,
@@ -591,7 +603,7 @@ func TestGetBookmarksByPath(t *testing.T) {
 	if err != nil {
 		t.Errorf(errStr, err)
 	}
-	_, err = repo.Create(store.Bookmark{
+	_, err = repo.Create(context.TODO(), store.Bookmark{
 		Type:        store.Folder,
 		DisplayName: "Folder2",
 		UserName:    userName,
@@ -601,7 +613,7 @@ func TestGetBookmarksByPath(t *testing.T) {
 		t.Errorf(errStr, err)
 	}
 
-	bookmarks, err := repo.GetBookmarksByPath("/", userName)
+	bookmarks, err := repo.GetBookmarksByPath(context.TODO(), "/", userName)
 	if err != nil {
 		t.Errorf(errStr, err)
 	}
@@ -609,14 +621,14 @@ func TestGetBookmarksByPath(t *testing.T) {
 	assert.Equal(t, 1, len(bookmarks))
 	assert.Equal(t, "Folder", bookmarks[0].DisplayName)
 
-	bookmarks, err = repo.GetBookmarksByPath("/Folder", userName)
+	bookmarks, err = repo.GetBookmarksByPath(context.TODO(), "/Folder", userName)
 	if err != nil {
 		t.Errorf(errStr, err)
 	}
 	assert.Equal(t, 1, len(bookmarks))
 	assert.Equal(t, "Folder1", bookmarks[0].DisplayName)
 
-	bookmarks, err = repo.GetBookmarksByPathStart("/Folder", userName)
+	bookmarks, err = repo.GetBookmarksByPathStart(context.TODO(), "/Folder", userName)
 	if err != nil {
 		t.Errorf(errStr, err)
 	}
@@ -634,7 +646,7 @@ func TestGetBookmarksByName(t *testing.T) {
 	userName := "userName"
 
 	// create a folder structure
-	_, err := repo.Create(store.Bookmark{
+	_, err := repo.Create(context.TODO(), store.Bookmark{
 		Type:        store.Folder,
 		DisplayName: "Folder",
 		UserName:    userName,
@@ -643,7 +655,7 @@ func TestGetBookmarksByName(t *testing.T) {
 	if err != nil {
 		t.Errorf(errStr, err)
 	}
-	_, err = repo.Create(store.Bookmark{
+	_, err = repo.Create(context.TODO(), store.Bookmark{
 		Type:        store.Node,
 		DisplayName: "Node",
 		UserName:    userName,
@@ -652,7 +664,7 @@ func TestGetBookmarksByName(t *testing.T) {
 	if err != nil {
 		t.Errorf(errStr, err)
 	}
-	_, err = repo.Create(store.Bookmark{
+	_, err = repo.Create(context.TODO(), store.Bookmark{
 		Type:        store.Node,
 		DisplayName: "Node1",
 		UserName:    userName,
@@ -662,19 +674,19 @@ func TestGetBookmarksByName(t *testing.T) {
 		t.Errorf(errStr, err)
 	}
 
-	bookmarks, err := repo.GetBookmarksByName("node", userName)
+	bookmarks, err := repo.GetBookmarksByName(context.TODO(), "node", userName)
 	if err != nil {
 		t.Errorf(errStr1, err)
 	}
 	assert.Equal(t, 2, len(bookmarks))
 
-	bookmarks, err = repo.GetBookmarksByName("Folder", userName)
+	bookmarks, err = repo.GetBookmarksByName(context.TODO(), "Folder", userName)
 	if err != nil {
 		t.Errorf(errStr1, err)
 	}
 	assert.Equal(t, 1, len(bookmarks))
 
-	bookmarks, err = repo.GetBookmarksByName("o", userName)
+	bookmarks, err = repo.GetBookmarksByName(context.TODO(), "o", userName)
 	if err != nil {
 		t.Errorf(errStr1, err)
 	}
@@ -687,7 +699,7 @@ func TestGetAllPath(t *testing.T) {
 
 	userName := "username"
 
-	if _, err := repo.Create(store.Bookmark{
+	if _, err := repo.Create(context.TODO(), store.Bookmark{
 		DisplayName: "Z",
 		Path:        "/",
 		SortOrder:   0,
@@ -697,7 +709,7 @@ func TestGetAllPath(t *testing.T) {
 		t.Errorf("Could not create bookmarks: %v", err)
 	}
 
-	if _, err := repo.Create(store.Bookmark{
+	if _, err := repo.Create(context.TODO(), store.Bookmark{
 		DisplayName: "A",
 		Path:        "/",
 		SortOrder:   0,
@@ -707,7 +719,7 @@ func TestGetAllPath(t *testing.T) {
 		t.Errorf("Could not create bookmarks: %v", err)
 	}
 
-	if _, err := repo.Create(store.Bookmark{
+	if _, err := repo.Create(context.TODO(), store.Bookmark{
 		DisplayName: "B",
 		Path:        "/A",
 		SortOrder:   0,
@@ -717,7 +729,7 @@ func TestGetAllPath(t *testing.T) {
 		t.Errorf("Could not create bookmarks: %v", err)
 	}
 
-	if _, err := repo.Create(store.Bookmark{
+	if _, err := repo.Create(context.TODO(), store.Bookmark{
 		DisplayName: "A",
 		Path:        "/Z",
 		SortOrder:   0,
@@ -727,7 +739,7 @@ func TestGetAllPath(t *testing.T) {
 		t.Errorf("Could not create bookmarks: %v", err)
 	}
 
-	paths, err := repo.GetAllPaths(userName)
+	paths, err := repo.GetAllPaths(context.TODO(), userName)
 	if err != nil {
 		t.Errorf("cannot get all paths: %v", err)
 	}
@@ -741,7 +753,7 @@ func TestGetFolderByPath(t *testing.T) {
 
 	userName := "username"
 
-	if _, err := repo.Create(store.Bookmark{
+	if _, err := repo.Create(context.TODO(), store.Bookmark{
 		DisplayName: "Z",
 		Path:        "/",
 		SortOrder:   0,
@@ -751,19 +763,19 @@ func TestGetFolderByPath(t *testing.T) {
 		t.Errorf("Could not create bookmarks: %v", err)
 	}
 
-	folder, err := repo.GetFolderByPath("/Z", userName)
+	folder, err := repo.GetFolderByPath(context.TODO(), "/Z", userName)
 	if err != nil {
 		t.Errorf("cannot get folder for path '%s': %v", "/Z", err)
 	}
 	assert.Equal(t, "/", folder.Path)
 	assert.Equal(t, "Z", folder.DisplayName)
 
-	_, err = repo.GetFolderByPath("/", userName)
+	_, err = repo.GetFolderByPath(context.TODO(), "/", userName)
 	if err == nil {
 		t.Errorf("expected error for path '/'")
 	}
 
-	_, err = repo.GetFolderByPath("", userName)
+	_, err = repo.GetFolderByPath(context.TODO(), "", userName)
 	if err == nil {
 		t.Errorf("expected error for path ''")
 	}
@@ -805,8 +817,8 @@ func TestConcurrentWriteForConnection(t *testing.T) {
 				InvertFaviconColor: 1,
 			}
 
-			errGo := repo.InUnitOfWork(func(r store.BookmarkRepository) error {
-				_, err = r.Create(item)
+			errGo := repo.InUnitOfWork(context.TODO(), func(r store.BookmarkRepository) error {
+				_, err = r.Create(context.TODO(), item)
 				return err
 			})
 
@@ -839,7 +851,7 @@ func TestBookmarksReferencingSameFavicon(t *testing.T) {
 		Type:        store.Folder,
 		UserName:    userName,
 	}
-	bm, err := repo.Create(folder1)
+	bm, err := repo.Create(context.TODO(), folder1)
 	if err != nil {
 		t.Errorf("Could not create bookmarks: %v", err)
 	}
@@ -852,7 +864,7 @@ func TestBookmarksReferencingSameFavicon(t *testing.T) {
 		Type:        store.Folder,
 		UserName:    userName,
 	}
-	bm, err = repo.Create(folder2)
+	bm, err = repo.Create(context.TODO(), folder2)
 	if err != nil {
 		t.Errorf("Could not create bookmarks: %v", err)
 	}
@@ -864,13 +876,13 @@ func TestBookmarksReferencingSameFavicon(t *testing.T) {
 		Type:        store.Folder,
 		UserName:    userName,
 	}
-	bm, err = repo.Create(folder3)
+	bm, err = repo.Create(context.TODO(), folder3)
 	if err != nil {
 		t.Errorf("Could not create bookmarks: %v", err)
 	}
 	assert.NotEmpty(t, bm.ID)
 
-	num, err := repo.NumBookmarksReferencingFavicon(favicon, userName)
+	num, err := repo.NumBookmarksReferencingFavicon(context.TODO(), favicon, userName)
 	if err != nil {
 		t.Errorf("could not count favicons; %v", err)
 	}
@@ -878,7 +890,7 @@ func TestBookmarksReferencingSameFavicon(t *testing.T) {
 		t.Error("expected 2 items to reference the favicon")
 	}
 
-	num, err = repo.NumBookmarksReferencingFavicon("NO_ID", userName)
+	num, err = repo.NumBookmarksReferencingFavicon(context.TODO(), "NO_ID", userName)
 	if err != nil {
 		t.Errorf("could not count favicons; %v", err)
 	}
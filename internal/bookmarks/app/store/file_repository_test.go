@@ -1,6 +1,7 @@
 package store_test
 
 import (
+	"context"
 	"database/sql"
 	"testing"
 
@@ -132,13 +133,13 @@ func Test_Bookmark_Referencing_File(t *testing.T) {
 		InvertFaviconColor: 1,
 		File:               &file,
 	}
-	savedBm, err := bmRepo.Create(item)
+	savedBm, err := bmRepo.Create(context.TODO(), item)
 	if err != nil {
 		t.Errorf("Could not create bookmarks: %v", err)
 	}
 
 	// retrieve the bookmark
-	bm, err := bmRepo.GetBookmarkByID(savedBm.ID, userName)
+	bm, err := bmRepo.GetBookmarkByID(context.TODO(), savedBm.ID, userName)
 	if err != nil {
 		t.Errorf("Could not get bookmarks by ID: %v", err)
 	}
@@ -151,7 +152,7 @@ func Test_Bookmark_Referencing_File(t *testing.T) {
 	}
 
 	// retrieve bookmark again, the file should be nil
-	bm, err = bmRepo.GetBookmarkByID(savedBm.ID, userName)
+	bm, err = bmRepo.GetBookmarkByID(context.TODO(), savedBm.ID, userName)
 	if err != nil {
 		t.Errorf("Could not get bookmarks by ID: %v", err)
 	}
@@ -26,7 +26,20 @@ type HTTPHandlerOptions struct {
 // MakeHTTPHandler creates a new handler implementation which is used together with the HTTP server
 func MakeHTTPHandler(app *bookmarks.Application, logger logging.Logger, opts HTTPHandlerOptions) http.Handler {
 	std, sec := setupRouter(opts, logger)
+	std.Mount("/", sec)
+
+	// use this for development purposes only!
+	develop.SetupDevTokenHandler(std, logger, opts.Config.Environment)
+
+	notFound := MountRoutes(std, sec, app, logger, opts)
+	std.NotFound(notFound)
 
+	return std
+}
+
+// MountRoutes adds the paths of the bookmarks service to the given routers. Public paths are
+// added to std, the secured paths to sec. The returned handler displays the not-found page.
+func MountRoutes(std, sec chi.Router, app *bookmarks.Application, logger logging.Logger, opts HTTPHandlerOptions) http.HandlerFunc {
 	templateHandler := &web.TemplateHandler{
 		TemplateHandler: &handler.TemplateHandler{
 			Logger:    logger,
@@ -40,18 +53,16 @@ func MakeHTTPHandler(app *bookmarks.Application, logger logging.Logger, opts HTT
 		Build:   opts.Build,
 	}
 
-	std.Mount("/", sec)
-
 	// server-side rendered paths
 	// the following paths provide server-rendered UIs
 	// /403 displays a page telling the user that access/permissions are missing
 	std.Get("/bm/403", templateHandler.Show403())
 
-	// use this for development purposes only!
-	develop.SetupDevTokenHandler(std, logger, opts.Config.Environment)
-
 	// /bm performs a server-side rendering
 	// this implementation is to supersede the client/angular-based search interaction
+	// fetching favicons triggers requests to external sites, limit the rate per user
+	faviconRateLimit := server.SetupRateLimit(opts.Config.RateLimit, "favicon", logger)
+
 	sec.Mount("/bm", func() http.Handler {
 		r := chi.NewRouter()
 		r.Get("/search", templateHandler.SearchBookmarks())
@@ -63,8 +74,8 @@ func MakeHTTPHandler(app *bookmarks.Application, logger logging.Logger, opts HTT
 		r.Delete("/DeleteBookmarkForce/{id}", templateHandler.DeleteBookmarkForce())
 		r.Get("/AvailableFaviconsDialog", templateHandler.AvailableFaviconsDialog())
 		r.Post("/FaviconGridPartial", templateHandler.FaviconGridPartial())
-		r.Post("/favicon/page", templateHandler.FetchCustomFaviconFromPage())
-		r.Post("/favicon/url", templateHandler.FetchCustomFaviconURL())
+		r.With(faviconRateLimit).Post("/favicon/page", templateHandler.FetchCustomFaviconFromPage())
+		r.With(faviconRateLimit).Post("/favicon/url", templateHandler.FetchCustomFaviconURL())
 		r.Get("/favicon/{id}", templateHandler.GetFaviconByBookmarkID())
 		r.Get("/favicon/select/{id}", templateHandler.SelectExistingFavicon())
 		r.Get("/favicon/raw/{id}", templateHandler.GetFaviconByID())
@@ -82,14 +93,18 @@ func MakeHTTPHandler(app *bookmarks.Application, logger logging.Logger, opts HTT
 		return r
 	}())
 
-	std.NotFound(templateHandler.Show404())
-
-	return std
+	return templateHandler.Show404()
 }
 
 func setupRouter(opts HTTPHandlerOptions, logger logging.Logger) (router chi.Router, secureRouter chi.Router) {
-	router = server.SetupBasicRouter(opts.BasePath, opts.Config.Cookies, opts.Config.Cors, opts.Config.Assets, logger)
+	router = server.SetupBasicRouter(opts.BasePath, opts.Config.Cookies, opts.Config.Cors, opts.Config.Headers, opts.Config.Assets, logger)
+	secureRouter = chi.NewRouter()
+	secureRouter.Use(JWTInterceptor(opts, logger))
+	return
+}
 
+// JWTInterceptor validates the JWT of requests and requires the claim of the bookmarks service
+func JWTInterceptor(opts HTTPHandlerOptions, logger logging.Logger) func(http.Handler) http.Handler {
 	// add a middleware to "catch" security errors and present a human-readable form
 	// if the client requests "application/json" just use the the problem-json format
 	jwtOptions := security.JwtOptions{
@@ -113,8 +128,5 @@ func setupRouter(opts HTTPHandlerOptions, logger logging.Logger) (router chi.Rou
 		Options:       jwtOptions,
 		ErrorRedirect: "/bm/403",
 	}
-	secureRouter = chi.NewRouter()
-	secureRouter.Use(interceptor.HandleJWT)
-
-	return
+	return interceptor.HandleJWT
 }
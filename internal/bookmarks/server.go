@@ -1,6 +1,7 @@
 package bookmarks
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"path"
@@ -14,37 +15,40 @@ import (
 	"golang.binggl.net/monorepo/pkg/logging"
 	"golang.binggl.net/monorepo/pkg/persistence"
 	"golang.binggl.net/monorepo/pkg/server"
+	"golang.binggl.net/monorepo/pkg/tracing"
 )
 
 // run is the entry-point for the bookmarks service
 // where initialization, setup and execution is done
 func Run(version, build, appName string) error {
 	//hostname, port, _, config := readConfig()
-	hostname, port, basePath, appCfg := server.ReadConfig[conf.AppConfig]("BM")
+	hostname, port, basePath, appCfg, err := server.ReadConfig[conf.AppConfig]("BM")
+	if err != nil {
+		return err
+	}
 	// use the new pkg logger implementation
 	logger := logConfig(appCfg)
 	// ensure closing of log-file on exit
 	defer logger.Close()
 
+	shutdownTracing, err := tracing.Setup(tracing.Options{
+		AppName: appCfg.AppName,
+		Version: version,
+		HostID:  appCfg.HostID,
+		Config:  appCfg.Tracing,
+	})
+	if err != nil {
+		panic(fmt.Sprintf("cannot setup tracing: %v", err))
+	}
+	// flush pending spans on exit
+	defer shutdownTracing(context.Background())
+
 	db := persistence.MustCreateSqliteConn(appCfg.Database.ConnectionString)
 	defer db.Close()
 
+	health := server.NewHealth()
 	var (
-		bRepo, favRepo, fileRepo = setupRepositories(db, logger)
-		uploadSvc                = upload.NewService(upload.ServiceOptions{
-			Logger:           logger,
-			Store:            upload.NewStore(appCfg.Upload.UploadPath),
-			MaxUploadSize:    appCfg.Upload.MaxUploadSize,
-			AllowedFileTypes: appCfg.Upload.AllowedFileTypes,
-		})
-		app = &bookmarks.Application{
-			Logger:        logger,
-			BookmarkStore: bRepo,
-			FavStore:      favRepo,
-			FileStore:     fileRepo,
-			UploadSvc:     uploadSvc,
-			FaviconPath:   path.Join(basePath, appCfg.FaviconUploadPath),
-		}
+		app     = Setup(db, basePath, appCfg, logger, health.Register)
 		handler = MakeHTTPHandler(app, logger, HTTPHandlerOptions{
 			BasePath:  basePath,
 			ErrorPath: appCfg.ErrorPath,
@@ -54,6 +58,11 @@ func Run(version, build, appName string) error {
 		})
 	)
 
+	listen, err := server.NewListenOptions(appCfg.Server)
+	if err != nil {
+		return err
+	}
+
 	// only run the reload-server in development
 	if appCfg.Environment == config.Development {
 		reload := develop.NewReloadServer()
@@ -69,9 +78,38 @@ func Run(version, build, appName string) error {
 		Environment:   string(appCfg.Environment),
 		ServerHandler: handler,
 		Logger:        logger,
+		Health:        health,
+		Listen:        listen,
 	})
 }
 
+// Setup creates the bookmarks application using the given database and registers the
+// readiness checks of its dependencies. It is used by Run and the combined server.
+func Setup(db *sql.DB, basePath string, appCfg conf.AppConfig, logger logging.Logger, register func(string, server.CheckFunc)) *bookmarks.Application {
+	var (
+		bRepo, favRepo, fileRepo = setupRepositories(db, logger)
+		uploadSvc                = upload.NewService(upload.ServiceOptions{
+			Logger:           logger,
+			Store:            upload.NewStore(appCfg.Upload.UploadPath),
+			MaxUploadSize:    appCfg.Upload.MaxUploadSize,
+			AllowedFileTypes: appCfg.Upload.AllowedFileTypes,
+		})
+		app = &bookmarks.Application{
+			Logger:        logger,
+			BookmarkStore: bRepo,
+			FavStore:      favRepo,
+			FileStore:     fileRepo,
+			UploadSvc:     uploadSvc,
+			FaviconPath:   path.Join(basePath, appCfg.FaviconUploadPath),
+		}
+	)
+
+	register("database", server.SqliteCheck(db))
+	register("upload", server.WritableDirCheck(appCfg.Upload.UploadPath))
+	register("favicons", server.WritableDirCheck(app.FaviconPath))
+	return app
+}
+
 func logConfig(cfg conf.AppConfig) logging.Logger {
 	return logging.New(logging.LogConfig{
 		FilePath:      cfg.Logging.FilePath,
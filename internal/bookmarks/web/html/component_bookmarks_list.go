@@ -6,6 +6,7 @@ import (
 
 	"golang.binggl.net/monorepo/internal/bookmarks/app/bookmarks"
 	"golang.binggl.net/monorepo/internal/common"
+	base "golang.binggl.net/monorepo/pkg/handler/html"
 	g "maragu.dev/gomponents"
 	h "maragu.dev/gomponents/html"
 )
@@ -68,7 +69,7 @@ func formatFileSize(size int) string {
 //go:embed copyClipboard.min.js
 var copyClipboard string
 
-func BookmarkList(path string, items []bookmarks.Bookmark, ell EllipsisValues) g.Node {
+func BookmarkList(path string, items []bookmarks.Bookmark, ell EllipsisValues, nonce string) g.Node {
 	return h.Div(h.Class("bookmark_list"), h.ID("bookmark_list"),
 		g.Attr("hx-get", "/bm/partial/~"+path),
 		g.Attr("hx-trigger", "refreshBookmarkList from:body once"),
@@ -140,8 +141,6 @@ func BookmarkList(path string, items []bookmarks.Bookmark, ell EllipsisValues) g
 				)
 			}),
 		),
-		g.El("script", g.Attr("type", "text/javascript"),
-			g.Raw(copyClipboard),
-		),
+		base.Script(nonce, copyClipboard),
 	)
 }
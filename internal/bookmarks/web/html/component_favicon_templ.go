@@ -159,7 +159,7 @@ func FaviconModalDialog(currFaviconID string, favicons []bookmarks.ObjectInfo, s
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 10, "\" autofocus=\"true\" autocomplete=\"off\" hx-post=\"/bm/FaviconGridPartial\" hx-trigger=\"keyup[key=='Enter']\" hx-target=\"#favicon_grid\" hx-swap=\"outerHTML\"></div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 10, "\" autofocus=\"true\" autocomplete=\"off\" hx-post=\"/bm/FaviconGridPartial\" _=\"on keyup[key is 'Enter'] trigger search\" hx-trigger=\"search\" hx-target=\"#favicon_grid\" hx-swap=\"outerHTML\"></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
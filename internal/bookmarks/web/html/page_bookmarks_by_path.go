@@ -3,6 +3,7 @@ package html
 import (
 	_ "embed"
 
+	base "golang.binggl.net/monorepo/pkg/handler/html"
 	g "maragu.dev/gomponents"
 	h "maragu.dev/gomponents/html"
 )
@@ -54,7 +55,7 @@ func BookmarksByPathStyles() g.Node {
 //go:embed sortingLogic.min.js
 var sortingLogic string
 
-func BookmarksByPathNavigation(entries []BookmarkPathEntry) g.Node {
+func BookmarksByPathNavigation(entries []BookmarkPathEntry, nonce string) g.Node {
 	breadcrumbs := make([]g.Node, 0)
 	for i, e := range entries {
 		if e.LastItem {
@@ -142,7 +143,7 @@ func BookmarksByPathNavigation(entries []BookmarkPathEntry) g.Node {
 						),
 					),
 				),
-				g.El("script", g.Attr("type", "text/javascript"), g.Raw(sortingLogic)),
+				base.Script(nonce, sortingLogic),
 			),
 		),
 	)
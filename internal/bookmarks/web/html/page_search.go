@@ -5,11 +5,12 @@ import (
 
 	"golang.binggl.net/monorepo/internal/bookmarks/app/bookmarks"
 	"golang.binggl.net/monorepo/internal/common"
+	base "golang.binggl.net/monorepo/pkg/handler/html"
 	g "maragu.dev/gomponents"
 	h "maragu.dev/gomponents/html"
 )
 
-func SearchContent(search string, items []bookmarks.Bookmark, ell EllipsisValues) g.Node {
+func SearchContent(search string, items []bookmarks.Bookmark, ell EllipsisValues, nonce string) g.Node {
 	return h.Div(h.Class("bookmark_list"),
 		g.Attr("hx-get", "/bm/partial/search?q="+search),
 		g.Attr("hx-trigger", "refreshBookmarkList from:body once"),
@@ -109,9 +110,7 @@ func SearchContent(search string, items []bookmarks.Bookmark, ell EllipsisValues
 						),
 					),
 				),
-				g.El("script", g.Attr("type", "text/javascript"),
-					g.Raw(copyClipboard),
-				),
+				base.Script(nonce, copyClipboard),
 			)
 		}),
 	)
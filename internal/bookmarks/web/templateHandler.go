@@ -32,8 +32,8 @@ type triggerDef struct {
 // https://htmx.org/headers/hx-trigger/
 const htmxHeaderTrigger = "HX-Trigger"
 
-func (t *TemplateHandler) pageModel(pageTitle, searchStr, favicon string, user security.User) base.LayoutModel {
-	return common.CreatePageModel("/bm", pageTitle, searchStr, favicon, t.Version, t.Build, t.Env, user)
+func (t *TemplateHandler) pageModel(r *http.Request, pageTitle, searchStr, favicon string, user security.User) base.LayoutModel {
+	return common.CreatePageModel(r.Context(), "/bm", pageTitle, searchStr, favicon, t.Version, t.Build, t.Env, user)
 }
 
 func queryParam(r *http.Request, name string) string {
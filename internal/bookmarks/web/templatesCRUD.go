@@ -13,6 +13,7 @@ import (
 	"golang.binggl.net/monorepo/internal/common/upload"
 	base "golang.binggl.net/monorepo/pkg/handler/html"
 	"golang.binggl.net/monorepo/pkg/logging"
+	"golang.binggl.net/monorepo/pkg/security"
 )
 
 // DeleteConfirm shows a confirm dialog before an item is deleted
@@ -22,7 +23,7 @@ func (t *TemplateHandler) DeleteConfirm() http.HandlerFunc {
 		user := common.EnsureUser(r)
 		t.Logger.InfoRequest(fmt.Sprintf("get bookmarks by id: '%s' for user: '%s'", id, user.Username), r)
 
-		bm, err := t.App.GetBookmarkByID(id, *user)
+		bm, err := t.App.GetBookmarkByID(r.Context(), id, *user)
 		if err != nil {
 			t.Logger.ErrorRequest(fmt.Sprintf("could not get bookmarks for id '%s'; '%v'", id, err), r)
 		}
@@ -41,11 +42,11 @@ func (t *TemplateHandler) DeleteBookmark() http.HandlerFunc {
 		user := common.EnsureUser(r)
 
 		t.Logger.InfoRequest(fmt.Sprintf("get bookmark by id: '%s' for user: '%s'", id, user.Username), r)
-		bm, err := t.App.GetBookmarkByID(id, *user)
+		bm, err := t.App.GetBookmarkByID(r.Context(), id, *user)
 		if err != nil {
 			t.Logger.ErrorRequest(fmt.Sprintf("could not get bookmark for id '%s'; '%v'", id, err), r)
 		}
-		err = t.App.Delete(bm.ID, *user)
+		err = t.App.Delete(r.Context(), bm.ID, *user)
 		if err != nil {
 			t.Logger.ErrorRequest(fmt.Sprintf("could not delete bookmark with id '%s'; '%v'", id, err), r)
 			triggerRefreshWithToast(w,
@@ -70,7 +71,7 @@ func (t *TemplateHandler) DeleteBookmarkForce() http.HandlerFunc {
 		user := common.EnsureUser(r)
 
 		t.Logger.InfoRequest(fmt.Sprintf("get bookmark by id: '%s' for user: '%s'", id, user.Username), r)
-		bm, err := t.App.GetBookmarkByID(id, *user)
+		bm, err := t.App.GetBookmarkByID(r.Context(), id, *user)
 		if err != nil {
 			t.Logger.ErrorRequest(fmt.Sprintf("could not get bookmark for id '%s'; '%v'", id, err), r)
 		}
@@ -82,7 +83,7 @@ func (t *TemplateHandler) DeleteBookmarkForce() http.HandlerFunc {
 			return
 		}
 
-		err = t.App.DeletePath(bm.ID, *user)
+		err = t.App.DeletePath(r.Context(), bm.ID, *user)
 		if err != nil {
 			t.Logger.ErrorRequest(fmt.Sprintf("could not delete bookmark with id '%s'; '%v'", id, err), r)
 			triggerRefreshWithToast(w,
@@ -123,7 +124,7 @@ func (t *TemplateHandler) EditBookmarkDialog() http.HandlerFunc {
 			bm.TStamp = fmt.Sprintf("%d", time.Now().Unix())
 		} else {
 			// fetch an existing bookmark
-			b, err = t.App.GetBookmarkByID(id, *user)
+			b, err = t.App.GetBookmarkByID(r.Context(), id, *user)
 			if err != nil {
 				t.Logger.ErrorRequest(fmt.Sprintf("could not get bookmarks for id '%s'; '%v'", id, err), r)
 				t.RenderErr(r, w, fmt.Sprintf("could not get bookmarks for id '%s'; '%v'", id, err))
@@ -145,7 +146,7 @@ func (t *TemplateHandler) EditBookmarkDialog() http.HandlerFunc {
 				}
 			}
 
-			paths, err = t.App.GetAllPaths(*user)
+			paths, err = t.App.GetAllPaths(r.Context(), *user)
 			if err != nil {
 				t.Logger.ErrorRequest(fmt.Sprintf("could not get all paths for bookmarks; '%v'", err), r)
 			}
@@ -220,7 +221,7 @@ func (t *TemplateHandler) DeleteBookmarkFile() http.HandlerFunc {
 		}
 
 		user := common.EnsureUser(r)
-		err := t.App.DeleteBookmarkFile(id, *user)
+		err := t.App.DeleteBookmarkFile(r.Context(), id, *user)
 		if err != nil {
 			triggerToast(w, base.MsgError, "Error!", fmt.Sprintf("Could not delete the file: '%v'!", err))
 		}
@@ -268,7 +269,7 @@ func (t *TemplateHandler) SaveBookmark() http.HandlerFunc {
 			recv.Type = bookmarks.FileItem
 		}
 
-		paths, err = t.App.GetAllPaths(*user)
+		paths, err = t.App.GetAllPaths(r.Context(), *user)
 		if err != nil {
 			t.Logger.ErrorRequest(fmt.Sprintf("could not get all paths for bookmarks; '%v'", err), r)
 			t.RenderErr(r, w, fmt.Sprintf("could not get paths for bookmark; '%v'", err))
@@ -341,7 +342,7 @@ func (t *TemplateHandler) SaveBookmark() http.HandlerFunc {
 				// the provided favicon needs to be an ID
 				bm.Favicon = recv.Favicon
 			}
-			created, err := t.App.CreateBookmark(bm, *user)
+			created, err := t.App.CreateBookmark(r.Context(), bm, *user)
 			if err != nil {
 				t.Logger.ErrorRequest(fmt.Sprintf("could not create a new bookmark entry; '%v'", err), r)
 				formBm.Error = "Error: " + err.Error()
@@ -362,7 +363,7 @@ func (t *TemplateHandler) SaveBookmark() http.HandlerFunc {
 		} else {
 
 			// update an exiting entry
-			existing, err := t.App.GetBookmarkByID(recv.ID, *user)
+			existing, err := t.App.GetBookmarkByID(r.Context(), recv.ID, *user)
 			if err != nil {
 				t.Logger.Error("the given bookmark ID is not available", logging.ErrV(err), logging.LogV("ID", recv.ID))
 				t.RenderErr(r, w, fmt.Sprintf("the given bookmark '%s' is not available; %v", recv.ID, err))
@@ -377,7 +378,7 @@ func (t *TemplateHandler) SaveBookmark() http.HandlerFunc {
 			existing.Favicon = recv.Favicon
 			existing.FileID = recv.FileID
 			formBm.TStamp = existing.TStamp()
-			updated, err := t.App.UpdateBookmark(*existing, *user)
+			updated, err := t.App.UpdateBookmark(r.Context(), *existing, *user)
 			if err != nil {
 				t.Logger.ErrorRequest(fmt.Sprintf("could not update bookmark entry '%s'; '%v'", recv.ID, err), r)
 				formBm.Error = "Error: " + err.Error()
@@ -423,7 +424,7 @@ func (t *TemplateHandler) SortBookmarks() http.HandlerFunc {
 			SortOrder: indexList,
 		}
 
-		updates, err := t.App.UpdateSortOrder(sortOrder, *user)
+		updates, err := t.App.UpdateSortOrder(r.Context(), sortOrder, *user)
 		if err != nil {
 
 			triggerToast(w,
@@ -446,7 +447,7 @@ func (t *TemplateHandler) GetBookmarkFile() http.HandlerFunc {
 		id := pathParam(r, "id")
 		user := common.EnsureUser(r)
 
-		bm, err := t.App.GetBookmarkByID(id, *user)
+		bm, err := t.App.GetBookmarkByID(r.Context(), id, *user)
 		if err != nil {
 			t.Logger.ErrorRequest(fmt.Sprintf("could not get bookmark by ID '%s'; '%v'", id, err), r)
 			w.WriteHeader(http.StatusNotFound)
@@ -468,7 +469,7 @@ func (t *TemplateHandler) GetBookmarkFile() http.HandlerFunc {
 			return
 		}
 
-		payload, err := t.App.GetBookmarkPayloadByID(id, *user)
+		payload, err := t.App.GetBookmarkPayloadByID(r.Context(), id, *user)
 		if err != nil {
 			t.Logger.ErrorRequest(fmt.Sprintf("the given bookmark '%s' does not have a file payload.", id), r)
 			w.WriteHeader(http.StatusBadRequest)
@@ -479,6 +480,7 @@ func (t *TemplateHandler) GetBookmarkFile() http.HandlerFunc {
 		// return the file item
 		file := bm.FileMeta
 		w.Header().Add("Content-Type", file.MimeType)
+		security.RestrictPayload(w)
 		http.ServeContent(w, r, file.Name, file.Modified, bytes.NewReader(payload))
 	}
 }
@@ -12,6 +12,7 @@ import (
 	"golang.binggl.net/monorepo/internal/common"
 	base "golang.binggl.net/monorepo/pkg/handler/html"
 	"golang.binggl.net/monorepo/pkg/logging"
+	"golang.binggl.net/monorepo/pkg/security"
 	"golang.binggl.net/monorepo/pkg/text"
 )
 
@@ -34,7 +35,7 @@ func (t *TemplateHandler) AvailableFaviconsDialog() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		user := common.EnsureUser(r)
 		currFavicon := queryParam(r, "current")
-		favicons, err := t.App.GetAvailableFavicons(*user, "")
+		favicons, err := t.App.GetAvailableFavicons(r.Context(), *user, "")
 		if err != nil {
 			t.Logger.Error(fmt.Sprintf("could not get available favicons for user '%s'", user.DisplayName), logging.ErrV(err), logging.LogV("username", user.Username))
 		}
@@ -52,7 +53,7 @@ func (t *TemplateHandler) FaviconGridPartial() http.HandlerFunc {
 			filterFavicon = r.FormValue("search_favicon")
 			t.Logger.Debug("will filter for favicon", logging.LogV("search_favicon", filterFavicon))
 		}
-		favicons, err := t.App.GetAvailableFavicons(*user, filterFavicon)
+		favicons, err := t.App.GetAvailableFavicons(r.Context(), *user, filterFavicon)
 		if err != nil {
 			t.Logger.Error(fmt.Sprintf("could not get available favicons for user '%s'", user.DisplayName), logging.ErrV(err), logging.LogV("username", user.Username))
 		}
@@ -181,12 +182,13 @@ func (t *TemplateHandler) GetFaviconByBookmarkID() http.HandlerFunc {
 		user := common.EnsureUser(r)
 		id := pathParam(r, "id")
 		t.Logger.InfoRequest(fmt.Sprintf("try to get bookmark's favicon with the given ID '%s'", id), r)
-		favicon, err := t.App.GetBookmarkFavicon(id, *user)
+		favicon, err := t.App.GetBookmarkFavicon(r.Context(), id, *user)
 		if err != nil {
 			t.Logger.ErrorRequest(fmt.Sprintf("could not get favicon by ID; '%v'", err), r)
 			w.WriteHeader(http.StatusNotFound)
 			return
 		}
+		security.RestrictPayload(w)
 		http.ServeContent(w, r, favicon.Name, favicon.Modified, bytes.NewReader(favicon.Payload))
 	}
 }
@@ -210,6 +212,7 @@ func (t *TemplateHandler) GetFaviconByID() http.HandlerFunc {
 			w.WriteHeader(http.StatusNotFound)
 			return
 		}
+		security.RestrictPayload(w)
 		http.ServeContent(w, r, favicon.Name, favicon.Modified, bytes.NewReader(favicon.Payload))
 	}
 }
@@ -225,6 +228,7 @@ func (t *TemplateHandler) GetTempFaviconByID() http.HandlerFunc {
 			w.WriteHeader(http.StatusNotFound)
 			return
 		}
+		security.RestrictPayload(w)
 		http.ServeContent(w, r, favicon.Name, favicon.Modified, bytes.NewReader(favicon.Payload))
 	}
 }
@@ -9,6 +9,7 @@ import (
 	"golang.binggl.net/monorepo/internal/bookmarks/web/html"
 	"golang.binggl.net/monorepo/internal/common"
 	base "golang.binggl.net/monorepo/pkg/handler/html"
+	"golang.binggl.net/monorepo/pkg/security"
 )
 
 const searchURL = "/bm/search"
@@ -22,7 +23,7 @@ func (t *TemplateHandler) SearchBookmarks() http.HandlerFunc {
 
 		t.Logger.InfoRequest(fmt.Sprintf("get bookmarks by name: '%s' for user: '%s'", search, user.Username), r)
 
-		bms, err := t.App.GetBookmarksByName(search, *user)
+		bms, err := t.App.GetBookmarksByName(r.Context(), search, *user)
 		if err != nil {
 			t.Logger.ErrorRequest(fmt.Sprintf("could not get bookmarks for search '%s'; '%v'", search, err), r)
 		}
@@ -30,10 +31,10 @@ func (t *TemplateHandler) SearchBookmarks() http.HandlerFunc {
 		ell := html.GetEllipsisValues(r)
 
 		base.Layout(
-			t.pageModel("Bookmark Search", search, "/public/bookmarks.svg", *user),
+			t.pageModel(r, "Bookmark Search", search, "/public/bookmarks.svg", *user),
 			html.SearchStyles(),
 			html.SearchNavigation(search),
-			html.SearchContent(search, bms, ell),
+			html.SearchContent(search, bms, ell, security.NonceFromContext(r.Context())),
 			searchURL,
 		).Render(w)
 	}
@@ -48,12 +49,12 @@ func (t *TemplateHandler) SearchBookmarksPartial() http.HandlerFunc {
 
 		t.Logger.InfoRequest(fmt.Sprintf("get search bookmark-list partial by name: '%s' for user: '%s'", search, user.Username), r)
 
-		bms, err := t.App.GetBookmarksByName(search, *user)
+		bms, err := t.App.GetBookmarksByName(r.Context(), search, *user)
 		if err != nil {
 			t.Logger.ErrorRequest(fmt.Sprintf("could not get bookmarks for search '%s'; '%v'", search, err), r)
 		}
 		ell := html.GetEllipsisValues(r)
-		html.SearchContent(search, bms, ell).Render(w)
+		html.SearchContent(search, bms, ell, security.NonceFromContext(r.Context())).Render(w)
 	}
 }
 
@@ -93,7 +94,7 @@ func (t *TemplateHandler) GetBookmarksForPath() http.HandlerFunc {
 		pathHierarchy[len(pathHierarchy)-1].LastItem = true
 		t.Logger.InfoRequest(fmt.Sprintf("get bookmarks for path: '%s' for user: '%s'", path, user.Username), r)
 
-		bms, err := t.App.GetBookmarksByPath(path, *user)
+		bms, err := t.App.GetBookmarksByPath(r.Context(), path, *user)
 		if err != nil {
 			t.Logger.ErrorRequest(fmt.Sprintf("could not get bookmarks for path '%s'; '%v'", path, err), r)
 			t.RenderErr(r, w, fmt.Sprintf("could not get bookmarks for path '%s'; '%v'", path, err))
@@ -104,7 +105,7 @@ func (t *TemplateHandler) GetBookmarksForPath() http.HandlerFunc {
 		favicon := ""
 		if len(pathParts) > 0 {
 			curFolder = pathParts[len(pathParts)-1]
-			folder, err := t.App.GetBookmarksFolderByPath(path, *user)
+			folder, err := t.App.GetBookmarksFolderByPath(r.Context(), path, *user)
 			if err != nil {
 				t.Logger.ErrorRequest(fmt.Sprintf("could not get bookmark folder for path '%s'; '%v'", path, err), r)
 				t.RenderErr(r, w, fmt.Sprintf("could not get bookmark folder for path '%s'; '%v'", path, err))
@@ -121,10 +122,10 @@ func (t *TemplateHandler) GetBookmarksForPath() http.HandlerFunc {
 
 		ell := html.GetEllipsisValues(r)
 		base.Layout(
-			t.pageModel(curFolder, "", favicon, *user),
+			t.pageModel(r, curFolder, "", favicon, *user),
 			html.BookmarksByPathStyles(),
-			html.BookmarksByPathNavigation(pathHierarchy),
-			html.BookmarkList(path, bms, ell),
+			html.BookmarksByPathNavigation(pathHierarchy, security.NonceFromContext(r.Context())),
+			html.BookmarkList(path, bms, ell, security.NonceFromContext(r.Context())),
 			searchURL,
 		).Render(w)
 	}
@@ -139,11 +140,11 @@ func (t *TemplateHandler) GetBookmarksForPathPartial() http.HandlerFunc {
 
 		t.Logger.InfoRequest(fmt.Sprintf("get bookmark-list partial for path: '%s' for user: '%s'", path, user.Username), r)
 
-		bms, err := t.App.GetBookmarksByPath(path, *user)
+		bms, err := t.App.GetBookmarksByPath(r.Context(), path, *user)
 		if err != nil {
 			t.Logger.ErrorRequest(fmt.Sprintf("could not get bookmarks for path '%s'; '%v'", path, err), r)
 		}
-		html.BookmarkList(path, bms, html.GetEllipsisValues(r)).Render(w)
+		html.BookmarkList(path, bms, html.GetEllipsisValues(r), security.NonceFromContext(r.Context())).Render(w)
 	}
 }
 
@@ -0,0 +1,117 @@
+package combined
+
+import (
+	bmconf "golang.binggl.net/monorepo/internal/bookmarks/app/conf"
+	coreconf "golang.binggl.net/monorepo/internal/core/app/conf"
+	mydmsconf "golang.binggl.net/monorepo/internal/mydms/app/config"
+	"golang.binggl.net/monorepo/pkg/config"
+)
+
+// AppConfig holds the configuration of the combined server. The base configuration and the
+// JWT settings are shared by all services, each service keeps its own database.
+type AppConfig struct {
+	config.BaseConfig
+	Security  Security
+	Core      CoreConfig
+	Bookmarks BookmarksConfig
+	Mydms     MydmsConfig
+}
+
+// Security defines the JWT settings used by all services
+type Security struct {
+	JwtIssuer     string `validate:"required"`
+	JwtSecret     string `validate:"required" secret:"true"`
+	CookieName    string `validate:"required"`
+	CacheDuration string `validate:"duration"`
+	// LoginRedirect is used for requests without a valid JWT
+	LoginRedirect string `validate:"required"`
+}
+
+// CoreConfig holds the settings specific to core
+type CoreConfig struct {
+	Database coreconf.Database
+	Claim    config.Claim
+	OIDC     coreconf.OAuthConfig
+	// Expiry of the created JWT in days
+	Expiry int `validate:"min=1"`
+	// LoginRedirect is the target after a successful login
+	LoginRedirect string
+}
+
+// BookmarksConfig holds the settings specific to bookmarks
+type BookmarksConfig struct {
+	Database          bmconf.Database
+	Claim             config.Claim
+	FaviconUploadPath string `validate:"required"`
+	DefaultFavicon    string
+	Upload            bmconf.UploadSettings
+}
+
+// MydmsConfig holds the settings specific to mydms
+type MydmsConfig struct {
+	Database       mydmsconf.Database
+	Claim          config.Claim
+	Filestore      mydmsconf.FileStore
+	Upload         mydmsconf.UploadSettings
+	Preview        mydmsconf.PreviewSettings
+	Inbox          mydmsconf.InboxSettings
+	Classification mydmsconf.ClassificationSettings
+	Reminders      mydmsconf.ReminderSettings
+}
+
+// baseConfig provides the shared configuration using the claim required by a service
+func (c AppConfig) baseConfig(claim config.Claim) config.BaseConfig {
+	base := c.BaseConfig
+	base.Security = config.Security{
+		JwtIssuer:     c.Security.JwtIssuer,
+		JwtSecret:     c.Security.JwtSecret,
+		CookieName:    c.Security.CookieName,
+		LoginRedirect: c.Security.LoginRedirect,
+		CacheDuration: c.Security.CacheDuration,
+		Claim:         claim,
+	}
+	return base
+}
+
+// CoreConfig provides the configuration used by the core service
+func (c AppConfig) CoreConfig() coreconf.AppConfig {
+	return coreconf.AppConfig{
+		BaseConfig: c.baseConfig(c.Core.Claim),
+		Database:   c.Core.Database,
+		Security: coreconf.Security{
+			JwtIssuer:     c.Security.JwtIssuer,
+			JwtSecret:     c.Security.JwtSecret,
+			CookieName:    c.Security.CookieName,
+			Expiry:        c.Core.Expiry,
+			Claim:         c.Core.Claim,
+			CacheDuration: c.Security.CacheDuration,
+			LoginRedirect: c.Core.LoginRedirect,
+		},
+		OIDC: c.Core.OIDC,
+	}
+}
+
+// BookmarksConfig provides the configuration used by the bookmarks service
+func (c AppConfig) BookmarksConfig() bmconf.AppConfig {
+	return bmconf.AppConfig{
+		BaseConfig:        c.baseConfig(c.Bookmarks.Claim),
+		Database:          c.Bookmarks.Database,
+		FaviconUploadPath: c.Bookmarks.FaviconUploadPath,
+		DefaultFavicon:    c.Bookmarks.DefaultFavicon,
+		Upload:            c.Bookmarks.Upload,
+	}
+}
+
+// MydmsConfig provides the configuration used by the mydms service
+func (c AppConfig) MydmsConfig() mydmsconf.AppConfig {
+	return mydmsconf.AppConfig{
+		BaseConfig:     c.baseConfig(c.Mydms.Claim),
+		Database:       c.Mydms.Database,
+		Filestore:      c.Mydms.Filestore,
+		Upload:         c.Mydms.Upload,
+		Preview:        c.Mydms.Preview,
+		Inbox:          c.Mydms.Inbox,
+		Classification: c.Mydms.Classification,
+		Reminders:      c.Mydms.Reminders,
+	}
+}
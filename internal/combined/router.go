@@ -0,0 +1,77 @@
+package combined
+
+import (
+	"net/http"
+
+	"golang.binggl.net/monorepo/internal/bookmarks"
+	bmapp "golang.binggl.net/monorepo/internal/bookmarks/app/bookmarks"
+	"golang.binggl.net/monorepo/internal/core"
+	"golang.binggl.net/monorepo/internal/mydms"
+	"golang.binggl.net/monorepo/pkg/develop"
+	"golang.binggl.net/monorepo/pkg/logging"
+	"golang.binggl.net/monorepo/pkg/server"
+)
+
+// Services hold the components of all applications served by the combined server
+type Services struct {
+	Core      core.Services
+	Bookmarks *bmapp.Application
+	Mydms     mydms.Services
+}
+
+// HTTPHandlerOptions are used to configure the http handler setup
+type HTTPHandlerOptions struct {
+	BasePath  string
+	ErrorPath string
+	Config    AppConfig
+	Version   string
+	Build     string
+}
+
+// MakeHTTPHandler creates one handler serving the paths of core, bookmarks and mydms.
+// The basic middlewares and the static assets are shared, every application validates
+// the JWT with its own required claim.
+func MakeHTTPHandler(svc Services, logger logging.Logger, opts HTTPHandlerOptions) http.Handler {
+	std := server.SetupBasicRouter(opts.BasePath, opts.Config.Cookies, opts.Config.Cors, opts.Config.Headers, opts.Config.Assets, logger, mydms.DavPath)
+
+	// use this for development purposes only!
+	develop.SetupDevTokenHandler(std, logger, opts.Config.Environment)
+
+	coreOpts := core.HTTPHandlerOptions{
+		BasePath:  opts.BasePath,
+		ErrorPath: opts.ErrorPath,
+		Config:    opts.Config.CoreConfig(),
+		Version:   opts.Version,
+		Build:     opts.Build,
+	}
+	notFound := core.MountRoutes(std, std.With(core.JWTInterceptor(coreOpts, logger)),
+		svc.Core.OIDC, svc.Core.Sites, svc.Core.Crypter, logger, coreOpts)
+
+	bmOpts := bookmarks.HTTPHandlerOptions{
+		BasePath:  opts.BasePath,
+		ErrorPath: opts.ErrorPath,
+		Config:    opts.Config.BookmarksConfig(),
+		Version:   opts.Version,
+		Build:     opts.Build,
+	}
+	bookmarks.MountRoutes(std, std.With(bookmarks.JWTInterceptor(bmOpts, logger)),
+		svc.Bookmarks, logger, bmOpts)
+
+	mydmsOpts := mydms.HTTPHandlerOptions{
+		BasePath:  opts.BasePath,
+		ErrorPath: opts.ErrorPath,
+		Config:    opts.Config.MydmsConfig(),
+		Version:   opts.Version,
+		Build:     opts.Build,
+	}
+	mydms.MountRoutes(std, std.With(mydms.JWTInterceptor(mydmsOpts, logger)),
+		svc.Mydms.Repository, svc.Mydms.Documents, svc.Mydms.Upload, svc.Mydms.Files, svc.Mydms.Rules, svc.Mydms.Reminders, logger, mydmsOpts)
+
+	// the bookmarks are the start-page, same as the redirect of the reverse-proxy
+	std.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/bm", http.StatusFound)
+	})
+	std.NotFound(notFound)
+
+	return std
+}
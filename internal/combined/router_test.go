@@ -0,0 +1,84 @@
+package combined
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.binggl.net/monorepo/pkg/config"
+	"golang.binggl.net/monorepo/pkg/logging"
+)
+
+func testHandler() http.Handler {
+	claim := func(name string) config.Claim {
+		return config.Claim{Name: name, URL: "http://localhost:3000", Roles: []string{"User"}}
+	}
+	cfg := AppConfig{
+		BaseConfig: config.BaseConfig{
+			Environment: config.Development,
+			Assets: config.AssetSettings{
+				AssetDir:    "../../assets",
+				AssetPrefix: "/public",
+			},
+		},
+		Security: Security{
+			JwtIssuer:     "issuer",
+			JwtSecret:     "secret",
+			CookieName:    "login_token",
+			CacheDuration: "10m",
+			LoginRedirect: "/oidc/start",
+		},
+		Core:      CoreConfig{Claim: claim("core"), Expiry: 7, LoginRedirect: "/bm"},
+		Bookmarks: BookmarksConfig{Claim: claim("bookmarks")},
+		Mydms:     MydmsConfig{Claim: claim("mydms")},
+	}
+	return MakeHTTPHandler(Services{}, logging.NewNop(), HTTPHandlerOptions{
+		BasePath:  "./",
+		ErrorPath: "/error",
+		Config:    cfg,
+		Version:   "1.0",
+		Build:     "build",
+	})
+}
+
+func TestForbiddenPages(t *testing.T) {
+	h := testHandler()
+	for _, path := range []string{"/core/403", "/bm/403", "/mydms/403"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusForbidden, rec.Code, path)
+		assert.True(t, strings.Contains(rec.Body.String(), "<html"), path)
+	}
+}
+
+func TestStartPageRedirect(t *testing.T) {
+	rec := httptest.NewRecorder()
+	testHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "/bm", rec.Header().Get("Location"))
+}
+
+func TestSecuredPathsNeedJWT(t *testing.T) {
+	h := testHandler()
+	// every application uses its own interceptor and error page
+	for path, redirect := range map[string]string{
+		"/sites":     "/core/403",
+		"/bm/search": "/bm/403",
+		"/mydms":     "/mydms/403",
+	} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept", "text/html")
+		h.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusFound, rec.Code, path)
+		assert.Equal(t, redirect, rec.Header().Get("Location"), path)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	rec := httptest.NewRecorder()
+	testHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/unknown/path", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,117 @@
+package combined
+
+import (
+	"context"
+	"fmt"
+
+	"golang.binggl.net/monorepo/internal/bookmarks"
+	"golang.binggl.net/monorepo/internal/core"
+	"golang.binggl.net/monorepo/internal/mydms"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+	"golang.binggl.net/monorepo/pkg/config"
+	"golang.binggl.net/monorepo/pkg/develop"
+	"golang.binggl.net/monorepo/pkg/logging"
+	"golang.binggl.net/monorepo/pkg/persistence"
+	"golang.binggl.net/monorepo/pkg/server"
+	"golang.binggl.net/monorepo/pkg/tracing"
+)
+
+// Run is the entry-point for the combined server, which serves core, bookmarks and mydms
+// using a single listener. Each application uses its own database.
+func Run(version, build, appName string) error {
+	hostname, port, basePath, appCfg, err := server.ReadConfig[AppConfig]("CB")
+	if err != nil {
+		return err
+	}
+
+	// use the new pkg logger implementation
+	logger := logConfig(appCfg)
+	// ensure closing of logfile on exit
+	defer logger.Close()
+
+	shutdownTracing, err := tracing.Setup(tracing.Options{
+		AppName: appCfg.AppName,
+		Version: version,
+		HostID:  appCfg.HostID,
+		Config:  appCfg.Tracing,
+	})
+	if err != nil {
+		panic(fmt.Sprintf("cannot setup tracing: %v", err))
+	}
+	// flush pending spans on exit
+	defer shutdownTracing(context.Background())
+
+	coreDB := persistence.MustCreateSqliteConn(appCfg.Core.Database.ConnectionString)
+	defer coreDB.Close()
+	bmDB := persistence.MustCreateSqliteConn(appCfg.Bookmarks.Database.ConnectionString)
+	defer bmDB.Close()
+	mydmsDB := shared.NewConnForSqlite(appCfg.Mydms.Database.ConnectionString)
+	defer mydmsDB.Close()
+
+	// the readiness checks of the applications are prefixed by the application name
+	health := server.NewHealth()
+	register := func(prefix string) func(string, server.CheckFunc) {
+		return func(name string, check server.CheckFunc) {
+			health.Register(prefix+"."+name, check)
+		}
+	}
+
+	var svc Services
+	if svc.Core, err = core.Setup(coreDB, appCfg.CoreConfig(), logger, register("core")); err != nil {
+		panic(fmt.Sprintf("cannot create database connection: %v", err))
+	}
+	svc.Bookmarks = bookmarks.Setup(bmDB, basePath, appCfg.BookmarksConfig(), logger, register("bookmarks"))
+	jobs, stopJobs := context.WithCancel(context.Background())
+	if svc.Mydms, err = mydms.Setup(jobs, mydmsDB, appCfg.MydmsConfig(), logger, register("mydms")); err != nil {
+		panic(fmt.Sprintf("cannot establish database connection: %v", err))
+	}
+	// the background jobs of mydms are finished before the database is closed
+	defer func() {
+		stopJobs()
+		svc.Mydms.Wait()
+	}()
+
+	handler := MakeHTTPHandler(svc, logger, HTTPHandlerOptions{
+		BasePath:  basePath,
+		ErrorPath: appCfg.ErrorPath,
+		Config:    appCfg,
+		Version:   version,
+		Build:     build,
+	})
+
+	listen, err := server.NewListenOptions(appCfg.Server)
+	if err != nil {
+		return err
+	}
+
+	// only run the reload-server in development
+	if appCfg.Environment == config.Development {
+		reload := develop.NewReloadServer()
+		reload.Start()
+	}
+
+	return server.Run(server.RunOptions{
+		AppName:       appName,
+		Version:       version,
+		Build:         build,
+		HostName:      hostname,
+		Port:          port,
+		Environment:   string(appCfg.Environment),
+		ServerHandler: handler,
+		Logger:        logger,
+		Health:        health,
+		Listen:        listen,
+	})
+}
+
+func logConfig(cfg AppConfig) logging.Logger {
+	return logging.New(logging.LogConfig{
+		FilePath:      cfg.Logging.FilePath,
+		LogLevel:      cfg.Logging.LogLevel,
+		GrayLogServer: cfg.Logging.GrayLogServer,
+		Trace: logging.TraceConfig{
+			AppName: cfg.AppName,
+			HostID:  cfg.HostID,
+		},
+	}, cfg.Environment)
+}
@@ -0,0 +1,131 @@
+package crypter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// age encryption using the reference implementation of the age file format
+// https://age-encryption.org/v1
+//
+// The payload is encrypted either for a passphrase (scrypt recipient) or for a list of
+// X25519 recipients (public keys "age1..."). The result uses the ASCII armor of age and
+// can be decrypted by the age CLI:
+//
+//	age --decrypt -o output payload.age
+//	age --decrypt -i key.txt -o output payload.age
+
+const (
+	// ageIdentityPrefix marks the private key of a X25519 identity
+	ageIdentityPrefix = "AGE-SECRET-KEY-"
+	// ageVersionLine starts the header of a binary age file
+	ageVersionLine = "age-encryption.org/"
+)
+
+// encryptAge creates an armored age file for the recipients, or the passphrase if no
+// recipients are provided. The passphrase cannot be combined with other recipients.
+func encryptAge(payload []byte, passphrase string, recipients []string) ([]byte, error) {
+	rcpts, err := ageRecipients(passphrase, recipients)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	armorWriter := armor.NewWriter(&buf)
+	w, err := age.Encrypt(armorWriter, rcpts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not create age encryption: %v", err)
+	}
+	if _, err := w.Write(payload); err != nil {
+		return nil, fmt.Errorf("could not encrypt payload: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("could not finish age encryption: %v", err)
+	}
+	if err := armorWriter.Close(); err != nil {
+		return nil, fmt.Errorf("could not finish age armor: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// decryptAge decrypts a binary or armored age file. The secret is either the passphrase
+// or one or more X25519 identities ("AGE-SECRET-KEY-1...") as written by age-keygen.
+func decryptAge(payload []byte, secret string) ([]byte, error) {
+	identities, err := ageIdentities(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	var src io.Reader = bytes.NewReader(payload)
+	if trimmed := bytes.TrimSpace(payload); bytes.HasPrefix(trimmed, []byte(armor.Header)) {
+		src = armor.NewReader(bytes.NewReader(trimmed))
+	}
+	r, err := age.Decrypt(src, identities...)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt age payload: %v", err)
+	}
+	plain, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not read decrypted age payload: %v", err)
+	}
+	return plain, nil
+}
+
+func ageRecipients(passphrase string, recipients []string) ([]age.Recipient, error) {
+	var rcpts []age.Recipient
+	for _, rcpt := range recipients {
+		rcpt = strings.TrimSpace(rcpt)
+		if rcpt == "" || strings.HasPrefix(rcpt, "#") {
+			continue
+		}
+		r, err := age.ParseX25519Recipient(rcpt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient '%s': %v", rcpt, err)
+		}
+		rcpts = append(rcpts, r)
+	}
+	if len(rcpts) > 0 {
+		return rcpts, nil
+	}
+
+	if passphrase == "" {
+		return nil, fmt.Errorf("either a passphrase or recipients are needed")
+	}
+	r, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("could not use passphrase: %v", err)
+	}
+	return []age.Recipient{r}, nil
+}
+
+func ageIdentities(secret string) ([]age.Identity, error) {
+	// the output of age-keygen starts with comments, the key follows
+	if IsAgeIdentity(secret) {
+		identities, err := age.ParseIdentities(strings.NewReader(secret))
+		if err != nil {
+			return nil, fmt.Errorf("invalid identity: %v", err)
+		}
+		return identities, nil
+	}
+	id, err := age.NewScryptIdentity(secret)
+	if err != nil {
+		return nil, fmt.Errorf("could not use passphrase: %v", err)
+	}
+	return []age.Identity{id}, nil
+}
+
+// IsAge checks if the payload is an age file, either binary or ASCII armored
+func IsAge(payload []byte) bool {
+	trimmed := bytes.TrimSpace(payload)
+	return bytes.HasPrefix(trimmed, []byte(armor.Header)) || bytes.HasPrefix(trimmed, []byte(ageVersionLine))
+}
+
+// IsAgeIdentity checks if the secret holds X25519 identities instead of a passphrase
+func IsAgeIdentity(secret string) bool {
+	return strings.Contains(secret, ageIdentityPrefix)
+}
@@ -0,0 +1,144 @@
+package crypter_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"golang.binggl.net/monorepo/internal/common/crypter"
+)
+
+func TestAgeCrypter_Passphrase(t *testing.T) {
+	password := "correct horse battery staple"
+	plainText := []byte("hello, world - age")
+
+	svc := crypter.NewService(logger)
+	encrypted, err := svc.Encrypt(context.TODO(), crypter.Request{
+		Password: password,
+		Payload:  plainText,
+		Type:     crypter.Age,
+	})
+	if err != nil {
+		t.Fatalf("could not encrypt payload: %v", err)
+	}
+	if !strings.HasPrefix(string(encrypted), armor.Header) {
+		t.Errorf("the result is not armored: '%s'", string(encrypted))
+	}
+
+	decrypted, err := svc.Decrypt(context.TODO(), crypter.Request{
+		Password: password,
+		Payload:  encrypted,
+		Type:     crypter.Age,
+	})
+	if err != nil {
+		t.Fatalf("could not decrypt payload: %v", err)
+	}
+	if !bytes.Equal(plainText, decrypted) {
+		t.Errorf("the round-trip did not work; expected '%s', got '%s'", plainText, decrypted)
+	}
+
+	_, err = svc.Decrypt(context.TODO(), crypter.Request{
+		Password: "wrong password",
+		Payload:  encrypted,
+		Type:     crypter.Age,
+	})
+	if err == nil {
+		t.Errorf("expected an error for a wrong password")
+	}
+}
+
+func TestAgeCrypter_Recipients(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("could not create identity: %v", err)
+	}
+	other, _ := age.GenerateX25519Identity()
+	plainText := []byte("hello, recipients")
+
+	svc := crypter.NewService(logger)
+	encrypted, err := svc.Encrypt(context.TODO(), crypter.Request{
+		Payload:    plainText,
+		Type:       crypter.Age,
+		Recipients: []string{"# the recipients", identity.Recipient().String(), "", other.Recipient().String()},
+	})
+	if err != nil {
+		t.Fatalf("could not encrypt payload: %v", err)
+	}
+
+	// the format of age-keygen is used for the identity
+	keyFile := fmt.Sprintf("# created: 2024-01-01T00:00:00Z\n# public key: %s\n%s\n", identity.Recipient(), identity)
+	decrypted, err := svc.Decrypt(context.TODO(), crypter.Request{
+		Password: keyFile,
+		Payload:  encrypted,
+		Type:     crypter.Age,
+	})
+	if err != nil {
+		t.Fatalf("could not decrypt payload: %v", err)
+	}
+	if !bytes.Equal(plainText, decrypted) {
+		t.Errorf("the round-trip did not work; expected '%s', got '%s'", plainText, decrypted)
+	}
+
+	third, _ := age.GenerateX25519Identity()
+	_, err = svc.Decrypt(context.TODO(), crypter.Request{
+		Password: third.String(),
+		Payload:  encrypted,
+		Type:     crypter.Age,
+	})
+	if err == nil {
+		t.Errorf("expected an error for an identity which is not a recipient")
+	}
+}
+
+func TestAgeCrypter_DecryptBinary(t *testing.T) {
+	recipient, _ := age.NewScryptRecipient("12345")
+	recipient.SetWorkFactor(10)
+	var buf bytes.Buffer
+	w, _ := age.Encrypt(&buf, recipient)
+	w.Write([]byte("binary age file"))
+	w.Close()
+
+	svc := crypter.NewService(logger)
+	decrypted, err := svc.Decrypt(context.TODO(), crypter.Request{
+		Password: "12345",
+		Payload:  buf.Bytes(),
+		Type:     crypter.Age,
+	})
+	if err != nil {
+		t.Fatalf("could not decrypt payload: %v", err)
+	}
+	if string(decrypted) != "binary age file" {
+		t.Errorf("unexpected result '%s'", decrypted)
+	}
+}
+
+func TestAgeCrypter_Validate_Input(t *testing.T) {
+	svc := crypter.NewService(logger)
+
+	var requestTests = []struct {
+		name string
+		req  crypter.Request
+	}{
+		{
+			name: "expect missing password or recipients",
+			req:  crypter.Request{Payload: []byte("a"), Type: crypter.Age},
+		},
+		{
+			name: "expect invalid recipient",
+			req:  crypter.Request{Payload: []byte("a"), Type: crypter.Age, Recipients: []string{"age1invalid"}},
+		},
+	}
+
+	for _, tt := range requestTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Encrypt(context.TODO(), tt.req)
+			if err == nil {
+				t.Errorf("error expected")
+			}
+		})
+	}
+}
@@ -83,32 +83,75 @@ import (
 // # Viewer is expected to supply appearance streams for form fields.
 // needAppearances: false
 
-// encrypt pdf or change password of pdf
-func encryptPdfPayload(payload []byte, initPass, newPass string) ([]byte, error) {
-	var (
-		conf *pdfModel.Configuration
-		err  error
-	)
-
-	conf = pdfModel.NewRC4Configuration(initPass, initPass, 40)
-	conf.ValidationMode = pdfModel.ValidationRelaxed
-	conf.Cmd = pdfModel.CHANGEUPW
-	conf.UserPW = initPass
-	conf.UserPWNew = &newPass
-	conf.OwnerPWNew = &newPass
-
-	// if no initial password is supplied the api does not change the
-	// password but sets a password via the ENCRYPT command
-	if initPass == "" && newPass != "" {
-		conf = pdfModel.NewRC4Configuration(newPass, newPass, 40)
-		conf.Cmd = pdfModel.ENCRYPT
-		conf.UserPWNew = &newPass
+// PdfPermissions restrict the usage of an encrypted PDF. The restrictions apply when the
+// document is opened using the user password; the owner password grants full access.
+type PdfPermissions struct {
+	Print  bool
+	Copy   bool
+	Modify bool
+}
+
+// flags maps the permissions to the permission bits of the PDF standard security handler
+func (p PdfPermissions) flags() pdfModel.PermissionFlags {
+	flags := pdfModel.PermissionsNone
+	if p.Print {
+		flags |= pdfModel.PermissionPrintRev2 | pdfModel.PermissionPrintRev3
+	}
+	if p.Copy {
+		flags |= pdfModel.PermissionExtract | pdfModel.PermissionExtractRev3
+	}
+	if p.Modify {
+		flags |= pdfModel.PermissionModify | pdfModel.PermissionModAnnFillForm | pdfModel.PermissionFillRev3 | pdfModel.PermissionAssembleRev3
+	}
+	return flags
+}
+
+// pdfKeyLength is the key length used for AES encryption of PDFs
+const pdfKeyLength = 256
+
+// IsPDF checks the header of the payload
+func IsPDF(payload []byte) bool {
+	return bytes.HasPrefix(payload, []byte("%PDF-"))
+}
+
+// IsEncryptedPDF uses the encryption dictionary referenced in the trailer to find encrypted PDFs
+func IsEncryptedPDF(payload []byte) bool {
+	return IsPDF(payload) && bytes.Contains(payload, []byte("/Encrypt"))
+}
+
+// encryptPdfPayload encrypts the PDF using AES-256. If the PDF is already encrypted the initial
+// password is used to decrypt it first, this way older RC4 encrypted files are upgraded as well.
+// Without an owner password the user password is used for both.
+func encryptPdfPayload(payload []byte, initPass, userPass, ownerPass string, perm PdfPermissions) ([]byte, error) {
+	var err error
+	if initPass != "" {
+		if payload, err = decryptPdfPayload(payload, initPass); err != nil {
+			return nil, err
+		}
+	}
+	if ownerPass == "" {
+		ownerPass = userPass
 	}
 
+	conf := pdfModel.NewAESConfiguration(userPass, ownerPass, pdfKeyLength)
+	conf.ValidationMode = pdfModel.ValidationRelaxed
+	conf.Permissions = perm.flags()
+
 	buff := new(bytes.Buffer)
-	if err = pdfApi.Optimize(bytes.NewReader(payload), buff, conf); err != nil {
-		return nil, fmt.Errorf("could not process PDF payload: %v", err)
+	if err = pdfApi.Encrypt(bytes.NewReader(payload), buff, conf); err != nil {
+		return nil, fmt.Errorf("could not encrypt PDF payload: %v", err)
 	}
+	return buff.Bytes(), nil
+}
+
+// decryptPdfPayload removes the encryption of the PDF, the password is either the user or the owner password
+func decryptPdfPayload(payload []byte, password string) ([]byte, error) {
+	conf := pdfModel.NewAESConfiguration(password, password, pdfKeyLength)
+	conf.ValidationMode = pdfModel.ValidationRelaxed
 
+	buff := new(bytes.Buffer)
+	if err := pdfApi.Decrypt(bytes.NewReader(payload), buff, conf); err != nil {
+		return nil, fmt.Errorf("could not decrypt PDF payload: %v", err)
+	}
 	return buff.Bytes(), nil
 }
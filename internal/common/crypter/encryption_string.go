@@ -0,0 +1,209 @@
+package crypter
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Versioned format of encrypted strings. The encrypted string is the prefix followed by the
+// base64 (URL alphabet) encoded payload. The prefix contains the '.' which is not part of the
+// base64 alphabet, therefore the format cannot be confused with the legacy format of encryptAES.
+//
+// Payload layout (version 2):
+//
+//	version  1 byte   (2)
+//	kdf      1 byte   (1: Argon2id, 2: scrypt)
+//	cipher   1 byte   (1: XChaCha20-Poly1305, 2: AES-256-GCM)
+//	param1   4 bytes  Argon2id: time / scrypt: log2(N)
+//	param2   4 bytes  Argon2id: memory in KiB / scrypt: r
+//	param3   1 byte   Argon2id: threads / scrypt: p
+//	salt     16 bytes
+//	nonce    24 bytes (XChaCha20-Poly1305) or 12 bytes (AES-GCM)
+//	sealed   ciphertext and tag
+//
+// All header values including the salt and nonce are authenticated as additional data.
+
+// KeyDerivation defines the function to derive the key from the password
+type KeyDerivation string
+
+const (
+	// Argon2id is the default key derivation
+	Argon2id KeyDerivation = "argon2id"
+	// Scrypt is an alternative key derivation
+	Scrypt KeyDerivation = "scrypt"
+)
+
+// Cipher defines the authenticated encryption used for strings
+type Cipher string
+
+const (
+	// XChaCha20Poly1305 is the default cipher, the large nonce is safe to choose randomly
+	XChaCha20Poly1305 Cipher = "xchacha20-poly1305"
+	// AESGCM uses AES-256 in Galois/Counter Mode
+	AESGCM Cipher = "aes-256-gcm"
+)
+
+const (
+	stringFormatVersion = 2
+	stringFormatPrefix  = "v2."
+	stringSaltLength    = 16
+	stringKeyLength     = 32
+	stringHeaderLength  = 3 + 4 + 4 + 1 + stringSaltLength
+
+	kdfArgon2id = 1
+	kdfScrypt   = 2
+
+	cipherXChaCha20 = 1
+	cipherAESGCM    = 2
+
+	// parameters of the key derivation, see RFC 9106 and the recommendation of the scrypt package
+	argon2Time    = 3
+	argon2Memory  = 64 * 1024
+	argon2Threads = 4
+	scryptLogN    = 15
+	scryptR       = 8
+	scryptP       = 1
+
+	// upper bounds for parameters read from a payload, to prevent resource exhaustion.
+	// The memory of scrypt is 128*N*r bytes, p multiplies the computation.
+	maxArgon2Time    = 16
+	maxArgon2Memory  = 128 * 1024
+	maxArgon2Threads = 16
+	maxScryptLogN    = 20
+	maxScryptR       = 32
+	maxScryptP       = 16
+	maxScryptMemory  = 128 << 20
+)
+
+// IsLegacy checks if the encrypted string uses the format of encryptAES
+func IsLegacy(payload []byte) bool {
+	return !bytes.HasPrefix(bytes.TrimSpace(payload), []byte(stringFormatPrefix))
+}
+
+// encryptString derives a key from the passphrase using a random salt and seals the text
+func encryptString(text []byte, passphrase string, kdf KeyDerivation, c Cipher) ([]byte, error) {
+	header := make([]byte, stringHeaderLength)
+	header[0] = stringFormatVersion
+	switch kdf {
+	case Argon2id, "":
+		header[1] = kdfArgon2id
+		binary.BigEndian.PutUint32(header[3:], argon2Time)
+		binary.BigEndian.PutUint32(header[7:], argon2Memory)
+		header[11] = argon2Threads
+	case Scrypt:
+		header[1] = kdfScrypt
+		binary.BigEndian.PutUint32(header[3:], scryptLogN)
+		binary.BigEndian.PutUint32(header[7:], scryptR)
+		header[11] = scryptP
+	default:
+		return nil, fmt.Errorf("the key derivation '%s' is not supported", kdf)
+	}
+	switch c {
+	case XChaCha20Poly1305, "":
+		header[2] = cipherXChaCha20
+	case AESGCM:
+		header[2] = cipherAESGCM
+	default:
+		return nil, fmt.Errorf("the cipher '%s' is not supported", c)
+	}
+	if _, err := io.ReadFull(rand.Reader, header[12:]); err != nil {
+		return nil, fmt.Errorf("could not create salt: %v", err)
+	}
+
+	aead, err := stringAEAD(header, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("could not create nonce: %v", err)
+	}
+
+	payload := append(header, nonce...)
+	payload = aead.Seal(payload, nonce, text, payload)
+	return []byte(stringFormatPrefix + base64.URLEncoding.EncodeToString(payload)), nil
+}
+
+// decryptString opens the versioned format created by encryptString
+func decryptString(cipherText, passphrase string) ([]byte, error) {
+	encoded, found := strings.CutPrefix(strings.TrimSpace(cipherText), stringFormatPrefix)
+	if !found {
+		return nil, fmt.Errorf("unknown format of the encrypted text")
+	}
+	payload, err := base64.URLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("could not base64decode encrypted text: %v", err)
+	}
+	if len(payload) < stringHeaderLength || payload[0] != stringFormatVersion {
+		return nil, fmt.Errorf("could not decrypt, invalid input")
+	}
+
+	header := payload[:stringHeaderLength]
+	aead, err := stringAEAD(header, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) < stringHeaderLength+aead.NonceSize()+aead.Overhead() {
+		return nil, fmt.Errorf("could not decrypt, invalid input")
+	}
+	additional := payload[:stringHeaderLength+aead.NonceSize()]
+	nonce := additional[stringHeaderLength:]
+	plain, err := aead.Open(nil, nonce, payload[len(additional):], additional)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt, invalid input or wrong passphrase")
+	}
+	return plain, nil
+}
+
+// stringAEAD derives the key defined by the header and creates the cipher
+func stringAEAD(header []byte, passphrase string) (cipher.AEAD, error) {
+	var (
+		key    []byte
+		err    error
+		param1 = binary.BigEndian.Uint32(header[3:])
+		param2 = binary.BigEndian.Uint32(header[7:])
+		param3 = header[11]
+		salt   = header[12:stringHeaderLength]
+	)
+	switch header[1] {
+	case kdfArgon2id:
+		if param1 == 0 || param1 > maxArgon2Time || param2 > maxArgon2Memory || param3 == 0 || param3 > maxArgon2Threads {
+			return nil, fmt.Errorf("invalid parameters of the key derivation")
+		}
+		key = argon2.IDKey([]byte(passphrase), salt, param1, param2, param3, stringKeyLength)
+	case kdfScrypt:
+		if param1 == 0 || param1 > maxScryptLogN || param2 == 0 || param2 > maxScryptR || param3 == 0 || param3 > maxScryptP ||
+			128*(uint64(1)<<param1)*uint64(param2) > maxScryptMemory {
+			return nil, fmt.Errorf("invalid parameters of the key derivation")
+		}
+		key, err = scrypt.Key([]byte(passphrase), salt, 1<<param1, int(param2), int(param3), stringKeyLength)
+		if err != nil {
+			return nil, fmt.Errorf("could not derive key: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown key derivation '%d'", header[1])
+	}
+
+	switch header[2] {
+	case cipherXChaCha20:
+		return chacha20poly1305.NewX(key)
+	case cipherAESGCM:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	}
+	return nil, fmt.Errorf("unknown cipher '%d'", header[2])
+}
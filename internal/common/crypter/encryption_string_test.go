@@ -0,0 +1,153 @@
+package crypter_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"golang.binggl.net/monorepo/internal/common/crypter"
+)
+
+// created by the previous AES/HMAC format using the password "test1"
+const legacyEncryptedText = "CEibBk8vd5VtU0q5nMyVdyrGpsn1-bpdg92JkhCsll4lHsTDSVWj9U8nzMo6V2_yRqCp-AzTPDl6UEhAnLylEvCvOCD9OUaV89pTkbeHdeAVkW-_bEvGD8OemerlnZpHe2oWF3HbSXJCTfkuIQIkmMa2khUC5sk0n3mrRLqUvjE="
+
+func TestStringCrypter_Formats(t *testing.T) {
+	password := "a passphrase which is longer than the 32 bytes of the key"
+	plainText := "hello, world"
+	svc := crypter.NewService(logger)
+
+	for _, kdf := range []crypter.KeyDerivation{crypter.Argon2id, crypter.Scrypt} {
+		for _, c := range []crypter.Cipher{crypter.XChaCha20Poly1305, crypter.AESGCM} {
+			t.Run(string(kdf)+"/"+string(c), func(t *testing.T) {
+				encrypted, err := svc.Encrypt(context.TODO(), crypter.Request{
+					Password:      password,
+					Payload:       []byte(plainText),
+					Type:          crypter.String,
+					KeyDerivation: kdf,
+					Cipher:        c,
+				})
+				if err != nil {
+					t.Fatalf("could not encrypt string: %v", err)
+				}
+				if crypter.IsLegacy(encrypted) {
+					t.Errorf("the versioned format was not used: %s", encrypted)
+				}
+
+				decrypted, err := svc.Decrypt(context.TODO(), crypter.Request{
+					Password: password,
+					Payload:  encrypted,
+					Type:     crypter.String,
+				})
+				if err != nil {
+					t.Fatalf("could not decrypt string: %v", err)
+				}
+				if string(decrypted) != plainText {
+					t.Errorf("the round-trip did not work; expected '%s', got '%s'", plainText, decrypted)
+				}
+
+				// only the first 32 bytes were used as key by the legacy format
+				_, err = svc.Decrypt(context.TODO(), crypter.Request{
+					Password: password[:32],
+					Payload:  encrypted,
+					Type:     crypter.String,
+				})
+				if err == nil {
+					t.Errorf("expected an error for a wrong password")
+				}
+			})
+		}
+	}
+}
+
+func TestStringCrypter_RandomSalt(t *testing.T) {
+	svc := crypter.NewService(logger)
+	req := crypter.Request{Password: "12345", Payload: []byte("hello"), Type: crypter.String}
+	first, _ := svc.Encrypt(context.TODO(), req)
+	second, _ := svc.Encrypt(context.TODO(), req)
+	if string(first) == string(second) {
+		t.Errorf("the same text encrypted twice should not produce the same output")
+	}
+}
+
+func TestStringCrypter_Tampered(t *testing.T) {
+	svc := crypter.NewService(logger)
+	encrypted, err := svc.Encrypt(context.TODO(), crypter.Request{Password: "12345", Payload: []byte("hello"), Type: crypter.String})
+	if err != nil {
+		t.Fatalf("could not encrypt string: %v", err)
+	}
+	payload, _ := base64.URLEncoding.DecodeString(strings.TrimPrefix(string(encrypted), "v2."))
+
+	// change the ciphertext and a parameter of the header
+	for _, index := range []int{len(payload) - 1, 3} {
+		tampered := append([]byte{}, payload...)
+		tampered[index] ^= 0x01
+		_, err = svc.Decrypt(context.TODO(), crypter.Request{
+			Password: "12345",
+			Payload:  []byte("v2." + base64.URLEncoding.EncodeToString(tampered)),
+			Type:     crypter.String,
+		})
+		if err == nil {
+			t.Errorf("expected an error for a modified byte at index %d", index)
+		}
+	}
+}
+
+func TestStringCrypter_OversizedHeader(t *testing.T) {
+	svc := crypter.NewService(logger)
+	// kdf, param1, param2, param3 of crafted headers which would exhaust the memory
+	for _, params := range [][4]uint32{
+		{2, 20, 512, 1},        // scrypt: r too large
+		{2, 10, 8, 255},        // scrypt: p too large
+		{2, 20, 32, 1},         // scrypt: 4 GiB
+		{1, 3, 1 << 20, 4},     // argon2id: 1 GiB
+		{1, 3, 64 * 1024, 255}, // argon2id: too many threads
+	} {
+		payload := make([]byte, 28+24+32)
+		payload[0] = 2
+		payload[1] = byte(params[0])
+		payload[2] = 1
+		binary.BigEndian.PutUint32(payload[3:], params[1])
+		binary.BigEndian.PutUint32(payload[7:], params[2])
+		payload[11] = byte(params[3])
+		_, err := svc.Decrypt(context.TODO(), crypter.Request{
+			Password: "12345",
+			Payload:  []byte("v2." + base64.URLEncoding.EncodeToString(payload)),
+			Type:     crypter.String,
+		})
+		if err == nil || !strings.Contains(err.Error(), "invalid parameters") {
+			t.Errorf("expected an error for the parameters %v, got: %v", params, err)
+		}
+	}
+}
+
+func TestStringCrypter_Legacy(t *testing.T) {
+	svc := crypter.NewService(logger)
+	if !crypter.IsLegacy([]byte(legacyEncryptedText)) {
+		t.Errorf("the legacy format was not detected")
+	}
+	decrypted, err := svc.Decrypt(context.TODO(), crypter.Request{
+		Password: "test1",
+		Payload:  []byte(legacyEncryptedText),
+		Type:     crypter.String,
+	})
+	if err != nil {
+		t.Fatalf("could not decrypt legacy string: %v", err)
+	}
+	if string(decrypted) != "hello, world - from unit-test" {
+		t.Errorf("unexpected result '%s'", decrypted)
+	}
+}
+
+func TestStringCrypter_Unsupported(t *testing.T) {
+	svc := crypter.NewService(logger)
+	_, err := svc.Encrypt(context.TODO(), crypter.Request{Password: "12345", Payload: []byte("hello"), Type: crypter.String, KeyDerivation: "pbkdf2"})
+	if err == nil {
+		t.Errorf("expected an error for an unsupported key derivation")
+	}
+	_, err = svc.Encrypt(context.TODO(), crypter.Request{Password: "12345", Payload: []byte("hello"), Type: crypter.String, Cipher: "rc4"})
+	if err == nil {
+		t.Errorf("expected an error for an unsupported cipher")
+	}
+}
@@ -21,6 +21,8 @@ const (
 	PDF PayloadType = "PDF"
 	// String payload to be used for encryption/decryption
 	String PayloadType = "String"
+	// Age uses the age file format for arbitrary payloads, the result is ASCII armored
+	Age PayloadType = "Age"
 )
 
 // Request combines the parameters passed on to the encryption service
@@ -33,6 +35,16 @@ type Request struct {
 	Password string
 	// InitPass if the payload is encrypted and the password should be changed
 	InitPass string
+	// OwnerPassword grants full access to an encrypted PDF, the Password is used if empty
+	OwnerPassword string
+	// Permissions of an encrypted PDF opened with the Password
+	Permissions PdfPermissions
+	// Recipients are X25519 public keys ("age1...") used instead of the password for the type Age
+	Recipients []string
+	// KeyDerivation and Cipher are used to encrypt the type String, the defaults are Argon2id
+	// and XChaCha20-Poly1305; decryption reads both values from the payload
+	KeyDerivation KeyDerivation
+	Cipher        Cipher
 }
 
 // --------------------------------------------------------------------------
@@ -49,9 +61,10 @@ type EncryptionService interface {
 
 // NewService returns a Service with all of the expected middlewares wired in.
 func NewService(logger logging.Logger) EncryptionService {
-	svc := &encryptionSvc{
+	var svc EncryptionService = &encryptionSvc{
 		logger: logger,
 	}
+	svc = ServiceTracingMiddleware()(svc)
 	return svc
 
 }
@@ -73,7 +86,8 @@ type encryptionSvc struct {
 // Encrypt performs the encryption operation for different file-types.
 func (e *encryptionSvc) Encrypt(ctx context.Context, req Request) ([]byte, error) {
 
-	if req.Password == "" {
+	// age can encrypt for public keys without a password
+	if req.Password == "" && (req.Type != Age || len(req.Recipients) == 0) {
 		return nil, fmt.Errorf("cannot encrypt with empty password")
 	}
 	if len(req.Payload) == 0 {
@@ -83,9 +97,11 @@ func (e *encryptionSvc) Encrypt(ctx context.Context, req Request) ([]byte, error
 	// encrypt the payload per respective type
 	switch req.Type {
 	case PDF:
-		return encryptPdfPayload(req.Payload, req.InitPass, req.Password)
+		return encryptPdfPayload(req.Payload, req.InitPass, req.Password, req.OwnerPassword, req.Permissions)
 	case String:
-		return encryptAES(string(req.Payload), req.Password)
+		return encryptString(req.Payload, req.Password, req.KeyDerivation, req.Cipher)
+	case Age:
+		return encryptAge(req.Payload, req.Password, req.Recipients)
 	}
 	return nil, fmt.Errorf("the provided PayloadType '%s' is not supported for the operation 'Encrypt'", req.Type)
 }
@@ -99,10 +115,18 @@ func (e *encryptionSvc) Decrypt(ctx context.Context, req Request) ([]byte, error
 		return nil, fmt.Errorf("no payload supplied")
 	}
 
-	// encrypt the payload per respective type
+	// decrypt the payload per respective type
 	switch req.Type {
+	case PDF:
+		return decryptPdfPayload(req.Payload, req.Password)
 	case String:
-		return decryptAES(string(req.Payload), req.Password)
+		// texts encrypted before the versioned format was introduced
+		if IsLegacy(req.Payload) {
+			return decryptAES(string(req.Payload), req.Password)
+		}
+		return decryptString(string(req.Payload), req.Password)
+	case Age:
+		return decryptAge(req.Payload, req.Password)
 	}
-	return nil, fmt.Errorf("the provided PayloadType '%s' is not supported for the operation 'Encrypt'", req.Type)
+	return nil, fmt.Errorf("the provided PayloadType '%s' is not supported for the operation 'Decrypt'", req.Type)
 }
@@ -0,0 +1,49 @@
+package crypter
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"golang.binggl.net/monorepo/pkg/tracing"
+)
+
+// ServiceMiddleware describes a service middleware.
+// it is used to intercept the method execution and perform actions before/after the
+// service method execution
+type ServiceMiddleware func(EncryptionService) EncryptionService
+
+// ServiceTracingMiddleware creates a child-span for every encryption operation
+func ServiceTracingMiddleware() ServiceMiddleware {
+	return func(next EncryptionService) EncryptionService {
+		return tracingMiddleware{next}
+	}
+}
+
+// compile guard for Service implementation
+var (
+	_ EncryptionService = &tracingMiddleware{}
+)
+
+type tracingMiddleware struct {
+	next EncryptionService
+}
+
+func (t tracingMiddleware) Encrypt(ctx context.Context, req Request) (b []byte, err error) {
+	ctx, span := tracing.Start(ctx, "EncryptionService.Encrypt", requestAttributes(req)...)
+	defer func() { tracing.End(span, err) }()
+	return t.next.Encrypt(ctx, req)
+}
+
+func (t tracingMiddleware) Decrypt(ctx context.Context, req Request) (b []byte, err error) {
+	ctx, span := tracing.Start(ctx, "EncryptionService.Decrypt", requestAttributes(req)...)
+	defer func() { tracing.End(span, err) }()
+	return t.next.Decrypt(ctx, req)
+}
+
+// only the type and size of the payload are recorded, never passwords or content
+func requestAttributes(req Request) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("crypter.type", string(req.Type)),
+		attribute.Int("crypter.payload.size", len(req.Payload)),
+	}
+}
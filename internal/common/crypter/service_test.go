@@ -1,10 +1,13 @@
 package crypter_test
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"testing"
 
+	pdfApi "github.com/pdfcpu/pdfcpu/pkg/api"
+	pdfModel "github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
 	"golang.binggl.net/monorepo/internal/common/crypter"
 	"golang.binggl.net/monorepo/pkg/logging"
 )
@@ -84,6 +87,90 @@ func TestFileCrypter_Encrypt_UnencryptedFile(t *testing.T) {
 	}
 }
 
+func TestFileCrypter_AES256_Permissions(t *testing.T) {
+	payload, err := os.ReadFile(unencryptedPDF)
+	if err != nil {
+		t.Fatalf("could not read testfile: %v", err)
+	}
+	svc := crypter.NewService(logger)
+	encrypted, err := svc.Encrypt(context.TODO(), crypter.Request{
+		Payload:       payload,
+		Type:          crypter.PDF,
+		Password:      "12345",
+		OwnerPassword: "owner",
+		Permissions:   crypter.PdfPermissions{Print: true},
+	})
+	if err != nil {
+		t.Fatalf("could not encrypt file: %v", err)
+	}
+	if !crypter.IsEncryptedPDF(encrypted) {
+		t.Errorf("the result is not an encrypted PDF")
+	}
+
+	conf := pdfModel.NewAESConfiguration("12345", "", 256)
+	ctx, err := pdfApi.ReadContext(bytes.NewReader(encrypted), conf)
+	if err != nil {
+		t.Fatalf("could not read encrypted file: %v", err)
+	}
+	// version 5 of the security handler uses AES-256
+	if ctx.E == nil || ctx.E.V != 5 || ctx.E.L != 256 {
+		t.Fatalf("the PDF is not encrypted using AES-256: %+v", ctx.E)
+	}
+	perm := pdfModel.PermissionFlags(ctx.E.P)
+	if perm&pdfModel.PermissionPrintRev3 == 0 {
+		t.Errorf("printing should be allowed")
+	}
+	if perm&pdfModel.PermissionExtract != 0 || perm&pdfModel.PermissionModify != 0 {
+		t.Errorf("copy and modify should not be allowed")
+	}
+
+	// both passwords remove the encryption
+	for _, password := range []string{"12345", "owner"} {
+		decrypted, err := svc.Decrypt(context.TODO(), crypter.Request{
+			Payload:  encrypted,
+			Type:     crypter.PDF,
+			Password: password,
+		})
+		if err != nil {
+			t.Fatalf("could not decrypt file using '%s': %v", password, err)
+		}
+		if crypter.IsEncryptedPDF(decrypted) {
+			t.Errorf("the decrypted file is still encrypted")
+		}
+	}
+}
+
+func TestFileCrypter_Decrypt_File(t *testing.T) {
+	payload, err := os.ReadFile(encryptedPDF)
+	if err != nil {
+		t.Fatalf("could not read testfile: %v", err)
+	}
+	if !crypter.IsEncryptedPDF(payload) {
+		t.Errorf("the testfile is not detected as encrypted")
+	}
+	svc := crypter.NewService(logger)
+	decrypted, err := svc.Decrypt(context.TODO(), crypter.Request{
+		Payload:  payload,
+		Type:     crypter.PDF,
+		Password: "12345",
+	})
+	if err != nil {
+		t.Fatalf("could not decrypt file: %v", err)
+	}
+	if !crypter.IsPDF(decrypted) || crypter.IsEncryptedPDF(decrypted) {
+		t.Errorf("the result is not an unencrypted PDF")
+	}
+
+	_, err = svc.Decrypt(context.TODO(), crypter.Request{
+		Payload:  payload,
+		Type:     crypter.PDF,
+		Password: "54321",
+	})
+	if err == nil {
+		t.Errorf("expected an error for a wrong password")
+	}
+}
+
 func TestStringCrypter(t *testing.T) {
 	password := "1245"
 	plainText := "hello, world"
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"context"
 	"strings"
 
 	"golang.binggl.net/monorepo/pkg/config"
@@ -35,7 +36,8 @@ var AvailableApps = []html.NavItem{
 }
 
 // CreatePageModel provides the needed data for a page using the shared Layout
-func CreatePageModel(pageURL, pageTitle, search, favicon, timeStamp, commit string, env config.Environment, user security.User) html.LayoutModel {
+// the CSRF token and the CSP nonce are taken from the context of the current request
+func CreatePageModel(ctx context.Context, pageURL, pageTitle, search, favicon, timeStamp, commit string, env config.Environment, user security.User) html.LayoutModel {
 	appNav := make([]html.NavItem, 0)
 	var title string
 	for _, a := range AvailableApps {
@@ -56,6 +58,8 @@ func CreatePageModel(pageURL, pageTitle, search, favicon, timeStamp, commit stri
 		User:       user,
 		Search:     search,
 		Navigation: appNav,
+		CSRFToken:  security.CSRFTokenFromContext(ctx),
+		Nonce:      security.NonceFromContext(ctx),
 	}
 	model.Env = env
 	if model.Favicon == "" {
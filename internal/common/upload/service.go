@@ -29,9 +29,12 @@ type File struct {
 }
 
 // EncryptionRequest contains parammeters used for encryption
+// if only the InitPassword is supplied the encryption of the payload is removed
 type EncryptionRequest struct {
-	InitPassword string
-	Password     string
+	InitPassword  string
+	Password      string
+	OwnerPassword string
+	Permissions   crypter.PdfPermissions
 }
 
 // --------------------------------------------------------------------------
@@ -140,15 +143,30 @@ func (s *uploadService) Save(file File) (string, error) {
 		defer cancel()
 
 		payload, err = s.crypter.Encrypt(ctxt, crypter.Request{
-			InitPass: file.Enc.InitPassword,
-			Password: file.Enc.Password,
-			Type:     crypter.PDF, // only encrypt PDFs for now
-			Payload:  payload,
+			InitPass:      file.Enc.InitPassword,
+			Password:      file.Enc.Password,
+			OwnerPassword: file.Enc.OwnerPassword,
+			Permissions:   file.Enc.Permissions,
+			Type:          crypter.PDF, // only encrypt PDFs for now
+			Payload:       payload,
 		})
 		if err != nil {
 			s.logger.Error(fmt.Sprintf("could not encrypt file: %v", err))
 			return id, fmt.Errorf("could not encrypt payload, %w", ErrService)
 		}
+	} else if s.crypter != nil && file.Enc.InitPassword != "" {
+		ctxt, cancel := context.WithTimeout(context.Background(), s.timeOut)
+		defer cancel()
+
+		payload, err = s.crypter.Decrypt(ctxt, crypter.Request{
+			Password: file.Enc.InitPassword,
+			Type:     crypter.PDF,
+			Payload:  payload,
+		})
+		if err != nil {
+			s.logger.Error(fmt.Sprintf("could not decrypt file: %v", err))
+			return id, fmt.Errorf("could not decrypt payload, %w", ErrService)
+		}
 	}
 
 	id = uuid.New().String()
@@ -17,6 +17,7 @@ import (
 var logger = logging.NewNop()
 
 const unencryptedPDF = "../../../testdata/unencrypted.pdf"
+const encryptedPDF = "../../../testdata/encrypted.pdf"
 
 // --------------------------------------------------------------------------
 
@@ -249,6 +250,68 @@ func TestService_Write_Encrypt(t *testing.T) {
 	}
 }
 
+func TestService_Write_Crypter(t *testing.T) {
+	store := &mockStore{}
+	svc := upload.NewService(upload.ServiceOptions{
+		Logger:           logger,
+		Store:            store,
+		MaxUploadSize:    maxUploadSize,
+		AllowedFileTypes: []string{"pdf"},
+		Crypter:          crypter.NewService(logger),
+		TimeOut:          "10s",
+	})
+
+	// encrypt using AES-256 and permissions
+	payload, err := os.ReadFile(unencryptedPDF)
+	if err != nil {
+		t.Fatalf("could not read testfile: %v", err)
+	}
+	id, err := svc.Save(upload.File{
+		File:     bytes.NewReader(payload),
+		MimeType: "application/pdf",
+		Name:     "unencrypted.pdf",
+		Size:     int64(len(payload)),
+		Enc: upload.EncryptionRequest{
+			Password:      "12345",
+			OwnerPassword: "owner",
+			Permissions:   crypter.PdfPermissions{Print: true},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, id, store.upload.ID)
+	assert.True(t, crypter.IsEncryptedPDF(store.upload.Payload))
+
+	// only the initial password removes the encryption
+	payload, err = os.ReadFile(encryptedPDF)
+	if err != nil {
+		t.Fatalf("could not read testfile: %v", err)
+	}
+	_, err = svc.Save(upload.File{
+		File:     bytes.NewReader(payload),
+		MimeType: "application/pdf",
+		Name:     "encrypted.pdf",
+		Size:     int64(len(payload)),
+		Enc: upload.EncryptionRequest{
+			InitPassword: "12345",
+		},
+	})
+	assert.NoError(t, err)
+	assert.True(t, crypter.IsPDF(store.upload.Payload))
+	assert.False(t, crypter.IsEncryptedPDF(store.upload.Payload))
+
+	// wrong initial password
+	_, err = svc.Save(upload.File{
+		File:     bytes.NewReader(payload),
+		MimeType: "application/pdf",
+		Name:     "encrypted.pdf",
+		Size:     int64(len(payload)),
+		Enc: upload.EncryptionRequest{
+			InitPassword: "54321",
+		},
+	})
+	assert.ErrorIs(t, err, upload.ErrService)
+}
+
 func Test_Service_Errors_Store(t *testing.T) {
 	svc := upload.NewService(upload.ServiceOptions{
 		Logger:           logger,
@@ -12,25 +12,25 @@ type AppConfig struct {
 
 // Database defines the connection string
 type Database struct {
-	ConnectionString string
+	ConnectionString string `validate:"required"`
 }
 
 // Security settings for the application
 type Security struct {
-	JwtIssuer     string
-	JwtSecret     string
-	CookieName    string
-	Expiry        int
+	JwtIssuer     string `validate:"required"`
+	JwtSecret     string `validate:"required" secret:"true"`
+	CookieName    string `validate:"required"`
+	Expiry        int    `validate:"min=1"`
 	Claim         config.Claim
-	CacheDuration string
+	CacheDuration string `validate:"duration"`
 	LoginRedirect string
 }
 
 // OAuthConfig is used to configure OAuth OpenID Connect
 type OAuthConfig struct {
-	ClientID     string
-	ClientSecret string
-	RedirectURL  string
-	Provider     string
-	EndPointURL  string
+	ClientID     string `validate:"required"`
+	ClientSecret string `validate:"required" secret:"true"`
+	RedirectURL  string `validate:"required,url"`
+	Provider     string `validate:"required,url"`
+	EndPointURL  string `validate:"url"`
 }
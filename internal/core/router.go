@@ -31,6 +31,21 @@ type HTTPHandlerOptions struct {
 // MakeHTTPHandler creates a new handler implementation which is used together with the HTTP server
 func MakeHTTPHandler(oidcSvc oidc.Service, siteSvc sites.Service, cryptSvc crypter.EncryptionService, logger logging.Logger, opts HTTPHandlerOptions) http.Handler {
 	std, sec := setupRouter(opts, logger)
+
+	// use this for development purposes only!
+	develop.SetupDevTokenHandler(std, logger, opts.Config.Environment)
+
+	std.Mount("/", sec)
+
+	notFound := MountRoutes(std, sec, oidcSvc, siteSvc, cryptSvc, logger, opts)
+	std.NotFound(notFound)
+
+	return std
+}
+
+// MountRoutes adds the paths of the core service to the given routers. Public paths are
+// added to std, the secured paths to sec. The returned handler displays the not-found page.
+func MountRoutes(std, sec chi.Router, oidcSvc oidc.Service, siteSvc sites.Service, cryptSvc crypter.EncryptionService, logger logging.Logger, opts HTTPHandlerOptions) http.HandlerFunc {
 	oidcHandler := api.OidcHandler{
 		OidcSvc: oidcSvc,
 		Logger:  logger,
@@ -58,9 +73,6 @@ func MakeHTTPHandler(oidcSvc oidc.Service, siteSvc sites.Service, cryptSvc crypt
 		Build:      opts.Build,
 	}
 
-	// use this for development purposes only!
-	develop.SetupDevTokenHandler(std, logger, opts.Config.Environment)
-
 	// server-side rendered paths
 	// the following paths provide server-rendered UIs
 	// /403 displays a page telling the user that access/permissions are missing
@@ -69,6 +81,8 @@ func MakeHTTPHandler(oidcSvc oidc.Service, siteSvc sites.Service, cryptSvc crypt
 	// the OIDC logic and handshake to authenticate with external auth system
 	std.Mount("/oidc", func() http.Handler {
 		r := chi.NewRouter()
+		// the OIDC flow is not authenticated, requests are limited per client-ip
+		r.Use(server.SetupRateLimit(opts.Config.RateLimit, "oidc", logger))
 		r.Get("/start", oidcHandler.HandlePrepIntOIDCRedirect())
 		r.Get(oidc.OIDCInitiateRoutingPath, oidcHandler.HandleGetExtOIDCRedirect())
 		r.Get("/signin", oidcHandler.HandleLoginOIDC(oidcHandler.JwtCookieName, oidcHandler.JwtExpiryDays))
@@ -76,8 +90,6 @@ func MakeHTTPHandler(oidcSvc oidc.Service, siteSvc sites.Service, cryptSvc crypt
 		return r
 	}())
 
-	std.Mount("/", sec)
-
 	// mount the server-side rendering paths
 	sec.Mount("/sites", func() http.Handler {
 		r := chi.NewRouter()
@@ -92,7 +104,9 @@ func MakeHTTPHandler(oidcSvc oidc.Service, siteSvc sites.Service, cryptSvc crypt
 		r := chi.NewRouter()
 		r.Get("/", templateHandler.DisplayCrypterStartPage())
 		r.Get("/search", templateHandler.DisplayCrypterStartPage())
-		r.Post("/", templateHandler.PerformCrypterAction())
+		crypterRateLimit := server.SetupRateLimit(opts.Config.RateLimit, "crypter", logger)
+		r.With(crypterRateLimit).Post("/", templateHandler.PerformCrypterAction())
+		r.With(crypterRateLimit).Post("/file", templateHandler.PerformCrypterFileAction())
 		r.Put("/toast", templateHandler.DisplayToastNotification())
 		return r
 	}())
@@ -102,14 +116,18 @@ func MakeHTTPHandler(oidcSvc oidc.Service, siteSvc sites.Service, cryptSvc crypt
 		w.WriteHeader(http.StatusOK)
 	})
 
-	std.NotFound(templateHandler.Show404())
-
-	return std
+	return templateHandler.Show404()
 }
 
 func setupRouter(opts HTTPHandlerOptions, logger logging.Logger) (router chi.Router, secureRouter chi.Router) {
-	router = server.SetupBasicRouter(opts.BasePath, opts.Config.Cookies, opts.Config.Cors, opts.Config.Assets, logger)
+	router = server.SetupBasicRouter(opts.BasePath, opts.Config.Cookies, opts.Config.Cors, opts.Config.Headers, opts.Config.Assets, logger)
+	secureRouter = chi.NewRouter()
+	secureRouter.Use(JWTInterceptor(opts, logger))
+	return
+}
 
+// JWTInterceptor validates the JWT of requests and requires the claim of the core service
+func JWTInterceptor(opts HTTPHandlerOptions, logger logging.Logger) func(http.Handler) http.Handler {
 	// add a middleware to "catch" security errors and present a human-readable form
 	// if the client requests "application/json" just use the the problem-json format
 	jwtOptions := security.JwtOptions{
@@ -132,9 +150,5 @@ func setupRouter(opts HTTPHandlerOptions, logger logging.Logger) (router chi.Rou
 		Options:       jwtOptions,
 		ErrorRedirect: "/core/403",
 	}
-
-	secureRouter = chi.NewRouter()
-	secureRouter.Use(interceptor.HandleJWT)
-
-	return
+	return interceptor.HandleJWT
 }
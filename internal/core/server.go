@@ -1,6 +1,8 @@
 package core
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 
 	"golang.binggl.net/monorepo/internal/common/crypter"
@@ -13,41 +15,55 @@ import (
 	"golang.binggl.net/monorepo/pkg/logging"
 	"golang.binggl.net/monorepo/pkg/persistence"
 	"golang.binggl.net/monorepo/pkg/server"
+	"golang.binggl.net/monorepo/pkg/tracing"
 )
 
 // run is the entry-point for the core/auth service
 // where initialization, setup and execution is done
 func Run(version, build, appName string) error {
-	hostname, port, basePath, appCfg := server.ReadConfig[conf.AppConfig]("CO")
+	hostname, port, basePath, appCfg, err := server.ReadConfig[conf.AppConfig]("CO")
+	if err != nil {
+		return err
+	}
 
 	// use the new pkg logger implementation
 	logger := logConfig(appCfg)
 	// ensure closing of logfile on exit
 	defer logger.Close()
 
+	shutdownTracing, err := tracing.Setup(tracing.Options{
+		AppName: appCfg.AppName,
+		Version: version,
+		HostID:  appCfg.HostID,
+		Config:  appCfg.Tracing,
+	})
+	if err != nil {
+		panic(fmt.Sprintf("cannot setup tracing: %v", err))
+	}
+	// flush pending spans on exit
+	defer shutdownTracing(context.Background())
+
 	// persistence store && application version
 	db := persistence.MustCreateSqliteConn(appCfg.Database.ConnectionString)
 	defer db.Close()
 
-	con, err := persistence.CreateGormSqliteCon(db)
+	health := server.NewHealth()
+	svc, err := Setup(db, appCfg, logger, health.Register)
 	if err != nil {
 		panic(fmt.Sprintf("cannot create database connection: %v", err))
 	}
+	handler := MakeHTTPHandler(svc.OIDC, svc.Sites, svc.Crypter, logger, HTTPHandlerOptions{
+		BasePath:  basePath,
+		ErrorPath: appCfg.ErrorPath,
+		Config:    appCfg,
+		Version:   version,
+		Build:     build,
+	})
 
-	var (
-		repo                     = store.NewDBStore(con)
-		oidcConfig, oidcVerifier = oidc.NewConfigAndVerifier(appCfg.OIDC)
-		oidcSvc                  = oidc.New(oidcConfig, oidcVerifier, appCfg.Security, repo)
-		siteSvc                  = sites.New(appCfg.Security.Claim.Roles[0], repo)
-		crypterSvc               = crypter.NewService(logger)
-		handler                  = MakeHTTPHandler(oidcSvc, siteSvc, crypterSvc, logger, HTTPHandlerOptions{
-			BasePath:  basePath,
-			ErrorPath: appCfg.ErrorPath,
-			Config:    appCfg,
-			Version:   version,
-			Build:     build,
-		})
-	)
+	listen, err := server.NewListenOptions(appCfg.Server)
+	if err != nil {
+		return err
+	}
 
 	// only run the reload-server in development
 	if appCfg.Environment == config.Development {
@@ -64,9 +80,39 @@ func Run(version, build, appName string) error {
 		Environment:   string(appCfg.Environment),
 		ServerHandler: handler,
 		Logger:        logger,
+		Health:        health,
+		Listen:        listen,
 	})
 }
 
+// Services are the components of core used by the http handlers
+type Services struct {
+	OIDC    oidc.Service
+	Sites   sites.Service
+	Crypter crypter.EncryptionService
+}
+
+// Setup creates the services of core using the given database and registers the readiness
+// checks of its dependencies. It is used by Run and the combined server.
+func Setup(db *sql.DB, appCfg conf.AppConfig, logger logging.Logger, register func(string, server.CheckFunc)) (Services, error) {
+	con, err := persistence.CreateGormSqliteCon(db)
+	if err != nil {
+		return Services{}, err
+	}
+
+	var (
+		repo                     = store.NewDBStore(con)
+		oidcConfig, oidcVerifier = oidc.NewConfigAndVerifier(appCfg.OIDC)
+	)
+	register("database", server.SqliteCheck(db))
+
+	return Services{
+		OIDC:    oidc.New(oidcConfig, oidcVerifier, appCfg.Security, repo),
+		Sites:   sites.New(appCfg.Security.Claim.Roles[0], repo),
+		Crypter: crypter.NewService(logger),
+	}, nil
+}
+
 func logConfig(cfg conf.AppConfig) logging.Logger {
 	return logging.New(logging.LogConfig{
 		FilePath:      cfg.Logging.FilePath,
@@ -2,6 +2,8 @@ package html
 
 import (
 	"golang.binggl.net/monorepo/internal/common"
+	base "golang.binggl.net/monorepo/pkg/handler/html"
+	"golang.binggl.net/monorepo/pkg/security"
 	g "maragu.dev/gomponents"
 	h "maragu.dev/gomponents/html"
 )
@@ -12,10 +14,33 @@ type ValidatorInput struct {
 	Message string
 }
 
+// the formats available to encrypt text
+const (
+	FormatAES = "aes"
+	FormatAge = "age"
+)
+
 type CrypterModel struct {
 	Passphrase ValidatorInput
 	InputText  ValidatorInput
 	OutputText ValidatorInput
+	// Format used to encrypt the text, decryption detects the format
+	Format string
+	// Recipients are age public keys, one per line
+	Recipients ValidatorInput
+	File       ValidatorInput
+	// CSRFToken is needed for the file-upload which is not sent by htmx
+	CSRFToken string
+	// Reencrypt replaces the decrypted ciphertext by the current format
+	Reencrypt bool
+	// Legacy is set if the decrypted ciphertext uses the previous format
+	Legacy bool
+	// Pdf protects PDF files using the encryption of the PDF standard instead of age
+	Pdf           bool
+	OwnerPassword ValidatorInput
+	PermPrint     bool
+	PermCopy      bool
+	PermModify    bool
 }
 
 const changePasswordJS = `
@@ -33,12 +58,21 @@ try {
 }
 `
 
-func CrypterContent(model CrypterModel) g.Node {
+func CrypterContent(model CrypterModel, nonce string) g.Node {
 	return h.Div(h.ID("age_content_area"), h.Class("container-fluid age_content"), g.Attr("data-bs-theme", "light"),
 		h.Div(h.Class("row"),
 			h.Form(g.Attr("hx-post", "/crypter"), g.Attr("hx-trigger", "performCrypterAction from:document"), g.Attr("hx-swap", "outerHTML"), g.Attr("hx-indicator", "#request_indicator"),
 				h.P(h.Class("mb-3 page_label"),
-					g.Text("To encrypt and decrypt content AES is used. The passphrase needs to be remembered to decrypt a given input."),
+					g.Text("To encrypt and decrypt content AES or age is used. The passphrase needs to be remembered to decrypt a given input."),
+				),
+				h.Input(h.Type("hidden"), h.Name(security.CSRFFormField), h.Value(model.CSRFToken)),
+
+				h.Div(h.Class("mb-3"),
+					h.Label(h.For("crypter_format"), h.Class("form-label"), g.Text("Format: ")),
+					h.Select(h.ID("crypter_format"), h.Name("crypter_format"), h.Class("form-select"),
+						h.Option(h.Value(FormatAES), g.If(model.Format != FormatAge, h.Selected()), g.Text("AES")),
+						h.Option(h.Value(FormatAge), g.If(model.Format == FormatAge, h.Selected()), g.Text("age - compatible with the age CLI")),
+					),
 				),
 
 				h.Div(h.Class("mb-3"),
@@ -62,6 +96,20 @@ func CrypterContent(model CrypterModel) g.Node {
 						),
 						g.If(!model.Passphrase.Valid, h.Div(h.Class("invalid_input"), g.Text(model.Passphrase.Message))),
 					),
+					h.Div(h.Class("form-text"), g.Text("age files encrypted for public keys are decrypted using the secret key 'AGE-SECRET-KEY-1...' as passphrase.")),
+				),
+
+				h.Div(h.Class("mb-3"),
+					h.Label(h.For("crypter_recipients"), h.Class("form-label"), g.Text("Recipients: ")),
+					h.Textarea(
+						h.Class(common.ClassCond("form-control", "control_invalid", !model.Recipients.Valid)),
+						h.ID("crypter_recipients"),
+						h.Name("crypter_recipients"),
+						h.Placeholder("optional age public keys 'age1...', one per line; used instead of the passphrase"),
+						h.Rows("2"),
+						g.Text(model.Recipients.Val),
+					),
+					g.If(!model.Recipients.Valid, h.Div(h.Class("invalid_input"), g.Text(model.Recipients.Message))),
 				),
 
 				h.Div(h.Class("mb-3"),
@@ -88,13 +136,82 @@ func CrypterContent(model CrypterModel) g.Node {
 						g.Raw(model.OutputText.Val),
 					),
 					g.If(!model.OutputText.Valid, h.Div(h.Class("invalid_input"), g.Text(model.OutputText.Message))),
+					g.If(model.Legacy, h.Div(h.Class("form-text"), g.Text("The text uses the previous encryption format, it can be converted using the re-encrypt option."))),
+					h.Div(h.Class("form-check mt-2"),
+						h.Input(
+							h.Type("checkbox"),
+							h.Class("form-check-input"),
+							h.ID("crypter_reencrypt"),
+							h.Name("crypter_reencrypt"),
+							g.If(model.Reencrypt, h.Checked()),
+						),
+						h.Label(h.For("crypter_reencrypt"), h.Class("form-check-label"), g.Text("Re-encrypt: decrypt the text and encrypt it again using the current format")),
+					),
+				),
+
+				h.Div(h.Class("mb-3"),
+					h.Label(h.For("crypter_file"), h.Class("form-label"), g.Text("File: ")),
+					h.Div(h.Class("input-group"),
+						h.Input(
+							h.Type("file"),
+							h.ID("crypter_file"),
+							h.Name("crypter_file"),
+							h.Class(common.ClassCond("form-control", "control_invalid", !model.File.Valid)),
+						),
+						// the result is a download, the form is submitted without htmx
+						h.Button(
+							h.Type("submit"),
+							h.ID("crypter_file_action"),
+							h.Class("btn btn-outline-secondary"),
+							g.Attr("formaction", "/crypter/file"),
+							g.Attr("formmethod", "post"),
+							g.Attr("formenctype", "multipart/form-data"),
+							h.I(h.Class("bi bi-file-earmark-lock")),
+							g.Text(" Encrypt / Decrypt file"),
+						),
+					),
+					h.Div(h.Class("form-text"), g.Text("Files are encrypted using age, age files are decrypted. The result is downloaded.")),
+					g.If(!model.File.Valid, h.Div(h.Class("invalid_input"), g.Text(model.File.Message))),
+				),
+
+				h.Div(h.Class("mb-3"),
+					h.Div(h.Class("form-check"),
+						h.Input(h.Type("checkbox"), h.Class("form-check-input"), h.ID("crypter_pdf"), h.Name("crypter_pdf"), g.If(model.Pdf, h.Checked())),
+						h.Label(h.For("crypter_pdf"), h.Class("form-check-label"), g.Text("PDF: use AES-256 PDF encryption with the passphrase as password; encrypted PDFs are decrypted")),
+					),
+					h.Div(h.Class("row mt-2"),
+						h.Div(h.Class("col-md-6"),
+							h.Input(
+								h.Type("password"),
+								h.ID("crypter_pdf_owner"),
+								h.Name("crypter_pdf_owner"),
+								h.Placeholder("optional owner password, full access to the PDF"),
+								h.Class(common.ClassCond("form-control", "control_invalid", !model.OwnerPassword.Valid)),
+								h.Value(model.OwnerPassword.Val),
+							),
+							g.If(!model.OwnerPassword.Valid, h.Div(h.Class("invalid_input"), g.Text(model.OwnerPassword.Message))),
+						),
+						h.Div(h.Class("col-md-6"),
+							permissionCheck("crypter_pdf_print", "Print", model.PermPrint),
+							permissionCheck("crypter_pdf_copy", "Copy", model.PermCopy),
+							permissionCheck("crypter_pdf_modify", "Modify", model.PermModify),
+						),
+					),
+					h.Div(h.Class("form-text"), g.Text("The permissions restrict the usage of the PDF opened with the passphrase.")),
 				),
 			),
-			h.Script(h.Type("text/javascript"), g.Raw(changePasswordJS)),
+			base.Script(nonce, changePasswordJS),
 		),
 	)
 }
 
+func permissionCheck(id, label string, checked bool) g.Node {
+	return h.Div(h.Class("form-check form-check-inline"),
+		h.Input(h.Type("checkbox"), h.Class("form-check-input"), h.ID(id), h.Name(id), g.If(checked, h.Checked())),
+		h.Label(h.For(id), h.Class("form-check-label"), g.Text(label)),
+	)
+}
+
 const crypterHeaderStyle = `
 body {
 	background-color: #F2F2F2;
@@ -154,7 +271,7 @@ try {
 }
 `
 
-func CrypterNavigation(search string) g.Node {
+func CrypterNavigation(search, nonce string) g.Node {
 
 	return h.Nav(h.Class("navbar navbar-expand application_name"),
 		h.Div(h.Class("container-fluid"),
@@ -179,7 +296,7 @@ func CrypterNavigation(search string) g.Node {
 						g.Text(" Action"),
 					),
 				),
-				h.Script(h.Type("text/javascript"), g.Raw(triggerCrypterAction)),
+				base.Script(nonce, triggerCrypterAction),
 			),
 		),
 	)
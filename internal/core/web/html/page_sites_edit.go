@@ -1,6 +1,7 @@
 package html
 
 import (
+	base "golang.binggl.net/monorepo/pkg/handler/html"
 	g "maragu.dev/gomponents"
 	h "maragu.dev/gomponents/html"
 )
@@ -33,7 +34,7 @@ try {
 }
 `
 
-func SiteEditNavigation(search string) g.Node {
+func SiteEditNavigation(search, nonce string) g.Node {
 
 	return h.Nav(h.Class("navbar navbar-expand application_name"),
 		h.Div(h.Class("container-fluid"),
@@ -54,7 +55,7 @@ func SiteEditNavigation(search string) g.Node {
 					g.Raw("&nbsp;"),
 					h.Button(h.Type("button"), h.ID("btn_save_sites"), h.Class("btn btn-success"), h.I(h.Class("bi bi-save")), g.Text(" Save")),
 				),
-				h.Script(h.Type("text/javascript"), g.Raw(javascriptContent)),
+				base.Script(nonce, javascriptContent),
 			),
 		),
 	)
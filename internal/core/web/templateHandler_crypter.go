@@ -1,37 +1,233 @@
 package web
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"path"
+	"strings"
 
 	"golang.binggl.net/monorepo/internal/common"
 	"golang.binggl.net/monorepo/internal/common/crypter"
 	"golang.binggl.net/monorepo/internal/core/web/html"
 	base "golang.binggl.net/monorepo/pkg/handler/html"
+	"golang.binggl.net/monorepo/pkg/security"
 )
 
-const ageSearchURL = "/crypter/search"
+const (
+	ageSearchURL      = "/crypter/search"
+	crypterFormPrefix = "crypter_"
+	ageFileExtension  = ".age"
+	// files are processed in memory, limit the size
+	maxCrypterFileSize = 20 << 20
+	// the form fields and the multipart encoding are allowed in addition to the file
+	crypterFormOverhead = 1 << 20
+)
 
 // DisplayCrypterStartPage is used to show the start-page of the util app for encryption/decryption
 func (t *TemplateHandler) DisplayCrypterStartPage() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		user := common.EnsureUser(r)
-		search := ""
 		t.Logger.InfoRequest(fmt.Sprintf("display crypter start-page for user: '%s'", user.Username), r)
 
-		model := html.CrypterModel{
-			Passphrase: html.ValidatorInput{Valid: true},
-			InputText:  html.ValidatorInput{Valid: true},
-			OutputText: html.ValidatorInput{Valid: true},
+		t.renderCrypterPage(w, r, *user, newCrypterModel(r))
+	}
+}
+
+// PerformCrypterFileAction encrypts an uploaded file using age or decrypts an uploaded age file.
+// PDF files optionally use the encryption of the PDF standard.
+// The form is not submitted by htmx, the result is returned as a download.
+func (t *TemplateHandler) PerformCrypterFileAction() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := common.EnsureUser(r)
+		t.Logger.InfoRequest(fmt.Sprintf("perform file action for user: '%s'", user.Username), r)
+
+		form := newCrypterModel(r)
+		// limit the request before the form is parsed, larger bodies are not read at all
+		r.Body = http.MaxBytesReader(w, r.Body, maxCrypterFileSize+crypterFormOverhead)
+		var tooLarge *http.MaxBytesError
+		if err := r.ParseMultipartForm(maxCrypterFileSize); errors.As(err, &tooLarge) {
+			form.File.Valid = false
+			form.File.Message = fmt.Sprintf("the maximum size of the file is %d MB", maxCrypterFileSize>>20)
+			t.renderCrypterPage(w, r, *user, form)
+			return
+		}
+		passphrase := r.FormValue(crypterFormPrefix + "passphrase")
+		form.Format = r.FormValue(crypterFormPrefix + "format")
+		form.Recipients.Val = r.FormValue(crypterFormPrefix + "recipients")
+		recipients := recipientList(form.Recipients.Val)
+		form.Pdf = r.FormValue(crypterFormPrefix+"pdf") == "on"
+		form.OwnerPassword.Val = r.FormValue(crypterFormPrefix + "pdf_owner")
+		form.PermPrint = r.FormValue(crypterFormPrefix+"pdf_print") == "on"
+		form.PermCopy = r.FormValue(crypterFormPrefix+"pdf_copy") == "on"
+		form.PermModify = r.FormValue(crypterFormPrefix+"pdf_modify") == "on"
+
+		file, meta, err := r.FormFile(crypterFormPrefix + "file")
+		if err != nil {
+			t.Logger.ErrorRequest(fmt.Sprintf("could not get uploaded file; '%v'", err), r)
+			form.File.Valid = false
+			form.File.Message = "a file is needed"
+			t.renderCrypterPage(w, r, *user, form)
+			return
+		}
+		defer file.Close()
+		if meta.Size > maxCrypterFileSize {
+			form.File.Valid = false
+			form.File.Message = fmt.Sprintf("the maximum size of the file is %d MB", maxCrypterFileSize>>20)
+			t.renderCrypterPage(w, r, *user, form)
+			return
+		}
+		payload, err := io.ReadAll(file)
+		if err != nil {
+			t.Logger.ErrorRequest(fmt.Sprintf("could not read uploaded file; '%v'", err), r)
+			form.File.Valid = false
+			form.File.Message = err.Error()
+			t.renderCrypterPage(w, r, *user, form)
+			return
+		}
+
+		var (
+			result      []byte
+			name        = path.Base(meta.Filename)
+			contentType = "application/octet-stream"
+		)
+		if form.Pdf {
+			if !crypter.IsPDF(payload) {
+				form.File.Valid = false
+				form.File.Message = "the file is not a PDF"
+				t.renderCrypterPage(w, r, *user, form)
+				return
+			}
+			if passphrase == "" {
+				form.Passphrase = html.ValidatorInput{Val: passphrase, Message: "a passphrase is needed"}
+				t.renderCrypterPage(w, r, *user, form)
+				return
+			}
+			req := crypter.Request{
+				Payload:  payload,
+				Password: passphrase,
+				Type:     crypter.PDF,
+			}
+			if crypter.IsEncryptedPDF(payload) {
+				result, err = t.CrypterSvc.Decrypt(r.Context(), req)
+			} else {
+				req.OwnerPassword = form.OwnerPassword.Val
+				req.Permissions = crypter.PdfPermissions{Print: form.PermPrint, Copy: form.PermCopy, Modify: form.PermModify}
+				result, err = t.CrypterSvc.Encrypt(r.Context(), req)
+			}
+			contentType = "application/pdf"
+		} else if crypter.IsAge(payload) {
+			if msg := validatePassphrase(passphrase, true, true); msg != "" {
+				form.Passphrase = html.ValidatorInput{Val: passphrase, Message: msg}
+				t.renderCrypterPage(w, r, *user, form)
+				return
+			}
+			result, err = t.CrypterSvc.Decrypt(r.Context(), crypter.Request{
+				Payload:  payload,
+				Password: passphrase,
+				Type:     crypter.Age,
+			})
+			name = decryptedFileName(name)
+		} else {
+			if msg := validatePassphrase(passphrase, true, len(recipients) == 0); msg != "" {
+				form.Passphrase = html.ValidatorInput{Val: passphrase, Message: msg}
+				t.renderCrypterPage(w, r, *user, form)
+				return
+			}
+			result, err = t.CrypterSvc.Encrypt(r.Context(), crypter.Request{
+				Payload:    payload,
+				Password:   passphrase,
+				Type:       crypter.Age,
+				Recipients: recipients,
+			})
+			name += ageFileExtension
+		}
+		if err != nil {
+			t.Logger.ErrorRequest(fmt.Sprintf("cannot process the provided file; '%v'", err), r)
+			form.File.Valid = false
+			form.File.Message = err.Error()
+			t.renderCrypterPage(w, r, *user, form)
+			return
+		}
+
+		security.RestrictPayload(w)
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+		w.Write(result)
+	}
+}
+
+// reencrypt encrypts the text using the current format and returns the armored result
+func (t *TemplateHandler) reencrypt(r *http.Request, text []byte, passphrase string) (string, error) {
+	encryptedBytes, err := t.CrypterSvc.Encrypt(r.Context(), crypter.Request{
+		Payload:  text,
+		Password: passphrase,
+		Type:     crypter.String,
+	})
+	if err != nil {
+		return "", err
+	}
+	return crypter.Armor(string(encryptedBytes))
+}
+
+func (t *TemplateHandler) renderCrypterPage(w http.ResponseWriter, r *http.Request, user security.User, model html.CrypterModel) {
+	search := ""
+	base.Layout(
+		common.CreatePageModel(r.Context(), "/crypter", "helpers to work with encryption/decryption", search, "/public/crypter.svg", t.Version, t.Build, t.Env, user),
+		html.CrypterStyle(),
+		html.CrypterNavigation(search, security.NonceFromContext(r.Context())),
+		html.CrypterContent(model, security.NonceFromContext(r.Context())),
+		ageSearchURL,
+	).Render(w)
+}
+
+func newCrypterModel(r *http.Request) html.CrypterModel {
+	return html.CrypterModel{
+		Passphrase:    html.ValidatorInput{Valid: true},
+		InputText:     html.ValidatorInput{Valid: true},
+		OutputText:    html.ValidatorInput{Valid: true},
+		Recipients:    html.ValidatorInput{Valid: true},
+		File:          html.ValidatorInput{Valid: true},
+		OwnerPassword: html.ValidatorInput{Valid: true},
+		CSRFToken:     security.CSRFTokenFromContext(r.Context()),
+	}
+}
+
+// validatePassphrase returns a message if the passphrase is not valid, age also accepts secret keys
+func validatePassphrase(passphrase string, age, required bool) string {
+	if passphrase == "" {
+		if required {
+			return "a passphrase is needed"
+		}
+		return ""
+	}
+	if age && crypter.IsAgeIdentity(passphrase) {
+		return ""
+	}
+	if len(passphrase) < 5 {
+		return "the minimum length of the passphrase is 5 chars"
+	}
+	return ""
+}
+
+// recipientList splits the recipients provided one per line
+func recipientList(recipients string) []string {
+	var list []string
+	for _, r := range strings.Split(recipients, "\n") {
+		if r = strings.TrimSpace(r); r != "" {
+			list = append(list, r)
 		}
-		base.Layout(
-			common.CreatePageModel("/crypter", "helpers to work with encryption/decryption", search, "/public/crypter.svg", t.Version, t.Build, t.Env, *user),
-			html.CrypterStyle(),
-			html.CrypterNavigation(search),
-			html.CrypterContent(model),
-			ageSearchURL,
-		).Render(w)
 	}
+	return list
+}
+
+func decryptedFileName(name string) string {
+	if trimmed := strings.TrimSuffix(name, ageFileExtension); trimmed != name && trimmed != "" {
+		return trimmed
+	}
+	return name + ".decrypted"
 }
 
 // PerformCrypterAction takes the provided input (passphrase, inputText, encryptedText) and encrypts/decrypts
@@ -48,16 +244,26 @@ func (t *TemplateHandler) PerformCrypterAction() http.HandlerFunc {
 		}
 
 		var (
-			form       html.CrypterModel
-			formPrefix = "crypter_"
+			form       = newCrypterModel(r)
 			passphrase string
 			inputText  string
 			outputText string
 		)
 
-		passphrase = r.FormValue(formPrefix + "passphrase")
-		inputText = r.FormValue(formPrefix + "input")
-		outputText = r.FormValue(formPrefix + "output")
+		passphrase = r.FormValue(crypterFormPrefix + "passphrase")
+		inputText = r.FormValue(crypterFormPrefix + "input")
+		outputText = r.FormValue(crypterFormPrefix + "output")
+		form.Format = r.FormValue(crypterFormPrefix + "format")
+		form.Recipients.Val = r.FormValue(crypterFormPrefix + "recipients")
+		form.Reencrypt = r.FormValue(crypterFormPrefix+"reencrypt") == "on"
+		recipients := recipientList(form.Recipients.Val)
+
+		// encryption uses the selected format, decryption detects age by its armor
+		useAge := form.Format == html.FormatAge
+		if inputText == "" && outputText != "" {
+			useAge = crypter.IsAge([]byte(outputText))
+		}
+		encryptForRecipients := useAge && inputText != "" && len(recipients) > 0
 
 		// form model and validation
 		validData := true
@@ -65,21 +271,10 @@ func (t *TemplateHandler) PerformCrypterAction() http.HandlerFunc {
 		form.InputText = html.ValidatorInput{Val: inputText, Valid: true}
 		form.OutputText = html.ValidatorInput{Val: outputText, Valid: true}
 
-		if passphrase == "" {
+		if msg := validatePassphrase(passphrase, useAge, !encryptForRecipients); msg != "" {
 			form.Passphrase.Valid = false
-			form.Passphrase.Message = "a passphrase is needed"
+			form.Passphrase.Message = msg
 			validData = false
-		} else {
-			if len(passphrase) > 32 {
-				form.Passphrase.Valid = false
-				form.Passphrase.Message = "the maximum length of the passphrase is 32 chars"
-				validData = false
-			}
-			if len(passphrase) < 5 {
-				form.Passphrase.Valid = false
-				form.Passphrase.Message = "the minimum length of the passphrase is 5 chars"
-				validData = false
-			}
 		}
 
 		if inputText == "" && outputText == "" {
@@ -100,7 +295,27 @@ func (t *TemplateHandler) PerformCrypterAction() http.HandlerFunc {
 
 		if validData {
 			// happy path
-			if inputText != "" {
+			if inputText != "" && useAge {
+				encryptedBytes, err := t.CrypterSvc.Encrypt(r.Context(), crypter.Request{
+					Payload:    []byte(inputText),
+					Password:   passphrase,
+					Type:       crypter.Age,
+					Recipients: recipients,
+				})
+				if err != nil {
+					t.Logger.ErrorRequest(fmt.Sprintf("cannot encrypt provided data; '%v'", err), r)
+
+					form.InputText.Valid = false
+					form.InputText.Message = err.Error()
+
+					html.CrypterContent(form, security.NonceFromContext(r.Context())).Render(w)
+					return
+				}
+				// age already provides the armor
+				form.OutputText.Val = string(encryptedBytes)
+				form.OutputText.Valid = true
+
+			} else if inputText != "" {
 				encryptedBytes, err := t.CrypterSvc.Encrypt(r.Context(), crypter.Request{
 					Payload:  []byte(inputText),
 					Password: passphrase,
@@ -112,7 +327,7 @@ func (t *TemplateHandler) PerformCrypterAction() http.HandlerFunc {
 					form.InputText.Valid = false
 					form.InputText.Message = err.Error()
 
-					html.CrypterContent(form).Render(w)
+					html.CrypterContent(form, security.NonceFromContext(r.Context())).Render(w)
 					return
 				}
 				// we want to have a nice "armor" output
@@ -123,13 +338,31 @@ func (t *TemplateHandler) PerformCrypterAction() http.HandlerFunc {
 					form.InputText.Valid = false
 					form.InputText.Message = err.Error()
 
-					html.CrypterContent(form).Render(w)
+					html.CrypterContent(form, security.NonceFromContext(r.Context())).Render(w)
 					return
 				}
 
 				form.OutputText.Val = armor
 				form.OutputText.Valid = true
 
+			} else if outputText != "" && useAge {
+				decryptedBytes, err := t.CrypterSvc.Decrypt(r.Context(), crypter.Request{
+					Payload:  []byte(outputText),
+					Password: passphrase,
+					Type:     crypter.Age,
+				})
+				if err != nil {
+					t.Logger.ErrorRequest(fmt.Sprintf("cannot decrypt provided data; '%v'", err), r)
+
+					form.OutputText.Valid = false
+					form.OutputText.Message = err.Error()
+
+					html.CrypterContent(form, security.NonceFromContext(r.Context())).Render(w)
+					return
+				}
+				form.InputText.Val = string(decryptedBytes)
+				form.InputText.Valid = true
+
 			} else if outputText != "" {
 				// the cipher input has an armor format / de-armor it first
 				dearmor, err := crypter.DeArmor(outputText)
@@ -139,7 +372,7 @@ func (t *TemplateHandler) PerformCrypterAction() http.HandlerFunc {
 					form.OutputText.Valid = false
 					form.OutputText.Message = err.Error()
 
-					html.CrypterContent(form).Render(w)
+					html.CrypterContent(form, security.NonceFromContext(r.Context())).Render(w)
 					return
 				}
 
@@ -154,20 +387,42 @@ func (t *TemplateHandler) PerformCrypterAction() http.HandlerFunc {
 					form.OutputText.Valid = false
 					form.OutputText.Message = err.Error()
 
-					html.CrypterContent(form).Render(w)
+					html.CrypterContent(form, security.NonceFromContext(r.Context())).Render(w)
 					return
 				}
 				form.InputText.Val = string(decryptedBytes)
 				form.InputText.Valid = true
+				form.Legacy = crypter.IsLegacy([]byte(dearmor))
+
+				if form.Reencrypt {
+					// replace the ciphertext by the current format, the plain text is not displayed
+					armor, err := t.reencrypt(r, decryptedBytes, passphrase)
+					if err != nil {
+						t.Logger.ErrorRequest(fmt.Sprintf("cannot re-encrypt provided data; '%v'", err), r)
+
+						form.OutputText.Valid = false
+						form.OutputText.Message = err.Error()
+
+						html.CrypterContent(form, security.NonceFromContext(r.Context())).Render(w)
+						return
+					}
+					form.InputText.Val = ""
+					form.OutputText.Val = armor
+					form.Legacy = false
+
+					triggerToast(w, base.MsgSuccess, "Crypter in action", "Re-encrypted the provided input!")
+					html.CrypterContent(form, security.NonceFromContext(r.Context())).Render(w)
+					return
+				}
 			}
 
 			triggerToast(w, base.MsgSuccess, "Crypter in action", "Processed the provided input!")
 
-			html.CrypterContent(form).Render(w)
+			html.CrypterContent(form, security.NonceFromContext(r.Context())).Render(w)
 			return
 		}
 		triggerToast(w, base.MsgWarning, "Validation", "Invalid input provided!")
 
-		html.CrypterContent(form).Render(w)
+		html.CrypterContent(form, security.NonceFromContext(r.Context())).Render(w)
 	}
 }
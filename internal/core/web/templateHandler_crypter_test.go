@@ -1,15 +1,19 @@
 package web_test
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"golang.binggl.net/monorepo/internal/common/crypter"
 	"golang.binggl.net/monorepo/internal/core/app/sites"
 	"golang.binggl.net/monorepo/pkg/security"
 )
@@ -102,6 +106,65 @@ func Test_Decrypt(t *testing.T) {
 	assert.Contains(t, string(body), "hello, world - from unit-test")
 }
 
+func Test_DecryptLegacyHint(t *testing.T) {
+	th := templateHandler(&mockSiteService{})
+	form := url.Values{}
+	form.Add("crypter_passphrase", "test1")
+	form.Add("crypter_output", encryptedText)
+	req := httptest.NewRequest("POST", "/crypter", strings.NewReader(form.Encode()))
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	addJwtAuth(req)
+	rec := httptest.NewRecorder()
+
+	th.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "uses the previous encryption format")
+}
+
+func Test_Reencrypt(t *testing.T) {
+	th := templateHandler(&mockSiteService{})
+
+	// arrange
+	form := url.Values{}
+	form.Add("crypter_passphrase", "test1")
+	form.Add("crypter_output", encryptedText)
+	form.Add("crypter_reencrypt", "on")
+	req := httptest.NewRequest("POST", "/crypter", strings.NewReader(form.Encode()))
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	addJwtAuth(req)
+	rec := httptest.NewRecorder()
+
+	// act
+	th.ServeHTTP(rec, req)
+
+	// assert
+	assert.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.NotContains(t, body, "hello, world - from unit-test")
+	assert.NotContains(t, body, "uses the previous encryption format")
+	start := strings.Index(body, "-----BEGIN ENCRYPTED CONTENT-----")
+	end := strings.Index(body, "-----END ENCRYPTED CONTENT-----")
+	assert.True(t, start > 0 && end > start)
+	reencrypted := body[start : end+len("-----END ENCRYPTED CONTENT-----")]
+	assert.NotEqual(t, encryptedText, reencrypted)
+
+	// the new ciphertext decrypts to the original text
+	form = url.Values{}
+	form.Add("crypter_passphrase", "test1")
+	form.Add("crypter_output", reencrypted)
+	req = httptest.NewRequest("POST", "/crypter", strings.NewReader(form.Encode()))
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	addJwtAuth(req)
+	rec = httptest.NewRecorder()
+
+	th.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "hello, world - from unit-test")
+	assert.NotContains(t, rec.Body.String(), "uses the previous encryption format")
+}
+
 func Test_DecryptWrongPassphrase(t *testing.T) {
 	th := templateHandler(&mockSiteService{})
 	// arrange
@@ -164,11 +227,12 @@ func Test_InputValidation(t *testing.T) {
 	body, _ = io.ReadAll(rec.Body)
 	assert.Contains(t, string(body), "the minimum length of the passphrase is 5 chars")
 
-	// max length of passphrase
+	// the key is derived from the passphrase, long passphrases are accepted
 
 	// arrange
 	form = url.Values{}
-	form.Add("crypter_passphrase", "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaA")
+	form.Add("crypter_passphrase", strings.Repeat("a", 64))
+	form.Add("crypter_input", "hello world")
 
 	req = httptest.NewRequest("POST", "/crypter", strings.NewReader(form.Encode()))
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
@@ -181,7 +245,8 @@ func Test_InputValidation(t *testing.T) {
 	// assert
 	assert.Equal(t, http.StatusOK, rec.Code)
 	body, _ = io.ReadAll(rec.Body)
-	assert.Contains(t, string(body), "the maximum length of the passphrase is 32 chars")
+	assert.NotContains(t, string(body), "the maximum length of the passphrase")
+	assert.Regexp(t, `v2\.[A-Za-z0-9_=-]{20,}`, string(body))
 
 	// both text-input fields are filled
 
@@ -226,6 +291,171 @@ func Test_InputValidation(t *testing.T) {
 	assert.Contains(t, string(body), "both fields are empty")
 }
 
+func Test_EncryptDecryptAge(t *testing.T) {
+	th := templateHandler(&mockSiteService{})
+
+	// arrange
+	form := url.Values{}
+	form.Add("crypter_format", "age")
+	form.Add("crypter_passphrase", "a long passphrase which is longer than 32 chars")
+	form.Add("crypter_input", "hello, age - from unit-test")
+	req := httptest.NewRequest("POST", "/crypter", strings.NewReader(form.Encode()))
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	addJwtAuth(req)
+	rec := httptest.NewRecorder()
+
+	// act
+	th.ServeHTTP(rec, req)
+
+	// assert
+	assert.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	start := strings.Index(body, "-----BEGIN AGE ENCRYPTED FILE-----")
+	end := strings.Index(body, "-----END AGE ENCRYPTED FILE-----")
+	assert.True(t, start > 0 && end > start)
+	encrypted := body[start : end+len("-----END AGE ENCRYPTED FILE-----")]
+
+	// the age format is detected for decryption
+	form = url.Values{}
+	form.Add("crypter_passphrase", "a long passphrase which is longer than 32 chars")
+	form.Add("crypter_output", encrypted)
+	req = httptest.NewRequest("POST", "/crypter", strings.NewReader(form.Encode()))
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	addJwtAuth(req)
+	rec = httptest.NewRecorder()
+
+	th.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "hello, age - from unit-test")
+}
+
+func fileRequest(t *testing.T, passphrase, fileName string, payload []byte, fields url.Values) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	writer.WriteField("crypter_passphrase", passphrase)
+	for key := range fields {
+		writer.WriteField(key, fields.Get(key))
+	}
+	part, err := writer.CreateFormFile("crypter_file", fileName)
+	if err != nil {
+		t.Fatalf("could not create multipart: %v", err)
+	}
+	part.Write(payload)
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/crypter/file", body)
+	req.Header.Add("Content-Type", writer.FormDataContentType())
+	addJwtAuth(req)
+	return req
+}
+
+func Test_EncryptDecryptFile(t *testing.T) {
+	th := templateHandler(&mockSiteService{})
+	payload := []byte("%PDF-1.4 some binary content \x00\x01")
+
+	// encrypt
+	rec := httptest.NewRecorder()
+	th.ServeHTTP(rec, fileRequest(t, "test1", "document.pdf", payload, nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `attachment; filename=document.pdf.age`, rec.Header().Get("Content-Disposition"))
+	encrypted := rec.Body.Bytes()
+	assert.True(t, bytes.HasPrefix(encrypted, []byte("-----BEGIN AGE ENCRYPTED FILE-----")))
+
+	// decrypt
+	rec = httptest.NewRecorder()
+	th.ServeHTTP(rec, fileRequest(t, "test1", "document.pdf.age", encrypted, nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `attachment; filename=document.pdf`, rec.Header().Get("Content-Disposition"))
+	assert.Equal(t, payload, rec.Body.Bytes())
+
+	// wrong passphrase
+	rec = httptest.NewRecorder()
+	th.ServeHTTP(rec, fileRequest(t, "wrong-passphrase", "document.pdf.age", encrypted, nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Disposition"))
+	assert.Contains(t, rec.Body.String(), "could not decrypt age payload")
+}
+
+func Test_EncryptDecryptPdf(t *testing.T) {
+	th := templateHandler(&mockSiteService{})
+	payload, err := os.ReadFile("../../../testdata/unencrypted.pdf")
+	if err != nil {
+		t.Fatalf("could not read testfile: %v", err)
+	}
+	pdf := url.Values{}
+	pdf.Add("crypter_pdf", "on")
+	pdf.Add("crypter_pdf_owner", "owner")
+	pdf.Add("crypter_pdf_print", "on")
+
+	// encrypt
+	rec := httptest.NewRecorder()
+	th.ServeHTTP(rec, fileRequest(t, "test1", "document.pdf", payload, pdf))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/pdf", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename=document.pdf`, rec.Header().Get("Content-Disposition"))
+	encrypted := rec.Body.Bytes()
+	assert.True(t, crypter.IsEncryptedPDF(encrypted))
+
+	// decrypt the testfile
+	payload, err = os.ReadFile("../../../testdata/encrypted.pdf")
+	if err != nil {
+		t.Fatalf("could not read testfile: %v", err)
+	}
+	rec = httptest.NewRecorder()
+	th.ServeHTTP(rec, fileRequest(t, "12345", "encrypted.pdf", payload, pdf))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, crypter.IsPDF(rec.Body.Bytes()))
+	assert.False(t, crypter.IsEncryptedPDF(rec.Body.Bytes()))
+
+	// wrong passphrase
+	rec = httptest.NewRecorder()
+	th.ServeHTTP(rec, fileRequest(t, "54321", "encrypted.pdf", payload, pdf))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Disposition"))
+	assert.Contains(t, rec.Body.String(), "could not decrypt PDF payload")
+
+	// only PDF files
+	rec = httptest.NewRecorder()
+	th.ServeHTTP(rec, fileRequest(t, "test1", "document.txt", []byte("hello"), pdf))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "the file is not a PDF")
+}
+
+func Test_FileValidation(t *testing.T) {
+	th := templateHandler(&mockSiteService{})
+
+	// missing passphrase
+	rec := httptest.NewRecorder()
+	th.ServeHTTP(rec, fileRequest(t, "", "document.txt", []byte("hello"), nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "a passphrase is needed")
+
+	// missing file
+	form := url.Values{}
+	form.Add("crypter_passphrase", "test1")
+	req := httptest.NewRequest("POST", "/crypter/file", strings.NewReader(form.Encode()))
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	addJwtAuth(req)
+	rec = httptest.NewRecorder()
+	th.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "a file is needed")
+
+	// the size of the request is limited
+	rec = httptest.NewRecorder()
+	th.ServeHTTP(rec, fileRequest(t, "test1", "document.txt", bytes.Repeat([]byte("a"), 22<<20), nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "the maximum size of the file is 20 MB")
+}
+
 func addJwtAuth(req *http.Request) {
 	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", validToken))
 }
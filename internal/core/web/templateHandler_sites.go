@@ -10,6 +10,7 @@ import (
 	"golang.binggl.net/monorepo/internal/core/web/html"
 	"golang.binggl.net/monorepo/pkg/handler"
 	base "golang.binggl.net/monorepo/pkg/handler/html"
+	"golang.binggl.net/monorepo/pkg/security"
 )
 
 const sitesSearchURL = "/sites/search"
@@ -29,7 +30,7 @@ func (t *TemplateHandler) DisplaySites() http.HandlerFunc {
 		}
 
 		base.Layout(
-			common.CreatePageModel(sitesBaseURL, "Available Apps", search, sitesFavicon, t.Version, t.Build, t.Env, *user),
+			common.CreatePageModel(r.Context(), sitesBaseURL, "Available Apps", search, sitesFavicon, t.Version, t.Build, t.Env, *user),
 			html.SiteStyles(),
 			html.SiteNavigation(search),
 			html.SiteContent(usrSites),
@@ -53,9 +54,9 @@ func (t *TemplateHandler) ShowEditSites() http.HandlerFunc {
 		jsonPayload := handler.JsonIndent[sites.UserSites](usrSites)
 
 		base.Layout(
-			common.CreatePageModel(sitesBaseURL, "Edit Apps", search, sitesFavicon, t.Version, t.Build, t.Env, *user),
+			common.CreatePageModel(r.Context(), sitesBaseURL, "Edit Apps", search, sitesFavicon, t.Version, t.Build, t.Env, *user),
 			html.SiteEditStyles(),
-			html.SiteEditNavigation(search),
+			html.SiteEditNavigation(search, security.NonceFromContext(r.Context())),
 			html.SiteEditContent(jsonPayload, ""),
 			sitesSearchURL,
 		).Render(w)
@@ -1,35 +1,171 @@
 package config
 
-import "golang.binggl.net/monorepo/pkg/config"
+import (
+	"time"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/filestore"
+	"golang.binggl.net/monorepo/internal/mydms/app/inbox"
+	"golang.binggl.net/monorepo/internal/mydms/app/reminder"
+	"golang.binggl.net/monorepo/pkg/config"
+)
 
 // AppConfig holds the application configuration
 type AppConfig struct {
 	config.BaseConfig
-	Database  Database
-	Filestore FileStore
-	Upload    UploadSettings
+	Database       Database
+	Filestore      FileStore
+	Upload         UploadSettings
+	Preview        PreviewSettings
+	Inbox          InboxSettings
+	Classification ClassificationSettings
+	Reminders      ReminderSettings
 }
 
 // Database defines the connection string
 type Database struct {
-	ConnectionString string
+	ConnectionString string `validate:"required"`
 }
 
 // FileStore holds configuration settings for the backend file store
 type FileStore struct {
-	Region   string
+	Region   string `validate:"required"`
 	EndPoint string
-	Bucket   string
-	Key      string
-	Secret   string
+	Bucket   string `validate:"required"`
+	Key      string `validate:"required"`
+	Secret   string `validate:"required" secret:"true"`
+	// Encryption of the stored documents
+	Encryption FileEncryption
+}
+
+// FileEncryption defines the master keys used for the envelope encryption of documents,
+// the encryption is disabled without a master key
+type FileEncryption struct {
+	// MasterKey is a base64 encoded 256-bit key, e.g. created by 'openssl rand -base64 32'
+	MasterKey string `secret:"true"`
+	// PreviousKeys are replaced master keys, needed until all documents are re-keyed
+	PreviousKeys []string `secret:"true"`
+}
+
+// Enabled is true if a master key is configured
+func (e FileEncryption) Enabled() bool {
+	return e.MasterKey != ""
+}
+
+// Keyring creates the keyring used by the filestore
+func (e FileEncryption) Keyring() (*filestore.Keyring, error) {
+	if !e.Enabled() {
+		return nil, nil
+	}
+	return filestore.NewKeyring(e.MasterKey, e.PreviousKeys...)
+}
+
+// Validate checks the format of the master keys
+func (e FileEncryption) Validate() []string {
+	if !e.Enabled() {
+		if len(e.PreviousKeys) > 0 {
+			return []string{"previous keys need a master key"}
+		}
+		return nil
+	}
+	if _, err := e.Keyring(); err != nil {
+		return []string{err.Error()}
+	}
+	return nil
+}
+
+// PreviewSettings define the thumbnails created for the documents
+type PreviewSettings struct {
+	// Width of the thumbnails in pixels
+	Width int
+	// Renderer is the path of pdftoppm used to render the first page of a PDF.
+	// Without a renderer the largest image of the first page is used as preview.
+	Renderer string
+}
+
+// InboxSettings define a local directory which is watched for new documents, e.g. the drop folder
+// of a scanner. The inbox is disabled without a path.
+type InboxSettings struct {
+	Path string
+	// Processed and Failed are the directories of the handled files, default to subdirectories of Path
+	Processed string
+	Failed    string
+	// Interval between the scans of the directory
+	Interval string `validate:"duration"`
+	// SettleTime a file needs to be unchanged before it is ingested
+	SettleTime string `validate:"duration"`
+}
+
+// Enabled is true if a directory is configured
+func (i InboxSettings) Enabled() bool {
+	return i.Path != ""
+}
+
+// Options creates the options of the inbox watcher
+func (i InboxSettings) Options() inbox.Options {
+	opts := inbox.Options{
+		Path:      i.Path,
+		Processed: i.Processed,
+		Failed:    i.Failed,
+	}
+	// the durations are validated with the configuration
+	opts.Interval, _ = time.ParseDuration(i.Interval)
+	opts.SettleTime, _ = time.ParseDuration(i.SettleTime)
+	return opts
+}
+
+// ClassificationSettings define how the rules access the content of new documents
+type ClassificationSettings struct {
+	// TextExtractor is the path of pdftotext used to read the text of a PDF.
+	// Without pdftotext only the plain text strings of simple PDFs are found.
+	TextExtractor string
+}
+
+// ReminderSettings define the mail server used to send the reminders of due documents.
+// The reminder mails are disabled without a host, the calendar feed is always available.
+type ReminderSettings struct {
+	Host     string
+	Port     int
+	Username string
+	Password string `secret:"true"`
+	From     string
+	// BaseURL is the public address of mydms used for the links in the mails, the calendar and the API
+	BaseURL string `validate:"url"`
+	// Interval between the checks of the due reminders
+	Interval string `validate:"duration"`
+}
+
+// Enabled is true if a mail server is configured
+func (r ReminderSettings) Enabled() bool {
+	return r.Host != ""
+}
+
+// Notifier creates the notifier sending the reminder mails, nil if no mail server is configured
+func (r ReminderSettings) Notifier() reminder.Notifier {
+	if !r.Enabled() {
+		return nil
+	}
+	return reminder.NewSMTPNotifier(reminder.SMTPOptions{
+		Host:     r.Host,
+		Port:     r.Port,
+		Username: r.Username,
+		Password: r.Password,
+		From:     r.From,
+		BaseURL:  r.BaseURL,
+	})
+}
+
+// CheckInterval is the parsed interval, the duration is validated with the configuration
+func (r ReminderSettings) CheckInterval() time.Duration {
+	d, _ := time.ParseDuration(r.Interval)
+	return d
 }
 
 // UploadSettings defines relevant values for the upload logic
 type UploadSettings struct {
 	// AllowedFileTypes is a list of mime-types allowed to be uploaded
-	AllowedFileTypes []string
+	AllowedFileTypes []string `validate:"required"`
 	// MaxUploadSize defines the maximum permissible file-size
-	MaxUploadSize int64
+	MaxUploadSize int64 `validate:"min=1"`
 	// UploadPath defines a directory where uploaded files are stored
-	UploadPath string
+	UploadPath string `validate:"required"`
 }
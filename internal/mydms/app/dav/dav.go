@@ -0,0 +1,445 @@
+// Package dav presents the documents as a WebDAV file system. The documents are listed in the
+// virtual folders year, tag and sender, the drafts of the review queue in the folder inbox.
+// New files are stored as drafts, a new file in the folder of a tag or sender is assigned to it.
+// Deleting a file deletes the document, regardless of the folder it is listed in.
+package dav
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.binggl.net/monorepo/internal/common/upload"
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/internal/mydms/app/filestore"
+	"golang.binggl.net/monorepo/pkg/logging"
+	"golang.binggl.net/monorepo/pkg/security"
+	"golang.org/x/net/webdav"
+)
+
+// the folders of the root directory
+const (
+	InboxFolder  = "inbox"
+	SenderFolder = "sender"
+	TagFolder    = "tag"
+	YearFolder   = "year"
+)
+
+var rootFolders = []string{InboxFolder, SenderFolder, TagFolder, YearFolder}
+
+// Options define the services used by the file system
+type Options struct {
+	Repo      document.Repository
+	DocSvc    document.Service
+	FileSvc   filestore.FileService
+	UploadSvc upload.Service
+	Logger    logging.Logger
+	// MaxUploadSize limits the size of new files, the upload service validates the size again
+	MaxUploadSize int64
+}
+
+// FileSystem implements webdav.FileSystem, the files are resolved by the listing of their folder
+type FileSystem struct {
+	opts Options
+}
+
+// compiler interface check
+var _ webdav.FileSystem = (*FileSystem)(nil)
+
+// NewFileSystem creates the file system of the documents
+func NewFileSystem(opts Options) *FileSystem {
+	return &FileSystem{opts: opts}
+}
+
+// NewHandler serves the file system below the given prefix. The handler keeps the listings of the
+// folders for the duration of a request, a PROPFIND opens every file of a folder.
+func NewHandler(prefix string, fs *FileSystem) http.Handler {
+	h := &webdav.Handler{
+		Prefix:     prefix,
+		FileSystem: fs,
+		LockSystem: webdav.NewMemLS(),
+		Logger: func(r *http.Request, err error) {
+			if err != nil && !os.IsNotExist(err) {
+				fs.opts.Logger.Warn(fmt.Sprintf("WebDAV: %s '%s' failed", r.Method, r.URL.Path), logging.ErrV(err))
+			}
+		},
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), listingKey, &listing{folders: map[string][]folder{}, files: map[string][]*fileInfo{}})
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+type ctxKey int
+
+const listingKey ctxKey = 0
+
+// listing caches the folders and files of a request
+type listing struct {
+	folders map[string][]folder
+	files   map[string][]*fileInfo
+}
+
+// folder is a sub-folder of year, tag or sender. The name of the folder cannot contain a slash,
+// the value is used for the search.
+type folder struct {
+	name  string
+	value string
+}
+
+// location is a parsed path of the file system, an empty name denotes the folder itself
+type location struct {
+	root   string
+	folder string
+	name   string
+}
+
+// parse splits the path, the depth of the path depends on the root folder
+func parse(name string) (loc location, ok bool) {
+	name = strings.Trim(path.Clean("/"+name), "/")
+	if name == "" {
+		return loc, true
+	}
+	parts := strings.Split(name, "/")
+	loc.root = parts[0]
+	if !slices.Contains(rootFolders, loc.root) {
+		return loc, false
+	}
+	switch {
+	case loc.root == InboxFolder && len(parts) <= 2:
+		if len(parts) == 2 {
+			loc.name = parts[1]
+		}
+		return loc, true
+	case loc.root != InboxFolder && len(parts) <= 3:
+		if len(parts) > 1 {
+			loc.folder = parts[1]
+		}
+		if len(parts) > 2 {
+			loc.name = parts[2]
+		}
+		return loc, true
+	}
+	return loc, false
+}
+
+// isFolder is true for the root, the root folders and the sub-folders of year, tag and sender
+func (l location) isFolder() bool {
+	return l.name == ""
+}
+
+// filesFolder is true for the folders listing documents
+func (l location) filesFolder() bool {
+	return l.root == InboxFolder || l.folder != ""
+}
+
+// Mkdir is not supported, the folders are derived from the documents
+func (f *FileSystem) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	return os.ErrPermission
+}
+
+// Rename is not supported, the file names are derived from the documents
+func (f *FileSystem) Rename(ctx context.Context, oldName, newName string) error {
+	return os.ErrPermission
+}
+
+// RemoveAll deletes the document of the file, the folders cannot be removed
+func (f *FileSystem) RemoveAll(ctx context.Context, name string) error {
+	fi, err := f.stat(ctx, name)
+	if err != nil {
+		return err
+	}
+	if fi.IsDir() {
+		return os.ErrPermission
+	}
+	user := userName(ctx)
+	f.opts.Logger.Info(fmt.Sprintf("WebDAV: delete the document '%s' of the file '%s' for user: '%s'", fi.doc.ID, name, user))
+	return f.opts.DocSvc.DeleteDocumentByID(ctx, fi.doc.ID)
+}
+
+// Stat returns the info of the file or folder
+func (f *FileSystem) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	return f.stat(ctx, name)
+}
+
+// OpenFile opens a folder, a file of a document or creates a new file. Existing documents
+// cannot be written, the changes of documents are done by mydms.
+func (f *FileSystem) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	write := flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) != 0
+	fi, err := f.stat(ctx, name)
+	if err == nil {
+		if write {
+			return nil, os.ErrPermission
+		}
+		if fi.IsDir() {
+			return f.openFolder(ctx, name, fi)
+		}
+		return &docFile{info: fi, load: func() ([]byte, error) {
+			file, err := f.opts.FileSvc.GetFile(ctx, fi.doc.FileName)
+			return file.Payload, err
+		}}, nil
+	}
+	if !os.IsNotExist(err) || flag&os.O_CREATE == 0 {
+		return nil, err
+	}
+
+	loc, ok := parse(name)
+	if !ok || loc.name == "" || strings.HasPrefix(loc.name, ".") {
+		return nil, os.ErrPermission
+	}
+	if loc.root == YearFolder {
+		return nil, os.ErrPermission
+	}
+	if _, err := f.stat(ctx, path.Dir(path.Clean("/"+name))); err != nil {
+		return nil, err
+	}
+	return &newFile{fs: f, ctx: ctx, loc: loc, value: f.folderValue(ctx, loc)}, nil
+}
+
+// stat resolves the path, the files are looked up in the listing of their folder
+func (f *FileSystem) stat(ctx context.Context, name string) (*fileInfo, error) {
+	loc, ok := parse(name)
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	if loc.isFolder() {
+		if loc.folder != "" && !slices.ContainsFunc(f.folders(ctx, loc.root), func(d folder) bool { return d.name == loc.folder }) {
+			return nil, os.ErrNotExist
+		}
+		dirName := loc.folder
+		if dirName == "" {
+			dirName = loc.root
+		}
+		return &fileInfo{name: dirName, dir: true, modified: time.Now()}, nil
+	}
+	if !loc.filesFolder() {
+		return nil, os.ErrNotExist
+	}
+	if loc.root != InboxFolder {
+		if _, err := f.stat(ctx, path.Join(loc.root, loc.folder)); err != nil {
+			return nil, err
+		}
+	}
+	for _, fi := range f.files(ctx, loc) {
+		if fi.name == loc.name {
+			return fi, nil
+		}
+	}
+	return nil, os.ErrNotExist
+}
+
+func (f *FileSystem) openFolder(ctx context.Context, name string, fi *fileInfo) (webdav.File, error) {
+	loc, _ := parse(name)
+	var children []os.FileInfo
+	switch {
+	case loc.root == "":
+		for _, r := range rootFolders {
+			children = append(children, &fileInfo{name: r, dir: true, modified: fi.modified})
+		}
+	case loc.filesFolder():
+		for _, c := range f.files(ctx, loc) {
+			children = append(children, c)
+		}
+	default:
+		for _, d := range f.folders(ctx, loc.root) {
+			children = append(children, &fileInfo{name: d.name, dir: true, modified: fi.modified})
+		}
+	}
+	return &folderFile{info: fi, children: children}, nil
+}
+
+// folderValue returns the tag or sender of the folder
+func (f *FileSystem) folderValue(ctx context.Context, loc location) string {
+	for _, d := range f.folders(ctx, loc.root) {
+		if d.name == loc.folder {
+			return d.value
+		}
+	}
+	return ""
+}
+
+// folders returns the sub-folders of year, tag and sender. The years range from the oldest to the
+// newest document, the tags and senders are the ones used by documents.
+func (f *FileSystem) folders(ctx context.Context, root string) []folder {
+	l := cache(ctx)
+	if d, ok := l.folders[root]; ok {
+		return d
+	}
+	var result []folder
+	switch root {
+	case YearFolder:
+		oldest, newest := f.created(ctx, document.ASC), f.created(ctx, document.DESC)
+		if !oldest.IsZero() {
+			for y := newest.Year(); y >= oldest.Year(); y-- {
+				result = append(result, folder{name: strconv.Itoa(y), value: strconv.Itoa(y)})
+			}
+		}
+	case TagFolder, SenderFolder:
+		st := document.TAGS
+		if root == SenderFolder {
+			st = document.SENDERS
+		}
+		items, err := f.opts.Repo.ListItems(ctx, st)
+		if err != nil {
+			f.opts.Logger.Error(fmt.Sprintf("WebDAV: could not list the %s", st), logging.ErrV(err))
+		}
+		// the names cannot contain a slash, names which are the same afterwards get the start of their id appended
+		folderName := func(item document.ListEntity) string { return strings.ReplaceAll(item.Name, "/", "_") }
+		names := map[string]int{}
+		for _, item := range items {
+			if item.Documents > 0 {
+				names[folderName(item)]++
+			}
+		}
+		for _, item := range items {
+			if item.Documents == 0 {
+				continue
+			}
+			name := folderName(item)
+			if names[name] > 1 {
+				name = fmt.Sprintf("%s (%s)", name, shortID(item.ID))
+			}
+			result = append(result, folder{name: name, value: item.Name})
+		}
+	}
+	l.folders[root] = result
+	return result
+}
+
+// created returns the creation of the oldest or newest document
+func (f *FileSystem) created(ctx context.Context, order document.SortDirection) time.Time {
+	docs, err := f.opts.Repo.Search(ctx, document.DocSearch{Limit: 1}, []document.OrderBy{{Field: "created", Order: order}})
+	if err != nil {
+		f.opts.Logger.Error("WebDAV: could not search the documents", logging.ErrV(err))
+		return time.Time{}
+	}
+	if len(docs.Documents) == 0 {
+		return time.Time{}
+	}
+	return docs.Documents[0].Created
+}
+
+// files returns the documents of the folder, the names are the names of the stored files.
+// Documents of the same name get the start of their id appended.
+func (f *FileSystem) files(ctx context.Context, loc location) []*fileInfo {
+	key := path.Join(loc.root, loc.folder)
+	l := cache(ctx)
+	if fi, ok := l.files[key]; ok {
+		return fi
+	}
+
+	var (
+		search document.DocSearch
+		match  = func(d document.DocEntity) bool { return true }
+	)
+	value := f.folderValue(ctx, loc)
+	switch loc.root {
+	case InboxFolder:
+		search.NeedsReview = true
+	case YearFolder:
+		year, _ := strconv.Atoi(value)
+		search.From = time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+		search.Until = search.From.AddDate(1, 0, 0).Add(-time.Nanosecond)
+	case TagFolder:
+		// the search matches parts of the names
+		search.Tag = value
+		match = func(d document.DocEntity) bool { return slices.Contains(strings.Split(d.TagList, ";"), value) }
+	case SenderFolder:
+		search.Sender = value
+		match = func(d document.DocEntity) bool { return slices.Contains(strings.Split(d.SenderList, ";"), value) }
+	}
+	docs, err := f.opts.Repo.Search(ctx, search, []document.OrderBy{{Field: "created", Order: document.DESC}, {Field: "title", Order: document.ASC}})
+	if err != nil {
+		f.opts.Logger.Error(fmt.Sprintf("WebDAV: could not list the documents of '%s'", key), logging.ErrV(err))
+	}
+
+	var result []*fileInfo
+	names := map[string]int{}
+	for _, d := range docs.Documents {
+		if !match(d) {
+			continue
+		}
+		fi := &fileInfo{name: path.Base(d.FileName), doc: d, modified: d.Created}
+		if d.Modified.Valid {
+			fi.modified = d.Modified.Time
+		}
+		names[fi.name]++
+		result = append(result, fi)
+	}
+	for _, fi := range result {
+		if names[fi.name] > 1 {
+			ext := path.Ext(fi.name)
+			fi.name = fmt.Sprintf("%s (%s)%s", strings.TrimSuffix(fi.name, ext), shortID(fi.doc.ID), ext)
+		}
+	}
+	l.files[key] = result
+	return result
+}
+
+// create saves the file as a draft, the tag or sender of the folder is assigned to the draft
+func (f *FileSystem) create(ctx context.Context, loc location, value string, payload []byte) error {
+	user, ok := security.UserFromContext(ctx)
+	if !ok {
+		return os.ErrPermission
+	}
+	token, err := f.opts.UploadSvc.Save(upload.File{
+		File:     bytes.NewReader(payload),
+		Name:     loc.name,
+		Size:     int64(len(payload)),
+		MimeType: mime.TypeByExtension(strings.ToLower(path.Ext(loc.name))),
+	})
+	if err != nil {
+		return err
+	}
+	draft := document.Document{
+		Title:       strings.TrimSuffix(loc.name, path.Ext(loc.name)),
+		FileName:    loc.name,
+		UploadToken: token,
+		NeedsReview: true,
+	}
+	switch loc.root {
+	case TagFolder:
+		draft.Tags = []string{value}
+	case SenderFolder:
+		draft.Senders = []string{value}
+	}
+	doc, err := f.opts.DocSvc.SaveDocument(ctx, draft, *user)
+	if err != nil {
+		if delErr := f.opts.UploadSvc.Delete(token); delErr != nil {
+			f.opts.Logger.Warn(fmt.Sprintf("WebDAV: could not delete the upload of '%s'", loc.name), logging.ErrV(delErr))
+		}
+		return err
+	}
+	f.opts.Logger.Info(fmt.Sprintf("WebDAV: created the draft '%s' for the file '%s' for user: '%s'", doc.ID, loc.name, user.Username))
+	return nil
+}
+
+func cache(ctx context.Context) *listing {
+	if l, ok := ctx.Value(listingKey).(*listing); ok {
+		return l
+	}
+	// without the handler the listings are not kept
+	return &listing{folders: map[string][]folder{}, files: map[string][]*fileInfo{}}
+}
+
+func userName(ctx context.Context) string {
+	if user, ok := security.UserFromContext(ctx); ok {
+		return user.Username
+	}
+	return ""
+}
+
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
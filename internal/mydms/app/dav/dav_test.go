@@ -0,0 +1,168 @@
+package dav_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.binggl.net/monorepo/internal/common/upload"
+	"golang.binggl.net/monorepo/internal/mydms/app/dav"
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/internal/mydms/app/filestore"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared/sqlitetest"
+	"golang.binggl.net/monorepo/pkg/logging"
+	"golang.binggl.net/monorepo/pkg/security"
+)
+
+var logger = logging.NewNop()
+
+// mockFileService keeps the files in memory
+type mockFileService struct {
+	filestore.FileService
+	files map[string][]byte
+}
+
+func (m *mockFileService) SaveFile(ctx context.Context, file filestore.FileItem) error {
+	m.files[fmt.Sprintf("/%s/%s", file.FolderName, file.FileName)] = file.Payload
+	return nil
+}
+
+func (m *mockFileService) GetFile(ctx context.Context, filePath string) (filestore.FileItem, error) {
+	payload, ok := m.files[filePath]
+	if !ok {
+		return filestore.FileItem{}, fmt.Errorf("file '%s' not found", filePath)
+	}
+	return filestore.FileItem{FileName: filepath.Base(filePath), MimeType: "application/pdf", Payload: payload}, nil
+}
+
+func (m *mockFileService) DeleteFile(ctx context.Context, filePath string) error {
+	delete(m.files, filePath)
+	return nil
+}
+
+func Test_FileSystem(t *testing.T) {
+	repo, err := document.NewRepository(sqlitetest.NewConn(t))
+	if err != nil {
+		t.Fatalf("cannot establish database connection: %v", err)
+	}
+	files := &mockFileService{files: map[string][]byte{}}
+	uploadSvc := upload.NewService(upload.ServiceOptions{
+		Logger:           logger,
+		Store:            upload.NewStore(t.TempDir()),
+		MaxUploadSize:    1000,
+		AllowedFileTypes: []string{"pdf"},
+	})
+	docSvc := document.NewService(logger, repo, files, uploadSvc, nil, nil)
+	user := security.User{Username: "user"}
+
+	var ids []string
+	for _, d := range []document.Document{
+		{Title: "invoice", FileName: "/2024_01_01/invoice.pdf", Tags: []string{"bill"}, Senders: []string{"shop"}},
+		{Title: "invoice", FileName: "/2024_02_01/invoice.pdf", Tags: []string{"bills"}, Senders: []string{"shop"}},
+		{Title: "contract", FileName: "/2024_03_01/contract.pdf", Tags: []string{"a/b"}, Senders: []string{"office"}},
+		{Title: "memo", FileName: "/2024_04_01/memo.pdf", Tags: []string{"a_b"}, Senders: []string{"office"}},
+	} {
+		saved, err := docSvc.SaveDocument(context.TODO(), d, user)
+		if err != nil {
+			t.Fatalf("could not save the document: %v", err)
+		}
+		ids = append(ids, saved.ID)
+	}
+	files.files["/2024_01_01/invoice.pdf"] = []byte("%PDF-1.0 invoice")
+
+	fs := dav.NewFileSystem(dav.Options{Repo: repo, DocSvc: docSvc, FileSvc: files, UploadSvc: uploadSvc, Logger: logger, MaxUploadSize: 1000})
+	h := dav.NewHandler("/dav", fs)
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if method == "PROPFIND" {
+			req.Header.Set("Depth", "1")
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req.WithContext(security.NewContext(req.Context(), &user)))
+		return rec
+	}
+	year := fmt.Sprintf("/dav/year/%d/", time.Now().Year())
+
+	rec := send("PROPFIND", "/dav/", "")
+	assert.Equal(t, http.StatusMultiStatus, rec.Code)
+	for _, folder := range []string{"/dav/inbox/", "/dav/tag/", "/dav/sender/", "/dav/year/"} {
+		assert.Contains(t, rec.Body.String(), "<D:href>"+folder+"</D:href>")
+	}
+	body := send("PROPFIND", "/dav/tag/", "").Body.String()
+	assert.Contains(t, body, "<D:href>/dav/tag/bill/</D:href>")
+	// the folder names of the tags 'a/b' and 'a_b' are told apart by their id
+	tags, err := repo.ListItems(context.TODO(), document.TAGS)
+	if err != nil {
+		t.Fatalf("could not list the tags: %v", err)
+	}
+	tagFolder := map[string]string{}
+	for _, tag := range tags {
+		tagFolder[tag.Name] = fmt.Sprintf("/dav/tag/a_b%%20%%28%s%%29/", tag.ID[:8])
+	}
+	assert.Contains(t, body, "<D:href>"+tagFolder["a/b"]+"</D:href>")
+	assert.Contains(t, body, "<D:href>"+tagFolder["a_b"]+"</D:href>")
+	assert.NotContains(t, body, "<D:href>/dav/tag/a_b/</D:href>")
+	assert.Contains(t, send("PROPFIND", year, "").Body.String(), "contract.pdf")
+
+	// the tag folders only list the documents having the tag
+	body = send("PROPFIND", "/dav/tag/bill/", "").Body.String()
+	assert.Contains(t, body, "<D:href>/dav/tag/bill/invoice.pdf</D:href>")
+	assert.Contains(t, body, "<D:getcontenttype>application/pdf</D:getcontenttype>")
+	assert.Equal(t, 1, strings.Count(body, "<D:href>/dav/tag/bill/invoice"))
+	body = send("PROPFIND", tagFolder["a/b"], "").Body.String()
+	assert.Contains(t, body, "contract.pdf")
+	assert.NotContains(t, body, "memo.pdf")
+	assert.Equal(t, http.StatusNotFound, send("PROPFIND", "/dav/tag/unknown/", "").Code)
+
+	// files of the same name are told apart by their id
+	body = send("PROPFIND", "/dav/sender/shop/", "").Body.String()
+	assert.Contains(t, body, fmt.Sprintf("invoice%%20%%28%s%%29.pdf", ids[0][:8]))
+	assert.Contains(t, body, fmt.Sprintf("invoice%%20%%28%s%%29.pdf", ids[1][:8]))
+
+	rec = send("GET", "/dav/tag/bill/invoice.pdf", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "%PDF-1.0 invoice", rec.Body.String())
+
+	// new files are drafts, the tag of the folder is assigned
+	assert.Equal(t, http.StatusCreated, send("PUT", "/dav/inbox/scan.pdf", "%PDF-1.0 scan").Code)
+	assert.Equal(t, http.StatusCreated, send("PUT", "/dav/tag/bill/letter.pdf", "%PDF-1.0 letter").Code)
+	drafts, err := docSvc.ReviewQueue(context.TODO(), 10, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, drafts.TotalEntries)
+	assert.Equal(t, "scan", drafts.Documents[0].Title)
+	assert.Equal(t, []string{"bill"}, drafts.Documents[1].Tags)
+	body = send("PROPFIND", "/dav/inbox/", "").Body.String()
+	assert.Contains(t, body, "<D:href>/dav/inbox/scan.pdf</D:href>")
+	assert.Contains(t, body, "<D:href>/dav/inbox/letter.pdf</D:href>")
+	rec = send("GET", "/dav/inbox/scan.pdf", "")
+	assert.Equal(t, "%PDF-1.0 scan", rec.Body.String())
+
+	// empty files are not stored, existing files are not changed
+	assert.Equal(t, http.StatusCreated, send("PUT", "/dav/inbox/empty.pdf", "").Code)
+	assert.Equal(t, http.StatusNotFound, send("GET", "/dav/inbox/empty.pdf", "").Code)
+	for _, p := range []string{"/dav/tag/bill/invoice.pdf", year + "new.pdf", "/dav/tag/unknown/new.pdf", "/dav/new.pdf"} {
+		assert.NotEqual(t, http.StatusCreated, send("PUT", p, "%PDF-1.0").Code, p)
+	}
+	assert.NotEqual(t, http.StatusCreated, send("PUT", "/dav/inbox/notes.txt", "text").Code)
+	assert.NotEqual(t, http.StatusCreated, send("PUT", "/dav/inbox/large.pdf", strings.Repeat("x", 2000)).Code)
+	drafts, _ = docSvc.ReviewQueue(context.TODO(), 10, 0)
+	assert.Equal(t, 2, drafts.TotalEntries)
+
+	// the folders are derived from the documents
+	assert.Equal(t, http.StatusMethodNotAllowed, send("MKCOL", "/dav/tag/new/", "").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, send("DELETE", "/dav/tag/bill/", "").Code)
+
+	// deleting a file deletes the document
+	assert.Equal(t, http.StatusNoContent, send("DELETE", "/dav/tag/bill/invoice.pdf", "").Code)
+	_, err = docSvc.GetDocumentByID(context.TODO(), ids[0])
+	assert.Error(t, err)
+	assert.NotContains(t, files.files, "/2024_01_01/invoice.pdf")
+	assert.Equal(t, http.StatusNotFound, send("DELETE", "/dav/tag/bill/invoice.pdf", "").Code)
+	assert.Equal(t, "%PDF-1.0 letter", send("GET", "/dav/tag/bill/letter.pdf", "").Body.String())
+}
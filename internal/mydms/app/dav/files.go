@@ -0,0 +1,161 @@
+package dav
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"time"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.org/x/net/webdav"
+)
+
+// fileInfo describes a folder or the file of a document. The size of the stored file is not
+// known without reading the file, it is reported once the file is read.
+type fileInfo struct {
+	name     string
+	dir      bool
+	size     int64
+	modified time.Time
+	doc      document.DocEntity
+}
+
+// compiler interface checks
+var (
+	_ webdav.ContentTyper = (*fileInfo)(nil)
+	_ webdav.ETager       = (*fileInfo)(nil)
+)
+
+func (fi *fileInfo) Name() string       { return fi.name }
+func (fi *fileInfo) Size() int64        { return fi.size }
+func (fi *fileInfo) ModTime() time.Time { return fi.modified }
+func (fi *fileInfo) IsDir() bool        { return fi.dir }
+func (fi *fileInfo) Sys() any           { return nil }
+
+func (fi *fileInfo) Mode() fs.FileMode {
+	if fi.dir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+
+// ContentType uses the extension, the webdav package would read the file otherwise
+func (fi *fileInfo) ContentType(ctx context.Context) (string, error) {
+	if ctype := mime.TypeByExtension(path.Ext(fi.name)); ctype != "" {
+		return ctype, nil
+	}
+	return "application/octet-stream", nil
+}
+
+// ETag changes with the document, the webdav package would use the size otherwise
+func (fi *fileInfo) ETag(ctx context.Context) (string, error) {
+	if fi.dir {
+		return "", webdav.ErrNotImplemented
+	}
+	return fmt.Sprintf(`"%s-%x"`, fi.doc.ID, fi.modified.UnixNano()), nil
+}
+
+// docFile reads the file of a document, the file is loaded from the store on first access
+type docFile struct {
+	info   *fileInfo
+	load   func() ([]byte, error)
+	reader *bytes.Reader
+}
+
+func (f *docFile) payload() (*bytes.Reader, error) {
+	if f.reader == nil {
+		p, err := f.load()
+		if err != nil {
+			return nil, err
+		}
+		f.reader = bytes.NewReader(p)
+		f.info.size = int64(len(p))
+	}
+	return f.reader, nil
+}
+
+func (f *docFile) Read(p []byte) (int, error) {
+	r, err := f.payload()
+	if err != nil {
+		return 0, err
+	}
+	return r.Read(p)
+}
+
+func (f *docFile) Seek(offset int64, whence int) (int64, error) {
+	r, err := f.payload()
+	if err != nil {
+		return 0, err
+	}
+	return r.Seek(offset, whence)
+}
+
+func (f *docFile) Readdir(count int) ([]fs.FileInfo, error) { return nil, os.ErrInvalid }
+func (f *docFile) Stat() (fs.FileInfo, error)               { return f.info, nil }
+func (f *docFile) Write(p []byte) (int, error)              { return 0, os.ErrPermission }
+func (f *docFile) Close() error                             { return nil }
+
+// folderFile lists the children of a folder
+type folderFile struct {
+	info     *fileInfo
+	children []fs.FileInfo
+	pos      int
+}
+
+// Readdir follows the semantics of os.File, a count > 0 returns io.EOF at the end of the folder
+func (f *folderFile) Readdir(count int) ([]fs.FileInfo, error) {
+	rest := f.children[f.pos:]
+	if count <= 0 {
+		f.pos = len(f.children)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	rest = rest[:min(count, len(rest))]
+	f.pos += len(rest)
+	return rest, nil
+}
+
+func (f *folderFile) Read(p []byte) (int, error)                   { return 0, os.ErrInvalid }
+func (f *folderFile) Seek(offset int64, whence int) (int64, error) { return 0, os.ErrInvalid }
+func (f *folderFile) Stat() (fs.FileInfo, error)                   { return f.info, nil }
+func (f *folderFile) Write(p []byte) (int, error)                  { return 0, os.ErrPermission }
+func (f *folderFile) Close() error                                 { return nil }
+
+// newFile collects the written payload, the draft is created when the file is closed.
+// Clients create empty files before writing the payload, empty files are not stored.
+type newFile struct {
+	fs    *FileSystem
+	ctx   context.Context
+	loc   location
+	value string
+	buf   bytes.Buffer
+}
+
+func (f *newFile) Write(p []byte) (int, error) {
+	if limit := f.fs.opts.MaxUploadSize; limit > 0 && int64(f.buf.Len()+len(p)) > limit {
+		return 0, fmt.Errorf("the file exceeds the maximum size of %d bytes", limit)
+	}
+	return f.buf.Write(p)
+}
+
+func (f *newFile) Close() error {
+	if f.buf.Len() == 0 {
+		return nil
+	}
+	return f.fs.create(f.ctx, f.loc, f.value, f.buf.Bytes())
+}
+
+func (f *newFile) Stat() (fs.FileInfo, error) {
+	return &fileInfo{name: f.loc.name, size: int64(f.buf.Len()), modified: time.Now()}, nil
+}
+
+func (f *newFile) Read(p []byte) (int, error)                   { return 0, os.ErrInvalid }
+func (f *newFile) Seek(offset int64, whence int) (int64, error) { return 0, os.ErrInvalid }
+func (f *newFile) Readdir(count int) ([]fs.FileInfo, error)     { return nil, os.ErrInvalid }
@@ -0,0 +1,129 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+	"golang.binggl.net/monorepo/pkg/logging"
+)
+
+// BulkAction is the change applied to multiple documents
+type BulkAction string
+
+const (
+	// BulkAddTags adds the values to the tags of the documents
+	BulkAddTags BulkAction = "add_tags"
+	// BulkRemoveTags removes the values from the tags of the documents
+	BulkRemoveTags BulkAction = "remove_tags"
+	// BulkSetSender replaces the senders of the documents by the first value
+	BulkSetSender BulkAction = "set_sender"
+	// BulkDelete removes the documents and their files
+	BulkDelete BulkAction = "delete"
+	// BulkMerge combines the files of the documents into a new document titled by the first value.
+	// The merge is not applied per document, it is done by MergeDocuments.
+	BulkMerge BulkAction = "merge"
+)
+
+// BulkChange defines the action and the tags or the sender used by the action
+type BulkChange struct {
+	Action BulkAction
+	Values []string
+}
+
+// BulkResult lists the changed documents and the documents which could not be changed
+type BulkResult struct {
+	Changed []string
+	Failed  []BulkFailure
+}
+
+// BulkFailure is the reason why a document could not be changed
+type BulkFailure struct {
+	ID      string
+	Message string
+}
+
+// BulkUpdate applies the change to the given documents. Every document is changed within its own
+// transaction, a failed document does not prevent the change of the other documents.
+func (s documentService) BulkUpdate(ctx context.Context, ids []string, change BulkChange) (r BulkResult, err error) {
+	if len(ids) == 0 {
+		return r, shared.ErrValidation("no documents are selected")
+	}
+	var values []string
+	for _, v := range change.Values {
+		v = strings.TrimSpace(s.policy.Sanitize(v))
+		if strings.Contains(v, ";") {
+			return r, shared.ErrValidation(fmt.Sprintf("the value '%s' is not valid", v))
+		}
+		if v != "" {
+			values = append(values, v)
+		}
+	}
+
+	var apply func(ctx context.Context, id string) error
+	switch change.Action {
+	case BulkDelete:
+		apply = s.DeleteDocumentByID
+	case BulkAddTags, BulkRemoveTags, BulkSetSender:
+		if len(values) == 0 {
+			return r, shared.ErrValidation(fmt.Sprintf("the action '%s' needs a value", change.Action))
+		}
+		apply = func(ctx context.Context, id string) error {
+			return s.changeEntry(ctx, id, change.Action, values)
+		}
+	default:
+		return r, shared.ErrValidation(fmt.Sprintf("the action '%s' is not supported", change.Action))
+	}
+
+	for _, id := range slices.Compact(slices.Sorted(slices.Values(ids))) {
+		if err := apply(ctx, id); err != nil {
+			s.logger.Warn(fmt.Sprintf("BulkUpdate: could not apply '%s' to the document '%s'", change.Action, id), logging.ErrV(err))
+			r.Failed = append(r.Failed, BulkFailure{ID: id, Message: err.Error()})
+			continue
+		}
+		r.Changed = append(r.Changed, id)
+	}
+	return r, nil
+}
+
+// changeEntry updates the tags or the senders of the document within a transaction
+func (s documentService) changeEntry(ctx context.Context, id string, action BulkAction, values []string) (err error) {
+	atomic, err := s.repo.CreateAtomic()
+	if err != nil {
+		return
+	}
+	defer func() {
+		err = shared.HandleTX(true, &atomic, err)
+	}()
+
+	doc, err := s.repo.Get(ctx, id, atomic)
+	if err != nil {
+		return shared.ErrNotFound(fmt.Sprintf("could not find document by id: %s", id))
+	}
+	switch action {
+	case BulkAddTags:
+		doc.TagList = mergeList(doc.TagList, strings.Join(values, ";"))
+	case BulkRemoveTags:
+		doc.TagList = removeList(doc.TagList, values)
+		if doc.TagList == "" {
+			return shared.ErrValidation(fmt.Sprintf("the document '%s' needs at least one tag", doc.Title))
+		}
+	case BulkSetSender:
+		doc.SenderList = values[0]
+	}
+
+	if _, err = s.repo.Save(ctx, doc, atomic); err != nil {
+		return fmt.Errorf("error while saving document: %v", err)
+	}
+	return nil
+}
+
+// removeList removes the values from the ';' separated list, the case of the entries is ignored
+func removeList(list string, values []string) string {
+	entries := slices.DeleteFunc(strings.Split(list, ";"), func(e string) bool {
+		return e == "" || slices.ContainsFunc(values, func(v string) bool { return strings.EqualFold(v, e) })
+	})
+	return strings.Join(entries, ";")
+}
@@ -0,0 +1,67 @@
+package document_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+)
+
+func Test_BulkUpdate(t *testing.T) {
+	repo := sqliteRepo(t)
+	fileSvc := newFileService()
+	svc := document.NewService(logger, repo, fileSvc, nil, nil, nil)
+
+	a := saveEntity(t, repo, document.DocEntity{Title: "a", FileName: "/2024_01_01/a.pdf", TagList: "invoice", SenderList: "Telekom"})
+	b := saveEntity(t, repo, document.DocEntity{Title: "b", FileName: "/2024_01_01/b.pdf", TagList: "tax;Invoice;private", SenderList: "Power Corp"})
+	ids := []string{a.ID, b.ID, b.ID}
+
+	var validation *shared.ValidationError
+	_, err := svc.BulkUpdate(context.TODO(), nil, document.BulkChange{Action: document.BulkDelete})
+	assert.ErrorAs(t, err, &validation)
+	_, err = svc.BulkUpdate(context.TODO(), ids, document.BulkChange{Action: document.BulkAddTags, Values: []string{" "}})
+	assert.ErrorAs(t, err, &validation)
+	_, err = svc.BulkUpdate(context.TODO(), ids, document.BulkChange{Action: document.BulkSetSender, Values: []string{"a;b"}})
+	assert.ErrorAs(t, err, &validation)
+	_, err = svc.BulkUpdate(context.TODO(), ids, document.BulkChange{Action: "archive"})
+	assert.ErrorAs(t, err, &validation)
+
+	result, err := svc.BulkUpdate(context.TODO(), append(ids, "unknown"), document.BulkChange{Action: document.BulkAddTags, Values: []string{"TAX", "2024"}})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{a.ID, b.ID}, result.Changed)
+	assert.Len(t, result.Failed, 1)
+	assert.Equal(t, "unknown", result.Failed[0].ID)
+	// the names are shared, the spelling of the existing tag is used
+	doc, _ := svc.GetDocumentByID(context.TODO(), a.ID)
+	assert.Equal(t, []string{"invoice", "tax", "2024"}, doc.Tags)
+	doc, _ = svc.GetDocumentByID(context.TODO(), b.ID)
+	assert.Equal(t, []string{"tax", "invoice", "private", "2024"}, doc.Tags)
+
+	// a document keeps at least one tag
+	result, err = svc.BulkUpdate(context.TODO(), ids, document.BulkChange{Action: document.BulkRemoveTags, Values: []string{"invoice", "tax", "2024"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{b.ID}, result.Changed)
+	assert.Len(t, result.Failed, 1)
+	assert.Equal(t, a.ID, result.Failed[0].ID)
+	doc, _ = svc.GetDocumentByID(context.TODO(), a.ID)
+	assert.Equal(t, []string{"invoice", "tax", "2024"}, doc.Tags)
+	doc, _ = svc.GetDocumentByID(context.TODO(), b.ID)
+	assert.Equal(t, []string{"private"}, doc.Tags)
+
+	result, err = svc.BulkUpdate(context.TODO(), ids, document.BulkChange{Action: document.BulkSetSender, Values: []string{"Office"}})
+	assert.NoError(t, err)
+	assert.Len(t, result.Changed, 2)
+	doc, _ = svc.GetDocumentByID(context.TODO(), b.ID)
+	assert.Equal(t, []string{"Office"}, doc.Senders)
+
+	result, err = svc.BulkUpdate(context.TODO(), ids, document.BulkChange{Action: document.BulkDelete})
+	assert.NoError(t, err)
+	assert.Len(t, result.Changed, 2)
+	assert.Len(t, result.Failed, 0)
+	assert.Contains(t, fileSvc.deleted, "/2024_01_01/a.pdf")
+	assert.Contains(t, fileSvc.deleted, "/2024_01_01/b.pdf")
+	_, err = svc.GetDocumentByID(context.TODO(), a.ID)
+	assert.Error(t, err)
+}
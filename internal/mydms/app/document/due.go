@@ -0,0 +1,158 @@
+package document
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+	"golang.binggl.net/monorepo/pkg/logging"
+)
+
+// DateLayout is the format of the due and reminder dates
+const DateLayout = "2006-01-02"
+
+// DueType describes what is due at the due date of a document
+type DueType string
+
+const (
+	// DuePayment is the payment of an invoice
+	DuePayment DueType = "payment"
+	// DueContract is the end or the cancellation period of a contract
+	DueContract DueType = "contract"
+	// DueFollowUp is any other follow-up of a document
+	DueFollowUp DueType = "followup"
+)
+
+// DueTypes are the available types in the order offered to the user
+var DueTypes = []DueType{DuePayment, DueContract, DueFollowUp}
+
+// Label is the name of the type shown to the user
+func (t DueType) Label() string {
+	switch t {
+	case DueContract:
+		return "Contract end"
+	case DueFollowUp:
+		return "Follow-up"
+	}
+	return "Payment"
+}
+
+// DoneLabel describes a completed document of the type
+func (t DueType) DoneLabel() string {
+	if t == DueContract || t == DueFollowUp {
+		return "done"
+	}
+	return "paid"
+}
+
+// UpcomingDocuments returns the documents having a due or reminder date ordered by the due date
+func (s documentService) UpcomingDocuments(ctx context.Context, includeDone bool) ([]Document, error) {
+	docs, err := s.repo.SearchDue(ctx, includeDone)
+	if err != nil {
+		s.logger.Error("UpcomingDocuments: repository error", logging.ErrV(err))
+		return nil, fmt.Errorf("cannot get the upcoming documents; %v", err)
+	}
+	return s.convertList(docs), nil
+}
+
+// MarkDone marks the document as paid or done, an open document does not need to be reminded
+func (s documentService) MarkDone(ctx context.Context, id string, done bool) (Document, error) {
+	doc, err := s.repo.Get(ctx, id, shared.Atomic{})
+	if err != nil {
+		return Document{}, shared.ErrNotFound(fmt.Sprintf("could not find document by id: %s", id))
+	}
+	if !doc.DueDate.Valid && !doc.ReminderDate.Valid {
+		return Document{}, shared.ErrValidation(fmt.Sprintf("the document '%s' has no due date", doc.Title))
+	}
+	doc.DoneAt = sql.NullTime{}
+	if done {
+		doc.DoneAt = sql.NullTime{Time: time.Now().UTC(), Valid: true}
+	}
+	if err = s.repo.UpdateDone(ctx, id, doc.DoneAt, shared.Atomic{}); err != nil {
+		s.logger.Error("MarkDone: repository error", logging.ErrV(err))
+		return Document{}, fmt.Errorf("could not update the document '%s'; %v", id, err)
+	}
+	return s.convertToDomain(doc), nil
+}
+
+// DueReminders returns the open documents whose reminder is due on the given day and was not sent
+func (s documentService) DueReminders(ctx context.Context, day time.Time) ([]Document, error) {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	docs, err := s.repo.SearchReminders(ctx, day)
+	if err != nil {
+		s.logger.Error("DueReminders: repository error", logging.ErrV(err))
+		return nil, fmt.Errorf("cannot get the due reminders; %v", err)
+	}
+	return s.convertList(docs), nil
+}
+
+// MarkReminded records the sent reminders of the documents within one transaction
+func (s documentService) MarkReminded(ctx context.Context, ids []string) (err error) {
+	atomic, err := s.repo.CreateAtomic()
+	if err != nil {
+		return
+	}
+	defer func() {
+		err = shared.HandleTX(true, &atomic, err)
+	}()
+
+	now := sql.NullTime{Time: time.Now().UTC(), Valid: true}
+	for _, id := range ids {
+		if err = s.repo.UpdateReminded(ctx, id, now, atomic); err != nil {
+			return fmt.Errorf("could not mark the reminder of '%s'; %v", id, err)
+		}
+	}
+	return nil
+}
+
+// dueEntity maps the due and reminder dates of the document to the entity. A changed reminder
+// is sent again, the type defaults to a payment.
+func dueEntity(d Document, e *DocEntity) error {
+	due, err := parseDate(d.DueDate)
+	if err != nil {
+		return shared.ErrValidation(fmt.Sprintf("the due date '%s' is not valid, use the format %s", d.DueDate, DateLayout))
+	}
+	reminder, err := parseDate(d.ReminderDate)
+	if err != nil {
+		return shared.ErrValidation(fmt.Sprintf("the reminder date '%s' is not valid, use the format %s", d.ReminderDate, DateLayout))
+	}
+	if d.DueType != "" && !slices.Contains(DueTypes, d.DueType) {
+		return shared.ErrValidation(fmt.Sprintf("the due type '%s' is not supported", d.DueType))
+	}
+
+	before := reminderDay(*e)
+	e.DueDate, e.ReminderDate = due, reminder
+	e.DueType = sql.NullString{}
+	if due.Valid || reminder.Valid {
+		e.DueType = sql.NullString{String: string(DuePayment), Valid: true}
+		if d.DueType != "" {
+			e.DueType.String = string(d.DueType)
+		}
+	}
+	if after := reminderDay(*e); after.Valid != before.Valid || !after.Time.Equal(before.Time) {
+		e.RemindedAt = sql.NullTime{}
+	}
+	return nil
+}
+
+// reminderDay is the day the reminder of the document is sent
+func reminderDay(e DocEntity) sql.NullTime {
+	if e.ReminderDate.Valid {
+		return e.ReminderDate
+	}
+	return e.DueDate
+}
+
+func parseDate(value string) (sql.NullTime, error) {
+	if value == "" {
+		return sql.NullTime{}, nil
+	}
+	t, err := time.Parse(DateLayout, value)
+	if err != nil {
+		return sql.NullTime{}, err
+	}
+	return sql.NullTime{Time: t, Valid: true}, nil
+}
@@ -0,0 +1,90 @@
+package document_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+	"golang.binggl.net/monorepo/pkg/security"
+)
+
+func Test_DueDates(t *testing.T) {
+	repo := sqliteRepo(t)
+	svc := document.NewService(logger, repo, newFileService(), uploadSvc, nil, nil)
+	user := security.User{Username: "test"}
+	day := time.Date(2024, 3, 10, 15, 30, 0, 0, time.UTC)
+
+	var validation *shared.ValidationError
+	_, err := svc.SaveDocument(context.TODO(), document.Document{Title: "invalid", FileName: "/a.pdf", Tags: []string{"t"}, DueDate: "10.03.2024"}, user)
+	assert.ErrorAs(t, err, &validation)
+	_, err = svc.SaveDocument(context.TODO(), document.Document{Title: "invalid", FileName: "/a.pdf", Tags: []string{"t"}, DueDate: "2024-03-10", DueType: "lottery"}, user)
+	assert.ErrorAs(t, err, &validation)
+
+	invoice, err := svc.SaveDocument(context.TODO(), document.Document{Title: "invoice", FileName: "/invoice.pdf", Tags: []string{"invoice"}, DueDate: "2024-03-15", ReminderDate: "2024-03-08"}, user)
+	assert.NoError(t, err)
+	assert.Equal(t, document.DuePayment, invoice.DueType)
+	assert.Equal(t, "2024-03-15", invoice.DueDate)
+	contract, err := svc.SaveDocument(context.TODO(), document.Document{Title: "contract", FileName: "/contract.pdf", Tags: []string{"contract"}, DueDate: "2024-03-10", DueType: document.DueContract}, user)
+	assert.NoError(t, err)
+	later, err := svc.SaveDocument(context.TODO(), document.Document{Title: "later", FileName: "/later.pdf", Tags: []string{"invoice"}, DueDate: "2024-04-01"}, user)
+	assert.NoError(t, err)
+	plain, err := svc.SaveDocument(context.TODO(), document.Document{Title: "plain", FileName: "/plain.pdf", Tags: []string{"misc"}}, user)
+	assert.NoError(t, err)
+	assert.Equal(t, document.DueType(""), plain.DueType)
+
+	// the reminder date is used for the order if no due date is available
+	docs, err := svc.UpcomingDocuments(context.TODO(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{contract.ID, invoice.ID, later.ID}, docIDs(docs))
+
+	reminders, err := svc.DueReminders(context.TODO(), day)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{contract.ID, invoice.ID}, docIDs(reminders))
+
+	// a sent reminder is not sent again, unless the date changes
+	assert.NoError(t, svc.MarkReminded(context.TODO(), docIDs(reminders)))
+	reminders, err = svc.DueReminders(context.TODO(), day)
+	assert.NoError(t, err)
+	assert.Len(t, reminders, 0)
+	invoice.ReminderDate = "2024-03-09"
+	_, err = svc.SaveDocument(context.TODO(), invoice, user)
+	assert.NoError(t, err)
+	reminders, err = svc.DueReminders(context.TODO(), day)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{invoice.ID}, docIDs(reminders))
+
+	// a completed document is neither listed nor reminded
+	done, err := svc.MarkDone(context.TODO(), invoice.ID, true)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, done.Done)
+	reminders, err = svc.DueReminders(context.TODO(), day)
+	assert.NoError(t, err)
+	assert.Len(t, reminders, 0)
+	docs, err = svc.UpcomingDocuments(context.TODO(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{contract.ID, later.ID}, docIDs(docs))
+	docs, err = svc.UpcomingDocuments(context.TODO(), true)
+	assert.NoError(t, err)
+	assert.Len(t, docs, 3)
+
+	done, err = svc.MarkDone(context.TODO(), invoice.ID, false)
+	assert.NoError(t, err)
+	assert.Empty(t, done.Done)
+
+	_, err = svc.MarkDone(context.TODO(), plain.ID, true)
+	assert.ErrorAs(t, err, &validation)
+	var notFound *shared.NotFoundError
+	_, err = svc.MarkDone(context.TODO(), "unknown", true)
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func docIDs(docs []document.Document) []string {
+	var ids []string
+	for _, d := range docs {
+		ids = append(ids, d.ID)
+	}
+	return ids
+}
@@ -0,0 +1,209 @@
+package document
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+	"golang.binggl.net/monorepo/pkg/logging"
+)
+
+// ContentHash returns the hex encoded SHA-256 hash of the payload, which identifies identical files
+func ContentHash(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// ContentHashReader returns the ContentHash of the payload read from r
+func ContentHashReader(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// DuplicateGroup holds the documents of identical files, the oldest document first
+type DuplicateGroup struct {
+	ContentHash string
+	Documents   []Document
+}
+
+// HashResult counts the documents processed by ComputeContentHashes
+type HashResult struct {
+	Computed int
+	Failed   int
+}
+
+// DocumentsByContentHash returns the documents storing a file with the given content hash
+func (s documentService) DocumentsByContentHash(ctx context.Context, hash string) ([]Document, error) {
+	docs, err := s.repo.SearchContentHash(ctx, hash)
+	if err != nil {
+		s.logger.Error("DocumentsByContentHash: repository error", logging.ErrV(err))
+		return nil, fmt.Errorf("cannot search for the documents of the content hash; %v", err)
+	}
+	return s.convertList(docs), nil
+}
+
+// Duplicates groups the documents storing identical files
+func (s documentService) Duplicates(ctx context.Context) ([]DuplicateGroup, error) {
+	docs, err := s.repo.SearchDuplicates(ctx)
+	if err != nil {
+		s.logger.Error("Duplicates: repository error", logging.ErrV(err))
+		return nil, fmt.Errorf("cannot search for duplicate documents; %v", err)
+	}
+	var groups []DuplicateGroup
+	for _, d := range docs {
+		// the documents are ordered by the content hash
+		if len(groups) == 0 || groups[len(groups)-1].ContentHash != d.ContentHash.String {
+			groups = append(groups, DuplicateGroup{ContentHash: d.ContentHash.String})
+		}
+		group := &groups[len(groups)-1]
+		group.Documents = append(group.Documents, s.convertToDomain(d))
+	}
+	return groups, nil
+}
+
+// ComputeContentHashes hashes the files of the documents stored without a content hash.
+// Documents which cannot be processed are logged and counted as failed.
+func (s documentService) ComputeContentHashes(ctx context.Context) (r HashResult, err error) {
+	const pageSize = 100
+	// only the hash of the documents is changed, the paging is stable
+	order := []OrderBy{{Field: "created", Order: ASC}}
+	for skip := 0; ; skip += pageSize {
+		docs, err := s.repo.Search(ctx, DocSearch{Limit: pageSize, Skip: skip}, order)
+		if err != nil {
+			return r, fmt.Errorf("cannot search for documents; %v", err)
+		}
+		for _, doc := range docs.Documents {
+			if doc.ContentHash.Valid {
+				continue
+			}
+			item, err := s.fileSvc.GetFile(ctx, doc.FileName)
+			if err != nil {
+				s.logger.Warn(fmt.Sprintf("ComputeContentHashes: cannot get the file of document '%s'", doc.ID), logging.ErrV(err))
+				r.Failed++
+				continue
+			}
+			hash := sql.NullString{String: ContentHash(item.Payload), Valid: true}
+			if err = s.repo.UpdateContentHash(ctx, doc.ID, hash, shared.Atomic{}); err != nil {
+				return r, fmt.Errorf("could not update the content hash of document '%s'; %v", doc.ID, err)
+			}
+			r.Computed++
+		}
+		if len(docs.Documents) < pageSize {
+			return r, nil
+		}
+	}
+}
+
+// MergeDuplicates merges the metadata of the duplicates into the kept document and removes the
+// duplicates. Tags and senders are combined, the amount and the invoice number of a duplicate
+// are used if the kept document has none.
+func (s documentService) MergeDuplicates(ctx context.Context, keepID string, ids []string) (d Document, err error) {
+	keep, files, err := s.mergeEntries(ctx, keepID, ids)
+	if err != nil {
+		return d, err
+	}
+	// the entries are removed, a file which cannot be deleted is only orphaned
+	for _, f := range files {
+		if err := s.fileSvc.DeleteFile(ctx, f); err != nil {
+			s.logger.Warn(fmt.Sprintf("MergeDuplicates: could not delete the file '%s'", f), logging.ErrV(err))
+		}
+		if err := s.fileSvc.DeleteFile(ctx, f+previewSuffix); err != nil {
+			s.logger.Warn("MergeDuplicates: could not delete the preview", logging.ErrV(err))
+		}
+	}
+	return s.convertToDomain(keep), nil
+}
+
+// mergeEntries updates the kept document and deletes the duplicates within one transaction.
+// It returns the files of the duplicates which are not used by the kept document.
+func (s documentService) mergeEntries(ctx context.Context, keepID string, ids []string) (keep DocEntity, files []string, err error) {
+	atomic, err := s.repo.CreateAtomic()
+	if err != nil {
+		return
+	}
+	defer func() {
+		err = shared.HandleTX(true, &atomic, err)
+	}()
+
+	if keep, err = s.repo.Get(ctx, keepID, atomic); err != nil {
+		return keep, nil, shared.ErrNotFound(fmt.Sprintf("could not find document by id: %s", keepID))
+	}
+	if !keep.ContentHash.Valid {
+		return keep, nil, shared.ErrValidation(fmt.Sprintf("the document '%s' has no content hash", keepID))
+	}
+
+	for _, id := range ids {
+		if id == keepID {
+			continue
+		}
+		dup, err := s.repo.Get(ctx, id, atomic)
+		if err != nil {
+			return keep, nil, shared.ErrNotFound(fmt.Sprintf("could not find document by id: %s", id))
+		}
+		if dup.ContentHash != keep.ContentHash {
+			return keep, nil, shared.ErrValidation(fmt.Sprintf("the document '%s' is not a duplicate of '%s'", id, keepID))
+		}
+		mergeEntity(&keep, dup)
+		revisions, err := s.revisionFiles(ctx, id, keep.FileName)
+		if err != nil {
+			return keep, nil, err
+		}
+		if err = s.repo.Delete(ctx, id, atomic); err != nil {
+			return keep, nil, fmt.Errorf("could not delete the duplicate '%s'; %v", id, err)
+		}
+		if dup.FileName != keep.FileName && !slices.Contains(files, dup.FileName) {
+			files = append(files, dup.FileName)
+		}
+		for _, f := range revisions {
+			if !slices.Contains(files, f) {
+				files = append(files, f)
+			}
+		}
+	}
+
+	if keep, err = s.repo.Save(ctx, keep, atomic); err != nil {
+		return keep, nil, fmt.Errorf("error while saving document: %v", err)
+	}
+	return keep, files, nil
+}
+
+// mergeEntity adds the metadata of the duplicate to the kept document
+func mergeEntity(keep *DocEntity, dup DocEntity) {
+	keep.TagList = mergeList(keep.TagList, dup.TagList)
+	keep.SenderList = mergeList(keep.SenderList, dup.SenderList)
+	if !keep.AmountMinor.Valid && dup.AmountMinor.Valid {
+		keep.AmountMinor, keep.Currency = dup.AmountMinor, dup.Currency
+	}
+	if keep.InvoiceNumber.String == "" && dup.InvoiceNumber.String != "" {
+		keep.InvoiceNumber = dup.InvoiceNumber
+	}
+	if !keep.DueDate.Valid && !keep.ReminderDate.Valid {
+		keep.DueDate, keep.ReminderDate, keep.DueType = dup.DueDate, dup.ReminderDate, dup.DueType
+		keep.DoneAt, keep.RemindedAt = dup.DoneAt, dup.RemindedAt
+	}
+	// a reviewed duplicate completes the review
+	keep.NeedsReview = keep.NeedsReview && dup.NeedsReview
+}
+
+// mergeList combines the ';' separated lists, the case of the entries is ignored
+func mergeList(list, other string) string {
+	var entries []string
+	if list != "" {
+		entries = strings.Split(list, ";")
+	}
+	for _, e := range strings.Split(other, ";") {
+		if e != "" && !slices.ContainsFunc(entries, func(v string) bool { return strings.EqualFold(v, e) }) {
+			entries = append(entries, e)
+		}
+	}
+	return strings.Join(entries, ";")
+}
@@ -0,0 +1,113 @@
+package document_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared/sqlitetest"
+)
+
+// sqliteRepo creates a repository of a new database with the schema of mydms
+func sqliteRepo(t *testing.T) document.Repository {
+	repo, err := document.NewRepository(sqlitetest.NewConn(t))
+	if err != nil {
+		t.Fatalf("could not create new repository; %v", err)
+	}
+	return repo
+}
+
+func saveEntity(t *testing.T, repo document.Repository, doc document.DocEntity) document.DocEntity {
+	doc, err := repo.Save(context.TODO(), doc, shared.Atomic{})
+	if err != nil {
+		t.Fatalf("could not save document; %v", err)
+	}
+	return doc
+}
+
+func Test_ContentHash(t *testing.T) {
+	assert.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", document.ContentHash(nil))
+	assert.Len(t, document.ContentHash([]byte(pdfPayload)), 64)
+}
+
+func Test_Duplicates(t *testing.T) {
+	repo := sqliteRepo(t)
+	fileSvc := newFileService()
+	svc := document.NewService(logger, repo, fileSvc, nil, nil, nil)
+
+	hash := document.ContentHash([]byte(pdfPayload))
+	saveEntity(t, repo, document.DocEntity{Title: "first", FileName: "/2024_01_01/a.pdf", ContentHash: sql.NullString{String: hash, Valid: true}})
+	saveEntity(t, repo, document.DocEntity{Title: "unique", FileName: "/2024_01_01/b.pdf", ContentHash: sql.NullString{String: "other", Valid: true}})
+	// the hash of the old document is computed from the stored file
+	saveEntity(t, repo, document.DocEntity{Title: "old", FileName: "/2020_01_01/a.pdf"})
+
+	groups, err := svc.Duplicates(context.TODO())
+	assert.NoError(t, err)
+	assert.Len(t, groups, 0)
+
+	result, err := svc.ComputeContentHashes(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, document.HashResult{Computed: 1}, result)
+
+	groups, err = svc.Duplicates(context.TODO())
+	assert.NoError(t, err)
+	assert.Len(t, groups, 1)
+	assert.Equal(t, hash, groups[0].ContentHash)
+	assert.Len(t, groups[0].Documents, 2)
+
+	docs, err := svc.DocumentsByContentHash(context.TODO(), hash)
+	assert.NoError(t, err)
+	assert.Len(t, docs, 2)
+}
+
+func Test_MergeDuplicates(t *testing.T) {
+	repo := sqliteRepo(t)
+	fileSvc := newFileService()
+	svc := document.NewService(logger, repo, fileSvc, nil, nil, nil)
+
+	hash := sql.NullString{String: document.ContentHash([]byte(pdfPayload)), Valid: true}
+	keep := saveEntity(t, repo, document.DocEntity{Title: "keep", FileName: "/2024_01_01/a.pdf", TagList: "Tax", SenderList: "Office", ContentHash: hash, NeedsReview: true})
+	dup := saveEntity(t, repo, document.DocEntity{
+		Title:         "duplicate",
+		FileName:      "/2024_02_01/a.pdf",
+		TagList:       "tax;invoice",
+		SenderList:    "Shop",
+		AmountMinor:   sql.NullInt64{Int64: 1050, Valid: true},
+		Currency:      sql.NullString{String: "EUR", Valid: true},
+		InvoiceNumber: sql.NullString{String: "4711", Valid: true},
+		ContentHash:   hash,
+	})
+	// the same file uploaded on the same day
+	same := saveEntity(t, repo, document.DocEntity{Title: "same", FileName: "/2024_01_01/a.pdf", ContentHash: hash, NeedsReview: true})
+	other := saveEntity(t, repo, document.DocEntity{Title: "other", FileName: "/2024_01_01/b.pdf", ContentHash: sql.NullString{String: "other", Valid: true}})
+
+	_, err := svc.MergeDuplicates(context.TODO(), keep.ID, []string{dup.ID, other.ID})
+	var validation *shared.ValidationError
+	assert.ErrorAs(t, err, &validation)
+	// nothing was changed
+	docs, err := svc.DocumentsByContentHash(context.TODO(), hash.String)
+	assert.NoError(t, err)
+	assert.Len(t, docs, 3)
+
+	merged, err := svc.MergeDuplicates(context.TODO(), keep.ID, []string{keep.ID, dup.ID, same.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, "keep", merged.Title)
+	assert.Equal(t, []string{"Tax", "invoice"}, merged.Tags)
+	assert.Equal(t, []string{"Office", "Shop"}, merged.Senders)
+	assert.Equal(t, document.Money{Minor: 1050, Currency: "EUR"}, merged.Amount)
+	assert.Equal(t, "4711", merged.InvoiceNumber)
+	assert.False(t, merged.NeedsReview)
+
+	docs, err = svc.DocumentsByContentHash(context.TODO(), hash.String)
+	assert.NoError(t, err)
+	assert.Len(t, docs, 1)
+	// the file of the kept document is not deleted
+	assert.Equal(t, []string{"/2024_02_01/a.pdf", "/2024_02_01/a.pdf.preview.jpg"}, fileSvc.deleted)
+
+	_, err = svc.MergeDuplicates(context.TODO(), "unknown", []string{dup.ID})
+	var notFound *shared.NotFoundError
+	assert.ErrorAs(t, err, &notFound)
+}
@@ -0,0 +1,86 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+	"golang.binggl.net/monorepo/pkg/logging"
+)
+
+// ListItem is a tag or a sender and the number of documents using it
+type ListItem struct {
+	ID        string
+	Name      string
+	Documents int
+}
+
+// ListItems returns the tags or senders ordered by name
+func (s documentService) ListItems(ctx context.Context, st SearchType) ([]ListItem, error) {
+	entities, err := s.repo.ListItems(ctx, st)
+	if err != nil {
+		s.logger.Error("ListItems: repository error", logging.ErrV(err))
+		return nil, fmt.Errorf("cannot get the %s; %v", st, err)
+	}
+	items := make([]ListItem, 0, len(entities))
+	for _, e := range entities {
+		items = append(items, ListItem{ID: e.ID, Name: s.policy.Sanitize(e.Name), Documents: e.Documents})
+	}
+	return items, nil
+}
+
+// RenameListItem changes the name of a tag or sender for all documents. A name used by another
+// entry is rejected, the entries need to be merged instead.
+func (s documentService) RenameListItem(ctx context.Context, st SearchType, id, name string) error {
+	name = strings.TrimSpace(s.policy.Sanitize(name))
+	if name == "" || strings.Contains(name, ";") {
+		return shared.ErrValidation(fmt.Sprintf("the name '%s' is not valid", name))
+	}
+	items, err := s.ListItems(ctx, st)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(items, func(i ListItem) bool { return i.ID == id }) {
+		return shared.ErrNotFound(fmt.Sprintf("could not find the entry by id: %s", id))
+	}
+	if slices.ContainsFunc(items, func(i ListItem) bool { return i.ID != id && strings.EqualFold(i.Name, name) }) {
+		return shared.ErrValidation(fmt.Sprintf("the name '%s' is already used, merge the entries instead", name))
+	}
+	if err = s.repo.RenameListItem(ctx, st, id, name, shared.Atomic{}); err != nil {
+		s.logger.Error("RenameListItem: repository error", logging.ErrV(err))
+		return fmt.Errorf("could not rename the entry '%s'; %v", id, err)
+	}
+	return nil
+}
+
+// MergeListItems replaces the given tags or senders by the target for all documents
+func (s documentService) MergeListItems(ctx context.Context, st SearchType, targetID string, ids []string) error {
+	items, err := s.ListItems(ctx, st)
+	if err != nil {
+		return err
+	}
+	for _, id := range append([]string{targetID}, ids...) {
+		if !slices.ContainsFunc(items, func(i ListItem) bool { return i.ID == id }) {
+			return shared.ErrNotFound(fmt.Sprintf("could not find the entry by id: %s", id))
+		}
+	}
+	if !slices.ContainsFunc(ids, func(id string) bool { return id != targetID }) {
+		return shared.ErrValidation("at least one entry besides the target is needed")
+	}
+	if err = s.repo.MergeListItems(ctx, st, targetID, ids, shared.Atomic{}); err != nil {
+		s.logger.Error("MergeListItems: repository error", logging.ErrV(err))
+		return fmt.Errorf("could not merge the entries into '%s'; %v", targetID, err)
+	}
+	return nil
+}
+
+// DeleteListItem removes a tag or sender from all documents
+func (s documentService) DeleteListItem(ctx context.Context, st SearchType, id string) error {
+	if err := s.repo.DeleteListItem(ctx, st, id, shared.Atomic{}); err != nil {
+		s.logger.Error("DeleteListItem: repository error", logging.ErrV(err))
+		return shared.ErrNotFound(fmt.Sprintf("could not delete the entry by id: %s", id))
+	}
+	return nil
+}
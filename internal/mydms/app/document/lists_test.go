@@ -0,0 +1,53 @@
+package document_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+)
+
+func Test_ListItems(t *testing.T) {
+	repo := sqliteRepo(t)
+	svc := document.NewService(logger, repo, newFileService(), nil, nil, nil)
+
+	saveEntity(t, repo, document.DocEntity{Title: "a", FileName: "a.pdf", SenderList: "Telekom"})
+	saveEntity(t, repo, document.DocEntity{Title: "b", FileName: "b.pdf", SenderList: "Telekom GmbH;Power Corp"})
+	doc := saveEntity(t, repo, document.DocEntity{Title: "c", FileName: "c.pdf", SenderList: "telekom gmbh"})
+
+	senders, err := svc.ListItems(context.TODO(), document.SENDERS)
+	assert.NoError(t, err)
+	assert.Len(t, senders, 3)
+	power, telekom, gmbh := senders[0], senders[1], senders[2]
+	assert.Equal(t, "Telekom GmbH", gmbh.Name)
+	assert.Equal(t, 2, gmbh.Documents)
+
+	var (
+		validation *shared.ValidationError
+		notFound   *shared.NotFoundError
+	)
+	// the name of another sender needs a merge
+	assert.ErrorAs(t, svc.RenameListItem(context.TODO(), document.SENDERS, telekom.ID, "TELEKOM GMBH"), &validation)
+	assert.ErrorAs(t, svc.RenameListItem(context.TODO(), document.SENDERS, telekom.ID, "a;b"), &validation)
+	assert.ErrorAs(t, svc.RenameListItem(context.TODO(), document.SENDERS, "unknown", "Telekom"), &notFound)
+	// only the case is changed
+	assert.NoError(t, svc.RenameListItem(context.TODO(), document.SENDERS, telekom.ID, " TELEKOM "))
+
+	assert.ErrorAs(t, svc.MergeListItems(context.TODO(), document.SENDERS, gmbh.ID, []string{gmbh.ID}), &validation)
+	assert.ErrorAs(t, svc.MergeListItems(context.TODO(), document.SENDERS, gmbh.ID, []string{"unknown"}), &notFound)
+	assert.NoError(t, svc.MergeListItems(context.TODO(), document.SENDERS, gmbh.ID, []string{telekom.ID}))
+
+	assert.NoError(t, svc.DeleteListItem(context.TODO(), document.SENDERS, power.ID))
+	assert.ErrorAs(t, svc.DeleteListItem(context.TODO(), document.SENDERS, power.ID), &notFound)
+
+	senders, err = svc.ListItems(context.TODO(), document.SENDERS)
+	assert.NoError(t, err)
+	assert.Len(t, senders, 1)
+	assert.Equal(t, 3, senders[0].Documents)
+
+	d, err := svc.GetDocumentByID(context.TODO(), doc.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Telekom GmbH"}, d.Senders)
+}
@@ -0,0 +1,150 @@
+package document
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is used for amounts without a currency, the amounts of mydms were stored in EUR
+const DefaultCurrency = "EUR"
+
+// Money is an exact decimal amount of a currency. The value is kept in the minor unit of the
+// currency (e.g. cents), the number of decimals is defined by ISO 4217.
+type Money struct {
+	Minor    int64
+	Currency string
+}
+
+// currencies which do not use two decimals
+var currencyDigits = map[string]int{
+	"BHD": 3, "CLP": 0, "IQD": 3, "ISK": 0, "JOD": 3, "JPY": 0, "KRW": 0,
+	"KWD": 3, "LYD": 3, "OMR": 3, "PYG": 0, "TND": 3, "UGX": 0, "VND": 0,
+}
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// CurrencyDigits returns the number of decimals of the currency
+func CurrencyDigits(currency string) int {
+	if d, ok := currencyDigits[currency]; ok {
+		return d
+	}
+	return 2
+}
+
+// ValidCurrency checks for an ISO 4217 currency code
+func ValidCurrency(currency string) bool {
+	return currencyCode.MatchString(currency)
+}
+
+// ParseMoney parses a decimal value like "1234.5" or "1234,50" of the given currency.
+// More decimals than the currency supports are rejected, the value is not rounded.
+func ParseMoney(value, currency string) (Money, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	if !ValidCurrency(currency) {
+		return Money{}, fmt.Errorf("invalid currency '%s'", currency)
+	}
+
+	v := strings.ReplaceAll(strings.TrimSpace(value), " ", "")
+	if v == "" {
+		return Money{}, fmt.Errorf("no amount supplied")
+	}
+	negative := strings.HasPrefix(v, "-")
+	v = strings.TrimPrefix(strings.TrimPrefix(v, "-"), "+")
+
+	// the last separator is the decimal separator if the other one is used for thousands
+	if strings.Contains(v, ",") && strings.Contains(v, ".") {
+		if strings.LastIndex(v, ",") > strings.LastIndex(v, ".") {
+			v = strings.ReplaceAll(v, ".", "")
+		} else {
+			v = strings.ReplaceAll(v, ",", "")
+		}
+	}
+	v = strings.ReplaceAll(v, ",", ".")
+
+	whole, fraction, _ := strings.Cut(v, ".")
+	digits := CurrencyDigits(currency)
+	if len(fraction) > digits {
+		return Money{}, fmt.Errorf("the amount '%s' has more than %d decimals", value, digits)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	digitsOnly := whole + fraction + strings.Repeat("0", digits-len(fraction))
+	if strings.ContainsFunc(digitsOnly, func(r rune) bool { return r < '0' || r > '9' }) {
+		return Money{}, fmt.Errorf("invalid amount '%s'", value)
+	}
+	minor, err := strconv.ParseInt(digitsOnly, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid amount '%s': %v", value, err)
+	}
+	if negative {
+		minor = -minor
+	}
+	return Money{Minor: minor, Currency: currency}, nil
+}
+
+// IsZero is true if no amount is available
+func (m Money) IsZero() bool {
+	return m.Minor == 0
+}
+
+// Add sums two amounts of the same currency
+func (m Money) Add(o Money) Money {
+	return Money{Minor: m.Minor + o.Minor, Currency: m.Currency}
+}
+
+// Decimal formats the amount without the currency, e.g. "1234.50"
+func (m Money) Decimal() string {
+	digits := CurrencyDigits(m.Currency)
+	sign := ""
+	minor := m.Minor
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	if digits == 0 {
+		return sign + strconv.FormatInt(minor, 10)
+	}
+	unit := int64(math.Pow10(digits))
+	return fmt.Sprintf("%s%d.%0*d", sign, minor/unit, digits, minor%unit)
+}
+
+// String formats the amount with the currency, e.g. "1234.50 EUR"
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+type moneyJSON struct {
+	Value    string `json:"value"`
+	Currency string `json:"currency"`
+}
+
+// MarshalJSON uses the decimal representation, floating point numbers are not exact
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Value: m.Decimal(), Currency: m.Currency})
+}
+
+// UnmarshalJSON parses the decimal representation
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var v moneyJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Value == "" {
+		*m = Money{}
+		return nil
+	}
+	parsed, err := ParseMoney(v.Value, v.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
@@ -0,0 +1,55 @@
+package document_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+)
+
+func TestParseMoney(t *testing.T) {
+	cases := []struct {
+		value    string
+		currency string
+		minor    int64
+		decimal  string
+		currOut  string
+	}{
+		{"12.34", "EUR", 1234, "12.34", "EUR"},
+		{"12,3", "eur", 1230, "12.30", "EUR"},
+		{"1.234,56", "", 123456, "1234.56", "EUR"},
+		{"1,234.56", "USD", 123456, "1234.56", "USD"},
+		{"-0.05", "EUR", -5, "-0.05", "EUR"},
+		{"1500", "JPY", 1500, "1500", "JPY"},
+		{"1.5", "KWD", 1500, "1.500", "KWD"},
+		{".5", "EUR", 50, "0.50", "EUR"},
+	}
+	for _, c := range cases {
+		m, err := document.ParseMoney(c.value, c.currency)
+		assert.NoError(t, err, c.value)
+		assert.Equal(t, c.minor, m.Minor, c.value)
+		assert.Equal(t, c.currOut, m.Currency, c.value)
+		assert.Equal(t, c.decimal, m.Decimal(), c.value)
+	}
+
+	for _, invalid := range [][2]string{{"12.345", "EUR"}, {"1.5", "JPY"}, {"abc", "EUR"}, {"", "EUR"}, {"1", "EURO"}, {"1-2", "EUR"}} {
+		_, err := document.ParseMoney(invalid[0], invalid[1])
+		assert.Error(t, err, invalid[0])
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	m, _ := document.ParseMoney("99.90", "CHF")
+	payload, err := json.Marshal(document.Document{Amount: m})
+	assert.NoError(t, err)
+	assert.Contains(t, string(payload), `"amount":{"value":"99.90","currency":"CHF"}`)
+
+	var doc document.Document
+	assert.NoError(t, json.Unmarshal(payload, &doc))
+	assert.Equal(t, m, doc.Amount)
+
+	// no amount
+	payload, _ = json.Marshal(document.Document{})
+	assert.NotContains(t, string(payload), `"amount"`)
+}
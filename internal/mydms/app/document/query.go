@@ -0,0 +1,287 @@
+package document
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// QueryField is the field of a filter of the search query
+type QueryField string
+
+const (
+	// TextField matches the title, the tags, the senders and the invoice number
+	TextField    QueryField = "text"
+	TitleField   QueryField = "title"
+	TagField     QueryField = "tag"
+	SenderField  QueryField = "sender"
+	InvoiceField QueryField = "invoice"
+	AmountField  QueryField = "amount"
+	CreatedField QueryField = "created"
+)
+
+// Filter is a condition of the search query, all filters of a query need to match
+type Filter struct {
+	Field QueryField
+	// Negate matches the documents not matching the condition
+	Negate bool
+	// Text is contained in the text fields, the case is ignored
+	Text string
+	// Min and Max limit the amount in the minor unit, the Currency is optional
+	Min, Max sql.NullInt64
+	Currency string
+	// From and Until limit the creation of the documents, a zero value is open
+	From, Until time.Time
+}
+
+// QueryError is an invalid fragment of the search query, Start and End are the byte offsets
+// of the fragment within the query
+type QueryError struct {
+	Fragment string
+	Start    int
+	End      int
+	Message  string
+}
+
+func (e QueryError) Error() string {
+	return fmt.Sprintf("'%s': %s", e.Fragment, e.Message)
+}
+
+// ParseQuery parses the search query into filters. The query consists of words and quoted
+// phrases, which are searched in the text fields, and field filters:
+//
+//	tag:invoice sender:"Power Corp" invoice:4711 title:letter
+//	amount:>100 amount:10..20.50 amount:<=100usd
+//	created:2024 created:2024-01..2024-03 created:>=2024-02-15
+//
+// A leading '-' negates a word, a phrase or a filter. Invalid fragments are returned as errors,
+// the filters of the valid fragments are used for the search.
+func ParseQuery(q string) (filters []Filter, errs []QueryError) {
+	for _, t := range tokenize(q) {
+		f, err := parseToken(t.text)
+		if err != nil {
+			errs = append(errs, QueryError{Fragment: t.text, Start: t.start, End: t.end, Message: err.Error()})
+			continue
+		}
+		if f.Field == TextField && f.Text == "" {
+			continue
+		}
+		filters = append(filters, f)
+	}
+	return filters, errs
+}
+
+type token struct {
+	text       string
+	start, end int
+}
+
+// tokenize splits the query at the spaces outside of quotes
+func tokenize(q string) (tokens []token) {
+	start := -1
+	quoted := false
+	for i, r := range q {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case unicode.IsSpace(r) && !quoted:
+			if start >= 0 {
+				tokens = append(tokens, token{text: q[start:i], start: start, end: i})
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{text: q[start:], start: start, end: len(q)})
+	}
+	return tokens
+}
+
+var fieldName = regexp.MustCompile(`^([a-zA-Z]+):`)
+
+func parseToken(text string) (f Filter, err error) {
+	if len(text) > 1 && text[0] == '-' {
+		f.Negate = true
+		text = text[1:]
+	}
+	if strings.Count(text, `"`)%2 != 0 {
+		return f, fmt.Errorf("the closing quote is missing")
+	}
+
+	f.Field = TextField
+	value := text
+	if m := fieldName.FindStringSubmatch(text); m != nil {
+		f.Field = QueryField(strings.ToLower(m[1]))
+		value = text[len(m[0]):]
+		if value == "" {
+			return f, fmt.Errorf("no value is supplied for '%s'", m[0])
+		}
+	}
+	value = strings.ReplaceAll(value, `"`, "")
+
+	switch f.Field {
+	case TextField, TitleField, TagField, SenderField, InvoiceField:
+		f.Text = strings.TrimSpace(value)
+	case AmountField:
+		err = parseAmountFilter(&f, value)
+	case CreatedField:
+		err = parseCreatedFilter(&f, value)
+	default:
+		err = fmt.Errorf("the field '%s' is not supported, use one of tag, sender, invoice, title, amount, created", f.Field)
+	}
+	return f, err
+}
+
+var comparison = regexp.MustCompile(`^(>=|<=|>|<|=)?(.*)$`)
+
+// parseAmountFilter parses a comparison or a range of amounts with an optional currency
+func parseAmountFilter(f *Filter, value string) error {
+	if lower, upper, ok := strings.Cut(value, ".."); ok {
+		if lower == "" && upper == "" {
+			return fmt.Errorf("the range needs a lower or an upper amount")
+		}
+		if lower != "" {
+			minor, currency, err := parseAmount(lower)
+			if err != nil {
+				return err
+			}
+			f.Min, f.Currency = sql.NullInt64{Int64: minor, Valid: true}, currency
+		}
+		if upper != "" {
+			minor, currency, err := parseAmount(upper)
+			if err != nil {
+				return err
+			}
+			if currency != "" && f.Currency != "" && currency != f.Currency {
+				return fmt.Errorf("the range uses the currencies %s and %s", f.Currency, currency)
+			}
+			f.Max = sql.NullInt64{Int64: minor, Valid: true}
+			if currency != "" {
+				f.Currency = currency
+			}
+		}
+		return nil
+	}
+
+	m := comparison.FindStringSubmatch(value)
+	minor, currency, err := parseAmount(m[2])
+	if err != nil {
+		return err
+	}
+	f.Currency = currency
+	// the amounts are integers, the exclusive comparisons are converted
+	switch m[1] {
+	case ">":
+		f.Min = sql.NullInt64{Int64: minor + 1, Valid: true}
+	case ">=":
+		f.Min = sql.NullInt64{Int64: minor, Valid: true}
+	case "<":
+		f.Max = sql.NullInt64{Int64: minor - 1, Valid: true}
+	case "<=":
+		f.Max = sql.NullInt64{Int64: minor, Valid: true}
+	default:
+		f.Min = sql.NullInt64{Int64: minor, Valid: true}
+		f.Max = f.Min
+	}
+	return nil
+}
+
+var amountValue = regexp.MustCompile(`^([\d.,]+)([a-zA-Z]{3})?$`)
+
+// parseAmount returns the amount in the minor unit of the currency, without a currency the
+// default currency defines the decimals
+func parseAmount(value string) (int64, string, error) {
+	m := amountValue.FindStringSubmatch(value)
+	if m == nil {
+		return 0, "", fmt.Errorf("'%s' is not an amount like 100, 12.50 or 10usd", value)
+	}
+	currency := strings.ToUpper(m[2])
+	money, err := ParseMoney(m[1], currency)
+	if err != nil {
+		return 0, "", err
+	}
+	return money.Minor, currency, nil
+}
+
+// parseCreatedFilter parses a comparison or a range of a year, a month or a day
+func parseCreatedFilter(f *Filter, value string) error {
+	if lower, upper, ok := strings.Cut(value, ".."); ok {
+		if lower == "" && upper == "" {
+			return fmt.Errorf("the range needs a start or an end")
+		}
+		if lower != "" {
+			start, _, err := parsePeriod(lower)
+			if err != nil {
+				return err
+			}
+			f.From = start
+		}
+		if upper != "" {
+			_, end, err := parsePeriod(upper)
+			if err != nil {
+				return err
+			}
+			f.Until = end
+		}
+		if !f.From.IsZero() && !f.Until.IsZero() && f.Until.Before(f.From) {
+			return fmt.Errorf("the end of the range is before the start")
+		}
+		return nil
+	}
+
+	m := comparison.FindStringSubmatch(value)
+	start, end, err := parsePeriod(m[2])
+	if err != nil {
+		return err
+	}
+	switch m[1] {
+	case ">":
+		f.From = end.Add(time.Nanosecond)
+	case ">=":
+		f.From = start
+	case "<":
+		f.Until = start.Add(-time.Nanosecond)
+	case "<=":
+		f.Until = end
+	default:
+		f.From, f.Until = start, end
+	}
+	return nil
+}
+
+// parsePeriod returns the start and the end of a year (2006), a month (2006-01) or a day (2006-01-02)
+func parsePeriod(value string) (start, end time.Time, err error) {
+	for _, p := range []struct {
+		layout string
+		years  int
+		months int
+		days   int
+	}{
+		{layout: "2006", years: 1},
+		{layout: "2006-01", months: 1},
+		{layout: "2006-01-02", days: 1},
+	} {
+		if len(value) != len(p.layout) {
+			continue
+		}
+		if start, err = time.Parse(p.layout, value); err == nil {
+			return start, start.AddDate(p.years, p.months, p.days).Add(-time.Nanosecond), nil
+		}
+	}
+	return start, end, fmt.Errorf("'%s' is not a date like 2024, 2024-01 or 2024-01-31", value)
+}
+
+// Field returns the field of the search query which filters the tags or senders
+func (s SearchType) Field() QueryField {
+	if s == TAGS {
+		return TagField
+	}
+	return SenderField
+}
@@ -0,0 +1,87 @@
+package document
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuery(t *testing.T) {
+	filters, errs := ParseQuery(`  invoice  -"power corp" tag:"Deutsche Telekom" -sender:shop INVOICE:4711 `)
+	assert.Len(t, errs, 0)
+	assert.Equal(t, []Filter{
+		{Field: TextField, Text: "invoice"},
+		{Field: TextField, Text: "power corp", Negate: true},
+		{Field: TagField, Text: "Deutsche Telekom"},
+		{Field: SenderField, Text: "shop", Negate: true},
+		{Field: InvoiceField, Text: "4711"},
+	}, filters)
+
+	filters, errs = ParseQuery("")
+	assert.Len(t, filters, 0)
+	assert.Len(t, errs, 0)
+}
+
+func TestParseQueryAmount(t *testing.T) {
+	min := func(v int64) sql.NullInt64 { return sql.NullInt64{Int64: v, Valid: true} }
+	for query, expected := range map[string]Filter{
+		"amount:>100":        {Min: min(10001)},
+		"amount:>=100":       {Min: min(10000)},
+		"amount:<12,50":      {Max: min(1249)},
+		"amount:<=12.50":     {Max: min(1250)},
+		"amount:99":          {Min: min(9900), Max: min(9900)},
+		"amount:10..20":      {Min: min(1000), Max: min(2000)},
+		"amount:..20usd":     {Max: min(2000), Currency: "USD"},
+		"amount:1000jpy..":   {Min: min(1000), Currency: "JPY"},
+		"-amount:=1.234,56":  {Min: min(123456), Max: min(123456), Negate: true},
+		"amount:5eur..10eur": {Min: min(500), Max: min(1000), Currency: "EUR"},
+	} {
+		filters, errs := ParseQuery(query)
+		assert.Len(t, errs, 0, query)
+		expected.Field = AmountField
+		assert.Equal(t, []Filter{expected}, filters, query)
+	}
+}
+
+func TestParseQueryCreated(t *testing.T) {
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+	end := func(s string) time.Time { return date(s).Add(-time.Nanosecond) }
+	for query, expected := range map[string]Filter{
+		"created:2024":               {From: date("2024-01-01"), Until: end("2025-01-01")},
+		"created:2024-02":            {From: date("2024-02-01"), Until: end("2024-03-01")},
+		"created:2024-01..2024-03":   {From: date("2024-01-01"), Until: end("2024-04-01")},
+		"created:2024-02-15..":       {From: date("2024-02-15")},
+		"created:..2023":             {Until: end("2024-01-01")},
+		"created:>2024-01":           {From: date("2024-02-01")},
+		"created:<2024-01-15":        {Until: end("2024-01-15")},
+		"created:>=2024-12-31":       {From: date("2024-12-31")},
+		"created:<=2024-12":          {Until: end("2025-01-01")},
+		"-created:2024-01-01..2024":  {From: date("2024-01-01"), Until: end("2025-01-01"), Negate: true},
+		`created:"2024-01..2024-03"`: {From: date("2024-01-01"), Until: end("2024-04-01")},
+	} {
+		filters, errs := ParseQuery(query)
+		assert.Len(t, errs, 0, query)
+		expected.Field = CreatedField
+		assert.Equal(t, []Filter{expected}, filters, query)
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	query := `invoice author:me amount:>ten created:2024-13 created:2024..2023 amount:1eur..2usd tag: "open amount:..`
+	filters, errs := ParseQuery(query)
+	assert.Equal(t, []Filter{{Field: TextField, Text: "invoice"}}, filters)
+	assert.Len(t, errs, 7)
+	for _, e := range errs {
+		assert.Equal(t, e.Fragment, query[e.Start:e.End])
+		assert.NotEmpty(t, e.Message)
+	}
+	assert.Equal(t, "author:me", errs[0].Fragment)
+	assert.Contains(t, errs[0].Error(), "not supported")
+	// the quote extends the fragment to the end of the query
+	assert.Equal(t, `"open amount:..`, errs[6].Fragment)
+}
@@ -0,0 +1,131 @@
+package document
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// ReportEntry is the sum of the amounts of a group of documents
+type ReportEntry struct {
+	Key   string
+	Total Money
+	Count int
+}
+
+// Report aggregates the amounts of the documents of a single currency. A document with several
+// senders or tags is counted for each of them, the totals of these groups can overlap.
+type Report struct {
+	Currency string
+	// Currencies lists the currencies used by the documents matching the search
+	Currencies []string
+	Total      Money
+	Count      int
+	BySender   []ReportEntry
+	ByTag      []ReportEntry
+	ByMonth    []ReportEntry
+	ByYear     []ReportEntry
+}
+
+// newReport aggregates the amounts of the given currency, without a currency the default
+// currency or the first available currency is used
+func newReport(amounts []AmountEntity, currency string) Report {
+	r := Report{}
+	for _, a := range amounts {
+		if !slices.Contains(r.Currencies, a.Currency) {
+			r.Currencies = append(r.Currencies, a.Currency)
+		}
+	}
+	slices.Sort(r.Currencies)
+
+	if currency == "" {
+		currency = DefaultCurrency
+		if len(r.Currencies) > 0 && !slices.Contains(r.Currencies, DefaultCurrency) {
+			currency = r.Currencies[0]
+		}
+	}
+	r.Currency = currency
+	r.Total = Money{Currency: currency}
+
+	var (
+		senders = newGroups(currency)
+		tags    = newGroups(currency)
+		months  = newGroups(currency)
+		years   = newGroups(currency)
+	)
+	for _, a := range amounts {
+		if a.Currency != currency {
+			continue
+		}
+		m := Money{Minor: a.AmountMinor, Currency: a.Currency}
+		r.Total = r.Total.Add(m)
+		r.Count++
+
+		for _, s := range splitList(a.SenderList) {
+			senders.add(s, m)
+		}
+		for _, t := range splitList(a.TagList) {
+			tags.add(t, m)
+		}
+		months.add(a.Created.Format("2006-01"), m)
+		years.add(a.Created.Format("2006"), m)
+	}
+
+	r.BySender = senders.byTotal()
+	r.ByTag = tags.byTotal()
+	r.ByMonth = months.byKey()
+	r.ByYear = years.byKey()
+	return r
+}
+
+func splitList(list string) []string {
+	if list == "" {
+		return nil
+	}
+	return strings.Split(list, ";")
+}
+
+type groups struct {
+	currency string
+	entries  map[string]*ReportEntry
+}
+
+func newGroups(currency string) *groups {
+	return &groups{currency: currency, entries: make(map[string]*ReportEntry)}
+}
+
+func (g *groups) add(key string, m Money) {
+	e, ok := g.entries[key]
+	if !ok {
+		e = &ReportEntry{Key: key, Total: Money{Currency: g.currency}}
+		g.entries[key] = e
+	}
+	e.Total = e.Total.Add(m)
+	e.Count++
+}
+
+func (g *groups) list() []ReportEntry {
+	entries := make([]ReportEntry, 0, len(g.entries))
+	for _, e := range g.entries {
+		entries = append(entries, *e)
+	}
+	return entries
+}
+
+// byTotal sorts the groups with the highest amount first
+func (g *groups) byTotal() []ReportEntry {
+	entries := g.list()
+	slices.SortFunc(entries, func(a, b ReportEntry) int {
+		return cmp.Or(cmp.Compare(b.Total.Minor, a.Total.Minor), strings.Compare(a.Key, b.Key))
+	})
+	return entries
+}
+
+// byKey sorts the groups chronologically
+func (g *groups) byKey() []ReportEntry {
+	entries := g.list()
+	slices.SortFunc(entries, func(a, b ReportEntry) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return entries
+}
@@ -1,10 +1,10 @@
 package document
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
-	"sort"
 	"strings"
 	"time"
 
@@ -20,12 +20,38 @@ type DocEntity struct {
 	FileName      string         `db:"filename"`
 	AltID         string         `db:"alternativeid"`
 	PreviewLink   sql.NullString `db:"previewlink"`
-	Amount        float32        `db:"amount"`
+	AmountMinor   sql.NullInt64  `db:"amountminor"`
+	Currency      sql.NullString `db:"currency"`
 	Created       time.Time      `db:"created"`
 	Modified      sql.NullTime   `db:"modified"`
 	TagList       string         `db:"taglist"`
 	SenderList    string         `db:"senderlist"`
 	InvoiceNumber sql.NullString `db:"invoicenumber"`
+	// NeedsReview marks documents created without user interaction, e.g. by the inbox
+	NeedsReview bool `db:"needsreview"`
+	// ContentHash is the hex encoded SHA-256 hash of the stored file
+	ContentHash sql.NullString `db:"contenthash"`
+	// DueDate is the date of the payment, the end of the contract or the follow-up
+	DueDate sql.NullTime `db:"duedate"`
+	// ReminderDate is the day of the reminder, the due date is used without a reminder date
+	ReminderDate sql.NullTime   `db:"reminderdate"`
+	DueType      sql.NullString `db:"duetype"`
+	// DoneAt is set once the document was marked as paid or done
+	DoneAt sql.NullTime `db:"doneat"`
+	// RemindedAt is set once the reminder was sent
+	RemindedAt sql.NullTime `db:"remindedat"`
+}
+
+// docColumns are the columns of a DocEntity, the tags and senders are aggregated from the linked names
+var docColumns = "id,title,filename,alternativeid,previewlink,amountminor,currency," + listColumns + ",created,modified,invoicenumber,needsreview,contenthash," + dueColumns
+
+// AmountEntity holds the amount of a document and the values used to group amounts
+type AmountEntity struct {
+	AmountMinor int64     `db:"amountminor"`
+	Currency    string    `db:"currency"`
+	Created     time.Time `db:"created"`
+	TagList     string    `db:"taglist"`
+	SenderList  string    `db:"senderlist"`
 }
 
 // PagedDocResult wraps a list of documents and returns the total number of documents
@@ -62,8 +88,12 @@ type DocSearch struct {
 	Sender string
 	From   time.Time
 	Until  time.Time
-	Limit  int
-	Skip   int
+	// NeedsReview restricts the search to the documents which need to be reviewed
+	NeedsReview bool
+	// Filters of a structured query, see ParseQuery
+	Filters []Filter
+	Limit   int
+	Skip    int
 }
 
 // OrderBy is used to sort a result list
@@ -75,12 +105,33 @@ type OrderBy struct {
 // Repository is the CRUD interface for documents in the persistence store
 type Repository interface {
 	shared.BaseRepository
-	Get(id string) (d DocEntity, err error)
-	Exists(id string, a shared.Atomic) (filePath string, err error)
-	Save(doc DocEntity, a shared.Atomic) (d DocEntity, err error)
-	Delete(id string, a shared.Atomic) (err error)
-	Search(s DocSearch, order []OrderBy) (PagedDocResult, error)
-	SearchLists(s string, st SearchType) ([]string, error)
+	Get(ctx context.Context, id string, a shared.Atomic) (d DocEntity, err error)
+	Exists(ctx context.Context, id string, a shared.Atomic) (filePath string, err error)
+	Save(ctx context.Context, doc DocEntity, a shared.Atomic) (d DocEntity, err error)
+	Delete(ctx context.Context, id string, a shared.Atomic) (err error)
+	UpdatePreview(ctx context.Context, id string, previewLink sql.NullString, a shared.Atomic) (err error)
+	UpdateContentHash(ctx context.Context, id string, hash sql.NullString, a shared.Atomic) (err error)
+	// SearchContentHash returns the documents of the given content hash, the oldest document first
+	SearchContentHash(ctx context.Context, hash string) ([]DocEntity, error)
+	// SearchDuplicates returns the documents sharing a content hash, ordered by hash and creation
+	SearchDuplicates(ctx context.Context) ([]DocEntity, error)
+	Search(ctx context.Context, s DocSearch, order []OrderBy) (PagedDocResult, error)
+	SearchAmounts(ctx context.Context, s DocSearch) ([]AmountEntity, error)
+	SearchLists(ctx context.Context, s string, st SearchType) ([]string, error)
+	ListItems(ctx context.Context, st SearchType) ([]ListEntity, error)
+	RenameListItem(ctx context.Context, st SearchType, id, name string, a shared.Atomic) (err error)
+	MergeListItems(ctx context.Context, st SearchType, targetID string, ids []string, a shared.Atomic) (err error)
+	DeleteListItem(ctx context.Context, st SearchType, id string, a shared.Atomic) (err error)
+	// SearchDue returns the documents having a due or reminder date, ordered by the due date.
+	// The documents marked as done are only returned if requested.
+	SearchDue(ctx context.Context, includeDone bool) ([]DocEntity, error)
+	// SearchReminders returns the open documents whose reminder is due until the given day and not yet sent
+	SearchReminders(ctx context.Context, day time.Time) ([]DocEntity, error)
+	UpdateDone(ctx context.Context, id string, done sql.NullTime, a shared.Atomic) (err error)
+	UpdateReminded(ctx context.Context, id string, reminded sql.NullTime, a shared.Atomic) (err error)
+	// Revisions returns the stored files of the document, the first revision first
+	Revisions(ctx context.Context, documentID string) ([]RevisionEntity, error)
+	SaveRevision(ctx context.Context, rev RevisionEntity, a shared.Atomic) (r RevisionEntity, err error)
 }
 
 // compiler interface check
@@ -91,7 +142,9 @@ func NewRepository(c shared.Connection) (Repository, error) {
 	if !c.Active {
 		return nil, fmt.Errorf("no repository connection available")
 	}
-	return &dbRepository{c}, nil
+	var repo Repository = &dbRepository{c}
+	repo = RepositoryTracingMiddleware()(repo)
+	return repo, nil
 }
 
 type dbRepository struct {
@@ -106,7 +159,7 @@ func (rw *dbRepository) CreateAtomic() (shared.Atomic, error) {
 // Save a document entry. Either create or update the entry, based on availability
 // if a valid/active atomic object is supplied the transaction handling is done by the caller
 // otherwise a new transaction is created for the scope of the method
-func (rw *dbRepository) Save(doc DocEntity, a shared.Atomic) (d DocEntity, err error) {
+func (rw *dbRepository) Save(ctx context.Context, doc DocEntity, a shared.Atomic) (d DocEntity, err error) {
 	var (
 		atomic   *shared.Atomic
 		newEntry bool
@@ -128,7 +181,7 @@ func (rw *dbRepository) Save(doc DocEntity, a shared.Atomic) (d DocEntity, err e
 	if doc.ID != "" {
 		var find DocEntity
 		// use the database logic for row-locking to prevent issues concurrently updating entries
-		err = rw.c.Get(&find, "SELECT id,title,filename,alternativeid,previewlink,amount,taglist,senderlist,created,modified,invoicenumber FROM DOCUMENTS WHERE id=?", doc.ID)
+		err = rw.c.GetContext(ctx, &find, "SELECT "+docColumns+" FROM DOCUMENTS WHERE id=?", doc.ID)
 		if err != nil {
 			log.Printf("could not get a Document by ID '%s' - a new entry will be created", doc.ID)
 			newEntry = true
@@ -142,11 +195,11 @@ func (rw *dbRepository) Save(doc DocEntity, a shared.Atomic) (d DocEntity, err e
 		doc.ID = uuid.New().String()
 		doc.Created = time.Now().UTC()
 		doc.AltID = randomString()
-		r, err = atomic.NamedExec("INSERT INTO DOCUMENTS (id,title,filename,alternativeid,previewlink,amount,taglist,senderlist,created,invoicenumber) VALUES (:id,:title,:filename,:alternativeid,:previewlink,:amount,:taglist,:senderlist,:created,:invoicenumber)", &doc)
+		r, err = atomic.NamedExecContext(ctx, "INSERT INTO DOCUMENTS (id,title,filename,alternativeid,previewlink,amountminor,currency,created,invoicenumber,needsreview,contenthash,"+dueColumns+") VALUES (:id,:title,:filename,:alternativeid,:previewlink,:amountminor,:currency,:created,:invoicenumber,:needsreview,:contenthash,:duedate,:reminderdate,:duetype,:doneat,:remindedat)", &doc)
 	} else {
 		m := sql.NullTime{Time: time.Now().UTC(), Valid: true}
 		doc.Modified = m
-		r, err = atomic.NamedExec("UPDATE DOCUMENTS SET title=:title,filename=:filename,alternativeid=:alternativeid,previewlink=:previewlink,amount=:amount,taglist=:taglist,senderlist=:senderlist,modified=:modified,invoicenumber=:invoicenumber WHERE id=:id", &doc)
+		r, err = atomic.NamedExecContext(ctx, "UPDATE DOCUMENTS SET title=:title,filename=:filename,alternativeid=:alternativeid,previewlink=:previewlink,amountminor=:amountminor,currency=:currency,modified=:modified,invoicenumber=:invoicenumber,needsreview=:needsreview,contenthash=:contenthash,duedate=:duedate,reminderdate=:reminderdate,duetype=:duetype,doneat=:doneat,remindedat=:remindedat WHERE id=:id", &doc)
 	}
 
 	if err != nil {
@@ -162,13 +215,23 @@ func (rw *dbRepository) Save(doc DocEntity, a shared.Atomic) (d DocEntity, err e
 		err = fmt.Errorf("invalid number of rows affected, got %d", c)
 		return
 	}
+	if err = saveList(ctx, atomic, doc.ID, TAGS, doc.TagList); err != nil {
+		return
+	}
+	if err = saveList(ctx, atomic, doc.ID, SENDERS, doc.SenderList); err != nil {
+		return
+	}
 
 	return doc, nil
 }
 
-// Get retuns a document by the given id
-func (rw *dbRepository) Get(id string) (d DocEntity, err error) {
-	err = rw.c.Get(&d, "SELECT id,title,filename,alternativeid,previewlink,amount,taglist,senderlist,created,modified,invoicenumber FROM DOCUMENTS WHERE id=?", id)
+// Get retuns a document by the given id, an active transaction is used to read the document
+func (rw *dbRepository) Get(ctx context.Context, id string, a shared.Atomic) (d DocEntity, err error) {
+	var q sqlx.QueryerContext = rw.c
+	if a.Active {
+		q = a
+	}
+	err = sqlx.GetContext(ctx, q, &d, "SELECT "+docColumns+" FROM DOCUMENTS WHERE id=?", id)
 	if err != nil {
 		err = fmt.Errorf("cannot get document by id '%s': %v", id, err)
 		return
@@ -177,7 +240,7 @@ func (rw *dbRepository) Get(id string) (d DocEntity, err error) {
 }
 
 // Exists checks if a given id is available
-func (rw *dbRepository) Exists(id string, a shared.Atomic) (filePath string, err error) {
+func (rw *dbRepository) Exists(ctx context.Context, id string, a shared.Atomic) (filePath string, err error) {
 	var (
 		atomic *shared.Atomic
 	)
@@ -191,7 +254,7 @@ func (rw *dbRepository) Exists(id string, a shared.Atomic) (filePath string, err
 	}
 
 	var filename string
-	err = atomic.Get(&filename, "SELECT filename FROM DOCUMENTS WHERE id = ?", id)
+	err = atomic.GetContext(ctx, &filename, "SELECT filename FROM DOCUMENTS WHERE id = ?", id)
 	if err != nil {
 		err = fmt.Errorf("cannot query document or document not available. %v", err)
 		return
@@ -201,7 +264,7 @@ func (rw *dbRepository) Exists(id string, a shared.Atomic) (filePath string, err
 }
 
 // Delete a document by its id
-func (rw *dbRepository) Delete(id string, a shared.Atomic) (err error) {
+func (rw *dbRepository) Delete(ctx context.Context, id string, a shared.Atomic) (err error) {
 	var (
 		atomic *shared.Atomic
 	)
@@ -214,45 +277,108 @@ func (rw *dbRepository) Delete(id string, a shared.Atomic) (err error) {
 		return
 	}
 
-	_, err = atomic.Exec("DELETE FROM DOCUMENTS WHERE id = ?", id)
+	_, err = atomic.ExecContext(ctx, "DELETE FROM DOCUMENTS WHERE id = ?", id)
 	if err != nil {
 		err = fmt.Errorf("cannot delete document item: %v", err)
+		return
+	}
+	if err = deleteRevisions(ctx, atomic, id); err != nil {
+		return
+	}
+	return deleteLists(ctx, atomic, id)
+}
+
+// UpdatePreview sets the preview-link of a document, the modification date is not changed
+func (rw *dbRepository) UpdatePreview(ctx context.Context, id string, previewLink sql.NullString, a shared.Atomic) (err error) {
+	var (
+		atomic *shared.Atomic
+		r      sql.Result
+	)
+
+	defer func() {
+		err = shared.HandleTX(!a.Active, atomic, err)
+	}()
+
+	if atomic, err = shared.CheckTX(rw.c, &a); err != nil {
+		return
+	}
+
+	r, err = atomic.ExecContext(ctx, "UPDATE DOCUMENTS SET previewlink = ? WHERE id = ?", previewLink, id)
+	if err != nil {
+		err = fmt.Errorf("cannot update the preview of the document: %v", err)
+		return
+	}
+	c, err := r.RowsAffected()
+	if err != nil {
+		err = fmt.Errorf("could not get affected rows: %v", err)
+		return
+	}
+	if c != 1 {
+		err = fmt.Errorf("invalid number of rows affected, got %d", c)
 	}
 	return
 }
 
+// UpdateContentHash sets the content hash of a document, the modification date is not changed
+func (rw *dbRepository) UpdateContentHash(ctx context.Context, id string, hash sql.NullString, a shared.Atomic) (err error) {
+	var (
+		atomic *shared.Atomic
+		r      sql.Result
+	)
+
+	defer func() {
+		err = shared.HandleTX(!a.Active, atomic, err)
+	}()
+
+	if atomic, err = shared.CheckTX(rw.c, &a); err != nil {
+		return
+	}
+
+	r, err = atomic.ExecContext(ctx, "UPDATE DOCUMENTS SET contenthash = ? WHERE id = ?", hash, id)
+	if err != nil {
+		err = fmt.Errorf("cannot update the content hash of the document: %v", err)
+		return
+	}
+	c, err := r.RowsAffected()
+	if err != nil {
+		err = fmt.Errorf("could not get affected rows: %v", err)
+		return
+	}
+	if c != 1 {
+		err = fmt.Errorf("invalid number of rows affected, got %d", c)
+	}
+	return
+}
+
+func (rw *dbRepository) SearchContentHash(ctx context.Context, hash string) ([]DocEntity, error) {
+	var docs []DocEntity
+	if err := rw.c.SelectContext(ctx, &docs, "SELECT "+docColumns+" FROM DOCUMENTS WHERE contenthash = ? ORDER BY created ASC", hash); err != nil {
+		return nil, fmt.Errorf("could not get the documents of the content hash: %v", err)
+	}
+	return docs, nil
+}
+
+func (rw *dbRepository) SearchDuplicates(ctx context.Context) ([]DocEntity, error) {
+	var docs []DocEntity
+	q := "SELECT " + docColumns + " FROM DOCUMENTS WHERE contenthash IN (" +
+		"SELECT contenthash FROM DOCUMENTS WHERE contenthash IS NOT NULL GROUP BY contenthash HAVING count(id) > 1)" +
+		"\nORDER BY contenthash ASC, created ASC"
+	if err := rw.c.SelectContext(ctx, &docs, q); err != nil {
+		return nil, fmt.Errorf("could not get the duplicate documents: %v", err)
+	}
+	return docs, nil
+}
+
 // Search for documents based on the supplied search-object 'DocSearch'
 // the slice of order-bys is used to defined the query sort-order
-func (rw *dbRepository) Search(s DocSearch, order []OrderBy) (d PagedDocResult, err error) {
+func (rw *dbRepository) Search(ctx context.Context, s DocSearch, order []OrderBy) (d PagedDocResult, err error) {
 	var query string
-	q := "SELECT id,title,filename,alternativeid,previewlink,amount,taglist,senderlist,created,modified,invoicenumber FROM DOCUMENTS"
+	q := "SELECT " + docColumns + " FROM DOCUMENTS"
 	qc := "SELECT count(id) FROM DOCUMENTS"
-	where := "\nWHERE 1=1"
+	where, arg := searchFilter(s)
 	paging := ""
 	orderby := orderBy(order)
-	arg := make(map[string]interface{})
 
-	// use the supplied search-object to create the query
-	if s.Title != "" {
-		where += "\nAND ( lower(title) LIKE :search OR lower(taglist) LIKE :search OR lower(senderlist) LIKE :search OR lower(invoicenumber) LIKE :search)"
-		arg["search"] = "%" + strings.ToLower(s.Title) + "%"
-	}
-	if s.Tag != "" {
-		where += "\nAND lower(taglist) LIKE :tag"
-		arg["tag"] = "%" + strings.ToLower(s.Tag) + "%"
-	}
-	if s.Sender != "" {
-		where += "\nAND lower(senderlist) LIKE :sender"
-		arg["sender"] = "%" + strings.ToLower(s.Sender) + "%"
-	}
-	if !s.From.IsZero() {
-		where += "\nAND created >= :from"
-		arg["from"] = s.From
-	}
-	if !s.Until.IsZero() {
-		where += "\nAND created <= :until"
-		arg["until"] = s.Until
-	}
 	if s.Limit > 0 {
 		paging += fmt.Sprintf("\nLIMIT %d", s.Limit)
 	}
@@ -268,7 +394,7 @@ func (rw *dbRepository) Search(s DocSearch, order []OrderBy) (d PagedDocResult,
 		return
 	}
 
-	if err = rw.c.Get(&c, query, args...); err != nil {
+	if err = rw.c.GetContext(ctx, &c, query, args...); err != nil {
 		err = fmt.Errorf("could not get the total number of documents: %v", err)
 		return
 	}
@@ -281,20 +407,124 @@ func (rw *dbRepository) Search(s DocSearch, order []OrderBy) (d PagedDocResult,
 		return
 	}
 	var docs []DocEntity
-	if err = rw.c.Select(&docs, query, args...); err != nil {
+	if err = rw.c.SelectContext(ctx, &docs, query, args...); err != nil {
 		err = fmt.Errorf("could not get the documents: %v", err)
 		return
 	}
 	return PagedDocResult{Documents: docs, Count: c}, nil
 }
 
+// SearchAmounts returns the amounts of the documents matching the search-object 'DocSearch'.
+// Documents without an amount are skipped, paging is not used.
+func (rw *dbRepository) SearchAmounts(ctx context.Context, s DocSearch) ([]AmountEntity, error) {
+	where, arg := searchFilter(s)
+	q := "SELECT amountminor,currency,created," + listColumns + " FROM DOCUMENTS" +
+		where + "\nAND amountminor IS NOT NULL AND currency IS NOT NULL\nORDER BY created ASC"
+	query, args, err := prepareQuery(rw.c, q, arg)
+	if err != nil {
+		return nil, err
+	}
+	var amounts []AmountEntity
+	if err = rw.c.SelectContext(ctx, &amounts, query, args...); err != nil {
+		return nil, fmt.Errorf("could not get the amounts of the documents: %v", err)
+	}
+	return amounts, nil
+}
+
+// searchFilter creates the where-clause of the search-object 'DocSearch', paging is not considered
+func searchFilter(s DocSearch) (where string, arg map[string]interface{}) {
+	where = "\nWHERE 1=1"
+	arg = make(map[string]interface{})
+	if s.Title != "" {
+		where += "\nAND ( lower(title) LIKE :search OR " + listFilter(TAGS, "search") + " OR " + listFilter(SENDERS, "search") + " OR lower(invoicenumber) LIKE :search)"
+		arg["search"] = "%" + strings.ToLower(s.Title) + "%"
+	}
+	if s.Tag != "" {
+		where += "\nAND " + listFilter(TAGS, "tag")
+		arg["tag"] = "%" + strings.ToLower(s.Tag) + "%"
+	}
+	if s.Sender != "" {
+		where += "\nAND " + listFilter(SENDERS, "sender")
+		arg["sender"] = "%" + strings.ToLower(s.Sender) + "%"
+	}
+	if !s.From.IsZero() {
+		where += "\nAND created >= :from"
+		arg["from"] = s.From
+	}
+	if !s.Until.IsZero() {
+		where += "\nAND created <= :until"
+		arg["until"] = s.Until
+	}
+	if s.NeedsReview {
+		where += "\nAND needsreview = 1"
+	}
+	for i, f := range s.Filters {
+		where += "\nAND " + filterCondition(f, fmt.Sprintf("filter%d", i), arg)
+	}
+	return where, arg
+}
+
+// filterCondition creates the condition of the filter, the arguments are named by the given name
+func filterCondition(f Filter, name string, arg map[string]interface{}) string {
+	var conditions []string
+	switch f.Field {
+	case AmountField:
+		conditions = append(conditions, "amountminor IS NOT NULL")
+		if f.Min.Valid {
+			conditions = append(conditions, "amountminor >= :"+name+"min")
+			arg[name+"min"] = f.Min.Int64
+		}
+		if f.Max.Valid {
+			conditions = append(conditions, "amountminor <= :"+name+"max")
+			arg[name+"max"] = f.Max.Int64
+		}
+		if f.Currency != "" {
+			conditions = append(conditions, "currency = :"+name+"currency")
+			arg[name+"currency"] = f.Currency
+		}
+	case CreatedField:
+		conditions = append(conditions, "1=1")
+		if !f.From.IsZero() {
+			conditions = append(conditions, "created >= :"+name+"from")
+			arg[name+"from"] = f.From
+		}
+		if !f.Until.IsZero() {
+			conditions = append(conditions, "created <= :"+name+"until")
+			arg[name+"until"] = f.Until
+		}
+	default:
+		arg[name] = "%" + strings.ToLower(f.Text) + "%"
+		title := "lower(title) LIKE :" + name
+		invoice := "lower(COALESCE(invoicenumber,'')) LIKE :" + name
+		switch f.Field {
+		case TitleField:
+			conditions = append(conditions, title)
+		case TagField:
+			conditions = append(conditions, listFilter(TAGS, name))
+		case SenderField:
+			conditions = append(conditions, listFilter(SENDERS, name))
+		case InvoiceField:
+			conditions = append(conditions, invoice)
+		default:
+			conditions = append(conditions, "( "+strings.Join([]string{title, listFilter(TAGS, name), listFilter(SENDERS, name), invoice}, " OR ")+" )")
+		}
+	}
+
+	condition := strings.Join(conditions, " AND ")
+	if f.Negate {
+		// documents without a value do not match the condition
+		return "NOT COALESCE((" + condition + "), 0)"
+	}
+	return "(" + condition + ")"
+}
+
 // SearchType is used to determine if the search is performed on tags or senders
 type SearchType uint
 
 const (
-	// TAGS is used to search tags within the documents table
+	// TAGS is used to search the tags of the documents
 	TAGS SearchType = iota
-	// SENDERS is used to search senders within the documents table
+	// SENDERS is used to search the senders of the documents
 	SENDERS
 )
 
@@ -305,54 +535,16 @@ func (s SearchType) String() string {
 	return "senders"
 }
 
-// SearchLists collects all tag-entries from all documents and returns those elements which start with
-// the given search term. The search is performed case insensitive
-func (rw *dbRepository) SearchLists(s string, st SearchType) ([]string, error) {
-	var (
-		t      string
-		result []string
-		lookup map[string]int
-		found  []string
-	)
+// SearchLists returns the tags or senders which start with the given search term.
+// The search is performed case insensitive
+func (rw *dbRepository) SearchLists(ctx context.Context, s string, st SearchType) ([]string, error) {
+	t := st.table()
+	query := fmt.Sprintf("SELECT name FROM %s WHERE lower(name) LIKE ? ORDER BY name", t.items)
 
-	search := make(map[SearchType]string)
-	search[TAGS] = "taglist"
-	search[SENDERS] = "senderlist"
-
-	query := "SELECT distinct(%s) as search FROM DOCUMENTS WHERE lower(%s) LIKE ?"
-	query = fmt.Sprintf(query, search[st], search[st])
-
-	rows, err := rw.c.Queryx(query, "%"+strings.ToLower(s)+"%")
-	if err != nil {
-		err = fmt.Errorf("could not search for %s: %v", search[st], err)
-		return nil, err
-	}
-	defer rows.Close()
-
-	lookup = make(map[string]int)
-	for rows.Next() {
-		if err := rows.Scan(&t); err != nil {
-			err = fmt.Errorf("could not get row contents: %v", err)
-			return nil, err
-		}
-		parts := strings.Split(t, ";")
-		for _, p := range parts {
-			if _, found := lookup[p]; !found && p != "" {
-				lookup[p] = 1
-				result = append(result, p)
-			}
-		}
-	}
-
-	// now we have collected all search-elements of all documents
-	// search for those which start with the given search term s
-	s = strings.ToLower(s)
-	for i := range result {
-		if strings.HasPrefix(strings.ToLower(result[i]), s) {
-			found = append(found, result[i])
-		}
+	var found []string
+	if err := rw.c.SelectContext(ctx, &found, query, strings.ToLower(s)+"%"); err != nil {
+		return nil, fmt.Errorf("could not search for %s: %v", st, err)
 	}
-	sort.Strings(found)
 	return found, nil
 }
 
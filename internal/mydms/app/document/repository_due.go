@@ -0,0 +1,78 @@
+package document
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+)
+
+// dueColumns are the columns of the due date, the reminder and the tracking of the documents
+const dueColumns = "duedate,reminderdate,duetype,doneat,remindedat"
+
+// dueIndex is used to find the upcoming documents
+const dueIndex = `CREATE INDEX IF NOT EXISTS "IX_DOCUMENTS_DUEDATE" ON "DOCUMENTS" ("duedate")`
+
+func (rw *dbRepository) SearchDue(ctx context.Context, includeDone bool) ([]DocEntity, error) {
+	var docs []DocEntity
+	q := "SELECT " + docColumns + " FROM DOCUMENTS WHERE (duedate IS NOT NULL OR reminderdate IS NOT NULL)"
+	if !includeDone {
+		q += " AND doneat IS NULL"
+	}
+	q += " ORDER BY COALESCE(duedate, reminderdate) ASC, title ASC"
+	if err := rw.c.SelectContext(ctx, &docs, q); err != nil {
+		return nil, fmt.Errorf("could not get the due documents: %v", err)
+	}
+	return docs, nil
+}
+
+func (rw *dbRepository) SearchReminders(ctx context.Context, day time.Time) ([]DocEntity, error) {
+	var docs []DocEntity
+	q := "SELECT " + docColumns + " FROM DOCUMENTS WHERE doneat IS NULL AND remindedat IS NULL" +
+		" AND COALESCE(reminderdate, duedate) <= ? ORDER BY COALESCE(duedate, reminderdate) ASC, title ASC"
+	if err := rw.c.SelectContext(ctx, &docs, q, day); err != nil {
+		return nil, fmt.Errorf("could not get the due reminders: %v", err)
+	}
+	return docs, nil
+}
+
+func (rw *dbRepository) UpdateDone(ctx context.Context, id string, done sql.NullTime, a shared.Atomic) (err error) {
+	return rw.updateDueColumn(ctx, "doneat", id, done, a)
+}
+
+func (rw *dbRepository) UpdateReminded(ctx context.Context, id string, reminded sql.NullTime, a shared.Atomic) (err error) {
+	return rw.updateDueColumn(ctx, "remindedat", id, reminded, a)
+}
+
+// updateDueColumn sets the tracking column of the document, the column is not user-supplied
+func (rw *dbRepository) updateDueColumn(ctx context.Context, column, id string, value sql.NullTime, a shared.Atomic) (err error) {
+	var (
+		atomic *shared.Atomic
+		r      sql.Result
+	)
+
+	defer func() {
+		err = shared.HandleTX(!a.Active, atomic, err)
+	}()
+
+	if atomic, err = shared.CheckTX(rw.c, &a); err != nil {
+		return
+	}
+
+	r, err = atomic.ExecContext(ctx, fmt.Sprintf("UPDATE DOCUMENTS SET %s = ? WHERE id = ?", column), value, id)
+	if err != nil {
+		err = fmt.Errorf("cannot update the %s of the document: %v", column, err)
+		return
+	}
+	c, err := r.RowsAffected()
+	if err != nil {
+		err = fmt.Errorf("could not get affected rows: %v", err)
+		return
+	}
+	if c != 1 {
+		err = fmt.Errorf("invalid number of rows affected, got %d", c)
+	}
+	return
+}
@@ -0,0 +1,241 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+)
+
+// listTables is the DDL of the tags and senders, which are linked to the documents.
+// The names are unique, the case of the names is ignored.
+const listTables = `CREATE TABLE IF NOT EXISTS "TAGS" (
+	"id"	varchar(36) NOT NULL,
+	"name"	varchar(255) NOT NULL COLLATE NOCASE,
+	PRIMARY KEY("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "IX_TAGS_NAME" ON "TAGS" (
+	"name"
+);
+
+CREATE TABLE IF NOT EXISTS "DOCUMENT_TAGS" (
+	"documentid"	varchar(36) NOT NULL,
+	"tagid"	varchar(36) NOT NULL,
+	"position"	integer NOT NULL DEFAULT 0,
+	PRIMARY KEY("documentid","tagid")
+);
+
+CREATE INDEX IF NOT EXISTS "IX_DOCUMENT_TAGS_TAG" ON "DOCUMENT_TAGS" (
+	"tagid"
+);
+
+CREATE TABLE IF NOT EXISTS "SENDERS" (
+	"id"	varchar(36) NOT NULL,
+	"name"	varchar(255) NOT NULL COLLATE NOCASE,
+	PRIMARY KEY("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "IX_SENDERS_NAME" ON "SENDERS" (
+	"name"
+);
+
+CREATE TABLE IF NOT EXISTS "DOCUMENT_SENDERS" (
+	"documentid"	varchar(36) NOT NULL,
+	"senderid"	varchar(36) NOT NULL,
+	"position"	integer NOT NULL DEFAULT 0,
+	PRIMARY KEY("documentid","senderid")
+);
+
+CREATE INDEX IF NOT EXISTS "IX_DOCUMENT_SENDERS_SENDER" ON "DOCUMENT_SENDERS" (
+	"senderid"
+);`
+
+// listColumns aggregates the linked tags and senders of a document into the ';' separated lists
+var listColumns = listColumn(TAGS) + "," + listColumn(SENDERS)
+
+// ListEntity is a tag or a sender and the number of linked documents
+type ListEntity struct {
+	ID        string `db:"id"`
+	Name      string `db:"name"`
+	Documents int    `db:"documents"`
+}
+
+// listTable names the tables of a SearchType
+type listTable struct {
+	items  string
+	links  string
+	key    string
+	column string
+}
+
+func (s SearchType) table() listTable {
+	if s == TAGS {
+		return listTable{items: "TAGS", links: "DOCUMENT_TAGS", key: "tagid", column: "taglist"}
+	}
+	return listTable{items: "SENDERS", links: "DOCUMENT_SENDERS", key: "senderid", column: "senderlist"}
+}
+
+// listColumn selects the names linked to the document in the order of the document
+func listColumn(st SearchType) string {
+	t := st.table()
+	return fmt.Sprintf("COALESCE((SELECT group_concat(i.name, ';' ORDER BY l.position) FROM %s l JOIN %s i ON i.id = l.%s WHERE l.documentid = DOCUMENTS.id),'') AS %s",
+		t.links, t.items, t.key, t.column)
+}
+
+// listFilter matches the documents linked to a name containing the named parameter
+func listFilter(st SearchType, param string) string {
+	t := st.table()
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM %s l JOIN %s i ON i.id = l.%s WHERE l.documentid = DOCUMENTS.id AND lower(i.name) LIKE :%s)",
+		t.links, t.items, t.key, param)
+}
+
+// saveList replaces the links of the document by the names of the ';' separated list.
+// Missing names are created, names without documents are removed.
+func saveList(ctx context.Context, a *shared.Atomic, id string, st SearchType, list string) error {
+	t := st.table()
+	if _, err := a.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE documentid = ?", t.links), id); err != nil {
+		return fmt.Errorf("could not remove the %s of the document: %v", st, err)
+	}
+	for i, name := range strings.Split(list, ";") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if _, err := a.ExecContext(ctx, fmt.Sprintf("INSERT OR IGNORE INTO %s (id,name) VALUES (?,?)", t.items), uuid.New().String(), name); err != nil {
+			return fmt.Errorf("could not create the %s '%s': %v", st, name, err)
+		}
+		q := fmt.Sprintf("INSERT OR IGNORE INTO %s (documentid,%s,position) SELECT ?, id, ? FROM %s WHERE name = ?", t.links, t.key, t.items)
+		if _, err := a.ExecContext(ctx, q, id, i, name); err != nil {
+			return fmt.Errorf("could not link the %s '%s': %v", st, name, err)
+		}
+	}
+	return removeUnused(ctx, a, st)
+}
+
+// removeUnused deletes the names which are not linked to a document
+func removeUnused(ctx context.Context, a *shared.Atomic, st SearchType) error {
+	t := st.table()
+	q := fmt.Sprintf("DELETE FROM %s WHERE NOT EXISTS (SELECT 1 FROM %s l WHERE l.%s = %s.id)", t.items, t.links, t.key, t.items)
+	if _, err := a.ExecContext(ctx, q); err != nil {
+		return fmt.Errorf("could not remove the unused %s: %v", st, err)
+	}
+	return nil
+}
+
+// deleteLists removes the links of the document
+func deleteLists(ctx context.Context, a *shared.Atomic, id string) error {
+	for _, st := range []SearchType{TAGS, SENDERS} {
+		if _, err := a.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE documentid = ?", st.table().links), id); err != nil {
+			return fmt.Errorf("could not remove the %s of the document: %v", st, err)
+		}
+		if err := removeUnused(ctx, a, st); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListItems returns the tags or senders ordered by name, including the number of linked documents
+func (rw *dbRepository) ListItems(ctx context.Context, st SearchType) ([]ListEntity, error) {
+	t := st.table()
+	q := fmt.Sprintf("SELECT i.id, i.name, count(l.documentid) AS documents FROM %s i LEFT JOIN %s l ON l.%s = i.id GROUP BY i.id, i.name ORDER BY i.name",
+		t.items, t.links, t.key)
+	var items []ListEntity
+	if err := rw.c.SelectContext(ctx, &items, q); err != nil {
+		return nil, fmt.Errorf("could not get the %s: %v", st, err)
+	}
+	return items, nil
+}
+
+// RenameListItem changes the name of a tag or sender, the name needs to be unique
+func (rw *dbRepository) RenameListItem(ctx context.Context, st SearchType, id, name string, a shared.Atomic) (err error) {
+	var atomic *shared.Atomic
+
+	defer func() {
+		err = shared.HandleTX(!a.Active, atomic, err)
+	}()
+
+	if atomic, err = shared.CheckTX(rw.c, &a); err != nil {
+		return
+	}
+
+	r, err := atomic.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET name = ? WHERE id = ?", st.table().items), name, id)
+	if err != nil {
+		err = fmt.Errorf("cannot rename the %s: %v", st, err)
+		return
+	}
+	c, err := r.RowsAffected()
+	if err != nil {
+		err = fmt.Errorf("could not get affected rows: %v", err)
+		return
+	}
+	if c != 1 {
+		err = fmt.Errorf("invalid number of rows affected, got %d", c)
+	}
+	return
+}
+
+// MergeListItems links the documents of the given items to the target and removes the items
+func (rw *dbRepository) MergeListItems(ctx context.Context, st SearchType, targetID string, ids []string, a shared.Atomic) (err error) {
+	var atomic *shared.Atomic
+
+	defer func() {
+		err = shared.HandleTX(!a.Active, atomic, err)
+	}()
+
+	if atomic, err = shared.CheckTX(rw.c, &a); err != nil {
+		return
+	}
+
+	t := st.table()
+	for _, id := range ids {
+		if id == targetID {
+			continue
+		}
+		// documents already linked to the target keep their link
+		q := fmt.Sprintf("INSERT OR IGNORE INTO %s (documentid,%s,position) SELECT documentid, ?, position FROM %s WHERE %s = ?", t.links, t.key, t.links, t.key)
+		if _, err = atomic.ExecContext(ctx, q, targetID, id); err != nil {
+			err = fmt.Errorf("cannot merge the %s: %v", st, err)
+			return
+		}
+		if err = rw.deleteListItem(ctx, atomic, st, id); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// DeleteListItem removes the tag or sender from all documents
+func (rw *dbRepository) DeleteListItem(ctx context.Context, st SearchType, id string, a shared.Atomic) (err error) {
+	var atomic *shared.Atomic
+
+	defer func() {
+		err = shared.HandleTX(!a.Active, atomic, err)
+	}()
+
+	if atomic, err = shared.CheckTX(rw.c, &a); err != nil {
+		return
+	}
+	return rw.deleteListItem(ctx, atomic, st, id)
+}
+
+func (rw *dbRepository) deleteListItem(ctx context.Context, a *shared.Atomic, st SearchType, id string) error {
+	t := st.table()
+	if _, err := a.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s = ?", t.links, t.key), id); err != nil {
+		return fmt.Errorf("cannot remove the %s from the documents: %v", st, err)
+	}
+	r, err := a.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE id = ?", t.items), id)
+	if err != nil {
+		return fmt.Errorf("cannot delete the %s: %v", st, err)
+	}
+	c, err := r.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("could not get affected rows: %v", err)
+	}
+	if c != 1 {
+		return fmt.Errorf("invalid number of rows affected, got %d", c)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package document
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+	"golang.binggl.net/monorepo/pkg/tracing"
+)
+
+// RepositoryMiddleware is used to intercept the repository methods
+type RepositoryMiddleware func(Repository) Repository
+
+// RepositoryTracingMiddleware creates a child-span for every repository call
+func RepositoryTracingMiddleware() RepositoryMiddleware {
+	return func(next Repository) Repository {
+		return repoTracingMiddleware{next}
+	}
+}
+
+// compile guard for Repository implementation
+var (
+	_ Repository = &repoTracingMiddleware{}
+)
+
+type repoTracingMiddleware struct {
+	next Repository
+}
+
+func (t repoTracingMiddleware) CreateAtomic() (shared.Atomic, error) {
+	return t.next.CreateAtomic()
+}
+
+func (t repoTracingMiddleware) Get(ctx context.Context, id string, a shared.Atomic) (d DocEntity, err error) {
+	ctx, span := tracing.Start(ctx, "document.Repository.Get", attribute.String("document.id", id))
+	defer func() { tracing.End(span, err) }()
+	return t.next.Get(ctx, id, a)
+}
+
+func (t repoTracingMiddleware) Exists(ctx context.Context, id string, a shared.Atomic) (filePath string, err error) {
+	ctx, span := tracing.Start(ctx, "document.Repository.Exists", attribute.String("document.id", id))
+	defer func() { tracing.End(span, err) }()
+	return t.next.Exists(ctx, id, a)
+}
+
+func (t repoTracingMiddleware) Save(ctx context.Context, doc DocEntity, a shared.Atomic) (d DocEntity, err error) {
+	ctx, span := tracing.Start(ctx, "document.Repository.Save", attribute.String("document.id", doc.ID))
+	defer func() { tracing.End(span, err) }()
+	return t.next.Save(ctx, doc, a)
+}
+
+func (t repoTracingMiddleware) Delete(ctx context.Context, id string, a shared.Atomic) (err error) {
+	ctx, span := tracing.Start(ctx, "document.Repository.Delete", attribute.String("document.id", id))
+	defer func() { tracing.End(span, err) }()
+	return t.next.Delete(ctx, id, a)
+}
+
+func (t repoTracingMiddleware) UpdatePreview(ctx context.Context, id string, previewLink sql.NullString, a shared.Atomic) (err error) {
+	ctx, span := tracing.Start(ctx, "document.Repository.UpdatePreview", attribute.String("document.id", id))
+	defer func() { tracing.End(span, err) }()
+	return t.next.UpdatePreview(ctx, id, previewLink, a)
+}
+
+func (t repoTracingMiddleware) UpdateContentHash(ctx context.Context, id string, hash sql.NullString, a shared.Atomic) (err error) {
+	ctx, span := tracing.Start(ctx, "document.Repository.UpdateContentHash", attribute.String("document.id", id))
+	defer func() { tracing.End(span, err) }()
+	return t.next.UpdateContentHash(ctx, id, hash, a)
+}
+
+func (t repoTracingMiddleware) SearchContentHash(ctx context.Context, hash string) (d []DocEntity, err error) {
+	ctx, span := tracing.Start(ctx, "document.Repository.SearchContentHash")
+	defer func() { tracing.End(span, err) }()
+	return t.next.SearchContentHash(ctx, hash)
+}
+
+func (t repoTracingMiddleware) SearchDuplicates(ctx context.Context) (d []DocEntity, err error) {
+	ctx, span := tracing.Start(ctx, "document.Repository.SearchDuplicates")
+	defer func() { tracing.End(span, err) }()
+	return t.next.SearchDuplicates(ctx)
+}
+
+func (t repoTracingMiddleware) Search(ctx context.Context, s DocSearch, order []OrderBy) (r PagedDocResult, err error) {
+	ctx, span := tracing.Start(ctx, "document.Repository.Search",
+		attribute.Int("search.limit", s.Limit),
+		attribute.Int("search.skip", s.Skip),
+	)
+	defer func() { tracing.End(span, err) }()
+	return t.next.Search(ctx, s, order)
+}
+
+func (t repoTracingMiddleware) SearchAmounts(ctx context.Context, s DocSearch) (a []AmountEntity, err error) {
+	ctx, span := tracing.Start(ctx, "document.Repository.SearchAmounts")
+	defer func() { tracing.End(span, err) }()
+	return t.next.SearchAmounts(ctx, s)
+}
+
+func (t repoTracingMiddleware) SearchLists(ctx context.Context, s string, st SearchType) (l []string, err error) {
+	ctx, span := tracing.Start(ctx, "document.Repository.SearchLists", attribute.String("search.type", st.String()))
+	defer func() { tracing.End(span, err) }()
+	return t.next.SearchLists(ctx, s, st)
+}
+
+func (t repoTracingMiddleware) ListItems(ctx context.Context, st SearchType) (l []ListEntity, err error) {
+	ctx, span := tracing.Start(ctx, "document.Repository.ListItems", attribute.String("search.type", st.String()))
+	defer func() { tracing.End(span, err) }()
+	return t.next.ListItems(ctx, st)
+}
+
+func (t repoTracingMiddleware) RenameListItem(ctx context.Context, st SearchType, id, name string, a shared.Atomic) (err error) {
+	ctx, span := tracing.Start(ctx, "document.Repository.RenameListItem", attribute.String("search.type", st.String()))
+	defer func() { tracing.End(span, err) }()
+	return t.next.RenameListItem(ctx, st, id, name, a)
+}
+
+func (t repoTracingMiddleware) MergeListItems(ctx context.Context, st SearchType, targetID string, ids []string, a shared.Atomic) (err error) {
+	ctx, span := tracing.Start(ctx, "document.Repository.MergeListItems", attribute.String("search.type", st.String()))
+	defer func() { tracing.End(span, err) }()
+	return t.next.MergeListItems(ctx, st, targetID, ids, a)
+}
+
+func (t repoTracingMiddleware) DeleteListItem(ctx context.Context, st SearchType, id string, a shared.Atomic) (err error) {
+	ctx, span := tracing.Start(ctx, "document.Repository.DeleteListItem", attribute.String("search.type", st.String()))
+	defer func() { tracing.End(span, err) }()
+	return t.next.DeleteListItem(ctx, st, id, a)
+}
+
+func (t repoTracingMiddleware) SearchDue(ctx context.Context, includeDone bool) (d []DocEntity, err error) {
+	ctx, span := tracing.Start(ctx, "document.Repository.SearchDue")
+	defer func() { tracing.End(span, err) }()
+	return t.next.SearchDue(ctx, includeDone)
+}
+
+func (t repoTracingMiddleware) SearchReminders(ctx context.Context, day time.Time) (d []DocEntity, err error) {
+	ctx, span := tracing.Start(ctx, "document.Repository.SearchReminders")
+	defer func() { tracing.End(span, err) }()
+	return t.next.SearchReminders(ctx, day)
+}
+
+func (t repoTracingMiddleware) UpdateDone(ctx context.Context, id string, done sql.NullTime, a shared.Atomic) (err error) {
+	ctx, span := tracing.Start(ctx, "document.Repository.UpdateDone", attribute.String("document.id", id))
+	defer func() { tracing.End(span, err) }()
+	return t.next.UpdateDone(ctx, id, done, a)
+}
+
+func (t repoTracingMiddleware) UpdateReminded(ctx context.Context, id string, reminded sql.NullTime, a shared.Atomic) (err error) {
+	ctx, span := tracing.Start(ctx, "document.Repository.UpdateReminded", attribute.String("document.id", id))
+	defer func() { tracing.End(span, err) }()
+	return t.next.UpdateReminded(ctx, id, reminded, a)
+}
+
+func (t repoTracingMiddleware) Revisions(ctx context.Context, documentID string) (revs []RevisionEntity, err error) {
+	ctx, span := tracing.Start(ctx, "document.Repository.Revisions", attribute.String("document.id", documentID))
+	defer func() { tracing.End(span, err) }()
+	return t.next.Revisions(ctx, documentID)
+}
+
+func (t repoTracingMiddleware) SaveRevision(ctx context.Context, rev RevisionEntity, a shared.Atomic) (r RevisionEntity, err error) {
+	ctx, span := tracing.Start(ctx, "document.Repository.SaveRevision", attribute.String("document.id", rev.DocumentID))
+	defer func() { tracing.End(span, err) }()
+	return t.next.SaveRevision(ctx, rev, a)
+}
@@ -0,0 +1,122 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+)
+
+// MigrateSchema updates the DOCUMENTS table of existing databases to the current schema and
+// moves the tags and senders of the documents into their own tables.
+// The migration is idempotent, a database created by the current DDL is not changed.
+func MigrateSchema(ctx context.Context, c shared.Connection) (err error) {
+	var columns []string
+	if err = c.SelectContext(ctx, &columns, "SELECT name FROM pragma_table_info('DOCUMENTS')"); err != nil {
+		return fmt.Errorf("could not read the schema of the documents: %v", err)
+	}
+	if len(columns) == 0 {
+		// the table is not available, nothing to migrate
+		return nil
+	}
+
+	atomic, err := c.CreateAtomic()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = shared.HandleTX(true, &atomic, err)
+	}()
+
+	if !slices.Contains(columns, "amountminor") {
+		if err = migrateAmounts(ctx, atomic); err != nil {
+			return fmt.Errorf("could not migrate the amounts of the documents: %v", err)
+		}
+	}
+	if !slices.Contains(columns, "needsreview") {
+		if _, err = atomic.ExecContext(ctx, `ALTER TABLE DOCUMENTS ADD COLUMN "needsreview" integer NOT NULL DEFAULT 0`); err != nil {
+			return fmt.Errorf("could not add the review flag of the documents: %v", err)
+		}
+	}
+	if !slices.Contains(columns, "contenthash") {
+		// the hashes of the existing documents are computed by the maintenance of the duplicates
+		if _, err = atomic.ExecContext(ctx, `ALTER TABLE DOCUMENTS ADD COLUMN "contenthash" varchar(64)`); err != nil {
+			return fmt.Errorf("could not add the content hash of the documents: %v", err)
+		}
+	}
+	if _, err = atomic.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS "IX_DOCUMENTS_CONTENTHASH" ON "DOCUMENTS" ("contenthash")`); err != nil {
+		return fmt.Errorf("could not create the index of the content hash: %v", err)
+	}
+	if !slices.Contains(columns, "duedate") {
+		for _, column := range []string{`"duedate" date`, `"reminderdate" date`, `"duetype" varchar(16)`, `"doneat" date`, `"remindedat" date`} {
+			if _, err = atomic.ExecContext(ctx, "ALTER TABLE DOCUMENTS ADD COLUMN "+column); err != nil {
+				return fmt.Errorf("could not add the due dates of the documents: %v", err)
+			}
+		}
+	}
+	if _, err = atomic.ExecContext(ctx, dueIndex); err != nil {
+		return fmt.Errorf("could not create the index of the due dates: %v", err)
+	}
+	if _, err = atomic.ExecContext(ctx, listTables); err != nil {
+		return fmt.Errorf("could not create the tables of the tags and senders: %v", err)
+	}
+	if _, err = atomic.ExecContext(ctx, revisionTable); err != nil {
+		return fmt.Errorf("could not create the table of the revisions: %v", err)
+	}
+	if slices.Contains(columns, "taglist") {
+		if err = migrateLists(ctx, &atomic); err != nil {
+			return fmt.Errorf("could not migrate the tags and senders of the documents: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrateLists links the documents to the entries of the ';' separated columns 'taglist' and
+// 'senderlist' and drops the columns. Names differing only in case are merged into the first name.
+func migrateLists(ctx context.Context, a *shared.Atomic) error {
+	var docs []struct {
+		ID         string `db:"id"`
+		TagList    string `db:"taglist"`
+		SenderList string `db:"senderlist"`
+	}
+	if err := a.SelectContext(ctx, &docs, "SELECT id,COALESCE(taglist,'') AS taglist,COALESCE(senderlist,'') AS senderlist FROM DOCUMENTS ORDER BY created ASC"); err != nil {
+		return err
+	}
+	for _, d := range docs {
+		if err := saveList(ctx, a, d.ID, TAGS, d.TagList); err != nil {
+			return err
+		}
+		if err := saveList(ctx, a, d.ID, SENDERS, d.SenderList); err != nil {
+			return err
+		}
+	}
+	for _, stmt := range []string{
+		`ALTER TABLE DOCUMENTS DROP COLUMN "taglist"`,
+		`ALTER TABLE DOCUMENTS DROP COLUMN "senderlist"`,
+	} {
+		if _, err := a.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateAmounts replaces the column 'amount' of type decimal(10,0) by the exact amount in the
+// minor unit of the currency. The former amounts were entered in EUR, an amount of 0 is treated
+// as no amount, which is how the UI displayed the value.
+func migrateAmounts(ctx context.Context, a shared.Atomic) error {
+	stmts := []string{
+		`ALTER TABLE DOCUMENTS ADD COLUMN "amountminor" integer`,
+		`ALTER TABLE DOCUMENTS ADD COLUMN "currency" varchar(3)`,
+		fmt.Sprintf(`UPDATE DOCUMENTS SET amountminor = CAST(ROUND(amount * 100) AS INTEGER), currency = '%s'
+			WHERE amount IS NOT NULL AND amount <> 0`, DefaultCurrency),
+		`ALTER TABLE DOCUMENTS DROP COLUMN "amount"`,
+	}
+	for _, stmt := range stmts {
+		if _, err := a.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package document
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+)
+
+// revisionTable is the DDL of the files stored for a document, each change of the pages
+// creates a new revision. The first revision is the originally uploaded file.
+const revisionTable = `CREATE TABLE IF NOT EXISTS "DOCUMENT_REVISIONS" (
+	"id"	varchar(36) NOT NULL,
+	"documentid"	varchar(36) NOT NULL,
+	"revision"	integer NOT NULL,
+	"filename"	varchar(255) NOT NULL,
+	"contenthash"	varchar(64),
+	"operation"	varchar(255) NOT NULL,
+	"created"	date NOT NULL,
+	PRIMARY KEY("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "IX_DOCUMENT_REVISIONS_DOCUMENT" ON "DOCUMENT_REVISIONS" (
+	"documentid",
+	"revision"
+);`
+
+// RevisionEntity is a file of a document created by a change of the pages
+type RevisionEntity struct {
+	ID          string         `db:"id"`
+	DocumentID  string         `db:"documentid"`
+	Revision    int            `db:"revision"`
+	FileName    string         `db:"filename"`
+	ContentHash sql.NullString `db:"contenthash"`
+	// Operation describes the change which created the revision
+	Operation string    `db:"operation"`
+	Created   time.Time `db:"created"`
+}
+
+func (rw *dbRepository) Revisions(ctx context.Context, documentID string) ([]RevisionEntity, error) {
+	var revs []RevisionEntity
+	q := "SELECT id,documentid,revision,filename,contenthash,operation,created FROM DOCUMENT_REVISIONS WHERE documentid = ? ORDER BY revision ASC"
+	if err := rw.c.SelectContext(ctx, &revs, q, documentID); err != nil {
+		return nil, fmt.Errorf("could not get the revisions of the document: %v", err)
+	}
+	return revs, nil
+}
+
+func (rw *dbRepository) SaveRevision(ctx context.Context, rev RevisionEntity, a shared.Atomic) (r RevisionEntity, err error) {
+	var (
+		atomic *shared.Atomic
+	)
+
+	defer func() {
+		err = shared.HandleTX(!a.Active, atomic, err)
+	}()
+
+	if atomic, err = shared.CheckTX(rw.c, &a); err != nil {
+		return
+	}
+
+	rev.ID = uuid.New().String()
+	if rev.Created.IsZero() {
+		rev.Created = time.Now().UTC()
+	}
+	_, err = atomic.NamedExecContext(ctx, `INSERT INTO DOCUMENT_REVISIONS (id,documentid,revision,filename,contenthash,operation,created)
+		VALUES (:id,:documentid,:revision,:filename,:contenthash,:operation,:created)`, &rev)
+	if err != nil {
+		err = fmt.Errorf("cannot save the revision of the document: %v", err)
+		return
+	}
+	return rev, nil
+}
+
+// deleteRevisions removes the revisions of a deleted document, the files are removed by the service
+func deleteRevisions(ctx context.Context, a *shared.Atomic, documentID string) error {
+	if _, err := a.ExecContext(ctx, "DELETE FROM DOCUMENT_REVISIONS WHERE documentid = ?", documentID); err != nil {
+		return fmt.Errorf("cannot delete the revisions of the document: %v", err)
+	}
+	return nil
+}
@@ -1,9 +1,11 @@
 package document
 
 import (
+	"context"
 	"database/sql"
 	_ "embed"
 	"fmt"
+	"regexp"
 	"testing"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
 	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared/sqlitetest"
 )
 
 const fatalErr = "an error '%s' was not expected when opening a stub database connection"
@@ -21,7 +24,8 @@ const existsErr = "error was not expected while checking for existence of item:
 const expectedErr = "error expected"
 
 const stmtInsertDocs = "INSERT INTO DOCUMENTS"
-const queryDocs = "SELECT id,title,filename,alternativeid,previewlink,amount,taglist,senderlist,created,modified,invoicenumber FROM DOCUMENTS"
+
+var queryDocs = regexp.QuoteMeta("SELECT " + docColumns + " FROM DOCUMENTS")
 
 var Err = fmt.Errorf("error")
 
@@ -34,6 +38,24 @@ func getDbx(t *testing.T) (dbx *sqlx.DB, db *sql.DB, mock sqlmock.Sqlmock) {
 	return
 }
 
+// expectSaveLists expects the links of a document with one tag and one sender
+func expectSaveLists(mock sqlmock.Sqlmock) {
+	for _, t := range []listTable{TAGS.table(), SENDERS.table()} {
+		mock.ExpectExec("DELETE FROM " + t.links).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT OR IGNORE INTO " + t.items).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT OR IGNORE INTO " + t.links).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("DELETE FROM " + t.items).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+}
+
+// expectDeleteLists expects the removal of the links of a document
+func expectDeleteLists(mock sqlmock.Sqlmock) {
+	for _, t := range []listTable{TAGS.table(), SENDERS.table()} {
+		mock.ExpectExec("DELETE FROM " + t.links).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("DELETE FROM " + t.items).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+}
+
 func TestAtomic(t *testing.T) {
 	dbx, db, mock := getDbx(t)
 	defer db.Close()
@@ -78,11 +100,12 @@ func TestSave(t *testing.T) {
 	rw := dbRepository{c}
 
 	item := DocEntity{
-		Title:      "title",
-		FileName:   "filename",
-		Amount:     10,
-		TagList:    "taglist",
-		SenderList: "senderlist",
+		Title:       "title",
+		FileName:    "filename",
+		AmountMinor: sql.NullInt64{Int64: 1000, Valid: true},
+		Currency:    sql.NullString{String: "EUR", Valid: true},
+		TagList:     "taglist",
+		SenderList:  "senderlist",
 	}
 
 	errInsert := "error was not expected while inserting item: %v"
@@ -90,18 +113,20 @@ func TestSave(t *testing.T) {
 	// INSERT
 	mock.ExpectBegin()
 	mock.ExpectExec(stmtInsertDocs).WillReturnResult(sqlmock.NewResult(1, 1))
+	expectSaveLists(mock)
 	mock.ExpectCommit()
 
 	var (
 		d   DocEntity
 		err error
 	)
-	if d, err = rw.Save(item, shared.Atomic{}); err != nil {
+	if d, err = rw.Save(context.TODO(), item, shared.Atomic{}); err != nil {
 		t.Errorf(errInsert, err)
 	}
 	assert.Equal(t, item.Title, d.Title)
 	assert.Equal(t, item.FileName, d.FileName)
-	assert.Equal(t, item.Amount, d.Amount)
+	assert.Equal(t, item.AmountMinor, d.AmountMinor)
+	assert.Equal(t, item.Currency, d.Currency)
 	assert.Equal(t, item.TagList, d.TagList)
 	assert.Equal(t, item.SenderList, d.SenderList)
 	assert.True(t, d.ID != "")
@@ -112,21 +137,23 @@ func TestSave(t *testing.T) {
 	item.ID = uuid.New().String()
 	item.AltID = d.AltID
 
-	rows := sqlmock.NewRows([]string{"id", "title", "filename", "alternativeid", "previewlink", "amount", "taglist", "senderlist", "created", "modified", "invoicenumber"}).
-		AddRow(item.ID, item.Title, item.FileName, item.AltID, item.PreviewLink, item.Amount, item.TagList, item.SenderList, d.Created, nil, item.InvoiceNumber)
+	rows := sqlmock.NewRows([]string{"id", "title", "filename", "alternativeid", "previewlink", "amountminor", "currency", "taglist", "senderlist", "created", "modified", "invoicenumber", "needsreview", "contenthash"}).
+		AddRow(item.ID, item.Title, item.FileName, item.AltID, item.PreviewLink, item.AmountMinor, item.Currency, item.TagList, item.SenderList, d.Created, nil, item.InvoiceNumber, item.NeedsReview, item.ContentHash)
 	mock.ExpectQuery(queryDocs).WillReturnRows(rows)
 	mock.ExpectExec("UPDATE DOCUMENTS").WillReturnResult(sqlmock.NewResult(1, 1))
+	expectSaveLists(mock)
 	mock.ExpectCommit()
 
 	var up DocEntity
-	if up, err = rw.Save(item, shared.Atomic{}); err != nil {
+	if up, err = rw.Save(context.TODO(), item, shared.Atomic{}); err != nil {
 		t.Errorf(errInsert, err)
 	}
 	assert.Equal(t, item.ID, up.ID)
 	assert.Equal(t, item.AltID, up.AltID)
 	assert.Equal(t, item.Title, up.Title)
 	assert.Equal(t, item.FileName, up.FileName)
-	assert.Equal(t, item.Amount, up.Amount)
+	assert.Equal(t, item.AmountMinor, up.AmountMinor)
+	assert.Equal(t, item.Currency, up.Currency)
 	assert.Equal(t, item.TagList, up.TagList)
 	assert.Equal(t, item.SenderList, up.SenderList)
 	assert.Equal(t, d.Created, up.Created)
@@ -141,16 +168,18 @@ func TestSave(t *testing.T) {
 
 	mock.ExpectQuery(queryDocs).WillReturnError(fmt.Errorf("no rows"))
 	mock.ExpectExec(stmtInsertDocs).WillReturnResult(sqlmock.NewResult(1, 1))
+	expectSaveLists(mock)
 	mock.ExpectCommit()
 
-	if up, err = rw.Save(item, shared.Atomic{}); err != nil {
+	if up, err = rw.Save(context.TODO(), item, shared.Atomic{}); err != nil {
 		t.Errorf(errInsert, err)
 	}
 
 	assert.NotEqual(t, item.AltID, up.AltID)
 	assert.Equal(t, item.Title, up.Title)
 	assert.Equal(t, item.FileName, up.FileName)
-	assert.Equal(t, item.Amount, up.Amount)
+	assert.Equal(t, item.AmountMinor, up.AmountMinor)
+	assert.Equal(t, item.Currency, up.Currency)
 	assert.Equal(t, item.TagList, up.TagList)
 	assert.Equal(t, item.SenderList, up.SenderList)
 	assert.True(t, up.Created.After(now))
@@ -159,13 +188,15 @@ func TestSave(t *testing.T) {
 	item.ID = ""
 	mock.ExpectBegin()
 	mock.ExpectExec(stmtInsertDocs).WillReturnResult(sqlmock.NewResult(1, 1))
+	expectSaveLists(mock)
 	a, _ := c.CreateAtomic()
-	if d, err = rw.Save(item, a); err != nil {
+	if d, err = rw.Save(context.TODO(), item, a); err != nil {
 		t.Errorf(errInsert, err)
 	}
 	assert.Equal(t, item.Title, d.Title)
 	assert.Equal(t, item.FileName, d.FileName)
-	assert.Equal(t, item.Amount, d.Amount)
+	assert.Equal(t, item.AmountMinor, d.AmountMinor)
+	assert.Equal(t, item.Currency, d.Currency)
 	assert.Equal(t, item.TagList, d.TagList)
 	assert.Equal(t, item.SenderList, d.SenderList)
 	assert.True(t, d.ID != "")
@@ -185,11 +216,12 @@ func TestSaveError(t *testing.T) {
 	rw := dbRepository{c}
 
 	item := DocEntity{
-		Title:      "title",
-		FileName:   "filename",
-		Amount:     10,
-		TagList:    "taglist",
-		SenderList: "senderlist",
+		Title:       "title",
+		FileName:    "filename",
+		AmountMinor: sql.NullInt64{Int64: 1000, Valid: true},
+		Currency:    sql.NullString{String: "EUR", Valid: true},
+		TagList:     "taglist",
+		SenderList:  "senderlist",
 	}
 
 	var errInsert = "error was expected while insert item"
@@ -199,7 +231,7 @@ func TestSaveError(t *testing.T) {
 	mock.ExpectExec(stmtInsertDocs).WillReturnError(fmt.Errorf("does not work"))
 	mock.ExpectRollback()
 
-	if _, err = rw.Save(item, shared.Atomic{}); err == nil {
+	if _, err = rw.Save(context.TODO(), item, shared.Atomic{}); err == nil {
 		t.Error(errInsert)
 	}
 
@@ -208,7 +240,7 @@ func TestSaveError(t *testing.T) {
 	mock.ExpectExec(stmtInsertDocs).WillReturnResult(sqlmock.NewErrorResult(fmt.Errorf("result error")))
 	mock.ExpectRollback()
 
-	if _, err = rw.Save(item, shared.Atomic{}); err == nil {
+	if _, err = rw.Save(context.TODO(), item, shared.Atomic{}); err == nil {
 		t.Error(errInsert)
 	}
 
@@ -217,7 +249,7 @@ func TestSaveError(t *testing.T) {
 	mock.ExpectExec(stmtInsertDocs).WillReturnResult(sqlmock.NewResult(0, 0))
 	mock.ExpectRollback()
 
-	if _, err = rw.Save(item, shared.Atomic{}); err == nil {
+	if _, err = rw.Save(context.TODO(), item, shared.Atomic{}); err == nil {
 		t.Error(errInsert)
 	}
 }
@@ -228,7 +260,7 @@ func TestRead(t *testing.T) {
 	defer db.Close()
 	c := shared.NewFromDB(dbx)
 	rw := dbRepository{c}
-	columns := []string{"id", "title", "filename", "alternativeid", "previewlink", "amount", "taglist", "senderlist", "created", "modified", "invoicenumber"}
+	columns := []string{"id", "title", "filename", "alternativeid", "previewlink", "amountminor", "currency", "taglist", "senderlist", "created", "modified", "invoicenumber", "needsreview", "contenthash"}
 	q := queryDocs
 	id := "id"
 
@@ -238,7 +270,8 @@ func TestRead(t *testing.T) {
 		FileName:      "filename",
 		AltID:         "altid",
 		PreviewLink:   sql.NullString{String: "previewlink", Valid: true},
-		Amount:        1.0,
+		AmountMinor:   sql.NullInt64{Int64: 150, Valid: true},
+		Currency:      sql.NullString{String: "EUR", Valid: true},
 		Created:       time.Now().UTC(),
 		Modified:      sql.NullTime{},
 		TagList:       "tags",
@@ -248,10 +281,10 @@ func TestRead(t *testing.T) {
 
 	// success
 	rows := sqlmock.NewRows(columns).
-		AddRow(expected.ID, expected.Title, expected.FileName, expected.AltID, expected.PreviewLink, expected.Amount, expected.TagList, expected.SenderList, expected.Created, expected.Modified, expected.InvoiceNumber)
+		AddRow(expected.ID, expected.Title, expected.FileName, expected.AltID, expected.PreviewLink, expected.AmountMinor, expected.Currency, expected.TagList, expected.SenderList, expected.Created, expected.Modified, expected.InvoiceNumber, expected.NeedsReview, expected.ContentHash)
 	mock.ExpectQuery(q).WithArgs(id).WillReturnRows(rows)
 
-	item, err := rw.Get(id)
+	item, err := rw.Get(context.TODO(), id, shared.Atomic{})
 	if err != nil {
 		t.Errorf("could not get item: %v", err)
 	}
@@ -261,7 +294,8 @@ func TestRead(t *testing.T) {
 	assert.Equal(t, expected.FileName, item.FileName)
 	assert.Equal(t, expected.AltID, item.AltID)
 	assert.Equal(t, expected.PreviewLink, item.PreviewLink)
-	assert.Equal(t, expected.Amount, item.Amount)
+	assert.Equal(t, expected.AmountMinor, item.AmountMinor)
+	assert.Equal(t, expected.Currency, item.Currency)
 	assert.Equal(t, expected.TagList, item.TagList)
 	assert.Equal(t, expected.SenderList, item.SenderList)
 	assert.Equal(t, expected.Created, item.Created)
@@ -272,7 +306,7 @@ func TestRead(t *testing.T) {
 	rows = sqlmock.NewRows(columns)
 	mock.ExpectQuery(q).WithArgs(id).WillReturnRows(rows)
 
-	item, err = rw.Get(id)
+	item, err = rw.Get(context.TODO(), id, shared.Atomic{})
 	if err == nil {
 		t.Errorf("should have returned an error")
 	}
@@ -297,19 +331,23 @@ func TestDelete(t *testing.T) {
 
 	mock.ExpectBegin()
 	mock.ExpectExec(stmt).WithArgs(item.ID).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE FROM DOCUMENT_REVISIONS").WithArgs(item.ID).WillReturnResult(sqlmock.NewResult(0, 0))
+	expectDeleteLists(mock)
 	mock.ExpectCommit()
 
 	// now we execute our method
-	if err = rw.Delete(item.ID, shared.Atomic{}); err != nil {
+	if err = rw.Delete(context.TODO(), item.ID, shared.Atomic{}); err != nil {
 		t.Errorf(deleteExpErr, err)
 	}
 
 	// externally supplied tx
 	mock.ExpectBegin()
 	mock.ExpectExec(stmt).WithArgs(item.ID).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE FROM DOCUMENT_REVISIONS").WithArgs(item.ID).WillReturnResult(sqlmock.NewResult(0, 0))
+	expectDeleteLists(mock)
 
 	a, _ := c.CreateAtomic()
-	if err = rw.Delete(item.ID, a); err != nil {
+	if err = rw.Delete(context.TODO(), item.ID, a); err != nil {
 		t.Errorf("error was not expected while delete item: %v", err)
 	}
 
@@ -319,6 +357,37 @@ func TestDelete(t *testing.T) {
 	}
 }
 
+func TestUpdatePreview(t *testing.T) {
+	dbx, db, mock := getDbx(t)
+	defer db.Close()
+	c := shared.NewFromDB(dbx)
+	rw := dbRepository{c}
+	stmt := "UPDATE DOCUMENTS SET previewlink"
+	link := sql.NullString{String: "previewlink", Valid: true}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(stmt).WithArgs(link, "id").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	if err := rw.UpdatePreview(context.TODO(), "id", link, shared.Atomic{}); err != nil {
+		t.Errorf("error was not expected while updating the preview: %v", err)
+	}
+
+	// unknown document
+	mock.ExpectBegin()
+	mock.ExpectExec(stmt).WithArgs(link, "!id").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	if err := rw.UpdatePreview(context.TODO(), "!id", link, shared.Atomic{}); err == nil {
+		t.Errorf("error expected for an unknown document")
+	}
+
+	// we make sure that all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf(expectations, err)
+	}
+}
+
 func TestExists(t *testing.T) {
 	var err error
 	dbx, db, mock := getDbx(t)
@@ -336,7 +405,7 @@ func TestExists(t *testing.T) {
 
 	// now we execute our method
 	var f string
-	if f, err = rw.Exists(id, shared.Atomic{}); err != nil {
+	if f, err = rw.Exists(context.TODO(), id, shared.Atomic{}); err != nil {
 		t.Errorf(existsErr, err)
 	}
 	if f != fileName {
@@ -347,7 +416,7 @@ func TestExists(t *testing.T) {
 	mock.ExpectBegin()
 	mock.ExpectQuery(q).WithArgs(id).WillReturnRows(sqlmock.NewRows(rows).AddRow(fileName))
 	a, _ := c.CreateAtomic()
-	if _, err = rw.Exists(id, a); err != nil {
+	if _, err = rw.Exists(context.TODO(), id, a); err != nil {
 		t.Errorf(existsErr, err)
 	}
 
@@ -356,7 +425,7 @@ func TestExists(t *testing.T) {
 	mock.ExpectQuery(q).WithArgs(id).WillReturnError(fmt.Errorf("error"))
 	mock.ExpectRollback()
 
-	if _, err = rw.Exists(id, shared.Atomic{}); err == nil {
+	if _, err = rw.Exists(context.TODO(), id, shared.Atomic{}); err == nil {
 		t.Error(expectedErr)
 	}
 
@@ -381,7 +450,7 @@ func TestDeleteError(t *testing.T) {
 	mock.ExpectRollback()
 
 	// now we execute our method
-	if err = rw.Delete(item.ID, shared.Atomic{}); err == nil {
+	if err = rw.Delete(context.TODO(), item.ID, shared.Atomic{}); err == nil {
 		t.Errorf("error was expected for insert item")
 	}
 
@@ -397,7 +466,7 @@ func TestSearch(t *testing.T) {
 	defer db.Close()
 	c := shared.NewFromDB(dbx)
 	rw := dbRepository{c}
-	columns := []string{"id", "title", "filename", "alternativeid", "previewlink", "amount", "taglist", "senderlist", "created", "modified", "invoicenumber"}
+	columns := []string{"id", "title", "filename", "alternativeid", "previewlink", "amountminor", "currency", "taglist", "senderlist", "created", "modified", "invoicenumber", "needsreview", "contenthash"}
 
 	qc := "SELECT count\\(id\\) FROM DOCUMENTS"
 
@@ -407,7 +476,8 @@ func TestSearch(t *testing.T) {
 		FileName:      "filename",
 		AltID:         "altid",
 		PreviewLink:   sql.NullString{String: "previewlink", Valid: true},
-		Amount:        1.0,
+		AmountMinor:   sql.NullInt64{Int64: 150, Valid: true},
+		Currency:      sql.NullString{String: "EUR", Valid: true},
 		Created:       time.Now().UTC(),
 		Modified:      sql.NullTime{},
 		TagList:       "tags",
@@ -420,7 +490,7 @@ func TestSearch(t *testing.T) {
 	mock.ExpectQuery(qc).WillReturnRows(cr)
 
 	dr := sqlmock.NewRows(columns).
-		AddRow(expected.ID, expected.Title, expected.FileName, expected.AltID, expected.PreviewLink, expected.Amount, expected.TagList, expected.SenderList, expected.Created, expected.Modified, expected.InvoiceNumber)
+		AddRow(expected.ID, expected.Title, expected.FileName, expected.AltID, expected.PreviewLink, expected.AmountMinor, expected.Currency, expected.TagList, expected.SenderList, expected.Created, expected.Modified, expected.InvoiceNumber, expected.NeedsReview, expected.ContentHash)
 	mock.ExpectQuery(queryDocs).WillReturnRows(dr)
 
 	ts := time.Now().UTC()
@@ -440,7 +510,7 @@ func TestSearch(t *testing.T) {
 		{Order: ASC, Field: "title"},
 	}
 
-	doc, err := rw.Search(search, order)
+	doc, err := rw.Search(context.TODO(), search, order)
 	if err != nil {
 		t.Errorf("could not query documents: %v", err)
 	}
@@ -455,7 +525,8 @@ func TestSearch(t *testing.T) {
 	assert.Equal(t, expected.FileName, item.FileName)
 	assert.Equal(t, expected.AltID, item.AltID)
 	assert.Equal(t, expected.PreviewLink, item.PreviewLink)
-	assert.Equal(t, expected.Amount, item.Amount)
+	assert.Equal(t, expected.AmountMinor, item.AmountMinor)
+	assert.Equal(t, expected.Currency, item.Currency)
 	assert.Equal(t, expected.TagList, item.TagList)
 	assert.Equal(t, expected.SenderList, item.SenderList)
 	assert.Equal(t, expected.Created, item.Created)
@@ -464,7 +535,7 @@ func TestSearch(t *testing.T) {
 
 	// failure1
 	mock.ExpectQuery(qc).WillReturnError(fmt.Errorf("could not get count"))
-	_, err = rw.Search(search, order)
+	_, err = rw.Search(context.TODO(), search, order)
 	if err == nil {
 		t.Error(expectedErr)
 	}
@@ -473,7 +544,7 @@ func TestSearch(t *testing.T) {
 	cr = sqlmock.NewRows([]string{"count(id)"}).AddRow(1)
 	mock.ExpectQuery(qc).WillReturnRows(cr)
 	mock.ExpectQuery(queryDocs).WillReturnError(fmt.Errorf("could not get documents"))
-	_, err = rw.Search(search, order)
+	_, err = rw.Search(context.TODO(), search, order)
 	if err == nil {
 		t.Error(expectedErr)
 	}
@@ -490,61 +561,31 @@ func TestSearchLists(t *testing.T) {
 	defer db.Close()
 	c := shared.NewFromDB(dbx)
 	rw := dbRepository{c}
-	q := "SELECT distinct\\(taglist\\) as search FROM DOCUMENTS"
-	columns := []string{"search"}
+	q := "SELECT name FROM TAGS"
+	columns := []string{"name"}
 	searchErr := "error searching: %v"
 
-	// multiple
-	mock.ExpectQuery(q).WillReturnRows(sqlmock.NewRows(columns).AddRow("tag1").AddRow("tag2").AddRow("tag1;tag3"))
-	tags, err := rw.SearchLists("tag", TAGS)
-	if err != nil {
-		t.Errorf(searchErr, err)
-	}
-
-	if len(tags) != 3 {
-		t.Errorf("3 entries expected, got %d", len(tags))
-	}
-
-	if tags[0] != "tag1" || tags[1] != "tag2" || tags[2] != "tag3" {
-		t.Errorf("wrong tags returned from search")
-	}
-
-	// single
-	mock.ExpectQuery(q).WillReturnRows(sqlmock.NewRows(columns).AddRow("tag1").AddRow("tag2").AddRow("tag1;tag3"))
-	tags, err = rw.SearchLists("tag2", TAGS)
+	// the search term is a case insensitive prefix
+	mock.ExpectQuery(q).WithArgs("tag%").WillReturnRows(sqlmock.NewRows(columns).AddRow("tag1").AddRow("tag2").AddRow("tag3"))
+	tags, err := rw.SearchLists(context.TODO(), "TAG", TAGS)
 	if err != nil {
 		t.Errorf(searchErr, err)
 	}
+	assert.Equal(t, []string{"tag1", "tag2", "tag3"}, tags)
 
-	if len(tags) != 1 {
-		t.Errorf("1 entries expected, got %d", len(tags))
-	}
-
-	if tags[0] != "tag2" {
-		t.Errorf("wrong tags returned from search")
-	}
-
-	// error1
+	// error
 	mock.ExpectQuery(q).WillReturnError(Err)
-	_, err = rw.SearchLists("tag2", TAGS)
+	_, err = rw.SearchLists(context.TODO(), "tag2", TAGS)
 	if err == nil {
 		t.Error(expectedErr)
 	}
 
-	// multiple
-	mock.ExpectQuery("SELECT distinct\\(senderlist\\) as search FROM DOCUMENTS").WillReturnRows(sqlmock.NewRows(columns).AddRow("sender1").AddRow("sender2").AddRow("sender1;sender3"))
-	senders, err := rw.SearchLists("sender", SENDERS)
+	mock.ExpectQuery("SELECT name FROM SENDERS").WithArgs("sender%").WillReturnRows(sqlmock.NewRows(columns).AddRow("sender1").AddRow("sender3"))
+	senders, err := rw.SearchLists(context.TODO(), "sender", SENDERS)
 	if err != nil {
 		t.Errorf(searchErr, err)
 	}
-
-	if len(senders) != 3 {
-		t.Errorf("3 entries expected, got %d", len(senders))
-	}
-
-	if senders[0] != "sender1" || senders[1] != "sender2" || senders[2] != "sender3" {
-		t.Errorf("wrong senders returned from search")
-	}
+	assert.Equal(t, []string{"sender1", "sender3"}, senders)
 
 	// we make sure that all expectations were met
 	if err := mock.ExpectationsWereMet(); err != nil {
@@ -564,7 +605,7 @@ func TestSearchIssueUmlaute(t *testing.T) {
 		t.Fatalf("could not create new repository; %v", err)
 	}
 
-	doc, err := repo.Save(DocEntity{
+	doc, err := repo.Save(context.TODO(), DocEntity{
 		Title:         "TEST_öÄÜ",
 		SenderList:    "SenÖÄüder",
 		TagList:       "Tag_öäÜ",
@@ -579,7 +620,7 @@ func TestSearchIssueUmlaute(t *testing.T) {
 	}
 
 	// simple search, return all
-	result, err := repo.Search(DocSearch{}, make([]OrderBy, 0))
+	result, err := repo.Search(context.TODO(), DocSearch{}, make([]OrderBy, 0))
 	if err != nil {
 		t.Errorf("error searching for documents; %v", err)
 	}
@@ -588,7 +629,7 @@ func TestSearchIssueUmlaute(t *testing.T) {
 	}
 
 	// search for part of the Title
-	result, err = repo.Search(DocSearch{
+	result, err = repo.Search(context.TODO(), DocSearch{
 		Title: "TEST",
 	}, make([]OrderBy, 0))
 	if err != nil {
@@ -599,7 +640,7 @@ func TestSearchIssueUmlaute(t *testing.T) {
 	}
 
 	// search for Umlaute (ö,ä,ü)
-	result, err = repo.Search(DocSearch{
+	result, err = repo.Search(context.TODO(), DocSearch{
 		Title: "öäü",
 	}, make([]OrderBy, 0))
 	if err != nil {
@@ -610,7 +651,7 @@ func TestSearchIssueUmlaute(t *testing.T) {
 	}
 
 	// search for Umlaute (ö,ä,ü) in invoice
-	result, err = repo.Search(DocSearch{
+	result, err = repo.Search(context.TODO(), DocSearch{
 		Title: "äää",
 	}, make([]OrderBy, 0))
 	if err != nil {
@@ -621,7 +662,7 @@ func TestSearchIssueUmlaute(t *testing.T) {
 	}
 
 	// search for Umlaute (ö,ä,ü) in Tags
-	result, err = repo.Search(DocSearch{
+	result, err = repo.Search(context.TODO(), DocSearch{
 		Tag: "ü",
 	}, make([]OrderBy, 0))
 	if err != nil {
@@ -632,7 +673,7 @@ func TestSearchIssueUmlaute(t *testing.T) {
 	}
 
 	// search for Umlaute (ö,ä,ü) in Senders
-	result, err = repo.Search(DocSearch{
+	result, err = repo.Search(context.TODO(), DocSearch{
 		Sender: "Ö",
 	}, make([]OrderBy, 0))
 	if err != nil {
@@ -652,7 +693,7 @@ func TestSearchListIssueUmlaute(t *testing.T) {
 		t.Fatalf("could not create new repository; %v", err)
 	}
 
-	doc, err := repo.Save(DocEntity{
+	doc, err := repo.Save(context.TODO(), DocEntity{
 		Title:      "Any_Document",
 		SenderList: "ÖÄÜ_Sender_Umlaute",
 		TagList:    "öäü_Tag_Umlaute",
@@ -666,7 +707,7 @@ func TestSearchListIssueUmlaute(t *testing.T) {
 	}
 
 	// search for senders with the std string "Sender"
-	senders, err := repo.SearchLists("ÖÄÜ", SENDERS)
+	senders, err := repo.SearchLists(context.TODO(), "ÖÄÜ", SENDERS)
 	if err != nil {
 		t.Errorf("could not get senders; %v", err)
 	}
@@ -675,7 +716,7 @@ func TestSearchListIssueUmlaute(t *testing.T) {
 	}
 
 	// search for senders with the std string "Sender"
-	tags, err := repo.SearchLists("öäü", TAGS)
+	tags, err := repo.SearchLists(context.TODO(), "öäü", TAGS)
 	if err != nil {
 		t.Errorf("could not get tags; %v", err)
 	}
@@ -683,3 +724,298 @@ func TestSearchListIssueUmlaute(t *testing.T) {
 		t.Errorf("expected to find a tags for 'öäü', got %d", len(tags))
 	}
 }
+
+const legacySchema = `CREATE TABLE "DOCUMENTS" (
+	"id"	varchar(36) NOT NULL,
+	"title"	varchar(255) NOT NULL,
+	"filename"	varchar(255) NOT NULL,
+	"alternativeid"	varchar(128),
+	"previewlink"	varchar(128),
+	"amount"	decimal(10 , 0),
+	"created"	date NOT NULL,
+	"modified"	date,
+	"taglist"	text,
+	"senderlist"	text,
+	"invoicenumber"	varchar(128),
+	PRIMARY KEY("id")
+);`
+
+func TestMigrateSchema(t *testing.T) {
+	con := shared.NewConnForSqlite(":memory:")
+	con.DB.MustExec(legacySchema)
+	con.DB.MustExec(`INSERT INTO DOCUMENTS (id,title,filename,alternativeid,amount,created,taglist,senderlist) VALUES
+		('1','a','a.pdf','A',12.3,'2024-01-01','tag;Tax','sender'),
+		('2','b','b.pdf','B',0,'2024-01-02','tax;tag','sender'),
+		('3','c','c.pdf','C',NULL,'2024-01-03','','sender'),
+		('4','d','d.pdf','D',100,'2024-01-04',NULL,NULL)`)
+
+	assert.NoError(t, MigrateSchema(context.TODO(), con))
+	// a second run does not change anything
+	assert.NoError(t, MigrateSchema(context.TODO(), con))
+
+	repo, err := NewRepository(con)
+	if err != nil {
+		t.Fatalf("could not create new repository; %v", err)
+	}
+	for id, expected := range map[string]sql.NullInt64{
+		"1": {Int64: 1230, Valid: true},
+		"2": {},
+		"3": {},
+		"4": {Int64: 10000, Valid: true},
+	} {
+		doc, err := repo.Get(context.TODO(), id, shared.Atomic{})
+		assert.NoError(t, err)
+		assert.Equal(t, expected, doc.AmountMinor, id)
+		assert.Equal(t, expected.Valid, doc.Currency.Valid, id)
+		// existing documents do not need a review
+		assert.False(t, doc.NeedsReview, id)
+		// the hashes are computed separately
+		assert.False(t, doc.ContentHash.Valid, id)
+	}
+
+	// the lists are linked in the order of the documents, names differing in case are merged
+	doc, err := repo.Get(context.TODO(), "2", shared.Atomic{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Tax;tag", doc.TagList)
+	assert.Equal(t, "sender", doc.SenderList)
+	doc, err = repo.Get(context.TODO(), "4", shared.Atomic{})
+	assert.NoError(t, err)
+	assert.Equal(t, "", doc.TagList)
+	tags, err := repo.ListItems(context.TODO(), TAGS)
+	assert.NoError(t, err)
+	assert.Len(t, tags, 2)
+	var columns []string
+	assert.NoError(t, con.SelectContext(context.TODO(), &columns, "SELECT name FROM pragma_table_info('DOCUMENTS')"))
+	assert.NotContains(t, columns, "taglist")
+	assert.NotContains(t, columns, "senderlist")
+
+	// the schema of new databases and an empty database are not changed
+	con = shared.NewConnForSqlite(":memory:")
+	assert.NoError(t, MigrateSchema(context.TODO(), con))
+	con.DB.MustExec(mydmsSchema)
+	assert.NoError(t, MigrateSchema(context.TODO(), con))
+}
+
+func TestSearchAmounts(t *testing.T) {
+	con := shared.NewConnForSqlite(":memory:")
+	con.DB.MustExec(mydmsSchema)
+	repo, err := NewRepository(con)
+	if err != nil {
+		t.Fatalf("could not create new repository; %v", err)
+	}
+
+	for i, sender := range []string{"office", "shop", "office"} {
+		doc := DocEntity{Title: "doc", FileName: "doc.pdf", SenderList: sender, TagList: "tag"}
+		if i > 0 {
+			doc.AmountMinor = sql.NullInt64{Int64: int64(i * 100), Valid: true}
+			doc.Currency = sql.NullString{String: "EUR", Valid: true}
+		}
+		if _, err := repo.Save(context.TODO(), doc, shared.Atomic{}); err != nil {
+			t.Fatalf("could not save document; %v", err)
+		}
+	}
+
+	// documents without an amount are skipped
+	amounts, err := repo.SearchAmounts(context.TODO(), DocSearch{})
+	assert.NoError(t, err)
+	assert.Len(t, amounts, 2)
+
+	amounts, err = repo.SearchAmounts(context.TODO(), DocSearch{Sender: "office"})
+	assert.NoError(t, err)
+	assert.Len(t, amounts, 1)
+	assert.Equal(t, int64(200), amounts[0].AmountMinor)
+	assert.Equal(t, "EUR", amounts[0].Currency)
+}
+
+func TestSearchFilters(t *testing.T) {
+	con := shared.NewConnForSqlite(":memory:")
+	con.DB.MustExec(mydmsSchema)
+	repo, err := NewRepository(con)
+	if err != nil {
+		t.Fatalf("could not create new repository; %v", err)
+	}
+
+	for i, d := range []struct {
+		title, tags, senders, invoice, created string
+		amount                                 int64
+	}{
+		{"phone bill", "invoice;Deutsche Telekom", "Telekom", "4711", "2024-01-15", 4999},
+		{"power bill", "invoice", "Power Corp", "", "2024-03-31", 12000},
+		{"letter", "private", "office", "", "2023-12-31", 0},
+	} {
+		doc := DocEntity{Title: d.title, FileName: "doc.pdf", TagList: d.tags, SenderList: d.senders}
+		if d.invoice != "" {
+			doc.InvoiceNumber = sql.NullString{String: d.invoice, Valid: true}
+		}
+		if d.amount > 0 {
+			doc.AmountMinor = sql.NullInt64{Int64: d.amount, Valid: true}
+			doc.Currency = sql.NullString{String: "EUR", Valid: true}
+		}
+		doc, err := repo.Save(context.TODO(), doc, shared.Atomic{})
+		if err != nil {
+			t.Fatalf("could not save document %d; %v", i, err)
+		}
+		created, _ := time.Parse("2006-01-02", d.created)
+		con.DB.MustExec("UPDATE DOCUMENTS SET created = ? WHERE id = ?", created.Add(12*time.Hour), doc.ID)
+	}
+
+	for query, titles := range map[string][]string{
+		"bill":                      {"power bill", "phone bill"},
+		"telekom":                   {"phone bill"},
+		`"power corp"`:              {"power bill"},
+		"4711":                      {"phone bill"},
+		"tag:invoice -sender:power": {"phone bill"},
+		"sender:office":             {"letter"},
+		"invoice:47":                {"phone bill"},
+		"title:bill amount:>49.99":  {"power bill"},
+		"amount:..50eur":            {"phone bill"},
+		"amount:10usd..":            {},
+		"-amount:>100":              {"phone bill", "letter"},
+		"created:2024-01..2024-03":  {"power bill", "phone bill"},
+		"created:<2024":             {"letter"},
+		"-created:2024-03":          {"phone bill", "letter"},
+		"-bill -tag:private":        {},
+	} {
+		filters, errs := ParseQuery(query)
+		assert.Len(t, errs, 0, query)
+		result, err := repo.Search(context.TODO(), DocSearch{Filters: filters}, []OrderBy{{Field: "created", Order: DESC}})
+		assert.NoError(t, err, query)
+		found := []string{}
+		for _, d := range result.Documents {
+			found = append(found, d.Title)
+		}
+		assert.Equal(t, titles, found, query)
+	}
+}
+
+func TestSearchNeedsReview(t *testing.T) {
+	con := sqlitetest.NewConn(t)
+	repo, err := NewRepository(con)
+	if err != nil {
+		t.Fatalf("could not create new repository; %v", err)
+	}
+
+	var draft DocEntity
+	for _, needsReview := range []bool{false, true} {
+		doc, err := repo.Save(context.TODO(), DocEntity{Title: "doc", FileName: "doc.pdf", NeedsReview: needsReview}, shared.Atomic{})
+		if err != nil {
+			t.Fatalf("could not save document; %v", err)
+		}
+		draft = doc
+	}
+
+	result, err := repo.Search(context.TODO(), DocSearch{NeedsReview: true}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Count)
+	assert.Equal(t, draft.ID, result.Documents[0].ID)
+	assert.True(t, result.Documents[0].NeedsReview)
+
+	// the review is completed by saving the document
+	draft.NeedsReview = false
+	_, err = repo.Save(context.TODO(), draft, shared.Atomic{})
+	assert.NoError(t, err)
+	result, err = repo.Search(context.TODO(), DocSearch{NeedsReview: true}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Count)
+
+	result, err = repo.Search(context.TODO(), DocSearch{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Count)
+}
+
+func TestSearchDuplicates(t *testing.T) {
+	con := shared.NewConnForSqlite(":memory:")
+	con.DB.MustExec(mydmsSchema)
+	repo, err := NewRepository(con)
+	if err != nil {
+		t.Fatalf("could not create new repository; %v", err)
+	}
+
+	var ids []string
+	for _, hash := range []string{"aaa", "bbb", "aaa", ""} {
+		doc, err := repo.Save(context.TODO(), DocEntity{
+			Title:       "doc",
+			FileName:    "doc.pdf",
+			ContentHash: sql.NullString{String: hash, Valid: hash != ""},
+		}, shared.Atomic{})
+		if err != nil {
+			t.Fatalf("could not save document; %v", err)
+		}
+		ids = append(ids, doc.ID)
+	}
+
+	docs, err := repo.SearchContentHash(context.TODO(), "aaa")
+	assert.NoError(t, err)
+	assert.Len(t, docs, 2)
+
+	docs, err = repo.SearchDuplicates(context.TODO())
+	assert.NoError(t, err)
+	assert.Len(t, docs, 2)
+	for _, d := range docs {
+		assert.Equal(t, "aaa", d.ContentHash.String)
+	}
+
+	// the hash of the document without a hash is computed later
+	assert.NoError(t, repo.UpdateContentHash(context.TODO(), ids[3], sql.NullString{String: "bbb", Valid: true}, shared.Atomic{}))
+	docs, err = repo.SearchDuplicates(context.TODO())
+	assert.NoError(t, err)
+	assert.Len(t, docs, 4)
+	assert.Equal(t, "aaa", docs[0].ContentHash.String)
+	assert.Equal(t, "bbb", docs[3].ContentHash.String)
+
+	assert.Error(t, repo.UpdateContentHash(context.TODO(), "unknown", sql.NullString{String: "bbb", Valid: true}, shared.Atomic{}))
+}
+
+func TestListItems(t *testing.T) {
+	con := shared.NewConnForSqlite(":memory:")
+	con.DB.MustExec(mydmsSchema)
+	repo, err := NewRepository(con)
+	if err != nil {
+		t.Fatalf("could not create new repository; %v", err)
+	}
+
+	var ids []string
+	for _, list := range []string{"Telekom;phone", "Deutsche Telekom", "phone;telekom"} {
+		doc, err := repo.Save(context.TODO(), DocEntity{Title: "doc", FileName: "doc.pdf", TagList: list, SenderList: "office"}, shared.Atomic{})
+		if err != nil {
+			t.Fatalf("could not save document; %v", err)
+		}
+		ids = append(ids, doc.ID)
+	}
+
+	items, err := repo.ListItems(context.TODO(), TAGS)
+	assert.NoError(t, err)
+	assert.Len(t, items, 3)
+	assert.Equal(t, "Deutsche Telekom", items[0].Name)
+	assert.Equal(t, 1, items[0].Documents)
+	// the case of the names is ignored
+	assert.Equal(t, "phone", items[1].Name)
+	assert.Equal(t, "Telekom", items[2].Name)
+	assert.Equal(t, 2, items[2].Documents)
+
+	// the merged documents keep a single link
+	assert.NoError(t, repo.MergeListItems(context.TODO(), TAGS, items[0].ID, []string{items[0].ID, items[2].ID}, shared.Atomic{}))
+	doc, err := repo.Get(context.TODO(), ids[2], shared.Atomic{})
+	assert.NoError(t, err)
+	assert.Equal(t, "phone;Deutsche Telekom", doc.TagList)
+
+	assert.NoError(t, repo.RenameListItem(context.TODO(), TAGS, items[0].ID, "Telekom AG", shared.Atomic{}))
+	result, err := repo.Search(context.TODO(), DocSearch{Tag: "telekom ag"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, result.Count)
+	assert.Error(t, repo.RenameListItem(context.TODO(), TAGS, items[1].ID, "telekom ag", shared.Atomic{}))
+
+	assert.NoError(t, repo.DeleteListItem(context.TODO(), TAGS, items[1].ID, shared.Atomic{}))
+	assert.Error(t, repo.DeleteListItem(context.TODO(), TAGS, items[1].ID, shared.Atomic{}))
+	doc, err = repo.Get(context.TODO(), ids[0], shared.Atomic{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Telekom AG", doc.TagList)
+
+	// unused names are removed with the last document
+	assert.NoError(t, repo.Delete(context.TODO(), ids[0], shared.Atomic{}))
+	assert.NoError(t, repo.Delete(context.TODO(), ids[1], shared.Atomic{}))
+	assert.NoError(t, repo.Delete(context.TODO(), ids[2], shared.Atomic{}))
+	items, err = repo.ListItems(context.TODO(), SENDERS)
+	assert.NoError(t, err)
+	assert.Len(t, items, 0)
+}
@@ -0,0 +1,445 @@
+package document
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.binggl.net/monorepo/internal/mydms/app/filestore"
+	"golang.binggl.net/monorepo/internal/mydms/app/pages"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+	"golang.binggl.net/monorepo/pkg/logging"
+)
+
+// OriginalRevision is the operation of the first revision, the file of the document before its pages were changed
+const OriginalRevision = "original"
+
+// Revision is a stored file of a document, the last revision is the current file of the document
+type Revision struct {
+	Revision    int    `json:"revision"`
+	FileName    string `json:"fileName"`
+	ContentHash string `json:"contentHash,omitempty"`
+	// Operation describes the change which created the revision
+	Operation string `json:"operation"`
+	Created   string `json:"created"`
+}
+
+// DocumentPages describes the pages and the stored revisions of a document
+type DocumentPages struct {
+	Document  Document
+	Pages     int
+	Revisions []Revision
+}
+
+// PageEdit changes the pages of a document. The pages are specified as ranges like '1-3, 5',
+// the numbers refer to the pages before the change.
+type PageEdit struct {
+	// Rotate are the pages turned clockwise by Degrees
+	Rotate  string
+	Degrees int
+	// Remove are the pages deleted from the document
+	Remove string
+}
+
+// Pages returns the number of pages and the revisions of the document. Documents whose pages
+// were never changed have the stored file as the only revision.
+func (s documentService) Pages(ctx context.Context, id string) (p DocumentPages, err error) {
+	doc, err := s.entity(ctx, id)
+	if err != nil {
+		return p, err
+	}
+	payload, err := s.readPDF(ctx, doc)
+	if err != nil {
+		return p, err
+	}
+	if p.Pages, err = pages.Count(payload); err != nil {
+		return p, shared.ErrValidation(err.Error())
+	}
+	revs, err := s.repo.Revisions(ctx, id)
+	if err != nil {
+		s.logger.Error("Pages: repository error", logging.ErrV(err))
+		return p, fmt.Errorf("cannot get the revisions of the document; %v", err)
+	}
+	if len(revs) == 0 {
+		revs = []RevisionEntity{originalRevision(doc)}
+	}
+	for _, r := range revs {
+		p.Revisions = append(p.Revisions, Revision{
+			Revision:    r.Revision,
+			FileName:    r.FileName,
+			ContentHash: r.ContentHash.String,
+			Operation:   r.Operation,
+			Created:     r.Created.Format(jsonTimeLayout),
+		})
+	}
+	p.Document = s.convertToDomain(doc)
+	return p, nil
+}
+
+// EditPages rotates and removes pages of the document, the changed file is stored as a new revision
+func (s documentService) EditPages(ctx context.Context, id string, edit PageEdit) (d Document, err error) {
+	doc, err := s.entity(ctx, id)
+	if err != nil {
+		return d, err
+	}
+	payload, err := s.readPDF(ctx, doc)
+	if err != nil {
+		return d, err
+	}
+	count, err := pages.Count(payload)
+	if err != nil {
+		return d, shared.ErrValidation(err.Error())
+	}
+
+	var operations []string
+	if strings.TrimSpace(edit.Rotate) != "" {
+		ranges, err := pages.ParseRanges(edit.Rotate, count)
+		if err != nil {
+			return d, shared.ErrValidation(err.Error())
+		}
+		if payload, err = pages.Rotate(payload, ranges, edit.Degrees); err != nil {
+			return d, shared.ErrValidation(err.Error())
+		}
+		operations = append(operations, fmt.Sprintf("rotated pages %s by %d°", rangeList(ranges), edit.Degrees))
+	}
+	if strings.TrimSpace(edit.Remove) != "" {
+		ranges, err := pages.ParseRanges(edit.Remove, count)
+		if err != nil {
+			return d, shared.ErrValidation(err.Error())
+		}
+		if payload, err = pages.Remove(payload, ranges); err != nil {
+			return d, shared.ErrValidation(err.Error())
+		}
+		operations = append(operations, fmt.Sprintf("removed pages %s", rangeList(ranges)))
+	}
+	if len(operations) == 0 {
+		return d, shared.ErrValidation("no pages are specified to rotate or remove")
+	}
+
+	if doc, err = s.saveRevision(ctx, doc, payload, strings.Join(operations, ", ")); err != nil {
+		return d, err
+	}
+	return s.convertToDomain(doc), nil
+}
+
+// AppendUpload adds the pages of the uploaded PDF to the end of the document as a new revision
+func (s documentService) AppendUpload(ctx context.Context, id, uploadToken string) (d Document, err error) {
+	doc, err := s.entity(ctx, id)
+	if err != nil {
+		return d, err
+	}
+	u, err := s.uploadSvc.Read(uploadToken)
+	if err != nil {
+		return d, shared.ErrNotFound(fmt.Sprintf("could not read the upload '%s'", uploadToken))
+	}
+	if !pages.IsPDF(u.Payload) {
+		return d, shared.ErrValidation(fmt.Sprintf("the upload '%s' is not a PDF", u.FileName))
+	}
+	payload, err := s.readPDF(ctx, doc)
+	if err != nil {
+		return d, err
+	}
+	if payload, err = pages.Merge(payload, u.Payload); err != nil {
+		return d, shared.ErrValidation(err.Error())
+	}
+
+	if doc, err = s.saveRevision(ctx, doc, payload, fmt.Sprintf("appended %s", u.FileName)); err != nil {
+		return d, err
+	}
+	if err := s.uploadSvc.Delete(uploadToken); err != nil {
+		s.logger.Warn("AppendUpload: unable to delete uploaded file", logging.ErrV(err))
+	}
+	return s.convertToDomain(doc), nil
+}
+
+// SplitDocument creates a new document for each of the page ranges. The new documents have the
+// tags and senders of the document, the document itself is kept.
+func (s documentService) SplitDocument(ctx context.Context, id, ranges string) (d []Document, err error) {
+	doc, err := s.entity(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := s.readPDF(ctx, doc)
+	if err != nil {
+		return nil, err
+	}
+	count, err := pages.Count(payload)
+	if err != nil {
+		return nil, shared.ErrValidation(err.Error())
+	}
+	parts, err := pages.ParseRanges(ranges, count)
+	if err != nil {
+		return nil, shared.ErrValidation(err.Error())
+	}
+
+	var (
+		entries []DocEntity
+		files   []string
+	)
+	for _, r := range parts {
+		part, err := pages.Extract(payload, r)
+		if err != nil {
+			s.removeFiles(ctx, files)
+			return nil, shared.ErrValidation(err.Error())
+		}
+		filePath, err := s.storeFile(ctx, derivedFileName(doc.FileName, "p"+r.String()), part)
+		if err != nil {
+			s.removeFiles(ctx, files)
+			return nil, err
+		}
+		files = append(files, filePath)
+		entries = append(entries, DocEntity{
+			Title:         fmt.Sprintf("%s (pages %s)", doc.Title, r),
+			FileName:      filePath,
+			PreviewLink:   s.savePreview(ctx, filePath, part),
+			TagList:       doc.TagList,
+			SenderList:    doc.SenderList,
+			InvoiceNumber: doc.InvoiceNumber,
+			NeedsReview:   doc.NeedsReview,
+			ContentHash:   hashColumn(part),
+		})
+	}
+
+	if entries, err = s.saveEntries(ctx, entries); err != nil {
+		s.removeFiles(ctx, files)
+		return nil, err
+	}
+	return s.convertList(entries), nil
+}
+
+// MergeDocuments combines the files of the documents in the given order into a new document.
+// The metadata is merged like the metadata of duplicates, the documents themselves are kept.
+// Without a title the title of the first document is used.
+func (s documentService) MergeDocuments(ctx context.Context, ids []string, title string) (d Document, err error) {
+	var (
+		docs     []DocEntity
+		payloads [][]byte
+	)
+	for _, id := range ids {
+		if slices.ContainsFunc(docs, func(e DocEntity) bool { return e.ID == id }) {
+			continue
+		}
+		doc, err := s.entity(ctx, id)
+		if err != nil {
+			return d, err
+		}
+		payload, err := s.readPDF(ctx, doc)
+		if err != nil {
+			return d, err
+		}
+		docs = append(docs, doc)
+		payloads = append(payloads, payload)
+	}
+	if len(docs) < 2 {
+		return d, shared.ErrValidation("at least two documents are needed for a merge")
+	}
+	payload, err := pages.Merge(payloads...)
+	if err != nil {
+		return d, shared.ErrValidation(err.Error())
+	}
+
+	merged := docs[0]
+	for _, doc := range docs[1:] {
+		mergeEntity(&merged, doc)
+	}
+	merged.ID = ""
+	if title = s.policy.Sanitize(strings.TrimSpace(title)); title != "" {
+		merged.Title = title
+	}
+	if merged.FileName, err = s.storeFile(ctx, derivedFileName(docs[0].FileName, "merged"), payload); err != nil {
+		return d, err
+	}
+	merged.PreviewLink = s.savePreview(ctx, merged.FileName, payload)
+	merged.ContentHash = hashColumn(payload)
+
+	entries, err := s.saveEntries(ctx, []DocEntity{merged})
+	if err != nil {
+		s.removeFiles(ctx, []string{merged.FileName})
+		return d, err
+	}
+	return s.convertToDomain(entries[0]), nil
+}
+
+// --------------------------------------------------------------------------
+// internal helpers
+// --------------------------------------------------------------------------
+
+// derivedSuffix matches the suffix added by derivedFileName, the suffix is replaced for files
+// derived from derived files
+var derivedSuffix = regexp.MustCompile(`(_(rev\d+|p\d+(-\d+)?|merged)_[0-9a-f]{8})+$`)
+
+// derivedFileName names a file created from the given file. A random part prevents that files
+// derived on the same day overwrite each other.
+func derivedFileName(filePath, suffix string) string {
+	base := path.Base(filePath)
+	ext := path.Ext(base)
+	name := derivedSuffix.ReplaceAllString(strings.TrimSuffix(base, ext), "")
+	if ext == "" {
+		ext = ".pdf"
+	}
+	return fmt.Sprintf("%s_%s_%s%s", name, suffix, uuid.New().String()[:8], ext)
+}
+
+// entity returns the document or a not-found error
+func (s documentService) entity(ctx context.Context, id string) (DocEntity, error) {
+	doc, err := s.repo.Get(ctx, id, shared.Atomic{})
+	if err != nil {
+		return doc, shared.ErrNotFound(fmt.Sprintf("could not find document by id: %s", id))
+	}
+	return doc, nil
+}
+
+// readPDF returns the stored file of the document, only the pages of PDF files can be changed
+func (s documentService) readPDF(ctx context.Context, doc DocEntity) ([]byte, error) {
+	item, err := s.fileSvc.GetFile(ctx, doc.FileName)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("cannot get the file of document '%s'", doc.ID), logging.ErrV(err))
+		return nil, fmt.Errorf("could not get the file of document '%s'; %v", doc.ID, err)
+	}
+	if !pages.IsPDF(item.Payload) {
+		return nil, shared.ErrValidation(fmt.Sprintf("the file of document '%s' is not a PDF", doc.Title))
+	}
+	return item.Payload, nil
+}
+
+// storeFile saves the payload in the folder of the current day and returns the path of the file
+func (s documentService) storeFile(ctx context.Context, fileName string, payload []byte) (string, error) {
+	folder := time.Now().UTC().Format("2006_01_02")
+	item := filestore.FileItem{
+		FileName:   fileName,
+		FolderName: folder,
+		MimeType:   "application/pdf",
+		Payload:    payload,
+	}
+	if err := s.fileSvc.SaveFile(ctx, item); err != nil {
+		s.logger.Error("unable to save file", logging.ErrV(fmt.Errorf("could not save file '%s', %v", fileName, err)))
+		return "", fmt.Errorf("error while saving file: %v", err)
+	}
+	return fmt.Sprintf("/%s/%s", folder, fileName), nil
+}
+
+// saveRevision stores the payload as the new file of the document. The former file is kept as a
+// revision, the file of a document without revisions is recorded as the original revision.
+func (s documentService) saveRevision(ctx context.Context, doc DocEntity, payload []byte, operation string) (d DocEntity, err error) {
+	revs, err := s.repo.Revisions(ctx, doc.ID)
+	if err != nil {
+		return d, fmt.Errorf("cannot get the revisions of the document; %v", err)
+	}
+	if len(revs) == 0 {
+		revs = []RevisionEntity{originalRevision(doc)}
+	}
+	next := revs[len(revs)-1].Revision + 1
+
+	filePath, err := s.storeFile(ctx, derivedFileName(doc.FileName, fmt.Sprintf("rev%d", next)), payload)
+	if err != nil {
+		return d, err
+	}
+
+	atomic, err := s.repo.CreateAtomic()
+	if err != nil {
+		return d, err
+	}
+	defer func() {
+		err = shared.HandleTX(true, &atomic, err)
+		if err != nil {
+			s.removeFiles(ctx, []string{filePath})
+		}
+	}()
+
+	if revs[0].ID == "" {
+		if _, err = s.repo.SaveRevision(ctx, revs[0], atomic); err != nil {
+			return d, err
+		}
+	}
+	doc.FileName = filePath
+	doc.PreviewLink = s.savePreview(ctx, filePath, payload)
+	doc.ContentHash = hashColumn(payload)
+	if d, err = s.repo.Save(ctx, doc, atomic); err != nil {
+		return d, fmt.Errorf("error while saving document: %v", err)
+	}
+	_, err = s.repo.SaveRevision(ctx, RevisionEntity{
+		DocumentID:  doc.ID,
+		Revision:    next,
+		FileName:    filePath,
+		ContentHash: doc.ContentHash,
+		Operation:   operation,
+	}, atomic)
+	return d, err
+}
+
+// saveEntries creates the documents within one transaction
+func (s documentService) saveEntries(ctx context.Context, entries []DocEntity) (saved []DocEntity, err error) {
+	atomic, err := s.repo.CreateAtomic()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = shared.HandleTX(true, &atomic, err)
+	}()
+
+	for _, e := range entries {
+		if e, err = s.repo.Save(ctx, e, atomic); err != nil {
+			return nil, fmt.Errorf("error while saving document: %v", err)
+		}
+		saved = append(saved, e)
+	}
+	return saved, nil
+}
+
+// revisionFiles returns the files of the former revisions of the document, the current file is excluded
+func (s documentService) revisionFiles(ctx context.Context, id, current string) ([]string, error) {
+	revs, err := s.repo.Revisions(ctx, id)
+	if err != nil {
+		s.logger.Error("cannot get the revisions of the document", logging.ErrV(err))
+		return nil, fmt.Errorf("cannot get the revisions of document '%s'; %v", id, err)
+	}
+	var files []string
+	for _, r := range revs {
+		if r.FileName != current && !slices.Contains(files, r.FileName) {
+			files = append(files, r.FileName)
+		}
+	}
+	return files, nil
+}
+
+// removeFiles deletes the files and their previews, a file which cannot be deleted is only orphaned
+func (s documentService) removeFiles(ctx context.Context, files []string) {
+	for _, f := range files {
+		if err := s.fileSvc.DeleteFile(ctx, f); err != nil {
+			s.logger.Warn(fmt.Sprintf("could not delete the file '%s'", f), logging.ErrV(err))
+		}
+		// the file might not have a preview, the error is ignored
+		_ = s.fileSvc.DeleteFile(ctx, f+previewSuffix)
+	}
+}
+
+// originalRevision describes the file of a document whose pages were never changed
+func originalRevision(doc DocEntity) RevisionEntity {
+	return RevisionEntity{
+		DocumentID:  doc.ID,
+		Revision:    1,
+		FileName:    doc.FileName,
+		ContentHash: doc.ContentHash,
+		Operation:   OriginalRevision,
+		Created:     doc.Created,
+	}
+}
+
+func rangeList(ranges []pages.Range) string {
+	list := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		list = append(list, r.String())
+	}
+	return strings.Join(list, ", ")
+}
+
+// hashColumn is the content hash of the payload as database column
+func hashColumn(payload []byte) sql.NullString {
+	return sql.NullString{String: ContentHash(payload), Valid: true}
+}
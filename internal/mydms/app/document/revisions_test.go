@@ -0,0 +1,134 @@
+package document_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.binggl.net/monorepo/internal/common/upload"
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/internal/mydms/app/pages"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+)
+
+// lastSaved returns the payload of the file stored last
+func lastSaved(t *testing.T, fileSvc *mockFileService) []byte {
+	if len(fileSvc.saved) == 0 {
+		t.Fatalf("no file was saved")
+	}
+	return fileSvc.saved[len(fileSvc.saved)-1].Payload
+}
+
+func pageCount(t *testing.T, payload []byte) int {
+	n, err := pages.Count(payload)
+	if err != nil {
+		t.Fatalf("could not count the pages; %v", err)
+	}
+	return n
+}
+
+func Test_Revisions(t *testing.T) {
+	repo := sqliteRepo(t)
+	fileSvc := newFileService()
+	svc := document.NewService(logger, repo, fileSvc, uploadSvc, nil, nil)
+	pdf, err := os.ReadFile(unencryptedPDF)
+	if err != nil {
+		t.Fatalf("could not read testfile: %v", err)
+	}
+	fileSvc.payload = pdf
+
+	var (
+		validation *shared.ValidationError
+		notFound   *shared.NotFoundError
+	)
+	scan := saveEntity(t, repo, document.DocEntity{Title: "scan", FileName: "/2024_01_01/scan.pdf", TagList: "tax", SenderList: "office"})
+
+	p, err := svc.Pages(context.TODO(), scan.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, p.Pages)
+	assert.Len(t, p.Revisions, 1)
+	assert.Equal(t, document.OriginalRevision, p.Revisions[0].Operation)
+	assert.Equal(t, "/2024_01_01/scan.pdf", p.Revisions[0].FileName)
+
+	// the pages of an upload are appended
+	token, err := uploadSvc.Save(upload.File{File: bytes.NewReader(pdf), MimeType: "application/pdf", Name: "back.pdf", Size: int64(len(pdf))})
+	assert.NoError(t, err)
+	doc, err := svc.AppendUpload(context.TODO(), scan.ID, token)
+	assert.NoError(t, err)
+	assert.Regexp(t, `^/\d{4}_\d{2}_\d{2}/scan_rev2_[0-9a-f]{8}\.pdf$`, doc.FileName)
+	fileSvc.payload = lastSaved(t, fileSvc)
+	assert.Equal(t, 2, pageCount(t, fileSvc.payload))
+	assert.Equal(t, document.ContentHash(fileSvc.payload), doc.ContentHash)
+	_, err = svc.AppendUpload(context.TODO(), scan.ID, token)
+	assert.ErrorAs(t, err, &notFound)
+
+	p, err = svc.Pages(context.TODO(), scan.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, p.Pages)
+	assert.Len(t, p.Revisions, 2)
+	assert.Equal(t, "appended back.pdf", p.Revisions[1].Operation)
+	assert.Equal(t, doc.FileName, p.Revisions[1].FileName)
+
+	// pages are rotated and removed
+	for _, edit := range []document.PageEdit{{}, {Remove: "1-2"}, {Rotate: "3", Degrees: 90}, {Rotate: "1", Degrees: 45}} {
+		_, err = svc.EditPages(context.TODO(), scan.ID, edit)
+		assert.ErrorAs(t, err, &validation, edit)
+	}
+	_, err = svc.EditPages(context.TODO(), "unknown", document.PageEdit{Remove: "1"})
+	assert.ErrorAs(t, err, &notFound)
+	doc, err = svc.EditPages(context.TODO(), scan.ID, document.PageEdit{Rotate: "2", Degrees: 90, Remove: "1"})
+	assert.NoError(t, err)
+	assert.Regexp(t, `/scan_rev3_[0-9a-f]{8}\.pdf$`, doc.FileName)
+	assert.Equal(t, 1, pageCount(t, lastSaved(t, fileSvc)))
+	p, err = svc.Pages(context.TODO(), scan.ID)
+	assert.NoError(t, err)
+	assert.Len(t, p.Revisions, 3)
+	assert.Equal(t, "rotated pages 2 by 90°, removed pages 1", p.Revisions[2].Operation)
+
+	// the mock file service still returns the two pages of the second revision
+	split, err := svc.SplitDocument(context.TODO(), scan.ID, "1, 2")
+	assert.NoError(t, err)
+	assert.Len(t, split, 2)
+	assert.Equal(t, "scan (pages 1)", split[0].Title)
+	assert.Equal(t, "scan (pages 2)", split[1].Title)
+	assert.Equal(t, []string{"tax"}, split[1].Tags)
+	assert.Equal(t, []string{"office"}, split[1].Senders)
+	assert.Regexp(t, `/scan_p2_[0-9a-f]{8}\.pdf$`, split[1].FileName)
+	_, err = svc.SplitDocument(context.TODO(), scan.ID, "1-3")
+	assert.ErrorAs(t, err, &validation)
+
+	// merged documents combine the metadata, the documents are kept
+	other := saveEntity(t, repo, document.DocEntity{Title: "other", FileName: "/2024_01_01/other.pdf", TagList: "bill", SenderList: "office"})
+	merged, err := svc.MergeDocuments(context.TODO(), []string{scan.ID, other.ID, scan.ID}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "scan", merged.Title)
+	assert.Equal(t, []string{"tax", "bill"}, merged.Tags)
+	assert.Equal(t, []string{"office"}, merged.Senders)
+	assert.Equal(t, 4, pageCount(t, lastSaved(t, fileSvc)))
+	merged, err = svc.MergeDocuments(context.TODO(), []string{other.ID, scan.ID}, "combined")
+	assert.NoError(t, err)
+	assert.Equal(t, "combined", merged.Title)
+	_, err = svc.GetDocumentByID(context.TODO(), other.ID)
+	assert.NoError(t, err)
+	_, err = svc.MergeDocuments(context.TODO(), []string{scan.ID, scan.ID}, "")
+	assert.ErrorAs(t, err, &validation)
+
+	// only the pages of PDF files are changed
+	fileSvc.payload = []byte("plain text")
+	_, err = svc.Pages(context.TODO(), scan.ID)
+	assert.ErrorAs(t, err, &validation)
+	_, err = svc.EditPages(context.TODO(), scan.ID, document.PageEdit{Remove: "1"})
+	assert.ErrorAs(t, err, &validation)
+
+	// the files of all revisions are deleted with the document
+	fileSvc.deleted = nil
+	assert.NoError(t, svc.DeleteDocumentByID(context.TODO(), scan.ID))
+	assert.Contains(t, fileSvc.deleted, doc.FileName)
+	assert.Contains(t, fileSvc.deleted, "/2024_01_01/scan.pdf")
+	assert.Contains(t, fileSvc.deleted, p.Revisions[1].FileName)
+	revs, err := repo.Revisions(context.TODO(), scan.ID)
+	assert.NoError(t, err)
+	assert.Len(t, revs, 0)
+}
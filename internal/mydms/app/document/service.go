@@ -1,14 +1,17 @@
 package document
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"path"
 	"strings"
 	"time"
 
 	"github.com/microcosm-cc/bluemonday"
 	"golang.binggl.net/monorepo/internal/common/upload"
 	"golang.binggl.net/monorepo/internal/mydms/app/filestore"
+	"golang.binggl.net/monorepo/internal/mydms/app/preview"
 	"golang.binggl.net/monorepo/internal/mydms/app/shared"
 	"golang.binggl.net/monorepo/pkg/logging"
 	"golang.binggl.net/monorepo/pkg/security"
@@ -22,28 +25,88 @@ import (
 // Service defines the methods of the document logic
 type Service interface {
 	// GetDocumentByID returns a document object specified by the given id
-	GetDocumentByID(id string) (d Document, err error)
+	GetDocumentByID(ctx context.Context, id string) (d Document, err error)
 	// DeleteDocumentByID deletes a document specified by the given id
-	DeleteDocumentByID(id string) (err error)
-	// SearchDocuments performs a search and returns paginated results
-	SearchDocuments(title, tag, sender string, from, until time.Time, limit, skip int) (p PagedDocument, err error)
+	DeleteDocumentByID(ctx context.Context, id string) (err error)
+	// SearchDocuments performs a search and returns paginated results, the query is parsed by ParseQuery
+	SearchDocuments(ctx context.Context, query, tag, sender string, from, until time.Time, limit, skip int) (p PagedDocument, err error)
+	// Search returns the documents of the search object ordered by the given fields, see SortFields
+	Search(ctx context.Context, search DocSearch, order []OrderBy) (p PagedDocument, err error)
 	// SearchList searches for senders or tags
-	SearchList(name string, st SearchType) (l []string, err error)
+	SearchList(ctx context.Context, name string, st SearchType) (l []string, err error)
 	// SaveDocument receives a document and stores it
 	// either creates a new document or updates an existing one
-	SaveDocument(doc Document, user security.User) (d Document, err error)
+	SaveDocument(ctx context.Context, doc Document, user security.User) (d Document, err error)
+	// GeneratePreviews creates the missing thumbnails of the stored documents
+	GeneratePreviews(ctx context.Context) (r PreviewResult, err error)
+	// SpendingReport aggregates the amounts of the documents matching the search criteria
+	SpendingReport(ctx context.Context, query, tag, sender string, from, until time.Time, currency string) (r Report, err error)
+	// ReviewQueue returns the drafts which need to be completed by the user
+	ReviewQueue(ctx context.Context, limit, skip int) (p PagedDocument, err error)
+	// DocumentsByContentHash returns the documents storing an identical file
+	DocumentsByContentHash(ctx context.Context, hash string) (d []Document, err error)
+	// Duplicates groups the documents storing identical files
+	Duplicates(ctx context.Context) (g []DuplicateGroup, err error)
+	// ComputeContentHashes creates the missing content hashes of the stored documents
+	ComputeContentHashes(ctx context.Context) (r HashResult, err error)
+	// MergeDuplicates merges the duplicates into the kept document and removes them
+	MergeDuplicates(ctx context.Context, keepID string, ids []string) (d Document, err error)
+	// ListItems returns the tags or senders with the number of their documents
+	ListItems(ctx context.Context, st SearchType) (l []ListItem, err error)
+	// RenameListItem changes the name of a tag or sender for all documents
+	RenameListItem(ctx context.Context, st SearchType, id, name string) (err error)
+	// MergeListItems replaces the tags or senders by the target for all documents
+	MergeListItems(ctx context.Context, st SearchType, targetID string, ids []string) (err error)
+	// DeleteListItem removes a tag or sender from all documents
+	DeleteListItem(ctx context.Context, st SearchType, id string) (err error)
+	// UpcomingDocuments returns the documents having a due or reminder date ordered by the due date
+	UpcomingDocuments(ctx context.Context, includeDone bool) (d []Document, err error)
+	// MarkDone marks the document as paid or done, done=false opens the document again
+	MarkDone(ctx context.Context, id string, done bool) (d Document, err error)
+	// DueReminders returns the open documents whose reminder is due on the given day and was not sent
+	DueReminders(ctx context.Context, day time.Time) (d []Document, err error)
+	// MarkReminded records the sent reminders of the documents
+	MarkReminded(ctx context.Context, ids []string) (err error)
+	// BulkUpdate applies the change to the documents and reports the documents which could not be changed
+	BulkUpdate(ctx context.Context, ids []string, change BulkChange) (r BulkResult, err error)
+	// Pages returns the number of pages and the stored revisions of the document
+	Pages(ctx context.Context, id string) (p DocumentPages, err error)
+	// EditPages rotates and removes pages of the document, the result is stored as a new revision
+	EditPages(ctx context.Context, id string, edit PageEdit) (d Document, err error)
+	// AppendUpload adds the pages of the uploaded PDF to the document as a new revision
+	AppendUpload(ctx context.Context, id, uploadToken string) (d Document, err error)
+	// SplitDocument creates a new document for each of the page ranges of the document
+	SplitDocument(ctx context.Context, id, ranges string) (d []Document, err error)
+	// MergeDocuments combines the files of the documents in the given order into a new document
+	MergeDocuments(ctx context.Context, ids []string, title string) (d Document, err error)
+}
+
+// Classifier completes the metadata of new documents, e.g. by rules defined by the user
+type Classifier interface {
+	// Classify returns the document with the classified values, the payload is the uploaded file
+	Classify(ctx context.Context, doc Document, payload []byte) Document
+}
+
+// PreviewResult counts the documents processed by GeneratePreviews
+type PreviewResult struct {
+	Created int
+	Failed  int
 }
 
 // NewService returns a Service with all of the expected middlewares wired in.
-func NewService(logger logging.Logger, repo Repository, fileSvc filestore.FileService, uploadSvc upload.Service) Service {
+// Without a preview generator no thumbnails are created for the documents, without a
+// classifier new documents are saved as supplied.
+func NewService(logger logging.Logger, repo Repository, fileSvc filestore.FileService, uploadSvc upload.Service, previews preview.Generator, classifier Classifier) Service {
 	var svc Service
 	{
 		svc = &documentService{
-			repo:      repo,
-			policy:    bluemonday.UGCPolicy(),
-			logger:    logger,
-			fileSvc:   fileSvc,
-			uploadSvc: uploadSvc,
+			repo:       repo,
+			policy:     bluemonday.UGCPolicy(),
+			logger:     logger,
+			fileSvc:    fileSvc,
+			uploadSvc:  uploadSvc,
+			previews:   previews,
+			classifier: classifier,
 		}
 		svc = ServiceLoggingMiddleware(logger)(svc)
 	}
@@ -56,23 +119,28 @@ func NewService(logger logging.Logger, repo Repository, fileSvc filestore.FileSe
 
 const jsonTimeLayout = "2006-01-02T15:04:05+07:00"
 
+// the thumbnail is stored next to the document
+const previewSuffix = ".preview.jpg"
+
 // compile time assertions for our service
 var (
 	_ Service = &documentService{}
 )
 
 type documentService struct {
-	repo      Repository
-	policy    *bluemonday.Policy
-	fileSvc   filestore.FileService
-	uploadSvc upload.Service
-	logger    logging.Logger
+	repo       Repository
+	policy     *bluemonday.Policy
+	fileSvc    filestore.FileService
+	uploadSvc  upload.Service
+	previews   preview.Generator
+	classifier Classifier
+	logger     logging.Logger
 }
 
 // GetDocumentByID returns a Document object for a specified id, or returns an error if the document is not found
-func (s documentService) GetDocumentByID(id string) (d Document, err error) {
+func (s documentService) GetDocumentByID(ctx context.Context, id string) (d Document, err error) {
 	var doc DocEntity
-	if doc, err = s.repo.Get(id); err != nil {
+	if doc, err = s.repo.Get(ctx, id, shared.Atomic{}); err != nil {
 		s.logger.Error("GetDocumentByID: repository error", logging.ErrV(fmt.Errorf("could not find doucment by id: '%s', %v", id, err)))
 		return Document{}, shared.ErrNotFound(fmt.Sprintf("could not find doucment by id: %s", id))
 	}
@@ -80,7 +148,7 @@ func (s documentService) GetDocumentByID(id string) (d Document, err error) {
 }
 
 // DeleteDocumentByID deletes a document identified by the id. the method returns an error if no document is availalbe for the given id
-func (s documentService) DeleteDocumentByID(id string) (err error) {
+func (s documentService) DeleteDocumentByID(ctx context.Context, id string) (err error) {
 	atomic, err := s.repo.CreateAtomic()
 	if err != nil {
 		return
@@ -90,29 +158,39 @@ func (s documentService) DeleteDocumentByID(id string) (err error) {
 		err = shared.HandleTX(true, &atomic, err)
 	}()
 
-	fileName, err := s.repo.Exists(id, atomic)
+	fileName, err := s.repo.Exists(ctx, id, atomic)
 	if err != nil {
 		s.logger.Error("DeleteDocumentByID: error in repository", logging.ErrV(fmt.Errorf("the document '%s' is not available, %v", id, err)))
 		return shared.ErrNotFound(fmt.Sprintf("document '%s' not available", id))
 	}
+	revisions, err := s.revisionFiles(ctx, id, fileName)
+	if err != nil {
+		return err
+	}
 
-	err = s.repo.Delete(id, atomic)
+	err = s.repo.Delete(ctx, id, atomic)
 	if err != nil {
 		s.logger.Error("DeleteDocumentByID: error in repository", logging.ErrV(fmt.Errorf("error during delete operation of '%s', %v", id, err)))
 		return fmt.Errorf("could not delete '%s', %v", id, err)
 	}
 
 	// also remove the file payload stored in the backend store
-	err = s.fileSvc.DeleteFile(fileName)
+	err = s.fileSvc.DeleteFile(ctx, fileName)
 	if err != nil {
 		s.logger.Error("DeleteDocumentByID: error in file-service", logging.ErrV(fmt.Errorf("could not delete file in backend store '%s', %v", fileName, err)))
 		return fmt.Errorf("could not delete '%s', %v", id, err)
 	}
+	// the document might not have a preview, the error is ignored
+	if err := s.fileSvc.DeleteFile(ctx, fileName+previewSuffix); err != nil {
+		s.logger.Warn("DeleteDocumentByID: could not delete the preview", logging.ErrV(err))
+	}
+	s.removeFiles(ctx, revisions)
 	return nil
 }
 
-// SearchDocuments performs a search and returns paginated results
-func (s documentService) SearchDocuments(title, tag, sender string, from, until time.Time, limit, skip int) (p PagedDocument, err error) {
+// SearchDocuments performs a search and returns paginated results.
+// The invalid fragments of the query are ignored.
+func (s documentService) SearchDocuments(ctx context.Context, query, tag, sender string, from, until time.Time, limit, skip int) (p PagedDocument, err error) {
 	var (
 		order []OrderBy
 		pd    PagedDocument
@@ -125,14 +203,15 @@ func (s documentService) SearchDocuments(title, tag, sender string, from, until
 	orderByTitle := OrderBy{Field: "title", Order: ASC}
 	orderByCreated := OrderBy{Field: "created", Order: DESC}
 
-	docs, err := s.repo.Search(DocSearch{
-		Title:  title,
-		Tag:    tag,
-		Sender: sender,
-		From:   from,
-		Until:  until,
-		Limit:  limit,
-		Skip:   skip,
+	filters, _ := ParseQuery(query)
+	docs, err := s.repo.Search(ctx, DocSearch{
+		Tag:     tag,
+		Sender:  sender,
+		From:    from,
+		Until:   until,
+		Filters: filters,
+		Limit:   limit,
+		Skip:    skip,
 	}, append(order, orderByCreated, orderByTitle))
 	if err != nil {
 		s.logger.Error("SearchDocuments: repository error", logging.ErrV(fmt.Errorf("search resulted in an error; %v", err)))
@@ -145,9 +224,55 @@ func (s documentService) SearchDocuments(title, tag, sender string, from, until
 	return pd, nil
 }
 
+// SortFields maps the fields available to order the documents to the columns of the documents
+var SortFields = map[string]string{
+	"title":    "title",
+	"created":  "created",
+	"modified": "modified",
+	"amount":   "amountminor",
+	"dueDate":  "duedate",
+}
+
+// MaxSearchLimit is the maximum number of documents returned by a search
+const MaxSearchLimit = 100
+
+// Search returns the documents of the search object. The fields of the order are the keys of
+// SortFields, without an order the newest documents are returned first.
+func (s documentService) Search(ctx context.Context, search DocSearch, order []OrderBy) (p PagedDocument, err error) {
+	switch {
+	case search.Limit == 0:
+		search.Limit = 20
+	case search.Limit < 0 || search.Limit > MaxSearchLimit:
+		return p, shared.ErrValidation(fmt.Sprintf("the limit needs to be between 1 and %d", MaxSearchLimit))
+	}
+	if search.Skip < 0 {
+		return p, shared.ErrValidation("the number of skipped documents cannot be negative")
+	}
+	columns := make([]OrderBy, 0, len(order)+1)
+	for _, o := range order {
+		column, ok := SortFields[o.Field]
+		if !ok {
+			return p, shared.ErrValidation(fmt.Sprintf("the documents cannot be ordered by '%s'", o.Field))
+		}
+		columns = append(columns, OrderBy{Field: column, Order: o.Order})
+	}
+	if len(columns) == 0 {
+		columns = append(columns, OrderBy{Field: "created", Order: DESC}, OrderBy{Field: "title", Order: ASC})
+	}
+
+	docs, err := s.repo.Search(ctx, search, columns)
+	if err != nil {
+		s.logger.Error("Search: repository error", logging.ErrV(fmt.Errorf("search resulted in an error; %v", err)))
+		return p, fmt.Errorf("cannot search for documents; %v", err)
+	}
+	p.Documents = s.convertList(docs.Documents)
+	p.TotalEntries = docs.Count
+	return p, nil
+}
+
 // SearchList searches for senders or tags
-func (s documentService) SearchList(name string, st SearchType) (l []string, err error) {
-	result, err := s.repo.SearchLists(name, st)
+func (s documentService) SearchList(ctx context.Context, name string, st SearchType) (l []string, err error) {
+	result, err := s.repo.SearchLists(ctx, name, st)
 	if err != nil {
 		s.logger.Error("could not search the list", logging.ErrV(fmt.Errorf("error searching for '%s'; %v", name, err)))
 		return nil, fmt.Errorf("could not search for '%s': %v", name, err)
@@ -157,7 +282,7 @@ func (s documentService) SearchList(name string, st SearchType) (l []string, err
 
 // SaveDocument receives a document and stores it
 // either creation a new document or updating an existing
-func (s documentService) SaveDocument(doc Document, user security.User) (d Document, err error) {
+func (s documentService) SaveDocument(ctx context.Context, doc Document, user security.User) (d Document, err error) {
 	var (
 		docE DocEntity
 	)
@@ -176,7 +301,7 @@ func (s documentService) SaveDocument(doc Document, user security.User) (d Docum
 	cleanDoc := s.sanitize(&doc)
 	d = *cleanDoc
 
-	filename, err := s.processUploadFile(d.UploadToken, d.FileName)
+	filename, previewLink, payload, err := s.processUploadFile(ctx, d.UploadToken, d.FileName)
 	if err != nil {
 		s.logger.Error("SaveDocument: upload-processing error", logging.ErrV(fmt.Errorf("could not process the uploaded file, %v", err)))
 		return
@@ -187,30 +312,50 @@ func (s documentService) SaveDocument(doc Document, user security.User) (d Docum
 	}
 	d.FileName = filename
 
+	if d.ID == "" && payload != nil && s.classifier != nil {
+		classified := s.classifier.Classify(ctx, d, payload)
+		d = *s.sanitize(&classified)
+	}
+
 	tagList := strings.Join(d.Tags, ";")
 	senderList := strings.Join(d.Senders, ";")
 
+	if payload != nil {
+		d.ContentHash = ContentHash(payload)
+	}
+
 	if d.ID == "" {
-		docE = initDocument(&d, senderList, tagList)
+		docE = initDocument(&d, senderList, tagList, previewLink)
 	} else {
 		// supplied ID needs to be checked if exists
-		docE, err = s.repo.Get(d.ID)
+		docE, err = s.repo.Get(ctx, d.ID, atomic)
 		if err != nil {
 			s.logger.Info(fmt.Sprintf("SaveDocument: cannot find document by ID '%s' - create a new entry, %v", d.ID, err))
-			docE = initDocument(&d, senderList, tagList)
+			docE = initDocument(&d, senderList, tagList, previewLink)
 		} else {
 			s.logger.Info(fmt.Sprintf("SaveDocument: will update existing document ID '%s'", d.ID))
 			docE.Title = d.Title
+			if previewLink.Valid || docE.FileName != d.FileName {
+				// a new file was uploaded, the preview of the former file is replaced
+				docE.PreviewLink = previewLink
+			}
+			if payload != nil {
+				docE.ContentHash = sql.NullString{String: d.ContentHash, Valid: true}
+			}
 			docE.FileName = d.FileName
-			docE.PreviewLink = sql.NullString{String: text.EncBase64SafePath(d.FileName), Valid: true}
-			docE.Amount = d.Amount
+			docE.AmountMinor, docE.Currency = amountColumns(d.Amount)
 			docE.SenderList = senderList
 			docE.TagList = tagList
 			docE.InvoiceNumber = sql.NullString{String: d.InvoiceNumber, Valid: true}
+			// saving a draft by the user completes the review
+			docE.NeedsReview = d.NeedsReview
 		}
 	}
+	if err = dueEntity(d, &docE); err != nil {
+		return d, err
+	}
 
-	docE, err = s.repo.Save(docE, atomic)
+	docE, err = s.repo.Save(ctx, docE, atomic)
 	if err != nil {
 		s.logger.Error("error saving document with repository", logging.ErrV(fmt.Errorf("could not save document: %v", err)))
 		return d, fmt.Errorf("error while saving document: %v", err)
@@ -225,6 +370,84 @@ func (s documentService) SaveDocument(doc Document, user security.User) (d Docum
 	return s.convertToDomain(docE), nil
 }
 
+// SpendingReport aggregates the amounts of the documents matching the search criteria.
+// The amounts are only summed within the given currency.
+func (s documentService) SpendingReport(ctx context.Context, query, tag, sender string, from, until time.Time, currency string) (r Report, err error) {
+	filters, _ := ParseQuery(query)
+	amounts, err := s.repo.SearchAmounts(ctx, DocSearch{
+		Tag:     tag,
+		Sender:  sender,
+		From:    from,
+		Until:   until,
+		Filters: filters,
+	})
+	if err != nil {
+		s.logger.Error("SpendingReport: repository error", logging.ErrV(fmt.Errorf("search resulted in an error; %v", err)))
+		return r, fmt.Errorf("cannot search for the amounts of the documents; %v", err)
+	}
+	return newReport(amounts, currency), nil
+}
+
+// ReviewQueue returns the drafts, the oldest drafts are listed first
+func (s documentService) ReviewQueue(ctx context.Context, limit, skip int) (p PagedDocument, err error) {
+	if limit == 0 {
+		limit = 20
+	}
+	docs, err := s.repo.Search(ctx, DocSearch{
+		NeedsReview: true,
+		Limit:       limit,
+		Skip:        skip,
+	}, []OrderBy{{Field: "created", Order: ASC}, {Field: "title", Order: ASC}})
+	if err != nil {
+		s.logger.Error("ReviewQueue: repository error", logging.ErrV(fmt.Errorf("search resulted in an error; %v", err)))
+		return p, fmt.Errorf("cannot search for the documents to review; %v", err)
+	}
+	p.Documents = s.convertList(docs.Documents)
+	p.TotalEntries = docs.Count
+	return p, nil
+}
+
+// GeneratePreviews creates the thumbnails of the documents stored without a preview.
+// Documents which cannot be processed are logged and counted as failed.
+func (s documentService) GeneratePreviews(ctx context.Context) (r PreviewResult, err error) {
+	if s.previews == nil {
+		return r, fmt.Errorf("no preview generator is available")
+	}
+
+	const pageSize = 100
+	// only the preview-link of the documents is changed, the paging is stable
+	order := []OrderBy{{Field: "created", Order: ASC}}
+	for skip := 0; ; skip += pageSize {
+		docs, err := s.repo.Search(ctx, DocSearch{Limit: pageSize, Skip: skip}, order)
+		if err != nil {
+			return r, fmt.Errorf("cannot search for documents; %v", err)
+		}
+		for _, doc := range docs.Documents {
+			if hasPreview(doc) {
+				continue
+			}
+			item, err := s.fileSvc.GetFile(ctx, doc.FileName)
+			if err != nil {
+				s.logger.Warn(fmt.Sprintf("GeneratePreviews: cannot get the file of document '%s'", doc.ID), logging.ErrV(err))
+				r.Failed++
+				continue
+			}
+			link := s.savePreview(ctx, doc.FileName, item.Payload)
+			if !link.Valid {
+				r.Failed++
+				continue
+			}
+			if err = s.repo.UpdatePreview(ctx, doc.ID, link, shared.Atomic{}); err != nil {
+				return r, fmt.Errorf("could not update the preview of document '%s'; %v", doc.ID, err)
+			}
+			r.Created++
+		}
+		if len(docs.Documents) < pageSize {
+			return r, nil
+		}
+	}
+}
+
 // --------------------------------------------------------------------------
 // internal helpers
 // --------------------------------------------------------------------------
@@ -237,13 +460,9 @@ func (s documentService) convertToDomain(d DocEntity) Document {
 		mod     string
 	)
 
-	p := d.PreviewLink
 	preview := ""
-	if p.Valid {
-		preview = p.String
-	} else {
-		// missing DB value for preview-link
-		preview = text.EncBase64SafePath(d.FileName)
+	if hasPreview(d) {
+		preview = d.PreviewLink.String
 	}
 	if d.TagList != "" {
 		tags = strings.Split(d.TagList, ";")
@@ -260,11 +479,18 @@ func (s documentService) convertToDomain(d DocEntity) Document {
 	if d.InvoiceNumber.Valid {
 		inv = d.InvoiceNumber.String
 	}
+	var amount Money
+	if d.AmountMinor.Valid {
+		amount = Money{Minor: d.AmountMinor.Int64, Currency: d.Currency.String}
+		if !d.Currency.Valid || d.Currency.String == "" {
+			amount.Currency = DefaultCurrency
+		}
+	}
 	doc := s.sanitize(&Document{
 		ID:            d.ID,
 		Title:         d.Title,
 		AltID:         d.AltID,
-		Amount:        d.Amount,
+		Amount:        amount,
 		Created:       cre,
 		Modified:      mod,
 		FileName:      d.FileName,
@@ -272,7 +498,19 @@ func (s documentService) convertToDomain(d DocEntity) Document {
 		Tags:          tags,
 		Senders:       senders,
 		InvoiceNumber: inv,
+		NeedsReview:   d.NeedsReview,
+		ContentHash:   d.ContentHash.String,
+		DueType:       DueType(d.DueType.String),
 	})
+	if d.DueDate.Valid {
+		doc.DueDate = d.DueDate.Time.Format(DateLayout)
+	}
+	if d.ReminderDate.Valid {
+		doc.ReminderDate = d.ReminderDate.Time.Format(DateLayout)
+	}
+	if d.DoneAt.Valid {
+		doc.Done = d.DoneAt.Time.Format(jsonTimeLayout)
+	}
 	return *doc
 }
 
@@ -290,10 +528,16 @@ func (s documentService) convertList(ds []DocEntity) []Document {
 
 func (s documentService) sanitize(d *Document) *Document {
 	doc := Document{
-		ID:     d.ID,
-		Amount: d.Amount,
+		ID:          d.ID,
+		Amount:      d.Amount,
+		NeedsReview: d.NeedsReview,
+		ContentHash: d.ContentHash,
+		Done:        d.Done,
 	}
 	doc.Title = s.policy.Sanitize(d.Title)
+	doc.DueDate = s.policy.Sanitize(d.DueDate)
+	doc.ReminderDate = s.policy.Sanitize(d.ReminderDate)
+	doc.DueType = DueType(s.policy.Sanitize(string(d.DueType)))
 	doc.AltID = s.policy.Sanitize(d.AltID)
 	doc.Created = s.policy.Sanitize(d.Created)
 	doc.Modified = s.policy.Sanitize(d.Modified)
@@ -311,14 +555,17 @@ func (s documentService) sanitize(d *Document) *Document {
 	return &doc
 }
 
-func (s documentService) processUploadFile(uploadToken, fileName string) (string, error) {
+// processUploadFile stores the uploaded file and its preview. It returns the path of the file, the
+// base64 encoded path of the preview, which is invalid if no preview could be created, and the
+// payload of the uploaded file. Without an upload the payload is nil.
+func (s documentService) processUploadFile(ctx context.Context, uploadToken, fileName string) (string, sql.NullString, []byte, error) {
 	if uploadToken == "" || uploadToken == "-" {
-		return fileName, nil
+		return fileName, sql.NullString{}, nil, nil
 	}
 	u, err := s.uploadSvc.Read(uploadToken)
 	if err != nil {
 		s.logger.Error("upload returned error", logging.ErrV(fmt.Errorf("could not read upload-file for token '%s', %v", uploadToken, err)))
-		return "", fmt.Errorf("upload token error: %v", err)
+		return "", sql.NullString{}, nil, fmt.Errorf("upload token error: %v", err)
 	}
 	s.logger.Info(fmt.Sprintf("use uploaded file identified by token '%s'", uploadToken))
 
@@ -332,23 +579,67 @@ func (s documentService) processUploadFile(uploadToken, fileName string) (string
 		MimeType:   u.MimeType,
 		Payload:    u.Payload,
 	}
-	err = s.fileSvc.SaveFile(item)
+	err = s.fileSvc.SaveFile(ctx, item)
 	if err != nil {
 		s.logger.Error("unable to save file", logging.ErrV(fmt.Errorf("could not save file '%s', %v", u.FileName, err)))
-		return "", fmt.Errorf("error while saving file: %v", err)
+		return "", sql.NullString{}, nil, fmt.Errorf("error while saving file: %v", err)
+	}
+
+	filePath := fmt.Sprintf("/%s/%s", folder, fileName)
+	return filePath, s.savePreview(ctx, filePath, u.Payload), u.Payload, nil
+}
+
+// savePreview creates the thumbnail of the payload and stores it next to the file.
+// A missing preview does not invalidate the document, errors are only logged.
+func (s documentService) savePreview(ctx context.Context, filePath string, payload []byte) sql.NullString {
+	if s.previews == nil {
+		return sql.NullString{}
+	}
+	thumbnail, err := s.previews.Thumbnail(ctx, payload)
+	if err != nil {
+		s.logger.Warn(fmt.Sprintf("could not create a preview for '%s'", filePath), logging.ErrV(err))
+		return sql.NullString{}
+	}
+	folder, fileName := path.Split(strings.TrimPrefix(filePath, "/"))
+	item := filestore.FileItem{
+		FileName:   fileName + previewSuffix,
+		FolderName: strings.TrimSuffix(folder, "/"),
+		MimeType:   "image/jpeg",
+		Payload:    thumbnail,
+	}
+	if err = s.fileSvc.SaveFile(ctx, item); err != nil {
+		s.logger.Warn(fmt.Sprintf("could not save the preview of '%s'", filePath), logging.ErrV(err))
+		return sql.NullString{}
 	}
+	return sql.NullString{String: text.EncBase64SafePath("/" + item.String()), Valid: true}
+}
+
+// hasPreview checks for a stored thumbnail; documents created before the previews were introduced
+// used the encoded path of the file as preview-link.
+func hasPreview(d DocEntity) bool {
+	return d.PreviewLink.Valid && d.PreviewLink.String != "" && d.PreviewLink.String != text.EncBase64SafePath(d.FileName)
+}
 
-	return fmt.Sprintf("/%s/%s", folder, fileName), nil
+// amountColumns maps the amount to the database columns, a zero amount is stored as no amount
+func amountColumns(m Money) (sql.NullInt64, sql.NullString) {
+	if m.IsZero() {
+		return sql.NullInt64{}, sql.NullString{}
+	}
+	return sql.NullInt64{Int64: m.Minor, Valid: true}, sql.NullString{String: m.Currency, Valid: true}
 }
 
-func initDocument(d *Document, sList, tList string) DocEntity {
+func initDocument(d *Document, sList, tList string, previewLink sql.NullString) DocEntity {
+	amount, currency := amountColumns(d.Amount)
 	return DocEntity{
 		Title:         d.Title,
 		FileName:      d.FileName,
-		PreviewLink:   sql.NullString{String: text.EncBase64SafePath(d.FileName), Valid: true},
-		Amount:        d.Amount,
+		PreviewLink:   previewLink,
+		AmountMinor:   amount,
+		Currency:      currency,
 		SenderList:    sList,
 		TagList:       tList,
 		InvoiceNumber: sql.NullString{String: d.InvoiceNumber, Valid: true},
+		NeedsReview:   d.NeedsReview,
+		ContentHash:   sql.NullString{String: d.ContentHash, Valid: d.ContentHash != ""},
 	}
 }
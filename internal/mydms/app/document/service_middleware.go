@@ -1,7 +1,9 @@
 package document
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"golang.binggl.net/monorepo/pkg/logging"
@@ -31,20 +33,20 @@ type loggingMiddleware struct {
 	next   Service
 }
 
-func (mw loggingMiddleware) GetDocumentByID(id string) (d Document, err error) {
+func (mw loggingMiddleware) GetDocumentByID(ctx context.Context, id string) (d Document, err error) {
 	mw.logger.Info("GetDocumentByID", logging.LogV("param:ID", id))
 	defer mw.logger.Info("called GetDocumentByID", logging.ErrV(err))
-	return mw.next.GetDocumentByID(id)
+	return mw.next.GetDocumentByID(ctx, id)
 }
 
-func (mw loggingMiddleware) DeleteDocumentByID(id string) (err error) {
+func (mw loggingMiddleware) DeleteDocumentByID(ctx context.Context, id string) (err error) {
 	mw.logger.Info("DeleteDocumentByID", logging.LogV("param:ID", id))
 	defer mw.logger.Info("called DeleteDocumentByID", logging.ErrV(err))
-	return mw.next.DeleteDocumentByID(id)
+	return mw.next.DeleteDocumentByID(ctx, id)
 }
 
-func (mw loggingMiddleware) SearchDocuments(title, tag, sender string, from, until time.Time, limit, skip int) (p PagedDocument, err error) {
-	mw.logger.Info("SearchDocuments", logging.LogV("param:title", title),
+func (mw loggingMiddleware) SearchDocuments(ctx context.Context, query, tag, sender string, from, until time.Time, limit, skip int) (p PagedDocument, err error) {
+	mw.logger.Info("SearchDocuments", logging.LogV("param:query", query),
 		logging.LogV("param:tag", tag),
 		logging.LogV("param:sender", sender),
 		logging.LogV("param:from", from.String()),
@@ -53,21 +55,170 @@ func (mw loggingMiddleware) SearchDocuments(title, tag, sender string, from, unt
 		logging.LogV("param:skip", fmt.Sprintf("%d", skip)),
 	)
 	defer mw.logger.Info("called SearchDocuments", logging.ErrV(err))
-	return mw.next.SearchDocuments(title, tag, sender, from, until, limit, skip)
+	return mw.next.SearchDocuments(ctx, query, tag, sender, from, until, limit, skip)
 }
 
-func (mw loggingMiddleware) SearchList(name string, st SearchType) (l []string, err error) {
+func (mw loggingMiddleware) Search(ctx context.Context, search DocSearch, order []OrderBy) (p PagedDocument, err error) {
+	mw.logger.Info("Search", logging.LogV("param:title", search.Title),
+		logging.LogV("param:tag", search.Tag),
+		logging.LogV("param:sender", search.Sender),
+		logging.LogV("param:from", search.From.String()),
+		logging.LogV("param:until", search.Until.String()),
+		logging.LogV("param:filters", fmt.Sprintf("%d", len(search.Filters))),
+		logging.LogV("param:limit", fmt.Sprintf("%d", search.Limit)),
+		logging.LogV("param:skip", fmt.Sprintf("%d", search.Skip)),
+		logging.LogV("param:order", fmt.Sprintf("%v", order)),
+	)
+	defer mw.logger.Info("called Search", logging.ErrV(err))
+	return mw.next.Search(ctx, search, order)
+}
+
+func (mw loggingMiddleware) SearchList(ctx context.Context, name string, st SearchType) (l []string, err error) {
 	mw.logger.Info("DeleteDocumentByID", logging.LogV("param:name", name), logging.LogV("param:searchtype", st.String()))
 	defer mw.logger.Info("called SearchList", logging.ErrV(err))
-	return mw.next.SearchList(name, st)
+	return mw.next.SearchList(ctx, name, st)
 }
 
-func (mw loggingMiddleware) SaveDocument(doc Document, user security.User) (d Document, err error) {
+func (mw loggingMiddleware) SaveDocument(ctx context.Context, doc Document, user security.User) (d Document, err error) {
 	mw.logger.Info("SaveDocument", logging.LogV("param:doc", doc.String()),
 		logging.LogV("param:user", user.String()),
 		logging.LogV("param.doc.filename", doc.FileName),
 		logging.LogV("param.doc.uploadtoken", doc.UploadToken),
 	)
 	defer mw.logger.Info("called SaveDocument", logging.ErrV(err))
-	return mw.next.SaveDocument(doc, user)
+	return mw.next.SaveDocument(ctx, doc, user)
+}
+
+func (mw loggingMiddleware) GeneratePreviews(ctx context.Context) (r PreviewResult, err error) {
+	mw.logger.Info("GeneratePreviews")
+	defer mw.logger.Info("called GeneratePreviews", logging.ErrV(err))
+	return mw.next.GeneratePreviews(ctx)
+}
+
+func (mw loggingMiddleware) SpendingReport(ctx context.Context, query, tag, sender string, from, until time.Time, currency string) (r Report, err error) {
+	mw.logger.Info("SpendingReport", logging.LogV("param:query", query),
+		logging.LogV("param:tag", tag),
+		logging.LogV("param:sender", sender),
+		logging.LogV("param:from", from.String()),
+		logging.LogV("param:until", until.String()),
+		logging.LogV("param:currency", currency),
+	)
+	defer mw.logger.Info("called SpendingReport", logging.ErrV(err))
+	return mw.next.SpendingReport(ctx, query, tag, sender, from, until, currency)
+}
+
+func (mw loggingMiddleware) ReviewQueue(ctx context.Context, limit, skip int) (p PagedDocument, err error) {
+	mw.logger.Info("ReviewQueue", logging.LogV("param:limit", fmt.Sprintf("%d", limit)),
+		logging.LogV("param:skip", fmt.Sprintf("%d", skip)),
+	)
+	defer mw.logger.Info("called ReviewQueue", logging.ErrV(err))
+	return mw.next.ReviewQueue(ctx, limit, skip)
+}
+
+func (mw loggingMiddleware) DocumentsByContentHash(ctx context.Context, hash string) (d []Document, err error) {
+	mw.logger.Info("DocumentsByContentHash", logging.LogV("param:hash", hash))
+	defer mw.logger.Info("called DocumentsByContentHash", logging.ErrV(err))
+	return mw.next.DocumentsByContentHash(ctx, hash)
+}
+
+func (mw loggingMiddleware) Duplicates(ctx context.Context) (g []DuplicateGroup, err error) {
+	mw.logger.Info("Duplicates")
+	defer mw.logger.Info("called Duplicates", logging.ErrV(err))
+	return mw.next.Duplicates(ctx)
+}
+
+func (mw loggingMiddleware) ComputeContentHashes(ctx context.Context) (r HashResult, err error) {
+	mw.logger.Info("ComputeContentHashes")
+	defer mw.logger.Info("called ComputeContentHashes", logging.ErrV(err))
+	return mw.next.ComputeContentHashes(ctx)
+}
+
+func (mw loggingMiddleware) MergeDuplicates(ctx context.Context, keepID string, ids []string) (d Document, err error) {
+	mw.logger.Info("MergeDuplicates", logging.LogV("param:keepID", keepID), logging.LogV("param:ids", strings.Join(ids, ",")))
+	defer mw.logger.Info("called MergeDuplicates", logging.ErrV(err))
+	return mw.next.MergeDuplicates(ctx, keepID, ids)
+}
+
+func (mw loggingMiddleware) ListItems(ctx context.Context, st SearchType) (l []ListItem, err error) {
+	mw.logger.Info("ListItems", logging.LogV("param:st", st.String()))
+	defer mw.logger.Info("called ListItems", logging.ErrV(err))
+	return mw.next.ListItems(ctx, st)
+}
+
+func (mw loggingMiddleware) RenameListItem(ctx context.Context, st SearchType, id, name string) (err error) {
+	mw.logger.Info("RenameListItem", logging.LogV("param:st", st.String()), logging.LogV("param:ID", id), logging.LogV("param:name", name))
+	defer mw.logger.Info("called RenameListItem", logging.ErrV(err))
+	return mw.next.RenameListItem(ctx, st, id, name)
+}
+
+func (mw loggingMiddleware) MergeListItems(ctx context.Context, st SearchType, targetID string, ids []string) (err error) {
+	mw.logger.Info("MergeListItems", logging.LogV("param:st", st.String()), logging.LogV("param:targetID", targetID), logging.LogV("param:ids", strings.Join(ids, ",")))
+	defer mw.logger.Info("called MergeListItems", logging.ErrV(err))
+	return mw.next.MergeListItems(ctx, st, targetID, ids)
+}
+
+func (mw loggingMiddleware) DeleteListItem(ctx context.Context, st SearchType, id string) (err error) {
+	mw.logger.Info("DeleteListItem", logging.LogV("param:st", st.String()), logging.LogV("param:ID", id))
+	defer mw.logger.Info("called DeleteListItem", logging.ErrV(err))
+	return mw.next.DeleteListItem(ctx, st, id)
+}
+
+func (mw loggingMiddleware) BulkUpdate(ctx context.Context, ids []string, change BulkChange) (r BulkResult, err error) {
+	mw.logger.Info("BulkUpdate", logging.LogV("param:ids", strings.Join(ids, ",")), logging.LogV("param:action", string(change.Action)), logging.LogV("param:values", strings.Join(change.Values, ",")))
+	defer mw.logger.Info("called BulkUpdate", logging.ErrV(err))
+	return mw.next.BulkUpdate(ctx, ids, change)
+}
+
+func (mw loggingMiddleware) UpcomingDocuments(ctx context.Context, includeDone bool) (d []Document, err error) {
+	mw.logger.Info("UpcomingDocuments", logging.LogV("param:includeDone", fmt.Sprintf("%t", includeDone)))
+	defer mw.logger.Info("called UpcomingDocuments", logging.ErrV(err))
+	return mw.next.UpcomingDocuments(ctx, includeDone)
+}
+
+func (mw loggingMiddleware) MarkDone(ctx context.Context, id string, done bool) (d Document, err error) {
+	mw.logger.Info("MarkDone", logging.LogV("param:ID", id), logging.LogV("param:done", fmt.Sprintf("%t", done)))
+	defer mw.logger.Info("called MarkDone", logging.ErrV(err))
+	return mw.next.MarkDone(ctx, id, done)
+}
+
+func (mw loggingMiddleware) DueReminders(ctx context.Context, day time.Time) (d []Document, err error) {
+	mw.logger.Info("DueReminders", logging.LogV("param:day", day.Format(DateLayout)))
+	defer mw.logger.Info("called DueReminders", logging.ErrV(err))
+	return mw.next.DueReminders(ctx, day)
+}
+
+func (mw loggingMiddleware) MarkReminded(ctx context.Context, ids []string) (err error) {
+	mw.logger.Info("MarkReminded", logging.LogV("param:ids", strings.Join(ids, ",")))
+	defer mw.logger.Info("called MarkReminded", logging.ErrV(err))
+	return mw.next.MarkReminded(ctx, ids)
+}
+
+func (mw loggingMiddleware) Pages(ctx context.Context, id string) (p DocumentPages, err error) {
+	mw.logger.Info("Pages", logging.LogV("param:ID", id))
+	defer mw.logger.Info("called Pages", logging.ErrV(err))
+	return mw.next.Pages(ctx, id)
+}
+
+func (mw loggingMiddleware) EditPages(ctx context.Context, id string, edit PageEdit) (d Document, err error) {
+	mw.logger.Info("EditPages", logging.LogV("param:ID", id), logging.LogV("param:rotate", edit.Rotate), logging.LogV("param:degrees", fmt.Sprintf("%d", edit.Degrees)), logging.LogV("param:remove", edit.Remove))
+	defer mw.logger.Info("called EditPages", logging.ErrV(err))
+	return mw.next.EditPages(ctx, id, edit)
+}
+
+func (mw loggingMiddleware) AppendUpload(ctx context.Context, id, uploadToken string) (d Document, err error) {
+	mw.logger.Info("AppendUpload", logging.LogV("param:ID", id), logging.LogV("param:uploadToken", uploadToken))
+	defer mw.logger.Info("called AppendUpload", logging.ErrV(err))
+	return mw.next.AppendUpload(ctx, id, uploadToken)
+}
+
+func (mw loggingMiddleware) SplitDocument(ctx context.Context, id, ranges string) (d []Document, err error) {
+	mw.logger.Info("SplitDocument", logging.LogV("param:ID", id), logging.LogV("param:ranges", ranges))
+	defer mw.logger.Info("called SplitDocument", logging.ErrV(err))
+	return mw.next.SplitDocument(ctx, id, ranges)
+}
+
+func (mw loggingMiddleware) MergeDocuments(ctx context.Context, ids []string, title string) (d Document, err error) {
+	mw.logger.Info("MergeDocuments", logging.LogV("param:ids", strings.Join(ids, ",")), logging.LogV("param:title", title))
+	defer mw.logger.Info("called MergeDocuments", logging.ErrV(err))
+	return mw.next.MergeDocuments(ctx, ids, title)
 }
@@ -1,8 +1,10 @@
 package document_test
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"io"
 	"testing"
 	"time"
 
@@ -48,6 +50,7 @@ type mockRepository struct {
 	fail      bool
 	errMap    map[int]error
 	callCount int
+	previews  map[string]sql.NullString
 }
 
 func newDocRepo(c shared.Connection) *mockRepository {
@@ -57,7 +60,7 @@ func newDocRepo(c shared.Connection) *mockRepository {
 	}
 }
 
-func (m *mockRepository) Get(id string) (d document.DocEntity, err error) {
+func (m *mockRepository) Get(ctx context.Context, id string, a shared.Atomic) (d document.DocEntity, err error) {
 	m.callCount++
 	if id == "" {
 		return document.DocEntity{}, fmt.Errorf("no document")
@@ -69,12 +72,12 @@ func (m *mockRepository) Get(id string) (d document.DocEntity, err error) {
 	}, m.errMap[m.callCount]
 }
 
-func (m *mockRepository) Save(doc document.DocEntity, a shared.Atomic) (d document.DocEntity, err error) {
+func (m *mockRepository) Save(ctx context.Context, doc document.DocEntity, a shared.Atomic) (d document.DocEntity, err error) {
 	m.callCount++
 	return doc, m.errMap[m.callCount]
 }
 
-func (m *mockRepository) Delete(id string, a shared.Atomic) (err error) {
+func (m *mockRepository) Delete(ctx context.Context, id string, a shared.Atomic) (err error) {
 	m.callCount++
 	if id == noDelete {
 		return fmt.Errorf("delete error")
@@ -82,9 +85,18 @@ func (m *mockRepository) Delete(id string, a shared.Atomic) (err error) {
 	return m.errMap[m.callCount]
 }
 
-func (m *mockRepository) Search(s document.DocSearch, order []document.OrderBy) (document.PagedDocResult, error) {
+func (m *mockRepository) UpdatePreview(ctx context.Context, id string, previewLink sql.NullString, a shared.Atomic) (err error) {
 	m.callCount++
-	if s.Title == noResult {
+	if m.previews == nil {
+		m.previews = make(map[string]sql.NullString)
+	}
+	m.previews[id] = previewLink
+	return m.errMap[m.callCount]
+}
+
+func (m *mockRepository) Search(ctx context.Context, s document.DocSearch, order []document.OrderBy) (document.PagedDocResult, error) {
+	m.callCount++
+	if searchText(s) == noResult {
 		return document.PagedDocResult{}, fmt.Errorf("search error")
 	}
 
@@ -94,7 +106,8 @@ func (m *mockRepository) Search(s document.DocSearch, order []document.OrderBy)
 			{
 				Title:       "title1",
 				FileName:    "filename1",
-				Amount:      1,
+				AmountMinor: sql.NullInt64{Int64: 100, Valid: true},
+				Currency:    sql.NullString{String: "EUR", Valid: true},
 				TagList:     "taglist1",
 				SenderList:  "senderlist1",
 				PreviewLink: sql.NullString{String: "previewlink", Valid: true},
@@ -102,18 +115,58 @@ func (m *mockRepository) Search(s document.DocSearch, order []document.OrderBy)
 				Modified:    sql.NullTime{Time: time.Now().UTC(), Valid: true},
 			},
 			{
-				Title:      "title2",
-				FileName:   "filename2",
-				Amount:     2,
-				TagList:    "taglist2",
-				SenderList: "senderlist2",
-				Created:    time.Now().UTC(),
+				ID:          "id2",
+				Title:       "title2",
+				FileName:    "filename2",
+				AmountMinor: sql.NullInt64{Int64: 200, Valid: true},
+				Currency:    sql.NullString{String: "EUR", Valid: true},
+				TagList:     "taglist2",
+				SenderList:  "senderlist2",
+				Created:     time.Now().UTC(),
 			},
 		},
 	}, nil
 }
 
-func (m *mockRepository) Exists(id string, a shared.Atomic) (filePath string, err error) {
+// searchText returns the text of the search query
+func searchText(s document.DocSearch) string {
+	for _, f := range s.Filters {
+		if f.Field == document.TextField {
+			return f.Text
+		}
+	}
+	return s.Title
+}
+
+func (m *mockRepository) UpdateContentHash(ctx context.Context, id string, hash sql.NullString, a shared.Atomic) (err error) {
+	m.callCount++
+	return m.errMap[m.callCount]
+}
+
+func (m *mockRepository) SearchContentHash(ctx context.Context, hash string) ([]document.DocEntity, error) {
+	m.callCount++
+	return nil, m.errMap[m.callCount]
+}
+
+func (m *mockRepository) SearchDuplicates(ctx context.Context) ([]document.DocEntity, error) {
+	m.callCount++
+	return nil, m.errMap[m.callCount]
+}
+
+func (m *mockRepository) SearchAmounts(ctx context.Context, s document.DocSearch) ([]document.AmountEntity, error) {
+	m.callCount++
+	if searchText(s) == noResult {
+		return nil, fmt.Errorf("search error")
+	}
+	return []document.AmountEntity{
+		{AmountMinor: 1050, Currency: "EUR", Created: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), TagList: "tax;house", SenderList: "office"},
+		{AmountMinor: 250, Currency: "EUR", Created: time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC), TagList: "tax", SenderList: "shop"},
+		{AmountMinor: 3000, Currency: "EUR", Created: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), TagList: "house", SenderList: "office"},
+		{AmountMinor: 999, Currency: "USD", Created: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), TagList: "travel", SenderList: "airline"},
+	}, nil
+}
+
+func (m *mockRepository) Exists(ctx context.Context, id string, a shared.Atomic) (filePath string, err error) {
 	m.callCount++
 	if id == notExists {
 		return "", fmt.Errorf("exists error")
@@ -132,13 +185,33 @@ func (m *mockRepository) CreateAtomic() (shared.Atomic, error) {
 	return m.c.CreateAtomic()
 }
 
-func (m *mockRepository) SearchLists(s string, st document.SearchType) ([]string, error) {
+func (m *mockRepository) SearchLists(ctx context.Context, s string, st document.SearchType) ([]string, error) {
 	if m.fail {
 		return nil, Err
 	}
 	return []string{"one", "two"}, nil
 }
 
+func (m *mockRepository) ListItems(ctx context.Context, st document.SearchType) ([]document.ListEntity, error) {
+	m.callCount++
+	return nil, m.errMap[m.callCount]
+}
+
+func (m *mockRepository) RenameListItem(ctx context.Context, st document.SearchType, id, name string, a shared.Atomic) (err error) {
+	m.callCount++
+	return m.errMap[m.callCount]
+}
+
+func (m *mockRepository) MergeListItems(ctx context.Context, st document.SearchType, targetID string, ids []string, a shared.Atomic) (err error) {
+	m.callCount++
+	return m.errMap[m.callCount]
+}
+
+func (m *mockRepository) DeleteListItem(ctx context.Context, st document.SearchType, id string, a shared.Atomic) (err error) {
+	m.callCount++
+	return m.errMap[m.callCount]
+}
+
 func GetMockConn(t *testing.T) (shared.Connection, *sql.DB, sqlmock.Sqlmock) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -158,6 +231,9 @@ func GetMockConn(t *testing.T) (shared.Connection, *sql.DB, sqlmock.Sqlmock) {
 type mockFileService struct {
 	errMap    map[int]error
 	callCount int
+	payload   []byte
+	saved     []filestore.FileItem
+	deleted   []string
 }
 
 func newFileService() *mockFileService {
@@ -176,22 +252,100 @@ func (m *mockFileService) InitClient() (err error) {
 	return m.errMap[m.callCount]
 }
 
-func (m *mockFileService) SaveFile(file filestore.FileItem) error {
+func (m *mockFileService) SaveFile(ctx context.Context, file filestore.FileItem) error {
 	m.callCount++
+	m.saved = append(m.saved, file)
 	return m.errMap[m.callCount]
 }
 
-func (m *mockFileService) GetFile(filePath string) (filestore.FileItem, error) {
+func (m *mockFileService) GetFile(ctx context.Context, filePath string) (filestore.FileItem, error) {
 	m.callCount++
+	payload := []byte(pdfPayload)
+	if m.payload != nil {
+		payload = m.payload
+	}
 	return filestore.FileItem{
 		FileName:   "test.pdf",
 		FolderName: "PATH",
 		MimeType:   "application/pdf",
-		Payload:    []byte(pdfPayload),
+		Payload:    payload,
 	}, m.errMap[m.callCount]
 }
 
-func (m *mockFileService) DeleteFile(filePath string) error {
+func (m *mockFileService) StreamFile(ctx context.Context, filePath string, w io.Writer) (filestore.FileItem, error) {
+	item, err := m.GetFile(ctx, filePath)
+	if err != nil {
+		return filestore.FileItem{}, err
+	}
+	_, err = w.Write(item.Payload)
+	item.Payload = nil
+	return item, err
+}
+
+func (m *mockFileService) DeleteFile(ctx context.Context, filePath string) error {
 	m.callCount++
+	m.deleted = append(m.deleted, filePath)
 	return m.errMap[m.callCount]
 }
+
+func (m *mockFileService) CheckBucket(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockFileService) ListFiles(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func (m *mockFileService) GetFileInfo(ctx context.Context, filePath string) (filestore.FileItem, error) {
+	return filestore.FileItem{}, nil
+}
+
+func (m *mockFileService) UpdateMetadata(ctx context.Context, filePath string, metadata map[string]string) error {
+	return nil
+}
+
+// --------------------------------------------------------------------------
+// MOCK: document.Classifier
+// --------------------------------------------------------------------------
+
+// mockClassifier adds a tag and records the classified payload
+type mockClassifier struct {
+	payload []byte
+}
+
+func (m *mockClassifier) Classify(ctx context.Context, doc document.Document, payload []byte) document.Document {
+	m.payload = payload
+	doc.Tags = append(doc.Tags, "classified")
+	doc.Title = "<script>alert('x')</script>classified"
+	return doc
+}
+
+func (m *mockRepository) SearchDue(ctx context.Context, includeDone bool) ([]document.DocEntity, error) {
+	m.callCount++
+	return nil, m.errMap[m.callCount]
+}
+
+func (m *mockRepository) SearchReminders(ctx context.Context, day time.Time) ([]document.DocEntity, error) {
+	m.callCount++
+	return nil, m.errMap[m.callCount]
+}
+
+func (m *mockRepository) UpdateDone(ctx context.Context, id string, done sql.NullTime, a shared.Atomic) (err error) {
+	m.callCount++
+	return m.errMap[m.callCount]
+}
+
+func (m *mockRepository) UpdateReminded(ctx context.Context, id string, reminded sql.NullTime, a shared.Atomic) (err error) {
+	m.callCount++
+	return m.errMap[m.callCount]
+}
+
+func (m *mockRepository) Revisions(ctx context.Context, documentID string) ([]document.RevisionEntity, error) {
+	m.callCount++
+	return nil, m.errMap[m.callCount]
+}
+
+func (m *mockRepository) SaveRevision(ctx context.Context, rev document.RevisionEntity, a shared.Atomic) (document.RevisionEntity, error) {
+	m.callCount++
+	return rev, m.errMap[m.callCount]
+}
@@ -2,6 +2,7 @@ package document_test
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"testing"
@@ -10,8 +11,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	"golang.binggl.net/monorepo/internal/common/upload"
 	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/internal/mydms/app/preview"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
 	"golang.binggl.net/monorepo/pkg/logging"
 	"golang.binggl.net/monorepo/pkg/security"
+	"golang.binggl.net/monorepo/pkg/text"
 )
 
 var logger = logging.NewNop()
@@ -27,15 +31,15 @@ var uploadSettings = upload.ServiceOptions{
 var uploadSvc = upload.NewService(uploadSettings)
 
 func Test_GetDocumentByID(t *testing.T) {
-	svc := document.NewService(logger, &mockRepository{}, nil, nil)
-	doc, err := svc.GetDocumentByID("id")
+	svc := document.NewService(logger, &mockRepository{}, nil, nil, nil, nil)
+	doc, err := svc.GetDocumentByID(context.TODO(), "id")
 	if err != nil {
 		t.Errorf("could not get document by id 'id'; %v", err)
 	}
 	assert.Equal(t, "id", doc.ID)
 
 	// no document found
-	doc, err = svc.GetDocumentByID("")
+	doc, err = svc.GetDocumentByID(context.TODO(), "")
 	if err == nil {
 		t.Errorf("should return ErrNotFound")
 	}
@@ -46,13 +50,13 @@ func Test_DeleteDocumentByID(t *testing.T) {
 	defer db.Close()
 
 	fileSvc := newFileService()
-	svc := document.NewService(logger, newDocRepo(c), fileSvc, nil)
+	svc := document.NewService(logger, newDocRepo(c), fileSvc, nil, nil, nil)
 
 	// straight
 	mock.ExpectBegin()
 	mock.ExpectCommit()
 
-	err := svc.DeleteDocumentByID("id")
+	err := svc.DeleteDocumentByID(context.TODO(), "id")
 	if err != nil {
 		t.Errorf("could not delete document by id 'id'; %v", err)
 	}
@@ -61,7 +65,7 @@ func Test_DeleteDocumentByID(t *testing.T) {
 	mock.ExpectBegin()
 	mock.ExpectRollback()
 
-	err = svc.DeleteDocumentByID("!exists")
+	err = svc.DeleteDocumentByID(context.TODO(), "!exists")
 	if err == nil {
 		t.Errorf("error expected for delete without found ID")
 	}
@@ -70,7 +74,7 @@ func Test_DeleteDocumentByID(t *testing.T) {
 	mock.ExpectBegin()
 	mock.ExpectRollback()
 
-	err = svc.DeleteDocumentByID("!delete")
+	err = svc.DeleteDocumentByID(context.TODO(), "!delete")
 	if err == nil {
 		t.Errorf("error expected for delete")
 	}
@@ -82,7 +86,7 @@ func Test_DeleteDocumentByID(t *testing.T) {
 	fileSvc.callCount = 0
 	fileSvc.errMap[1] = fmt.Errorf("error")
 
-	err = svc.DeleteDocumentByID("id")
+	err = svc.DeleteDocumentByID(context.TODO(), "id")
 	if err == nil {
 		t.Errorf("error expected for delete")
 	}
@@ -94,36 +98,61 @@ func Test_DeleteDocumentByID(t *testing.T) {
 }
 
 func Test_SearchDocuments(t *testing.T) {
-	svc := document.NewService(logger, &mockRepository{}, nil, nil)
-	pd, err := svc.SearchDocuments("", "", "", time.Now(), time.Time{}, 0, 0)
+	svc := document.NewService(logger, &mockRepository{}, nil, nil, nil, nil)
+	pd, err := svc.SearchDocuments(context.TODO(), "", "", "", time.Now(), time.Time{}, 0, 0)
 	if err != nil {
 		t.Errorf("error searching documents; %v", err)
 	}
 	assert.Equal(t, 2, pd.TotalEntries)
 
-	_, err = svc.SearchDocuments("!result", "", "", time.Now(), time.Time{}, 20, 0)
+	_, err = svc.SearchDocuments(context.TODO(), "!result", "", "", time.Now(), time.Time{}, 20, 0)
 	if err == nil {
 		t.Errorf("search error expected, nothing found")
 	}
 }
 
+func Test_Search(t *testing.T) {
+	repo := sqliteRepo(t)
+	svc := document.NewService(logger, repo, nil, nil, nil, nil)
+	for _, title := range []string{"b", "c", "a"} {
+		saveEntity(t, repo, document.DocEntity{Title: title, FileName: "/" + title + ".pdf", TagList: "tax"})
+	}
+
+	pd, err := svc.Search(context.TODO(), document.DocSearch{Tag: "tax", Limit: 2}, []document.OrderBy{{Field: "title", Order: document.ASC}})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, pd.TotalEntries)
+	assert.Equal(t, []string{"a", "b"}, []string{pd.Documents[0].Title, pd.Documents[1].Title})
+	pd, err = svc.Search(context.TODO(), document.DocSearch{Skip: 2}, []document.OrderBy{{Field: "title", Order: document.DESC}})
+	assert.NoError(t, err)
+	assert.Len(t, pd.Documents, 1)
+	assert.Equal(t, "a", pd.Documents[0].Title)
+
+	var validation *shared.ValidationError
+	_, err = svc.Search(context.TODO(), document.DocSearch{}, []document.OrderBy{{Field: "title; DROP TABLE DOCUMENTS"}})
+	assert.ErrorAs(t, err, &validation)
+	_, err = svc.Search(context.TODO(), document.DocSearch{Limit: document.MaxSearchLimit + 1}, nil)
+	assert.ErrorAs(t, err, &validation)
+	_, err = svc.Search(context.TODO(), document.DocSearch{Skip: -1}, nil)
+	assert.ErrorAs(t, err, &validation)
+}
+
 func Test_SearchList(t *testing.T) {
-	svc := document.NewService(logger, &mockRepository{}, nil, nil)
-	l, err := svc.SearchList("name", document.SENDERS)
+	svc := document.NewService(logger, &mockRepository{}, nil, nil, nil, nil)
+	l, err := svc.SearchList(context.TODO(), "name", document.SENDERS)
 	if err != nil {
 		t.Errorf("error searching for list '%s'; %v", "name", err)
 	}
 	assert.True(t, len(l) == 2)
 
-	l, err = svc.SearchList("name", document.TAGS)
+	l, err = svc.SearchList(context.TODO(), "name", document.TAGS)
 	if err != nil {
 		t.Errorf("error searching for list '%s'; %v", "name", err)
 	}
 	assert.True(t, len(l) == 2)
 
 	// fail
-	svc = document.NewService(logger, &mockRepository{fail: true}, nil, nil)
-	_, err = svc.SearchList("name", document.SENDERS)
+	svc = document.NewService(logger, &mockRepository{fail: true}, nil, nil, nil, nil)
+	_, err = svc.SearchList(context.TODO(), "name", document.SENDERS)
 	if err == nil {
 		t.Errorf("error expected")
 	}
@@ -136,7 +165,7 @@ func Test_SaveDocument(t *testing.T) {
 	defer db.Close()
 
 	fileSvc := newFileService()
-	svc := document.NewService(logger, newDocRepo(c), fileSvc, uploadSvc)
+	svc := document.NewService(logger, newDocRepo(c), fileSvc, uploadSvc, nil, nil)
 
 	// test a blank / new document
 	// ------------------------------------------------------------------
@@ -163,7 +192,7 @@ func Test_SaveDocument(t *testing.T) {
 		t.Fatalf("could not write file: %v", err)
 	}
 
-	doc, err := svc.SaveDocument(document.Document{
+	doc, err := svc.SaveDocument(context.TODO(), document.Document{
 		UploadToken: id,
 		Title:       "New-Document",
 		Tags:        []string{"A", "B"},
@@ -174,9 +203,168 @@ func Test_SaveDocument(t *testing.T) {
 	}
 	// clean input via policy
 	assert.Equal(t, "New-Document", doc.Title)
+	assert.Equal(t, document.ContentHash(payload), doc.ContentHash)
 
 	// we make sure that all expectations were met
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf(expectations, err)
 	}
 }
+
+func Test_SaveDocument_Classifier(t *testing.T) {
+	c, db, mock := GetMockConn(t)
+	defer db.Close()
+
+	classifier := &mockClassifier{}
+	svc := document.NewService(logger, newDocRepo(c), newFileService(), uploadSvc, nil, classifier)
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	payload, err := os.ReadFile(unencryptedPDF)
+	if err != nil {
+		t.Fatalf("could not read testfile: %v", err)
+	}
+	id, err := uploadSvc.Save(upload.File{
+		File:     bytes.NewBuffer(payload),
+		MimeType: "application/pdf",
+		Name:     "unencrypted.pdf",
+		Size:     int64(len(payload)),
+	})
+	if err != nil {
+		t.Fatalf("could not write file: %v", err)
+	}
+
+	doc, err := svc.SaveDocument(context.TODO(), document.Document{
+		UploadToken: id,
+		Title:       "New-Document",
+		Tags:        []string{"A"},
+		Senders:     []string{"Sender"},
+	}, security.User{})
+	assert.NoError(t, err)
+	assert.Equal(t, payload, classifier.payload)
+	assert.Equal(t, []string{"A", "classified"}, doc.Tags)
+	// the classified values are sanitized
+	assert.Equal(t, "classified", doc.Title)
+
+	// existing documents are not classified
+	classifier.payload = nil
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	doc, err = svc.SaveDocument(context.TODO(), document.Document{
+		ID:       "id",
+		Title:    "Document",
+		FileName: "/2024_01_01/document.pdf",
+		Tags:     []string{"A"},
+		Senders:  []string{"Sender"},
+	}, security.User{})
+	assert.NoError(t, err)
+	assert.Nil(t, classifier.payload)
+	assert.Equal(t, []string{"A"}, doc.Tags)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf(expectations, err)
+	}
+}
+
+func Test_SaveDocument_Preview(t *testing.T) {
+	c, db, mock := GetMockConn(t)
+	defer db.Close()
+
+	fileSvc := newFileService()
+	svc := document.NewService(logger, newDocRepo(c), fileSvc, uploadSvc, preview.NewGenerator(preview.Options{}), nil)
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	payload, err := os.ReadFile(unencryptedPDF)
+	if err != nil {
+		t.Fatalf("could not read testfile: %v", err)
+	}
+	id, err := uploadSvc.Save(upload.File{
+		File:     bytes.NewBuffer(payload),
+		MimeType: "application/pdf",
+		Name:     "unencrypted.pdf",
+		Size:     int64(len(payload)),
+	})
+	if err != nil {
+		t.Fatalf("could not write file: %v", err)
+	}
+
+	doc, err := svc.SaveDocument(context.TODO(), document.Document{
+		UploadToken: id,
+		FileName:    "unencrypted.pdf",
+		Title:       "Preview",
+	}, security.User{})
+	assert.NoError(t, err)
+
+	// the document and the preview are stored in the same folder
+	assert.Len(t, fileSvc.saved, 2)
+	thumbnail := fileSvc.saved[1]
+	assert.Equal(t, fileSvc.saved[0].FolderName, thumbnail.FolderName)
+	assert.Equal(t, "unencrypted.pdf.preview.jpg", thumbnail.FileName)
+	assert.Equal(t, "image/jpeg", thumbnail.MimeType)
+	assert.Equal(t, text.EncBase64SafePath("/"+thumbnail.String()), doc.PreviewLink)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf(expectations, err)
+	}
+}
+
+func Test_GeneratePreviews(t *testing.T) {
+	payload, err := os.ReadFile(unencryptedPDF)
+	if err != nil {
+		t.Fatalf("could not read testfile: %v", err)
+	}
+	repo := &mockRepository{}
+	fileSvc := newFileService()
+	fileSvc.payload = payload
+	svc := document.NewService(logger, repo, fileSvc, nil, preview.NewGenerator(preview.Options{}), nil)
+
+	// only the second document of the mock has no preview
+	result, err := svc.GeneratePreviews(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, document.PreviewResult{Created: 1}, result)
+	assert.Len(t, repo.previews, 1)
+	assert.True(t, repo.previews["id2"].Valid)
+	assert.Equal(t, "filename2.preview.jpg", fileSvc.saved[0].FileName)
+
+	// the payload of the document does not provide a preview
+	fileSvc.payload = []byte("some text")
+	result, err = svc.GeneratePreviews(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, document.PreviewResult{Failed: 1}, result)
+
+	_, err = document.NewService(logger, repo, fileSvc, nil, nil, nil).GeneratePreviews(context.TODO())
+	assert.Error(t, err)
+}
+
+func Test_SpendingReport(t *testing.T) {
+	svc := document.NewService(logger, &mockRepository{}, nil, nil, nil, nil)
+	r, err := svc.SpendingReport(context.TODO(), "", "", "", time.Time{}, time.Time{}, "")
+	assert.NoError(t, err)
+
+	// the default currency is used, the other currencies are listed
+	assert.Equal(t, "EUR", r.Currency)
+	assert.Equal(t, []string{"EUR", "USD"}, r.Currencies)
+	assert.Equal(t, 3, r.Count)
+	assert.Equal(t, "43.00", r.Total.Decimal())
+
+	assert.Equal(t, []document.ReportEntry{
+		{Key: "office", Total: document.Money{Minor: 4050, Currency: "EUR"}, Count: 2},
+		{Key: "shop", Total: document.Money{Minor: 250, Currency: "EUR"}, Count: 1},
+	}, r.BySender)
+	assert.Equal(t, "house", r.ByTag[0].Key)
+	assert.Equal(t, int64(4050), r.ByTag[0].Total.Minor)
+	assert.Equal(t, []string{"2024-01", "2025-03"}, []string{r.ByMonth[0].Key, r.ByMonth[1].Key})
+	assert.Equal(t, int64(1300), r.ByMonth[0].Total.Minor)
+	assert.Len(t, r.ByYear, 2)
+
+	r, err = svc.SpendingReport(context.TODO(), "", "", "", time.Time{}, time.Time{}, "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, r.Count)
+	assert.Equal(t, "9.99 USD", r.Total.String())
+
+	_, err = svc.SpendingReport(context.TODO(), "!result", "", "", time.Time{}, time.Time{}, "")
+	assert.Error(t, err)
+}
@@ -33,7 +33,7 @@ type Document struct {
 	ID            string   `json:"id"`
 	Title         string   `json:"title"`
 	AltID         string   `json:"alternativeId"`
-	Amount        float32  `json:"amount"`
+	Amount        Money    `json:"amount,omitzero"`
 	Created       string   `json:"created"`
 	Modified      string   `json:"modified,omitempty"`
 	FileName      string   `json:"fileName"`
@@ -42,6 +42,17 @@ type Document struct {
 	Tags          []string `json:"tags"`
 	Senders       []string `json:"senders"`
 	InvoiceNumber string   `json:"invoiceNumber,omitempty"`
+	// NeedsReview marks drafts which were not yet completed by the user
+	NeedsReview bool `json:"needsReview,omitempty"`
+	// ContentHash is the SHA-256 hash of the file, identical files have the same hash
+	ContentHash string `json:"contentHash,omitempty"`
+	// DueDate is the day of the payment, the end of the contract or the follow-up, formatted as DateLayout
+	DueDate string `json:"dueDate,omitempty"`
+	// ReminderDate is the day of the reminder, without a reminder date the reminder is sent on the due date
+	ReminderDate string  `json:"reminderDate,omitempty"`
+	DueType      DueType `json:"dueType,omitempty"`
+	// Done is the time the document was marked as paid or done
+	Done string `json:"done,omitempty"`
 }
 
 func (d Document) String() string {
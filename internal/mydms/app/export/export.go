@@ -0,0 +1,165 @@
+// Package export writes the documents of a search as CSV or XLSX index or as ZIP archive of the files.
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+)
+
+// Format of the export
+type Format string
+
+const (
+	// CSV is the index of the documents as comma-separated values
+	CSV Format = "csv"
+	// XLSX is the index of the documents as spreadsheet
+	XLSX Format = "xlsx"
+	// ZIP is an archive of the document files and the index
+	ZIP Format = "zip"
+)
+
+// ParseFormat returns the format of the given name
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case CSV, XLSX, ZIP:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown export format '%s'", name)
+}
+
+// ContentType is the mime-type of the format
+func (f Format) ContentType() string {
+	switch f {
+	case XLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case ZIP:
+		return "application/zip"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Filter holds the criteria of the document search
+type Filter struct {
+	// Query is the structured query of the search, see document.ParseQuery
+	Query  string
+	Tag    string
+	Sender string
+	From   time.Time
+	Until  time.Time
+}
+
+// the documents are fetched in pages
+const pageSize = 100
+
+// Search returns all documents matching the filter, not only a single page of the search
+func Search(ctx context.Context, svc document.Service, f Filter) ([]document.Document, error) {
+	var docs []document.Document
+	for skip := 0; ; skip += pageSize {
+		page, err := svc.SearchDocuments(ctx, f.Query, f.Tag, f.Sender, f.From, f.Until, pageSize, skip)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, page.Documents...)
+		if len(page.Documents) < pageSize || len(docs) >= page.TotalEntries {
+			return docs, nil
+		}
+	}
+}
+
+var indexHeader = []string{"Date", "Title", "Senders", "Tags", "Amount", "Currency", "InvoiceNumber", "File", "ID"}
+
+// indexRow returns the metadata of the document, file is the name of the document in the export
+func indexRow(doc document.Document, file string) []string {
+	amount, currency := "", ""
+	if !doc.Amount.IsZero() {
+		amount, currency = doc.Amount.Decimal(), doc.Amount.Currency
+	}
+	return []string{
+		documentDate(doc),
+		doc.Title,
+		strings.Join(doc.Senders, ", "),
+		strings.Join(doc.Tags, ", "),
+		amount,
+		currency,
+		doc.InvoiceNumber,
+		file,
+		doc.ID,
+	}
+}
+
+// WriteCSV writes the index of the documents
+func WriteCSV(w io.Writer, docs []document.Document) error {
+	return writeCSV(w, docs, func(doc document.Document) string { return doc.FileName })
+}
+
+func writeCSV(w io.Writer, docs []document.Document, file func(document.Document) string) error {
+	c := csv.NewWriter(w)
+	if err := c.Write(indexHeader); err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		if err := c.Write(csvRow(indexRow(doc, file(doc)))); err != nil {
+			return err
+		}
+	}
+	c.Flush()
+	return c.Error()
+}
+
+// csvRow prefixes values which spreadsheet applications would evaluate as formula with a quote,
+// the amount is a number and is not changed
+func csvRow(values []string) []string {
+	for i, v := range values {
+		if i != amountColumn && v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+			values[i] = "'" + v
+		}
+	}
+	return values
+}
+
+// documentDate is the creation date of the document, e.g. 2024-12-31
+func documentDate(doc document.Document) string {
+	if len(doc.Created) < 10 {
+		return doc.Created
+	}
+	return doc.Created[:10]
+}
+
+var unsafeChars = regexp.MustCompile(`[^\p{L}\p{N}.-]+`)
+
+func safeName(s string) string {
+	return strings.Trim(unsafeChars.ReplaceAllString(s, "_"), "_.")
+}
+
+// FileName derives the name of the document in the archive from date, sender and title
+func FileName(doc document.Document) string {
+	parts := []string{documentDate(doc)}
+	if len(doc.Senders) > 0 {
+		parts = append(parts, doc.Senders[0])
+	}
+	parts = append(parts, doc.Title)
+
+	var names []string
+	for _, p := range parts {
+		if n := safeName(p); n != "" {
+			names = append(names, n)
+		}
+	}
+	name := strings.Join(names, "_")
+	if name == "" {
+		name = "document"
+	}
+	ext := strings.ToLower(path.Ext(doc.FileName))
+	if ext == "" {
+		ext = ".pdf"
+	}
+	return name + ext
+}
@@ -0,0 +1,203 @@
+package export_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/internal/mydms/app/export"
+	"golang.binggl.net/monorepo/internal/mydms/app/filestore"
+	"golang.binggl.net/monorepo/pkg/logging"
+)
+
+// mockDocumentService only implements the search of documents
+type mockDocumentService struct {
+	document.Service
+	docs  []document.Document
+	calls int
+}
+
+func (m *mockDocumentService) SearchDocuments(ctx context.Context, title, tag, sender string, from, until time.Time, limit, skip int) (document.PagedDocument, error) {
+	m.calls++
+	end := min(skip+limit, len(m.docs))
+	return document.PagedDocument{
+		TotalEntries: len(m.docs),
+		Documents:    m.docs[skip:end],
+	}, nil
+}
+
+// mockFileService provides the files of the given map
+type mockFileService struct {
+	filestore.FileService
+	files map[string][]byte
+}
+
+func (m *mockFileService) GetFileInfo(ctx context.Context, filePath string) (filestore.FileItem, error) {
+	if _, ok := m.files[filePath]; !ok {
+		return filestore.FileItem{}, fmt.Errorf("file '%s' not found", filePath)
+	}
+	return filestore.FileItem{MimeType: "application/pdf"}, nil
+}
+
+func (m *mockFileService) StreamFile(ctx context.Context, filePath string, w io.Writer) (filestore.FileItem, error) {
+	item, err := m.GetFileInfo(ctx, filePath)
+	if err != nil {
+		return item, err
+	}
+	_, err = w.Write(m.files[filePath])
+	return item, err
+}
+
+func testDocuments() []document.Document {
+	return []document.Document{
+		{
+			ID:       "1",
+			Title:    "Invoice: March",
+			FileName: "/2024_03_01/invoice.pdf",
+			Created:  "2024-03-01T10:00:00Z",
+			Senders:  []string{"Power & Co", "Other"},
+			Tags:     []string{"energy", "tax"},
+			Amount:   document.Money{Minor: 1234, Currency: "EUR"},
+		},
+		{
+			ID:       "2",
+			Title:    "Invoice: March",
+			FileName: "/2024_03_01/invoice_2.pdf",
+			Created:  "2024-03-01T12:00:00Z",
+			Senders:  []string{"Power & Co"},
+		},
+		{
+			ID:       "3",
+			Title:    "Contract",
+			FileName: "/2024_04_01/missing.pdf",
+			Created:  "2024-04-01T12:00:00Z",
+		},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := export.ParseFormat("XLSX")
+	assert.NoError(t, err)
+	assert.Equal(t, export.XLSX, f)
+	assert.Equal(t, "application/zip", export.ZIP.ContentType())
+
+	_, err = export.ParseFormat("pdf")
+	assert.Error(t, err)
+}
+
+func TestFileName(t *testing.T) {
+	docs := testDocuments()
+	assert.Equal(t, "2024-03-01_Power_Co_Invoice_March.pdf", export.FileName(docs[0]))
+	assert.Equal(t, "2024-04-01_Contract.pdf", export.FileName(docs[2]))
+	assert.Equal(t, "document.pdf", export.FileName(document.Document{}))
+	assert.Equal(t, "2024-01-01_scan.png", export.FileName(document.Document{Created: "2024-01-01T00:00:00Z", Title: "../scan", FileName: "a.PNG"}))
+}
+
+func TestSearch(t *testing.T) {
+	var docs []document.Document
+	for i := range 250 {
+		docs = append(docs, document.Document{ID: fmt.Sprintf("%d", i)})
+	}
+	svc := &mockDocumentService{docs: docs}
+
+	result, err := export.Search(context.TODO(), svc, export.Filter{})
+	assert.NoError(t, err)
+	assert.Len(t, result, 250)
+	assert.Equal(t, 3, svc.calls)
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, export.WriteCSV(&buf, testDocuments()))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 4)
+	assert.Equal(t, "Date", records[0][0])
+	assert.Equal(t, []string{"2024-03-01", "Invoice: March", "Power & Co, Other", "energy, tax", "12.34", "EUR", "", "/2024_03_01/invoice.pdf", "1"}, records[1])
+	assert.Equal(t, "", records[2][4])
+}
+
+func TestWriteXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, export.WriteXLSX(&buf, testDocuments()))
+
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	sheet := readZipFile(t, z, "xl/worksheets/sheet1.xml")
+	assert.NotEmpty(t, readZipFile(t, z, "[Content_Types].xml"))
+	assert.NotEmpty(t, readZipFile(t, z, "xl/workbook.xml"))
+
+	assert.Contains(t, sheet, `<c r="E2"><v>12.34</v></c>`)
+	assert.Contains(t, sheet, "Power &amp; Co, Other")
+	assert.Contains(t, sheet, `<row r="4">`)
+}
+
+func TestWriteFormulas(t *testing.T) {
+	docs := []document.Document{{
+		ID:       "1",
+		Title:    "=HYPERLINK(\"https://example.com\")",
+		Senders:  []string{"@sender"},
+		Tags:     []string{"-tag", "+tag"},
+		FileName: "/2024_03_01/invoice.pdf",
+		Amount:   document.Money{Minor: -500, Currency: "EUR"},
+	}}
+
+	var buf bytes.Buffer
+	assert.NoError(t, export.WriteCSV(&buf, docs))
+	records, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "'=HYPERLINK(\"https://example.com\")", "'@sender", "'-tag, +tag", "-5.00", "EUR", "", "/2024_03_01/invoice.pdf", "1"}, records[1])
+
+	buf.Reset()
+	assert.NoError(t, export.WriteXLSX(&buf, docs))
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	sheet := readZipFile(t, z, "xl/worksheets/sheet1.xml")
+	assert.Contains(t, sheet, `<c r="B2" t="inlineStr"><is><t xml:space="preserve">=HYPERLINK(&#34;https://example.com&#34;)</t></is></c>`)
+	assert.NotContains(t, sheet, "<f>")
+}
+
+func TestWriteZIP(t *testing.T) {
+	files := &mockFileService{files: map[string][]byte{
+		"/2024_03_01/invoice.pdf":   []byte("first"),
+		"/2024_03_01/invoice_2.pdf": []byte("second"),
+	}}
+
+	var buf bytes.Buffer
+	assert.NoError(t, export.WriteZIP(context.TODO(), &buf, testDocuments(), files, logging.NewNop()))
+
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	assert.Len(t, z.File, 3)
+	assert.Equal(t, "first", readZipFile(t, z, "2024-03-01_Power_Co_Invoice_March.pdf"))
+	assert.Equal(t, "second", readZipFile(t, z, "2024-03-01_Power_Co_Invoice_March-2.pdf"))
+
+	records, err := csv.NewReader(strings.NewReader(readZipFile(t, z, export.IndexFileName))).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 4)
+	assert.Equal(t, "2024-03-01_Power_Co_Invoice_March-2.pdf", records[2][7])
+	// the missing file is listed without a file
+	assert.Equal(t, "", records[3][7])
+}
+
+func readZipFile(t *testing.T, z *zip.Reader, name string) string {
+	f, err := z.Open(name)
+	if err != nil {
+		t.Fatalf("could not open '%s': %v", name, err)
+	}
+	defer f.Close()
+	b, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatalf("could not read '%s': %v", name, err)
+	}
+	return string(b)
+}
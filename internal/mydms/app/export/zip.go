@@ -0,0 +1,83 @@
+package export
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/internal/mydms/app/filestore"
+	"golang.binggl.net/monorepo/pkg/logging"
+)
+
+// IndexFileName is the name of the index within the ZIP archive
+const IndexFileName = "index.csv"
+
+// WriteZIP streams the files of the documents from the filestore into a ZIP archive and adds
+// the index of the documents. The response is already written when a file is not available,
+// therefore missing files are logged and listed without a file in the index. A file failing
+// after parts of it were written would corrupt the archive, the export is aborted.
+func WriteZIP(ctx context.Context, w io.Writer, docs []document.Document, files filestore.FileService, logger logging.Logger) error {
+	z := zip.NewWriter(w)
+	names := make(map[string]string, len(docs))
+	used := make(map[string]bool, len(docs))
+
+	for _, doc := range docs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// missing files are detected before the entry of the archive is created
+		if _, err := files.GetFileInfo(ctx, doc.FileName); err != nil {
+			logger.Warn(fmt.Sprintf("the file of document '%s' is not available for the export", doc.ID), logging.ErrV(err))
+			continue
+		}
+
+		name := uniqueName(FileName(doc), used)
+		f, err := z.CreateHeader(&zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: modified(doc),
+		})
+		if err != nil {
+			return err
+		}
+		if _, err = files.StreamFile(ctx, doc.FileName, f); err != nil {
+			return fmt.Errorf("could not add the file of document '%s'; %w", doc.ID, err)
+		}
+		names[doc.ID] = name
+	}
+
+	index, err := z.Create(IndexFileName)
+	if err != nil {
+		return err
+	}
+	if err = writeCSV(index, docs, func(doc document.Document) string { return names[doc.ID] }); err != nil {
+		return err
+	}
+	return z.Close()
+}
+
+// uniqueName appends a counter if documents of the same day, sender and title exist
+func uniqueName(name string, used map[string]bool) string {
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	unique := name
+	for i := 2; used[unique]; i++ {
+		unique = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+	used[unique] = true
+	return unique
+}
+
+func modified(doc document.Document) time.Time {
+	for _, ts := range []string{doc.Modified, doc.Created} {
+		if t, err := time.Parse(time.RFC3339, ts); err == nil {
+			return t
+		}
+	}
+	return time.Now()
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"net/url"
 	"strings"
 
 	"golang.binggl.net/monorepo/pkg/logging"
@@ -12,6 +14,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
 // --------------------------------------------------------------------------
@@ -24,6 +27,8 @@ type FileItem struct {
 	FolderName string
 	MimeType   string
 	Payload    []byte
+	// Metadata is stored alongside the object, the keys are lower-case
+	Metadata map[string]string
 }
 
 func (f FileItem) String() string {
@@ -33,9 +38,19 @@ func (f FileItem) String() string {
 // FileService defines an interface for backend file services
 type FileService interface {
 	InitClient() (err error)
-	SaveFile(file FileItem) (err error)
-	GetFile(filePath string) (item FileItem, err error)
-	DeleteFile(filePath string) (err error)
+	SaveFile(ctx context.Context, file FileItem) (err error)
+	GetFile(ctx context.Context, filePath string) (item FileItem, err error)
+	// StreamFile writes the payload of the file to w, the returned item has no payload
+	StreamFile(ctx context.Context, filePath string, w io.Writer) (item FileItem, err error)
+	DeleteFile(ctx context.Context, filePath string) (err error)
+	// CheckBucket verifies that the configured bucket is reachable
+	CheckBucket(ctx context.Context) (err error)
+	// ListFiles returns the paths of all files in the bucket
+	ListFiles(ctx context.Context) (paths []string, err error)
+	// GetFileInfo retrieves the file without the payload
+	GetFileInfo(ctx context.Context, filePath string) (item FileItem, err error)
+	// UpdateMetadata replaces the metadata of a file, the payload is not transferred
+	UpdateMetadata(ctx context.Context, filePath string, metadata map[string]string) (err error)
 }
 
 // S3Config defines the parameters to interact with S3 storage
@@ -48,11 +63,16 @@ type S3Config struct {
 }
 
 // NewService returns a new instance of the fileservice
-func NewService(ctx context.Context, logger logging.Logger, config S3Config) FileService {
+// if a keyring is supplied the payload of the files is encrypted
+func NewService(ctx context.Context, logger logging.Logger, config S3Config, keys *Keyring) FileService {
 	var svc FileService
 	{
 		svc = &s3service{config: config, logger: logger, ctx: ctx}
+		if keys != nil {
+			svc = ServiceEncryptionMiddleware(keys)(svc)
+		}
 		svc = ServiceLoggingMiddleware(logger)(svc)
+		svc = ServiceTracingMiddleware()(svc)
 	}
 	return svc
 }
@@ -67,6 +87,10 @@ type s3ServiceClient interface {
 	GetObject(context.Context, *s3.GetObjectInput, ...func(*s3.Options)) (*s3.GetObjectOutput, error)
 	PutObject(context.Context, *s3.PutObjectInput, ...func(*s3.Options)) (*s3.PutObjectOutput, error)
 	DeleteObject(context.Context, *s3.DeleteObjectInput, ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+	HeadBucket(context.Context, *s3.HeadBucketInput, ...func(*s3.Options)) (*s3.HeadBucketOutput, error)
+	HeadObject(context.Context, *s3.HeadObjectInput, ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+	CopyObject(context.Context, *s3.CopyObjectInput, ...func(*s3.Options)) (*s3.CopyObjectOutput, error)
+	ListObjectsV2(context.Context, *s3.ListObjectsV2Input, ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
 }
 
 type s3service struct {
@@ -110,24 +134,18 @@ func (s *s3service) InitClient() (err error) {
 }
 
 // GetFile retrieves a file defined by a given path from the backend store
-func (s *s3service) GetFile(filePath string) (item FileItem, err error) {
+func (s *s3service) GetFile(ctx context.Context, filePath string) (item FileItem, err error) {
 	err = s.InitClient()
 	if err != nil {
 		return FileItem{}, err
 	}
 
-	fileURLPath := filePath
-	if strings.Index(fileURLPath, "/") == 0 {
-		fileURLPath = fileURLPath[1:]
-	}
-	parts := strings.Split(fileURLPath, "/")
-	if len(parts) != 2 {
-		return FileItem{}, fmt.Errorf("invalid path supplied: %s", fileURLPath)
+	fileURLPath, path, fileName, err := splitPath(filePath)
+	if err != nil {
+		return FileItem{}, err
 	}
-	path := parts[0]
-	fileName := parts[1]
 
-	s3obj, err := s.s3client.GetObject(s.ctx,
+	s3obj, err := s.s3client.GetObject(ctx,
 		&s3.GetObjectInput{
 			Bucket: aws.String(s.config.Bucket),
 			Key:    aws.String(fileURLPath),
@@ -146,11 +164,127 @@ func (s *s3service) GetFile(filePath string) (item FileItem, err error) {
 		FolderName: path,
 		MimeType:   *ctype,
 		Payload:    payload,
+		Metadata:   s3obj.Metadata,
+	}, nil
+}
+
+// StreamFile copies the payload of the file to the writer without holding it in memory
+func (s *s3service) StreamFile(ctx context.Context, filePath string, w io.Writer) (item FileItem, err error) {
+	err = s.InitClient()
+	if err != nil {
+		return FileItem{}, err
+	}
+
+	fileURLPath, path, fileName, err := splitPath(filePath)
+	if err != nil {
+		return FileItem{}, err
+	}
+
+	s3obj, err := s.s3client.GetObject(ctx,
+		&s3.GetObjectInput{
+			Bucket: aws.String(s.config.Bucket),
+			Key:    aws.String(fileURLPath),
+		})
+	if err != nil {
+		return FileItem{}, fmt.Errorf("could not get object %s/%s. %v", s.config.Bucket, fileURLPath, err)
+	}
+	defer s3obj.Body.Close()
+	if _, err = io.Copy(w, s3obj.Body); err != nil {
+		return FileItem{}, fmt.Errorf("could not read object %s/%s. %v", s.config.Bucket, fileURLPath, err)
+	}
+
+	return FileItem{
+		FileName:   fileName,
+		FolderName: path,
+		MimeType:   aws.ToString(s3obj.ContentType),
+		Metadata:   s3obj.Metadata,
+	}, nil
+}
+
+// GetFileInfo retrieves the content-type and metadata of a file
+func (s *s3service) GetFileInfo(ctx context.Context, filePath string) (item FileItem, err error) {
+	err = s.InitClient()
+	if err != nil {
+		return FileItem{}, err
+	}
+
+	fileURLPath, path, fileName, err := splitPath(filePath)
+	if err != nil {
+		return FileItem{}, err
+	}
+
+	head, err := s.s3client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.config.Bucket),
+		Key:    aws.String(fileURLPath),
+	})
+	if err != nil {
+		return FileItem{}, fmt.Errorf("could not get object info %s/%s. %v", s.config.Bucket, fileURLPath, err)
+	}
+	return FileItem{
+		FileName:   fileName,
+		FolderName: path,
+		MimeType:   aws.ToString(head.ContentType),
+		Metadata:   head.Metadata,
 	}, nil
 }
 
+// UpdateMetadata copies the object onto itself, S3 does not allow to change metadata otherwise.
+// The copy is done by the storage backend.
+func (s *s3service) UpdateMetadata(ctx context.Context, filePath string, metadata map[string]string) (err error) {
+	item, err := s.GetFileInfo(ctx, filePath)
+	if err != nil {
+		return err
+	}
+
+	storagePath := item.String()
+	_, err = s.s3client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:            aws.String(s.config.Bucket),
+		Key:               aws.String(storagePath),
+		CopySource:        aws.String(url.PathEscape(s.config.Bucket + "/" + storagePath)),
+		ContentType:       aws.String(item.MimeType),
+		Metadata:          metadata,
+		MetadataDirective: types.MetadataDirectiveReplace,
+	})
+	if err != nil {
+		return fmt.Errorf("could not update metadata of file item '%s'. %v", storagePath, err)
+	}
+	return nil
+}
+
+// ListFiles returns the keys of all objects in the bucket
+func (s *s3service) ListFiles(ctx context.Context) (paths []string, err error) {
+	err = s.InitClient()
+	if err != nil {
+		return nil, err
+	}
+
+	pages := s3.NewListObjectsV2Paginator(s.s3client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.config.Bucket),
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not list objects of bucket '%s'. %v", s.config.Bucket, err)
+		}
+		for _, obj := range page.Contents {
+			paths = append(paths, aws.ToString(obj.Key))
+		}
+	}
+	return paths, nil
+}
+
+// splitPath validates the path of a file which is defined as folder/filename
+func splitPath(filePath string) (fileURLPath, path, fileName string, err error) {
+	fileURLPath = strings.TrimPrefix(filePath, "/")
+	parts := strings.Split(fileURLPath, "/")
+	if len(parts) != 2 {
+		return "", "", "", fmt.Errorf("invalid path supplied: %s", fileURLPath)
+	}
+	return fileURLPath, parts[0], parts[1], nil
+}
+
 // SaveFile stores a file item using a given path to the backend store
-func (s *s3service) SaveFile(file FileItem) (err error) {
+func (s *s3service) SaveFile(ctx context.Context, file FileItem) (err error) {
 	err = s.InitClient()
 	if err != nil {
 		return err
@@ -158,12 +292,13 @@ func (s *s3service) SaveFile(file FileItem) (err error) {
 
 	fileSize := len(file.Payload)
 	storagePath := fmt.Sprintf("%s/%s", file.FolderName, file.FileName)
-	_, err = s.s3client.PutObject(s.ctx, &s3.PutObjectInput{
+	_, err = s.s3client.PutObject(ctx, &s3.PutObjectInput{
 		Bucket:        aws.String(s.config.Bucket),
 		Key:           aws.String(storagePath),
 		Body:          bytes.NewReader(file.Payload),
 		ContentLength: aws.Int64(int64(fileSize)),
 		ContentType:   aws.String(file.MimeType),
+		Metadata:      file.Metadata,
 	})
 	if err != nil {
 		return fmt.Errorf("could not upload file item '%s' to S3 storage. %v", storagePath, err)
@@ -172,13 +307,13 @@ func (s *s3service) SaveFile(file FileItem) (err error) {
 }
 
 // DeleteFile removes the item using the specified paht
-func (s *s3service) DeleteFile(filePath string) (err error) {
+func (s *s3service) DeleteFile(ctx context.Context, filePath string) (err error) {
 	err = s.InitClient()
 	if err != nil {
 		return err
 	}
 
-	_, err = s.s3client.DeleteObject(s.ctx, &s3.DeleteObjectInput{
+	_, err = s.s3client.DeleteObject(ctx, &s3.DeleteObjectInput{
 		Bucket: aws.String(s.config.Bucket),
 		Key:    aws.String(filePath),
 	})
@@ -187,3 +322,19 @@ func (s *s3service) DeleteFile(filePath string) (err error) {
 	}
 	return nil
 }
+
+// CheckBucket verifies that the configured bucket is available and accessible
+func (s *s3service) CheckBucket(ctx context.Context) (err error) {
+	err = s.InitClient()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.s3client.HeadBucket(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(s.config.Bucket),
+	})
+	if err != nil {
+		return fmt.Errorf("could not reach bucket '%s'. %v", s.config.Bucket, err)
+	}
+	return nil
+}
@@ -0,0 +1,286 @@
+package filestore
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"maps"
+)
+
+// Envelope encryption of the stored files: every file is encrypted by a random data key using
+// AES-256-GCM, the data key is encrypted (wrapped) by the master key. The wrapped data key and the
+// id of the master key are stored as metadata of the object. Rotating the master key only needs
+// to re-wrap the data keys, the payload of the files stays untouched.
+//
+// The nonce is prepended to the encrypted payload; the path of the file is used as additional
+// data, an encrypted payload cannot be moved to another document.
+
+const (
+	// metadata keys of an encrypted object, S3 returns the keys in lower-case
+	metaEncryption = "encryption"
+	metaKeyID      = "encryption-key-id"
+	metaDataKey    = "encryption-data-key"
+
+	envelopeScheme = "envelope-aes256-gcm"
+	masterKeySize  = 32
+)
+
+// Keyring holds the current master key used for encryption and previous master keys which
+// are still needed to decrypt files which were not re-keyed.
+type Keyring struct {
+	currentID string
+	keys      map[string][]byte
+}
+
+// NewKeyring decodes the base64 encoded master keys, each key has 256 bits
+func NewKeyring(masterKey string, previousKeys ...string) (*Keyring, error) {
+	k := &Keyring{keys: make(map[string][]byte)}
+	for i, encoded := range append([]string{masterKey}, previousKeys...) {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("the master key is not base64 encoded: %v", err)
+		}
+		if len(key) != masterKeySize {
+			return nil, fmt.Errorf("the master key needs %d bytes, got %d", masterKeySize, len(key))
+		}
+		id := keyID(key)
+		if i == 0 {
+			k.currentID = id
+		}
+		k.keys[id] = key
+	}
+	return k, nil
+}
+
+// CurrentKeyID returns the id of the master key used to encrypt files
+func (k *Keyring) CurrentKeyID() string {
+	return k.currentID
+}
+
+// the id is derived from the key, it does not reveal the key
+func keyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts the plaintext and prepends the random nonce
+func seal(key, plaintext, additional []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, additional), nil
+}
+
+// open decrypts the ciphertext created by seal
+func open(key, ciphertext, additional []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, fmt.Errorf("the ciphertext is too short")
+	}
+	return gcm.Open(nil, ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():], additional)
+}
+
+// wrap encrypts the data key using the current master key and returns the metadata of the object
+func (k *Keyring) wrap(dataKey []byte, metadata map[string]string) (map[string]string, error) {
+	wrapped, err := seal(k.keys[k.currentID], dataKey, []byte(k.currentID))
+	if err != nil {
+		return nil, fmt.Errorf("could not wrap the data key: %v", err)
+	}
+	meta := maps.Clone(metadata)
+	if meta == nil {
+		meta = make(map[string]string)
+	}
+	meta[metaEncryption] = envelopeScheme
+	meta[metaKeyID] = k.currentID
+	meta[metaDataKey] = base64.StdEncoding.EncodeToString(wrapped)
+	return meta, nil
+}
+
+// unwrap decrypts the data key using the master key referenced by the metadata
+func (k *Keyring) unwrap(metadata map[string]string) ([]byte, error) {
+	if scheme := metadata[metaEncryption]; scheme != envelopeScheme {
+		return nil, fmt.Errorf("unknown encryption '%s'", scheme)
+	}
+	id := metadata[metaKeyID]
+	key, found := k.keys[id]
+	if !found {
+		return nil, fmt.Errorf("the master key '%s' is not available", id)
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(metadata[metaDataKey])
+	if err != nil {
+		return nil, fmt.Errorf("invalid data key: %v", err)
+	}
+	dataKey, err := open(key, wrapped, []byte(id))
+	if err != nil {
+		return nil, fmt.Errorf("could not unwrap the data key using the master key '%s': %v", id, err)
+	}
+	return dataKey, nil
+}
+
+// IsEncrypted checks the metadata of a file for the envelope encryption
+func IsEncrypted(item FileItem) bool {
+	return item.Metadata[metaEncryption] != ""
+}
+
+// ServiceEncryptionMiddleware encrypts the payload of saved files and decrypts the payload of
+// retrieved files. Files stored before the encryption was enabled are returned as they are.
+func ServiceEncryptionMiddleware(keys *Keyring) ServiceMiddleware {
+	return func(next FileService) FileService {
+		return encryptionMiddleware{keys, next}
+	}
+}
+
+// compile guard for Service implementation
+var (
+	_ FileService = &encryptionMiddleware{}
+)
+
+type encryptionMiddleware struct {
+	keys *Keyring
+	next FileService
+}
+
+func (e encryptionMiddleware) InitClient() (err error) {
+	return e.next.InitClient()
+}
+
+func (e encryptionMiddleware) CheckBucket(ctx context.Context) (err error) {
+	return e.next.CheckBucket(ctx)
+}
+
+func (e encryptionMiddleware) SaveFile(ctx context.Context, file FileItem) (err error) {
+	dataKey := make([]byte, masterKeySize)
+	if _, err = rand.Read(dataKey); err != nil {
+		return fmt.Errorf("could not create a data key: %v", err)
+	}
+	payload, err := seal(dataKey, file.Payload, []byte(file.String()))
+	if err != nil {
+		return fmt.Errorf("could not encrypt file item '%s': %v", file, err)
+	}
+	metadata, err := e.keys.wrap(dataKey, file.Metadata)
+	if err != nil {
+		return err
+	}
+	file.Payload = payload
+	file.Metadata = metadata
+	return e.next.SaveFile(ctx, file)
+}
+
+func (e encryptionMiddleware) GetFile(ctx context.Context, filePath string) (item FileItem, err error) {
+	item, err = e.next.GetFile(ctx, filePath)
+	if err != nil || !IsEncrypted(item) {
+		return item, err
+	}
+	dataKey, err := e.keys.unwrap(item.Metadata)
+	if err != nil {
+		return FileItem{}, fmt.Errorf("could not decrypt file item '%s': %v", item, err)
+	}
+	payload, err := open(dataKey, item.Payload, []byte(item.String()))
+	if err != nil {
+		return FileItem{}, fmt.Errorf("could not decrypt file item '%s': %v", item, err)
+	}
+	item.Payload = payload
+	return item, nil
+}
+
+// StreamFile decrypts an encrypted file as a whole, the authentication of the cipher needs the
+// complete payload. Files stored without encryption are streamed.
+func (e encryptionMiddleware) StreamFile(ctx context.Context, filePath string, w io.Writer) (item FileItem, err error) {
+	info, err := e.next.GetFileInfo(ctx, filePath)
+	if err != nil {
+		return FileItem{}, err
+	}
+	if !IsEncrypted(info) {
+		return e.next.StreamFile(ctx, filePath, w)
+	}
+	item, err = e.GetFile(ctx, filePath)
+	if err != nil {
+		return FileItem{}, err
+	}
+	if _, err = w.Write(item.Payload); err != nil {
+		return FileItem{}, err
+	}
+	item.Payload = nil
+	return item, nil
+}
+
+func (e encryptionMiddleware) DeleteFile(ctx context.Context, filePath string) (err error) {
+	return e.next.DeleteFile(ctx, filePath)
+}
+
+func (e encryptionMiddleware) ListFiles(ctx context.Context) (paths []string, err error) {
+	return e.next.ListFiles(ctx)
+}
+
+func (e encryptionMiddleware) GetFileInfo(ctx context.Context, filePath string) (item FileItem, err error) {
+	return e.next.GetFileInfo(ctx, filePath)
+}
+
+func (e encryptionMiddleware) UpdateMetadata(ctx context.Context, filePath string, metadata map[string]string) (err error) {
+	return e.next.UpdateMetadata(ctx, filePath, metadata)
+}
+
+// RekeyResult counts the files processed by Rekey
+type RekeyResult struct {
+	Rekeyed     int
+	Current     int
+	Unencrypted int
+}
+
+// Rekey wraps the data keys of all encrypted files using the current master key of the keyring.
+// Only the metadata of the files is changed; files which are not encrypted are skipped.
+func Rekey(ctx context.Context, svc FileService, keys *Keyring) (result RekeyResult, err error) {
+	paths, err := svc.ListFiles(ctx)
+	if err != nil {
+		return result, err
+	}
+	for _, path := range paths {
+		item, err := svc.GetFileInfo(ctx, path)
+		if err != nil {
+			return result, err
+		}
+		if !IsEncrypted(item) {
+			result.Unencrypted++
+			continue
+		}
+		if item.Metadata[metaKeyID] == keys.CurrentKeyID() {
+			result.Current++
+			continue
+		}
+		dataKey, err := keys.unwrap(item.Metadata)
+		if err != nil {
+			return result, fmt.Errorf("could not re-key file item '%s': %v", path, err)
+		}
+		metadata, err := keys.wrap(dataKey, item.Metadata)
+		if err != nil {
+			return result, err
+		}
+		if err = svc.UpdateMetadata(ctx, path, metadata); err != nil {
+			return result, err
+		}
+		result.Rekeyed++
+	}
+	return result, nil
+}
@@ -0,0 +1,180 @@
+package filestore
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryFileService keeps the files in memory, it stores what it receives
+type memoryFileService struct {
+	files map[string]FileItem
+}
+
+func newMemoryFileService() *memoryFileService {
+	return &memoryFileService{files: make(map[string]FileItem)}
+}
+
+var _ FileService = &memoryFileService{}
+
+func (m *memoryFileService) InitClient() error                     { return nil }
+func (m *memoryFileService) CheckBucket(ctx context.Context) error { return nil }
+
+func (m *memoryFileService) SaveFile(ctx context.Context, file FileItem) error {
+	m.files[file.String()] = file
+	return nil
+}
+
+func (m *memoryFileService) GetFile(ctx context.Context, filePath string) (FileItem, error) {
+	item, found := m.files[strings.TrimPrefix(filePath, "/")]
+	if !found {
+		return FileItem{}, fmt.Errorf("not found: %s", filePath)
+	}
+	return item, nil
+}
+
+func (m *memoryFileService) StreamFile(ctx context.Context, filePath string, w io.Writer) (FileItem, error) {
+	item, err := m.GetFile(ctx, filePath)
+	if err != nil {
+		return item, err
+	}
+	_, err = w.Write(item.Payload)
+	item.Payload = nil
+	return item, err
+}
+
+func (m *memoryFileService) DeleteFile(ctx context.Context, filePath string) error {
+	delete(m.files, filePath)
+	return nil
+}
+
+func (m *memoryFileService) ListFiles(ctx context.Context) ([]string, error) {
+	return slices.Sorted(maps.Keys(m.files)), nil
+}
+
+func (m *memoryFileService) GetFileInfo(ctx context.Context, filePath string) (FileItem, error) {
+	item, err := m.GetFile(ctx, filePath)
+	item.Payload = nil
+	return item, err
+}
+
+func (m *memoryFileService) UpdateMetadata(ctx context.Context, filePath string, metadata map[string]string) error {
+	item, found := m.files[filePath]
+	if !found {
+		return fmt.Errorf("not found: %s", filePath)
+	}
+	item.Metadata = metadata
+	m.files[filePath] = item
+	return nil
+}
+
+func newMasterKey(t *testing.T) string {
+	key := make([]byte, masterKeySize)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("could not create key: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func TestKeyring(t *testing.T) {
+	key := newMasterKey(t)
+	keys, err := NewKeyring(key)
+	assert.NoError(t, err)
+	assert.Len(t, keys.CurrentKeyID(), 16)
+
+	_, err = NewKeyring("not base64!")
+	assert.Error(t, err)
+	_, err = NewKeyring(base64.StdEncoding.EncodeToString([]byte("too short")))
+	assert.Error(t, err)
+	_, err = NewKeyring(key, "")
+	assert.Error(t, err)
+}
+
+func TestEncryptionMiddleware(t *testing.T) {
+	keys, _ := NewKeyring(newMasterKey(t))
+	store := newMemoryFileService()
+	svc := ServiceEncryptionMiddleware(keys)(store)
+
+	file := FileItem{FileName: "test.pdf", FolderName: "__TEST", MimeType: mimeType, Payload: []byte(pdfPayload)}
+	assert.NoError(t, svc.SaveFile(context.TODO(), file))
+
+	// the payload is stored encrypted
+	stored := store.files["__TEST/test.pdf"]
+	assert.True(t, IsEncrypted(stored))
+	assert.NotContains(t, string(stored.Payload), "%PDF")
+	assert.Equal(t, keys.CurrentKeyID(), stored.Metadata[metaKeyID])
+	assert.Equal(t, mimeType, stored.MimeType)
+
+	item, err := svc.GetFile(context.TODO(), "/__TEST/test.pdf")
+	assert.NoError(t, err)
+	assert.Equal(t, pdfPayload, string(item.Payload))
+	var buf strings.Builder
+	_, err = svc.StreamFile(context.TODO(), "/__TEST/test.pdf", &buf)
+	assert.NoError(t, err)
+	assert.Equal(t, pdfPayload, buf.String())
+
+	// files stored before the encryption was enabled
+	store.files["__TEST/plain.pdf"] = FileItem{FileName: "plain.pdf", FolderName: "__TEST", MimeType: mimeType, Payload: []byte(pdfPayload)}
+	item, err = svc.GetFile(context.TODO(), "__TEST/plain.pdf")
+	assert.NoError(t, err)
+	assert.Equal(t, pdfPayload, string(item.Payload))
+	buf.Reset()
+	_, err = svc.StreamFile(context.TODO(), "__TEST/plain.pdf", &buf)
+	assert.NoError(t, err)
+	assert.Equal(t, pdfPayload, buf.String())
+
+	// the payload is bound to the path of the file
+	moved := stored
+	moved.FileName = "moved.pdf"
+	store.files["__TEST/moved.pdf"] = moved
+	_, err = svc.GetFile(context.TODO(), "__TEST/moved.pdf")
+	assert.Error(t, err)
+
+	// another master key cannot decrypt the file
+	otherKeys, _ := NewKeyring(newMasterKey(t))
+	_, err = ServiceEncryptionMiddleware(otherKeys)(store).GetFile(context.TODO(), "__TEST/test.pdf")
+	assert.ErrorContains(t, err, "is not available")
+}
+
+func TestRekey(t *testing.T) {
+	oldKey := newMasterKey(t)
+	oldKeys, _ := NewKeyring(oldKey)
+	store := newMemoryFileService()
+	for _, name := range []string{"a.pdf", "b.pdf"} {
+		file := FileItem{FileName: name, FolderName: "__TEST", MimeType: mimeType, Payload: []byte(pdfPayload)}
+		assert.NoError(t, ServiceEncryptionMiddleware(oldKeys)(store).SaveFile(context.TODO(), file))
+	}
+	store.files["__TEST/plain.pdf"] = FileItem{FileName: "plain.pdf", FolderName: "__TEST", MimeType: mimeType, Payload: []byte(pdfPayload)}
+	encrypted := string(store.files["__TEST/a.pdf"].Payload)
+
+	// without the old key the files cannot be re-keyed
+	newKey := newMasterKey(t)
+	newKeys, _ := NewKeyring(newKey)
+	_, err := Rekey(context.TODO(), store, newKeys)
+	assert.Error(t, err)
+
+	newKeys, _ = NewKeyring(newKey, oldKey)
+	result, err := Rekey(context.TODO(), store, newKeys)
+	assert.NoError(t, err)
+	assert.Equal(t, RekeyResult{Rekeyed: 2, Unencrypted: 1}, result)
+
+	// the payload is unchanged, only the new master key is needed
+	assert.Equal(t, encrypted, string(store.files["__TEST/a.pdf"].Payload))
+	onlyNewKey, _ := NewKeyring(newKey)
+	item, err := ServiceEncryptionMiddleware(onlyNewKey)(store).GetFile(context.TODO(), "__TEST/a.pdf")
+	assert.NoError(t, err)
+	assert.Equal(t, pdfPayload, string(item.Payload))
+
+	// a second run has nothing to do
+	result, err = Rekey(context.TODO(), store, newKeys)
+	assert.NoError(t, err)
+	assert.Equal(t, RekeyResult{Current: 2, Unencrypted: 1}, result)
+}
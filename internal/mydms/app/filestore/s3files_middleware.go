@@ -1,7 +1,12 @@
 package filestore
 
 import (
+	"context"
+	"io"
+
+	"go.opentelemetry.io/otel/attribute"
 	"golang.binggl.net/monorepo/pkg/logging"
+	"golang.binggl.net/monorepo/pkg/tracing"
 )
 
 // ServiceMiddleware describes a service middleware.
@@ -32,20 +37,116 @@ func (l loggingMiddleware) InitClient() (err error) {
 	return l.next.InitClient()
 }
 
-func (l loggingMiddleware) SaveFile(file FileItem) (err error) {
+func (l loggingMiddleware) CheckBucket(ctx context.Context) (err error) {
+	return l.next.CheckBucket(ctx)
+}
+
+func (l loggingMiddleware) SaveFile(ctx context.Context, file FileItem) (err error) {
 	l.logger.Info("SaveFile", logging.LogV("param:file", file.String()))
 	defer l.logger.Info("called SaveFile", logging.ErrV(err))
-	return l.next.SaveFile(file)
+	return l.next.SaveFile(ctx, file)
 }
 
-func (l loggingMiddleware) GetFile(filePath string) (item FileItem, err error) {
+func (l loggingMiddleware) GetFile(ctx context.Context, filePath string) (item FileItem, err error) {
 	l.logger.Info("GetFile", logging.LogV("param:filePath", filePath))
 	defer l.logger.Info("called GetFile", logging.ErrV(err))
-	return l.next.GetFile(filePath)
+	return l.next.GetFile(ctx, filePath)
+}
+
+func (l loggingMiddleware) StreamFile(ctx context.Context, filePath string, w io.Writer) (item FileItem, err error) {
+	l.logger.Info("StreamFile", logging.LogV("param:filePath", filePath))
+	defer l.logger.Info("called StreamFile", logging.ErrV(err))
+	return l.next.StreamFile(ctx, filePath, w)
 }
 
-func (l loggingMiddleware) DeleteFile(filePath string) (err error) {
+func (l loggingMiddleware) DeleteFile(ctx context.Context, filePath string) (err error) {
 	l.logger.Info("DeleteFile", logging.LogV("param:filePath", filePath))
 	defer l.logger.Info("called DeleteFile", logging.ErrV(err))
-	return l.next.DeleteFile(filePath)
+	return l.next.DeleteFile(ctx, filePath)
+}
+
+func (l loggingMiddleware) ListFiles(ctx context.Context) (paths []string, err error) {
+	defer l.logger.Info("called ListFiles", logging.ErrV(err))
+	return l.next.ListFiles(ctx)
+}
+
+func (l loggingMiddleware) GetFileInfo(ctx context.Context, filePath string) (item FileItem, err error) {
+	l.logger.Debug("GetFileInfo", logging.LogV("param:filePath", filePath))
+	return l.next.GetFileInfo(ctx, filePath)
+}
+
+func (l loggingMiddleware) UpdateMetadata(ctx context.Context, filePath string, metadata map[string]string) (err error) {
+	l.logger.Info("UpdateMetadata", logging.LogV("param:filePath", filePath))
+	defer l.logger.Info("called UpdateMetadata", logging.ErrV(err))
+	return l.next.UpdateMetadata(ctx, filePath, metadata)
+}
+
+// ServiceTracingMiddleware creates a child-span for every file operation
+func ServiceTracingMiddleware() ServiceMiddleware {
+	return func(next FileService) FileService {
+		return tracingMiddleware{next}
+	}
+}
+
+// compile guard for Service implementation
+var (
+	_ FileService = &tracingMiddleware{}
+)
+
+type tracingMiddleware struct {
+	next FileService
+}
+
+// the bucket check is used by readiness probes, no spans are created to avoid noise
+func (t tracingMiddleware) CheckBucket(ctx context.Context) (err error) {
+	return t.next.CheckBucket(ctx)
+}
+
+func (t tracingMiddleware) InitClient() (err error) {
+	return t.next.InitClient()
+}
+
+func (t tracingMiddleware) SaveFile(ctx context.Context, file FileItem) (err error) {
+	ctx, span := tracing.Start(ctx, "FileService.SaveFile",
+		attribute.String("file.path", file.String()),
+		attribute.Int("file.size", len(file.Payload)),
+	)
+	defer func() { tracing.End(span, err) }()
+	return t.next.SaveFile(ctx, file)
+}
+
+func (t tracingMiddleware) GetFile(ctx context.Context, filePath string) (item FileItem, err error) {
+	ctx, span := tracing.Start(ctx, "FileService.GetFile", attribute.String("file.path", filePath))
+	defer func() { tracing.End(span, err) }()
+	return t.next.GetFile(ctx, filePath)
+}
+
+func (t tracingMiddleware) StreamFile(ctx context.Context, filePath string, w io.Writer) (item FileItem, err error) {
+	ctx, span := tracing.Start(ctx, "FileService.StreamFile", attribute.String("file.path", filePath))
+	defer func() { tracing.End(span, err) }()
+	return t.next.StreamFile(ctx, filePath, w)
+}
+
+func (t tracingMiddleware) DeleteFile(ctx context.Context, filePath string) (err error) {
+	ctx, span := tracing.Start(ctx, "FileService.DeleteFile", attribute.String("file.path", filePath))
+	defer func() { tracing.End(span, err) }()
+	return t.next.DeleteFile(ctx, filePath)
+}
+
+func (t tracingMiddleware) ListFiles(ctx context.Context) (paths []string, err error) {
+	ctx, span := tracing.Start(ctx, "FileService.ListFiles")
+	defer func() { tracing.End(span, err) }()
+	return t.next.ListFiles(ctx)
+}
+
+func (t tracingMiddleware) GetFileInfo(ctx context.Context, filePath string) (item FileItem, err error) {
+	ctx, span := tracing.Start(ctx, "FileService.GetFileInfo", attribute.String("file.path", filePath))
+	defer func() { tracing.End(span, err) }()
+	return t.next.GetFileInfo(ctx, filePath)
+}
+
+func (t tracingMiddleware) UpdateMetadata(ctx context.Context, filePath string, metadata map[string]string) (err error) {
+	ctx, span := tracing.Start(ctx, "FileService.UpdateMetadata", attribute.String("file.path", filePath))
+	defer func() { tracing.End(span, err) }()
+	return t.next.UpdateMetadata(ctx, filePath, metadata)
 }
@@ -9,6 +9,7 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/stretchr/testify/assert"
 	"golang.binggl.net/monorepo/pkg/logging"
 )
@@ -33,6 +34,7 @@ const mimeType = "application/pdf"
 var logger = logging.NewNop()
 
 type mockS3Client struct {
+	copyInput *s3.CopyObjectInput
 }
 
 func (m *mockS3Client) GetObject(ctx context.Context, input *s3.GetObjectInput, fn ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
@@ -59,14 +61,50 @@ func (m *mockS3Client) DeleteObject(ctx context.Context, input *s3.DeleteObjectI
 	return &s3.DeleteObjectOutput{}, nil
 }
 
+func (m *mockS3Client) HeadBucket(ctx context.Context, input *s3.HeadBucketInput, fn ...func(*s3.Options)) (*s3.HeadBucketOutput, error) {
+	if *input.Bucket == "" {
+		return nil, fmt.Errorf("no such bucket")
+	}
+	return &s3.HeadBucketOutput{}, nil
+}
+
+func (m *mockS3Client) HeadObject(ctx context.Context, input *s3.HeadObjectInput, fn ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	if *input.Key == "null/null" {
+		return nil, fmt.Errorf("could not get object with Key %s", *input.Key)
+	}
+	return &s3.HeadObjectOutput{
+		ContentType: aws.String(mimeType),
+		Metadata:    map[string]string{"a": "b"},
+	}, nil
+}
+
+func (m *mockS3Client) CopyObject(ctx context.Context, input *s3.CopyObjectInput, fn ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	m.copyInput = input
+	return &s3.CopyObjectOutput{}, nil
+}
+
+// two pages of objects
+func (m *mockS3Client) ListObjectsV2(ctx context.Context, input *s3.ListObjectsV2Input, fn ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	if input.ContinuationToken == nil {
+		return &s3.ListObjectsV2Output{
+			Contents:              []types.Object{{Key: aws.String("a/1.pdf")}},
+			IsTruncated:           aws.Bool(true),
+			NextContinuationToken: aws.String("next"),
+		}, nil
+	}
+	return &s3.ListObjectsV2Output{
+		Contents: []types.Object{{Key: aws.String("b/2.pdf")}},
+	}, nil
+}
+
 func TestInitClient(t *testing.T) {
-	svc := NewService(context.TODO(), logger, S3Config{})
+	svc := NewService(context.TODO(), logger, S3Config{}, nil)
 	err := svc.InitClient()
 	if err != nil {
 		t.Errorf("error initializing client")
 	}
 
-	svc = NewService(context.TODO(), logger, S3Config{EndPoint: "http://minio:9000"})
+	svc = NewService(context.TODO(), logger, S3Config{EndPoint: "http://minio:9000"}, nil)
 	err = svc.InitClient()
 	if err != nil {
 		t.Errorf("error initializing client")
@@ -118,7 +156,7 @@ func TestGetS3Entry(t *testing.T) {
 
 	for _, c := range cases {
 		t.Run(c.Name, func(t *testing.T) {
-			fileItem, err := service.GetFile(c.Path)
+			fileItem, err := service.GetFile(context.TODO(), c.Path)
 			if c.Name == "invalid path" || c.Name == "invalid file" {
 				assert.Error(t, err, "error for invalid file expected!")
 				return
@@ -131,12 +169,30 @@ func TestGetS3Entry(t *testing.T) {
 
 }
 
+func TestStreamS3Entry(t *testing.T) {
+	service := s3service{
+		config:   S3Config{},
+		s3client: &mockS3Client{},
+	}
+
+	var buf bytes.Buffer
+	item, err := service.StreamFile(context.TODO(), "/2009_08_06/20090806-invoice.pdf", &buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "20090806-invoice.pdf", item.FileName)
+	assert.Equal(t, mimeType, item.MimeType)
+	assert.Nil(t, item.Payload)
+	assert.Equal(t, pdfPayload, buf.String())
+
+	_, err = service.StreamFile(context.TODO(), "null/null", &buf)
+	assert.Error(t, err)
+}
+
 func TestSaveS3Entry(t *testing.T) {
 	service := s3service{
 		config:   S3Config{},
 		s3client: &mockS3Client{},
 	}
-	err := service.SaveFile(FileItem{
+	err := service.SaveFile(context.TODO(), FileItem{
 		FileName:   "test.pdf",
 		FolderName: "__TEST",
 		MimeType:   mimeType,
@@ -146,7 +202,7 @@ func TestSaveS3Entry(t *testing.T) {
 		t.Errorf("could not get upload file to s3 backend: %v", err)
 	}
 
-	err = service.SaveFile(FileItem{
+	err = service.SaveFile(context.TODO(), FileItem{
 		FileName:   "",
 		FolderName: "",
 		MimeType:   mimeType,
@@ -162,13 +218,62 @@ func TestDeleteS3Entry(t *testing.T) {
 		config:   S3Config{},
 		s3client: &mockS3Client{},
 	}
-	err := service.DeleteFile("test.pdf")
+	err := service.DeleteFile(context.TODO(), "test.pdf")
 	if err != nil {
 		t.Errorf("could not get delete file from s3 backend: %v", err)
 	}
 
-	err = service.DeleteFile("/")
+	err = service.DeleteFile(context.TODO(), "/")
 	if err == nil {
 		t.Errorf("expected error invalid path")
 	}
 }
+
+func TestCheckBucket(t *testing.T) {
+	service := s3service{
+		config:   S3Config{Bucket: "bucket"},
+		s3client: &mockS3Client{},
+	}
+	if err := service.CheckBucket(context.TODO()); err != nil {
+		t.Errorf("could not check bucket: %v", err)
+	}
+
+	service.config.Bucket = ""
+	if err := service.CheckBucket(context.TODO()); err == nil {
+		t.Errorf("expected error for missing bucket")
+	}
+}
+
+func TestS3Metadata(t *testing.T) {
+	client := &mockS3Client{}
+	service := s3service{
+		config:   S3Config{Bucket: "bucket"},
+		s3client: client,
+	}
+
+	item, err := service.GetFileInfo(context.TODO(), "/2009_08_06/20090806-invoice.pdf")
+	assert.NoError(t, err)
+	assert.Equal(t, "2009_08_06/20090806-invoice.pdf", item.String())
+	assert.Equal(t, mimeType, item.MimeType)
+	assert.Equal(t, "b", item.Metadata["a"])
+	assert.Empty(t, item.Payload)
+
+	_, err = service.GetFileInfo(context.TODO(), "invalid")
+	assert.Error(t, err)
+
+	// the metadata is replaced by a copy of the object
+	err = service.UpdateMetadata(context.TODO(), "2009_08_06/20090806-invoice.pdf", map[string]string{"c": "d"})
+	assert.NoError(t, err)
+	assert.Equal(t, "2009_08_06/20090806-invoice.pdf", *client.copyInput.Key)
+	assert.Equal(t, "bucket%2F2009_08_06%2F20090806-invoice.pdf", *client.copyInput.CopySource)
+	assert.Equal(t, types.MetadataDirectiveReplace, client.copyInput.MetadataDirective)
+	assert.Equal(t, mimeType, *client.copyInput.ContentType)
+	assert.Equal(t, map[string]string{"c": "d"}, client.copyInput.Metadata)
+
+	err = service.UpdateMetadata(context.TODO(), "null/null", map[string]string{"c": "d"})
+	assert.Error(t, err)
+
+	paths, err := service.ListFiles(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a/1.pdf", "b/2.pdf"}, paths)
+}
@@ -0,0 +1,279 @@
+// Package inbox watches a local directory, e.g. the drop folder of a scanner, and ingests new PDF
+// files as drafts. The drafts need to be reviewed by the user to complete title, tags and senders.
+package inbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.binggl.net/monorepo/internal/common/upload"
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/pkg/logging"
+	"golang.binggl.net/monorepo/pkg/security"
+)
+
+const (
+	// DefaultInterval is used if no interval between the scans of the inbox is set
+	DefaultInterval = time.Minute
+	// DefaultSettleTime is used if no settle time is set
+	DefaultSettleTime = 10 * time.Second
+)
+
+// Options define the watched directory and where the files are moved after processing
+type Options struct {
+	// Path of the watched directory
+	Path string
+	// Processed is the directory of the ingested files, defaults to 'processed' within Path
+	Processed string
+	// Failed is the directory of files which could not be ingested, defaults to 'failed' within Path
+	Failed string
+	// Interval between the scans of the directory
+	Interval time.Duration
+	// SettleTime is the time a file has to be unchanged, a scanner might still write recent files
+	SettleTime time.Duration
+}
+
+// Result counts the files handled by a scan of the inbox
+type Result struct {
+	Processed int
+	Failed    int
+	// Duplicates are files already stored by a document, no draft is created
+	Duplicates int
+}
+
+// errDuplicate is returned by ingest for files already stored by a document
+var errDuplicate = errors.New("the file is already stored")
+
+// pdfHeader starts every PDF file, the extension alone does not tell a half-written file apart
+var pdfHeader = []byte("%PDF-")
+
+// the drafts are saved on behalf of this user
+var inboxUser = security.User{
+	Username:    "inbox",
+	DisplayName: "mydms inbox",
+}
+
+// Watcher ingests the files of the inbox using the upload and document services
+type Watcher struct {
+	opts      Options
+	docSvc    document.Service
+	uploadSvc upload.Service
+	logger    logging.Logger
+}
+
+// NewWatcher creates a Watcher and the directories of the processed and failed files
+func NewWatcher(opts Options, docSvc document.Service, uploadSvc upload.Service, logger logging.Logger) (*Watcher, error) {
+	if opts.Path == "" {
+		return nil, fmt.Errorf("no inbox directory is set")
+	}
+	if opts.Processed == "" {
+		opts.Processed = filepath.Join(opts.Path, "processed")
+	}
+	if opts.Failed == "" {
+		opts.Failed = filepath.Join(opts.Path, "failed")
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	if opts.SettleTime <= 0 {
+		opts.SettleTime = DefaultSettleTime
+	}
+	for _, dir := range []string{opts.Path, opts.Processed, opts.Failed} {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return nil, fmt.Errorf("could not create the inbox directory '%s': %w", dir, err)
+		}
+	}
+	return &Watcher{
+		opts:      opts,
+		docSvc:    docSvc,
+		uploadSvc: uploadSvc,
+		logger:    logger,
+	}, nil
+}
+
+// Run scans the inbox in the configured interval until the context is done
+func (w *Watcher) Run(ctx context.Context) {
+	w.logger.Info(fmt.Sprintf("watching the inbox '%s' every %s", w.opts.Path, w.opts.Interval))
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+	for {
+		r, err := w.Scan(ctx)
+		if err != nil {
+			w.logger.Error("could not scan the inbox", logging.ErrV(err))
+		} else if r.Processed > 0 || r.Failed > 0 || r.Duplicates > 0 {
+			w.logger.Info(fmt.Sprintf("inbox: ingested %d files, %d files failed, %d duplicates", r.Processed, r.Failed, r.Duplicates))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Scan ingests the PDF files of the inbox which were not changed within the settle time, other files
+// are kept in the inbox. Each file is moved to the processed directory before it is ingested, a file
+// which cannot be moved stays in the inbox and is not ingested twice. Failed files are moved on to
+// the failed directory.
+func (w *Watcher) Scan(ctx context.Context) (r Result, err error) {
+	entries, err := os.ReadDir(w.opts.Path)
+	if err != nil {
+		return r, fmt.Errorf("could not read the inbox '%s': %w", w.opts.Path, err)
+	}
+	settled := time.Now().Add(-w.opts.SettleTime)
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			return r, ctx.Err()
+		}
+		// scanners write temporary files, other applications add hidden files
+		if !e.Type().IsRegular() || strings.HasPrefix(e.Name(), ".") || !strings.EqualFold(filepath.Ext(e.Name()), ".pdf") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || info.ModTime().After(settled) {
+			continue
+		}
+
+		processed, err := move(filepath.Join(w.opts.Path, e.Name()), w.opts.Processed)
+		if err != nil {
+			w.logger.Error(fmt.Sprintf("could not move the file '%s' of the inbox, the file is ingested by the next scan", e.Name()), logging.ErrV(err))
+			r.Failed++
+			continue
+		}
+		err = w.ingest(ctx, processed, e.Name(), info.Size())
+		switch {
+		case err == nil:
+			r.Processed++
+		case errors.Is(err, errDuplicate):
+			w.logger.Info(fmt.Sprintf("the file '%s' of the inbox is already stored, no draft is created", e.Name()))
+			r.Duplicates++
+		default:
+			w.logger.Warn(fmt.Sprintf("could not ingest the file '%s' of the inbox", e.Name()), logging.ErrV(err))
+			r.Failed++
+			if _, err = move(processed, w.opts.Failed); err != nil {
+				w.logger.Warn(fmt.Sprintf("the failed file '%s' stays in the processed directory", e.Name()), logging.ErrV(err))
+			}
+		}
+	}
+	return r, nil
+}
+
+// ingest uploads the file and saves a draft of the document, the name is the name of the file in the inbox.
+// Files already stored by a document are skipped, a scanner might deliver the same file again.
+func (w *Watcher) ingest(ctx context.Context, path, name string, size int64) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	header := make([]byte, len(pdfHeader))
+	if _, err = io.ReadFull(f, header); err != nil || !bytes.Equal(header, pdfHeader) {
+		return fmt.Errorf("the file is not a PDF")
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	hash, err := document.ContentHashReader(f)
+	if err != nil {
+		return err
+	}
+	docs, err := w.docSvc.DocumentsByContentHash(ctx, hash)
+	if err != nil {
+		return err
+	}
+	if len(docs) > 0 {
+		return fmt.Errorf("%w by document '%s'", errDuplicate, docs[0].ID)
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	token, err := w.uploadSvc.Save(upload.File{
+		File:     f,
+		Name:     name,
+		Size:     size,
+		MimeType: mime.TypeByExtension(strings.ToLower(filepath.Ext(name))),
+	})
+	if err != nil {
+		return err
+	}
+	doc, err := w.docSvc.SaveDocument(ctx, document.Document{
+		Title:       title(name),
+		FileName:    name,
+		UploadToken: token,
+		NeedsReview: true,
+	}, inboxUser)
+	if err != nil {
+		if delErr := w.uploadSvc.Delete(token); delErr != nil {
+			w.logger.Warn(fmt.Sprintf("could not delete the upload of '%s'", name), logging.ErrV(delErr))
+		}
+		return err
+	}
+	w.logger.Info(fmt.Sprintf("created the draft '%s' for the file '%s' of the inbox", doc.ID, name))
+	return nil
+}
+
+// title is the initial title of the draft, the name of the file without the extension
+func title(name string) string {
+	t := strings.TrimSuffix(name, filepath.Ext(name))
+	t = strings.TrimSpace(strings.NewReplacer("_", " ", "-", " ").Replace(t))
+	if t == "" {
+		return name
+	}
+	return t
+}
+
+// move puts the file into the directory and returns the new path, existing files of the same name are not replaced
+func move(src, dir string) (string, error) {
+	name := filepath.Base(src)
+	target := filepath.Join(dir, name)
+	for i := 2; exists(target); i++ {
+		ext := filepath.Ext(name)
+		target = filepath.Join(dir, fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), i, ext))
+	}
+	err := os.Rename(src, target)
+	if err == nil {
+		return target, nil
+	}
+	if !errors.Is(err, syscall.EXDEV) {
+		return "", err
+	}
+	// the directories are on different devices
+	return target, copyFile(src, target)
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func copyFile(src, target string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o640)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(target)
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
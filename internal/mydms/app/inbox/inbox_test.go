@@ -0,0 +1,175 @@
+package inbox_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.binggl.net/monorepo/internal/common/upload"
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/internal/mydms/app/inbox"
+	"golang.binggl.net/monorepo/pkg/logging"
+	"golang.binggl.net/monorepo/pkg/security"
+)
+
+// mockDocumentService records the saved drafts, the stored files are identified by their hash
+type mockDocumentService struct {
+	document.Service
+	saved  []document.Document
+	hashes map[string]string
+	err    error
+}
+
+func (m *mockDocumentService) DocumentsByContentHash(ctx context.Context, hash string) ([]document.Document, error) {
+	if id, ok := m.hashes[hash]; ok {
+		return []document.Document{{ID: id}}, nil
+	}
+	return nil, nil
+}
+
+func (m *mockDocumentService) SaveDocument(ctx context.Context, doc document.Document, user security.User) (document.Document, error) {
+	if m.err != nil {
+		return document.Document{}, m.err
+	}
+	doc.ID = fmt.Sprintf("%d", len(m.saved)+1)
+	m.saved = append(m.saved, doc)
+	return doc, nil
+}
+
+func newUploadService(t *testing.T) upload.Service {
+	return upload.NewService(upload.ServiceOptions{
+		Logger:           logging.NewNop(),
+		Store:            upload.NewStore(t.TempDir()),
+		MaxUploadSize:    1000,
+		AllowedFileTypes: []string{"pdf"},
+	})
+}
+
+// writeFile creates a PDF file in the inbox, the age defines the modification time
+func writeFile(t *testing.T, dir, name string, age time.Duration) {
+	writeContent(t, dir, name, "%PDF-1.0", age)
+}
+
+func writeContent(t *testing.T, dir, name, content string, age time.Duration) {
+	p := filepath.Join(dir, name)
+	if err := os.WriteFile(p, []byte(content), 0o640); err != nil {
+		t.Fatalf("could not write '%s': %v", name, err)
+	}
+	mod := time.Now().Add(-age)
+	if err := os.Chtimes(p, mod, mod); err != nil {
+		t.Fatalf("could not change the time of '%s': %v", name, err)
+	}
+}
+
+func names(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("could not read '%s': %v", dir, err)
+	}
+	var n []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			n = append(n, e.Name())
+		}
+	}
+	return n
+}
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	docSvc := &mockDocumentService{}
+	w, err := inbox.NewWatcher(inbox.Options{Path: dir, SettleTime: time.Minute}, docSvc, newUploadService(t), logging.NewNop())
+	if err != nil {
+		t.Fatalf("could not create the watcher: %v", err)
+	}
+
+	writeFile(t, dir, "scan_2024-01-01.pdf", time.Hour)
+	// only PDF files are ingested
+	writeFile(t, dir, "notes.txt", time.Hour)
+	writeFile(t, dir, "scan.tmp", time.Hour)
+	writeContent(t, dir, "image.pdf", "\x89PNG", time.Hour)
+	writeFile(t, dir, ".hidden.pdf", time.Hour)
+	// the scanner might still write the file
+	writeFile(t, dir, "recent.pdf", 0)
+	// a file of the same name was processed before
+	writeFile(t, filepath.Join(dir, "processed"), "scan_2024-01-01.pdf", time.Hour)
+
+	r, err := w.Scan(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, inbox.Result{Processed: 1, Failed: 1}, r)
+
+	assert.Len(t, docSvc.saved, 1)
+	draft := docSvc.saved[0]
+	assert.Equal(t, "scan 2024 01 01", draft.Title)
+	assert.Equal(t, "scan_2024-01-01.pdf", draft.FileName)
+	assert.NotEmpty(t, draft.UploadToken)
+	assert.True(t, draft.NeedsReview)
+
+	assert.ElementsMatch(t, []string{".hidden.pdf", "recent.pdf", "notes.txt", "scan.tmp"}, names(t, dir))
+	assert.ElementsMatch(t, []string{"scan_2024-01-01.pdf", "scan_2024-01-01-2.pdf"}, names(t, filepath.Join(dir, "processed")))
+	assert.ElementsMatch(t, []string{"image.pdf"}, names(t, filepath.Join(dir, "failed")))
+}
+
+func TestScanFailedDocument(t *testing.T) {
+	dir := t.TempDir()
+	failed := t.TempDir()
+	docSvc := &mockDocumentService{err: fmt.Errorf("filestore not available")}
+	w, err := inbox.NewWatcher(inbox.Options{Path: dir, Failed: failed}, docSvc, newUploadService(t), logging.NewNop())
+	if err != nil {
+		t.Fatalf("could not create the watcher: %v", err)
+	}
+	writeFile(t, dir, "letter.pdf", time.Hour)
+
+	r, err := w.Scan(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, inbox.Result{Failed: 1}, r)
+	assert.Empty(t, names(t, dir))
+	assert.Equal(t, []string{"letter.pdf"}, names(t, failed))
+}
+
+func TestScanDuplicate(t *testing.T) {
+	dir := t.TempDir()
+	docSvc := &mockDocumentService{hashes: map[string]string{document.ContentHash([]byte("%PDF-1.0")): "1"}}
+	w, err := inbox.NewWatcher(inbox.Options{Path: dir}, docSvc, newUploadService(t), logging.NewNop())
+	if err != nil {
+		t.Fatalf("could not create the watcher: %v", err)
+	}
+	writeFile(t, dir, "letter.pdf", time.Hour)
+
+	r, err := w.Scan(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, inbox.Result{Duplicates: 1}, r)
+	assert.Empty(t, docSvc.saved)
+	assert.Equal(t, []string{"letter.pdf"}, names(t, filepath.Join(dir, "processed")))
+}
+
+func TestScanMoveFailed(t *testing.T) {
+	dir := t.TempDir()
+	processed := filepath.Join(t.TempDir(), "processed")
+	docSvc := &mockDocumentService{}
+	w, err := inbox.NewWatcher(inbox.Options{Path: dir, Processed: processed}, docSvc, newUploadService(t), logging.NewNop())
+	if err != nil {
+		t.Fatalf("could not create the watcher: %v", err)
+	}
+	writeFile(t, dir, "letter.pdf", time.Hour)
+	writeFile(t, dir, "invoice.pdf", time.Hour)
+	// the processed directory is not available
+	assert.NoError(t, os.Remove(processed))
+	assert.NoError(t, os.WriteFile(processed, nil, 0o640))
+
+	// the files are not ingested before they are moved, the scan goes on with the next file
+	r, err := w.Scan(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, inbox.Result{Failed: 2}, r)
+	assert.Empty(t, docSvc.saved)
+	assert.ElementsMatch(t, []string{"letter.pdf", "invoice.pdf"}, names(t, dir))
+}
+
+func TestNewWatcher(t *testing.T) {
+	_, err := inbox.NewWatcher(inbox.Options{}, &mockDocumentService{}, newUploadService(t), logging.NewNop())
+	assert.Error(t, err)
+}
@@ -0,0 +1,150 @@
+// Package pages changes the pages of PDF files: files are merged, split by page ranges, pages are
+// rotated or removed. The operations are done in memory by pdfcpu.
+package pages
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	pdfApi "github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// Range is a range of pages, the first page is 1. The range includes the last page.
+type Range struct {
+	From int
+	To   int
+}
+
+func (r Range) String() string {
+	if r.From == r.To {
+		return strconv.Itoa(r.From)
+	}
+	return fmt.Sprintf("%d-%d", r.From, r.To)
+}
+
+// ParseRanges parses a comma separated list of pages and page ranges like '1-3, 5, 7-'.
+// An open range ends with the last page. The ranges need to be within the number of pages.
+func ParseRanges(value string, count int) ([]Range, error) {
+	var ranges []Range
+	for part := range strings.SplitSeq(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		from, to, isRange := strings.Cut(part, "-")
+		r := Range{}
+		var err error
+		if r.From, err = strconv.Atoi(strings.TrimSpace(from)); err != nil {
+			return nil, fmt.Errorf("the page range '%s' is not valid", part)
+		}
+		r.To = r.From
+		if isRange {
+			r.To = count
+			if to = strings.TrimSpace(to); to != "" {
+				if r.To, err = strconv.Atoi(to); err != nil {
+					return nil, fmt.Errorf("the page range '%s' is not valid", part)
+				}
+			}
+		}
+		if r.From < 1 || r.To < r.From || r.To > count {
+			return nil, fmt.Errorf("the page range '%s' is not within the %d pages", part, count)
+		}
+		ranges = append(ranges, r)
+	}
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("no pages are specified")
+	}
+	return ranges, nil
+}
+
+// IsPDF checks the header of the payload
+func IsPDF(payload []byte) bool {
+	return bytes.HasPrefix(payload, []byte("%PDF-"))
+}
+
+// Count returns the number of pages
+func Count(payload []byte) (int, error) {
+	n, err := pdfApi.PageCount(bytes.NewReader(payload), config())
+	if err != nil {
+		return 0, fmt.Errorf("could not read the pages of the PDF: %v", err)
+	}
+	return n, nil
+}
+
+// Merge appends the pages of the files in the given order
+func Merge(payloads ...[]byte) ([]byte, error) {
+	if len(payloads) < 2 {
+		return nil, fmt.Errorf("at least two files are needed for a merge")
+	}
+	files := make([]io.ReadSeeker, 0, len(payloads))
+	for _, p := range payloads {
+		files = append(files, bytes.NewReader(p))
+	}
+	var buf bytes.Buffer
+	if err := pdfApi.MergeRaw(files, &buf, false, config()); err != nil {
+		return nil, fmt.Errorf("could not merge the PDF files: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Extract returns a file with the pages of the range
+func Extract(payload []byte, r Range) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := pdfApi.Trim(bytes.NewReader(payload), &buf, []string{r.String()}, config()); err != nil {
+		return nil, fmt.Errorf("could not extract the pages '%s': %v", r, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Rotate turns the pages clockwise by the given degrees, a multiple of 90
+func Rotate(payload []byte, ranges []Range, degrees int) ([]byte, error) {
+	if degrees%90 != 0 || degrees%360 == 0 {
+		return nil, fmt.Errorf("the rotation of %d degrees is not supported, use 90, 180 or 270", degrees)
+	}
+	var buf bytes.Buffer
+	if err := pdfApi.Rotate(bytes.NewReader(payload), &buf, degrees, selection(ranges), config()); err != nil {
+		return nil, fmt.Errorf("could not rotate the pages: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Remove deletes the pages of the ranges, at least one page needs to remain
+func Remove(payload []byte, ranges []Range) ([]byte, error) {
+	count, err := Count(payload)
+	if err != nil {
+		return nil, err
+	}
+	removed := make(map[int]bool)
+	for _, r := range ranges {
+		for p := r.From; p <= r.To; p++ {
+			removed[p] = true
+		}
+	}
+	if len(removed) >= count {
+		return nil, fmt.Errorf("all pages would be removed")
+	}
+	var buf bytes.Buffer
+	if err := pdfApi.RemovePages(bytes.NewReader(payload), &buf, selection(ranges), config()); err != nil {
+		return nil, fmt.Errorf("could not remove the pages: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func selection(ranges []Range) []string {
+	pages := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		pages = append(pages, r.String())
+	}
+	return pages
+}
+
+// config relaxes the validation, scanners do not always create valid files
+func config() *model.Configuration {
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+	return conf
+}
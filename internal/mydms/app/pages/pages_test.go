@@ -0,0 +1,111 @@
+package pages_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	pdfApi "github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/stretchr/testify/assert"
+	"golang.binggl.net/monorepo/internal/mydms/app/pages"
+)
+
+// newPDF creates a valid PDF with the given number of empty pages
+func newPDF(numPages int) []byte {
+	var (
+		buf     bytes.Buffer
+		offsets []int
+	)
+	obj := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+	buf.WriteString("%PDF-1.4\n")
+	kids := make([]string, 0, numPages)
+	for i := range numPages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", i+3))
+	}
+	obj("<</Type/Catalog/Pages 2 0 R>>")
+	obj(fmt.Sprintf("<</Type/Pages/Kids[%s]/Count %d>>", strings.Join(kids, " "), numPages))
+	for range numPages {
+		obj("<</Type/Page/Parent 2 0 R/MediaBox[0 0 200 300]>>")
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, o := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", o)
+	}
+	fmt.Fprintf(&buf, "trailer\n<</Size %d/Root 1 0 R>>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes()
+}
+
+func rotation(t *testing.T, payload []byte, page int) int {
+	ctx, err := pdfApi.ReadAndValidate(bytes.NewReader(payload), model.NewDefaultConfiguration())
+	if err != nil {
+		t.Fatalf("could not read the PDF; %v", err)
+	}
+	d, _, inh, err := ctx.PageDict(page, false)
+	if err != nil || d == nil {
+		t.Fatalf("could not read the page %d; %v", page, err)
+	}
+	return inh.Rotate
+}
+
+func TestParseRanges(t *testing.T) {
+	ranges, err := pages.ParseRanges(" 1-2, 4 ,6-", 8)
+	assert.NoError(t, err)
+	assert.Equal(t, []pages.Range{{From: 1, To: 2}, {From: 4, To: 4}, {From: 6, To: 8}}, ranges)
+	assert.Equal(t, "1-2", ranges[0].String())
+	assert.Equal(t, "4", ranges[1].String())
+
+	for _, invalid := range []string{"", " , ", "a", "1-b", "0", "3-2", "9", "2-9"} {
+		_, err = pages.ParseRanges(invalid, 8)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestMergeAndExtract(t *testing.T) {
+	payload, err := pages.Merge(newPDF(1), newPDF(2))
+	assert.NoError(t, err)
+	assert.True(t, pages.IsPDF(payload))
+	n, err := pages.Count(payload)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	_, err = pages.Merge(newPDF(1))
+	assert.Error(t, err)
+	_, err = pages.Merge(newPDF(1), []byte("no pdf"))
+	assert.Error(t, err)
+
+	part, err := pages.Extract(payload, pages.Range{From: 2, To: 3})
+	assert.NoError(t, err)
+	n, _ = pages.Count(part)
+	assert.Equal(t, 2, n)
+
+	_, err = pages.Count([]byte("no pdf"))
+	assert.Error(t, err)
+}
+
+func TestRotateAndRemove(t *testing.T) {
+	payload := newPDF(3)
+
+	rotated, err := pages.Rotate(payload, []pages.Range{{From: 2, To: 2}}, 90)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, rotation(t, rotated, 1))
+	assert.Equal(t, 90, rotation(t, rotated, 2))
+	_, err = pages.Rotate(payload, []pages.Range{{From: 1, To: 1}}, 45)
+	assert.Error(t, err)
+	_, err = pages.Rotate(payload, []pages.Range{{From: 1, To: 1}}, 360)
+	assert.Error(t, err)
+
+	removed, err := pages.Remove(rotated, []pages.Range{{From: 1, To: 1}, {From: 3, To: 3}})
+	assert.NoError(t, err)
+	n, _ := pages.Count(removed)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 90, rotation(t, removed, 1))
+
+	_, err = pages.Remove(payload, []pages.Range{{From: 1, To: 3}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,161 @@
+// Package preview creates thumbnail images of the stored documents.
+package preview
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // register the decoder
+	"image/jpeg"
+	_ "image/png" // register the decoder
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+
+	pdfApi "github.com/pdfcpu/pdfcpu/pkg/api"
+	"golang.org/x/image/draw"
+)
+
+// DefaultWidth of a thumbnail in pixels
+const DefaultWidth = 200
+
+// ErrNoPreview is returned if no thumbnail can be created for the payload
+var ErrNoPreview = errors.New("no preview available")
+
+// Generator creates a thumbnail for the payload of a document
+type Generator interface {
+	// Thumbnail returns a JPEG image of the first page of a PDF or of an image
+	Thumbnail(ctx context.Context, payload []byte) ([]byte, error)
+}
+
+// Options configure the Generator
+type Options struct {
+	// Width of the thumbnail, the height is derived from the aspect-ratio
+	Width int
+	// Renderer is the path of the pdftoppm executable used to render the first page of a PDF.
+	// Without a renderer the largest image embedded in the first page is used.
+	Renderer string
+}
+
+// NewGenerator returns a Generator using the given options
+func NewGenerator(opts Options) Generator {
+	if opts.Width <= 0 {
+		opts.Width = DefaultWidth
+	}
+	return &generator{opts: opts}
+}
+
+// compile guard for Generator implementation
+var (
+	_ Generator = &generator{}
+)
+
+type generator struct {
+	opts Options
+}
+
+func (g *generator) Thumbnail(ctx context.Context, payload []byte) ([]byte, error) {
+	var (
+		img image.Image
+		err error
+	)
+	if bytes.HasPrefix(payload, []byte("%PDF-")) {
+		img, err = g.firstPage(ctx, payload)
+	} else {
+		img, _, err = image.Decode(bytes.NewReader(payload))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return g.scale(img)
+}
+
+// scale resizes the image to the configured width and encodes it as JPEG
+func (g *generator) scale(src image.Image) ([]byte, error) {
+	b := src.Bounds()
+	if b.Dx() == 0 || b.Dy() == 0 {
+		return nil, ErrNoPreview
+	}
+	height := max(1, b.Dy()*g.opts.Width/b.Dx())
+	dst := image.NewRGBA(image.Rect(0, 0, g.opts.Width, height))
+	// transparent areas are shown on white paper
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.ApproxBiLinear.Scale(dst, dst.Bounds(), src, b, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, fmt.Errorf("could not encode the preview: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (g *generator) firstPage(ctx context.Context, payload []byte) (image.Image, error) {
+	if g.opts.Renderer != "" {
+		return g.render(ctx, payload)
+	}
+	return embeddedImage(payload)
+}
+
+// render uses pdftoppm to rasterize the first page of the PDF
+func (g *generator) render(ctx context.Context, payload []byte) (image.Image, error) {
+	dir, err := os.MkdirTemp("", "mydms-preview")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "document.pdf")
+	if err = os.WriteFile(input, payload, 0600); err != nil {
+		return nil, err
+	}
+	output := filepath.Join(dir, "page")
+	cmd := exec.CommandContext(ctx, g.opts.Renderer, "-f", "1", "-l", "1", "-singlefile",
+		"-png", "-scale-to-x", strconv.Itoa(g.opts.Width), "-scale-to-y", "-1", input, output)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("could not render the PDF: %v; %s", err, out)
+	}
+
+	rendered, err := os.ReadFile(output + ".png")
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(rendered))
+	return img, err
+}
+
+// embeddedImage returns the largest image of the first page, scanned documents are
+// typically a single image per page
+func embeddedImage(payload []byte) (image.Image, error) {
+	pages, err := pdfApi.ExtractImagesRaw(bytes.NewReader(payload), []string{"1"}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the images of the PDF: %v", err)
+	}
+
+	var (
+		largest image.Image
+		size    int
+	)
+	for _, images := range pages {
+		for _, i := range images {
+			if i.FileType != "jpg" && i.FileType != "png" {
+				continue
+			}
+			// the raw images do not provide the dimensions
+			img, _, err := image.Decode(i)
+			if err != nil {
+				continue
+			}
+			if s := img.Bounds().Dx() * img.Bounds().Dy(); s > size {
+				largest, size = img, s
+			}
+		}
+	}
+	if largest == nil {
+		return nil, ErrNoPreview
+	}
+	return largest, nil
+}
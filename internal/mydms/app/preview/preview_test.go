@@ -0,0 +1,68 @@
+package preview_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.binggl.net/monorepo/internal/mydms/app/preview"
+)
+
+func decode(t *testing.T, payload []byte) image.Image {
+	img, err := jpeg.Decode(bytes.NewReader(payload))
+	if err != nil {
+		t.Fatalf("the preview is not a JPEG: %v", err)
+	}
+	return img
+}
+
+func TestThumbnail_Image(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 400, 800))
+	for x := range 400 {
+		for y := range 800 {
+			src.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+
+	thumb, err := preview.NewGenerator(preview.Options{}).Thumbnail(context.TODO(), buf.Bytes())
+	assert.NoError(t, err)
+	img := decode(t, thumb)
+	assert.Equal(t, preview.DefaultWidth, img.Bounds().Dx())
+	assert.Equal(t, 2*preview.DefaultWidth, img.Bounds().Dy())
+
+	thumb, err = preview.NewGenerator(preview.Options{Width: 50}).Thumbnail(context.TODO(), buf.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, 50, decode(t, thumb).Bounds().Dx())
+}
+
+func TestThumbnail_PDF(t *testing.T) {
+	payload, err := os.ReadFile("../../../../testdata/unencrypted.pdf")
+	if err != nil {
+		t.Fatalf("could not read the PDF: %v", err)
+	}
+	thumb, err := preview.NewGenerator(preview.Options{}).Thumbnail(context.TODO(), payload)
+	assert.NoError(t, err)
+	assert.Equal(t, preview.DefaultWidth, decode(t, thumb).Bounds().Dx())
+}
+
+func TestThumbnail_Unsupported(t *testing.T) {
+	gen := preview.NewGenerator(preview.Options{})
+	_, err := gen.Thumbnail(context.TODO(), []byte("just some text"))
+	assert.Error(t, err)
+	_, err = gen.Thumbnail(context.TODO(), []byte("%PDF-1.7 not really a PDF"))
+	assert.Error(t, err)
+
+	gen = preview.NewGenerator(preview.Options{Renderer: "/not/available/pdftoppm"})
+	_, err = gen.Thumbnail(context.TODO(), []byte("%PDF-1.7"))
+	assert.Error(t, err)
+}
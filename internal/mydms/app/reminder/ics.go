@@ -0,0 +1,127 @@
+package reminder
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+)
+
+// CalendarContentType is the mime-type of the calendar feed
+const CalendarContentType = "text/calendar; charset=utf-8"
+
+const (
+	icsDate     = "20060102"
+	icsDateTime = "20060102T150405Z"
+	// lines of the iCalendar format are folded after 75 octets
+	icsLineLength = 75
+)
+
+// WriteCalendar writes the documents as all-day events of an iCalendar (RFC 5545). An event is
+// placed at the due date, the reminder date is added as alarm. Documents having only a reminder
+// date are placed at the reminder date. The link of the events is created from the baseURL.
+func WriteCalendar(w io.Writer, docs []document.Document, baseURL string, now time.Time) error {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//binggl.net//mydms//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:mydms",
+	}
+	stamp := now.UTC().Format(icsDateTime)
+	for _, d := range docs {
+		lines = append(lines, event(d, baseURL, stamp)...)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, l := range lines {
+		if _, err := io.WriteString(w, fold(l)); err != nil {
+			return fmt.Errorf("could not write the calendar: %v", err)
+		}
+	}
+	return nil
+}
+
+func event(d document.Document, baseURL, stamp string) []string {
+	day, err := time.Parse(document.DateLayout, d.DueDate)
+	if err != nil {
+		if day, err = time.Parse(document.DateLayout, d.ReminderDate); err != nil {
+			return nil
+		}
+	}
+
+	var description []string
+	if len(d.Senders) > 0 {
+		description = append(description, "Senders: "+strings.Join(d.Senders, ", "))
+	}
+	if !d.Amount.IsZero() {
+		description = append(description, "Amount: "+d.Amount.String())
+	}
+	if d.InvoiceNumber != "" {
+		description = append(description, "Invoice number: "+d.InvoiceNumber)
+	}
+
+	lines := []string{
+		"BEGIN:VEVENT",
+		"UID:" + d.ID + "@mydms",
+		"DTSTAMP:" + stamp,
+		"DTSTART;VALUE=DATE:" + day.Format(icsDate),
+		"DTEND;VALUE=DATE:" + day.AddDate(0, 0, 1).Format(icsDate),
+		"SUMMARY:" + escape(d.DueType.Label()+": "+d.Title),
+	}
+	if len(description) > 0 {
+		lines = append(lines, "DESCRIPTION:"+escape(strings.Join(description, "\n")))
+	}
+	if baseURL != "" {
+		lines = append(lines, "URL:"+documentURL(baseURL, d))
+	}
+	if reminder, err := time.Parse(document.DateLayout, d.ReminderDate); err == nil && d.DueDate != "" {
+		// the alarm is raised in the morning of the reminder day
+		lines = append(lines,
+			"BEGIN:VALARM",
+			"ACTION:DISPLAY",
+			"DESCRIPTION:"+escape(d.Title),
+			"TRIGGER;VALUE=DATE-TIME:"+reminder.Add(8*time.Hour).Format(icsDateTime),
+			"END:VALARM",
+		)
+	}
+	return append(lines, "END:VEVENT")
+}
+
+// documentURL links to the search of the document in mydms
+func documentURL(baseURL string, d document.Document) string {
+	return strings.TrimSuffix(baseURL, "/") + "/mydms/upcoming#" + d.ID
+}
+
+// escape the special characters of TEXT values
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", "").Replace(s)
+}
+
+// fold splits the line into lines of at most 75 octets, the continued lines start with a space.
+// Multi-byte characters are not split.
+func fold(line string) string {
+	var b strings.Builder
+	limit := icsLineLength
+	for len(line) > limit {
+		i := limit
+		for i > 0 && !isRuneStart(line[i]) {
+			i--
+		}
+		b.WriteString(line[:i])
+		b.WriteString("\r\n ")
+		line = line[i:]
+		// the leading space counts as part of the continued line
+		limit = icsLineLength - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+	return b.String()
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,115 @@
+package reminder
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+)
+
+// SubscriberEntity represents a record in the persistence store
+type SubscriberEntity struct {
+	Username  string    `db:"username"`
+	Email     string    `db:"email"`
+	FeedToken string    `db:"feedtoken"`
+	Notify    bool      `db:"notify"`
+	Created   time.Time `db:"created"`
+}
+
+// Repository stores the users receiving the reminders
+type Repository interface {
+	shared.BaseRepository
+	// List returns all subscribers ordered by username
+	List(ctx context.Context) ([]SubscriberEntity, error)
+	Get(ctx context.Context, username string) (SubscriberEntity, error)
+	GetByToken(ctx context.Context, token string) (SubscriberEntity, error)
+	// Save creates the subscriber or replaces the settings of an existing subscriber
+	Save(ctx context.Context, s SubscriberEntity, a shared.Atomic) (SubscriberEntity, error)
+}
+
+const subscriberColumns = "username,email,feedtoken,notify,created"
+
+// the reminders were added after the documents, the table is created for existing databases
+const ddlSubscribers = `CREATE TABLE IF NOT EXISTS "REMINDER_SUBSCRIBERS" (
+	"username"	varchar(128) NOT NULL,
+	"email"	varchar(255) NOT NULL DEFAULT '',
+	"feedtoken"	varchar(64) NOT NULL UNIQUE,
+	"notify"	integer NOT NULL DEFAULT 0,
+	"created"	date NOT NULL,
+	PRIMARY KEY("username")
+)`
+
+// MigrateSchema creates the REMINDER_SUBSCRIBERS table if it is not available
+func MigrateSchema(ctx context.Context, c shared.Connection) error {
+	if _, err := c.ExecContext(ctx, ddlSubscribers); err != nil {
+		return fmt.Errorf("could not create the table of the reminder subscribers: %v", err)
+	}
+	return nil
+}
+
+// compiler interface check
+var _ Repository = (*dbRepository)(nil)
+
+// NewRepository creates a new instance using an existing connection
+func NewRepository(c shared.Connection) (Repository, error) {
+	if !c.Active {
+		return nil, fmt.Errorf("no repository connection available")
+	}
+	var repo Repository = &dbRepository{c}
+	repo = RepositoryTracingMiddleware()(repo)
+	return repo, nil
+}
+
+type dbRepository struct {
+	c shared.Connection
+}
+
+// CreateAtomic returns a new atomic object
+func (rw *dbRepository) CreateAtomic() (shared.Atomic, error) {
+	return rw.c.CreateAtomic()
+}
+
+func (rw *dbRepository) List(ctx context.Context) ([]SubscriberEntity, error) {
+	var subscribers []SubscriberEntity
+	if err := rw.c.SelectContext(ctx, &subscribers, "SELECT "+subscriberColumns+" FROM REMINDER_SUBSCRIBERS ORDER BY username ASC"); err != nil {
+		return nil, fmt.Errorf("could not get the subscribers: %v", err)
+	}
+	return subscribers, nil
+}
+
+func (rw *dbRepository) Get(ctx context.Context, username string) (s SubscriberEntity, err error) {
+	if err = rw.c.GetContext(ctx, &s, "SELECT "+subscriberColumns+" FROM REMINDER_SUBSCRIBERS WHERE username = ?", username); err != nil {
+		return s, fmt.Errorf("cannot get subscriber '%s': %w", username, err)
+	}
+	return s, nil
+}
+
+func (rw *dbRepository) GetByToken(ctx context.Context, token string) (s SubscriberEntity, err error) {
+	if err = rw.c.GetContext(ctx, &s, "SELECT "+subscriberColumns+" FROM REMINDER_SUBSCRIBERS WHERE feedtoken = ?", token); err != nil {
+		return s, fmt.Errorf("cannot get subscriber by feed token: %v", err)
+	}
+	return s, nil
+}
+
+func (rw *dbRepository) Save(ctx context.Context, s SubscriberEntity, a shared.Atomic) (r SubscriberEntity, err error) {
+	var atomic *shared.Atomic
+
+	defer func() {
+		err = shared.HandleTX(!a.Active, atomic, err)
+	}()
+
+	if atomic, err = shared.CheckTX(rw.c, &a); err != nil {
+		return
+	}
+
+	if s.Created.IsZero() {
+		s.Created = time.Now().UTC()
+	}
+	if _, err = atomic.NamedExecContext(ctx, "INSERT INTO REMINDER_SUBSCRIBERS ("+subscriberColumns+") VALUES (:username,:email,:feedtoken,:notify,:created) "+
+		"ON CONFLICT(username) DO UPDATE SET email=excluded.email,feedtoken=excluded.feedtoken,notify=excluded.notify", &s); err != nil {
+		err = fmt.Errorf("could not save the subscriber: %v", err)
+		return
+	}
+	return s, nil
+}
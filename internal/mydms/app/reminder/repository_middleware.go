@@ -0,0 +1,56 @@
+package reminder
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+	"golang.binggl.net/monorepo/pkg/tracing"
+)
+
+// RepositoryMiddleware is used to intercept the repository methods
+type RepositoryMiddleware func(Repository) Repository
+
+// RepositoryTracingMiddleware creates a child-span for every repository call
+func RepositoryTracingMiddleware() RepositoryMiddleware {
+	return func(next Repository) Repository {
+		return repoTracingMiddleware{next}
+	}
+}
+
+// compile guard for Repository implementation
+var (
+	_ Repository = &repoTracingMiddleware{}
+)
+
+type repoTracingMiddleware struct {
+	next Repository
+}
+
+func (t repoTracingMiddleware) CreateAtomic() (shared.Atomic, error) {
+	return t.next.CreateAtomic()
+}
+
+func (t repoTracingMiddleware) List(ctx context.Context) (r []SubscriberEntity, err error) {
+	ctx, span := tracing.Start(ctx, "reminder.Repository.List")
+	defer func() { tracing.End(span, err) }()
+	return t.next.List(ctx)
+}
+
+func (t repoTracingMiddleware) Get(ctx context.Context, username string) (r SubscriberEntity, err error) {
+	ctx, span := tracing.Start(ctx, "reminder.Repository.Get", attribute.String("subscriber.username", username))
+	defer func() { tracing.End(span, err) }()
+	return t.next.Get(ctx, username)
+}
+
+func (t repoTracingMiddleware) GetByToken(ctx context.Context, token string) (r SubscriberEntity, err error) {
+	ctx, span := tracing.Start(ctx, "reminder.Repository.GetByToken")
+	defer func() { tracing.End(span, err) }()
+	return t.next.GetByToken(ctx, token)
+}
+
+func (t repoTracingMiddleware) Save(ctx context.Context, s SubscriberEntity, a shared.Atomic) (r SubscriberEntity, err error) {
+	ctx, span := tracing.Start(ctx, "reminder.Repository.Save", attribute.String("subscriber.username", s.Username))
+	defer func() { tracing.End(span, err) }()
+	return t.next.Save(ctx, s, a)
+}
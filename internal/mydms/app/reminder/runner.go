@@ -0,0 +1,48 @@
+package reminder
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang.binggl.net/monorepo/pkg/logging"
+)
+
+// DefaultInterval is used if no interval between the checks of the reminders is set
+const DefaultInterval = time.Hour
+
+// Runner sends the due reminders periodically
+type Runner struct {
+	svc      Service
+	interval time.Duration
+	logger   logging.Logger
+}
+
+// NewRunner creates a Runner checking the reminders in the given interval
+func NewRunner(svc Service, interval time.Duration, logger logging.Logger) *Runner {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Runner{svc: svc, interval: interval, logger: logger}
+}
+
+// Run sends the reminders of the current day in the configured interval until the context is done.
+// A reminder is sent once, a missed reminder is sent with the next run.
+func (r *Runner) Run(ctx context.Context) {
+	r.logger.Info(fmt.Sprintf("checking the reminders every %s", r.interval))
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		res, err := r.svc.SendReminders(ctx, time.Now())
+		if err != nil {
+			r.logger.Error("could not send the reminders", logging.ErrV(err))
+		} else if res.Documents > 0 {
+			r.logger.Info(fmt.Sprintf("reminder: sent %d documents to %d subscribers", res.Documents, res.Recipients))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,225 @@
+// Package reminder informs the users about the due dates of their documents, either by mail or
+// by a calendar feed which can be subscribed in any calendar application.
+package reminder
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+	"golang.binggl.net/monorepo/pkg/logging"
+	"golang.binggl.net/monorepo/pkg/security"
+)
+
+// --------------------------------------------------------------------------
+// Service Definition
+// --------------------------------------------------------------------------
+
+// Service manages the subscriptions of the users and delivers the reminders
+type Service interface {
+	// Subscription returns the settings of the user, the user is subscribed on first access
+	Subscription(ctx context.Context, user security.User) (s Subscriber, err error)
+	// RenewFeed replaces the token of the calendar feed, the former feed is no longer available
+	RenewFeed(ctx context.Context, user security.User) (s Subscriber, err error)
+	// SetNotify enables or disables the reminder mails sent to the address of the user
+	SetNotify(ctx context.Context, user security.User, notify bool) (s Subscriber, err error)
+	// Calendar writes the open documents as iCalendar for the owner of the feed token
+	Calendar(ctx context.Context, token string, w io.Writer) (err error)
+	// SendReminders mails the reminders due at the given day to the subscribers and
+	// marks the documents as reminded
+	SendReminders(ctx context.Context, day time.Time) (r Result, err error)
+}
+
+// Subscriber holds the reminder settings of a user
+type Subscriber struct {
+	Username  string
+	Email     string
+	FeedToken string
+	// Notify is true if reminder mails are sent to the user
+	Notify bool
+}
+
+// Result counts the sent reminders
+type Result struct {
+	Documents  int
+	Recipients int
+	Failed     int
+}
+
+// NewService returns a Service with all of the expected middlewares wired in. Without a notifier
+// the reminders are only available by the calendar feed.
+func NewService(logger logging.Logger, repo Repository, docSvc document.Service, notifier Notifier, baseURL string) Service {
+	var svc Service
+	{
+		svc = &reminderService{
+			repo:     repo,
+			docSvc:   docSvc,
+			notifier: notifier,
+			baseURL:  baseURL,
+			logger:   logger,
+		}
+		svc = ServiceLoggingMiddleware(logger)(svc)
+	}
+	return svc
+}
+
+// --------------------------------------------------------------------------
+// Service implementation
+// --------------------------------------------------------------------------
+
+// compile time assertions for our service
+var (
+	_ Service = &reminderService{}
+)
+
+type reminderService struct {
+	repo     Repository
+	docSvc   document.Service
+	notifier Notifier
+	baseURL  string
+	logger   logging.Logger
+}
+
+func (s reminderService) Subscription(ctx context.Context, user security.User) (Subscriber, error) {
+	e, err := s.repo.Get(ctx, user.Username)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			s.logger.Error("Subscription: repository error", logging.ErrV(err))
+			return Subscriber{}, fmt.Errorf("cannot get the subscription of '%s'; %v", user.Username, err)
+		}
+		if e, err = s.save(ctx, SubscriberEntity{Username: user.Username}, user); err != nil {
+			return Subscriber{}, err
+		}
+	}
+	return subscriber(e), nil
+}
+
+func (s reminderService) RenewFeed(ctx context.Context, user security.User) (Subscriber, error) {
+	e, err := s.Subscription(ctx, user)
+	if err != nil {
+		return Subscriber{}, err
+	}
+	saved, err := s.save(ctx, SubscriberEntity{Username: e.Username, Notify: e.Notify}, user)
+	if err != nil {
+		return Subscriber{}, err
+	}
+	return subscriber(saved), nil
+}
+
+func (s reminderService) SetNotify(ctx context.Context, user security.User, notify bool) (Subscriber, error) {
+	if notify && user.Email == "" {
+		return Subscriber{}, shared.ErrValidation(fmt.Sprintf("no email address is available for '%s'", user.Username))
+	}
+	e, err := s.Subscription(ctx, user)
+	if err != nil {
+		return Subscriber{}, err
+	}
+	saved, err := s.save(ctx, SubscriberEntity{Username: e.Username, FeedToken: e.FeedToken, Notify: notify}, user)
+	if err != nil {
+		return Subscriber{}, err
+	}
+	return subscriber(saved), nil
+}
+
+func (s reminderService) Calendar(ctx context.Context, token string, w io.Writer) error {
+	if token == "" {
+		return shared.ErrNotFound("no feed token supplied")
+	}
+	if _, err := s.repo.GetByToken(ctx, token); err != nil {
+		return shared.ErrNotFound("the calendar feed is not available")
+	}
+	docs, err := s.docSvc.UpcomingDocuments(ctx, false)
+	if err != nil {
+		return err
+	}
+	return WriteCalendar(w, docs, s.baseURL, time.Now())
+}
+
+func (s reminderService) SendReminders(ctx context.Context, day time.Time) (r Result, err error) {
+	if s.notifier == nil {
+		return r, nil
+	}
+	subscribers, err := s.repo.List(ctx)
+	if err != nil {
+		s.logger.Error("SendReminders: repository error", logging.ErrV(err))
+		return r, fmt.Errorf("cannot get the subscribers; %v", err)
+	}
+	var recipients []string
+	for _, e := range subscribers {
+		if e.Notify && e.Email != "" {
+			recipients = append(recipients, e.Email)
+		}
+	}
+	if len(recipients) == 0 {
+		// the reminders stay open until a user subscribes
+		return r, nil
+	}
+
+	docs, err := s.docSvc.DueReminders(ctx, day)
+	if err != nil || len(docs) == 0 {
+		return r, err
+	}
+	r.Documents = len(docs)
+	for _, to := range recipients {
+		if err := s.notifier.Notify(ctx, to, docs); err != nil {
+			s.logger.Error("SendReminders: could not notify the subscriber", logging.ErrV(err))
+			r.Failed++
+			continue
+		}
+		r.Recipients++
+	}
+	if r.Recipients == 0 {
+		// the reminders are sent again with the next run
+		return r, fmt.Errorf("could not send the reminders to any of the %d subscribers", r.Failed)
+	}
+
+	ids := make([]string, 0, len(docs))
+	for _, d := range docs {
+		ids = append(ids, d.ID)
+	}
+	return r, s.docSvc.MarkReminded(ctx, ids)
+}
+
+// save stores the settings with the current address of the user, a missing feed token is created
+func (s reminderService) save(ctx context.Context, e SubscriberEntity, user security.User) (SubscriberEntity, error) {
+	e.Email = strings.TrimSpace(user.Email)
+	if e.FeedToken == "" {
+		token, err := newToken()
+		if err != nil {
+			return e, fmt.Errorf("could not create a feed token; %v", err)
+		}
+		e.FeedToken = token
+	}
+	saved, err := s.repo.Save(ctx, e, shared.Atomic{})
+	if err != nil {
+		s.logger.Error("could not save the subscriber", logging.ErrV(err))
+		return e, fmt.Errorf("could not save the subscription of '%s'; %v", e.Username, err)
+	}
+	return saved, nil
+}
+
+func subscriber(e SubscriberEntity) Subscriber {
+	return Subscriber{
+		Username:  e.Username,
+		Email:     e.Email,
+		FeedToken: e.FeedToken,
+		Notify:    e.Notify,
+	}
+}
+
+// newToken creates a random token of 256 bit, the token is the only protection of the feed
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,62 @@
+package reminder
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"time"
+
+	"golang.binggl.net/monorepo/pkg/logging"
+	"golang.binggl.net/monorepo/pkg/security"
+)
+
+// ServiceMiddleware describes a service (as opposed to endpoint) middleware.
+type ServiceMiddleware func(Service) Service
+
+// ServiceLoggingMiddleware takes a logger as a dependency
+// and returns a ServiceLoggingMiddleware.
+func ServiceLoggingMiddleware(logger logging.Logger) ServiceMiddleware {
+	return func(next Service) Service {
+		return loggingMiddleware{logger, next}
+	}
+}
+
+// compile guard for Service implementation
+var (
+	_ Service = &loggingMiddleware{}
+)
+
+type loggingMiddleware struct {
+	logger logging.Logger
+	next   Service
+}
+
+func (mw loggingMiddleware) Subscription(ctx context.Context, user security.User) (s Subscriber, err error) {
+	mw.logger.Info("Subscription", logging.LogV("param:user", user.Username))
+	defer mw.logger.Info("called Subscription", logging.ErrV(err))
+	return mw.next.Subscription(ctx, user)
+}
+
+func (mw loggingMiddleware) RenewFeed(ctx context.Context, user security.User) (s Subscriber, err error) {
+	mw.logger.Info("RenewFeed", logging.LogV("param:user", user.Username))
+	defer mw.logger.Info("called RenewFeed", logging.ErrV(err))
+	return mw.next.RenewFeed(ctx, user)
+}
+
+func (mw loggingMiddleware) SetNotify(ctx context.Context, user security.User, notify bool) (s Subscriber, err error) {
+	mw.logger.Info("SetNotify", logging.LogV("param:user", user.Username), logging.LogV("param:notify", strconv.FormatBool(notify)))
+	defer mw.logger.Info("called SetNotify", logging.ErrV(err))
+	return mw.next.SetNotify(ctx, user, notify)
+}
+
+func (mw loggingMiddleware) Calendar(ctx context.Context, token string, w io.Writer) (err error) {
+	mw.logger.Info("Calendar")
+	defer mw.logger.Info("called Calendar", logging.ErrV(err))
+	return mw.next.Calendar(ctx, token, w)
+}
+
+func (mw loggingMiddleware) SendReminders(ctx context.Context, day time.Time) (r Result, err error) {
+	mw.logger.Info("SendReminders", logging.LogV("param:day", day.Format(time.DateOnly)))
+	defer mw.logger.Info("called SendReminders", logging.ErrV(err))
+	return mw.next.SendReminders(ctx, day)
+}
@@ -0,0 +1,194 @@
+package reminder_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/internal/mydms/app/reminder"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared/sqlitetest"
+	"golang.binggl.net/monorepo/pkg/logging"
+	"golang.binggl.net/monorepo/pkg/security"
+)
+
+var user = security.User{Username: "user", Email: "user@example.com"}
+
+func setup(t *testing.T, notifier reminder.Notifier) (reminder.Service, document.Repository) {
+	con := sqlitetest.NewConn(t)
+	if err := reminder.MigrateSchema(context.TODO(), con); err != nil {
+		t.Fatalf("could not create the schema; %v", err)
+	}
+
+	docRepo, err := document.NewRepository(con)
+	if err != nil {
+		t.Fatalf("could not create the repository; %v", err)
+	}
+	repo, err := reminder.NewRepository(con)
+	if err != nil {
+		t.Fatalf("could not create the repository; %v", err)
+	}
+	docSvc := document.NewService(logging.NewNop(), docRepo, nil, nil, nil, nil)
+	return reminder.NewService(logging.NewNop(), repo, docSvc, notifier, "https://mydms.example.com"), docRepo
+}
+
+func saveDue(t *testing.T, repo document.Repository, title, due, reminderDate string) document.DocEntity {
+	d := document.DocEntity{Title: title, FileName: "/" + title + ".pdf", TagList: "invoice", DueType: sql.NullString{String: string(document.DuePayment), Valid: true}}
+	if due != "" {
+		day, _ := time.Parse(document.DateLayout, due)
+		d.DueDate = sql.NullTime{Time: day, Valid: true}
+	}
+	if reminderDate != "" {
+		day, _ := time.Parse(document.DateLayout, reminderDate)
+		d.ReminderDate = sql.NullTime{Time: day, Valid: true}
+	}
+	d, err := repo.Save(context.TODO(), d, shared.Atomic{})
+	if err != nil {
+		t.Fatalf("could not save document; %v", err)
+	}
+	return d
+}
+
+func TestSubscription(t *testing.T) {
+	svc, _ := setup(t, nil)
+
+	s, err := svc.Subscription(context.TODO(), user)
+	assert.NoError(t, err)
+	assert.Equal(t, "user", s.Username)
+	assert.Equal(t, "user@example.com", s.Email)
+	assert.Len(t, s.FeedToken, 64)
+	assert.False(t, s.Notify)
+
+	same, err := svc.Subscription(context.TODO(), user)
+	assert.NoError(t, err)
+	assert.Equal(t, s.FeedToken, same.FeedToken)
+
+	s, err = svc.SetNotify(context.TODO(), user, true)
+	assert.NoError(t, err)
+	assert.True(t, s.Notify)
+	assert.Equal(t, same.FeedToken, s.FeedToken)
+
+	renewed, err := svc.RenewFeed(context.TODO(), user)
+	assert.NoError(t, err)
+	assert.NotEqual(t, s.FeedToken, renewed.FeedToken)
+	assert.True(t, renewed.Notify)
+	var notFound *shared.NotFoundError
+	assert.ErrorAs(t, svc.Calendar(context.TODO(), s.FeedToken, &bytes.Buffer{}), &notFound)
+
+	var validation *shared.ValidationError
+	_, err = svc.SetNotify(context.TODO(), security.User{Username: "nomail"}, true)
+	assert.ErrorAs(t, err, &validation)
+}
+
+func TestCalendar(t *testing.T) {
+	svc, repo := setup(t, nil)
+	invoice := saveDue(t, repo, "Invoice, March", "2024-03-15", "2024-03-08")
+	saveDue(t, repo, "Contract", "", "2024-04-01")
+	saveDue(t, repo, "Plain", "", "")
+
+	var notFound *shared.NotFoundError
+	assert.ErrorAs(t, svc.Calendar(context.TODO(), "", &bytes.Buffer{}), &notFound)
+	assert.ErrorAs(t, svc.Calendar(context.TODO(), "unknown", &bytes.Buffer{}), &notFound)
+
+	s, _ := svc.Subscription(context.TODO(), user)
+	var buf bytes.Buffer
+	assert.NoError(t, svc.Calendar(context.TODO(), s.FeedToken, &buf))
+	ics := buf.String()
+	assert.True(t, strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(ics, "END:VCALENDAR\r\n"))
+	assert.Equal(t, 2, strings.Count(ics, "BEGIN:VEVENT"))
+	assert.Contains(t, ics, "UID:"+invoice.ID+"@mydms\r\n")
+	assert.Contains(t, ics, "DTSTART;VALUE=DATE:20240315\r\nDTEND;VALUE=DATE:20240316\r\n")
+	assert.Contains(t, ics, `SUMMARY:Payment: Invoice\, March`)
+	assert.Contains(t, ics, "TRIGGER;VALUE=DATE-TIME:20240308T080000Z\r\n")
+	assert.Contains(t, ics, "DTSTART;VALUE=DATE:20240401\r\n")
+	for _, l := range strings.Split(ics, "\r\n") {
+		assert.LessOrEqual(t, len(l), 75)
+	}
+}
+
+func TestWriteCalendarFolding(t *testing.T) {
+	var buf bytes.Buffer
+	title := strings.Repeat("Übersicht ", 12)
+	assert.NoError(t, reminder.WriteCalendar(&buf, []document.Document{{ID: "1", Title: title, DueDate: "2024-03-15"}}, "", time.Now()))
+	for _, l := range strings.Split(buf.String(), "\r\n") {
+		assert.LessOrEqual(t, len(l), 75)
+	}
+	unfolded := strings.ReplaceAll(buf.String(), "\r\n ", "")
+	assert.Contains(t, unfolded, "SUMMARY:Payment: "+title+"\r\n")
+}
+
+// failingNotifier counts the notifications and fails for the given recipient
+type failingNotifier struct {
+	fail  string
+	calls int
+}
+
+func (n *failingNotifier) Notify(ctx context.Context, to string, docs []document.Document) error {
+	n.calls++
+	if to == n.fail {
+		return assert.AnError
+	}
+	return nil
+}
+
+func TestSendReminders(t *testing.T) {
+	opts, mails := smtpServer(t)
+	svc, repo := setup(t, reminder.NewSMTPNotifier(opts))
+	day := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+	invoice := saveDue(t, repo, "Invoice", "2024-03-15", "2024-03-08")
+	saveDue(t, repo, "Later", "2024-04-15", "")
+
+	// without subscribers the reminders are kept
+	r, err := svc.SendReminders(context.TODO(), day)
+	assert.NoError(t, err)
+	assert.Equal(t, reminder.Result{}, r)
+
+	_, err = svc.SetNotify(context.TODO(), user, true)
+	assert.NoError(t, err)
+	_, err = svc.Subscription(context.TODO(), security.User{Username: "calendar-only", Email: "other@example.com"})
+	assert.NoError(t, err)
+
+	r, err = svc.SendReminders(context.TODO(), day)
+	assert.NoError(t, err)
+	assert.Equal(t, reminder.Result{Documents: 1, Recipients: 1}, r)
+	m := <-mails
+	assert.Equal(t, []string{"user@example.com"}, m.To)
+	assert.Contains(t, m.Data, "'Invoice' is due")
+	assert.Contains(t, m.Data, "/mydms/upcoming#"+invoice.ID)
+
+	// a reminder is sent once
+	r, err = svc.SendReminders(context.TODO(), day)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, r.Documents)
+	assert.Len(t, mails, 0)
+}
+
+func TestSendRemindersFailed(t *testing.T) {
+	notifier := &failingNotifier{fail: "user@example.com"}
+	svc, repo := setup(t, notifier)
+	day := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+	saveDue(t, repo, "Invoice", "2024-03-09", "")
+
+	_, err := svc.SetNotify(context.TODO(), user, true)
+	assert.NoError(t, err)
+
+	// the reminders are sent again if no subscriber was notified
+	r, err := svc.SendReminders(context.TODO(), day)
+	assert.Error(t, err)
+	assert.Equal(t, reminder.Result{Documents: 1, Failed: 1}, r)
+	r, _ = svc.SendReminders(context.TODO(), day)
+	assert.Equal(t, 1, r.Documents)
+	assert.Equal(t, 2, notifier.calls)
+
+	// without a notifier only the calendar is available
+	svc, _ = setup(t, nil)
+	r, err = svc.SendReminders(context.TODO(), day)
+	assert.NoError(t, err)
+	assert.Equal(t, reminder.Result{}, r)
+}
@@ -0,0 +1,96 @@
+package reminder
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+)
+
+// Notifier delivers the reminders of the documents to a recipient
+type Notifier interface {
+	Notify(ctx context.Context, to string, docs []document.Document) error
+}
+
+// SMTPOptions define the mail server used to send the reminders
+type SMTPOptions struct {
+	Host string
+	Port int
+	// Username and Password are used for the authentication, the server is used without
+	// authentication if no username is set
+	Username string
+	Password string
+	// From is the sender address of the reminders
+	From string
+	// BaseURL is the address of mydms used for the links of the documents
+	BaseURL string
+}
+
+// NewSMTPNotifier creates a Notifier sending plain-text mails. The connection is upgraded
+// with STARTTLS if the server supports it.
+func NewSMTPNotifier(opts SMTPOptions) Notifier {
+	if opts.Port == 0 {
+		opts.Port = 25
+	}
+	return &smtpNotifier{opts: opts}
+}
+
+type smtpNotifier struct {
+	opts SMTPOptions
+}
+
+// Notify sends one mail listing all documents
+func (n *smtpNotifier) Notify(_ context.Context, to string, docs []document.Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	var auth smtp.Auth
+	if n.opts.Username != "" {
+		auth = smtp.PlainAuth("", n.opts.Username, n.opts.Password, n.opts.Host)
+	}
+	addr := net.JoinHostPort(n.opts.Host, strconv.Itoa(n.opts.Port))
+	if err := smtp.SendMail(addr, auth, n.opts.From, []string{to}, n.message(to, docs, time.Now())); err != nil {
+		return fmt.Errorf("could not send the reminder to '%s': %v", to, err)
+	}
+	return nil
+}
+
+func (n *smtpNotifier) message(to string, docs []document.Document, now time.Time) []byte {
+	subject := fmt.Sprintf("mydms: %d documents are due", len(docs))
+	if len(docs) == 1 {
+		subject = fmt.Sprintf("mydms: '%s' is due", docs[0].Title)
+	}
+
+	var b strings.Builder
+	for _, h := range [][2]string{
+		{"From", n.opts.From},
+		{"To", to},
+		{"Subject", mime.QEncoding.Encode("utf-8", subject)},
+		{"Date", now.Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "text/plain; charset=utf-8"},
+		{"Content-Transfer-Encoding", "8bit"},
+	} {
+		fmt.Fprintf(&b, "%s: %s\r\n", h[0], h[1])
+	}
+	b.WriteString("\r\nThe following documents need your attention:\r\n")
+	for _, d := range docs {
+		fmt.Fprintf(&b, "\r\n- %s: %s\r\n", d.DueType.Label(), d.Title)
+		if d.DueDate != "" {
+			fmt.Fprintf(&b, "  due at %s\r\n", d.DueDate)
+		}
+		if !d.Amount.IsZero() {
+			fmt.Fprintf(&b, "  amount %s\r\n", d.Amount)
+		}
+		if n.opts.BaseURL != "" {
+			fmt.Fprintf(&b, "  %s\r\n", documentURL(n.opts.BaseURL, d))
+		}
+	}
+	return []byte(b.String())
+}
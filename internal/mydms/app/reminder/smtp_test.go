@@ -0,0 +1,128 @@
+package reminder_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/internal/mydms/app/reminder"
+)
+
+// mail is a message received by the smtpServer
+type mail struct {
+	From string
+	To   []string
+	Data string
+}
+
+// smtpServer is a local stand-in of a mail server accepting every message without authentication
+func smtpServer(t *testing.T) (reminder.SMTPOptions, <-chan mail) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not start the smtp server; %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	mails := make(chan mail, 10)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(conn, mails)
+		}
+	}()
+
+	addr := l.Addr().(*net.TCPAddr)
+	return reminder.SMTPOptions{
+		Host:    addr.IP.String(),
+		Port:    addr.Port,
+		From:    "mydms@example.com",
+		BaseURL: "https://mydms.example.com",
+	}, mails
+}
+
+func serveSMTP(conn net.Conn, mails chan<- mail) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+
+	var m mail
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			m = mail{From: strings.Trim(strings.TrimSpace(line)[10:], "<>")}
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			m.To = append(m.To, strings.Trim(strings.TrimSpace(line)[8:], "<>"))
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			m.Data = data.String()
+			mails <- m
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	opts, mails := smtpServer(t)
+	notifier := reminder.NewSMTPNotifier(opts)
+
+	assert.NoError(t, notifier.Notify(context.TODO(), "user@example.com", nil))
+	assert.Len(t, mails, 0)
+
+	err := notifier.Notify(context.TODO(), "user@example.com", []document.Document{
+		{ID: "1", Title: "Stromrechnung März", DueDate: "2024-03-15", DueType: document.DuePayment, Amount: document.Money{Minor: 4250, Currency: "EUR"}},
+		{ID: "2", Title: "Mobile", ReminderDate: "2024-03-01", DueType: document.DueContract},
+	})
+	assert.NoError(t, err)
+	m := <-mails
+	assert.Equal(t, "mydms@example.com", m.From)
+	assert.Equal(t, []string{"user@example.com"}, m.To)
+	assert.Contains(t, m.Data, "Subject: mydms: 2 documents are due\r\n")
+	assert.Contains(t, m.Data, "- Payment: Stromrechnung März\r\n  due at 2024-03-15\r\n  amount 42.50 EUR\r\n")
+	assert.Contains(t, m.Data, "- Contract end: Mobile\r\n")
+	assert.Contains(t, m.Data, "https://mydms.example.com/mydms/upcoming#1")
+
+	err = reminder.NewSMTPNotifier(reminder.SMTPOptions{Host: "127.0.0.1", Port: unusedPort(t)}).Notify(context.TODO(), "user@example.com", []document.Document{{ID: "1"}})
+	assert.Error(t, err)
+}
+
+func unusedPort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not find a free port; %v", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
@@ -0,0 +1,157 @@
+package rules
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+)
+
+// RuleEntity represents a record in the persistence store, the conditions are stored as JSON
+type RuleEntity struct {
+	ID            string         `db:"id"`
+	Name          string         `db:"name"`
+	Position      int            `db:"position"`
+	Enabled       bool           `db:"enabled"`
+	Conditions    string         `db:"conditions"`
+	TagList       string         `db:"taglist"`
+	SenderList    string         `db:"senderlist"`
+	TitleTemplate string         `db:"titletemplate"`
+	AmountMinor   sql.NullInt64  `db:"amountminor"`
+	Currency      sql.NullString `db:"currency"`
+	Created       time.Time      `db:"created"`
+	Modified      sql.NullTime   `db:"modified"`
+}
+
+// Repository is the CRUD interface for rules in the persistence store
+type Repository interface {
+	shared.BaseRepository
+	// List returns all rules ordered by position
+	List(ctx context.Context) ([]RuleEntity, error)
+	Get(ctx context.Context, id string) (RuleEntity, error)
+	Save(ctx context.Context, rule RuleEntity, a shared.Atomic) (RuleEntity, error)
+	Delete(ctx context.Context, id string, a shared.Atomic) error
+}
+
+const ruleColumns = "id,name,position,enabled,conditions,taglist,senderlist,titletemplate,amountminor,currency,created,modified"
+
+// the rules were added after the documents, the table is created for existing databases
+const ddlRules = `CREATE TABLE IF NOT EXISTS "RULES" (
+	"id"	varchar(36) NOT NULL,
+	"name"	varchar(255) NOT NULL,
+	"position"	integer NOT NULL DEFAULT 0,
+	"enabled"	integer NOT NULL DEFAULT 1,
+	"conditions"	text NOT NULL,
+	"taglist"	text NOT NULL DEFAULT '',
+	"senderlist"	text NOT NULL DEFAULT '',
+	"titletemplate"	varchar(255) NOT NULL DEFAULT '',
+	"amountminor"	integer,
+	"currency"	varchar(3),
+	"created"	date NOT NULL,
+	"modified"	date,
+	PRIMARY KEY("id")
+)`
+
+// MigrateSchema creates the RULES table if it is not available
+func MigrateSchema(ctx context.Context, c shared.Connection) error {
+	if _, err := c.ExecContext(ctx, ddlRules); err != nil {
+		return fmt.Errorf("could not create the table of the rules: %v", err)
+	}
+	return nil
+}
+
+// compiler interface check
+var _ Repository = (*dbRepository)(nil)
+
+// NewRepository creates a new instance using an existing connection
+func NewRepository(c shared.Connection) (Repository, error) {
+	if !c.Active {
+		return nil, fmt.Errorf("no repository connection available")
+	}
+	var repo Repository = &dbRepository{c}
+	repo = RepositoryTracingMiddleware()(repo)
+	return repo, nil
+}
+
+type dbRepository struct {
+	c shared.Connection
+}
+
+// CreateAtomic returns a new atomic object
+func (rw *dbRepository) CreateAtomic() (shared.Atomic, error) {
+	return rw.c.CreateAtomic()
+}
+
+func (rw *dbRepository) List(ctx context.Context) ([]RuleEntity, error) {
+	var rules []RuleEntity
+	if err := rw.c.SelectContext(ctx, &rules, "SELECT "+ruleColumns+" FROM RULES ORDER BY position ASC, name ASC"); err != nil {
+		return nil, fmt.Errorf("could not get the rules: %v", err)
+	}
+	return rules, nil
+}
+
+func (rw *dbRepository) Get(ctx context.Context, id string) (r RuleEntity, err error) {
+	if err = rw.c.GetContext(ctx, &r, "SELECT "+ruleColumns+" FROM RULES WHERE id = ?", id); err != nil {
+		return r, fmt.Errorf("cannot get rule by id '%s': %v", id, err)
+	}
+	return r, nil
+}
+
+// Save creates a new rule if no ID is supplied, otherwise the rule is updated
+func (rw *dbRepository) Save(ctx context.Context, rule RuleEntity, a shared.Atomic) (r RuleEntity, err error) {
+	var (
+		atomic *shared.Atomic
+		res    sql.Result
+	)
+
+	defer func() {
+		err = shared.HandleTX(!a.Active, atomic, err)
+	}()
+
+	if atomic, err = shared.CheckTX(rw.c, &a); err != nil {
+		return
+	}
+
+	if rule.ID == "" {
+		rule.ID = uuid.New().String()
+		rule.Created = time.Now().UTC()
+		res, err = atomic.NamedExecContext(ctx, "INSERT INTO RULES ("+ruleColumns+") VALUES (:id,:name,:position,:enabled,:conditions,:taglist,:senderlist,:titletemplate,:amountminor,:currency,:created,:modified)", &rule)
+	} else {
+		rule.Modified = sql.NullTime{Time: time.Now().UTC(), Valid: true}
+		res, err = atomic.NamedExecContext(ctx, "UPDATE RULES SET name=:name,position=:position,enabled=:enabled,conditions=:conditions,taglist=:taglist,senderlist=:senderlist,titletemplate=:titletemplate,amountminor=:amountminor,currency=:currency,modified=:modified WHERE id=:id", &rule)
+	}
+	if err != nil {
+		err = fmt.Errorf("could not save the rule: %v", err)
+		return
+	}
+	c, err := res.RowsAffected()
+	if err != nil {
+		err = fmt.Errorf("could not get affected rows: %v", err)
+		return
+	}
+	if c != 1 {
+		err = fmt.Errorf("invalid number of rows affected, got %d", c)
+		return
+	}
+	return rule, nil
+}
+
+func (rw *dbRepository) Delete(ctx context.Context, id string, a shared.Atomic) (err error) {
+	var atomic *shared.Atomic
+
+	defer func() {
+		err = shared.HandleTX(!a.Active, atomic, err)
+	}()
+
+	if atomic, err = shared.CheckTX(rw.c, &a); err != nil {
+		return
+	}
+
+	if _, err = atomic.ExecContext(ctx, "DELETE FROM RULES WHERE id = ?", id); err != nil {
+		err = fmt.Errorf("cannot delete the rule: %v", err)
+	}
+	return
+}
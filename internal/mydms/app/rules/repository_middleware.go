@@ -0,0 +1,56 @@
+package rules
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+	"golang.binggl.net/monorepo/pkg/tracing"
+)
+
+// RepositoryMiddleware is used to intercept the repository methods
+type RepositoryMiddleware func(Repository) Repository
+
+// RepositoryTracingMiddleware creates a child-span for every repository call
+func RepositoryTracingMiddleware() RepositoryMiddleware {
+	return func(next Repository) Repository {
+		return repoTracingMiddleware{next}
+	}
+}
+
+// compile guard for Repository implementation
+var (
+	_ Repository = &repoTracingMiddleware{}
+)
+
+type repoTracingMiddleware struct {
+	next Repository
+}
+
+func (t repoTracingMiddleware) CreateAtomic() (shared.Atomic, error) {
+	return t.next.CreateAtomic()
+}
+
+func (t repoTracingMiddleware) List(ctx context.Context) (r []RuleEntity, err error) {
+	ctx, span := tracing.Start(ctx, "rules.Repository.List")
+	defer func() { tracing.End(span, err) }()
+	return t.next.List(ctx)
+}
+
+func (t repoTracingMiddleware) Get(ctx context.Context, id string) (r RuleEntity, err error) {
+	ctx, span := tracing.Start(ctx, "rules.Repository.Get", attribute.String("rule.id", id))
+	defer func() { tracing.End(span, err) }()
+	return t.next.Get(ctx, id)
+}
+
+func (t repoTracingMiddleware) Save(ctx context.Context, rule RuleEntity, a shared.Atomic) (r RuleEntity, err error) {
+	ctx, span := tracing.Start(ctx, "rules.Repository.Save", attribute.String("rule.id", rule.ID))
+	defer func() { tracing.End(span, err) }()
+	return t.next.Save(ctx, rule, a)
+}
+
+func (t repoTracingMiddleware) Delete(ctx context.Context, id string, a shared.Atomic) (err error) {
+	ctx, span := tracing.Start(ctx, "rules.Repository.Delete", attribute.String("rule.id", id))
+	defer func() { tracing.End(span, err) }()
+	return t.next.Delete(ctx, id, a)
+}
@@ -0,0 +1,265 @@
+// Package rules classifies new documents by rules defined by the user. A rule matches the filename,
+// the text of the PDF, the title or the senders of a document and sets tags, senders, title and amount.
+package rules
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+)
+
+// Field of the document which is checked by a condition
+type Field string
+
+const (
+	// FileName is the name of the uploaded file
+	FileName Field = "filename"
+	// Text is the text extracted from the PDF
+	Text Field = "text"
+	// Title of the document
+	Title Field = "title"
+	// Sender matches if one of the senders of the document matches
+	Sender Field = "sender"
+)
+
+// Fields lists the available fields of the conditions
+var Fields = []Field{FileName, Text, Title, Sender}
+
+// Operator compares the field with the value of the condition
+type Operator string
+
+const (
+	// Contains matches if the field contains the value, the case is ignored
+	Contains Operator = "contains"
+	// Regex matches if the regular expression matches the field
+	Regex Operator = "regex"
+)
+
+// Operators lists the available operators of the conditions
+var Operators = []Operator{Contains, Regex}
+
+// AmountGroup is the name of the regex group used as amount of the document, e.g. (?P<amount>\d+,\d\d)
+const AmountGroup = "amount"
+
+// Condition of a rule
+type Condition struct {
+	Field    Field    `json:"field"`
+	Operator Operator `json:"operator"`
+	Value    string   `json:"value"`
+	// re is the compiled regular expression of the value
+	re *regexp.Regexp
+}
+
+// Rule sets the metadata of documents matching all of its conditions
+type Rule struct {
+	ID         string
+	Name       string
+	Position   int
+	Enabled    bool
+	Conditions []Condition
+	Tags       []string
+	Senders    []string
+	// TitleTemplate is a text/template, see TitleData for the available values
+	TitleTemplate string
+	// Amount is used if the matching text provides no amount
+	Amount   document.Money
+	Created  time.Time
+	Modified time.Time
+}
+
+// TitleData is available within the title template, e.g. "Invoice {{.Month}}/{{.Year}}"
+type TitleData struct {
+	Title    string
+	FileName string
+	Date     string
+	Year     string
+	Month    string
+	// Groups holds the named groups of the matching regular expressions
+	Groups map[string]string
+}
+
+// Input are the values of a document checked by the conditions
+type Input struct {
+	FileName string
+	Text     string
+	Title    string
+	Senders  []string
+}
+
+// NewInput returns the values of the document, the text is extracted separately
+func NewInput(doc document.Document, text string) Input {
+	return Input{
+		FileName: path.Base(doc.FileName),
+		Text:     text,
+		Title:    doc.Title,
+		Senders:  doc.Senders,
+	}
+}
+
+// Validate checks the conditions and the actions of the rule and compiles the regular expressions
+func (r *Rule) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("a name is required")
+	}
+	if len(r.Conditions) == 0 {
+		return fmt.Errorf("at least one condition is required")
+	}
+	for i := range r.Conditions {
+		c := &r.Conditions[i]
+		if !slices.Contains(Fields, c.Field) {
+			return fmt.Errorf("the field '%s' is not supported", c.Field)
+		}
+		if c.Value == "" {
+			return fmt.Errorf("the condition on '%s' has no value", c.Field)
+		}
+		switch c.Operator {
+		case Contains:
+		case Regex:
+			if err := c.compile(); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("the operator '%s' is not supported", c.Operator)
+		}
+	}
+	if len(r.Tags) == 0 && len(r.Senders) == 0 && r.TitleTemplate == "" && r.Amount.IsZero() && !r.UsesGroup(AmountGroup) {
+		return fmt.Errorf("the rule does not set any value")
+	}
+	if r.TitleTemplate != "" {
+		if _, err := template.New("title").Parse(r.TitleTemplate); err != nil {
+			return fmt.Errorf("invalid title template: %v", err)
+		}
+	}
+	return nil
+}
+
+// compile prepares the regular expressions of the conditions
+func (r *Rule) compile() error {
+	for i := range r.Conditions {
+		if err := r.Conditions[i].compile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Condition) compile() error {
+	c.re = nil
+	if c.Operator != Regex {
+		return nil
+	}
+	re, err := regexp.Compile(c.Value)
+	if err != nil {
+		return fmt.Errorf("invalid regular expression '%s': %v", c.Value, err)
+	}
+	c.re = re
+	return nil
+}
+
+// UsesGroup is true if a compiled regular expression of the rule defines the named group
+func (r Rule) UsesGroup(name string) bool {
+	for _, c := range r.Conditions {
+		if c.Operator != Regex {
+			continue
+		}
+		if c.re != nil && slices.Contains(c.re.SubexpNames(), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// UsesText is true if a condition needs the text of the PDF
+func (r Rule) UsesText() bool {
+	return slices.ContainsFunc(r.Conditions, func(c Condition) bool { return c.Field == Text })
+}
+
+// Match checks all conditions and returns the named groups of the regular expressions
+func (r Rule) Match(in Input) (groups map[string]string, ok bool) {
+	groups = make(map[string]string)
+	for _, c := range r.Conditions {
+		var values []string
+		switch c.Field {
+		case FileName:
+			values = []string{in.FileName}
+		case Text:
+			values = []string{in.Text}
+		case Title:
+			values = []string{in.Title}
+		case Sender:
+			values = in.Senders
+		}
+		if !slices.ContainsFunc(values, func(v string) bool { return c.match(v, groups) }) {
+			return nil, false
+		}
+	}
+	return groups, true
+}
+
+func (c Condition) match(value string, groups map[string]string) bool {
+	if c.Operator == Contains {
+		return strings.Contains(strings.ToLower(value), strings.ToLower(c.Value))
+	}
+	// the expression is compiled by Validate or when the rule is loaded
+	if c.re == nil {
+		return false
+	}
+	m := c.re.FindStringSubmatch(value)
+	if m == nil {
+		return false
+	}
+	for i, name := range c.re.SubexpNames() {
+		if name != "" && m[i] != "" {
+			groups[name] = m[i]
+		}
+	}
+	return true
+}
+
+// title executes the template of the rule
+func (r Rule) title(doc document.Document, groups map[string]string) (string, error) {
+	tmpl, err := template.New("title").Option("missingkey=zero").Parse(r.TitleTemplate)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, TitleData{
+		Title:    doc.Title,
+		FileName: path.Base(doc.FileName),
+		Date:     now.Format("2006-01-02"),
+		Year:     now.Format("2006"),
+		Month:    now.Format("01"),
+		Groups:   groups,
+	})
+	return strings.TrimSpace(buf.String()), err
+}
+
+// amount returns the amount of the matching text or the amount of the rule
+func (r Rule) amount(groups map[string]string) (document.Money, error) {
+	if v, ok := groups[AmountGroup]; ok {
+		currency := r.Amount.Currency
+		if currency == "" {
+			currency = document.DefaultCurrency
+		}
+		return document.ParseMoney(v, currency)
+	}
+	return r.Amount, nil
+}
+
+// mergeList adds the values missing in the list, the case is ignored
+func mergeList(list, values []string) []string {
+	for _, v := range values {
+		if !slices.ContainsFunc(list, func(e string) bool { return strings.EqualFold(e, v) }) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
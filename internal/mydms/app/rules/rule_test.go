@@ -0,0 +1,111 @@
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+)
+
+func TestValidate(t *testing.T) {
+	valid := Rule{
+		Name:       "energy",
+		Conditions: []Condition{{Field: FileName, Operator: Contains, Value: "strom"}},
+		Tags:       []string{"energy"},
+	}
+	assert.NoError(t, valid.Validate())
+
+	invalid := valid
+	invalid.Name = " "
+	assert.Error(t, invalid.Validate())
+
+	invalid = valid
+	invalid.Conditions = nil
+	assert.Error(t, invalid.Validate())
+
+	invalid = valid
+	invalid.Conditions = []Condition{{Field: "author", Operator: Contains, Value: "a"}}
+	assert.Error(t, invalid.Validate())
+
+	invalid = valid
+	invalid.Conditions = []Condition{{Field: Text, Operator: Regex, Value: "(unclosed"}}
+	assert.Error(t, invalid.Validate())
+
+	invalid = valid
+	invalid.Tags = nil
+	assert.Error(t, invalid.Validate())
+
+	invalid = valid
+	invalid.TitleTemplate = "{{.Year"
+	assert.Error(t, invalid.Validate())
+
+	// the amount of the text is a value of the rule
+	amount := valid
+	amount.Tags = nil
+	amount.Conditions = []Condition{{Field: Text, Operator: Regex, Value: `Total (?P<amount>\d+,\d\d)`}}
+	assert.NoError(t, amount.Validate())
+	assert.NotNil(t, amount.Conditions[0].re)
+}
+
+func TestMatch(t *testing.T) {
+	rule := Rule{
+		Conditions: []Condition{
+			{Field: FileName, Operator: Contains, Value: "INVOICE"},
+			{Field: Text, Operator: Regex, Value: `No\. (?P<number>\d+)`},
+			{Field: Sender, Operator: Contains, Value: "power"},
+		},
+	}
+	assert.NoError(t, rule.compile())
+	groups, ok := rule.Match(Input{
+		FileName: "invoice_2024.pdf",
+		Text:     "Invoice No. 4711 of January",
+		Senders:  []string{"Shop", "Power Corp"},
+	})
+	assert.True(t, ok)
+	assert.Equal(t, "4711", groups["number"])
+
+	// all conditions need to match
+	_, ok = rule.Match(Input{
+		FileName: "invoice_2024.pdf",
+		Text:     "Invoice No. 4711 of January",
+		Senders:  []string{"Shop"},
+	})
+	assert.False(t, ok)
+}
+
+func TestApply(t *testing.T) {
+	rule := Rule{
+		Conditions:    []Condition{{Field: Text, Operator: Regex, Value: `Total (?P<amount>[\d.,]+)`}},
+		Tags:          []string{"Energy", "invoice"},
+		Senders:       []string{"Power Corp"},
+		TitleTemplate: "Energy {{.Year}} {{.Groups.number}}",
+		Amount:        document.Money{Currency: "USD"},
+	}
+	doc := document.Document{
+		Title:    "scan",
+		FileName: "/2024_01_01/scan.pdf",
+		Tags:     []string{"Invoice"},
+	}
+
+	result, err := rule.apply(doc, map[string]string{"amount": "12.50"}, true, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Invoice", "Energy"}, result.Tags)
+	assert.Equal(t, []string{"Power Corp"}, result.Senders)
+	assert.Equal(t, "Energy "+time.Now().Format("2006"), result.Title)
+	assert.Equal(t, document.Money{Minor: 1250, Currency: "USD"}, result.Amount)
+
+	// the title and the amount are kept
+	result, err = rule.apply(doc, map[string]string{"amount": "12.50"}, false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "scan", result.Title)
+	assert.True(t, result.Amount.IsZero())
+
+	_, err = rule.apply(doc, map[string]string{"amount": "twelve"}, true, true)
+	assert.Error(t, err)
+}
+
+func TestLiteralStrings(t *testing.T) {
+	content := []byte(`BT /F1 12 Tf 72 712 Td (Invoice \(copy\)) Tj T* [(Tot) -20 (al) ] TJ (12,50\040EUR) Tj ET`)
+	assert.Equal(t, "Invoice (copy) Total 12,50 EUR", literalStrings(content))
+}
@@ -0,0 +1,324 @@
+package rules
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/internal/mydms/app/filestore"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+	"golang.binggl.net/monorepo/pkg/logging"
+)
+
+// --------------------------------------------------------------------------
+// Service Definition
+// --------------------------------------------------------------------------
+
+// Service manages the rules and classifies documents
+type Service interface {
+	document.Classifier
+	// Rules returns all rules ordered by position
+	Rules(ctx context.Context) (r []Rule, err error)
+	// GetRule returns the rule specified by the given id
+	GetRule(ctx context.Context, id string) (r Rule, err error)
+	// SaveRule validates the rule and creates or updates it
+	SaveRule(ctx context.Context, rule Rule) (r Rule, err error)
+	// DeleteRule deletes the rule specified by the given id
+	DeleteRule(ctx context.Context, id string) (err error)
+	// TestRule applies the rule to the given documents, the rule does not need to be saved
+	TestRule(ctx context.Context, rule Rule, docs []document.Document) (r []TestResult, err error)
+}
+
+// TestResult is the outcome of a rule for a matching document
+type TestResult struct {
+	Document document.Document
+	// Result holds the values set by the rule
+	Result document.Document
+	Err    error
+}
+
+// NewService returns a Service with all of the expected middlewares wired in. The files are needed
+// for rules matching the text of the documents.
+func NewService(logger logging.Logger, repo Repository, fileSvc filestore.FileService, text TextExtractor) Service {
+	var svc Service
+	{
+		svc = &ruleService{
+			repo:    repo,
+			fileSvc: fileSvc,
+			text:    text,
+			logger:  logger,
+		}
+		svc = ServiceLoggingMiddleware(logger)(svc)
+	}
+	return svc
+}
+
+// --------------------------------------------------------------------------
+// Service implementation
+// --------------------------------------------------------------------------
+
+// compile time assertions for our service
+var (
+	_ Service = &ruleService{}
+)
+
+type ruleService struct {
+	repo    Repository
+	fileSvc filestore.FileService
+	text    TextExtractor
+	logger  logging.Logger
+}
+
+func (s ruleService) Rules(ctx context.Context) ([]Rule, error) {
+	entities, err := s.repo.List(ctx)
+	if err != nil {
+		s.logger.Error("Rules: repository error", logging.ErrV(err))
+		return nil, fmt.Errorf("cannot get the rules; %v", err)
+	}
+	rules := make([]Rule, 0, len(entities))
+	for _, e := range entities {
+		r, err := convertToDomain(e)
+		if err != nil {
+			s.logger.Warn(fmt.Sprintf("the rule '%s' is skipped", e.ID), logging.ErrV(err))
+			continue
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+func (s ruleService) GetRule(ctx context.Context, id string) (Rule, error) {
+	e, err := s.repo.Get(ctx, id)
+	if err != nil {
+		s.logger.Error("GetRule: repository error", logging.ErrV(err))
+		return Rule{}, shared.ErrNotFound(fmt.Sprintf("could not find rule by id: %s", id))
+	}
+	return convertToDomain(e)
+}
+
+func (s ruleService) SaveRule(ctx context.Context, rule Rule) (Rule, error) {
+	rule = sanitize(rule)
+	if err := rule.Validate(); err != nil {
+		return rule, shared.ErrValidation(err.Error())
+	}
+	e, err := convertToEntity(rule)
+	if err != nil {
+		return rule, err
+	}
+	if rule.ID != "" {
+		// the creation date is kept by the update
+		if _, err = s.repo.Get(ctx, rule.ID); err != nil {
+			return rule, shared.ErrNotFound(fmt.Sprintf("could not find rule by id: %s", rule.ID))
+		}
+	}
+	if e, err = s.repo.Save(ctx, e, shared.Atomic{}); err != nil {
+		s.logger.Error("SaveRule: repository error", logging.ErrV(err))
+		return rule, fmt.Errorf("error while saving the rule: %v", err)
+	}
+	return convertToDomain(e)
+}
+
+func (s ruleService) DeleteRule(ctx context.Context, id string) error {
+	if err := s.repo.Delete(ctx, id, shared.Atomic{}); err != nil {
+		s.logger.Error("DeleteRule: repository error", logging.ErrV(err))
+		return fmt.Errorf("could not delete the rule '%s'; %v", id, err)
+	}
+	return nil
+}
+
+// TestRule classifies the documents as if they were new drafts: the title template and the
+// amount of the rule replace the values of the documents.
+func (s ruleService) TestRule(ctx context.Context, rule Rule, docs []document.Document) ([]TestResult, error) {
+	rule = sanitize(rule)
+	if err := rule.Validate(); err != nil {
+		return nil, shared.ErrValidation(err.Error())
+	}
+	var results []TestResult
+	for _, doc := range docs {
+		text := ""
+		if rule.UsesText() {
+			text = s.documentText(ctx, doc.FileName)
+		}
+		groups, ok := rule.Match(NewInput(doc, text))
+		if !ok {
+			continue
+		}
+		draft := doc
+		draft.NeedsReview = true
+		draft.Amount = document.Money{}
+		result, err := rule.apply(draft, groups, true, true)
+		results = append(results, TestResult{Document: doc, Result: result, Err: err})
+	}
+	return results, nil
+}
+
+// Classify applies the enabled rules in the order of their position. Tags and senders of all
+// matching rules are added, the title and the amount are set by the first matching rule only.
+// The title is replaced if it is empty or if the document is a draft, an amount is only set
+// for documents without an amount.
+func (s ruleService) Classify(ctx context.Context, doc document.Document, payload []byte) document.Document {
+	rules, err := s.Rules(ctx)
+	if err != nil {
+		s.logger.Error("could not classify the document", logging.ErrV(err))
+		return doc
+	}
+
+	var (
+		text      string
+		extracted bool
+		titled    = doc.Title != "" && !doc.NeedsReview
+		amounted  = !doc.Amount.IsZero()
+	)
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		if rule.UsesText() && !extracted {
+			extracted = true
+			if text, err = s.text.Text(ctx, payload); err != nil {
+				s.logger.Warn(fmt.Sprintf("could not extract the text of '%s'", doc.FileName), logging.ErrV(err))
+			}
+		}
+		groups, ok := rule.Match(NewInput(doc, text))
+		if !ok {
+			continue
+		}
+		s.logger.Info(fmt.Sprintf("the rule '%s' matches the document '%s'", rule.Name, doc.FileName))
+		result, err := rule.apply(doc, groups, !titled, !amounted)
+		if err != nil {
+			s.logger.Warn(fmt.Sprintf("could not apply the rule '%s'", rule.Name), logging.ErrV(err))
+			continue
+		}
+		titled = titled || rule.TitleTemplate != ""
+		amounted = amounted || !result.Amount.IsZero()
+		doc = result
+	}
+	return doc
+}
+
+// documentText fetches the file of an existing document and extracts the text
+func (s ruleService) documentText(ctx context.Context, fileName string) string {
+	item, err := s.fileSvc.GetFile(ctx, fileName)
+	if err != nil {
+		s.logger.Warn(fmt.Sprintf("could not get the file '%s'", fileName), logging.ErrV(err))
+		return ""
+	}
+	text, err := s.text.Text(ctx, item.Payload)
+	if err != nil {
+		s.logger.Warn(fmt.Sprintf("could not extract the text of '%s'", fileName), logging.ErrV(err))
+	}
+	return text
+}
+
+// apply sets the values of the rule, the title and the amount only if requested
+func (r Rule) apply(doc document.Document, groups map[string]string, setTitle, setAmount bool) (document.Document, error) {
+	doc.Tags = mergeList(doc.Tags, r.Tags)
+	doc.Senders = mergeList(doc.Senders, r.Senders)
+	if setTitle && r.TitleTemplate != "" {
+		title, err := r.title(doc, groups)
+		if err != nil {
+			return doc, fmt.Errorf("could not create the title: %v", err)
+		}
+		if title != "" {
+			doc.Title = title
+		}
+	}
+	if setAmount {
+		amount, err := r.amount(groups)
+		if err != nil {
+			return doc, err
+		}
+		if !amount.IsZero() {
+			doc.Amount = amount
+		}
+	}
+	return doc, nil
+}
+
+// --------------------------------------------------------------------------
+// internal helpers
+// --------------------------------------------------------------------------
+
+func sanitize(r Rule) Rule {
+	r.Name = strings.TrimSpace(r.Name)
+	r.TitleTemplate = strings.TrimSpace(r.TitleTemplate)
+	conditions := make([]Condition, 0, len(r.Conditions))
+	for _, c := range r.Conditions {
+		c.Value = strings.TrimSpace(c.Value)
+		if c.Value != "" {
+			conditions = append(conditions, c)
+		}
+	}
+	r.Conditions = conditions
+	r.Tags = trimList(r.Tags)
+	r.Senders = trimList(r.Senders)
+	return r
+}
+
+func trimList(list []string) []string {
+	var trimmed []string
+	for _, v := range list {
+		// the lists of the documents are stored separated by ';'
+		v = strings.TrimSpace(strings.ReplaceAll(v, ";", ""))
+		if v != "" {
+			trimmed = mergeList(trimmed, []string{v})
+		}
+	}
+	return trimmed
+}
+
+func convertToEntity(r Rule) (RuleEntity, error) {
+	conditions, err := json.Marshal(r.Conditions)
+	if err != nil {
+		return RuleEntity{}, fmt.Errorf("could not serialize the conditions: %v", err)
+	}
+	e := RuleEntity{
+		ID:            r.ID,
+		Name:          r.Name,
+		Position:      r.Position,
+		Enabled:       r.Enabled,
+		Conditions:    string(conditions),
+		TagList:       strings.Join(r.Tags, ";"),
+		SenderList:    strings.Join(r.Senders, ";"),
+		TitleTemplate: r.TitleTemplate,
+	}
+	if !r.Amount.IsZero() {
+		e.AmountMinor = sql.NullInt64{Int64: r.Amount.Minor, Valid: true}
+	}
+	if r.Amount.Currency != "" {
+		e.Currency = sql.NullString{String: r.Amount.Currency, Valid: true}
+	}
+	return e, nil
+}
+
+func convertToDomain(e RuleEntity) (Rule, error) {
+	r := Rule{
+		ID:            e.ID,
+		Name:          e.Name,
+		Position:      e.Position,
+		Enabled:       e.Enabled,
+		Tags:          splitList(e.TagList),
+		Senders:       splitList(e.SenderList),
+		TitleTemplate: e.TitleTemplate,
+		Amount:        document.Money{Minor: e.AmountMinor.Int64, Currency: e.Currency.String},
+		Created:       e.Created,
+		Modified:      e.Modified.Time,
+	}
+	if err := json.Unmarshal([]byte(e.Conditions), &r.Conditions); err != nil {
+		return r, fmt.Errorf("invalid conditions of rule '%s': %v", e.ID, err)
+	}
+	if err := r.compile(); err != nil {
+		return r, fmt.Errorf("invalid conditions of rule '%s': %v", e.ID, err)
+	}
+	return r, nil
+}
+
+func splitList(list string) []string {
+	if list == "" {
+		return nil
+	}
+	return strings.Split(list, ";")
+}
@@ -0,0 +1,65 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/pkg/logging"
+)
+
+// ServiceMiddleware describes a service (as opposed to endpoint) middleware.
+type ServiceMiddleware func(Service) Service
+
+// ServiceLoggingMiddleware takes a logger as a dependency
+// and returns a ServiceLoggingMiddleware.
+func ServiceLoggingMiddleware(logger logging.Logger) ServiceMiddleware {
+	return func(next Service) Service {
+		return loggingMiddleware{logger, next}
+	}
+}
+
+// compile guard for Service implementation
+var (
+	_ Service = &loggingMiddleware{}
+)
+
+type loggingMiddleware struct {
+	logger logging.Logger
+	next   Service
+}
+
+func (mw loggingMiddleware) Rules(ctx context.Context) (r []Rule, err error) {
+	mw.logger.Info("Rules")
+	defer mw.logger.Info("called Rules", logging.ErrV(err))
+	return mw.next.Rules(ctx)
+}
+
+func (mw loggingMiddleware) GetRule(ctx context.Context, id string) (r Rule, err error) {
+	mw.logger.Info("GetRule", logging.LogV("param:ID", id))
+	defer mw.logger.Info("called GetRule", logging.ErrV(err))
+	return mw.next.GetRule(ctx, id)
+}
+
+func (mw loggingMiddleware) SaveRule(ctx context.Context, rule Rule) (r Rule, err error) {
+	mw.logger.Info("SaveRule", logging.LogV("param:ID", rule.ID), logging.LogV("param:name", rule.Name))
+	defer mw.logger.Info("called SaveRule", logging.ErrV(err))
+	return mw.next.SaveRule(ctx, rule)
+}
+
+func (mw loggingMiddleware) DeleteRule(ctx context.Context, id string) (err error) {
+	mw.logger.Info("DeleteRule", logging.LogV("param:ID", id))
+	defer mw.logger.Info("called DeleteRule", logging.ErrV(err))
+	return mw.next.DeleteRule(ctx, id)
+}
+
+func (mw loggingMiddleware) TestRule(ctx context.Context, rule Rule, docs []document.Document) (r []TestResult, err error) {
+	mw.logger.Info("TestRule", logging.LogV("param:name", rule.Name), logging.LogV("param:documents", fmt.Sprintf("%d", len(docs))))
+	defer mw.logger.Info("called TestRule", logging.ErrV(err))
+	return mw.next.TestRule(ctx, rule, docs)
+}
+
+func (mw loggingMiddleware) Classify(ctx context.Context, doc document.Document, payload []byte) document.Document {
+	mw.logger.Info("Classify", logging.LogV("param:fileName", doc.FileName))
+	return mw.next.Classify(ctx, doc, payload)
+}
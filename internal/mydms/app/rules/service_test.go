@@ -0,0 +1,156 @@
+package rules_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/internal/mydms/app/rules"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared/sqlitetest"
+	"golang.binggl.net/monorepo/pkg/logging"
+)
+
+// mockText returns the same text for every payload
+type mockText struct {
+	text  string
+	calls int
+}
+
+func (m *mockText) Text(ctx context.Context, payload []byte) (string, error) {
+	m.calls++
+	return m.text, nil
+}
+
+func newService(t *testing.T, text rules.TextExtractor) rules.Service {
+	con := sqlitetest.NewConn(t)
+	if err := rules.MigrateSchema(context.TODO(), con); err != nil {
+		t.Fatalf("could not create the schema; %v", err)
+	}
+	repo, err := rules.NewRepository(con)
+	if err != nil {
+		t.Fatalf("could not create the repository; %v", err)
+	}
+	return rules.NewService(logging.NewNop(), repo, nil, text)
+}
+
+func TestSaveRule(t *testing.T) {
+	svc := newService(t, &mockText{})
+
+	rule, err := svc.SaveRule(context.TODO(), rules.Rule{
+		Name:       " energy ",
+		Enabled:    true,
+		Conditions: []rules.Condition{{Field: rules.FileName, Operator: rules.Contains, Value: "strom"}, {Field: rules.Title, Operator: rules.Contains, Value: ""}},
+		Tags:       []string{" Energy ", "", "energy;"},
+		Amount:     document.Money{Minor: 1000, Currency: "EUR"},
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, rule.ID)
+	assert.Equal(t, "energy", rule.Name)
+	assert.Len(t, rule.Conditions, 1)
+	assert.Equal(t, []string{"Energy"}, rule.Tags)
+
+	rule.Position = 5
+	_, err = svc.SaveRule(context.TODO(), rule)
+	assert.NoError(t, err)
+
+	stored, err := svc.GetRule(context.TODO(), rule.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, stored.Position)
+	assert.Equal(t, document.Money{Minor: 1000, Currency: "EUR"}, stored.Amount)
+	assert.False(t, stored.Modified.IsZero())
+
+	_, err = svc.SaveRule(context.TODO(), rules.Rule{Name: "invalid"})
+	var validation *shared.ValidationError
+	assert.ErrorAs(t, err, &validation)
+
+	_, err = svc.SaveRule(context.TODO(), rules.Rule{ID: "unknown", Name: "energy", Conditions: rule.Conditions, Tags: rule.Tags})
+	var notFound *shared.NotFoundError
+	assert.ErrorAs(t, err, &notFound)
+
+	assert.NoError(t, svc.DeleteRule(context.TODO(), rule.ID))
+	list, err := svc.Rules(context.TODO())
+	assert.NoError(t, err)
+	assert.Len(t, list, 0)
+}
+
+func TestClassify(t *testing.T) {
+	text := &mockText{text: "Power Corp\nInvoice No. 4711\nTotal 1.234,56 EUR"}
+	svc := newService(t, text)
+
+	for i, r := range []rules.Rule{
+		{
+			Name:          "power",
+			Position:      1,
+			Enabled:       true,
+			Conditions:    []rules.Condition{{Field: rules.Text, Operator: rules.Regex, Value: `No\. (?P<number>\d+)[\s\S]*Total (?P<amount>[\d.,]+)`}},
+			Senders:       []string{"Power Corp"},
+			TitleTemplate: "Power {{.Groups.number}}",
+		},
+		{
+			Name:          "invoice",
+			Position:      2,
+			Enabled:       true,
+			Conditions:    []rules.Condition{{Field: rules.FileName, Operator: rules.Contains, Value: "scan"}},
+			Tags:          []string{"invoice"},
+			TitleTemplate: "Invoice",
+		},
+		{
+			Name:       "disabled",
+			Position:   3,
+			Conditions: []rules.Condition{{Field: rules.FileName, Operator: rules.Contains, Value: "scan"}},
+			Tags:       []string{"disabled"},
+		},
+	} {
+		if _, err := svc.SaveRule(context.TODO(), r); err != nil {
+			t.Fatalf("could not save rule %d; %v", i, err)
+		}
+	}
+
+	doc := svc.Classify(context.TODO(), document.Document{
+		FileName:    "scan_001.pdf",
+		Title:       "scan_001.pdf",
+		NeedsReview: true,
+	}, []byte("%PDF-"))
+	assert.Equal(t, "Power 4711", doc.Title)
+	assert.Equal(t, []string{"invoice"}, doc.Tags)
+	assert.Equal(t, []string{"Power Corp"}, doc.Senders)
+	assert.Equal(t, document.Money{Minor: 123456, Currency: "EUR"}, doc.Amount)
+	// the text is extracted once
+	assert.Equal(t, 1, text.calls)
+
+	// the values supplied by the user are kept
+	doc = svc.Classify(context.TODO(), document.Document{
+		FileName: "scan_002.pdf",
+		Title:    "My title",
+		Amount:   document.Money{Minor: 100, Currency: "USD"},
+	}, []byte("%PDF-"))
+	assert.Equal(t, "My title", doc.Title)
+	assert.Equal(t, document.Money{Minor: 100, Currency: "USD"}, doc.Amount)
+	assert.Equal(t, []string{"invoice"}, doc.Tags)
+}
+
+func TestTestRule(t *testing.T) {
+	svc := newService(t, &mockText{})
+	docs := []document.Document{
+		{ID: "1", Title: "Invoice January", FileName: "/2024_01_01/invoice.pdf", Amount: document.Money{Minor: 100, Currency: "EUR"}},
+		{ID: "2", Title: "Letter", FileName: "/2024_01_01/letter.pdf"},
+	}
+
+	results, err := svc.TestRule(context.TODO(), rules.Rule{
+		Name:          "invoices",
+		Conditions:    []rules.Condition{{Field: rules.Title, Operator: rules.Regex, Value: `Invoice (?P<month>\w+)`}},
+		TitleTemplate: "Invoice of {{.Groups.month}}",
+		Amount:        document.Money{Minor: 990, Currency: "EUR"},
+	}, docs)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "1", results[0].Document.ID)
+	assert.Equal(t, "Invoice of January", results[0].Result.Title)
+	assert.Equal(t, int64(990), results[0].Result.Amount.Minor)
+	assert.NoError(t, results[0].Err)
+
+	_, err = svc.TestRule(context.TODO(), rules.Rule{Name: "invalid"}, docs)
+	assert.Error(t, err)
+}
@@ -0,0 +1,169 @@
+package rules
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	pdfApi "github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// textPages limits the extraction to the first pages, which identify a document
+const textPages = 3
+
+// TextExtractor returns the text of a PDF
+type TextExtractor interface {
+	Text(ctx context.Context, payload []byte) (string, error)
+}
+
+// NewTextExtractor uses pdftotext if the path of the executable is given. Otherwise the text
+// shown by the content streams of the pages is used, which works for simple PDFs only.
+func NewTextExtractor(pdftotext string) TextExtractor {
+	return &textExtractor{pdftotext: pdftotext}
+}
+
+// compile guard for TextExtractor implementation
+var (
+	_ TextExtractor = &textExtractor{}
+)
+
+type textExtractor struct {
+	pdftotext string
+}
+
+func (t *textExtractor) Text(ctx context.Context, payload []byte) (string, error) {
+	if !bytes.HasPrefix(payload, []byte("%PDF-")) {
+		// images do not provide any text
+		return "", nil
+	}
+	if t.pdftotext != "" {
+		return t.run(ctx, payload)
+	}
+	return contentText(payload)
+}
+
+// run uses pdftotext to extract the text of the first pages
+func (t *textExtractor) run(ctx context.Context, payload []byte) (string, error) {
+	dir, err := os.MkdirTemp("", "mydms-text")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "document.pdf")
+	if err = os.WriteFile(input, payload, 0600); err != nil {
+		return "", err
+	}
+	cmd := exec.CommandContext(ctx, t.pdftotext, "-f", "1", "-l", strconv.Itoa(textPages), "-enc", "UTF-8", input, "-")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("could not extract the text of the PDF: %v; %s", err, stderr.String())
+	}
+	return string(out), nil
+}
+
+// contentText collects the literal strings of the text operators of the first pages
+func contentText(payload []byte) (string, error) {
+	conf := model.NewDefaultConfiguration()
+	conf.Cmd = model.EXTRACTCONTENT
+	pdf, err := pdfApi.ReadValidateAndOptimize(bytes.NewReader(payload), conf)
+	if err != nil {
+		return "", fmt.Errorf("could not read the PDF: %v", err)
+	}
+
+	var text []string
+	for page := 1; page <= min(pdf.PageCount, textPages); page++ {
+		r, err := pdfcpu.ExtractPageContent(pdf, page)
+		if err != nil {
+			return "", fmt.Errorf("could not read the content of page %d: %v", page, err)
+		}
+		content, err := io.ReadAll(r)
+		if err != nil {
+			return "", err
+		}
+		text = append(text, literalStrings(content))
+	}
+	return strings.Join(text, "\n"), nil
+}
+
+// literalStrings returns the strings (...) of a content stream. The strings of a TJ array are
+// joined, the bytes are interpreted as Latin-1 which matches the standard encodings for ASCII.
+func literalStrings(content []byte) string {
+	var (
+		b       strings.Builder
+		inArray bool
+	)
+	for i := 0; i < len(content); i++ {
+		switch content[i] {
+		case '[':
+			inArray = true
+		case ']':
+			inArray = false
+			b.WriteByte(' ')
+		case '(':
+			s, n := literal(content[i:])
+			b.WriteString(s)
+			if !inArray {
+				b.WriteByte(' ')
+			}
+			i += n - 1
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// literal decodes the string starting with '(' and returns the number of consumed bytes
+func literal(content []byte) (string, int) {
+	var (
+		b     strings.Builder
+		depth int
+	)
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '\\' && i+1 < len(content):
+			i++
+			switch e := content[i]; e {
+			case 'n', 'r', 't', 'f', 'b':
+				b.WriteByte(' ')
+			case '0', '1', '2', '3', '4', '5', '6', '7':
+				// up to three octal digits
+				j := i
+				for j < len(content) && j < i+3 && content[j] >= '0' && content[j] <= '7' {
+					j++
+				}
+				v, _ := strconv.ParseUint(string(content[i:j]), 8, 8)
+				b.WriteRune(rune(v))
+				i = j - 1
+			case '\r', '\n':
+				// line continuation
+			default:
+				b.WriteRune(rune(e))
+			}
+		case c == '(':
+			if depth > 0 {
+				b.WriteByte(c)
+			}
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return b.String(), i + 1
+			}
+			b.WriteByte(c)
+		default:
+			b.WriteRune(rune(c))
+		}
+	}
+	return b.String(), len(content)
+}
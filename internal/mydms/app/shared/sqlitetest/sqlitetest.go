@@ -0,0 +1,37 @@
+// Package sqlitetest provides a sqlite database with the schema of mydms for tests
+package sqlitetest
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+)
+
+// schemaFile is the schema of mydms, relative to the root of the repository
+const schemaFile = "testdata/sqlite/ddl__mydms.sql"
+
+// NewConn creates the schema of mydms in a new database file which is closed after the test.
+// The connections of the pool share the database file, in-memory databases are separate for
+// every connection and reads outside of a running transaction would not find the tables.
+func NewConn(t testing.TB) shared.Connection {
+	t.Helper()
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		t.Fatalf("could not locate the schema")
+	}
+	schema, err := os.ReadFile(filepath.Join(filepath.Dir(file), "../../../../..", schemaFile))
+	if err != nil {
+		t.Fatalf("could not read the schema; %v", err)
+	}
+	dbFile := filepath.Join(t.TempDir(), "mydms.db")
+	if err := os.WriteFile(dbFile, nil, 0o600); err != nil {
+		t.Fatalf("could not create the database; %v", err)
+	}
+	con := shared.NewConnForSqlite(dbFile)
+	t.Cleanup(func() { con.Close() })
+	con.DB.MustExec(string(schema))
+	return con
+}
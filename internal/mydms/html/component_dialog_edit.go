@@ -2,9 +2,9 @@ package html
 
 import (
 	_ "embed"
-	"fmt"
 
 	"golang.binggl.net/monorepo/internal/common"
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
 	g "maragu.dev/gomponents"
 	h "maragu.dev/gomponents/html"
 )
@@ -12,13 +12,17 @@ import (
 type Document struct {
 	ID            string
 	Title         ValidStr
-	Amount        ValidFloat
+	Amount        ValidStr
+	Currency      ValidStr
 	FileName      ValidStr
 	PreviewLink   ValidStr
 	UploadToken   ValidStr
 	Tags          ValidStrSlice
 	Senders       ValidStrSlice
 	InvoiceNumber ValidStr
+	DueDate       ValidStr
+	ReminderDate  ValidStr
+	DueType       ValidStr
 	Error         string
 	Close         bool
 }
@@ -35,11 +39,8 @@ type ValidStrSlice struct {
 	Message string
 }
 
-type ValidFloat struct {
-	Val     float32
-	Valid   bool
-	Message string
-}
+// currencies offered in the edit dialog, any ISO 4217 code can be entered
+var currencies = []string{"EUR", "USD", "CHF", "GBP"}
 
 //go:embed component_dialog_edit.css
 var component_dialog_edit_styles string
@@ -89,9 +90,13 @@ func EditDocumentDialog(doc Document, docDownload g.Node) g.Node {
 							h.Div(h.Class("col"),
 								h.Div(h.Class("input-group"),
 									h.Span(h.Class("input-group-text"), g.Text("Amount")),
-									h.Input(h.Type("text"), h.Class("form-control"), h.ID("document_amount"), h.Placeholder("Amount"), h.Name("doc-amount"), h.Value(fmt.Sprintf("%.f", doc.Amount.Val))),
-									h.Span(h.Class("input-group-text"), g.Text("€")),
+									h.Input(h.Type("text"), h.Class(common.ClassCond("form-control", "control_invalid", !doc.Amount.Valid)), h.ID("document_amount"), h.Placeholder("Amount"), h.Name("doc-amount"), h.Value(doc.Amount.Val), g.Attr("inputmode", "decimal")),
+									h.Input(h.Type("text"), h.Class(common.ClassCond("form-control document_currency", "control_invalid", !doc.Currency.Valid)), h.ID("document_currency"), h.Name("doc-currency"), h.Value(doc.Currency.Val), h.MaxLength("3"), g.Attr("list", "document_currencies"), h.Title("Currency (ISO 4217)")),
+									h.DataList(h.ID("document_currencies"), g.Map(currencies, func(c string) g.Node {
+										return h.Option(h.Value(c))
+									})),
 								),
+								g.If(doc.Amount.Message != "", h.Div(h.Class("form-text text-danger"), g.Text(doc.Amount.Message))),
 							),
 							h.Div(h.Class("col mb-3"),
 								h.Div(h.Class("input-group"),
@@ -100,6 +105,28 @@ func EditDocumentDialog(doc Document, docDownload g.Node) g.Node {
 								),
 							),
 						),
+						h.Div(h.Class("row"),
+							h.Div(h.Class("col"),
+								h.Div(h.Class("input-group"),
+									h.Span(h.Class("input-group-text"), h.I(h.Class("bi bi-calendar-event")), g.Text(" Due")),
+									h.Input(h.Type("date"), h.Class(common.ClassCond("form-control", "control_invalid", !doc.DueDate.Valid)), h.ID("document_duedate"), h.Name("doc-duedate"), h.Value(doc.DueDate.Val)),
+									h.Select(h.Class(common.ClassCond("form-select document_duetype", "control_invalid", !doc.DueType.Valid)), h.ID("document_duetype"), h.Name("doc-duetype"), h.Title("What is due"),
+										g.Map(document.DueTypes, func(t document.DueType) g.Node {
+											return h.Option(h.Value(string(t)), g.If(string(t) == doc.DueType.Val, h.Selected()), g.Text(t.Label()))
+										}),
+									),
+								),
+								g.If(doc.DueDate.Message != "", h.Div(h.Class("form-text text-danger"), g.Text(doc.DueDate.Message))),
+								g.If(doc.DueType.Message != "", h.Div(h.Class("form-text text-danger"), g.Text(doc.DueType.Message))),
+							),
+							h.Div(h.Class("col mb-3"),
+								h.Div(h.Class("input-group"),
+									h.Span(h.Class("input-group-text"), h.I(h.Class("bi bi-bell")), g.Text(" Reminder")),
+									h.Input(h.Type("date"), h.Class(common.ClassCond("form-control", "control_invalid", !doc.ReminderDate.Valid)), h.ID("document_reminderdate"), h.Name("doc-reminderdate"), h.Value(doc.ReminderDate.Val), h.Title("Without a reminder date the reminder is sent on the due date")),
+								),
+								g.If(doc.ReminderDate.Message != "", h.Div(h.Class("form-text text-danger"), g.Text(doc.ReminderDate.Message))),
+							),
+						),
 						h.Div(h.Class("mb-3"),
 							docDownload,
 						),
@@ -2,13 +2,19 @@ package html
 
 import (
 	_ "embed"
+	"fmt"
+	"net/url"
 
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/pkg/security"
 	g "maragu.dev/gomponents"
 	h "maragu.dev/gomponents/html"
 )
 
-func DocumentsContent(documentList g.Node, search string) g.Node {
+func DocumentsContent(documentList g.Node, search string, queryErrors []document.QueryError, csrfToken string) g.Node {
 	return h.Div(h.Class("container-fluid"),
+		QueryErrors(search, queryErrors),
+		bulkActions(csrfToken),
 		h.Form(
 			h.Input(h.Type("hidden"), h.Name("q"), h.Value(search)),
 			h.Div(
@@ -24,7 +30,8 @@ func DocumentsContent(documentList g.Node, search string) g.Node {
 	)
 }
 
-func DocumentsNavigation(search string) g.Node {
+// DocumentsNavigation shows the search and the actions, numDrafts is the size of the review queue
+func DocumentsNavigation(search string, numDrafts int) g.Node {
 	searchText := []g.Node{
 		g.Text("> mydms "),
 	}
@@ -48,6 +55,42 @@ func DocumentsNavigation(search string) g.Node {
 						),
 					),
 
+					g.If(numDrafts > 0, h.A(h.Class("btn btn-warning review_button"), h.Href("/mydms/review"), h.Title("Review the drafts"),
+						h.I(h.Class("bi bi-inbox")), g.Text(fmt.Sprintf(" %d", numDrafts)),
+					)),
+
+					h.A(h.Class("btn btn-outline-light reports_button"), h.Href("/mydms/reports"), h.Title("Reports"),
+						h.I(h.Class("bi bi-bar-chart")),
+					),
+
+					h.A(h.Class("btn btn-outline-light upcoming_button"), h.Href("/mydms/upcoming"), h.Title("Due dates & reminders"),
+						h.I(h.Class("bi bi-calendar-check")),
+					),
+
+					h.A(h.Class("btn btn-outline-light rules_button"), h.Href("/mydms/rules"), h.Title("Classification rules"),
+						h.I(h.Class("bi bi-funnel")),
+					),
+
+					h.A(h.Class("btn btn-outline-light lists_button"), h.Href("/mydms/lists/tags"), h.Title("Tags & senders"),
+						h.I(h.Class("bi bi-tags")),
+					),
+
+					h.A(h.Class("btn btn-outline-light maintenance_button"), h.Href("/mydms/maintenance"), h.Title("Duplicate documents"),
+						h.I(h.Class("bi bi-files")),
+					),
+
+					h.Div(h.Class("btn-group export_button"),
+						h.Button(h.Type("button"), h.Class("btn btn-outline-light dropdown-toggle"), h.Title("Export"),
+							g.Attr("data-bs-toggle", "dropdown"), g.Attr("aria-expanded", "false"),
+							h.I(h.Class("bi bi-download")),
+						),
+						h.Ul(h.Class("dropdown-menu dropdown-menu-end"),
+							exportLink(search, "csv", "bi-filetype-csv", "Index as CSV"),
+							exportLink(search, "xlsx", "bi-filetype-xlsx", "Index as XLSX"),
+							exportLink(search, "zip", "bi-file-zip", "Documents as ZIP"),
+						),
+					),
+
 					h.Button(h.Type("button"),
 						h.Class("btn btn-primary new_button"),
 						g.Attr("data-testid", "link-add-document"),
@@ -64,6 +107,80 @@ func DocumentsNavigation(search string) g.Node {
 	)
 }
 
+// exportLink downloads the documents of the current search in the given format
+func exportLink(search, format, icon, label string) g.Node {
+	query := url.Values{}
+	query.Set("format", format)
+	if search != "" {
+		query.Set("q", search)
+	}
+	return h.Li(h.A(h.Class("dropdown-item"), h.Href("/mydms/export?"+query.Encode()),
+		h.I(h.Class("bi "+icon)), g.Text(" "+label),
+	))
+}
+
+// bulkFormID is the form submitting the selected documents
+const bulkFormID = "bulk_form"
+
+// bulkActions changes the selected documents, the changes are reported by a toast message.
+// The download of the ZIP archive is not sent by htmx and needs the csrfToken.
+func bulkActions(csrfToken string) g.Node {
+	return h.Form(h.ID(bulkFormID), h.Class("bulk_actions"), h.Method("post"), h.Action("/mydms/bulk/zip"),
+		h.Input(h.Type("hidden"), h.Name(security.CSRFFormField), h.Value(csrfToken)),
+		h.Div(h.Class("input-group input-group-sm"),
+			h.Span(h.Class("input-group-text"), h.I(h.Class("bi bi-check2-square")), g.Text(" Selected documents")),
+			h.Select(h.Class("form-select bulk_action"), h.Name("action"),
+				h.Option(h.Value(string(document.BulkAddTags)), g.Text("Add tags")),
+				h.Option(h.Value(string(document.BulkRemoveTags)), g.Text("Remove tags")),
+				h.Option(h.Value(string(document.BulkSetSender)), g.Text("Set sender")),
+				h.Option(h.Value(string(document.BulkDelete)), g.Text("Delete")),
+				h.Option(h.Value(string(document.BulkMerge)), g.Text("Merge into new document")),
+			),
+			h.Input(h.Type("text"), h.Class("form-control"), h.Name("value"), h.Placeholder("tags separated by comma, the sender or the title")),
+			h.Button(h.Type("button"), h.Class("btn btn-primary"),
+				g.Attr("hx-post", "/mydms/bulk"),
+				g.Attr("hx-include", "#"+bulkFormID),
+				g.Attr("hx-swap", "none"),
+				g.Attr("hx-confirm", "Apply the action to the selected documents?"),
+				h.I(h.Class("bi bi-check2-all")), g.Text(" Apply"),
+			),
+			h.Button(h.Type("submit"), h.Class("btn btn-outline-light"), h.Title("Download the selected documents"),
+				h.I(h.Class("bi bi-file-zip")), g.Text(" ZIP"),
+			),
+		),
+	)
+}
+
+// QueryErrors shows the search query with the invalid fragments highlighted, the fragments are
+// ignored by the search
+func QueryErrors(query string, errs []document.QueryError) g.Node {
+	if len(errs) == 0 {
+		return nil
+	}
+	var (
+		parts []g.Node
+		pos   int
+	)
+	for _, e := range errs {
+		parts = append(parts,
+			g.Text(query[pos:e.Start]),
+			h.Mark(h.Class("query_error"), h.Title(e.Message), g.Text(e.Fragment)),
+		)
+		pos = e.End
+	}
+	parts = append(parts, g.Text(query[pos:]))
+
+	return h.Div(h.Class("alert alert-warning query_errors"), h.Role("alert"),
+		h.Div(h.I(h.Class("bi bi-exclamation-triangle")), g.Text(" The highlighted parts of the search are ignored: "),
+			h.Code(h.Class("query_text"), g.Group(parts)),
+		),
+		h.Ul(g.Map(errs, func(e document.QueryError) g.Node {
+			return h.Li(g.Text(e.Error()))
+		})),
+		h.Small(g.Text(`Search for words, "quoted phrases" or fields like tag:invoice sender:"Power Corp" invoice:4711 title:bill amount:>100 amount:10..20eur created:2024-01..2024-03 - a leading '-' excludes the matches.`)),
+	)
+}
+
 //go:embed page_documents.css
 var page_documents_styles string
 
@@ -0,0 +1,107 @@
+package html
+
+import (
+	_ "embed"
+	"fmt"
+	"net/url"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	g "maragu.dev/gomponents"
+	h "maragu.dev/gomponents/html"
+)
+
+//go:embed page_lists.css
+var page_lists_styles string
+
+func ListsStyles() g.Node {
+	return g.El("style", g.Attr("type", "text/css"), g.Raw(page_lists_styles))
+}
+
+func ListsNavigation() g.Node {
+	return h.Nav(h.Class("navbar navbar-expand application_name"),
+		h.Div(h.Class("container-fluid"),
+			h.A(h.Class("navbar-brand application_title"), h.Href("/mydms"), h.I(h.Class("bi bi-file-earmark-pdf"))),
+			h.Div(h.Class("collapse navbar-collapse"),
+				h.Ul(h.Class("navbar-nav me-auto"),
+					h.Li(h.Class("nav-item"), h.A(h.Class("nav-link"), h.Href("/mydms"), g.Text("> mydms "))),
+					h.Li(h.Class("nav-item"), h.A(h.Class("nav-link"), g.Text(">> tags & senders"))),
+				),
+			),
+		),
+	)
+}
+
+// ListsContent shows the tags or senders with the number of their documents. The entries are
+// renamed and deleted one by one, the selected entries are merged into the target entry.
+func ListsContent(st document.SearchType, items []document.ListItem, message string) g.Node {
+	base := "/mydms/lists/" + st.String()
+	return h.Div(h.Class("container-fluid lists"),
+		h.Ul(h.Class("nav nav-tabs"),
+			listTab(document.TAGS, st, "bi-tags", "Tags"),
+			listTab(document.SENDERS, st, "bi-person-lines-fill", "Senders"),
+		),
+		g.If(message != "", h.Div(h.Class("alert alert-info list_message"), h.Role("alert"), h.I(h.Class("bi bi-info-circle")), g.Text(" "+message))),
+		h.Div(h.ID("list_result")),
+		g.If(len(items) == 0, h.Div(h.Class("center_aligned"),
+			h.P(h.Class("noitems"), h.I(h.Class("bigger bi bi-balloon")), g.Text(" No entries available!")),
+		)),
+		g.If(len(items) > 0, h.Form(h.ID("list_form"), g.Attr("hx-target", "#list_result"),
+			h.Table(h.Class("table table-sm list_table"),
+				h.THead(h.Tr(
+					h.Th(h.Class("list_select"), g.Text("Merge")),
+					h.Th(g.Text("Name")),
+					h.Th(h.Class("list_count"), g.Text("Documents")),
+					h.Th(),
+				)),
+				h.TBody(g.Map(items, func(i document.ListItem) g.Node {
+					return h.Tr(
+						h.Td(h.Class("list_select"), h.Input(h.Class("form-check-input"), h.Type("checkbox"), h.Name("id"), h.Value(i.ID))),
+						h.Td(h.Input(h.Class("form-control form-control-sm"), h.Type("text"), h.Name("name-"+i.ID), h.Value(i.Name), h.Required())),
+						h.Td(h.Class("list_count"), h.A(h.Href("/mydms?q="+url.QueryEscape(fmt.Sprintf(`%s:"%s"`, st.Field(), i.Name))), g.Text(fmt.Sprintf("%d", i.Documents)))),
+						h.Td(h.Class("list_actions"),
+							h.Button(h.Type("button"), h.Class("btn btn-outline-primary btn-sm"), h.Title("Rename"),
+								g.Attr("hx-post", base+"/"+i.ID),
+								h.I(h.Class("bi bi-pencil")),
+							),
+							h.Button(h.Type("button"), h.Class("btn btn-outline-danger btn-sm"), h.Title("Delete"),
+								g.Attr("hx-delete", base+"/"+i.ID),
+								g.Attr("hx-confirm", fmt.Sprintf("Remove '%s' from all %d documents?", i.Name, i.Documents)),
+								h.I(h.Class("bi bi-trash")),
+							),
+						),
+					)
+				})),
+			),
+			h.Div(h.Class("input-group input-group-sm list_merge"),
+				h.Span(h.Class("input-group-text"), g.Text("Merge the selected entries into")),
+				h.Select(h.Class("form-select"), h.Name("target"),
+					g.Map(items, func(i document.ListItem) g.Node {
+						return h.Option(h.Value(i.ID), g.Text(i.Name))
+					}),
+				),
+				h.Button(h.Type("button"), h.Class("btn btn-warning"),
+					g.Attr("hx-post", base+"/merge"),
+					g.Attr("hx-confirm", "Replace the selected entries by the target in all documents?"),
+					h.I(h.Class("bi bi-intersect")), g.Text(" Merge"),
+				),
+			),
+		)),
+	)
+}
+
+func listTab(st, active document.SearchType, icon, label string) g.Node {
+	class := "nav-link"
+	if st == active {
+		class += " active"
+	}
+	return h.Li(h.Class("nav-item"),
+		h.A(h.Class(class), h.Href("/mydms/lists/"+st.String()),
+			h.I(h.Class("bi "+icon)), g.Text(" "+label),
+		),
+	)
+}
+
+// ListError is shown above the entries if an action failed
+func ListError(errMsg string) g.Node {
+	return h.Div(h.Class("alert alert-danger list_message"), h.Role("alert"), h.I(h.Class("bi bi-exclamation-triangle")), g.Text(" "+errMsg))
+}
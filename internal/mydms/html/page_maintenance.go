@@ -0,0 +1,102 @@
+package html
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/pkg/security"
+	g "maragu.dev/gomponents"
+	h "maragu.dev/gomponents/html"
+)
+
+//go:embed page_maintenance.css
+var page_maintenance_styles string
+
+func MaintenanceStyles() g.Node {
+	return g.El("style", g.Attr("type", "text/css"), g.Raw(page_maintenance_styles))
+}
+
+func MaintenanceNavigation() g.Node {
+	return h.Nav(h.Class("navbar navbar-expand application_name"),
+		h.Div(h.Class("container-fluid"),
+			h.A(h.Class("navbar-brand application_title"), h.Href("/mydms"), h.I(h.Class("bi bi-file-earmark-pdf"))),
+			h.Div(h.Class("collapse navbar-collapse"),
+				h.Ul(h.Class("navbar-nav me-auto"),
+					h.Li(h.Class("nav-item"), h.A(h.Class("nav-link"), h.Href("/mydms"), g.Text("> mydms "))),
+					h.Li(h.Class("nav-item"), h.A(h.Class("nav-link"), g.Text(">> maintenance"))),
+				),
+			),
+		),
+	)
+}
+
+// MaintenanceContent lists the groups of duplicate documents, the message is the outcome of the last action.
+// The csrfToken is sent by the form computing the hashes, which is not sent by htmx.
+func MaintenanceContent(groups []document.DuplicateGroup, message, csrfToken string) g.Node {
+	return h.Div(h.Class("container-fluid maintenance"),
+		g.If(message != "", h.Div(h.Class("alert alert-info"), h.Role("alert"), h.I(h.Class("bi bi-info-circle")), g.Text(" "+message))),
+		h.Form(h.Class("content_hashes"), h.Method("post"), h.Action("/mydms/maintenance/hashes"),
+			h.Input(h.Type("hidden"), h.Name(security.CSRFFormField), h.Value(csrfToken)),
+			h.Span(g.Text("Documents stored before the duplicate detection are found once their content hash is computed. ")),
+			h.Button(h.Type("submit"), h.Class("btn btn-outline-primary btn-sm"), h.I(h.Class("bi bi-hash")), g.Text(" Compute missing hashes")),
+		),
+		g.If(len(groups) == 0, h.Div(h.Class("center_aligned"),
+			h.P(h.Class("noitems"), h.I(h.Class("bigger bi bi-balloon")), g.Text(" No duplicates found!")),
+		)),
+		g.Map(groups, duplicateGroup),
+	)
+}
+
+// duplicateGroup merges the documents into the selected document, the oldest document is selected
+func duplicateGroup(group document.DuplicateGroup) g.Node {
+	resultID := "merge_result_" + group.ContentHash
+	return h.Form(h.Class("card duplicate_group"),
+		g.Attr("hx-post", "/mydms/maintenance/merge"),
+		g.Attr("hx-target", "#"+resultID),
+		g.Attr("hx-confirm", "Merge the metadata into the selected document and remove the other documents?"),
+		h.Div(h.Class("card-header"),
+			h.I(h.Class("bi bi-files")), g.Text(fmt.Sprintf(" %d identical files", len(group.Documents))),
+			h.Span(h.Class("content_hash"), h.Title(group.ContentHash), g.Text(group.ContentHash[:min(12, len(group.ContentHash))])),
+		),
+		h.Table(h.Class("table table-sm mb-0"),
+			h.THead(h.Tr(
+				h.Th(g.Text("Keep")),
+				h.Th(g.Text("Title")),
+				h.Th(g.Text("File")),
+				h.Th(g.Text("Created")),
+				h.Th(g.Text("Tags")),
+				h.Th(g.Text("Senders")),
+				h.Th(g.Text("Amount")),
+			)),
+			h.TBody(g.Map(group.Documents, func(d document.Document) g.Node {
+				amount := ""
+				if !d.Amount.IsZero() {
+					amount = d.Amount.String()
+				}
+				return h.Tr(
+					h.Td(
+						h.Input(h.Class("form-check-input"), h.Type("radio"), h.Name("keep"), h.Value(d.ID), g.If(d.ID == group.Documents[0].ID, h.Checked())),
+						h.Input(h.Type("hidden"), h.Name("id"), h.Value(d.ID)),
+					),
+					h.Td(g.Text(d.Title), g.If(d.NeedsReview, h.Span(h.Class("badge text-bg-warning needs_review"), g.Text("needs review")))),
+					h.Td(h.A(h.Href(documentLink(d.FileName)), h.Target("_NEW"), g.Text(d.FileName))),
+					h.Td(g.Text(d.Created)),
+					h.Td(g.Text(strings.Join(d.Tags, ", "))),
+					h.Td(g.Text(strings.Join(d.Senders, ", "))),
+					h.Td(h.Class("duplicate_amount"), g.Text(amount)),
+				)
+			})),
+		),
+		h.Div(h.Class("card-footer duplicate_actions"),
+			h.Div(h.ID(resultID)),
+			h.Button(h.Type("submit"), h.Class("btn btn-warning btn-sm"), h.I(h.Class("bi bi-intersect")), g.Text(" Merge into selected")),
+		),
+	)
+}
+
+// MergeError is shown in the group of the duplicates which could not be merged
+func MergeError(errMsg string) g.Node {
+	return h.Span(h.Class("text-danger"), h.I(h.Class("bi bi-exclamation-triangle")), g.Text(" "+errMsg))
+}
@@ -0,0 +1,124 @@
+package html
+
+import (
+	_ "embed"
+	"fmt"
+
+	"golang.binggl.net/monorepo/internal/common"
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	g "maragu.dev/gomponents"
+	h "maragu.dev/gomponents/html"
+)
+
+//go:embed page_pages.css
+var page_pages_styles string
+
+func PagesStyles() g.Node {
+	return g.El("style", g.Attr("type", "text/css"), g.Raw(page_pages_styles))
+}
+
+func PagesNavigation() g.Node {
+	return h.Nav(h.Class("navbar navbar-expand application_name"),
+		h.Div(h.Class("container-fluid"),
+			h.A(h.Class("navbar-brand application_title"), h.Href("/mydms"), h.I(h.Class("bi bi-file-earmark-pdf"))),
+			h.Div(h.Class("collapse navbar-collapse"),
+				h.Ul(h.Class("navbar-nav me-auto"),
+					h.Li(h.Class("nav-item"), h.A(h.Class("nav-link"), h.Href("/mydms"), g.Text("> mydms "))),
+					h.Li(h.Class("nav-item"), h.A(h.Class("nav-link"), g.Text(">> pages"))),
+				),
+			),
+		),
+	)
+}
+
+// PagesContent changes the pages of the document and lists its revisions, the message is the
+// outcome of the last action. The actions are sent by htmx, the errors are shown as toast messages.
+func PagesContent(p document.DocumentPages, message string) g.Node {
+	doc := p.Document
+	action := "/mydms/pages/" + doc.ID
+	current := 0
+	if len(p.Revisions) > 0 {
+		current = p.Revisions[len(p.Revisions)-1].Revision
+	}
+	return h.Div(h.Class("container-fluid pages"),
+		g.If(message != "", h.Div(h.Class("alert alert-info"), h.Role("alert"), h.I(h.Class("bi bi-info-circle")), g.Text(" "+message))),
+		h.Div(h.Class("card pages_document"),
+			h.Div(h.Class("card-body d-flex justify-content-between"),
+				h.Div(
+					h.H5(h.Class("card-title"), h.A(h.Href(documentLink(doc.FileName)), h.Target("_NEW"), h.I(h.Class("bi bi-cloud-download"))), g.Text(" "+doc.Title)),
+					h.Div(g.Text(fmt.Sprintf("%d page(s), revision %d", p.Pages, current))),
+				),
+				g.If(doc.PreviewLink != "", h.Div(h.Class("pages_preview"),
+					h.Img(h.Src(previewLink(doc.PreviewLink)), h.Alt(doc.Title), h.Loading("lazy")),
+				)),
+			),
+		),
+		h.Div(h.Class("row"),
+			h.Div(h.Class("col-md-4"),
+				h.Form(h.Class("card pages_action"),
+					g.Attr("hx-post", action), g.Attr("hx-swap", "none"),
+					h.Div(h.Class("card-header"), h.I(h.Class("bi bi-arrow-clockwise")), g.Text(" Rotate or remove pages")),
+					h.Div(h.Class("card-body"),
+						h.Div(h.Class("input-group input-group-sm mb-2"),
+							h.Span(h.Class("input-group-text"), g.Text("Rotate")),
+							h.Input(h.Type("text"), h.Class("form-control"), h.Name("rotate"), h.Placeholder("e.g. 1-3, 5")),
+							h.Select(h.Class("form-select pages_degrees"), h.Name("degrees"),
+								h.Option(h.Value("90"), g.Text("90°")),
+								h.Option(h.Value("180"), g.Text("180°")),
+								h.Option(h.Value("270"), g.Text("270°")),
+							),
+						),
+						h.Div(h.Class("input-group input-group-sm mb-2"),
+							h.Span(h.Class("input-group-text"), g.Text("Remove")),
+							h.Input(h.Type("text"), h.Class("form-control"), h.Name("remove"), h.Placeholder("e.g. 2, 7-")),
+						),
+						h.Button(h.Type("submit"), h.Class("btn btn-primary btn-sm"), h.I(h.Class("bi bi-check2")), g.Text(" Save as new revision")),
+					),
+				),
+			),
+			h.Div(h.Class("col-md-4"),
+				h.Form(h.Class("card pages_action"),
+					g.Attr("hx-post", action+"/split"), g.Attr("hx-swap", "none"),
+					g.Attr("hx-confirm", "Create a new document for each of the page ranges?"),
+					h.Div(h.Class("card-header"), h.I(h.Class("bi bi-scissors")), g.Text(" Split into new documents")),
+					h.Div(h.Class("card-body"),
+						h.Div(h.Class("input-group input-group-sm mb-2"),
+							h.Span(h.Class("input-group-text"), g.Text("Pages")),
+							h.Input(h.Type("text"), h.Class("form-control"), h.Name("ranges"), h.Placeholder("e.g. 1-2, 3-")),
+						),
+						h.Div(h.Class("form-text mb-2"), g.Text("Each range becomes a new document with the tags and senders of this document.")),
+						h.Button(h.Type("submit"), h.Class("btn btn-primary btn-sm"), h.I(h.Class("bi bi-scissors")), g.Text(" Split")),
+					),
+				),
+			),
+			h.Div(h.Class("col-md-4"),
+				h.Form(h.Class("card pages_action"),
+					g.Attr("hx-post", action+"/append"), g.Attr("hx-swap", "none"), g.Attr("hx-encoding", "multipart/form-data"),
+					h.Div(h.Class("card-header"), h.I(h.Class("bi bi-file-earmark-plus")), g.Text(" Append pages")),
+					h.Div(h.Class("card-body"),
+						h.Input(h.Type("file"), h.Class("form-control form-control-sm mb-2"), h.Name("pages-upload"), h.Accept("application/pdf")),
+						h.Button(h.Type("submit"), h.Class("btn btn-primary btn-sm"), h.I(h.Class("bi bi-upload")), g.Text(" Append as new revision")),
+					),
+				),
+			),
+		),
+		h.Table(h.Class("table table-sm pages_revisions"),
+			h.THead(h.Tr(
+				h.Th(g.Text("Revision")),
+				h.Th(g.Text("Change")),
+				h.Th(g.Text("Created")),
+				h.Th(g.Text("File")),
+			)),
+			h.TBody(g.Map(p.Revisions, func(r document.Revision) g.Node {
+				return h.Tr(
+					h.Td(g.Text(fmt.Sprintf("%d", r.Revision)),
+						g.If(r.Revision == current, h.Span(h.Class("badge text-bg-primary pages_current"), g.Text("current"))),
+					),
+					h.Td(g.Text(r.Operation)),
+					h.Td(h.Class("pages_created"), g.Text(common.SubString(r.Created, 10))),
+					h.Td(h.A(h.Href(documentLink(r.FileName)), h.Target("_NEW"), h.I(h.Class("bi bi-cloud-download")), g.Text(" "+r.FileName))),
+				)
+			})),
+		),
+	)
+}
@@ -0,0 +1,137 @@
+package html
+
+import (
+	_ "embed"
+	"fmt"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	g "maragu.dev/gomponents"
+	h "maragu.dev/gomponents/html"
+)
+
+//go:embed page_reports.css
+var page_reports_styles string
+
+// ReportFilter holds the search criteria of the report, the same criteria as the document search
+type ReportFilter struct {
+	Search   string
+	Tag      string
+	Sender   string
+	From     string
+	Until    string
+	Currency string
+}
+
+func ReportsStyles() g.Node {
+	return g.El("style", g.Attr("type", "text/css"), g.Raw(page_reports_styles))
+}
+
+func ReportsNavigation() g.Node {
+	return h.Nav(h.Class("navbar navbar-expand application_name"),
+		h.Div(h.Class("container-fluid"),
+			h.A(h.Class("navbar-brand application_title"), h.Href("/mydms"), h.I(h.Class("bi bi-file-earmark-pdf"))),
+			h.Div(h.Class("collapse navbar-collapse"),
+				h.Ul(h.Class("navbar-nav me-auto"),
+					h.Li(h.Class("nav-item"), h.A(h.Class("nav-link"), h.Href("/mydms"), g.Text("> mydms "))),
+					h.Li(h.Class("nav-item"), h.A(h.Class("nav-link"), g.Text(">> reports"))),
+				),
+			),
+		),
+	)
+}
+
+func ReportsContent(report document.Report, filter ReportFilter, queryErrors []document.QueryError) g.Node {
+	return h.Div(h.Class("container-fluid reports"),
+		reportFilterForm(report, filter),
+		QueryErrors(filter.Search, queryErrors),
+		g.If(report.Count == 0, h.Div(h.Class("center_aligned"),
+			h.P(h.Class("noitems"), h.I(h.Class("bigger bi bi-balloon")), g.Text(" No amounts available!")),
+		)),
+		g.If(report.Count > 0, g.Group([]g.Node{
+			h.Div(h.Class("row report_total"),
+				h.Div(h.Class("col"),
+					h.Span(h.Class("total_label"), g.Text("Total")),
+					h.Span(h.Class("total_amount"), g.Text(report.Total.String())),
+					h.Span(h.Class("total_count"), g.Text(fmt.Sprintf("%d documents", report.Count))),
+				),
+			),
+			h.Div(h.Class("row"),
+				reportChart("By year", "bi-calendar", report.ByYear),
+				reportChart("By month", "bi-calendar3", report.ByMonth),
+			),
+			h.Div(h.Class("row"),
+				reportChart("By sender", "bi-truck", report.BySender),
+				reportChart("By tag", "bi-tag", report.ByTag),
+			),
+		})),
+	)
+}
+
+func reportFilterForm(report document.Report, filter ReportFilter) g.Node {
+	input := func(name, label, inputType, value string) g.Node {
+		return h.Div(h.Class("col-md-2 mb-2"),
+			h.Div(h.Class("input-group input-group-sm"),
+				h.Span(h.Class("input-group-text"), g.Text(label)),
+				h.Input(h.Type(inputType), h.Class("form-control"), h.Name(name), h.Value(value)),
+			),
+		)
+	}
+	currencies := report.Currencies
+	if len(currencies) == 0 {
+		currencies = []string{report.Currency}
+	}
+
+	return h.Form(h.Class("row report_filter"), h.Method("get"), h.Action("/mydms/reports"),
+		input("q", "Search", "text", filter.Search),
+		input("tag", "#Tag", "text", filter.Tag),
+		input("sender", "Sender", "text", filter.Sender),
+		input("from", "From", "date", filter.From),
+		input("until", "Until", "date", filter.Until),
+		h.Div(h.Class("col-md-1 mb-2"),
+			h.Select(h.Class("form-select form-select-sm"), h.Name("currency"),
+				g.Map(currencies, func(c string) g.Node {
+					return h.Option(h.Value(c), g.If(c == report.Currency, h.Selected()), g.Text(c))
+				}),
+			),
+		),
+		h.Div(h.Class("col-md-1 mb-2"),
+			h.Button(h.Type("submit"), h.Class("btn btn-primary btn-sm"), h.I(h.Class("bi bi-funnel")), g.Text(" Filter")),
+		),
+	)
+}
+
+// reportChart shows the totals of the groups as horizontal bars relative to the largest total
+func reportChart(title, icon string, entries []document.ReportEntry) g.Node {
+	var largest int64
+	for _, e := range entries {
+		largest = max(largest, abs(e.Total.Minor))
+	}
+
+	return h.Div(h.Class("col-md-6 mb-3"),
+		h.Div(h.Class("card report_chart"),
+			h.Div(h.Class("card-header"), h.I(h.Class("bi "+icon)), g.Text(" "+title)),
+			h.Div(h.Class("card-body"),
+				g.Map(entries, func(e document.ReportEntry) g.Node {
+					width := 0
+					if largest > 0 {
+						width = int(abs(e.Total.Minor) * 100 / largest)
+					}
+					return h.Div(h.Class("report_row"),
+						h.Span(h.Class("report_key"), h.Title(fmt.Sprintf("%s: %d documents", e.Key, e.Count)), g.Text(e.Key)),
+						h.Div(h.Class("progress report_bar"), h.Role("progressbar"),
+							h.Div(h.Class("progress-bar"), h.Style(fmt.Sprintf("width: %d%%", width))),
+						),
+						h.Span(h.Class("report_amount"), g.Text(e.Total.Decimal())),
+					)
+				}),
+			),
+		),
+	)
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
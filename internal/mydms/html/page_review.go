@@ -0,0 +1,40 @@
+package html
+
+import (
+	"fmt"
+
+	g "maragu.dev/gomponents"
+	h "maragu.dev/gomponents/html"
+)
+
+func ReviewNavigation(numDrafts int) g.Node {
+	return h.Nav(h.Class("navbar navbar-expand application_name"),
+		h.Div(h.Class("container-fluid"),
+			h.A(h.Class("navbar-brand application_title"), h.Href("/mydms"), h.I(h.Class("bi bi-file-earmark-pdf"))),
+			h.Div(h.Class("collapse navbar-collapse"),
+				h.Ul(h.Class("navbar-nav me-auto"),
+					h.Li(h.Class("nav-item"), h.A(h.Class("nav-link"), h.Href("/mydms"), g.Text("> mydms "))),
+					h.Li(h.Class("nav-item"), h.A(h.Class("nav-link"), g.Text(">> review "),
+						h.Span(h.Class("badge text-bg-warning"), h.Style("font-size:small"), g.Text(fmt.Sprintf("%d", numDrafts))),
+					)),
+				),
+			),
+		),
+	)
+}
+
+// ReviewContent lists the drafts, the list is reloaded after a draft was saved
+func ReviewContent(reviewList g.Node) g.Node {
+	return h.Div(h.Class("container-fluid"),
+		h.Form(
+			h.Div(
+				h.Class("row be_my_center"),
+				h.ID("document_list"),
+				g.Attr("hx-put", "/mydms/review/partial/list"),
+				g.Attr("hx-trigger", "refreshDocumentList from:body"),
+				g.Attr("hx-swap", "innerHTML"),
+				reviewList,
+			),
+		),
+	)
+}
@@ -0,0 +1,214 @@
+package html
+
+import (
+	_ "embed"
+	"fmt"
+	"path"
+	"strings"
+
+	"golang.binggl.net/monorepo/internal/common"
+	"golang.binggl.net/monorepo/internal/mydms/app/rules"
+	"golang.binggl.net/monorepo/pkg/security"
+	g "maragu.dev/gomponents"
+	h "maragu.dev/gomponents/html"
+)
+
+//go:embed page_rules.css
+var page_rules_styles string
+
+// RuleConditions is the number of conditions offered by the rule form
+const RuleConditions = 3
+
+// RuleForm holds the values of the rule form, invalid values are shown again together with the error
+type RuleForm struct {
+	ID            string
+	Name          string
+	Position      string
+	Enabled       bool
+	Conditions    []rules.Condition
+	Tags          string
+	Senders       string
+	TitleTemplate string
+	Amount        string
+	Currency      string
+	Error         string
+	// CSRFToken is needed because the form is not sent by htmx
+	CSRFToken string
+}
+
+func RulesStyles() g.Node {
+	return g.El("style", g.Attr("type", "text/css"), g.Raw(page_rules_styles))
+}
+
+func RulesNavigation() g.Node {
+	return h.Nav(h.Class("navbar navbar-expand application_name"),
+		h.Div(h.Class("container-fluid"),
+			h.A(h.Class("navbar-brand application_title"), h.Href("/mydms"), h.I(h.Class("bi bi-file-earmark-pdf"))),
+			h.Div(h.Class("collapse navbar-collapse"),
+				h.Ul(h.Class("navbar-nav me-auto"),
+					h.Li(h.Class("nav-item"), h.A(h.Class("nav-link"), h.Href("/mydms"), g.Text("> mydms "))),
+					h.Li(h.Class("nav-item"), h.A(h.Class("nav-link"), h.Href("/mydms/rules"), g.Text(">> rules"))),
+				),
+			),
+		),
+	)
+}
+
+// RulesContent lists the rules next to the form of the selected or the new rule
+func RulesContent(list []rules.Rule, form RuleForm) g.Node {
+	return h.Div(h.Class("container-fluid rules"),
+		h.Div(h.Class("row"),
+			h.Div(h.Class("col-md-4 mb-3"), ruleList(list, form.ID)),
+			h.Div(h.Class("col-md-8 mb-3"),
+				ruleForm(form),
+				h.Div(h.ID("rule_test_results")),
+			),
+		),
+	)
+}
+
+func ruleList(list []rules.Rule, selected string) g.Node {
+	return h.Div(h.Class("card"),
+		h.Div(h.Class("card-header"),
+			h.I(h.Class("bi bi-funnel")), g.Text(" Rules"),
+			h.A(h.Class("btn btn-primary btn-sm new_rule"), h.Href("/mydms/rules"), h.I(h.Class("bi bi-plus")), g.Text(" New")),
+		),
+		g.If(len(list) == 0, h.Div(h.Class("card-body"),
+			h.P(h.Class("noitems"), g.Text("No rules defined, new documents are saved as uploaded.")),
+		)),
+		h.Ul(h.Class("list-group list-group-flush"),
+			g.Map(list, func(r rules.Rule) g.Node {
+				return h.A(h.Class(common.ClassCond("list-group-item list-group-item-action rule_item", "active", r.ID == selected)),
+					h.Href("/mydms/rules/"+r.ID),
+					h.Span(h.Class("rule_position"), g.Text(fmt.Sprintf("%d", r.Position))),
+					g.Text(r.Name),
+					g.If(!r.Enabled, h.Span(h.Class("badge text-bg-secondary rule_disabled"), g.Text("disabled"))),
+				)
+			}),
+		),
+	)
+}
+
+func ruleForm(form RuleForm) g.Node {
+	input := func(name, label, value, placeholder string) g.Node {
+		return h.Div(h.Class("input-group input-group-sm mb-2"),
+			h.Span(h.Class("input-group-text"), g.Text(label)),
+			h.Input(h.Type("text"), h.Class("form-control"), h.Name(name), h.Value(value), h.Placeholder(placeholder)),
+		)
+	}
+	conditions := form.Conditions
+	for len(conditions) < RuleConditions {
+		conditions = append(conditions, rules.Condition{Field: rules.FileName, Operator: rules.Contains})
+	}
+
+	return h.Form(h.Class("card rule_form"), h.ID("rule_form"), h.Method("post"), h.Action("/mydms/rules"),
+		h.Div(h.Class("card-header"),
+			g.If(form.ID == "", g.Text("Create Rule")),
+			g.If(form.ID != "", g.Text("Edit: "+form.Name)),
+		),
+		h.Div(h.Class("card-body"),
+			h.Input(h.Type("hidden"), h.Name(security.CSRFFormField), h.Value(form.CSRFToken)),
+			h.Input(h.Type("hidden"), h.Name("rule-id"), h.Value(form.ID)),
+			h.Div(h.Class("row"),
+				h.Div(h.Class("col-md-7"), input("rule-name", "Name", form.Name, "Name of the rule")),
+				h.Div(h.Class("col-md-3"), input("rule-position", "Position", form.Position, "0")),
+				h.Div(h.Class("col-md-2"),
+					h.Div(h.Class("form-check form-switch"),
+						h.Input(h.Class("form-check-input"), h.Type("checkbox"), h.Role("switch"), h.ID("rule_enabled"), h.Name("rule-enabled"), g.If(form.Enabled, h.Checked())),
+						h.Label(h.Class("form-check-label"), h.For("rule_enabled"), g.Text("Enabled")),
+					),
+				),
+			),
+			h.H6(h.Class("rule_section"), g.Text("All conditions need to match")),
+			conditionRows(conditions[:RuleConditions]),
+			h.H6(h.Class("rule_section"), g.Text("Values set for matching documents")),
+			input("rule-tags", "#Tags", form.Tags, "comma separated"),
+			input("rule-senders", "Senders", form.Senders, "comma separated"),
+			input("rule-title", "Title", form.TitleTemplate, "e.g. Invoice {{.Month}}/{{.Year}} {{.Groups.number}}"),
+			h.Div(h.Class("input-group input-group-sm mb-2"),
+				h.Span(h.Class("input-group-text"), g.Text("Amount")),
+				h.Input(h.Type("text"), h.Class("form-control"), h.Name("rule-amount"), h.Value(form.Amount), h.Placeholder("fixed amount, or the regex group (?P<amount>...)"), g.Attr("inputmode", "decimal")),
+				h.Input(h.Type("text"), h.Class("form-control rule_currency"), h.Name("rule-currency"), h.Value(form.Currency), h.MaxLength("3"), g.Attr("list", "rule_currencies"), h.Title("Currency (ISO 4217)")),
+				h.DataList(h.ID("rule_currencies"), g.Map(currencies, func(c string) g.Node {
+					return h.Option(h.Value(c))
+				})),
+			),
+			h.Div(h.Class("form-text"),
+				g.Text("The title template can use .Title, .FileName, .Date, .Year, .Month and the named regex groups of .Groups."),
+			),
+			g.If(form.Error != "", h.Div(h.Class("alert alert-danger rule_error"), h.Role("alert"), h.I(h.Class("bi bi-exclamation-triangle")), g.Text(" "+form.Error))),
+		),
+		h.Div(h.Class("card-footer rule_actions"),
+			h.Button(h.Type("button"), h.Class("btn btn-outline-secondary btn-sm"),
+				g.Attr("hx-post", "/mydms/rules/test"),
+				g.Attr("hx-include", "#rule_form"),
+				g.Attr("hx-target", "#rule_test_results"),
+				h.I(h.Class("bi bi-play")), g.Text(" Test against existing documents"),
+			),
+			g.If(form.ID != "", h.Button(h.Type("button"), h.Class("btn btn-outline-danger btn-sm"),
+				g.Attr("hx-delete", "/mydms/rules/"+form.ID),
+				g.Attr("hx-confirm", fmt.Sprintf("Delete the rule '%s'?", form.Name)),
+				h.I(h.Class("bi bi-x")), g.Text(" Delete"),
+			)),
+			h.Button(h.Type("submit"), h.Class("btn btn-success btn-sm"), g.Text("Save")),
+		),
+	)
+}
+
+func conditionRows(conditions []rules.Condition) g.Node {
+	nodes := make([]g.Node, 0, len(conditions))
+	for i, c := range conditions {
+		nodes = append(nodes, h.Div(h.Class("input-group input-group-sm mb-2"),
+			h.Select(h.Class("form-select rule_field"), h.Name(fmt.Sprintf("rule-field-%d", i)),
+				g.Map(rules.Fields, func(f rules.Field) g.Node {
+					return h.Option(h.Value(string(f)), g.If(f == c.Field, h.Selected()), g.Text(string(f)))
+				}),
+			),
+			h.Select(h.Class("form-select rule_operator"), h.Name(fmt.Sprintf("rule-operator-%d", i)),
+				g.Map(rules.Operators, func(o rules.Operator) g.Node {
+					return h.Option(h.Value(string(o)), g.If(o == c.Operator, h.Selected()), g.Text(string(o)))
+				}),
+			),
+			h.Input(h.Type("text"), h.Class("form-control"), h.Name(fmt.Sprintf("rule-value-%d", i)), h.Value(c.Value), h.Placeholder("value, empty conditions are ignored")),
+		))
+	}
+	return g.Group(nodes)
+}
+
+// RuleTestResults shows the documents matching the tested rule and the values the rule would set
+func RuleTestResults(results []rules.TestResult, numDocs int, errMsg string) g.Node {
+	if errMsg != "" {
+		return h.Div(h.Class("alert alert-danger rule_error"), h.Role("alert"), h.I(h.Class("bi bi-exclamation-triangle")), g.Text(" "+errMsg))
+	}
+	return h.Div(h.Class("card rule_results"),
+		h.Div(h.Class("card-header"), g.Text(fmt.Sprintf("%d of %d documents match", len(results), numDocs))),
+		g.If(len(results) > 0, h.Table(h.Class("table table-sm mb-0"),
+			h.THead(h.Tr(
+				h.Th(g.Text("Document")),
+				h.Th(g.Text("Title")),
+				h.Th(g.Text("Tags")),
+				h.Th(g.Text("Senders")),
+				h.Th(g.Text("Amount")),
+			)),
+			h.TBody(g.Map(results, func(r rules.TestResult) g.Node {
+				if r.Err != nil {
+					return h.Tr(
+						h.Td(g.Text(r.Document.Title)),
+						h.Td(h.ColSpan("4"), h.Class("text-danger"), g.Text(r.Err.Error())),
+					)
+				}
+				amount := ""
+				if !r.Result.Amount.IsZero() {
+					amount = r.Result.Amount.String()
+				}
+				return h.Tr(
+					h.Td(h.Title(path.Base(r.Document.FileName)), g.Text(r.Document.Title)),
+					h.Td(g.Text(r.Result.Title)),
+					h.Td(g.Text(strings.Join(r.Result.Tags, ", "))),
+					h.Td(g.Text(strings.Join(r.Result.Senders, ", "))),
+					h.Td(h.Class("rule_amount"), g.Text(amount)),
+				)
+			})),
+		)),
+	)
+}
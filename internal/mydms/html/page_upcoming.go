@@ -0,0 +1,168 @@
+package html
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/internal/mydms/app/reminder"
+	base "golang.binggl.net/monorepo/pkg/handler/html"
+	g "maragu.dev/gomponents"
+	h "maragu.dev/gomponents/html"
+)
+
+//go:embed page_upcoming.css
+var page_upcoming_styles string
+
+// select the calendar feed on click, inline event handlers are blocked by the CSP
+const jsSelectFeed = `document.getElementById("reminder_feed")?.addEventListener("click", (e) => e.target.select());`
+
+func UpcomingStyles() g.Node {
+	return g.El("style", g.Attr("type", "text/css"), g.Raw(page_upcoming_styles))
+}
+
+func UpcomingNavigation() g.Node {
+	return h.Nav(h.Class("navbar navbar-expand application_name"),
+		h.Div(h.Class("container-fluid"),
+			h.A(h.Class("navbar-brand application_title"), h.Href("/mydms"), h.I(h.Class("bi bi-file-earmark-pdf"))),
+			h.Div(h.Class("collapse navbar-collapse"),
+				h.Ul(h.Class("navbar-nav me-auto"),
+					h.Li(h.Class("nav-item"), h.A(h.Class("nav-link"), h.Href("/mydms"), g.Text("> mydms "))),
+					h.Li(h.Class("nav-item"), h.A(h.Class("nav-link"), g.Text(">> upcoming"))),
+				),
+			),
+		),
+	)
+}
+
+// Subscription describes how the reminders are delivered to the user
+type Subscription struct {
+	reminder.Subscriber
+	// FeedURL is the absolute address of the calendar feed
+	FeedURL string
+	// MailEnabled is true if a mail server is configured
+	MailEnabled bool
+}
+
+// UpcomingContent lists the documents by their due date, overdue documents are highlighted.
+// The completed documents are only listed if showDone is set, the nonce is used for the script of the page.
+func UpcomingContent(docs []document.Document, today time.Time, showDone bool, sub Subscription, nonce string) g.Node {
+	toggle := h.A(h.Class("btn btn-outline-secondary btn-sm"), h.Href("/mydms/upcoming?done=true"), h.I(h.Class("bi bi-eye")), g.Text(" Show completed"))
+	if showDone {
+		toggle = h.A(h.Class("btn btn-outline-secondary btn-sm"), h.Href("/mydms/upcoming"), h.I(h.Class("bi bi-eye-slash")), g.Text(" Hide completed"))
+	}
+	return h.Div(h.Class("container-fluid upcoming"),
+		subscriptionPanel(sub, nonce),
+		h.Div(h.Class("upcoming_actions"), toggle),
+		g.If(len(docs) == 0, h.Div(h.Class("center_aligned"),
+			h.P(h.Class("noitems"), h.I(h.Class("bigger bi bi-balloon")), g.Text(" Nothing is due!")),
+		)),
+		g.If(len(docs) > 0, h.Table(h.Class("table table-sm upcoming_list"),
+			h.THead(h.Tr(
+				h.Th(g.Text("Due")),
+				h.Th(g.Text("Type")),
+				h.Th(g.Text("Title")),
+				h.Th(g.Text("Senders")),
+				h.Th(g.Text("Amount")),
+				h.Th(g.Text("Reminder")),
+				h.Th(),
+			)),
+			h.TBody(g.Map(docs, func(d document.Document) g.Node {
+				return upcomingRow(d, today, showDone)
+			})),
+		)),
+	)
+}
+
+func upcomingRow(d document.Document, today time.Time, showDone bool) g.Node {
+	day := d.DueDate
+	if day == "" {
+		day = d.ReminderDate
+	}
+	class := ""
+	switch {
+	case d.Done != "":
+		class = "upcoming_done"
+	case day < today.Format(document.DateLayout):
+		class = "table-danger"
+	case day <= today.AddDate(0, 0, 7).Format(document.DateLayout):
+		class = "table-warning"
+	}
+
+	action := "/mydms/upcoming/" + d.ID + "/done"
+	if showDone {
+		action += "?done=true"
+	}
+	var button g.Node
+	if d.Done == "" {
+		button = h.Button(h.Type("button"), h.Class("btn btn-success btn-sm"),
+			g.Attr("hx-post", action), g.Attr("hx-swap", "none"),
+			h.I(h.Class("bi bi-check2")), g.Text(" Mark "+d.DueType.DoneLabel()),
+		)
+	} else {
+		button = h.Button(h.Type("button"), h.Class("btn btn-outline-secondary btn-sm"),
+			g.Attr("hx-delete", action), g.Attr("hx-swap", "none"),
+			h.I(h.Class("bi bi-arrow-counterclockwise")), g.Text(" Reopen"),
+		)
+	}
+
+	amount := ""
+	if !d.Amount.IsZero() {
+		amount = d.Amount.String()
+	}
+	return h.Tr(h.ID(d.ID), g.If(class != "", h.Class(class)),
+		h.Td(h.Class("upcoming_date"), g.Text(day)),
+		h.Td(g.Text(d.DueType.Label())),
+		h.Td(h.A(h.Href(documentLink(d.FileName)), h.Target("_NEW"), g.Text(d.Title)),
+			g.If(d.Done != "", h.Span(h.Class("badge text-bg-secondary upcoming_badge"), g.Text(d.DueType.DoneLabel()))),
+		),
+		h.Td(g.Text(strings.Join(d.Senders, ", "))),
+		h.Td(h.Class("upcoming_amount"), g.Text(amount)),
+		h.Td(h.Class("upcoming_date"), g.Text(d.ReminderDate)),
+		h.Td(h.Class("upcoming_button"), button),
+	)
+}
+
+// subscriptionPanel shows the calendar feed and the settings of the reminder mails
+func subscriptionPanel(sub Subscription, nonce string) g.Node {
+	var mail g.Node
+	switch {
+	case !sub.MailEnabled:
+		mail = h.Span(h.Class("text-muted"), g.Text("Reminder mails are not configured."))
+	case sub.Email == "":
+		mail = h.Span(h.Class("text-muted"), g.Text("No email address is available for your account."))
+	default:
+		mail = h.Div(h.Class("form-check form-switch"),
+			h.Input(h.Class("form-check-input"), h.Type("checkbox"), g.Attr("role", "switch"), h.ID("reminder_notify"),
+				h.Name("notify"), h.Value("true"), g.If(sub.Notify, h.Checked()),
+				g.Attr("hx-post", "/mydms/upcoming/notify"), g.Attr("hx-swap", "none"),
+			),
+			h.Label(h.Class("form-check-label"), h.For("reminder_notify"), g.Text(fmt.Sprintf("Send reminders to %s", sub.Email))),
+		)
+	}
+	return h.Div(h.Class("card upcoming_subscription"),
+		h.Div(h.Class("card-body"),
+			h.Div(h.Class("row"),
+				h.Div(h.Class("col-md-8"),
+					h.Label(h.Class("form-label"), h.For("reminder_feed"), h.I(h.Class("bi bi-calendar-week")), g.Text(" Calendar feed")),
+					h.Div(h.Class("input-group input-group-sm"),
+						h.Input(h.Type("text"), h.Class("form-control"), h.ID("reminder_feed"), h.ReadOnly(), h.Value(sub.FeedURL)),
+						h.Button(h.Type("button"), h.Class("btn btn-outline-warning"), h.Title("Create a new address, the current address stops working"),
+							g.Attr("hx-post", "/mydms/upcoming/feed"), g.Attr("hx-swap", "none"),
+							g.Attr("hx-confirm", "Create a new calendar address? Calendars using the current address are no longer updated."),
+							h.I(h.Class("bi bi-arrow-repeat")), g.Text(" Renew"),
+						),
+					),
+					h.Div(h.Class("form-text"), g.Text("Subscribe to the address in your calendar application. Keep it private, the address gives access to the upcoming documents.")),
+					base.Script(nonce, jsSelectFeed),
+				),
+				h.Div(h.Class("col-md-4"),
+					h.Label(h.Class("form-label"), h.I(h.Class("bi bi-envelope")), g.Text(" Email")),
+					mail,
+				),
+			),
+		),
+	)
+}
@@ -5,7 +5,7 @@ import (
 	"fmt"
 
 	"golang.binggl.net/monorepo/internal/common"
-	"golang.binggl.net/monorepo/pkg/text"
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
 	g "maragu.dev/gomponents"
 	h "maragu.dev/gomponents/html"
 )
@@ -26,10 +26,13 @@ func DisplayDocumentDownload(doc Document) g.Node {
 	if doc.ID != "" && doc.FileName.Val != "" {
 		documentDownload = h.Div(h.Class("document_download"), h.ID("document_download_link"),
 			h.I(h.Class("bi bi-cloud-arrow-down")), g.Text(" "),
-			h.A(h.Class("document_download_link"), h.Href("/mydms/file/"+text.SafePathEscapeBase64(doc.PreviewLink.Val)), h.Target("_NEW"), g.Text(doc.FileName.Val)),
+			h.A(h.Class("document_download_link"), h.Href(documentLink(doc.FileName.Val)), h.Target("_NEW"), g.Text(doc.FileName.Val)),
 			h.Input(h.Type("hidden"), h.Name("doc-tempID"), h.Value("-")),
 			h.Input(h.Type("hidden"), h.Name("doc-filename"), h.Value(doc.FileName.Val)),
 			removeLink,
+			g.If(doc.PreviewLink.Val != "", h.Div(h.Class("document_preview"),
+				h.Img(h.Src(previewLink(doc.PreviewLink.Val)), h.Alt(doc.FileName.Val)),
+			)),
 		)
 
 	} else if doc.UploadToken.Val != "" && doc.FileName.Val != "" {
@@ -59,7 +62,16 @@ func DisplayDocumentDownload(doc Document) g.Node {
 						h.Div(h.Class("mb-3"),
 							h.Label(h.For("pass"), h.Class("form-label"), g.Text("Password")),
 							h.Input(h.Type("password"), h.Class("form-control"), h.ID("pass"), h.Name("doc-newPass"), h.Placeholder("new")),
+							h.Div(h.Class("form-text"), g.Text("Only the initial password removes the encryption.")),
 						),
+						h.Div(h.Class("mb-3"),
+							h.Label(h.For("ownerPass"), h.Class("form-label"), g.Text("Owner password")),
+							h.Input(h.Type("password"), h.Class("form-control"), h.ID("ownerPass"), h.Name("doc-ownerPass"), h.Placeholder("optional, full access")),
+						),
+						h.Div(h.Class("mb-1"), g.Text("Permissions")),
+						permissionCheck("permPrint", "Print"),
+						permissionCheck("permCopy", "Copy"),
+						permissionCheck("permModify", "Modify"),
 					),
 				),
 				g.Raw("&nbsp;"),
@@ -75,7 +87,7 @@ func DisplayDocumentDownload(doc Document) g.Node {
 					g.Attr("hx-trigger", "click"),
 					g.Attr("hx-target", "#document_upload_area"),
 					g.Attr("hx-swap", "outerHTML"),
-					g.Attr("hx-params", "doc-fileupload,doc-initPass,doc-newPass"),
+					g.Attr("hx-params", "doc-fileupload,doc-initPass,doc-newPass,doc-ownerPass,doc-permPrint,doc-permCopy,doc-permModify"),
 					g.Attr("hx-indicator", "#indicator"),
 					h.I(h.Class("bi bi-upload")),
 				),
@@ -85,13 +97,29 @@ func DisplayDocumentDownload(doc Document) g.Node {
 	return documentDownload
 }
 
-func DisplayTempDocumentUpload(fileName, tempID, errMsg string) g.Node {
+// permissionCheck renders a permission of an encrypted PDF, the owner password is not restricted
+func permissionCheck(id, label string) g.Node {
+	return h.Div(h.Class("form-check"),
+		h.Input(h.Type("checkbox"), h.Class("form-check-input"), h.ID(id), h.Name("doc-"+id), h.Checked()),
+		h.Label(h.For(id), h.Class("form-check-label"), g.Text(label)),
+	)
+}
+
+// DisplayTempDocumentUpload shows the uploaded file, the duplicates are existing documents
+// storing an identical file
+func DisplayTempDocumentUpload(fileName, tempID, errMsg string, duplicates []document.Document) g.Node {
 	elements := []g.Node{
 		h.I(h.Class("bi bi-cloud-arrow-down")), g.Text(" "),
 		h.A(h.Class("document_download_link"), g.Text(fileName)),
 		h.Input(h.Type("hidden"), h.Name("doc-tempID"), h.Value(tempID)),
 		h.Input(h.Type("hidden"), h.Name("doc-filename"), h.Value(fileName)),
 		removeLink,
+		g.If(len(duplicates) > 0, h.Div(h.Class("alert alert-warning duplicate_warning"), h.Role("alert"),
+			h.I(h.Class("bi bi-files")), g.Text(" An identical file is already stored: "),
+			g.Map(duplicates, func(d document.Document) g.Node {
+				return h.A(h.Class("duplicate_link"), h.Href(documentLink(d.FileName)), h.Target("_NEW"), h.Title(d.FileName), g.Text(d.Title))
+			}),
+		)),
 	}
 
 	return h.Div(h.Class("document_download"), h.ID("document_download_link"),
@@ -3,6 +3,8 @@ package html
 import (
 	_ "embed"
 	"fmt"
+	"path"
+	"strings"
 
 	"golang.binggl.net/monorepo/internal/common"
 	"golang.binggl.net/monorepo/internal/mydms/app/document"
@@ -14,21 +16,44 @@ import (
 //go:embed partial_document_list.css
 var partial_document_list_styles string
 
-func getDocumentPath(doc document.Document) string {
-	if doc.PreviewLink != "" {
-		return doc.PreviewLink
-	}
-	return text.EncBase64(doc.FileName)
+// documentLink references the payload of the document
+func documentLink(fileName string) string {
+	return "/mydms/file/" + text.EncBase64SafePath(fileName)
 }
 
+// isPDF checks the extension of the file, only the pages of PDF files can be changed
+func isPDF(fileName string) bool {
+	return strings.EqualFold(path.Ext(fileName), ".pdf")
+}
+
+// previewLink references the thumbnail of the document, the link is already base64 encoded
+func previewLink(preview string) string {
+	return "/mydms/file/" + text.SafePathEscapeBase64(preview)
+}
+
+// DocumentList shows the documents of the search, the documents are selected for the bulk actions
 func DocumentList(docNum, skip int, pd document.PagedDocument) g.Node {
+	return documentList("/mydms/partial/list", true, docNum, skip, pd)
+}
+
+// ReviewList shows the drafts of the review queue
+func ReviewList(docNum, skip int, pd document.PagedDocument) g.Node {
+	return documentList("/mydms/review/partial/list", false, docNum, skip, pd)
+}
+
+// documentList shows the cards of the documents, listURL is used to fetch the next page.
+// The selectable documents are submitted with the form of the bulk actions.
+func documentList(listURL string, selectable bool, docNum, skip int, pd document.PagedDocument) g.Node {
 	elements := make([]g.Node, 0)
 
 	doclist := g.Map(pd.Documents, func(doc document.Document) g.Node {
 		return h.Div(h.Class("card be_my_document"),
 			h.Div(h.Class("card-body"),
 				h.H5(h.Class("card-title"), h.Title(doc.Title),
-					h.A(h.Href("/mydms/file/"+text.SafePathEscapeBase64(getDocumentPath(doc))), h.Target("_NEW"),
+					g.If(selectable, h.Input(h.Class("form-check-input bulk_select"), h.Type("checkbox"),
+						h.Name("id"), h.Value(doc.ID), g.Attr("form", bulkFormID), h.Title("Select the document"),
+					)),
+					h.A(h.Href(documentLink(doc.FileName)), h.Target("_NEW"),
 						h.I(h.Class("bi bi-cloud-download")),
 					),
 					g.Text(" "),
@@ -51,6 +76,13 @@ func DocumentList(docNum, skip int, pd document.PagedDocument) g.Node {
 						g.Attr("aria-expanded", "false"),
 					),
 					h.Ul(h.Class("dropdown-menu"),
+						g.If(isPDF(doc.FileName), h.Li(
+							h.A(
+								h.Class("dropdown-item"),
+								h.Href("/mydms/pages/"+doc.ID),
+								h.I(h.Class("bi bi-files"), g.Text(" Pages")),
+							),
+						)),
 						h.Li(
 							h.A(
 								h.Class("dropdown-item delete"),
@@ -67,11 +99,12 @@ func DocumentList(docNum, skip int, pd document.PagedDocument) g.Node {
 						),
 					),
 				),
-				g.If(doc.Amount != 0, h.Span(h.Class("amount"), g.Text(fmt.Sprintf("€ %.2f", doc.Amount)))),
+				g.If(!doc.Amount.IsZero(), h.Span(h.Class("amount"), g.Text(doc.Amount.String()))),
 			),
 			h.Div(h.Class("card-body doc-content"),
 				g.If(doc.InvoiceNumber != "", h.Span(h.Class("invoice-number"), h.I(h.Class("bi bi-123")), g.Text(doc.InvoiceNumber))),
 				g.If(doc.InvoiceNumber == "", h.Span(h.Class("invoice-number"), g.Text("-"))),
+				g.If(doc.NeedsReview, h.Span(h.Class("badge text-bg-warning needs_review"), h.I(h.Class("bi bi-hourglass-split")), g.Text(" needs review"))),
 			),
 			h.Div(h.Class("card-body"),
 				h.Div(h.Class("tags"),
@@ -105,6 +138,11 @@ func DocumentList(docNum, skip int, pd document.PagedDocument) g.Node {
 					),
 				),
 			),
+			g.If(doc.PreviewLink != "", h.Div(h.Class("card-body doc-preview"),
+				h.A(h.Href(documentLink(doc.FileName)), h.Target("_NEW"),
+					h.Img(h.Src(previewLink(doc.PreviewLink)), h.Alt(doc.Title), h.Loading("lazy")),
+				),
+			)),
 		)
 	})
 	elements = append(elements, doclist)
@@ -126,7 +164,7 @@ func DocumentList(docNum, skip int, pd document.PagedDocument) g.Node {
 					h.Button(
 						h.Type("button"),
 						h.Class("btn btn-light btn-sm"),
-						g.Attr("hx-put", "/mydms/partial/list"),
+						g.Attr("hx-put", listURL),
 						g.Attr("hx-target", "#page_content"),
 						g.Attr("hx-swap", "outerHTML"),
 						g.Attr("hx-params", "q,skip"),
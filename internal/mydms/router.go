@@ -6,8 +6,11 @@ import (
 	"github.com/go-chi/chi/v5"
 	"golang.binggl.net/monorepo/internal/common/upload"
 	"golang.binggl.net/monorepo/internal/mydms/app/config"
+	"golang.binggl.net/monorepo/internal/mydms/app/dav"
 	"golang.binggl.net/monorepo/internal/mydms/app/document"
 	"golang.binggl.net/monorepo/internal/mydms/app/filestore"
+	"golang.binggl.net/monorepo/internal/mydms/app/reminder"
+	"golang.binggl.net/monorepo/internal/mydms/app/rules"
 	"golang.binggl.net/monorepo/internal/mydms/web"
 	"golang.binggl.net/monorepo/pkg/develop"
 	"golang.binggl.net/monorepo/pkg/handler"
@@ -28,10 +31,38 @@ type HTTPHandlerOptions struct {
 
 const forbiddenPath = "/mydms/403"
 
+// DavPath is the root of the WebDAV file system, the CSRF protection does not apply to it
+const DavPath = "/mydms/dav"
+
+var davMethods = []string{"PROPFIND", "PROPPATCH", "MKCOL", "COPY", "MOVE", "LOCK", "UNLOCK"}
+
+// file managers and scanner apps use WebDAV, the methods need to be known to the router.
+// The methods are registered globally by chi, once before the routes are mounted.
+func init() {
+	for _, m := range davMethods {
+		chi.RegisterMethod(m)
+	}
+}
+
 // MakeHTTPHandler creates a new handler implementation which is used together with the HTTP server
-func MakeHTTPHandler(docSvc document.Service, uploadSvc upload.Service, fileSvc filestore.FileService, logger logging.Logger, opts HTTPHandlerOptions) http.Handler {
+func MakeHTTPHandler(repo document.Repository, docSvc document.Service, uploadSvc upload.Service, fileSvc filestore.FileService, ruleSvc rules.Service, reminderSvc reminder.Service, logger logging.Logger, opts HTTPHandlerOptions) http.Handler {
 	std, sec := setupRouter(opts, logger)
 
+	// use this for development purposes only!
+	develop.SetupDevTokenHandler(std, logger, opts.Config.Environment)
+
+	std.Mount("/", sec)
+
+	notFound := MountRoutes(std, sec, repo, docSvc, uploadSvc, fileSvc, ruleSvc, reminderSvc, logger, opts)
+	std.NotFound(notFound)
+
+	return std
+}
+
+// MountRoutes adds the paths of the mydms service to the given routers. Public paths are
+// added to std, the secured paths to sec. The returned handler displays the not-found page.
+// The WebDAV paths are added to std, they authenticate the requests by davAuth.
+func MountRoutes(std, sec chi.Router, repo document.Repository, docSvc document.Service, uploadSvc upload.Service, fileSvc filestore.FileService, ruleSvc rules.Service, reminderSvc reminder.Service, logger logging.Logger, opts HTTPHandlerOptions) http.HandlerFunc {
 	templateHandler := &web.TemplateHandler{
 		TemplateHandler: &handler.TemplateHandler{
 			Logger:    logger,
@@ -40,11 +71,15 @@ func MakeHTTPHandler(docSvc document.Service, uploadSvc upload.Service, fileSvc
 			BasePath:  "/public",
 			StartPage: "/mydms",
 		},
-		DocSvc:        docSvc,
-		UploadSvc:     uploadSvc,
-		Version:       opts.Version,
-		Build:         opts.Build,
-		MaxUploadSize: opts.Config.Upload.MaxUploadSize,
+		DocSvc:          docSvc,
+		UploadSvc:       uploadSvc,
+		RuleSvc:         ruleSvc,
+		ReminderSvc:     reminderSvc,
+		Version:         opts.Version,
+		Build:           opts.Build,
+		MaxUploadSize:   opts.Config.Upload.MaxUploadSize,
+		BaseURL:         opts.Config.Reminders.BaseURL,
+		RemindersByMail: opts.Config.Reminders.Enabled(),
 	}
 
 	fileHandler := &web.FileHandler{
@@ -52,15 +87,51 @@ func MakeHTTPHandler(docSvc document.Service, uploadSvc upload.Service, fileSvc
 		FileSvc: fileSvc,
 	}
 
-	// use this for development purposes only!
-	develop.SetupDevTokenHandler(std, logger, opts.Config.Environment)
+	exportHandler := &web.ExportHandler{
+		DocSvc:  docSvc,
+		FileSvc: fileSvc,
+		Logger:  logger,
+	}
+
+	apiHandler := &web.APIHandler{
+		DocSvc:        docSvc,
+		UploadSvc:     uploadSvc,
+		FileSvc:       fileSvc,
+		Logger:        logger,
+		MaxUploadSize: opts.Config.Upload.MaxUploadSize,
+		BaseURL:       opts.Config.Reminders.BaseURL,
+	}
+
+	davFS := dav.NewFileSystem(dav.Options{
+		Repo:          repo,
+		DocSvc:        docSvc,
+		FileSvc:       fileSvc,
+		UploadSvc:     uploadSvc,
+		Logger:        logger,
+		MaxUploadSize: opts.Config.Upload.MaxUploadSize,
+	})
 
 	// server-side rendered paths
 	// the following paths provide server-rendered UIs
 	// /403 displays a page telling the user that access/permissions are missing
 	std.Get(forbiddenPath, templateHandler.Show403())
+	// the calendar feed is requested by calendar applications, the token of the path identifies the user
+	std.Get("/mydms/calendar/{token}.ics", templateHandler.Calendar())
+	// the specification of the JSON API is available for clients without a token
+	std.Get("/api/v1/openapi.json", apiHandler.OpenAPI())
 
-	std.Mount("/", sec)
+	// the versioned JSON API, used by mobile and scripting clients
+	sec.Mount("/api/v1", func() http.Handler {
+		r := chi.NewRouter()
+		r.Get("/documents", apiHandler.SearchDocuments())
+		r.Post("/documents", apiHandler.CreateDocument())
+		r.Get("/documents/{id}", apiHandler.GetDocument())
+		r.Put("/documents/{id}", apiHandler.UpdateDocument())
+		r.Delete("/documents/{id}", apiHandler.DeleteDocument())
+		r.Get("/documents/{id}/file", apiHandler.GetDocumentFile())
+
+		return r
+	}())
 
 	// the routes for the templates
 	sec.Mount("/mydms", func() http.Handler {
@@ -74,22 +145,99 @@ func MakeHTTPHandler(docSvc document.Service, uploadSvc upload.Service, fileSvc
 		r.Post("/confirm/{id}", templateHandler.ShowDeleteConfirmDialog())
 		r.Delete("/{id}", templateHandler.DeleteDocument())
 		r.Get("/list/{type}", templateHandler.SearchListItems())
+		r.Get("/reports", templateHandler.DisplayReports())
+		r.Get("/review", templateHandler.DisplayReviewQueue())
+		r.Put("/review/partial/list", templateHandler.DisplayReviewQueuePartial())
+		r.Get("/export", exportHandler.Export())
+		r.Get("/upcoming", templateHandler.DisplayUpcoming())
+		r.Post("/upcoming/feed", templateHandler.RenewFeed())
+		r.Post("/upcoming/notify", templateHandler.SetReminderNotify())
+		r.Post("/upcoming/{id}/done", templateHandler.MarkDocumentDone())
+		r.Delete("/upcoming/{id}/done", templateHandler.MarkDocumentDone())
+		r.Get("/pages/{id}", templateHandler.DisplayPages())
+		r.Post("/pages/{id}", templateHandler.EditPages())
+		r.Post("/pages/{id}/split", templateHandler.SplitDocument())
+		r.Post("/pages/{id}/append", templateHandler.AppendPages())
+		r.Post("/bulk", templateHandler.BulkUpdateDocuments())
+		r.Post("/bulk/zip", exportHandler.ExportSelected())
+		r.Get("/rules", templateHandler.DisplayRules())
+		r.Post("/rules", templateHandler.SaveRule())
+		r.Post("/rules/test", templateHandler.TestRule())
+		r.Get("/rules/{id}", templateHandler.DisplayRule())
+		r.Delete("/rules/{id}", templateHandler.DeleteRule())
+		r.Get("/maintenance", templateHandler.DisplayMaintenance())
+		r.Post("/maintenance/hashes", templateHandler.ComputeContentHashes())
+		r.Post("/maintenance/merge", templateHandler.MergeDuplicates())
+		r.Get("/lists/{type}", templateHandler.DisplayListItems())
+		r.Post("/lists/{type}/merge", templateHandler.MergeListItems())
+		r.Post("/lists/{type}/{id}", templateHandler.RenameListItem())
+		r.Delete("/lists/{type}/{id}", templateHandler.DeleteListItem())
 		r.Get("/file/{path}", fileHandler.GetDocumentPayload())
 
 		return r
 	}())
 
-	std.NotFound(templateHandler.Show404())
+	std.With(davAuth(opts, logger)).Mount(DavPath, dav.NewHandler(DavPath, davFS))
 
-	return std
+	return templateHandler.Show404()
 }
 
 func setupRouter(opts HTTPHandlerOptions, logger logging.Logger) (router chi.Router, secureRouter chi.Router) {
-	router = server.SetupBasicRouter(opts.BasePath, opts.Config.Cookies, opts.Config.Cors, opts.Config.Assets, logger)
+	router = server.SetupBasicRouter(opts.BasePath, opts.Config.Cookies, opts.Config.Cors, opts.Config.Headers, opts.Config.Assets, logger, DavPath)
+	secureRouter = chi.NewRouter()
+	secureRouter.Use(JWTInterceptor(opts, logger))
+	return
+}
 
+// JWTInterceptor validates the JWT of requests and requires the claim of the mydms service
+func JWTInterceptor(opts HTTPHandlerOptions, logger logging.Logger) func(http.Handler) http.Handler {
 	// add a middleware to "catch" security errors and present a human-readable form
 	// if the client requests "application/json" just use the the problem-json format
-	jwtOptions := security.JwtOptions{
+	jwtOptions := securityOptions(opts)
+	jwtAuth := security.NewJWTAuthorization(jwtOptions, true)
+	interceptor := security.SecInterceptor{
+		Log:           logger,
+		Auth:          jwtAuth,
+		Options:       jwtOptions,
+		ErrorRedirect: forbiddenPath,
+	}
+	return interceptor.HandleJWT
+}
+
+// davAuth validates the JWT of WebDAV requests. File managers only support basic authentication,
+// the password of the basic authentication is used as the token. Failed requests are answered
+// with a challenge for basic authentication instead of the redirect to the error page.
+// The CSRF protection does not apply to the WebDAV paths, the cookie of the session is not used.
+func davAuth(opts HTTPHandlerOptions, logger logging.Logger) func(http.Handler) http.Handler {
+	jwtOptions := securityOptions(opts)
+	jwtAuth := security.NewJWTAuthorization(jwtOptions, true)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			unauthorized := func() {
+				w.Header().Set("WWW-Authenticate", `Basic realm="mydms", charset="UTF-8"`)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			}
+			if r.Header.Get("Authorization") == "" {
+				unauthorized()
+				return
+			}
+			req := r
+			if _, token, ok := r.BasicAuth(); ok {
+				req = r.Clone(r.Context())
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			ctx, err := security.Validate(req, jwtAuth, jwtOptions, logger)
+			if err != nil {
+				unauthorized()
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func securityOptions(opts HTTPHandlerOptions) security.JwtOptions {
+	return security.JwtOptions{
 		CacheDuration: opts.Config.Security.CacheDuration,
 		CookieName:    opts.Config.Security.CookieName,
 		ErrorPath:     opts.ErrorPath,
@@ -102,16 +250,4 @@ func setupRouter(opts HTTPHandlerOptions, logger logging.Logger) (router chi.Rou
 			Roles: opts.Config.Security.Claim.Roles,
 		},
 	}
-	jwtAuth := security.NewJWTAuthorization(jwtOptions, true)
-	interceptor := security.SecInterceptor{
-		Log:           logger,
-		Auth:          jwtAuth,
-		Options:       jwtOptions,
-		ErrorRedirect: forbiddenPath,
-	}
-
-	secureRouter = chi.NewRouter()
-	secureRouter.Use(interceptor.HandleJWT)
-
-	return
 }
@@ -3,64 +3,77 @@ package mydms
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"golang.binggl.net/monorepo/internal/common/crypter"
 	"golang.binggl.net/monorepo/internal/common/upload"
 	"golang.binggl.net/monorepo/internal/mydms/app/config"
 	"golang.binggl.net/monorepo/internal/mydms/app/document"
 	"golang.binggl.net/monorepo/internal/mydms/app/filestore"
+	"golang.binggl.net/monorepo/internal/mydms/app/inbox"
+	"golang.binggl.net/monorepo/internal/mydms/app/preview"
+	"golang.binggl.net/monorepo/internal/mydms/app/reminder"
+	"golang.binggl.net/monorepo/internal/mydms/app/rules"
 	"golang.binggl.net/monorepo/internal/mydms/app/shared"
 	conf "golang.binggl.net/monorepo/pkg/config"
 	"golang.binggl.net/monorepo/pkg/develop"
 	"golang.binggl.net/monorepo/pkg/logging"
 	"golang.binggl.net/monorepo/pkg/server"
+	"golang.binggl.net/monorepo/pkg/tracing"
 )
 
 // run is the entry-point for the core/auth service
 // where initialization, setup and execution is done
 func Run(version, build, appName string) error {
-	hostname, port, basePath, appCfg := server.ReadConfig[config.AppConfig]("my")
+	hostname, port, basePath, appCfg, err := server.ReadConfig[config.AppConfig]("my")
+	if err != nil {
+		return err
+	}
 
 	// use the new pkg logger implementation
 	logger := logConfig(appCfg)
 	// ensure closing of logfile on exit
 	defer logger.Close()
 
+	shutdownTracing, err := tracing.Setup(tracing.Options{
+		AppName: appCfg.AppName,
+		Version: version,
+		HostID:  appCfg.HostID,
+		Config:  appCfg.Tracing,
+	})
+	if err != nil {
+		panic(fmt.Sprintf("cannot setup tracing: %v", err))
+	}
+	// flush pending spans on exit
+	defer shutdownTracing(context.Background())
+
 	// shared.store && application version
 	db := shared.NewConnForSqlite(appCfg.Database.ConnectionString)
 	defer db.Close()
 
-	repo, err := document.NewRepository(db)
+	health := server.NewHealth()
+	jobs, stopJobs := context.WithCancel(context.Background())
+	svc, err := Setup(jobs, db, appCfg, logger, health.Register)
 	if err != nil {
 		panic(fmt.Sprintf("cannot establish database connection: %v", err))
 	}
+	// the background jobs are finished before the database is closed
+	defer func() {
+		stopJobs()
+		svc.Wait()
+	}()
+	handler := MakeHTTPHandler(svc.Repository, svc.Documents, svc.Upload, svc.Files, svc.Rules, svc.Reminders, logger, HTTPHandlerOptions{
+		BasePath:  basePath,
+		ErrorPath: appCfg.ErrorPath,
+		Config:    appCfg,
+		Version:   version,
+		Build:     build,
+	})
 
-	var (
-		fileSvc = filestore.NewService(context.Background(), logger, filestore.S3Config{
-			Bucket:   appCfg.Filestore.Bucket,
-			Region:   appCfg.Filestore.Region,
-			EndPoint: appCfg.Filestore.EndPoint,
-			Key:      appCfg.Filestore.Key,
-			Secret:   appCfg.Filestore.Secret,
-		})
-		crypterSvc = crypter.NewService(logger)
-		uploadSvc  = upload.NewService(upload.ServiceOptions{
-			Logger:           logger,
-			Store:            upload.NewStore(appCfg.Upload.UploadPath),
-			MaxUploadSize:    appCfg.Upload.MaxUploadSize,
-			AllowedFileTypes: appCfg.Upload.AllowedFileTypes,
-			Crypter:          crypterSvc,
-			TimeOut:          "30s",
-		})
-		docSvc  = document.NewService(logger, repo, fileSvc, uploadSvc)
-		handler = MakeHTTPHandler(docSvc, uploadSvc, fileSvc, logger, HTTPHandlerOptions{
-			BasePath:  basePath,
-			ErrorPath: appCfg.ErrorPath,
-			Config:    appCfg,
-			Version:   version,
-			Build:     build,
-		})
-	)
+	listen, err := server.NewListenOptions(appCfg.Server)
+	if err != nil {
+		return err
+	}
 
 	// only run the reload-server in development
 	if appCfg.Environment == conf.Development {
@@ -77,9 +90,159 @@ func Run(version, build, appName string) error {
 		Environment:   string(appCfg.Environment),
 		ServerHandler: handler,
 		Logger:        logger,
+		Health:        health,
+		Listen:        listen,
 	})
 }
 
+// Services are the components of mydms used by the http handlers
+type Services struct {
+	// Repository of the documents, the WebDAV file system lists the documents directly
+	Repository document.Repository
+	Documents  document.Service
+	Upload     upload.Service
+	Files      filestore.FileService
+	Rules      rules.Service
+	Reminders  reminder.Service
+	// jobs are the background jobs started by Setup
+	jobs *sync.WaitGroup
+}
+
+// Wait blocks until the background jobs are finished, the jobs stop when the context of Setup is done
+func (s Services) Wait() {
+	if s.jobs != nil {
+		s.jobs.Wait()
+	}
+}
+
+// Setup creates the services of mydms using the given database connection and registers the
+// readiness checks of its dependencies. New documents are classified by the rules of the user.
+// If an inbox is configured, the watcher of the inbox is
+// started in the background, the same applies to the reminder mails if a mail server is configured.
+// The background jobs run until ctx is done, Services.Wait returns once they are finished.
+// It is used by Run and the combined server.
+func Setup(ctx context.Context, db shared.Connection, appCfg config.AppConfig, logger logging.Logger, register func(string, server.CheckFunc)) (Services, error) {
+	repo, err := document.NewRepository(db)
+	if err != nil {
+		return Services{}, err
+	}
+	if err = document.MigrateSchema(context.Background(), db); err != nil {
+		return Services{}, err
+	}
+	if err = rules.MigrateSchema(context.Background(), db); err != nil {
+		return Services{}, err
+	}
+	ruleRepo, err := rules.NewRepository(db)
+	if err != nil {
+		return Services{}, err
+	}
+	if err = reminder.MigrateSchema(context.Background(), db); err != nil {
+		return Services{}, err
+	}
+	reminderRepo, err := reminder.NewRepository(db)
+	if err != nil {
+		return Services{}, err
+	}
+	fileSvc, err := newFileService(appCfg, logger)
+	if err != nil {
+		return Services{}, err
+	}
+
+	var (
+		crypterSvc = crypter.NewService(logger)
+		uploadSvc  = upload.NewService(upload.ServiceOptions{
+			Logger:           logger,
+			Store:            upload.NewStore(appCfg.Upload.UploadPath),
+			MaxUploadSize:    appCfg.Upload.MaxUploadSize,
+			AllowedFileTypes: appCfg.Upload.AllowedFileTypes,
+			Crypter:          crypterSvc,
+			TimeOut:          "30s",
+		})
+	)
+
+	register("database", server.SqliteCheck(db.DB.DB))
+	register("filestore", fileSvc.CheckBucket)
+	register("upload", server.WritableDirCheck(appCfg.Upload.UploadPath))
+
+	ruleSvc := rules.NewService(logger, ruleRepo, fileSvc, rules.NewTextExtractor(appCfg.Classification.TextExtractor))
+	docSvc := document.NewService(logger, repo, fileSvc, uploadSvc, newPreviewGenerator(appCfg), ruleSvc)
+	jobs := &sync.WaitGroup{}
+	if appCfg.Inbox.Enabled() {
+		watcher, err := inbox.NewWatcher(appCfg.Inbox.Options(), docSvc, uploadSvc, logger)
+		if err != nil {
+			return Services{}, err
+		}
+		register("inbox", server.WritableDirCheck(appCfg.Inbox.Path))
+		jobs.Go(func() { watcher.Run(ctx) })
+	}
+	reminderSvc := reminder.NewService(logger, reminderRepo, docSvc, appCfg.Reminders.Notifier(), appCfg.Reminders.BaseURL)
+	if appCfg.Reminders.Enabled() {
+		runner := reminder.NewRunner(reminderSvc, appCfg.Reminders.CheckInterval(), logger)
+		jobs.Go(func() { runner.Run(ctx) })
+	}
+
+	return Services{
+		Repository: repo,
+		Documents:  docSvc,
+		Upload:     uploadSvc,
+		Files:      fileSvc,
+		Rules:      ruleSvc,
+		Reminders:  reminderSvc,
+		jobs:       jobs,
+	}, nil
+}
+
+// newFileService creates the filestore, the payload is encrypted if a master key is configured
+func newFileService(appCfg config.AppConfig, logger logging.Logger) (filestore.FileService, error) {
+	keys, err := appCfg.Filestore.Encryption.Keyring()
+	if err != nil {
+		return nil, err
+	}
+	return filestore.NewService(context.Background(), logger, filestore.S3Config{
+		Bucket:   appCfg.Filestore.Bucket,
+		Region:   appCfg.Filestore.Region,
+		EndPoint: appCfg.Filestore.EndPoint,
+		Key:      appCfg.Filestore.Key,
+		Secret:   appCfg.Filestore.Secret,
+	}, keys), nil
+}
+
+// newPreviewGenerator creates the thumbnails of the documents
+func newPreviewGenerator(appCfg config.AppConfig) preview.Generator {
+	return preview.NewGenerator(preview.Options{
+		Width:    appCfg.Preview.Width,
+		Renderer: appCfg.Preview.Renderer,
+	})
+}
+
+// Rekey wraps the data keys of the stored documents using the configured master key. The replaced
+// master key has to be listed in the previous keys. It is the entry-point of the rekey command.
+func Rekey() error {
+	_, _, _, appCfg, err := server.ReadConfig[config.AppConfig]("my")
+	if err != nil {
+		return err
+	}
+	logger := logConfig(appCfg)
+	defer logger.Close()
+
+	keys, err := appCfg.Filestore.Encryption.Keyring()
+	if err != nil {
+		return err
+	}
+	if keys == nil {
+		return fmt.Errorf("no master key is configured")
+	}
+	fileSvc, err := newFileService(appCfg, logger)
+	if err != nil {
+		return err
+	}
+
+	result, err := filestore.Rekey(context.Background(), fileSvc, keys)
+	fmt.Printf("re-keyed %d documents, %d already use the master key '%s', %d are not encrypted\n",
+		result.Rekeyed, result.Current, keys.CurrentKeyID(), result.Unencrypted)
+	return err
+}
+
 func logConfig(cfg config.AppConfig) logging.Logger {
 	return logging.New(logging.LogConfig{
 		FilePath:      cfg.Logging.FilePath,
@@ -91,3 +254,33 @@ func logConfig(cfg config.AppConfig) logging.Logger {
 		},
 	}, cfg.Environment)
 }
+
+// BackfillPreviews creates the thumbnails of documents stored without a preview.
+// It is the entry-point of the preview command.
+func BackfillPreviews() error {
+	_, _, _, appCfg, err := server.ReadConfig[config.AppConfig]("my")
+	if err != nil {
+		return err
+	}
+	logger := logConfig(appCfg)
+	defer logger.Close()
+
+	db := shared.NewConnForSqlite(appCfg.Database.ConnectionString)
+	defer db.Close()
+	repo, err := document.NewRepository(db)
+	if err != nil {
+		return err
+	}
+	if err = document.MigrateSchema(context.Background(), db); err != nil {
+		return err
+	}
+	fileSvc, err := newFileService(appCfg, logger)
+	if err != nil {
+		return err
+	}
+
+	svc := document.NewService(logger, repo, fileSvc, nil, newPreviewGenerator(appCfg), nil)
+	result, err := svc.GeneratePreviews(context.Background())
+	fmt.Printf("created %d previews, %d documents could not be processed\n", result.Created, result.Failed)
+	return err
+}
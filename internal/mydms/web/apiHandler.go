@@ -0,0 +1,326 @@
+package web
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.binggl.net/monorepo/internal/common/upload"
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/internal/mydms/app/filestore"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+	pkgerr "golang.binggl.net/monorepo/pkg/errors"
+	"golang.binggl.net/monorepo/pkg/logging"
+	"golang.binggl.net/monorepo/pkg/security"
+)
+
+//go:embed openapi.json
+var openAPISpec []byte
+
+// APIHandler provides the documents as a versioned JSON API, the API is described by the
+// OpenAPI specification of the service. Errors are returned in the problem-json format.
+type APIHandler struct {
+	DocSvc        document.Service
+	UploadSvc     upload.Service
+	FileSvc       filestore.FileService
+	Logger        logging.Logger
+	MaxUploadSize int64
+	// BaseURL is the public address of mydms used for the location of created documents
+	BaseURL string
+}
+
+// multipartOverhead is allowed in addition to the file for the metadata and the multipart encoding
+const multipartOverhead = 1 << 20
+
+// OpenAPI returns the specification of the API
+func (a *APIHandler) OpenAPI() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPISpec)
+	}
+}
+
+// SearchDocuments returns the documents of the query parameters
+func (a *APIHandler) SearchDocuments() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		search, order, err := parseSearch(r)
+		if err != nil {
+			a.writeError(w, r, err)
+			return
+		}
+		a.Logger.InfoRequest(fmt.Sprintf("search the documents for user: '%s'", ensureUser(r).Username), r)
+
+		p, err := a.DocSvc.Search(r.Context(), search, order)
+		if err != nil {
+			a.writeError(w, r, err)
+			return
+		}
+		if p.Documents == nil {
+			p.Documents = []document.Document{}
+		}
+		writeJSON(w, http.StatusOK, p)
+	}
+}
+
+// GetDocument returns the document of the given id
+func (a *APIHandler) GetDocument() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		doc, err := a.DocSvc.GetDocumentByID(r.Context(), pathParam(r, "id"))
+		if err != nil {
+			a.writeError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, doc)
+	}
+}
+
+// CreateDocument stores the uploaded file of the multipart request, the metadata of the
+// document is the JSON of the form field "document"
+func (a *APIHandler) CreateDocument() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := ensureUser(r)
+		// the size of the request is limited, the files are not only buffered on disk
+		r.Body = http.MaxBytesReader(w, r.Body, a.MaxUploadSize+multipartOverhead)
+		if err := r.ParseMultipartForm(a.MaxUploadSize); err != nil {
+			a.writeError(w, r, shared.ErrValidation(fmt.Sprintf("could not parse the multipart request; %v", err)))
+			return
+		}
+		var doc document.Document
+		if err := json.Unmarshal([]byte(r.FormValue("document")), &doc); err != nil {
+			a.writeError(w, r, shared.ErrValidation(fmt.Sprintf("could not parse the document; %v", err)))
+			return
+		}
+		if err := validateDocument(doc); err != nil {
+			a.writeError(w, r, err)
+			return
+		}
+
+		file, meta, err := r.FormFile("file")
+		if err != nil {
+			a.writeError(w, r, shared.ErrValidation("the file of the document is missing"))
+			return
+		}
+		defer file.Close()
+		a.Logger.InfoRequest(fmt.Sprintf("create the document '%s' for user: '%s'", meta.Filename, user.Username), r)
+
+		token, err := a.UploadSvc.Save(upload.File{
+			File:     file,
+			Name:     meta.Filename,
+			Size:     meta.Size,
+			MimeType: meta.Header.Get("Content-Type"),
+		})
+		if err != nil {
+			a.writeError(w, r, err)
+			return
+		}
+		doc.ID = ""
+		doc.UploadToken = token
+		doc.FileName = meta.Filename
+
+		saved, err := a.DocSvc.SaveDocument(r.Context(), doc, *user)
+		if err != nil {
+			if err := a.UploadSvc.Delete(token); err != nil {
+				a.Logger.Warn(fmt.Sprintf("could not delete the upload '%s'; %v", token, err))
+			}
+			a.writeError(w, r, err)
+			return
+		}
+		w.Header().Set("Location", publicURL(r, a.BaseURL, "/api/v1/documents/"+saved.ID))
+		writeJSON(w, http.StatusCreated, saved)
+	}
+}
+
+// UpdateDocument changes the metadata of the document, the file of the document is kept
+func (a *APIHandler) UpdateDocument() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := ensureUser(r)
+		id := pathParam(r, "id")
+		var doc document.Document
+		if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+			a.writeError(w, r, shared.ErrValidation(fmt.Sprintf("could not parse the document; %v", err)))
+			return
+		}
+		if err := validateDocument(doc); err != nil {
+			a.writeError(w, r, err)
+			return
+		}
+		existing, err := a.DocSvc.GetDocumentByID(r.Context(), id)
+		if err != nil {
+			a.writeError(w, r, err)
+			return
+		}
+		a.Logger.InfoRequest(fmt.Sprintf("update the document '%s' for user: '%s'", id, user.Username), r)
+
+		doc.ID = existing.ID
+		doc.FileName = existing.FileName
+		doc.UploadToken = ""
+		saved, err := a.DocSvc.SaveDocument(r.Context(), doc, *user)
+		if err != nil {
+			a.writeError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, saved)
+	}
+}
+
+// DeleteDocument removes the document and its files
+func (a *APIHandler) DeleteDocument() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := pathParam(r, "id")
+		a.Logger.InfoRequest(fmt.Sprintf("delete the document '%s' for user: '%s'", id, ensureUser(r).Username), r)
+		if err := a.DocSvc.DeleteDocumentByID(r.Context(), id); err != nil {
+			a.writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// GetDocumentFile returns the file of the document
+func (a *APIHandler) GetDocumentFile() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		doc, err := a.DocSvc.GetDocumentByID(r.Context(), pathParam(r, "id"))
+		if err != nil {
+			a.writeError(w, r, err)
+			return
+		}
+		file, err := a.FileSvc.GetFile(r.Context(), doc.FileName)
+		if err != nil {
+			a.writeError(w, r, fmt.Errorf("could not access the file of document '%s'; %v", doc.ID, err))
+			return
+		}
+
+		w.Header().Set("Content-Type", file.MimeType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", path.Base(doc.FileName)))
+		security.RestrictPayload(w)
+		if _, err = w.Write(file.Payload); err != nil {
+			a.Logger.Error(fmt.Sprintf("could not write document payload to client; %v", err))
+		}
+	}
+}
+
+// writeError maps the errors of the service to the problem-json of the response
+func (a *APIHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var (
+		validation *shared.ValidationError
+		notFound   *shared.NotFoundError
+	)
+	switch {
+	case errors.As(err, &validation), errors.Is(err, upload.ErrValidation):
+		pkgerr.WriteError(w, r, pkgerr.BadRequestError{Err: err, Request: r})
+	case errors.As(err, &notFound):
+		pkgerr.WriteError(w, r, pkgerr.NotFoundError{Err: err, Request: r})
+	default:
+		a.Logger.ErrorRequest(fmt.Sprintf("the API request failed; %v", err), r)
+		pkgerr.WriteError(w, r, pkgerr.ServerError{Err: err, Request: r})
+	}
+}
+
+// validateDocument applies the rules of the edit form to the document of a request
+func validateDocument(doc document.Document) error {
+	if strings.TrimSpace(doc.Title) == "" {
+		return shared.ErrValidation("the title of the document is required")
+	}
+	if len(doc.Tags) == 0 {
+		return shared.ErrValidation("the document needs at least one tag")
+	}
+	if len(doc.Senders) == 0 {
+		return shared.ErrValidation("the document needs at least one sender")
+	}
+	return nil
+}
+
+// parseSearch reads the search and the order of the query parameters. The structured query
+// "q" is parsed by document.ParseQuery, the order has the form "created:desc,title".
+func parseSearch(r *http.Request) (search document.DocSearch, order []document.OrderBy, err error) {
+	query := r.URL.Query()
+	if q := query.Get("q"); q != "" {
+		filters, errs := document.ParseQuery(q)
+		if len(errs) > 0 {
+			msgs := make([]string, 0, len(errs))
+			for _, e := range errs {
+				msgs = append(msgs, e.Error())
+			}
+			return search, nil, shared.ErrValidation("invalid query; " + strings.Join(msgs, "; "))
+		}
+		search.Filters = filters
+	}
+	search.Title = query.Get("title")
+	search.Tag = query.Get("tag")
+	search.Sender = query.Get("sender")
+	if search.From, err = apiDate(query.Get("from")); err != nil {
+		return
+	}
+	if search.Until, err = apiDate(query.Get("until")); err != nil {
+		return
+	}
+	if !search.Until.IsZero() {
+		search.Until = search.Until.Add(24*time.Hour - time.Nanosecond)
+	}
+	if v := query.Get("needsReview"); v != "" {
+		if search.NeedsReview, err = strconv.ParseBool(v); err != nil {
+			return search, nil, shared.ErrValidation(fmt.Sprintf("the value '%s' of needsReview is not a boolean", v))
+		}
+	}
+	if search.Limit, err = apiNumber(query, "limit"); err != nil {
+		return
+	}
+	if search.Skip, err = apiNumber(query, "skip"); err != nil {
+		return
+	}
+
+	for _, o := range query["orderBy"] {
+		for field := range strings.SplitSeq(o, ",") {
+			field, dir, _ := strings.Cut(strings.TrimSpace(field), ":")
+			if field == "" {
+				continue
+			}
+			ob := document.OrderBy{Field: field, Order: document.ASC}
+			switch strings.ToLower(dir) {
+			case "", "asc":
+			case "desc":
+				ob.Order = document.DESC
+			default:
+				return search, nil, shared.ErrValidation(fmt.Sprintf("the sort direction '%s' is not valid, use asc or desc", dir))
+			}
+			order = append(order, ob)
+		}
+	}
+	return search, order, nil
+}
+
+func apiDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	d, err := time.Parse(document.DateLayout, value)
+	if err != nil {
+		return d, shared.ErrValidation(fmt.Sprintf("the date '%s' is not valid, use the format %s", value, document.DateLayout))
+	}
+	return d, nil
+}
+
+func apiNumber(query url.Values, name string) (int, error) {
+	v := query.Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, shared.ErrValidation(fmt.Sprintf("the value '%s' of %s is not a number", v, name))
+	}
+	return n, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,105 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+	"golang.binggl.net/monorepo/pkg/handler"
+	base "golang.binggl.net/monorepo/pkg/handler/html"
+)
+
+// BulkUpdateDocuments applies the selected action to the selected documents, the outcome is
+// reported by a toast message and the document list is reloaded
+func (t *TemplateHandler) BulkUpdateDocuments() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := ensureUser(r)
+		if err := r.ParseForm(); err != nil {
+			t.Logger.ErrorRequest(fmt.Sprintf("could not parse supplied form data; '%v'", err), r)
+			w.Header().Add("HX-Trigger", base.ErrorToast("Bulk action failed!", "could not parse supplied form data"))
+			return
+		}
+
+		ids := r.Form["id"]
+		if document.BulkAction(r.FormValue("action")) == document.BulkMerge {
+			t.mergeDocuments(w, r, ids, r.FormValue("value"))
+			return
+		}
+		change := document.BulkChange{Action: document.BulkAction(r.FormValue("action"))}
+		if value := r.FormValue("value"); change.Action == document.BulkSetSender {
+			change.Values = []string{value}
+		} else {
+			change.Values = strings.Split(value, ",")
+		}
+		t.Logger.InfoRequest(fmt.Sprintf("apply '%s' to %d documents for user: '%s'", change.Action, len(ids), user.Username), r)
+
+		result, err := t.DocSvc.BulkUpdate(r.Context(), ids, change)
+		if err != nil {
+			var validation *shared.ValidationError
+			if !errors.As(err, &validation) {
+				t.Logger.ErrorRequest(fmt.Sprintf("could not apply '%s' to the documents; %v", change.Action, err), r)
+			}
+			w.Header().Add("HX-Trigger", base.ErrorToast("Bulk action failed!", err.Error()))
+			return
+		}
+
+		// https://htmx.org/headers/hx-trigger/
+		w.Header().Add("HX-Trigger", handler.Json(triggerDef{
+			ToastMessage: base.ToastMessage{Event: bulkSummary(result)},
+			Refresh:      "now",
+		}))
+	}
+}
+
+// bulkSummary counts the changed documents and lists the reasons of the failed documents
+func bulkSummary(result document.BulkResult) base.ToastMessageContent {
+	if len(result.Failed) == 0 {
+		return base.ToastMessageContent{
+			Type:  base.MsgSuccess,
+			Title: "Bulk action completed!",
+			Text:  fmt.Sprintf("%d document(s) changed.", len(result.Changed)),
+		}
+	}
+	reasons := make([]string, 0, len(result.Failed))
+	for _, f := range result.Failed {
+		reasons = append(reasons, f.Message)
+	}
+	msg := base.ToastMessageContent{
+		Type:  base.MsgWarning,
+		Title: "Bulk action partially failed!",
+		Text:  fmt.Sprintf("%d document(s) changed, %d failed: %s", len(result.Changed), len(result.Failed), strings.Join(reasons, "; ")),
+	}
+	if len(result.Changed) == 0 {
+		msg.Type = base.MsgError
+		msg.Title = "Bulk action failed!"
+	}
+	return msg
+}
+
+// mergeDocuments combines the selected documents in the order of the list into a new document
+func (t *TemplateHandler) mergeDocuments(w http.ResponseWriter, r *http.Request, ids []string, title string) {
+	user := ensureUser(r)
+	t.Logger.InfoRequest(fmt.Sprintf("merge %d documents for user: '%s'", len(ids), user.Username), r)
+
+	doc, err := t.DocSvc.MergeDocuments(r.Context(), ids, title)
+	if err != nil {
+		if !isClientError(err) {
+			t.Logger.ErrorRequest(fmt.Sprintf("could not merge the documents; %v", err), r)
+		}
+		w.Header().Add("HX-Trigger", base.ErrorToast("Merge failed!", err.Error()))
+		return
+	}
+	w.Header().Add("HX-Trigger", handler.Json(triggerDef{
+		ToastMessage: base.ToastMessage{
+			Event: base.ToastMessageContent{
+				Type:  base.MsgSuccess,
+				Title: "Documents merged!",
+				Text:  fmt.Sprintf("The new document '%s' was created from %d documents.", doc.Title, len(ids)),
+			},
+		},
+		Refresh: "now",
+	}))
+}
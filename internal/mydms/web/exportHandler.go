@@ -0,0 +1,110 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/internal/mydms/app/export"
+	"golang.binggl.net/monorepo/internal/mydms/app/filestore"
+	"golang.binggl.net/monorepo/pkg/logging"
+)
+
+// exportTimeout limits the ZIP archives, the timeout of the router would cancel the export
+// after the response was started and leave a truncated archive
+const exportTimeout = 30 * time.Minute
+
+// exportContext is not cancelled by the timeout of the router. A client closing the connection
+// fails the writes of the response, which ends the export.
+func exportContext(r *http.Request) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(r.Context()), exportTimeout)
+}
+
+// ExportHandler provides the documents of a search as download
+type ExportHandler struct {
+	DocSvc  document.Service
+	FileSvc filestore.FileService
+	Logger  logging.Logger
+}
+
+// Export returns the index of the documents matching the search or a ZIP archive with the files
+func (e *ExportHandler) Export() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := ensureUser(r)
+		format, err := export.ParseFormat(queryParam(r, "format"))
+		if err != nil {
+			e.Logger.ErrorRequest(fmt.Sprintf("invalid export requested; %v", err), r)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		filter := export.Filter{
+			Query:  queryParam(r, searchParam),
+			Tag:    queryParam(r, "tag"),
+			Sender: queryParam(r, "sender"),
+		}
+		filter.From, filter.Until = parseDateRange(e.Logger, queryParam(r, "from"), queryParam(r, "until"))
+
+		ctx, cancel := exportContext(r)
+		defer cancel()
+		e.Logger.InfoRequest(fmt.Sprintf("export the documents as '%s' for user: '%s'", format, user.Username), r)
+		docs, err := export.Search(ctx, e.DocSvc, filter)
+		if err != nil {
+			e.Logger.ErrorRequest(fmt.Sprintf("could not search the documents to export for user '%s'; '%v'", user.Username, err), r)
+			http.Error(w, "could not export the documents", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=mydms_export_%s.%s", time.Now().Format("20060102"), format))
+		switch format {
+		case export.XLSX:
+			err = export.WriteXLSX(w, docs)
+		case export.ZIP:
+			err = export.WriteZIP(ctx, w, docs, e.FileSvc, e.Logger)
+		default:
+			err = export.WriteCSV(w, docs)
+		}
+		if err != nil {
+			e.Logger.Error(fmt.Sprintf("could not write the export to client; %v", err))
+		}
+	}
+}
+
+// ExportSelected returns a ZIP archive with the files of the selected documents, unknown
+// documents are skipped
+func (e *ExportHandler) ExportSelected() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := ensureUser(r)
+		if err := r.ParseForm(); err != nil {
+			e.Logger.ErrorRequest(fmt.Sprintf("could not parse supplied form data; '%v'", err), r)
+			http.Error(w, "could not parse supplied form data", http.StatusBadRequest)
+			return
+		}
+
+		var docs []document.Document
+		for _, id := range r.Form["id"] {
+			doc, err := e.DocSvc.GetDocumentByID(r.Context(), id)
+			if err != nil {
+				e.Logger.Warn(fmt.Sprintf("the selected document '%s' is not available for the export; %v", id, err))
+				continue
+			}
+			docs = append(docs, doc)
+		}
+		if len(docs) == 0 {
+			http.Error(w, "no documents are selected", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := exportContext(r)
+		defer cancel()
+		e.Logger.InfoRequest(fmt.Sprintf("export %d selected documents for user: '%s'", len(docs), user.Username), r)
+		w.Header().Set("Content-Type", export.ZIP.ContentType())
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=mydms_selection_%s.%s", time.Now().Format("20060102"), export.ZIP))
+		if err := export.WriteZIP(ctx, w, docs, e.FileSvc, e.Logger); err != nil {
+			e.Logger.Error(fmt.Sprintf("could not write the export to client; %v", err))
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"golang.binggl.net/monorepo/internal/mydms/app/filestore"
 	"golang.binggl.net/monorepo/pkg/logging"
+	"golang.binggl.net/monorepo/pkg/security"
 	"golang.binggl.net/monorepo/pkg/text"
 )
 
@@ -30,7 +31,7 @@ func (f *FileHandler) GetDocumentPayload() http.HandlerFunc {
 			return
 		}
 		f.Logger.Debug(fmt.Sprintf("get payload for path '%s'", string(decodedPath)))
-		file, err := f.FileSvc.GetFile(string(decodedPath))
+		file, err := f.FileSvc.GetFile(r.Context(), string(decodedPath))
 		if err != nil {
 			f.Logger.ErrorRequest(fmt.Sprintf("could not access the document payload; %v", err), r)
 			w.WriteHeader(http.StatusNotFound)
@@ -38,6 +39,7 @@ func (f *FileHandler) GetDocumentPayload() http.HandlerFunc {
 		}
 
 		w.Header().Set("Content-Type", file.MimeType)
+		security.RestrictPayload(w)
 		_, err = w.Write(file.Payload)
 		if err != nil {
 			f.Logger.Error(fmt.Sprintf("could not write document payload to client; %v", err))
@@ -0,0 +1,116 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+	"golang.binggl.net/monorepo/internal/mydms/html"
+	base "golang.binggl.net/monorepo/pkg/handler/html"
+)
+
+// DisplayListItems shows the tags or senders with the number of their documents
+func (t *TemplateHandler) DisplayListItems() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := ensureUser(r)
+		st, ok := searchType(pathParam(r, "type"))
+		if !ok {
+			http.Redirect(w, r, "/mydms/lists/"+document.TAGS.String(), http.StatusFound)
+			return
+		}
+		items, err := t.DocSvc.ListItems(r.Context(), st)
+		message := listMessage(r.URL.Query())
+		if err != nil {
+			t.Logger.ErrorRequest(fmt.Sprintf("could not get the %s; %v", st, err), r)
+			message = fmt.Sprintf("The %s could not be loaded!", st)
+		}
+
+		base.Layout(
+			t.pageModel(r, "Tags & Senders", "", "/public/mydms.svg", *user),
+			html.ListsStyles(),
+			html.ListsNavigation(),
+			html.ListsContent(st, items, message),
+			searchURL,
+		).Render(w)
+	}
+}
+
+// RenameListItem changes the name of a tag or sender
+func (t *TemplateHandler) RenameListItem() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		t.listAction(w, r, "renamed", func(st document.SearchType, id string) error {
+			return t.DocSvc.RenameListItem(r.Context(), st, id, r.FormValue("name-"+id))
+		})
+	}
+}
+
+// MergeListItems replaces the selected tags or senders by the target
+func (t *TemplateHandler) MergeListItems() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		t.listAction(w, r, "merged", func(st document.SearchType, _ string) error {
+			return t.DocSvc.MergeListItems(r.Context(), st, r.FormValue("target"), r.Form["id"])
+		})
+	}
+}
+
+// DeleteListItem removes a tag or sender from all documents
+func (t *TemplateHandler) DeleteListItem() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		t.listAction(w, r, "deleted", func(st document.SearchType, id string) error {
+			return t.DocSvc.DeleteListItem(r.Context(), st, id)
+		})
+	}
+}
+
+// listAction performs the change of the entries and reloads the page, an error is shown above the entries
+func (t *TemplateHandler) listAction(w http.ResponseWriter, r *http.Request, done string, action func(st document.SearchType, id string) error) {
+	user := ensureUser(r)
+	st, ok := searchType(pathParam(r, "type"))
+	if !ok {
+		html.ListError("unknown type of the entries").Render(w)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		t.Logger.ErrorRequest(fmt.Sprintf("could not parse supplied form data; '%v'", err), r)
+		html.ListError("could not parse supplied form data").Render(w)
+		return
+	}
+	id := pathParam(r, "id")
+	t.Logger.InfoRequest(fmt.Sprintf("the %s '%s' are %s by user: '%s'", st, id, done, user.Username), r)
+
+	if err := action(st, id); err != nil {
+		var (
+			validation *shared.ValidationError
+			notFound   *shared.NotFoundError
+		)
+		if !errors.As(err, &validation) && !errors.As(err, &notFound) {
+			t.Logger.ErrorRequest(fmt.Sprintf("could not change the %s; %v", st, err), r)
+		}
+		html.ListError(err.Error()).Render(w)
+		return
+	}
+	// https://htmx.org/headers/hx-redirect/
+	w.Header().Add("HX-Redirect", fmt.Sprintf("/mydms/lists/%s?done=%s", st, done))
+}
+
+func searchType(name string) (document.SearchType, bool) {
+	switch name {
+	case document.TAGS.String():
+		return document.TAGS, true
+	case document.SENDERS.String():
+		return document.SENDERS, true
+	}
+	return document.TAGS, false
+}
+
+// listMessage describes the outcome of the last change of the entries
+func listMessage(query url.Values) string {
+	switch done := query.Get("done"); done {
+	case "renamed", "merged", "deleted":
+		return fmt.Sprintf("The entries were %s.", done)
+	}
+	return ""
+}
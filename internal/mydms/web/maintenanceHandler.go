@@ -0,0 +1,93 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+	"golang.binggl.net/monorepo/internal/mydms/html"
+	base "golang.binggl.net/monorepo/pkg/handler/html"
+)
+
+// DisplayMaintenance lists the groups of duplicate documents
+func (t *TemplateHandler) DisplayMaintenance() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := ensureUser(r)
+		groups, err := t.DocSvc.Duplicates(r.Context())
+		message := maintenanceMessage(r.URL.Query())
+		if err != nil {
+			t.Logger.ErrorRequest(fmt.Sprintf("could not get the duplicates; %v", err), r)
+			message = "The duplicates could not be determined!"
+		}
+
+		model := t.pageModel(r, "Maintenance", "", "/public/mydms.svg", *user)
+		base.Layout(
+			model,
+			html.MaintenanceStyles(),
+			html.MaintenanceNavigation(),
+			html.MaintenanceContent(groups, message, model.CSRFToken),
+			searchURL,
+		).Render(w)
+	}
+}
+
+// ComputeContentHashes hashes the files of the documents stored without a content hash
+func (t *TemplateHandler) ComputeContentHashes() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := ensureUser(r)
+		t.Logger.InfoRequest(fmt.Sprintf("compute the missing content hashes for user: '%s'", user.Username), r)
+
+		result, err := t.DocSvc.ComputeContentHashes(r.Context())
+		if err != nil {
+			t.Logger.ErrorRequest(fmt.Sprintf("could not compute the content hashes; %v", err), r)
+			result.Failed++
+		}
+		query := url.Values{}
+		query.Set("computed", strconv.Itoa(result.Computed))
+		query.Set("failed", strconv.Itoa(result.Failed))
+		http.Redirect(w, r, "/mydms/maintenance?"+query.Encode(), http.StatusSeeOther)
+	}
+}
+
+// MergeDuplicates merges the duplicates of the form into the selected document
+func (t *TemplateHandler) MergeDuplicates() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := ensureUser(r)
+		if err := r.ParseForm(); err != nil {
+			t.Logger.ErrorRequest(fmt.Sprintf("could not parse supplied form data; '%v'", err), r)
+			html.MergeError("could not parse supplied form data").Render(w)
+			return
+		}
+		keep := r.FormValue("keep")
+		ids := r.Form["id"]
+		t.Logger.InfoRequest(fmt.Sprintf("merge the duplicates %v into '%s' for user: '%s'", ids, keep, user.Username), r)
+
+		if _, err := t.DocSvc.MergeDuplicates(r.Context(), keep, ids); err != nil {
+			var (
+				validation *shared.ValidationError
+				notFound   *shared.NotFoundError
+			)
+			if !errors.As(err, &validation) && !errors.As(err, &notFound) {
+				t.Logger.ErrorRequest(fmt.Sprintf("could not merge the duplicates; %v", err), r)
+			}
+			html.MergeError(fmt.Sprintf("Cannot merge the duplicates; '%v'", err)).Render(w)
+			return
+		}
+		// https://htmx.org/headers/hx-redirect/
+		w.Header().Add("HX-Redirect", fmt.Sprintf("/mydms/maintenance?merged=%d", len(ids)-1))
+	}
+}
+
+// maintenanceMessage describes the outcome of the last maintenance action
+func maintenanceMessage(query url.Values) string {
+	if merged := query.Get("merged"); merged != "" {
+		return fmt.Sprintf("%s duplicate(s) merged.", merged)
+	}
+	if computed := query.Get("computed"); computed != "" {
+		return fmt.Sprintf("Computed %s content hash(es), %s document(s) could not be processed.", computed, query.Get("failed"))
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"golang.binggl.net/monorepo/internal/common/upload"
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/internal/mydms/html"
+	base "golang.binggl.net/monorepo/pkg/handler/html"
+)
+
+// DisplayPages shows the page operations and the revisions of a document
+func (t *TemplateHandler) DisplayPages() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := ensureUser(r)
+		id := pathParam(r, "id")
+		p, err := t.DocSvc.Pages(r.Context(), id)
+		if err != nil {
+			if !isClientError(err) {
+				t.Logger.ErrorRequest(fmt.Sprintf("could not get the pages of document '%s'; %v", id, err), r)
+			}
+			http.Redirect(w, r, "/mydms", http.StatusFound)
+			return
+		}
+
+		base.Layout(
+			t.pageModel(r, "Pages", "", "/public/mydms.svg", *user),
+			html.PagesStyles(),
+			html.PagesNavigation(),
+			html.PagesContent(p, pagesMessage(r.URL.Query())),
+			searchURL,
+		).Render(w)
+	}
+}
+
+// EditPages rotates and removes the pages of the form, the result is a new revision of the document
+func (t *TemplateHandler) EditPages() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := ensureUser(r)
+		id := pathParam(r, "id")
+		if err := r.ParseForm(); err != nil {
+			t.Logger.ErrorRequest(fmt.Sprintf("could not parse supplied form data; '%v'", err), r)
+			w.Header().Add("HX-Trigger", base.ErrorToast("Pages not changed!", "could not parse supplied form data"))
+			return
+		}
+		// an invalid rotation is reported by the service
+		degrees, _ := strconv.Atoi(r.FormValue("degrees"))
+		edit := document.PageEdit{
+			Rotate:  r.FormValue("rotate"),
+			Degrees: degrees,
+			Remove:  r.FormValue("remove"),
+		}
+		t.Logger.InfoRequest(fmt.Sprintf("change the pages of document '%s' for user: '%s'", id, user.Username), r)
+
+		if _, err := t.DocSvc.EditPages(r.Context(), id, edit); err != nil {
+			t.pagesError(w, r, id, err)
+			return
+		}
+		// https://htmx.org/headers/hx-redirect/
+		w.Header().Add("HX-Redirect", "/mydms/pages/"+id+"?changed=true")
+	}
+}
+
+// SplitDocument creates new documents from the page ranges of the form
+func (t *TemplateHandler) SplitDocument() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := ensureUser(r)
+		id := pathParam(r, "id")
+		if err := r.ParseForm(); err != nil {
+			t.Logger.ErrorRequest(fmt.Sprintf("could not parse supplied form data; '%v'", err), r)
+			w.Header().Add("HX-Trigger", base.ErrorToast("Document not split!", "could not parse supplied form data"))
+			return
+		}
+		t.Logger.InfoRequest(fmt.Sprintf("split the document '%s' for user: '%s'", id, user.Username), r)
+
+		docs, err := t.DocSvc.SplitDocument(r.Context(), id, r.FormValue("ranges"))
+		if err != nil {
+			t.pagesError(w, r, id, err)
+			return
+		}
+		w.Header().Add("HX-Redirect", fmt.Sprintf("/mydms/pages/%s?split=%d", id, len(docs)))
+	}
+}
+
+// AppendPages adds the pages of the uploaded PDF to the document as a new revision
+func (t *TemplateHandler) AppendPages() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := ensureUser(r)
+		id := pathParam(r, "id")
+		r.ParseMultipartForm(t.MaxUploadSize)
+
+		file, meta, err := r.FormFile("pages-upload")
+		if err != nil {
+			t.Logger.ErrorRequest(fmt.Sprintf("could not upload the pages; '%v'", err), r)
+			w.Header().Add("HX-Trigger", base.ErrorToast("Pages not appended!", "no file was uploaded"))
+			return
+		}
+		defer file.Close()
+		t.Logger.InfoRequest(fmt.Sprintf("append the pages of '%s' to document '%s' for user: '%s'", meta.Filename, id, user.Username), r)
+
+		token, err := t.UploadSvc.Save(upload.File{
+			File:     file,
+			Name:     meta.Filename,
+			Size:     meta.Size,
+			MimeType: meta.Header.Get("Content-Type"),
+		})
+		if err != nil {
+			t.Logger.ErrorRequest(fmt.Sprintf("could not save the upload; '%v'", err), r)
+			w.Header().Add("HX-Trigger", base.ErrorToast("Pages not appended!", err.Error()))
+			return
+		}
+		if _, err = t.DocSvc.AppendUpload(r.Context(), id, token); err != nil {
+			if err := t.UploadSvc.Delete(token); err != nil {
+				t.Logger.Warn(fmt.Sprintf("could not delete the upload '%s'; %v", token, err))
+			}
+			t.pagesError(w, r, id, err)
+			return
+		}
+		w.Header().Add("HX-Redirect", "/mydms/pages/"+id+"?changed=true")
+	}
+}
+
+// pagesError reports the failed page operation by a toast message
+func (t *TemplateHandler) pagesError(w http.ResponseWriter, r *http.Request, id string, err error) {
+	if !isClientError(err) {
+		t.Logger.ErrorRequest(fmt.Sprintf("could not change the pages of document '%s'; %v", id, err), r)
+	}
+	w.Header().Add("HX-Trigger", base.ErrorToast("Pages not changed!", err.Error()))
+}
+
+// pagesMessage describes the outcome of the last page operation
+func pagesMessage(query url.Values) string {
+	if split := query.Get("split"); split != "" {
+		return fmt.Sprintf("Created %s new document(s).", split)
+	}
+	if query.Get("changed") != "" {
+		return "The pages were saved as a new revision."
+	}
+	return ""
+}
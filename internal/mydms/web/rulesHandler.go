@@ -0,0 +1,194 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/internal/mydms/app/rules"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+	"golang.binggl.net/monorepo/internal/mydms/html"
+	base "golang.binggl.net/monorepo/pkg/handler/html"
+)
+
+// testDocuments limits the number of the latest documents used to test a rule
+const testDocuments = 100
+
+// DisplayRules shows the rules next to the form of a new rule
+func (t *TemplateHandler) DisplayRules() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		t.renderRules(w, r, html.RuleForm{Enabled: true, Currency: document.DefaultCurrency})
+	}
+}
+
+// DisplayRule shows the rules next to the form of the rule specified by the id
+func (t *TemplateHandler) DisplayRule() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := pathParam(r, "id")
+		rule, err := t.RuleSvc.GetRule(r.Context(), id)
+		if err != nil {
+			t.Logger.ErrorRequest(fmt.Sprintf("could not get rule for id '%s'; %v", id, err), r)
+			http.Redirect(w, r, "/mydms/rules", http.StatusFound)
+			return
+		}
+		t.renderRules(w, r, prepRuleForm(rule))
+	}
+}
+
+// SaveRule creates or updates the rule of the form, invalid values are shown again
+func (t *TemplateHandler) SaveRule() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := ensureUser(r)
+		form, rule, err := t.parseRuleForm(r)
+		if err != nil {
+			form.Error = err.Error()
+			t.renderRules(w, r, form)
+			return
+		}
+
+		t.Logger.InfoRequest(fmt.Sprintf("save the rule '%s' for user: '%s'", rule.Name, user.Username), r)
+		saved, err := t.RuleSvc.SaveRule(r.Context(), rule)
+		if err != nil {
+			t.Logger.ErrorRequest(fmt.Sprintf("could not save the rule; %v", err), r)
+			form.Error = fmt.Sprintf("Cannot save the rule; '%v'", err)
+			t.renderRules(w, r, form)
+			return
+		}
+		http.Redirect(w, r, "/mydms/rules/"+saved.ID, http.StatusSeeOther)
+	}
+}
+
+// TestRule applies the rule of the form to the latest documents without changing them
+func (t *TemplateHandler) TestRule() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, rule, err := t.parseRuleForm(r)
+		if err != nil {
+			html.RuleTestResults(nil, 0, err.Error()).Render(w)
+			return
+		}
+
+		t.Logger.InfoRequest(fmt.Sprintf("test the rule '%s'", rule.Name), r)
+		docs, err := t.DocSvc.SearchDocuments(r.Context(), "", "", "", time.Time{}, time.Time{}, testDocuments, 0)
+		if err != nil {
+			t.Logger.ErrorRequest(fmt.Sprintf("could not get the documents; %v", err), r)
+			html.RuleTestResults(nil, 0, "could not get the documents").Render(w)
+			return
+		}
+		results, err := t.RuleSvc.TestRule(r.Context(), rule, docs.Documents)
+		if err != nil {
+			var validation *shared.ValidationError
+			if !errors.As(err, &validation) {
+				t.Logger.ErrorRequest(fmt.Sprintf("could not test the rule; %v", err), r)
+			}
+			html.RuleTestResults(nil, 0, err.Error()).Render(w)
+			return
+		}
+		html.RuleTestResults(results, len(docs.Documents), "").Render(w)
+	}
+}
+
+// DeleteRule removes the rule with the given id and shows the list of the rules
+func (t *TemplateHandler) DeleteRule() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := pathParam(r, "id")
+		user := ensureUser(r)
+		t.Logger.InfoRequest(fmt.Sprintf("delete rule by id: '%s' for user: '%s'", id, user.Username), r)
+
+		if err := t.RuleSvc.DeleteRule(r.Context(), id); err != nil {
+			t.Logger.ErrorRequest(fmt.Sprintf("could not delete rule by id '%s'; '%v'", id, err), r)
+		}
+		// https://htmx.org/headers/hx-redirect/
+		w.Header().Add("HX-Redirect", "/mydms/rules")
+	}
+}
+
+func (t *TemplateHandler) renderRules(w http.ResponseWriter, r *http.Request, form html.RuleForm) {
+	user := ensureUser(r)
+	list, err := t.RuleSvc.Rules(r.Context())
+	if err != nil {
+		t.Logger.ErrorRequest(fmt.Sprintf("could not get the rules; %v", err), r)
+	}
+
+	model := t.pageModel(r, "Rules", "", "/public/mydms.svg", *user)
+	form.CSRFToken = model.CSRFToken
+	base.Layout(
+		model,
+		html.RulesStyles(),
+		html.RulesNavigation(),
+		html.RulesContent(list, form),
+		searchURL,
+	).Render(w)
+}
+
+// parseRuleForm returns the supplied values and the rule, the error describes invalid values
+func (t *TemplateHandler) parseRuleForm(r *http.Request) (html.RuleForm, rules.Rule, error) {
+	if err := r.ParseForm(); err != nil {
+		t.Logger.ErrorRequest(fmt.Sprintf("could not parse supplied form data; '%v'", err), r)
+		return html.RuleForm{}, rules.Rule{}, fmt.Errorf("could not parse supplied form data")
+	}
+
+	const formPrefix = "rule-"
+	form := html.RuleForm{
+		ID:            r.FormValue(formPrefix + "id"),
+		Name:          r.FormValue(formPrefix + "name"),
+		Position:      strings.TrimSpace(r.FormValue(formPrefix + "position")),
+		Enabled:       r.FormValue(formPrefix+"enabled") == "on",
+		Tags:          r.FormValue(formPrefix + "tags"),
+		Senders:       r.FormValue(formPrefix + "senders"),
+		TitleTemplate: r.FormValue(formPrefix + "title"),
+		Amount:        strings.TrimSpace(r.FormValue(formPrefix + "amount")),
+		Currency:      r.FormValue(formPrefix + "currency"),
+	}
+	for i := range html.RuleConditions {
+		form.Conditions = append(form.Conditions, rules.Condition{
+			Field:    rules.Field(r.FormValue(fmt.Sprintf("%sfield-%d", formPrefix, i))),
+			Operator: rules.Operator(r.FormValue(fmt.Sprintf("%soperator-%d", formPrefix, i))),
+			Value:    r.FormValue(fmt.Sprintf("%svalue-%d", formPrefix, i)),
+		})
+	}
+
+	rule := rules.Rule{
+		ID:            form.ID,
+		Name:          form.Name,
+		Enabled:       form.Enabled,
+		Conditions:    form.Conditions,
+		Tags:          strings.Split(form.Tags, ","),
+		Senders:       strings.Split(form.Senders, ","),
+		TitleTemplate: form.TitleTemplate,
+		Amount:        document.Money{Currency: strings.ToUpper(strings.TrimSpace(form.Currency))},
+	}
+	if form.Position != "" {
+		pos, err := strconv.Atoi(form.Position)
+		if err != nil {
+			return form, rule, fmt.Errorf("the position '%s' is not a number", form.Position)
+		}
+		rule.Position = pos
+	}
+	if form.Amount != "" {
+		amount, err := document.ParseMoney(form.Amount, form.Currency)
+		if err != nil {
+			return form, rule, err
+		}
+		rule.Amount = amount
+	}
+	return form, rule, nil
+}
+
+func prepRuleForm(rule rules.Rule) html.RuleForm {
+	return html.RuleForm{
+		ID:            rule.ID,
+		Name:          rule.Name,
+		Position:      strconv.Itoa(rule.Position),
+		Enabled:       rule.Enabled,
+		Conditions:    rule.Conditions,
+		Tags:          strings.Join(rule.Tags, ", "),
+		Senders:       strings.Join(rule.Senders, ", "),
+		TitleTemplate: rule.TitleTemplate,
+		Amount:        amountValue(rule.Amount),
+		Currency:      currencyValue(rule.Amount),
+	}
+}
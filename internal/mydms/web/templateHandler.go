@@ -3,14 +3,18 @@ package web
 import (
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"golang.binggl.net/monorepo/internal/common"
+	"golang.binggl.net/monorepo/internal/common/crypter"
 	"golang.binggl.net/monorepo/internal/common/upload"
 	"golang.binggl.net/monorepo/internal/mydms/app/document"
+	"golang.binggl.net/monorepo/internal/mydms/app/reminder"
+	"golang.binggl.net/monorepo/internal/mydms/app/rules"
 	"golang.binggl.net/monorepo/internal/mydms/html"
 	"golang.binggl.net/monorepo/pkg/handler"
 	base "golang.binggl.net/monorepo/pkg/handler/html"
@@ -28,9 +32,15 @@ type TemplateHandler struct {
 	*handler.TemplateHandler
 	DocSvc        document.Service
 	UploadSvc     upload.Service
+	RuleSvc       rules.Service
+	ReminderSvc   reminder.Service
 	Version       string
 	Build         string
 	MaxUploadSize int64
+	// BaseURL is the public address used for the calendar feed, derived from the request if empty
+	BaseURL string
+	// RemindersByMail is true if a mail server sends the reminders
+	RemindersByMail bool
 }
 
 const defaultPageSize = 20
@@ -51,12 +61,13 @@ func (t *TemplateHandler) DisplayDocuments() http.HandlerFunc {
 
 		documents, search, numDocs, next = t.getDocuments(r)
 
+		model := t.pageModel(r, "Documents", search, "/public/mydms.svg", *user)
 		base.Layout(
-			t.pageModel("Documents", search, "/public/mydms.svg", *user),
+			model,
 			html.DocumentsStyles(),
-			html.DocumentsNavigation(search),
+			html.DocumentsNavigation(search, t.numDrafts(r)),
 			html.DocumentsContent(
-				html.DocumentList(numDocs, next, documents), search,
+				html.DocumentList(numDocs, next, documents), search, queryErrors(search), model.CSRFToken,
 			), searchURL,
 		).Render(w)
 	}
@@ -85,25 +96,14 @@ func (t *TemplateHandler) getDocuments(r *http.Request) (documents document.Page
 	err = r.ParseForm()
 	if err == nil {
 		search = r.FormValue(searchParam)
-		skipParam := r.FormValue(skipParam)
-
-		if skipParam != "" {
-			skip, err = strconv.Atoi(skipParam)
-			if err != nil {
-				t.Logger.Warn(fmt.Sprintf("could not parse skip param: '%s'; %v", skipParam, err))
-				skip = 0
-			}
-			if skip < 0 {
-				skip = 0
-			}
-		}
+		skip = t.parseSkip(r)
 	} else {
 		t.Logger.Error(fmt.Sprintf("could not parse provided form data; %v", err))
 	}
 
 	t.Logger.InfoRequest(fmt.Sprintf("display the documents for user: '%s'", user.Username), r)
 
-	documents, err = t.DocSvc.SearchDocuments(search, "", "", time.Time{}, time.Time{}, defaultPageSize, skip)
+	documents, err = t.DocSvc.SearchDocuments(r.Context(), search, "", "", time.Time{}, time.Time{}, defaultPageSize, skip)
 	if err != nil {
 		t.Logger.ErrorRequest(fmt.Sprintf("could not get documents for user '%s'; '%v'", user.Username, err), r)
 		documents = document.PagedDocument{
@@ -112,14 +112,151 @@ func (t *TemplateHandler) getDocuments(r *http.Request) (documents document.Page
 		}
 	}
 
-	docSize := len(documents.Documents)
-	numDocs = docSize + skip
+	numDocs, next = paging(documents, skip)
+	return documents, search, numDocs, next
+}
+
+func (t *TemplateHandler) parseSkip(r *http.Request) int {
+	skipParam := r.FormValue(skipParam)
+	if skipParam == "" {
+		return 0
+	}
+	skip, err := strconv.Atoi(skipParam)
+	if err != nil {
+		t.Logger.Warn(fmt.Sprintf("could not parse skip param: '%s'; %v", skipParam, err))
+		return 0
+	}
+	return max(skip, 0)
+}
+
+// paging returns the number of documents displayed so far and the skip-value of the next page
+func paging(documents document.PagedDocument, skip int) (numDocs, next int) {
+	numDocs = len(documents.Documents) + skip
 	next = defaultPageSize + skip
 	if next > documents.TotalEntries {
 		next = 0
 	}
+	return numDocs, next
+}
 
-	return documents, search, numDocs, next
+// DisplayReviewQueue shows the drafts created by the inbox, which need to be completed
+func (t *TemplateHandler) DisplayReviewQueue() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := ensureUser(r)
+		documents, numDocs, next := t.getReviewDocuments(r)
+
+		base.Layout(
+			t.pageModel(r, "Review", "", "/public/mydms.svg", *user),
+			html.DocumentsStyles(),
+			html.ReviewNavigation(documents.TotalEntries),
+			html.ReviewContent(
+				html.ReviewList(numDocs, next, documents),
+			), searchURL,
+		).Render(w)
+	}
+}
+
+// DisplayReviewQueuePartial returns the HTML list code for the drafts
+func (t *TemplateHandler) DisplayReviewQueuePartial() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		documents, numDocs, next := t.getReviewDocuments(r)
+		html.ReviewList(numDocs, next, documents).Render(w)
+	}
+}
+
+func (t *TemplateHandler) getReviewDocuments(r *http.Request) (documents document.PagedDocument, numDocs, next int) {
+	user := ensureUser(r)
+	skip := 0
+	if err := r.ParseForm(); err == nil {
+		skip = t.parseSkip(r)
+	} else {
+		t.Logger.Error(fmt.Sprintf("could not parse provided form data; %v", err))
+	}
+
+	t.Logger.InfoRequest(fmt.Sprintf("display the review queue for user: '%s'", user.Username), r)
+	documents, err := t.DocSvc.ReviewQueue(r.Context(), defaultPageSize, skip)
+	if err != nil {
+		t.Logger.ErrorRequest(fmt.Sprintf("could not get the review queue for user '%s'; '%v'", user.Username, err), r)
+		documents = document.PagedDocument{
+			TotalEntries: 0,
+			Documents:    make([]document.Document, 0),
+		}
+	}
+	numDocs, next = paging(documents, skip)
+	return documents, numDocs, next
+}
+
+// numDrafts is the size of the review queue
+func (t *TemplateHandler) numDrafts(r *http.Request) int {
+	drafts, err := t.DocSvc.ReviewQueue(r.Context(), 1, 0)
+	if err != nil {
+		t.Logger.ErrorRequest(fmt.Sprintf("could not get the review queue; '%v'", err), r)
+		return 0
+	}
+	return drafts.TotalEntries
+}
+
+// reportDateLayout is the format of the date inputs of the report and export filter
+const reportDateLayout = "2006-01-02"
+
+// DisplayReports aggregates the amounts of the documents, the documents are filtered with the
+// same criteria as the document search
+func (t *TemplateHandler) DisplayReports() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := ensureUser(r)
+		filter := html.ReportFilter{
+			Search:   queryParam(r, searchParam),
+			Tag:      queryParam(r, "tag"),
+			Sender:   queryParam(r, "sender"),
+			From:     queryParam(r, "from"),
+			Until:    queryParam(r, "until"),
+			Currency: strings.ToUpper(queryParam(r, "currency")),
+		}
+		from, until := parseDateRange(t.Logger, filter.From, filter.Until)
+
+		t.Logger.InfoRequest(fmt.Sprintf("display the reports for user: '%s'", user.Username), r)
+		report, err := t.DocSvc.SpendingReport(r.Context(), filter.Search, filter.Tag, filter.Sender, from, until, filter.Currency)
+		if err != nil {
+			t.Logger.ErrorRequest(fmt.Sprintf("could not create the report for user '%s'; '%v'", user.Username, err), r)
+			report = document.Report{Currency: document.DefaultCurrency}
+		}
+
+		base.Layout(
+			t.pageModel(r, "Reports", filter.Search, "/public/mydms.svg", *user),
+			html.ReportsStyles(),
+			html.ReportsNavigation(),
+			html.ReportsContent(report, filter, queryErrors(filter.Search)),
+			searchURL,
+		).Render(w)
+	}
+}
+
+// queryErrors returns the invalid fragments of the search query, which are ignored by the search
+func queryErrors(search string) []document.QueryError {
+	_, errs := document.ParseQuery(search)
+	return errs
+}
+
+// parseDateRange parses the dates of the filter, the range includes the documents of the last day
+func parseDateRange(logger logging.Logger, from, until string) (time.Time, time.Time) {
+	f := parseDate(logger, from)
+	u := parseDate(logger, until)
+	if !u.IsZero() {
+		u = u.Add(24*time.Hour - time.Nanosecond)
+	}
+	return f, u
+}
+
+func parseDate(logger logging.Logger, value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	d, err := time.Parse(reportDateLayout, value)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("could not parse the date '%s'; %v", value, err))
+		return time.Time{}
+	}
+	return d
 }
 
 // ShowEditDocumentDialog is used to display the document edit dialog
@@ -140,7 +277,7 @@ func (t *TemplateHandler) ShowEditDocumentDialog() http.HandlerFunc {
 			t.Logger.ErrorRequest("empty param supplied for id", r)
 		default:
 			t.Logger.Debug(fmt.Sprintf("fetch document by id '%s' for user '%s'", id, *user), logging.LogV("id", id))
-			doc, err = t.DocSvc.GetDocumentByID(id)
+			doc, err = t.DocSvc.GetDocumentByID(r.Context(), id)
 			if err != nil {
 				t.Logger.ErrorRequest(fmt.Sprintf("could not get document for id '%s'; %v", id, err), r)
 			}
@@ -172,7 +309,7 @@ func (t *TemplateHandler) SearchListItems() http.HandlerFunc {
 
 		t.Logger.Debug(fmt.Sprintf("search list '%s' for item '%s'", listType, q))
 
-		items, err := t.DocSvc.SearchList(q, st)
+		items, err := t.DocSvc.SearchList(r.Context(), q, st)
 		if err != nil {
 			t.Logger.ErrorRequest(fmt.Sprintf("could search items in list; %v", err), r)
 			w.WriteHeader(http.StatusInternalServerError)
@@ -217,16 +354,21 @@ func (t *TemplateHandler) UploadDocument() http.HandlerFunc {
 		if err != nil {
 			errMsg := strings.ReplaceAll(err.Error(), "\"", "'")
 			t.Logger.ErrorRequest(fmt.Sprintf("could not upload document; '%v'", err), r)
-			html.DisplayTempDocumentUpload("", "", errMsg).Render(w)
+			html.DisplayTempDocumentUpload("", "", errMsg, nil).Render(w)
 			return
 		}
 		defer file.Close()
 
-		initPass := r.Form.Get("doc-initPass")
-		newPass := r.Form.Get("doc-newPass")
+		// the initial password alone removes the encryption of the document
 		encReq := upload.EncryptionRequest{
-			InitPassword: initPass,
-			Password:     newPass,
+			InitPassword:  r.Form.Get("doc-initPass"),
+			Password:      r.Form.Get("doc-newPass"),
+			OwnerPassword: r.Form.Get("doc-ownerPass"),
+			Permissions: crypter.PdfPermissions{
+				Print:  r.Form.Get("doc-permPrint") == "on",
+				Copy:   r.Form.Get("doc-permCopy") == "on",
+				Modify: r.Form.Get("doc-permModify") == "on",
+			},
 		}
 
 		cType := meta.Header.Get("Content-Type")
@@ -240,14 +382,29 @@ func (t *TemplateHandler) UploadDocument() http.HandlerFunc {
 		if err != nil {
 			errMsg := strings.ReplaceAll(err.Error(), "\"", "'")
 			t.Logger.ErrorRequest(fmt.Sprintf("could not save document; '%v'", err), r)
-			html.DisplayTempDocumentUpload("", "", errMsg).Render(w)
+			html.DisplayTempDocumentUpload("", "", errMsg, nil).Render(w)
 			return
 		}
 
-		html.DisplayTempDocumentUpload(meta.Filename, tempId, "").Render(w)
+		html.DisplayTempDocumentUpload(meta.Filename, tempId, "", t.findDuplicates(r, tempId)).Render(w)
 	}
 }
 
+// findDuplicates returns the documents storing a file identical to the upload
+func (t *TemplateHandler) findDuplicates(r *http.Request, tempID string) []document.Document {
+	u, err := t.UploadSvc.Read(tempID)
+	if err != nil {
+		t.Logger.ErrorRequest(fmt.Sprintf("could not read the upload '%s'; '%v'", tempID, err), r)
+		return nil
+	}
+	docs, err := t.DocSvc.DocumentsByContentHash(r.Context(), document.ContentHash(u.Payload))
+	if err != nil {
+		t.Logger.ErrorRequest(fmt.Sprintf("could not search for duplicates of the upload '%s'; '%v'", tempID, err), r)
+		return nil
+	}
+	return docs
+}
+
 // we define a JSON structure which is used to trigger actions on the frontend via htmx
 type triggerDef struct {
 	base.ToastMessage
@@ -277,14 +434,14 @@ func (t *TemplateHandler) SaveDocument() http.HandlerFunc {
 
 		rcvDoc.ID = r.FormValue(formPrefix + "id")
 		rcvDoc.Title = r.FormValue(formPrefix + "title")
-		amount := r.FormValue(formPrefix + "amount")
+		amount := strings.TrimSpace(r.FormValue(formPrefix + "amount"))
+		currency := r.FormValue(formPrefix + "currency")
+		var amountErr error
 		if amount != "" {
-			// parse the supplied amount value
-			f, err := strconv.ParseFloat(amount, 32)
-			if err != nil {
-				t.Logger.Warn(fmt.Sprintf("could not parse amount '%s'; %v", amount, err))
-			} else {
-				rcvDoc.Amount = float32(f)
+			// parse the supplied amount value, the value is exact and not rounded
+			rcvDoc.Amount, amountErr = document.ParseMoney(amount, currency)
+			if amountErr != nil {
+				t.Logger.Warn(fmt.Sprintf("could not parse amount '%s'; %v", amount, amountErr))
 			}
 		}
 		rcvDoc.InvoiceNumber = r.FormValue(formPrefix + "invoicenumber")
@@ -292,9 +449,18 @@ func (t *TemplateHandler) SaveDocument() http.HandlerFunc {
 		rcvDoc.Senders = r.Form[formPrefix+"senders[]"]
 		rcvDoc.UploadToken = r.FormValue(formPrefix + "tempID")
 		rcvDoc.FileName = r.FormValue(formPrefix + "filename")
+		rcvDoc.DueDate = strings.TrimSpace(r.FormValue(formPrefix + "duedate"))
+		rcvDoc.ReminderDate = strings.TrimSpace(r.FormValue(formPrefix + "reminderdate"))
+		rcvDoc.DueType = document.DueType(r.FormValue(formPrefix + "duetype"))
 
 		validData = true
 		validDoc = prepValidDoc(rcvDoc)
+		if amountErr != nil {
+			// show the supplied values again
+			validDoc.Amount = html.ValidStr{Val: amount, Message: amountErr.Error()}
+			validDoc.Currency = html.ValidStr{Val: currency, Valid: true}
+			validData = false
+		}
 		if validDoc.Title.Val == "" {
 			validDoc.Title.Valid = false
 			validDoc.Title.Message = "Title is required"
@@ -310,6 +476,9 @@ func (t *TemplateHandler) SaveDocument() http.HandlerFunc {
 			validDoc.Senders.Message = "Senders are required"
 			validData = false
 		}
+		if !validDueDates(&validDoc) {
+			validData = false
+		}
 		if validDoc.UploadToken.Val == "" {
 			validDoc.UploadToken.Valid = false
 			validDoc.UploadToken.Message = "Document upload is required"
@@ -323,7 +492,7 @@ func (t *TemplateHandler) SaveDocument() http.HandlerFunc {
 			return
 		}
 
-		savedDoc, err := t.DocSvc.SaveDocument(rcvDoc, *user)
+		savedDoc, err := t.DocSvc.SaveDocument(r.Context(), rcvDoc, *user)
 		if err != nil {
 			t.Logger.ErrorRequest(fmt.Sprintf("could not save the supplied document; %v", err), r)
 			validDoc.Error = fmt.Sprintf("Cannot save the document; '%v'", err)
@@ -359,7 +528,7 @@ func (t *TemplateHandler) ShowDeleteConfirmDialog() http.HandlerFunc {
 		id := pathParam(r, "id")
 		user := ensureUser(r)
 		t.Logger.InfoRequest(fmt.Sprintf("get document by id: '%s' for user: '%s'", id, user.Username), r)
-		doc, err := t.DocSvc.GetDocumentByID(id)
+		doc, err := t.DocSvc.GetDocumentByID(r.Context(), id)
 		if err != nil {
 			t.Logger.ErrorRequest(fmt.Sprintf("could not get document for id '%s'; '%v'", id, err), r)
 		}
@@ -374,7 +543,7 @@ func (t *TemplateHandler) DeleteDocument() http.HandlerFunc {
 		user := ensureUser(r)
 		t.Logger.InfoRequest(fmt.Sprintf("delete document by id: '%s' for user: '%s'", id, user.Username), r)
 
-		err := t.DocSvc.DeleteDocumentByID(id)
+		err := t.DocSvc.DeleteDocumentByID(r.Context(), id)
 		if err != nil {
 			t.Logger.ErrorRequest(fmt.Sprintf("could not delete document by id '%s'; '%v'", id, err), r)
 		}
@@ -401,8 +570,8 @@ func (t *TemplateHandler) DeleteDocument() http.HandlerFunc {
 //  Internals
 // --------------------------------------------------------------------------
 
-func (t *TemplateHandler) pageModel(pageTitle, searchStr, favicon string, user security.User) base.LayoutModel {
-	return common.CreatePageModel("/"+searchURL, pageTitle, searchStr, favicon, t.Version, t.Build, t.Env, user)
+func (t *TemplateHandler) pageModel(r *http.Request, pageTitle, searchStr, favicon string, user security.User) base.LayoutModel {
+	return common.CreatePageModel(r.Context(), "/"+searchURL, pageTitle, searchStr, favicon, t.Version, t.Build, t.Env, user)
 }
 
 func ensureUser(r *http.Request) *security.User {
@@ -425,6 +594,20 @@ func pathParam(r *http.Request, name string) string {
 	return chi.URLParam(r, name)
 }
 
+// publicURL is the absolute address of the path. The configured base URL is the public address
+// of mydms including a path prefix of a reverse-proxy, without a base URL it is derived from the request.
+func publicURL(r *http.Request, baseURL, path string) string {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	if baseURL == "" {
+		scheme := "http"
+		if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+			scheme = "https"
+		}
+		baseURL = scheme + "://" + r.Host
+	}
+	return baseURL + path
+}
+
 func prepValidDoc(doc document.Document) html.Document {
 	return html.Document{
 		ID: doc.ID,
@@ -432,8 +615,12 @@ func prepValidDoc(doc document.Document) html.Document {
 			Val:   doc.Title,
 			Valid: true,
 		},
-		Amount: html.ValidFloat{
-			Val:   doc.Amount,
+		Amount: html.ValidStr{
+			Val:   amountValue(doc.Amount),
+			Valid: true,
+		},
+		Currency: html.ValidStr{
+			Val:   currencyValue(doc.Amount),
 			Valid: true,
 		},
 		FileName: html.ValidStr{
@@ -460,5 +647,55 @@ func prepValidDoc(doc document.Document) html.Document {
 			Val:   doc.Senders,
 			Valid: true,
 		},
+		DueDate: html.ValidStr{
+			Val:   doc.DueDate,
+			Valid: true,
+		},
+		ReminderDate: html.ValidStr{
+			Val:   doc.ReminderDate,
+			Valid: true,
+		},
+		DueType: html.ValidStr{
+			Val:   string(doc.DueType),
+			Valid: true,
+		},
+	}
+}
+
+// validDueDates checks the format of the dates, a reminder after the due date is not useful
+func validDueDates(doc *html.Document) bool {
+	due, dueErr := time.Parse(document.DateLayout, doc.DueDate.Val)
+	if doc.DueDate.Val != "" && dueErr != nil {
+		doc.DueDate.Valid = false
+		doc.DueDate.Message = "The due date is not a valid date"
+	}
+	reminder, reminderErr := time.Parse(document.DateLayout, doc.ReminderDate.Val)
+	if doc.ReminderDate.Val != "" && reminderErr != nil {
+		doc.ReminderDate.Valid = false
+		doc.ReminderDate.Message = "The reminder date is not a valid date"
+	}
+	if dueErr == nil && reminderErr == nil && reminder.After(due) {
+		doc.ReminderDate.Valid = false
+		doc.ReminderDate.Message = "The reminder needs to be before the due date"
+	}
+	if doc.DueType.Val != "" && !slices.Contains(document.DueTypes, document.DueType(doc.DueType.Val)) {
+		doc.DueType.Valid = false
+		doc.DueType.Message = "The type of the due date is not supported"
+	}
+	return doc.DueDate.Valid && doc.ReminderDate.Valid && doc.DueType.Valid
+}
+
+// amountValue is the decimal value shown in the edit dialog, a missing amount is empty
+func amountValue(m document.Money) string {
+	if m.IsZero() {
+		return ""
+	}
+	return m.Decimal()
+}
+
+func currencyValue(m document.Money) string {
+	if m.Currency == "" {
+		return document.DefaultCurrency
 	}
+	return m.Currency
 }
@@ -1,20 +1,33 @@
 package web_test
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"golang.binggl.net/monorepo/internal/common/upload"
 	"golang.binggl.net/monorepo/internal/mydms"
 	"golang.binggl.net/monorepo/internal/mydms/app/config"
 	"golang.binggl.net/monorepo/internal/mydms/app/document"
 	"golang.binggl.net/monorepo/internal/mydms/app/filestore"
+	"golang.binggl.net/monorepo/internal/mydms/app/reminder"
+	"golang.binggl.net/monorepo/internal/mydms/app/rules"
 	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared/sqlitetest"
 	conf "golang.binggl.net/monorepo/pkg/config"
 	"golang.binggl.net/monorepo/pkg/logging"
 )
@@ -29,20 +42,22 @@ const validToken = "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.eyJleHAiOjE4MjcyMDE0NDU
 // MOCK: filestore.FileService
 // --------------------------------------------------------------------------
 
-// rather small PDF payload
+// rather small PDF payload, the offsets of the xref table are valid for the page operations
 // https://stackoverflow.com/questions/17279712/what-is-the-smallest-possible-valid-pdf
 const pdfPayload = `%PDF-1.0
-1 0 obj<</Type/Catalog/Pages 2 0 R>>endobj 2 0 obj<</Type/Pages/Kids[3 0 R]/Count 1>>endobj 3 0 obj<</Type/Page/MediaBox[0 0 3 3]>>endobj
+1 0 obj<</Type/Catalog/Pages 2 0 R>>endobj
+2 0 obj<</Type/Pages/Kids[3 0 R]/Count 1>>endobj
+3 0 obj<</Type/Page/Parent 2 0 R/MediaBox[0 0 3 3]>>endobj
 xref
 0 4
 0000000000 65535 f
-0000000010 00000 n
-0000000053 00000 n
-0000000102 00000 n
+0000000009 00000 n
+0000000052 00000 n
+0000000101 00000 n
 trailer<</Size 4/Root 1 0 R>>
 startxref
-149
-%EOF
+160
+%%EOF
 `
 
 type mockFileService struct {
@@ -66,12 +81,12 @@ func (m *mockFileService) InitClient() (err error) {
 	return m.errMap[m.callCount]
 }
 
-func (m *mockFileService) SaveFile(file filestore.FileItem) error {
+func (m *mockFileService) SaveFile(ctx context.Context, file filestore.FileItem) error {
 	m.callCount++
 	return m.errMap[m.callCount]
 }
 
-func (m *mockFileService) GetFile(filePath string) (filestore.FileItem, error) {
+func (m *mockFileService) GetFile(ctx context.Context, filePath string) (filestore.FileItem, error) {
 	m.callCount++
 	return filestore.FileItem{
 		FileName:   "test.pdf",
@@ -81,28 +96,76 @@ func (m *mockFileService) GetFile(filePath string) (filestore.FileItem, error) {
 	}, m.errMap[m.callCount]
 }
 
-func (m *mockFileService) DeleteFile(filePath string) error {
+func (m *mockFileService) StreamFile(ctx context.Context, filePath string, w io.Writer) (filestore.FileItem, error) {
+	item, err := m.GetFile(ctx, filePath)
+	if err != nil {
+		return filestore.FileItem{}, err
+	}
+	_, err = w.Write(item.Payload)
+	item.Payload = nil
+	return item, err
+}
+
+func (m *mockFileService) DeleteFile(ctx context.Context, filePath string) error {
 	m.callCount++
 	return m.errMap[m.callCount]
 }
 
+func (m *mockFileService) CheckBucket(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockFileService) ListFiles(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func (m *mockFileService) GetFileInfo(ctx context.Context, filePath string) (filestore.FileItem, error) {
+	return filestore.FileItem{}, nil
+}
+
+func (m *mockFileService) UpdateMetadata(ctx context.Context, filePath string, metadata map[string]string) error {
+	return nil
+}
+
 var logger = logging.NewNop()
 
 func handler(repo document.Repository) http.Handler {
+	return handlerWithRules(repo, nil)
+}
+
+// handlerWithRules uses the given rules to classify new documents
+func handlerWithRules(repo document.Repository, ruleSvc rules.Service) http.Handler {
+	return handlerWithServices(repo, ruleSvc, nil)
+}
+
+// handlerWithReminders creates the reminder service in the database of the documents
+func handlerWithReminders(t *testing.T, repo document.Repository, con shared.Connection) http.Handler {
+	reminderRepo, err := reminder.NewRepository(con)
+	if err != nil {
+		t.Fatalf("could not create the repository: %v", err)
+	}
+	docSvc := document.NewService(logger, repo, nil, nil, nil, nil)
+	return handlerWithServices(repo, nil, reminder.NewService(logger, reminderRepo, docSvc, nil, ""))
+}
 
+func handlerWithServices(repo document.Repository, ruleSvc rules.Service, reminderSvc reminder.Service) http.Handler {
 	fileStore := newFileService()
 	uploadStore := upload.NewStore("/tmp")
 	uploadSvc := upload.NewService(upload.ServiceOptions{
-		Logger: logger,
-		Store:  uploadStore,
+		Logger:           logger,
+		Store:            uploadStore,
+		MaxUploadSize:    1 << 20,
+		AllowedFileTypes: []string{"pdf"},
 	})
 	svc := document.NewService(logger,
 		repo,
 		fileStore, /* filestore.FileService */
 		uploadSvc, /* upload.Service */
+		nil,       /* preview.Generator */
+		ruleSvc,   /* document.Classifier */
 	)
 
-	return mydms.MakeHTTPHandler(svc, uploadSvc, fileStore, logger, mydms.HTTPHandlerOptions{
+	return mydms.MakeHTTPHandler(repo, svc, uploadSvc, fileStore, ruleSvc, reminderSvc, logger, mydms.HTTPHandlerOptions{
 		BasePath:  "./",
 		ErrorPath: "/error",
 		Config: config.AppConfig{
@@ -127,14 +190,21 @@ func handler(repo document.Repository) http.Handler {
 					AssetPrefix: "/public",
 				},
 			},
+			Upload: config.UploadSettings{
+				MaxUploadSize: 1 << 20,
+			},
+			Reminders: config.ReminderSettings{
+				BaseURL: "https://example.com/dms",
+			},
 		},
 		Version: "local",
 		Build:   "testing",
 	})
 }
 
+// memRepo creates a repository with the schema of mydms, the database is removed after the test
 func memRepo(t *testing.T) (document.Repository, shared.Connection) {
-	con := shared.NewConnForSqlite("file::memory:")
+	con := sqlitetest.NewConn(t)
 	repo, err := document.NewRepository(con)
 	if err != nil {
 		t.Fatalf("cannot establish database connection: %v", err)
@@ -196,6 +266,48 @@ func Test_Document_List(t *testing.T) {
 	}
 }
 
+func Test_Document_Query(t *testing.T) {
+	repo, con := memRepo(t)
+	defer con.Close()
+
+	for i, title := range []string{"small invoice", "large invoice"} {
+		_, err := repo.Save(context.TODO(), document.DocEntity{
+			Title:       title,
+			FileName:    "invoice.pdf",
+			AmountMinor: sql.NullInt64{Int64: int64(i+1) * 1000, Valid: true},
+			Currency:    sql.NullString{String: "EUR", Valid: true},
+			TagList:     "tax",
+		}, shared.Atomic{})
+		if err != nil {
+			t.Fatalf("could not save a document: %v", err)
+		}
+	}
+
+	r := handler(repo)
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/mydms?q="+url.QueryEscape("tag:tax amount:>15 author:me"), nil)
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// the invalid fragment is highlighted and ignored by the search
+	payload := rec.Body.String()
+	assert.Contains(t, payload, `<mark class="query_error"`)
+	assert.Contains(t, payload, "author:me</mark>")
+	assert.Contains(t, payload, "large invoice")
+	assert.NotContains(t, payload, "small invoice")
+
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/mydms?q="+url.QueryEscape("-large"), nil)
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	payload = rec.Body.String()
+	assert.NotContains(t, payload, "<mark")
+	assert.Contains(t, payload, "small invoice")
+	assert.NotContains(t, payload, "large invoice")
+}
+
 func Test_Document_Partial(t *testing.T) {
 	repo, con := memRepo(t)
 	defer con.Close()
@@ -291,8 +403,13 @@ func Test_ShowEditDocumentDialog(t *testing.T) {
 }
 
 func Test_SearchListItemsForAutocomplete(t *testing.T) {
-	repo, con := memRepo(t)
+	// the database has no schema, the lists cannot be read
+	con := shared.NewConnForSqlite("file::memory:")
 	defer con.Close()
+	repo, err := document.NewRepository(con)
+	if err != nil {
+		t.Fatalf("cannot establish database connection: %v", err)
+	}
 
 	r := handler(repo)
 	rec := httptest.NewRecorder()
@@ -325,3 +442,833 @@ func Test_SearchListItemsForAutocomplete(t *testing.T) {
 	// }
 
 }
+
+func Test_Reports(t *testing.T) {
+	repo, con := memRepo(t)
+	defer con.Close()
+
+	for _, amount := range []int64{1050, 2025} {
+		_, err := repo.Save(context.TODO(), document.DocEntity{
+			Title:       "invoice",
+			FileName:    "invoice.pdf",
+			AmountMinor: sql.NullInt64{Int64: amount, Valid: true},
+			Currency:    sql.NullString{String: "EUR", Valid: true},
+			SenderList:  "Office",
+			TagList:     "tax",
+		}, shared.Atomic{})
+		if err != nil {
+			t.Fatalf("could not save a document: %v", err)
+		}
+	}
+
+	r := handler(repo)
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/mydms/reports?sender=office&from=2000-01-01", nil)
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	payload := rec.Body.String()
+	assert.Contains(t, payload, "30.75 EUR")
+	assert.Contains(t, payload, "2 documents")
+	assert.Contains(t, payload, "Office")
+
+	// no documents of the sender
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/mydms/reports?sender=shop", nil)
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "No amounts available!")
+
+	// the invalid fragments of the search are highlighted
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/mydms/reports?q="+url.QueryEscape("amount:>ten"), nil)
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "amount:&gt;ten</mark>")
+}
+
+func Test_Export(t *testing.T) {
+	repo, con := memRepo(t)
+	defer con.Close()
+
+	_, err := repo.Save(context.TODO(), document.DocEntity{
+		Title:       "invoice",
+		FileName:    "/2024_01_01/invoice.pdf",
+		AmountMinor: sql.NullInt64{Int64: 1050, Valid: true},
+		Currency:    sql.NullString{String: "EUR", Valid: true},
+		SenderList:  "Office",
+	}, shared.Atomic{})
+	if err != nil {
+		t.Fatalf("could not save a document: %v", err)
+	}
+
+	r := handler(repo)
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/mydms/export?format=csv&q=invoice", nil)
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/csv")
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), ".csv")
+	assert.Contains(t, rec.Body.String(), "invoice,Office,,10.50,EUR")
+
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/mydms/export?format=zip", nil)
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/zip", rec.Header().Get("Content-Type"))
+
+	z, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	assert.NoError(t, err)
+	assert.Len(t, z.File, 2)
+
+	// the archive is not truncated by the timeout of the request
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequestWithContext(ctx, "GET", "/mydms/export?format=zip", nil)
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	z, err = zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	assert.NoError(t, err)
+	assert.Len(t, z.File, 2)
+
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/mydms/export?format=pdf", nil)
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func Test_ReviewQueue(t *testing.T) {
+	repo, con := memRepo(t)
+	defer con.Close()
+
+	for _, title := range []string{"scanned letter", "reviewed invoice"} {
+		_, err := repo.Save(context.TODO(), document.DocEntity{
+			Title:       title,
+			FileName:    title + ".pdf",
+			NeedsReview: title == "scanned letter",
+		}, shared.Atomic{})
+		if err != nil {
+			t.Fatalf("could not save a document: %v", err)
+		}
+	}
+
+	r := handler(repo)
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/mydms/review", nil)
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	payload := rec.Body.String()
+	assert.Contains(t, payload, "scanned letter")
+	assert.Contains(t, payload, "needs review")
+	assert.NotContains(t, payload, "reviewed invoice")
+
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/mydms/review/partial/list", nil)
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Currently showing 1 results of total 1")
+
+	// the documents page links to the review queue
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/mydms", nil)
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `href="/mydms/review"`)
+}
+
+func Test_Rules(t *testing.T) {
+	repo, con := memRepo(t)
+	defer con.Close()
+
+	for _, title := range []string{"power invoice", "letter"} {
+		_, err := repo.Save(context.TODO(), document.DocEntity{
+			Title:    title,
+			FileName: title + ".pdf",
+		}, shared.Atomic{})
+		if err != nil {
+			t.Fatalf("could not save a document: %v", err)
+		}
+	}
+	ruleRepo, err := rules.NewRepository(con)
+	if err != nil {
+		t.Fatalf("could not create the rule repository: %v", err)
+	}
+	r := handlerWithRules(repo, rules.NewService(logger, ruleRepo, newFileService(), rules.NewTextExtractor("")))
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/mydms/rules", nil)
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Create Rule")
+	// the form is not sent by htmx and needs the CSRF token
+	assert.Contains(t, rec.Body.String(), `name="_csrf"`)
+
+	form := url.Values{}
+	form.Set("rule-name", "Energy")
+	form.Set("rule-enabled", "on")
+	form.Set("rule-field-0", "title")
+	form.Set("rule-operator-0", "contains")
+	form.Set("rule-value-0", "power")
+	form.Set("rule-tags", "energy, invoice")
+	form.Set("rule-currency", "EUR")
+
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/mydms/rules/test", strings.NewReader(form.Encode()))
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	payload := rec.Body.String()
+	assert.Contains(t, payload, "1 of 2 documents match")
+	assert.Contains(t, payload, "energy, invoice")
+
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/mydms/rules", strings.NewReader(form.Encode()))
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusSeeOther, rec.Code)
+	location := rec.Header().Get("Location")
+	assert.True(t, strings.HasPrefix(location, "/mydms/rules/"))
+
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", location, nil)
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Edit: Energy")
+
+	// the rule needs a name
+	form.Set("rule-name", "")
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/mydms/rules", strings.NewReader(form.Encode()))
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "a name is required")
+
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", location, nil)
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "/mydms/rules", rec.Header().Get("HX-Redirect"))
+}
+
+func Test_Maintenance(t *testing.T) {
+	// the merge reads the documents outside of the transaction
+	repo, con := memRepo(t)
+	defer con.Close()
+
+	var ids []string
+	for _, title := range []string{"invoice", "invoice copy"} {
+		doc, err := repo.Save(context.TODO(), document.DocEntity{
+			Title:    title,
+			FileName: title + ".pdf",
+		}, shared.Atomic{})
+		if err != nil {
+			t.Fatalf("could not save a document: %v", err)
+		}
+		ids = append(ids, doc.ID)
+	}
+	r := handler(repo)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/mydms/maintenance", nil)
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "No duplicates found!")
+	assert.Contains(t, rec.Body.String(), `name="_csrf"`)
+
+	// the files of the mock are identical
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/mydms/maintenance/hashes", nil)
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusSeeOther, rec.Code)
+	location := rec.Header().Get("Location")
+	assert.Equal(t, "/mydms/maintenance?computed=2&failed=0", location)
+
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", location, nil)
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	payload := rec.Body.String()
+	assert.Contains(t, payload, "Computed 2 content hash(es)")
+	assert.Contains(t, payload, "2 identical files")
+	assert.Contains(t, payload, "invoice copy")
+
+	form := url.Values{}
+	form.Set("keep", ids[0])
+	form["id"] = append(ids, "unknown")
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/mydms/maintenance/merge", strings.NewReader(form.Encode()))
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Cannot merge the duplicates")
+	assert.Empty(t, rec.Header().Get("HX-Redirect"))
+
+	form["id"] = ids
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/mydms/maintenance/merge", strings.NewReader(form.Encode()))
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "/mydms/maintenance?merged=1", rec.Header().Get("HX-Redirect"))
+}
+
+func Test_ListItems(t *testing.T) {
+	repo, con := memRepo(t)
+	defer con.Close()
+
+	for _, tags := range []string{"Telekom;phone", "Deutsche Telekom"} {
+		_, err := repo.Save(context.TODO(), document.DocEntity{
+			Title:    "invoice",
+			FileName: "invoice.pdf",
+			TagList:  tags,
+		}, shared.Atomic{})
+		if err != nil {
+			t.Fatalf("could not save a document: %v", err)
+		}
+	}
+	tags, err := repo.ListItems(context.TODO(), document.TAGS)
+	if err != nil {
+		t.Fatalf("could not get the tags: %v", err)
+	}
+	r := handler(repo)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/mydms/lists/tags", nil)
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	payload := rec.Body.String()
+	assert.Contains(t, payload, "Deutsche Telekom")
+	assert.Contains(t, payload, "/mydms/lists/tags/"+tags[0].ID)
+
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/mydms/lists/unknown", nil)
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusFound, rec.Code)
+
+	// the name is used by another tag
+	form := url.Values{}
+	form.Set("name-"+tags[2].ID, "deutsche telekom")
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/mydms/lists/tags/"+tags[2].ID, strings.NewReader(form.Encode()))
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "merge the entries instead")
+	assert.Empty(t, rec.Header().Get("HX-Redirect"))
+
+	form = url.Values{}
+	form.Set("target", tags[0].ID)
+	form["id"] = []string{tags[2].ID}
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/mydms/lists/tags/merge", strings.NewReader(form.Encode()))
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "/mydms/lists/tags?done=merged", rec.Header().Get("HX-Redirect"))
+
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/mydms/lists/tags?done=merged", nil)
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "The entries were merged.")
+
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/mydms/lists/tags/"+tags[1].ID, nil)
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "/mydms/lists/tags?done=deleted", rec.Header().Get("HX-Redirect"))
+
+	tags, err = repo.ListItems(context.TODO(), document.TAGS)
+	assert.NoError(t, err)
+	assert.Len(t, tags, 1)
+	assert.Equal(t, 2, tags[0].Documents)
+}
+
+func Test_BulkActions(t *testing.T) {
+	repo, con := memRepo(t)
+	defer con.Close()
+
+	var ids []string
+	for _, title := range []string{"invoice", "letter"} {
+		doc, err := repo.Save(context.TODO(), document.DocEntity{
+			Title:      title,
+			FileName:   title + ".pdf",
+			TagList:    title,
+			SenderList: "Office",
+		}, shared.Atomic{})
+		if err != nil {
+			t.Fatalf("could not save a document: %v", err)
+		}
+		ids = append(ids, doc.ID)
+	}
+	r := handler(repo)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/mydms", nil)
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `form="bulk_form"`)
+
+	bulk := func(action, value string, ids ...string) *httptest.ResponseRecorder {
+		form := url.Values{}
+		form.Set("action", action)
+		form.Set("value", value)
+		form["id"] = ids
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/mydms/bulk", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		addJwtAuth(req)
+		r.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		return rec
+	}
+
+	rec = bulk("add_tags", "tax, 2024", ids...)
+	assert.Contains(t, rec.Header().Get("HX-Trigger"), "2 document(s) changed")
+	assert.Contains(t, rec.Header().Get("HX-Trigger"), "refreshDocumentList")
+	doc, err := repo.Get(context.TODO(), ids[0], shared.Atomic{})
+	assert.NoError(t, err)
+	assert.Equal(t, "invoice;tax;2024", doc.TagList)
+
+	// the unknown document is reported by the summary
+	rec = bulk("set_sender", "Tax Office, Inc.", ids[1], "unknown")
+	assert.Contains(t, rec.Header().Get("HX-Trigger"), "1 document(s) changed, 1 failed")
+	doc, err = repo.Get(context.TODO(), ids[1], shared.Atomic{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Tax Office, Inc.", doc.SenderList)
+
+	rec = bulk("add_tags", "", ids...)
+	assert.Contains(t, rec.Header().Get("HX-Trigger"), "Bulk action failed!")
+
+	form := url.Values{}
+	form["id"] = []string{ids[0], "unknown"}
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/mydms/bulk/zip", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/zip", rec.Header().Get("Content-Type"))
+	z, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	assert.NoError(t, err)
+	assert.Len(t, z.File, 2)
+
+	rec = bulk("delete", "", ids...)
+	assert.Contains(t, rec.Header().Get("HX-Trigger"), "2 document(s) changed")
+	_, err = repo.Get(context.TODO(), ids[0], shared.Atomic{})
+	assert.Error(t, err)
+}
+
+func Test_Upcoming(t *testing.T) {
+	repo, con := memRepo(t)
+	defer con.Close()
+
+	due := func(day string) sql.NullTime {
+		d, _ := time.Parse(document.DateLayout, day)
+		return sql.NullTime{Time: d, Valid: true}
+	}
+	invoice, err := repo.Save(context.TODO(), document.DocEntity{
+		Title:    "invoice",
+		FileName: "invoice.pdf",
+		TagList:  "invoice",
+		DueDate:  due("2024-03-15"),
+		DueType:  sql.NullString{String: string(document.DuePayment), Valid: true},
+	}, shared.Atomic{})
+	if err != nil {
+		t.Fatalf("could not save a document: %v", err)
+	}
+	r := handlerWithReminders(t, repo, con)
+
+	get := func(path string, auth bool) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		if auth {
+			addJwtAuth(req)
+		}
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/mydms/upcoming", true)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, "invoice")
+	assert.Contains(t, body, `class="table-danger"`)
+	assert.Contains(t, body, "Mark paid")
+	// inline event handlers are blocked by the CSP
+	assert.NotContains(t, body, "onclick")
+	token := regexp.MustCompile(`/mydms/calendar/([0-9a-f]{64})\.ics`).FindStringSubmatch(body)
+	if !assert.Len(t, token, 2) {
+		return
+	}
+
+	// the feed is available without a session
+	rec = get("/mydms/calendar/"+token[1]+".ics", false)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, reminder.CalendarContentType, rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "DTSTART;VALUE=DATE:20240315\r\n")
+	rec = get("/mydms/calendar/unknown.ics", false)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/mydms/upcoming/"+invoice.ID+"/done", nil)
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, "/mydms/upcoming", rec.Header().Get("HX-Redirect"))
+	assert.Contains(t, get("/mydms/upcoming", true).Body.String(), "Nothing is due!")
+	assert.Contains(t, get("/mydms/upcoming?done=true", true).Body.String(), "Reopen")
+
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/mydms/upcoming/unknown/done", nil)
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Contains(t, rec.Header().Get("HX-Trigger"), "Document not updated!")
+
+	form := url.Values{}
+	form.Set("notify", "true")
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/mydms/upcoming/notify", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Contains(t, rec.Header().Get("HX-Trigger"), "The reminders are sent to 'user@a.com'.")
+
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/mydms/upcoming/feed", nil)
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, "/mydms/upcoming", rec.Header().Get("HX-Redirect"))
+	assert.Equal(t, http.StatusNotFound, get("/mydms/calendar/"+token[1]+".ics", false).Code)
+
+	// the dates of the edit dialog are validated
+	form = url.Values{}
+	form.Set("doc-title", "invoice")
+	form.Set("doc-tags[]", "invoice")
+	form.Set("doc-senders[]", "Office")
+	form.Set("doc-tempID", "-")
+	form.Set("doc-filename", "invoice.pdf")
+	form.Set("doc-duedate", "2024-03-15")
+	form.Set("doc-reminderdate", "2024-03-20")
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/mydms", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	addJwtAuth(req)
+	r.ServeHTTP(rec, req)
+	assert.Contains(t, rec.Body.String(), "The reminder needs to be before the due date")
+}
+
+func Test_Pages(t *testing.T) {
+	repo, con := memRepo(t)
+	defer con.Close()
+
+	var ids []string
+	for _, title := range []string{"scan", "letter"} {
+		doc, err := repo.Save(context.TODO(), document.DocEntity{
+			Title:    title,
+			FileName: "/2024_01_01/" + title + ".pdf",
+			TagList:  title,
+		}, shared.Atomic{})
+		if err != nil {
+			t.Fatalf("could not save a document: %v", err)
+		}
+		ids = append(ids, doc.ID)
+	}
+	r := handler(repo)
+
+	send := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		addJwtAuth(req)
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+	post := func(path string, form url.Values) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return send(req)
+	}
+	get := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		return send(req)
+	}
+
+	assert.Contains(t, get("/mydms").Body.String(), `href="/mydms/pages/`+ids[0]+`"`)
+	rec := get("/mydms/pages/" + ids[0])
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "1 page(s), revision 1")
+	assert.Contains(t, rec.Body.String(), document.OriginalRevision)
+	rec = get("/mydms/pages/unknown")
+	assert.Equal(t, http.StatusFound, rec.Code)
+
+	rec = post("/mydms/pages/"+ids[0], url.Values{"remove": {"1"}})
+	assert.Contains(t, rec.Header().Get("HX-Trigger"), "all pages would be removed")
+	rec = post("/mydms/pages/"+ids[0], url.Values{"rotate": {"1"}, "degrees": {"90"}})
+	assert.Equal(t, "/mydms/pages/"+ids[0]+"?changed=true", rec.Header().Get("HX-Redirect"))
+	doc, err := repo.Get(context.TODO(), ids[0], shared.Atomic{})
+	assert.NoError(t, err)
+	assert.Regexp(t, `/scan_rev2_[0-9a-f]{8}\.pdf$`, doc.FileName)
+	body := get("/mydms/pages/" + ids[0] + "?changed=true").Body.String()
+	assert.Contains(t, body, "The pages were saved as a new revision.")
+	assert.Contains(t, body, "rotated pages 1 by 90°")
+
+	rec = post("/mydms/pages/"+ids[0]+"/split", url.Values{"ranges": {"1"}})
+	assert.Equal(t, "/mydms/pages/"+ids[0]+"?split=1", rec.Header().Get("HX-Redirect"))
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	part, _ := mw.CreateFormFile("pages-upload", "back.pdf")
+	part.Write([]byte(pdfPayload))
+	mw.Close()
+	req, _ := http.NewRequest("POST", "/mydms/pages/"+ids[0]+"/append", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec = send(req)
+	assert.Equal(t, "/mydms/pages/"+ids[0]+"?changed=true", rec.Header().Get("HX-Redirect"))
+	assert.Contains(t, get("/mydms/pages/"+ids[0]).Body.String(), "appended back.pdf")
+	req, _ = http.NewRequest("POST", "/mydms/pages/"+ids[0]+"/append", nil)
+	rec = send(req)
+	assert.Contains(t, rec.Header().Get("HX-Trigger"), "Pages not appended!")
+
+	// the selected documents are merged into a new document
+	form := url.Values{"action": {"merge"}, "value": {"combined"}, "id": ids}
+	rec = post("/mydms/bulk", form)
+	assert.Contains(t, rec.Header().Get("HX-Trigger"), "The new document 'combined' was created from 2 documents.")
+	assert.Contains(t, rec.Header().Get("HX-Trigger"), "refreshDocumentList")
+	rec = post("/mydms/bulk", url.Values{"action": {"merge"}, "id": {ids[0]}})
+	assert.Contains(t, rec.Header().Get("HX-Trigger"), "Merge failed!")
+}
+
+// Test_API_Specification compares the routes of the API with the paths of the specification
+func Test_API_Specification(t *testing.T) {
+	repo, con := memRepo(t)
+	defer con.Close()
+	r := handler(repo)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/openapi.json", nil)
+	r.ServeHTTP(rec, req)
+	var spec struct {
+		Paths map[string]map[string]any `json:"paths"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("could not decode the specification: %v", err)
+	}
+	specified := map[string]bool{}
+	for path, ops := range spec.Paths {
+		for method := range ops {
+			if method != "parameters" {
+				specified[strings.ToUpper(method)+" "+path] = true
+			}
+		}
+	}
+
+	routes, ok := r.(chi.Routes)
+	if !ok {
+		t.Fatalf("the handler does not provide the routes")
+	}
+	mounted := map[string]bool{}
+	err := chi.Walk(routes, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		path, found := strings.CutPrefix(route, "/api/v1")
+		if found && path != "/openapi.json" {
+			mounted[method+" "+path] = true
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("could not walk the routes: %v", err)
+	}
+	assert.NotEmpty(t, mounted)
+	assert.Equal(t, specified, mounted)
+}
+
+func Test_API(t *testing.T) {
+	repo, con := memRepo(t)
+	defer con.Close()
+	r := handler(repo)
+
+	send := func(method, path string, body io.Reader, contentType string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, body)
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		req.Header.Set("Accept", "application/json")
+		addJwtAuth(req)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+	create := func(meta string) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		mw.WriteField("document", meta)
+		part, _ := mw.CreateFormFile("file", "invoice.pdf")
+		part.Write([]byte(pdfPayload))
+		mw.Close()
+		return send("POST", "/api/v1/documents", &buf, mw.FormDataContentType())
+	}
+	decode := func(rec *httptest.ResponseRecorder, v any) {
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("could not decode the response '%s': %v", rec.Body.String(), err)
+		}
+	}
+
+	// the specification is public
+	req, _ := http.NewRequest("GET", "/api/v1/openapi.json", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var spec struct {
+		OpenAPI string                    `json:"openapi"`
+		Paths   map[string]map[string]any `json:"paths"`
+	}
+	decode(rec, &spec)
+	assert.Equal(t, "3.0.3", spec.OpenAPI)
+	assert.Contains(t, spec.Paths, "/documents")
+	assert.Contains(t, spec.Paths["/documents/{id}"], "put")
+	assert.Contains(t, spec.Paths, "/documents/{id}/file")
+
+	rec = create(`{"title":"invoice","tags":["bill"],"senders":["shop"],"amount":{"value":"12.50","currency":"EUR"}}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var doc document.Document
+	decode(rec, &doc)
+	assert.NotEmpty(t, doc.ID)
+	assert.Equal(t, "https://example.com/dms/api/v1/documents/"+doc.ID, rec.Header().Get("Location"))
+	assert.Equal(t, "12.50", doc.Amount.Decimal())
+
+	rec = create(`{"title":"invoice","senders":["shop"]}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "application/problem+json")
+	assert.Contains(t, rec.Body.String(), "at least one tag")
+	rec = create(`not json`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	// the size of the request is limited
+	var large bytes.Buffer
+	mw := multipart.NewWriter(&large)
+	part, _ := mw.CreateFormFile("file", "large.pdf")
+	part.Write(bytes.Repeat([]byte("a"), 3<<20))
+	mw.Close()
+	rec = send("POST", "/api/v1/documents", &large, mw.FormDataContentType())
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "request body too large")
+
+	rec = send("PUT", "/api/v1/documents/"+doc.ID, strings.NewReader(`{"title":"another","tags":["bill"],"senders":["shop"],"fileName":"/other.pdf"}`), "application/json")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var updated document.Document
+	decode(rec, &updated)
+	assert.Equal(t, "another", updated.Title)
+	assert.Equal(t, doc.FileName, updated.FileName)
+	rec = send("PUT", "/api/v1/documents/unknown", strings.NewReader(`{"title":"another","tags":["bill"],"senders":["shop"]}`), "application/json")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = send("GET", "/api/v1/documents/"+doc.ID, nil, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	decode(rec, &updated)
+	assert.Equal(t, "another", updated.Title)
+	rec = send("GET", "/api/v1/documents/unknown", nil, "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "application/problem+json")
+
+	rec = send("GET", "/api/v1/documents/"+doc.ID+"/file", nil, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/pdf", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "attachment")
+	assert.Equal(t, pdfPayload, rec.Body.String())
+
+	// search with ordering and paging
+	rec = create(`{"title":"contract","tags":["contract"],"senders":["office"]}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var paged document.PagedDocument
+	decode(send("GET", "/api/v1/documents?orderBy=title:desc", nil, ""), &paged)
+	assert.Equal(t, 2, paged.TotalEntries)
+	assert.Equal(t, "another", paged.Documents[1].Title)
+	decode(send("GET", "/api/v1/documents?orderBy=title&limit=1&skip=1", nil, ""), &paged)
+	assert.Len(t, paged.Documents, 1)
+	assert.Equal(t, "contract", paged.Documents[0].Title)
+	decode(send("GET", "/api/v1/documents?q=tag:contract", nil, ""), &paged)
+	assert.Equal(t, 1, paged.TotalEntries)
+	decode(send("GET", "/api/v1/documents?sender=nobody", nil, ""), &paged)
+	assert.Equal(t, 0, paged.TotalEntries)
+	assert.NotNil(t, paged.Documents)
+
+	for _, query := range []string{"orderBy=filename", "orderBy=title:up", "limit=500", "skip=x", "from=01.01.2024", "needsReview=maybe", "q=color:red"} {
+		rec = send("GET", "/api/v1/documents?"+query, nil, "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+
+	rec = send("DELETE", "/api/v1/documents/"+doc.ID, nil, "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = send("DELETE", "/api/v1/documents/"+doc.ID, nil, "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// requests without a token are rejected
+	req, _ = http.NewRequest("GET", "/api/v1/documents", nil)
+	req.Header.Set("Accept", "application/json")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func Test_WebDAV(t *testing.T) {
+	repo, con := memRepo(t)
+	defer con.Close()
+	r := handler(repo)
+
+	send := func(method, path, body string, auth func(*http.Request)) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Depth", "1")
+		auth(req)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+	basicAuth := func(req *http.Request) { req.SetBasicAuth("user", validToken) }
+
+	rec := send("PROPFIND", "/mydms/dav/", "", func(*http.Request) {})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Header().Get("WWW-Authenticate"), "Basic")
+	rec = send("PROPFIND", "/mydms/dav/", "", func(req *http.Request) { req.SetBasicAuth("user", "invalid") })
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// the cookie of the session is not used, the WebDAV paths are not protected against CSRF
+	rec = send("PROPFIND", "/mydms/dav/", "", func(req *http.Request) { req.AddCookie(&http.Cookie{Name: "jwt", Value: validToken}) })
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = send("PROPFIND", "/mydms/dav/", "", addJwtAuth)
+	assert.Equal(t, http.StatusMultiStatus, rec.Code)
+	rec = send("PROPFIND", "/mydms/dav/", "", basicAuth)
+	assert.Equal(t, http.StatusMultiStatus, rec.Code)
+	assert.Contains(t, rec.Body.String(), "<D:href>/mydms/dav/inbox/</D:href>")
+
+	// the CSRF protection does not apply to the WebDAV paths
+	rec = send("PUT", "/mydms/dav/inbox/scan.pdf", pdfPayload, basicAuth)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	rec = send("PROPFIND", "/mydms/dav/inbox/", "", basicAuth)
+	assert.Contains(t, rec.Body.String(), "<D:href>/mydms/dav/inbox/scan.pdf</D:href>")
+}
@@ -0,0 +1,159 @@
+package web
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.binggl.net/monorepo/internal/mydms/app/reminder"
+	"golang.binggl.net/monorepo/internal/mydms/app/shared"
+	"golang.binggl.net/monorepo/internal/mydms/html"
+	"golang.binggl.net/monorepo/pkg/handler"
+	base "golang.binggl.net/monorepo/pkg/handler/html"
+	"golang.binggl.net/monorepo/pkg/security"
+)
+
+// DisplayUpcoming lists the documents by their due date and shows the reminder settings of the user
+func (t *TemplateHandler) DisplayUpcoming() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := ensureUser(r)
+		showDone := queryParam(r, "done") == "true"
+		t.Logger.InfoRequest(fmt.Sprintf("display the upcoming documents for user: '%s'", user.Username), r)
+
+		docs, err := t.DocSvc.UpcomingDocuments(r.Context(), showDone)
+		if err != nil {
+			t.Logger.ErrorRequest(fmt.Sprintf("could not get the upcoming documents for user '%s'; '%v'", user.Username, err), r)
+		}
+		sub, err := t.ReminderSvc.Subscription(r.Context(), *user)
+		if err != nil {
+			t.Logger.ErrorRequest(fmt.Sprintf("could not get the subscription of user '%s'; '%v'", user.Username, err), r)
+		}
+
+		base.Layout(
+			t.pageModel(r, "Upcoming", "", "/public/mydms.svg", *user),
+			html.UpcomingStyles(),
+			html.UpcomingNavigation(),
+			html.UpcomingContent(docs, time.Now(), showDone, html.Subscription{
+				Subscriber:  sub,
+				FeedURL:     t.feedURL(r, sub.FeedToken),
+				MailEnabled: t.RemindersByMail,
+			}, security.NonceFromContext(r.Context())),
+			searchURL,
+		).Render(w)
+	}
+}
+
+// MarkDocumentDone marks the document as paid or done, the delete method reopens the document
+func (t *TemplateHandler) MarkDocumentDone() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := ensureUser(r)
+		id := pathParam(r, "id")
+		done := r.Method != http.MethodDelete
+		t.Logger.InfoRequest(fmt.Sprintf("mark the document '%s' as done '%t' for user: '%s'", id, done, user.Username), r)
+
+		if _, err := t.DocSvc.MarkDone(r.Context(), id, done); err != nil {
+			if !isClientError(err) {
+				t.Logger.ErrorRequest(fmt.Sprintf("could not mark the document '%s'; %v", id, err), r)
+			}
+			w.Header().Add("HX-Trigger", base.ErrorToast("Document not updated!", err.Error()))
+			return
+		}
+		redirect := "/mydms/upcoming"
+		if queryParam(r, "done") == "true" {
+			redirect += "?done=true"
+		}
+		// https://htmx.org/headers/hx-redirect/
+		w.Header().Add("HX-Redirect", redirect)
+	}
+}
+
+// RenewFeed replaces the address of the calendar feed of the user
+func (t *TemplateHandler) RenewFeed() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := ensureUser(r)
+		t.Logger.InfoRequest(fmt.Sprintf("renew the calendar feed of user: '%s'", user.Username), r)
+		if _, err := t.ReminderSvc.RenewFeed(r.Context(), *user); err != nil {
+			t.Logger.ErrorRequest(fmt.Sprintf("could not renew the calendar feed of user '%s'; %v", user.Username, err), r)
+			w.Header().Add("HX-Trigger", base.ErrorToast("Calendar feed not renewed!", err.Error()))
+			return
+		}
+		w.Header().Add("HX-Redirect", "/mydms/upcoming")
+	}
+}
+
+// SetReminderNotify enables the reminder mails if the switch of the form is on
+func (t *TemplateHandler) SetReminderNotify() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := ensureUser(r)
+		if err := r.ParseForm(); err != nil {
+			t.Logger.ErrorRequest(fmt.Sprintf("could not parse supplied form data; '%v'", err), r)
+			w.Header().Add("HX-Trigger", base.ErrorToast("Reminders not changed!", "could not parse supplied form data"))
+			return
+		}
+		notify := r.FormValue("notify") == "true"
+		t.Logger.InfoRequest(fmt.Sprintf("set the reminder mails to '%t' for user: '%s'", notify, user.Username), r)
+
+		sub, err := t.ReminderSvc.SetNotify(r.Context(), *user, notify)
+		if err != nil {
+			if !isClientError(err) {
+				t.Logger.ErrorRequest(fmt.Sprintf("could not change the reminder mails of user '%s'; %v", user.Username, err), r)
+			}
+			w.Header().Add("HX-Trigger", base.ErrorToast("Reminders not changed!", err.Error()))
+			return
+		}
+		text := "No reminder mails are sent."
+		if sub.Notify {
+			text = fmt.Sprintf("The reminders are sent to '%s'.", sub.Email)
+		}
+		w.Header().Add("HX-Trigger", handler.Json(triggerDef{
+			ToastMessage: base.ToastMessage{
+				Event: base.ToastMessageContent{
+					Type:  base.MsgSuccess,
+					Title: "Reminders changed!",
+					Text:  text,
+				},
+			},
+		}))
+	}
+}
+
+// Calendar provides the upcoming documents as iCalendar feed. The feed is requested by calendar
+// applications without a session, the token of the path identifies the user.
+func (t *TemplateHandler) Calendar() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		if err := t.ReminderSvc.Calendar(r.Context(), pathParam(r, "token"), &buf); err != nil {
+			var notFound *shared.NotFoundError
+			if errors.As(err, &notFound) {
+				t.Logger.Warn("the calendar feed was requested with an unknown token")
+				http.Error(w, "the calendar is not available", http.StatusNotFound)
+				return
+			}
+			t.Logger.ErrorRequest(fmt.Sprintf("could not create the calendar feed; %v", err), r)
+			http.Error(w, "could not create the calendar", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", reminder.CalendarContentType)
+		w.Header().Set("Content-Disposition", "inline; filename=mydms.ics")
+		w.Write(buf.Bytes())
+	}
+}
+
+// feedURL is the absolute address of the calendar feed
+func (t *TemplateHandler) feedURL(r *http.Request, token string) string {
+	if token == "" {
+		return ""
+	}
+	return publicURL(r, t.BaseURL, "/mydms/calendar/"+token+".ics")
+}
+
+// isClientError is true for errors caused by the supplied values, which are not logged as errors
+func isClientError(err error) bool {
+	var (
+		validation *shared.ValidationError
+		notFound   *shared.NotFoundError
+	)
+	return errors.As(err, &validation) || errors.As(err, &notFound)
+}
@@ -1,5 +1,12 @@
 package config
 
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
 // Environment specifies operation modes
 type Environment string
 
@@ -16,40 +23,145 @@ const (
 type BaseConfig struct {
 	Security    Security
 	Logging     LogConfig
+	Tracing     TraceConfig
+	RateLimit   RateLimitConfig
+	Headers     SecurityHeaders
 	Cors        CorsSettings
-	Environment Environment
+	Environment Environment `validate:"required,oneof=Development Production Integration"`
 	Cookies     ApplicationCookies
 	Assets      AssetSettings
-	AppName     string
+	Server      ServerSettings
+	AppName     string `validate:"required"`
 	HostID      string
 	ErrorPath   string
 }
 
 // Security settings for the application
 type Security struct {
-	JwtIssuer     string
-	JwtSecret     string
-	CookieName    string
-	LoginRedirect string
+	JwtIssuer     string `validate:"required"`
+	JwtSecret     string `validate:"required" secret:"true"`
+	CookieName    string `validate:"required"`
+	LoginRedirect string `validate:"required"`
 	Claim         Claim
-	CacheDuration string
+	CacheDuration string `validate:"duration"`
 }
 
 // Claim defines the required claims
 type Claim struct {
-	Name  string
-	URL   string
-	Roles []string
+	Name  string   `validate:"required"`
+	URL   string   `validate:"required,url"`
+	Roles []string `validate:"required"`
 }
 
 // LogConfig is used to define settings for the logging process
 type LogConfig struct {
 	FilePath string
-	LogLevel string
+	LogLevel string `validate:"oneof=debug info warning error"`
 	// GrayLogServer defines the address of a log-aggregator using Graylog
 	GrayLogServer string
 }
 
+// TraceConfig defines the OpenTelemetry settings used to export traces
+type TraceConfig struct {
+	Enabled bool
+	// Exporter is either "stdout" or "otlp"
+	Exporter string `validate:"oneof=stdout otlp"`
+	// Endpoint is the host:port of an OTLP/HTTP collector
+	Endpoint string
+	// Insecure uses plain http to reach the collector
+	Insecure bool
+	// SampleRatio defines the fraction of traces to sample (0..1)
+	SampleRatio float64 `validate:"min=0,max=1"`
+}
+
+// Validate checks the exporter settings if tracing is enabled
+func (t TraceConfig) Validate() []string {
+	var problems []string
+	if t.Enabled && t.Exporter == "" {
+		problems = append(problems, "an exporter is required if tracing is enabled")
+	}
+	if t.Enabled && t.Exporter == "otlp" && t.Endpoint == "" {
+		problems = append(problems, "the otlp exporter needs an endpoint")
+	}
+	return problems
+}
+
+// RateLimitConfig defines the token-bucket policies used to limit request rates
+type RateLimitConfig struct {
+	Enabled bool
+	// Default is used if no dedicated policy is defined for a route
+	Default RateLimitPolicy
+	// Policies are identified by name and referenced by the routes
+	Policies map[string]RateLimitPolicy
+	// TrustedProxies are IP addresses or CIDR ranges of reverse proxies. The client-ip is only
+	// taken from the forwarded headers of requests sent by these proxies.
+	TrustedProxies []string
+}
+
+// Validate checks the policies if rate-limiting is enabled, a rate or burst of 0 would block every client
+func (c RateLimitConfig) Validate() []string {
+	if !c.Enabled {
+		return nil
+	}
+	var problems []string
+	check := func(name string, p RateLimitPolicy) {
+		if p.Rate <= 0 || p.Burst <= 0 {
+			problems = append(problems, fmt.Sprintf("the policy '%s' needs a rate and a burst greater than 0", name))
+		}
+	}
+	check("default", c.Default)
+	for name, p := range c.Policies {
+		check(name, p)
+	}
+	if _, err := c.Proxies(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	return problems
+}
+
+// Proxies parses the TrustedProxies, a single address is a range of one address
+func (c RateLimitConfig) Proxies() ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, p := range c.TrustedProxies {
+		if strings.Contains(p, "/") {
+			prefix, err := netip.ParsePrefix(p)
+			if err != nil {
+				return nil, fmt.Errorf("'%s' is not a valid trusted proxy", p)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(p)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a valid trusted proxy", p)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return prefixes, nil
+}
+
+// RateLimitPolicy defines the parameters of a token-bucket
+type RateLimitPolicy struct {
+	// Rate is the number of tokens refilled per second
+	Rate float64 `validate:"min=0"`
+	// Burst is the maximum number of tokens available at once
+	Burst int `validate:"min=0"`
+}
+
+// SecurityHeaders defines the security related headers sent with every response.
+// Empty values use the defaults of the security package
+type SecurityHeaders struct {
+	// ContentSecurityPolicy may use the placeholder {nonce} for the per-request nonce
+	ContentSecurityPolicy string
+	// FrameAncestors defines the sources allowed to embed the pages, e.g. 'self' or 'none'
+	FrameAncestors string
+	// HSTSMaxAge in seconds; 0 omits the Strict-Transport-Security header
+	HSTSMaxAge            int `validate:"min=0"`
+	HSTSIncludeSubdomains bool
+	ReferrerPolicy        string
+	PermissionsPolicy     string
+}
+
 // CorsSettings specifies the used settings
 type CorsSettings struct {
 	Origins     []string
@@ -72,3 +184,43 @@ type AssetSettings struct {
 	AssetDir    string
 	AssetPrefix string
 }
+
+// ServerSettings define the listener and the timeouts of the http server
+type ServerSettings struct {
+	// TLS is used if the certificate and the key are set
+	TLS TLSSettings
+	// HTTP2 enables HTTP/2 for TLS connections
+	HTTP2 bool
+	// H2C enables HTTP/2 without TLS, e.g. behind a reverse-proxy
+	H2C bool
+	// UnixSocket is the path of a Unix domain socket used instead of hostname and port
+	UnixSocket string
+	// SocketMode defines the permissions of the socket file as an octal number, e.g. "0660"
+	SocketMode        string
+	ReadTimeout       string `validate:"duration"`
+	ReadHeaderTimeout string `validate:"duration"`
+	WriteTimeout      string `validate:"duration"`
+	IdleTimeout       string `validate:"duration"`
+}
+
+// Validate checks the combination of the TLS files and the socket permissions
+func (s ServerSettings) Validate() []string {
+	var problems []string
+	if (s.TLS.CertFile == "") != (s.TLS.KeyFile == "") {
+		problems = append(problems, "tls needs both the certfile and the keyfile")
+	}
+	if s.SocketMode != "" {
+		if _, err := strconv.ParseUint(s.SocketMode, 8, 32); err != nil {
+			problems = append(problems, fmt.Sprintf("'%s' is not a valid octal socketmode", s.SocketMode))
+		}
+	}
+	return problems
+}
+
+// TLSSettings define the certificate used for TLS connections
+type TLSSettings struct {
+	CertFile string
+	KeyFile  string
+	// ReloadInterval defines how often the files are checked for changes
+	ReloadInterval string `validate:"duration"`
+}
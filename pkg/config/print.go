@@ -0,0 +1,24 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+const maskedValue = "********"
+
+// Print writes the effective configuration as a list of key/value pairs.
+// The values of fields marked as secret are masked.
+func Print(w io.Writer, cfg any) {
+	walkConfig(reflect.ValueOf(cfg), "", func(path string, field reflect.StructField, value reflect.Value) {
+		display := fmt.Sprintf("%v", value.Interface())
+		if field.Tag.Get(secretTag) == "true" && !isEmpty(value) {
+			display = maskedValue
+		}
+		if value.Kind() == reflect.Map && value.Type().Elem().Kind() == reflect.Struct {
+			return
+		}
+		fmt.Fprintf(w, "%s = %s\n", path, display)
+	}, func(string, reflect.Value) {})
+}
@@ -0,0 +1,220 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The validation rules are declared by the struct-tag `validate` as a comma-separated list:
+//
+//	required     the value must not be empty (strings, slices, maps, numbers)
+//	oneof=a b c  a non-empty string has to be one of the given values
+//	duration     a non-empty string has to be a valid time.Duration
+//	url          a non-empty string has to be an absolute URL
+//	min=n, max=n numbers have to be within the given bounds
+//
+// Fields holding credentials are marked by the struct-tag `secret:"true"`,
+// the values are masked when the configuration is printed.
+const (
+	validateTag = "validate"
+	secretTag   = "secret"
+)
+
+// Validator is implemented by configuration types which need rules spanning several fields.
+// The returned problems are added to the report of Validate.
+type Validator interface {
+	Validate() []string
+}
+
+// ValidationError lists all problems found in a configuration
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("the configuration is invalid:\n  - %s", strings.Join(e.Problems, "\n  - "))
+}
+
+// Validate checks the given configuration using the declared rules and returns a
+// ValidationError listing every problem. The field-path uses the lower-case keys
+// also used for the configuration file and the environment variables.
+func Validate(cfg any) error {
+	var problems []string
+	walkConfig(reflect.ValueOf(cfg), "", func(path string, field reflect.StructField, value reflect.Value) {
+		problems = append(problems, validateField(path, field.Tag.Get(validateTag), value)...)
+	}, func(path string, value reflect.Value) {
+		if v, ok := value.Interface().(Validator); ok {
+			for _, p := range v.Validate() {
+				if path != "" {
+					p = fmt.Sprintf("%s: %s", path, p)
+				}
+				problems = append(problems, p)
+			}
+		}
+	})
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// walkConfig visits every leaf field of the configuration. Fields of embedded structs
+// which are shadowed by a field of the outer struct are not reachable and skipped.
+func walkConfig(v reflect.Value, path string, leaf func(string, reflect.StructField, reflect.Value), node func(string, reflect.Value)) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	node(path, v)
+	walkStruct(v, path, nil, leaf, node)
+}
+
+func walkStruct(v reflect.Value, path string, shadowed map[string]bool, leaf func(string, reflect.StructField, reflect.Value), node func(string, reflect.Value)) {
+	t := v.Type()
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		if !t.Field(i).Anonymous {
+			names[t.Field(i).Name] = true
+		}
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || shadowed[field.Name] {
+			continue
+		}
+		value := v.Field(i)
+		fieldPath := joinPath(path, strings.ToLower(field.Name))
+
+		switch {
+		case value.Kind() == reflect.Struct && !isLeaf(value):
+			if !field.Anonymous {
+				node(fieldPath, value)
+			}
+			var inner map[string]bool
+			if field.Anonymous {
+				inner = names
+			}
+			walkStruct(value, fieldPath, inner, leaf, node)
+		case value.Kind() == reflect.Map && value.Type().Elem().Kind() == reflect.Struct:
+			leaf(fieldPath, field, value)
+			iter := value.MapRange()
+			for iter.Next() {
+				entryPath := joinPath(fieldPath, fmt.Sprintf("%v", iter.Key()))
+				node(entryPath, iter.Value())
+				walkStruct(iter.Value(), entryPath, nil, leaf, node)
+			}
+		default:
+			leaf(fieldPath, field, value)
+		}
+	}
+}
+
+// types which are handled as a single value
+func isLeaf(v reflect.Value) bool {
+	return v.Type() == reflect.TypeOf(time.Time{})
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func validateField(path, tag string, value reflect.Value) []string {
+	if tag == "" {
+		return nil
+	}
+	var problems []string
+	for _, rule := range strings.Split(tag, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		if msg := checkRule(name, arg, value); msg != "" {
+			problems = append(problems, fmt.Sprintf("%s: %s", path, msg))
+		}
+	}
+	return problems
+}
+
+func checkRule(rule, arg string, value reflect.Value) string {
+	switch rule {
+	case "required":
+		if isEmpty(value) {
+			return "a value is required"
+		}
+	case "oneof":
+		s := value.String()
+		if value.Kind() != reflect.String || s == "" {
+			return ""
+		}
+		allowed := strings.Fields(arg)
+		for _, a := range allowed {
+			if s == a {
+				return ""
+			}
+		}
+		return fmt.Sprintf("'%s' is not one of [%s]", s, strings.Join(allowed, ", "))
+	case "duration":
+		if s := value.String(); value.Kind() == reflect.String && s != "" {
+			if _, err := time.ParseDuration(s); err != nil {
+				return fmt.Sprintf("'%s' is not a valid duration", s)
+			}
+		}
+	case "url":
+		if s := value.String(); value.Kind() == reflect.String && s != "" {
+			if u, err := url.Parse(s); err != nil || u.Scheme == "" || u.Host == "" {
+				return fmt.Sprintf("'%s' is not a valid URL", s)
+			}
+		}
+	case "min", "max":
+		bound, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return fmt.Sprintf("invalid rule '%s=%s'", rule, arg)
+		}
+		n, ok := number(value)
+		if !ok {
+			return ""
+		}
+		if rule == "min" && n < bound {
+			return fmt.Sprintf("%v is less than %v", n, bound)
+		}
+		if rule == "max" && n > bound {
+			return fmt.Sprintf("%v is greater than %v", n, bound)
+		}
+	default:
+		return fmt.Sprintf("unknown validation rule '%s'", rule)
+	}
+	return ""
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+func number(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
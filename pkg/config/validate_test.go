@@ -0,0 +1,121 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testAppConfig struct {
+	BaseConfig
+	Security testSecurity
+	Store    testStore
+}
+
+type testSecurity struct {
+	Secret string `validate:"required" secret:"true"`
+}
+
+type testStore struct {
+	URL     string `validate:"required,url"`
+	MaxSize int64  `validate:"min=1"`
+}
+
+func (s testStore) Validate() []string {
+	if s.MaxSize > 100 {
+		return []string{"the size is too large"}
+	}
+	return nil
+}
+
+func validBaseConfig() BaseConfig {
+	return BaseConfig{
+		AppName:     "app",
+		Environment: Development,
+		Logging:     LogConfig{LogLevel: "debug"},
+		Security: Security{
+			JwtIssuer:     "issuer",
+			JwtSecret:     "secret",
+			CookieName:    "cookie",
+			LoginRedirect: "/login",
+			CacheDuration: "10m",
+			Claim:         Claim{Name: "app", URL: "http://localhost", Roles: []string{"User"}},
+		},
+	}
+}
+
+func TestValidateBaseConfig(t *testing.T) {
+	cfg := validBaseConfig()
+	assert.NoError(t, Validate(cfg))
+
+	cfg.Environment = "Test"
+	cfg.Security.JwtSecret = ""
+	cfg.Security.CacheDuration = "ten minutes"
+	cfg.Security.Claim.URL = "localhost"
+	cfg.Security.Claim.Roles = nil
+	cfg.Tracing = TraceConfig{Enabled: true, Exporter: "otlp", SampleRatio: 2}
+	cfg.RateLimit = RateLimitConfig{
+		Enabled:        true,
+		Default:        RateLimitPolicy{Rate: 1, Burst: 10},
+		Policies:       map[string]RateLimitPolicy{"api": {Rate: -1}, "oidc": {Rate: 1}},
+		TrustedProxies: []string{"10.0.0.0/8", "proxy"},
+	}
+	cfg.Server = ServerSettings{TLS: TLSSettings{CertFile: "cert.pem"}, SocketMode: "rw", IdleTimeout: "2 minutes"}
+
+	err := Validate(&cfg)
+	var valErr *ValidationError
+	assert.True(t, errors.As(err, &valErr))
+	assert.ElementsMatch(t, []string{
+		"environment: 'Test' is not one of [Development, Production, Integration]",
+		"security.jwtsecret: a value is required",
+		"security.cacheduration: 'ten minutes' is not a valid duration",
+		"security.claim.url: 'localhost' is not a valid URL",
+		"security.claim.roles: a value is required",
+		"tracing: the otlp exporter needs an endpoint",
+		"tracing.sampleratio: 2 is greater than 1",
+		"ratelimit.policies.api.rate: -1 is less than 0",
+		"ratelimit: the policy 'api' needs a rate and a burst greater than 0",
+		"ratelimit: the policy 'oidc' needs a rate and a burst greater than 0",
+		"ratelimit: 'proxy' is not a valid trusted proxy",
+		"server: tls needs both the certfile and the keyfile",
+		"server: 'rw' is not a valid octal socketmode",
+		"server.idletimeout: '2 minutes' is not a valid duration",
+	}, valErr.Problems)
+}
+
+func TestValidateAppConfig(t *testing.T) {
+	// the security of the base-config is shadowed and therefore not validated
+	cfg := testAppConfig{
+		BaseConfig: BaseConfig{AppName: "app", Environment: Production},
+		Store:      testStore{URL: "http://localhost", MaxSize: 200},
+	}
+
+	err := Validate(cfg)
+	var valErr *ValidationError
+	assert.True(t, errors.As(err, &valErr))
+	assert.Equal(t, []string{
+		"security.secret: a value is required",
+		"store: the size is too large",
+	}, valErr.Problems)
+	assert.Contains(t, err.Error(), "  - store: the size is too large")
+}
+
+func TestPrintMasksSecrets(t *testing.T) {
+	cfg := testAppConfig{
+		BaseConfig: validBaseConfig(),
+		Security:   testSecurity{Secret: "--secret--"},
+		Store:      testStore{URL: "http://localhost", MaxSize: 10},
+	}
+	var buf bytes.Buffer
+	Print(&buf, cfg)
+	output := buf.String()
+
+	assert.NotContains(t, output, "--secret--")
+	assert.Contains(t, output, "security.secret = ********\n")
+	assert.Contains(t, output, "store.url = http://localhost\n")
+	assert.Contains(t, output, "baseconfig.appname = app\n")
+	// shadowed values are not printed
+	assert.NotContains(t, output, "baseconfig.security")
+}
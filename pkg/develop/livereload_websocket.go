@@ -29,8 +29,12 @@ const (
 var liveReloadClientJS string
 
 var (
-	newline            = []byte{'\n'}
-	PageReloadClientJS = "<script type=\"text/javascript\">" + fmt.Sprintf(liveReloadClientJS, reloadAddress) + "</script>"
+	newline = []byte{'\n'}
+	// PageReloadClientJS is the client logic connecting to the reload server. It is rendered as an inline
+	// script by the page layout, which needs to set the nonce of the Content-Security-Policy
+	PageReloadClientJS = fmt.Sprintf(liveReloadClientJS, reloadAddress)
+	// ReloadConnectSource needs to be allowed by the connect-src directive of the Content-Security-Policy
+	ReloadConnectSource = "ws://" + reloadAddress
 )
 
 // ReloadServer implements a websocket communication to implement a similar behavior like the "live-reload" package
@@ -6,7 +6,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 // --------------------------------------------------------------------------
@@ -82,6 +85,18 @@ func (e SecurityError) Error() string {
 	return fmt.Sprintf("the request '%s' is not allowed: %v", e.Request.RequestURI, e.Err)
 }
 
+// TooManyRequestsError is used when a client exceeds the allowed request rate
+type TooManyRequestsError struct {
+	Err        error
+	Request    *http.Request
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e TooManyRequestsError) Error() string {
+	return fmt.Sprintf("the request '%s' was rejected: %v", e.Request.RequestURI, e.Err)
+}
+
 // --------------------------------------------------------------------------
 // Shortcuts for common error responses
 // --------------------------------------------------------------------------
@@ -132,6 +147,16 @@ func ErrSecurityError(err SecurityError) *ProblemDetail {
 	}
 }
 
+// ErrTooManyRequests returns a http.StatusTooManyRequests
+func ErrTooManyRequests(err TooManyRequestsError) *ProblemDetail {
+	return &ProblemDetail{
+		Type:   t,
+		Title:  "too many requests",
+		Status: http.StatusTooManyRequests,
+		Detail: err.Error(),
+	}
+}
+
 // --------------------------------------------------------------------------
 // Error handling
 // --------------------------------------------------------------------------
@@ -161,6 +186,13 @@ func WriteError(w http.ResponseWriter, r *http.Request, err error) {
 		pd = ErrSecurityError(security)
 	}
 
+	if tooMany, ok := err.(TooManyRequestsError); ok {
+		pd = ErrTooManyRequests(tooMany)
+		if tooMany.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(tooMany.RetryAfter.Seconds()))))
+		}
+	}
+
 	status := http.StatusInternalServerError
 	if pd.Status > 0 {
 		status = pd.Status
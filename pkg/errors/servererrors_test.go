@@ -67,6 +67,12 @@ func TestErrorHandler(t *testing.T) {
 			Error:  SecurityError{Err: errors.New(errText), Request: errReq, Status: http.StatusUnauthorized},
 			Accept: "application/json",
 		},
+		{
+			Name:   "TooManyRequestsError",
+			Status: http.StatusTooManyRequests,
+			Error:  TooManyRequestsError{Err: errors.New(errText), Request: errReq},
+			Accept: "application/json",
+		},
 		{
 			Name:   "no-error",
 			Status: http.StatusOK,
@@ -24,6 +24,10 @@ type LayoutModel struct {
 	WindowY    int
 	Navigation []NavItem
 	Env        config.Environment
+	// CSRFToken is sent with every htmx request of the page
+	CSRFToken string
+	// Nonce of the Content-Security-Policy needed for inline scripts
+	Nonce string
 }
 
 type NavItem struct {
@@ -57,6 +61,27 @@ func cssItems(env config.Environment, commit string) []g.Node {
 
 }
 
+// csrfHeaders adds the token as a header to all htmx requests issued within the page
+func csrfHeaders(token string) g.Node {
+	if token == "" {
+		return nil
+	}
+	return g.Attr("hx-headers", fmt.Sprintf(`{"%s": "%s"}`, security.CSRFHeaderName, token))
+}
+
+// htmxConfig sets the nonce for inline scripts of content swapped by htmx
+func htmxConfig(nonce string) g.Node {
+	if nonce == "" {
+		return nil
+	}
+	return h.Meta(h.Name("htmx-config"), h.Content(fmt.Sprintf(`{"inlineScriptNonce": "%s"}`, nonce)))
+}
+
+// Script renders an inline script using the nonce of the page
+func Script(nonce, content string) g.Node {
+	return h.Script(h.Type("text/javascript"), g.If(nonce != "", g.Attr("nonce", nonce)), g.Raw(content))
+}
+
 // Layout provides the basic HTML layout for content pages.
 // The layout is currently based on bootstrap
 func Layout(model LayoutModel, style, navigation, content g.Node, searchURL string) g.Node {
@@ -65,6 +90,8 @@ func Layout(model LayoutModel, style, navigation, content g.Node, searchURL stri
 	headContent = append(headContent, []g.Node{
 		h.Base(h.Href("/")),
 		h.Meta(g.Attr("creator", "https://www.gomponents.com/")),
+		h.Meta(h.Name("csrf-token"), h.Content(model.CSRFToken)),
+		htmxConfig(model.Nonce),
 		// page icon definition
 		h.Link(h.Rel("icon"), h.Href(model.Favicon), g.Attr("size", "48x48")),
 		h.Link(h.Rel("shortcut icon"), h.ID("site-favicon"), h.Type("image/x-icon"), h.Href(model.Favicon)),
@@ -77,7 +104,7 @@ func Layout(model LayoutModel, style, navigation, content g.Node, searchURL stri
 		Language: "en",
 		Head:     headContent,
 		Body: []g.Node{
-			h.Body(g.Attr("data-bs-theme", "dark"),
+			h.Body(g.Attr("data-bs-theme", "dark"), csrfHeaders(model.CSRFToken),
 				h.Header(
 					h.Nav(h.Class("navbar navbar-expand-lg navbar-dark fixed-top header"),
 						h.Div(h.Class("container-fluid navbar-menu-area"),
@@ -140,7 +167,7 @@ func Layout(model LayoutModel, style, navigation, content g.Node, searchURL stri
 
 				// only during development the live-reload feature should be available
 				g.If(model.Env == config.Development,
-					g.Raw(develop.PageReloadClientJS),
+					Script(model.Nonce, develop.PageReloadClientJS),
 				),
 			),
 		},
@@ -0,0 +1,35 @@
+package html_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.binggl.net/monorepo/pkg/config"
+	"golang.binggl.net/monorepo/pkg/handler/html"
+	g "maragu.dev/gomponents"
+)
+
+func TestLayoutNonce(t *testing.T) {
+	var outBuffer bytes.Buffer
+	page := html.Layout(html.LayoutModel{
+		PageTitle: "title",
+		Env:       config.Development,
+		CSRFToken: "--token--",
+		Nonce:     "--nonce--",
+	}, g.Text(""), g.Text(""), html.Script("--nonce--", "console.log('content')"), "/search")
+	if err := page.Render(&outBuffer); err != nil {
+		t.Error(err)
+	}
+	output := outBuffer.String()
+
+	if !strings.Contains(output, `{&#34;inlineScriptNonce&#34;: &#34;--nonce--&#34;}`) {
+		t.Errorf("the htmx-config with the nonce is missing")
+	}
+	if strings.Count(output, `nonce="--nonce--"`) != 2 {
+		t.Errorf("the inline scripts of the content and the live-reload need the nonce")
+	}
+	if strings.Contains(output, "<script type=\"text/javascript\">") {
+		t.Errorf("inline scripts without a nonce are not allowed")
+	}
+}
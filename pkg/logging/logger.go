@@ -12,6 +12,7 @@ import (
 
 	gl "github.com/bihe/go-gelf/gelf" // this is the forked version of the library "gopkg.in/Graylog2/go-gelf.v2/gelf"
 	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"golang.binggl.net/monorepo/pkg/config"
@@ -210,21 +211,13 @@ func (l *zapLogger) Error(msg string, keyvals ...KeyValue) {
 
 func (l *zapLogger) InfoRequest(msg string, req *http.Request, keyvals ...KeyValue) {
 	kvs := l.appendKeys(keyvals...)
-	reqID := middleware.GetReqID(req.Context())
-	if reqID != "" {
-		kvs = append(kvs, RequestIDKey)
-		kvs = append(kvs, reqID)
-	}
+	kvs = append(kvs, requestKeys(req)...)
 	l.sugar.Infow(msg, kvs...)
 }
 
 func (l *zapLogger) ErrorRequest(msg string, req *http.Request, keyvals ...KeyValue) {
 	kvs := l.appendKeys(keyvals...)
-	reqID := middleware.GetReqID(req.Context())
-	if reqID != "" {
-		kvs = append(kvs, RequestIDKey)
-		kvs = append(kvs, reqID)
-	}
+	kvs = append(kvs, requestKeys(req)...)
 	l.sugar.Errorw(msg, kvs...)
 }
 
@@ -243,6 +236,22 @@ func (l *zapLogger) appendKeys(keyvals ...KeyValue) []interface{} {
 	return kvs
 }
 
+// requestKeys returns the request-id and the trace/span-id of the given request
+// so that log-messages can be correlated with traces
+func requestKeys(req *http.Request) []interface{} {
+	var kvs []interface{}
+	reqID := middleware.GetReqID(req.Context())
+	if reqID != "" {
+		kvs = append(kvs, RequestIDKey, reqID)
+	}
+	sc := trace.SpanContextFromContext(req.Context())
+	if sc.IsValid() {
+		kvs = append(kvs, TraceIDKey, sc.TraceID().String())
+		kvs = append(kvs, SpanIDKey, sc.SpanID().String())
+	}
+	return kvs
+}
+
 // add common keys to log-messages
 func (l *zapLogger) stdKeys() []interface{} {
 	var kvs []interface{}
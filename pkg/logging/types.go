@@ -7,6 +7,12 @@ const (
 	// RequestIDKey identifies a reqest in structured logging
 	RequestIDKey = "requestID"
 
+	// TraceIDKey holds the OpenTelemetry trace-id of a request
+	TraceIDKey = "traceID"
+
+	// SpanIDKey holds the OpenTelemetry span-id of a request
+	SpanIDKey = "spanID"
+
 	// ApplicationNameKey identifies the application in structured logging
 	ApplicationNameKey = "appName"
 
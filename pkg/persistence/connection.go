@@ -1,6 +1,7 @@
 package persistence
 
 import (
+	"context"
 	"fmt"
 
 	"gorm.io/gorm"
@@ -45,10 +46,16 @@ func (c Connection) W() *gorm.DB {
 
 // Begin starts a transaction and executes the provided handle function in a transaction context
 func (c Connection) Begin(handle func(c Connection) error) error {
+	return c.BeginContext(context.Background(), handle)
+}
+
+// BeginContext starts a transaction bound to the given context, a cancelled context rolls back
+// the transaction. The handle function is executed in the transaction context.
+func (c Connection) BeginContext(ctx context.Context, handle func(c Connection) error) error {
 	if c.Tx != nil {
 		return fmt.Errorf("a transaction is already available, will not start a new one")
 	}
-	return c.Write.Transaction(func(tx *gorm.DB) error {
+	return c.Write.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		return handle(Connection{
 			Read:  c.Read,
 			Write: c.Write,
@@ -0,0 +1,184 @@
+package security
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.binggl.net/monorepo/pkg/errors"
+	"golang.binggl.net/monorepo/pkg/logging"
+)
+
+const (
+	// CSRFHeaderName is the request header used to send the CSRF token
+	CSRFHeaderName = "X-CSRF-Token"
+	// CSRFFormField is the form field used to send the CSRF token if no header can be set
+	CSRFFormField = "_csrf"
+
+	csrfTokenLength = 32
+)
+
+// CSRFOptions define the cookie holding the CSRF token and the trusted origins
+type CSRFOptions struct {
+	CookieName string
+	Domain     string
+	Path       string
+	Secure     bool
+	// TrustedOrigins are origins (scheme://host[:port]) other than the request host
+	// which are allowed to perform state-changing requests
+	TrustedOrigins []string
+	// ExemptPaths are path prefixes which are not protected, the requests need to be
+	// authenticated by the Authorization header, e.g. the requests of WebDAV clients
+	ExemptPaths []string
+}
+
+type csrfKey int
+
+var csrfTokenKey csrfKey
+
+// CSRFTokenFromContext returns the CSRF token of the current request
+func CSRFTokenFromContext(ctx context.Context) string {
+	token, _ := ctx.Value(csrfTokenKey).(string)
+	return token
+}
+
+// NewCSRFMiddleware creates a middleware using the double-submit cookie pattern.
+// A random token is stored in a cookie and made available to the rendered page via
+// CSRFTokenFromContext; unsafe requests need to send the same token in the header
+// CSRFHeaderName or the form field CSRFFormField.
+func NewCSRFMiddleware(opts CSRFOptions, logger logging.Logger) *CSRFMiddleware {
+	if opts.CookieName == "" {
+		opts.CookieName = "csrf"
+	}
+	if opts.Path == "" {
+		opts.Path = "/"
+	}
+	trusted := make(map[string]bool)
+	for _, o := range opts.TrustedOrigins {
+		trusted[strings.ToLower(strings.TrimSuffix(o, "/"))] = true
+	}
+	return &CSRFMiddleware{opts: opts, trusted: trusted, logger: logger}
+}
+
+// CSRFMiddleware protects state-changing requests against cross-site request forgery
+type CSRFMiddleware struct {
+	opts    CSRFOptions
+	trusted map[string]bool
+	logger  logging.Logger
+}
+
+// Handler is the middleware function validating unsafe requests
+func (c *CSRFMiddleware) Handler(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if c.exempt(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cookieToken := ""
+		if cookie, err := r.Cookie(c.opts.CookieName); err == nil && validCSRFToken(cookie.Value) {
+			cookieToken = cookie.Value
+		}
+
+		if !safeMethod(r.Method) {
+			if err := c.validate(r, cookieToken); err != nil {
+				c.logger.InfoRequest(fmt.Sprintf("CSRF validation failed: %v", err), r)
+				errors.WriteError(w, r, errors.SecurityError{
+					Err:     err,
+					Request: r,
+					Status:  http.StatusForbidden,
+				})
+				return
+			}
+		}
+
+		token := cookieToken
+		if token == "" {
+			token = newCSRFToken()
+			http.SetCookie(w, &http.Cookie{
+				Name:     c.opts.CookieName,
+				Value:    token,
+				Path:     c.opts.Path,
+				Domain:   c.opts.Domain,
+				Secure:   c.opts.Secure,
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), csrfTokenKey, token)))
+	}
+	return http.HandlerFunc(fn)
+}
+
+func (c *CSRFMiddleware) validate(r *http.Request, cookieToken string) error {
+	// browsers cannot add the Authorization header for cross-site requests on their own;
+	// API clients using a bearer token are not affected by CSRF
+	if strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		return nil
+	}
+	// browsers send the fetch metadata, requests from other sites are rejected
+	if r.Header.Get("Sec-Fetch-Site") == "cross-site" {
+		return fmt.Errorf("cross-site request")
+	}
+	if origin := r.Header.Get("Origin"); origin != "" && !c.allowedOrigin(origin, r) {
+		return fmt.Errorf("origin '%s' is not allowed", origin)
+	}
+
+	if cookieToken == "" {
+		return fmt.Errorf("no CSRF cookie available")
+	}
+	token := r.Header.Get(CSRFHeaderName)
+	if token == "" {
+		token = r.PostFormValue(CSRFFormField)
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(cookieToken)) != 1 {
+		return fmt.Errorf("invalid CSRF token")
+	}
+	return nil
+}
+
+func (c *CSRFMiddleware) exempt(path string) bool {
+	for _, p := range c.opts.ExemptPaths {
+		if path == p || strings.HasPrefix(path, strings.TrimSuffix(p, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CSRFMiddleware) allowedOrigin(origin string, r *http.Request) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	return c.trusted[strings.ToLower(strings.TrimSuffix(origin, "/"))]
+}
+
+func safeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+func newCSRFToken() string {
+	b := make([]byte, csrfTokenLength)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("could not create random CSRF token: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func validCSRFToken(token string) bool {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	return err == nil && len(b) == csrfTokenLength
+}
@@ -0,0 +1,154 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.binggl.net/monorepo/pkg/logging"
+)
+
+func csrfHandler() (http.Handler, *string) {
+	var token string
+	mw := NewCSRFMiddleware(CSRFOptions{
+		CookieName:     "test_csrf",
+		TrustedOrigins: []string{"https://trusted.example.com"},
+	}, logging.NewNop())
+	return mw.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token = CSRFTokenFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})), &token
+}
+
+func TestCSRFIssueToken(t *testing.T) {
+	h, token := csrfHandler()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEmpty(t, *token)
+
+	cookies := rec.Result().Cookies()
+	assert.Len(t, cookies, 1)
+	assert.Equal(t, "test_csrf", cookies[0].Name)
+	assert.Equal(t, *token, cookies[0].Value)
+	assert.True(t, cookies[0].HttpOnly)
+
+	// an existing token is re-used
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookies[0])
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Len(t, rec.Result().Cookies(), 0)
+	assert.Equal(t, cookies[0].Value, *token)
+}
+
+func TestCSRFValidation(t *testing.T) {
+	h, _ := csrfHandler()
+	token := newCSRFToken()
+
+	cases := map[string]struct {
+		method  string
+		headers map[string]string
+		cookie  bool
+		form    string
+		status  int
+	}{
+		"valid header": {
+			method:  http.MethodPost,
+			headers: map[string]string{CSRFHeaderName: token, "Origin": "http://example.com", "Sec-Fetch-Site": "same-origin"},
+			cookie:  true,
+			status:  http.StatusOK,
+		},
+		"valid form field": {
+			method:  http.MethodPost,
+			headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			form:    CSRFFormField + "=" + token,
+			cookie:  true,
+			status:  http.StatusOK,
+		},
+		"trusted origin": {
+			method:  http.MethodPut,
+			headers: map[string]string{CSRFHeaderName: token, "Origin": "https://trusted.example.com"},
+			cookie:  true,
+			status:  http.StatusOK,
+		},
+		"missing token": {
+			method: http.MethodDelete,
+			cookie: true,
+			status: http.StatusForbidden,
+		},
+		"invalid token": {
+			method:  http.MethodPost,
+			headers: map[string]string{CSRFHeaderName: newCSRFToken()},
+			cookie:  true,
+			status:  http.StatusForbidden,
+		},
+		"missing cookie": {
+			method:  http.MethodPost,
+			headers: map[string]string{CSRFHeaderName: token},
+			status:  http.StatusForbidden,
+		},
+		"cross-site origin": {
+			method:  http.MethodPost,
+			headers: map[string]string{CSRFHeaderName: token, "Origin": "https://evil.example.com"},
+			cookie:  true,
+			status:  http.StatusForbidden,
+		},
+		"cross-site fetch metadata": {
+			method:  http.MethodPost,
+			headers: map[string]string{CSRFHeaderName: token, "Sec-Fetch-Site": "cross-site"},
+			cookie:  true,
+			status:  http.StatusForbidden,
+		},
+		"bearer token": {
+			method:  http.MethodPost,
+			headers: map[string]string{"Authorization": "Bearer token"},
+			status:  http.StatusOK,
+		},
+		"safe method": {
+			method: http.MethodGet,
+			status: http.StatusOK,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "http://example.com/", strings.NewReader(tc.form))
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			if tc.cookie {
+				req.AddCookie(&http.Cookie{Name: "test_csrf", Value: token})
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			assert.Equal(t, tc.status, rec.Code)
+		})
+	}
+}
+
+func TestCSRFExemptPaths(t *testing.T) {
+	mw := NewCSRFMiddleware(CSRFOptions{
+		CookieName:  "test_csrf",
+		ExemptPaths: []string{"/dav"},
+	}, logging.NewNop())
+	h := mw.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for path, status := range map[string]int{
+		"/dav":          http.StatusOK,
+		"/dav/inbox/a":  http.StatusOK,
+		"/davinci":      http.StatusForbidden,
+		"/app/dav/file": http.StatusForbidden,
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "http://example.com"+path, nil))
+		assert.Equal(t, status, rec.Code, path)
+		// no token is issued for the exempt paths
+		assert.Empty(t, rec.Result().Cookies(), path)
+	}
+}
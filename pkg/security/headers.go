@@ -0,0 +1,140 @@
+package security
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// NoncePlaceholder is replaced by the per-request nonce in the Content-Security-Policy
+	NoncePlaceholder = "{nonce}"
+
+	// DefaultContentSecurityPolicy only allows resources of the application itself;
+	// inline scripts need the nonce of the request
+	DefaultContentSecurityPolicy = "default-src 'self'; script-src 'self' 'nonce-{nonce}'; style-src 'self' 'unsafe-inline'; img-src 'self' data: https:; object-src 'none'; base-uri 'self'; form-action 'self'"
+	// DefaultReferrerPolicy does not leak the path of the application to other origins
+	DefaultReferrerPolicy = "strict-origin-when-cross-origin"
+	// DefaultPermissionsPolicy disables browser features not needed by the applications
+	DefaultPermissionsPolicy = "camera=(), microphone=(), geolocation=(), payment=(), usb=(), clipboard-write=(self)"
+	// DefaultFrameAncestors prevents the pages to be embedded by other sites
+	DefaultFrameAncestors = "'self'"
+
+	// PayloadContentSecurityPolicy is used for user-provided content like documents or favicons.
+	// No script is executed and no further resources are loaded; object-src is needed
+	// for the built-in PDF viewer of browsers
+	PayloadContentSecurityPolicy = "default-src 'none'; img-src 'self' data:; style-src 'unsafe-inline'; object-src 'self'; frame-ancestors 'self'"
+
+	nonceLength = 16
+)
+
+// HeaderOptions define the security headers sent with every response
+type HeaderOptions struct {
+	// ContentSecurityPolicy may contain the NoncePlaceholder which is replaced for each request
+	ContentSecurityPolicy string
+	// FrameAncestors is added as the frame-ancestors directive of the CSP
+	FrameAncestors string
+	// HSTSMaxAge in seconds; the Strict-Transport-Security header is omitted if not set
+	HSTSMaxAge            int
+	HSTSIncludeSubdomains bool
+	ReferrerPolicy        string
+	PermissionsPolicy     string
+}
+
+type nonceKey int
+
+var cspNonceKey nonceKey
+
+// NonceFromContext returns the CSP nonce of the current request
+func NonceFromContext(ctx context.Context) string {
+	nonce, _ := ctx.Value(cspNonceKey).(string)
+	return nonce
+}
+
+// NewSecurityHeaders creates a middleware which adds the security headers to responses.
+// Options which are not set use the default values.
+func NewSecurityHeaders(opts HeaderOptions) *SecurityHeaders {
+	if opts.ContentSecurityPolicy == "" {
+		opts.ContentSecurityPolicy = DefaultContentSecurityPolicy
+	}
+	if opts.FrameAncestors == "" {
+		opts.FrameAncestors = DefaultFrameAncestors
+	}
+	if opts.ReferrerPolicy == "" {
+		opts.ReferrerPolicy = DefaultReferrerPolicy
+	}
+	if opts.PermissionsPolicy == "" {
+		opts.PermissionsPolicy = DefaultPermissionsPolicy
+	}
+
+	csp := strings.TrimSuffix(strings.TrimSpace(opts.ContentSecurityPolicy), ";")
+	if !strings.Contains(csp, "frame-ancestors") {
+		csp = fmt.Sprintf("%s; frame-ancestors %s", csp, opts.FrameAncestors)
+	}
+	hsts := ""
+	if opts.HSTSMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d", opts.HSTSMaxAge)
+		if opts.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+	frameOptions := ""
+	switch opts.FrameAncestors {
+	case "'none'":
+		frameOptions = "DENY"
+	case "'self'":
+		frameOptions = "SAMEORIGIN"
+	}
+	return &SecurityHeaders{
+		opts:         opts,
+		csp:          csp,
+		hsts:         hsts,
+		frameOptions: frameOptions,
+	}
+}
+
+// SecurityHeaders adds the Content-Security-Policy and related headers to responses
+type SecurityHeaders struct {
+	opts         HeaderOptions
+	csp          string
+	hsts         string
+	frameOptions string
+}
+
+// Handler is the middleware function setting the headers and the nonce of the request
+func (s *SecurityHeaders) Handler(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		nonce := newNonce()
+		header := w.Header()
+		header.Set("Content-Security-Policy", strings.ReplaceAll(s.csp, NoncePlaceholder, nonce))
+		header.Set("X-Content-Type-Options", "nosniff")
+		header.Set("Referrer-Policy", s.opts.ReferrerPolicy)
+		header.Set("Permissions-Policy", s.opts.PermissionsPolicy)
+		if s.frameOptions != "" {
+			header.Set("X-Frame-Options", s.frameOptions)
+		}
+		if s.hsts != "" {
+			header.Set("Strict-Transport-Security", s.hsts)
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), cspNonceKey, nonce)))
+	}
+	return http.HandlerFunc(fn)
+}
+
+// RestrictPayload replaces the Content-Security-Policy of the response with a policy
+// suitable for user-provided content, so that uploaded files cannot execute script
+func RestrictPayload(w http.ResponseWriter) {
+	w.Header().Set("Content-Security-Policy", PayloadContentSecurityPolicy)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+}
+
+func newNonce() string {
+	b := make([]byte, nonceLength)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("could not create random CSP nonce: %v", err))
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
@@ -0,0 +1,59 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	var nonce string
+	h := NewSecurityHeaders(HeaderOptions{HSTSMaxAge: 3600, HSTSIncludeSubdomains: true}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonce = NonceFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEmpty(t, nonce)
+
+	csp := rec.Header().Get("Content-Security-Policy")
+	assert.Contains(t, csp, "script-src 'self' 'nonce-"+nonce+"'")
+	assert.Contains(t, csp, "frame-ancestors 'self'")
+	assert.NotContains(t, csp, NoncePlaceholder)
+	assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "SAMEORIGIN", rec.Header().Get("X-Frame-Options"))
+	assert.Equal(t, DefaultReferrerPolicy, rec.Header().Get("Referrer-Policy"))
+	assert.Equal(t, DefaultPermissionsPolicy, rec.Header().Get("Permissions-Policy"))
+	assert.Equal(t, "max-age=3600; includeSubDomains", rec.Header().Get("Strict-Transport-Security"))
+
+	// every request gets a new nonce
+	first := nonce
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.NotEqual(t, first, nonce)
+}
+
+func TestSecurityHeadersCustomPolicy(t *testing.T) {
+	h := NewSecurityHeaders(HeaderOptions{
+		ContentSecurityPolicy: "default-src 'none'; script-src 'nonce-{nonce}';",
+		FrameAncestors:        "'none'",
+	}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		RestrictPayload(w)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	// the payload policy replaces the policy of the application
+	assert.Equal(t, PayloadContentSecurityPolicy, rec.Header().Get("Content-Security-Policy"))
+	assert.Equal(t, "DENY", rec.Header().Get("X-Frame-Options"))
+	assert.Empty(t, rec.Header().Get("Strict-Transport-Security"))
+
+	s := NewSecurityHeaders(HeaderOptions{ContentSecurityPolicy: "default-src 'none'; script-src 'nonce-{nonce}';", FrameAncestors: "'none'"})
+	assert.True(t, strings.HasSuffix(s.csp, "script-src 'nonce-{nonce}'; frame-ancestors 'none'"))
+}
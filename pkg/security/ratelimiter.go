@@ -0,0 +1,196 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.binggl.net/monorepo/pkg/errors"
+	"golang.binggl.net/monorepo/pkg/logging"
+)
+
+// RateLimitOptions define the token-bucket used by the RateLimiter
+type RateLimitOptions struct {
+	// Name of the policy, used for logging
+	Name string
+	// Rate is the number of tokens refilled per second
+	Rate float64
+	// Burst is the maximum number of tokens available at once
+	Burst int
+	// TrustedProxies are the addresses of reverse proxies, the client-ip is only taken from
+	// the forwarded headers of requests sent by one of these proxies
+	TrustedProxies []netip.Prefix
+}
+
+// idle buckets are removed after this duration
+const bucketIdleTime = 10 * time.Minute
+
+// NewRateLimiter creates a RateLimiter using a token-bucket per client
+func NewRateLimiter(opts RateLimitOptions, logger logging.Logger) *RateLimiter {
+	if opts.Burst < 1 {
+		opts.Burst = 1
+	}
+	return &RateLimiter{
+		opts:    opts,
+		logger:  logger,
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// --------------------------------------------------------------------------
+// RateLimiter limiting requests per user or client-ip
+// --------------------------------------------------------------------------
+
+// RateLimiter implements a token-bucket per client. A client is identified by the
+// authenticated user or by the client-ip if no user is available
+type RateLimiter struct {
+	sync.Mutex
+	opts        RateLimitOptions
+	logger      logging.Logger
+	buckets     map[string]*bucket
+	lastCleanup time.Time
+	now         func() time.Time
+}
+
+// Allow takes a token of the bucket identified by key. If no token is available
+// the duration until the next token is available is returned
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+
+	now := l.now()
+	l.cleanup(now)
+
+	burst := float64(l.opts.Burst)
+	b, found := l.buckets[key]
+	if !found {
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[key] = b
+	} else {
+		b.tokens = min(burst, b.tokens+now.Sub(b.last).Seconds()*l.opts.Rate)
+		b.last = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	if l.opts.Rate <= 0 {
+		return false, 0
+	}
+	return false, time.Duration((1 - b.tokens) / l.opts.Rate * float64(time.Second))
+}
+
+// Handler is the middleware function which rejects requests exceeding the rate
+func (l *RateLimiter) Handler(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		key := rateLimitKey(r, l.opts.TrustedProxies)
+		if ok, retry := l.Allow(key); !ok {
+			l.logger.InfoRequest(fmt.Sprintf("rate limit '%s' exceeded for '%s'", l.opts.Name, key), r)
+			errors.WriteError(w, r, errors.TooManyRequestsError{
+				Err:        fmt.Errorf("rate limit exceeded"),
+				Request:    r,
+				RetryAfter: retry,
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+	return http.HandlerFunc(fn)
+}
+
+// remove buckets which were not used for some time and are refilled anyway
+func (l *RateLimiter) cleanup(now time.Time) {
+	if now.Sub(l.lastCleanup) < bucketIdleTime {
+		return
+	}
+	for k, b := range l.buckets {
+		if now.Sub(b.last) > bucketIdleTime {
+			delete(l.buckets, k)
+		}
+	}
+	l.lastCleanup = now
+}
+
+type peerKey int
+
+var peerAddrKey peerKey
+
+// PeerAddress keeps the address of the connection. It has to be used before middleware.RealIP,
+// which replaces the RemoteAddr by the forwarded headers of the request.
+func PeerAddress(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), peerAddrKey, r.RemoteAddr)))
+	}
+	return http.HandlerFunc(fn)
+}
+
+// rateLimitKey prefers the authenticated user and falls back to the client-ip. The forwarded
+// headers can be set by any client and are only used for requests of trusted proxies.
+func rateLimitKey(r *http.Request, trusted []netip.Prefix) string {
+	if user, ok := UserFromContext(r.Context()); ok && user != nil && user.Username != "" {
+		return "user:" + user.Username
+	}
+	remote, ok := r.Context().Value(peerAddrKey).(string)
+	if !ok {
+		remote = r.RemoteAddr
+	}
+	host, _, err := net.SplitHostPort(remote)
+	if err != nil {
+		host = remote
+	}
+	if isTrusted(host, trusted) {
+		host = forwardedFor(r, host, trusted)
+	}
+	return "ip:" + host
+}
+
+// forwardedFor returns the client-ip added by the trusted proxies. The addresses of the
+// X-Forwarded-For header are appended by each proxy, the last untrusted one is the client.
+func forwardedFor(r *http.Request, peer string, trusted []netip.Prefix) string {
+	var addrs []string
+	for _, h := range r.Header.Values("X-Forwarded-For") {
+		for a := range strings.SplitSeq(h, ",") {
+			addrs = append(addrs, strings.TrimSpace(a))
+		}
+	}
+	for i := len(addrs) - 1; i >= 0; i-- {
+		if !isTrusted(addrs[i], trusted) {
+			if _, err := netip.ParseAddr(addrs[i]); err == nil {
+				return addrs[i]
+			}
+			return peer
+		}
+	}
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); ip != "" {
+		if _, err := netip.ParseAddr(ip); err == nil {
+			return ip
+		}
+	}
+	return peer
+}
+
+func isTrusted(host string, trusted []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
@@ -0,0 +1,107 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"golang.binggl.net/monorepo/pkg/logging"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	now := time.Now()
+	l := NewRateLimiter(RateLimitOptions{Name: "test", Rate: 1, Burst: 2}, logging.NewNop())
+	l.now = func() time.Time { return now }
+
+	ok, _ := l.Allow("a")
+	assert.True(t, ok)
+	ok, _ = l.Allow("a")
+	assert.True(t, ok)
+	ok, retry := l.Allow("a")
+	assert.False(t, ok)
+	assert.Equal(t, time.Second, retry)
+
+	// other keys have their own bucket
+	ok, _ = l.Allow("b")
+	assert.True(t, ok)
+
+	// tokens are refilled over time
+	now = now.Add(1500 * time.Millisecond)
+	ok, _ = l.Allow("a")
+	assert.True(t, ok)
+	ok, _ = l.Allow("a")
+	assert.False(t, ok)
+
+	// idle buckets are removed
+	now = now.Add(2 * bucketIdleTime)
+	l.Allow("c")
+	assert.Len(t, l.buckets, 1)
+}
+
+func TestRateLimiterHandler(t *testing.T) {
+	l := NewRateLimiter(RateLimitOptions{Name: "test", Rate: 0.1, Burst: 1}, logging.NewNop())
+	h := l.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := func(remote string, user *User) int {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.RemoteAddr = remote
+		if user != nil {
+			r = r.WithContext(NewContext(r.Context(), user))
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		if rec.Code == http.StatusTooManyRequests {
+			assert.Equal(t, "10", rec.Header().Get("Retry-After"))
+			assert.Contains(t, rec.Header().Get("Content-Type"), "application/problem+json")
+		}
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, req("10.0.0.1:1234", nil))
+	assert.Equal(t, http.StatusTooManyRequests, req("10.0.0.1:4321", nil))
+	assert.Equal(t, http.StatusOK, req("10.0.0.2:1234", nil))
+
+	// an authenticated user is identified by the username, not the ip
+	user := &User{Username: "user"}
+	assert.Equal(t, http.StatusOK, req("10.0.0.1:1234", user))
+	assert.Equal(t, http.StatusTooManyRequests, req("10.0.0.3:1234", user))
+}
+
+func TestRateLimiterForwardedFor(t *testing.T) {
+	l := NewRateLimiter(RateLimitOptions{
+		Name:           "test",
+		Rate:           0.1,
+		Burst:          1,
+		TrustedProxies: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+	}, logging.NewNop())
+	// the peer address is kept before RealIP replaces the RemoteAddr
+	h := PeerAddress(middleware.RealIP(l.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))))
+
+	req := func(remote, forwarded string) int {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.RemoteAddr = remote
+		if forwarded != "" {
+			r.Header.Set("X-Forwarded-For", forwarded)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec.Code
+	}
+
+	// the forwarded headers of untrusted clients are ignored
+	assert.Equal(t, http.StatusOK, req("192.168.1.1:1234", "1.1.1.1"))
+	assert.Equal(t, http.StatusTooManyRequests, req("192.168.1.1:1234", "2.2.2.2"))
+
+	// the client-ip is taken from the headers of trusted proxies, spoofed entries are skipped
+	assert.Equal(t, http.StatusOK, req("10.0.0.1:1234", "3.3.3.3"))
+	assert.Equal(t, http.StatusTooManyRequests, req("10.0.0.2:1234", "4.4.4.4, 3.3.3.3"))
+	assert.Equal(t, http.StatusOK, req("10.0.0.1:1234", "5.5.5.5, 10.0.0.3"))
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.binggl.net/monorepo/pkg/handler"
+)
+
+const (
+	// LivenessPath is used to determine if the process is alive
+	LivenessPath = "/healthz"
+	// ReadinessPath is used to determine if the application is able to serve requests
+	ReadinessPath = "/readyz"
+
+	defaultCheckTimeout = 5 * time.Second
+)
+
+// CheckFunc verifies a single dependency of the application
+type CheckFunc func(ctx context.Context) error
+
+// HealthStatus is the result of a liveness or readiness request
+type HealthStatus struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Health tracks the readiness of an application by executing registered dependency checks.
+// Once the application is shutting down, the readiness is reported as failing.
+type Health struct {
+	Timeout time.Duration
+
+	mu       sync.RWMutex
+	checks   map[string]CheckFunc
+	stopping atomic.Bool
+}
+
+// NewHealth creates a Health instance without any checks
+func NewHealth() *Health {
+	return &Health{
+		Timeout: defaultCheckTimeout,
+		checks:  make(map[string]CheckFunc),
+	}
+}
+
+// Register adds a named check which is executed for readiness requests
+func (h *Health) Register(name string, check CheckFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks[name] = check
+}
+
+// Shutdown flags the application as stopping, readiness requests fail from now on
+func (h *Health) Shutdown() {
+	h.stopping.Store(true)
+}
+
+// Ready executes all registered checks and returns the result per check
+func (h *Health) Ready(ctx context.Context) (bool, map[string]string) {
+	h.mu.RLock()
+	names := make([]string, 0, len(h.checks))
+	for n := range h.checks {
+		names = append(names, n)
+	}
+	h.mu.RUnlock()
+	sort.Strings(names)
+
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = defaultCheckTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ready := true
+	result := make(map[string]string)
+	if h.stopping.Load() {
+		ready = false
+		result["shutdown"] = "application is shutting down"
+	}
+	for _, n := range names {
+		h.mu.RLock()
+		check := h.checks[n]
+		h.mu.RUnlock()
+		if err := check(ctx); err != nil {
+			ready = false
+			result[n] = err.Error()
+			continue
+		}
+		result[n] = "ok"
+	}
+	return ready, result
+}
+
+// Liveness returns ok as long as the process is able to handle requests
+func (h *Health) Liveness() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, http.StatusOK, HealthStatus{Status: "ok"})
+	}
+}
+
+// Readiness executes the registered checks and returns 503 if one of them fails
+func (h *Health) Readiness() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ready, checks := h.Ready(r.Context())
+		if !ready {
+			writeHealth(w, http.StatusServiceUnavailable, HealthStatus{Status: "unavailable", Checks: checks})
+			return
+		}
+		writeHealth(w, http.StatusOK, HealthStatus{Status: "ok", Checks: checks})
+	}
+}
+
+func writeHealth(w http.ResponseWriter, status int, h HealthStatus) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	w.Write([]byte(handler.Json(h)))
+}
+
+// --------------------------------------------------------------------------
+// common checks
+// --------------------------------------------------------------------------
+
+// SqliteCheck pings the database and verifies that a query can be executed.
+// The check only reads, it does not compete with the writers of the application.
+func SqliteCheck(db *sql.DB) CheckFunc {
+	return func(ctx context.Context) error {
+		if err := db.PingContext(ctx); err != nil {
+			return fmt.Errorf("database ping failed: %v", err)
+		}
+		var one int
+		if err := db.QueryRowContext(ctx, "SELECT 1").Scan(&one); err != nil {
+			return fmt.Errorf("database query failed: %v", err)
+		}
+		return nil
+	}
+}
+
+// WritableDirCheck verifies that files can be created in the given directory
+func WritableDirCheck(dir string) CheckFunc {
+	return func(ctx context.Context) error {
+		f, err := os.CreateTemp(dir, ".readyz-*")
+		if err != nil {
+			return fmt.Errorf("path '%s' is not writable: %v", dir, err)
+		}
+		name := f.Name()
+		f.Close()
+		return os.Remove(name)
+	}
+}
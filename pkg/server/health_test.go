@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.binggl.net/monorepo/pkg/persistence"
+)
+
+func readiness(t *testing.T, h http.Handler) (int, HealthStatus) {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, ReadinessPath, nil))
+	var status HealthStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("could not unmarshal health status: %v", err)
+	}
+	return rec.Code, status
+}
+
+func Test_Health_Endpoints(t *testing.T) {
+	db := persistence.MustCreateSqliteConn("file::memory:")
+	defer db.Close()
+	// the first connection initializes the driver, which is slow with the race detector;
+	// the connection is established before the checks and their timeout
+	if err := db.Ping(); err != nil {
+		t.Fatalf("could not connect to the database: %v", err)
+	}
+
+	app := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	health := NewHealth()
+	health.Register("database", SqliteCheck(db))
+	health.Register("upload", WritableDirCheck(t.TempDir()))
+	h := withHealth(health, app)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, LivenessPath, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	code, status := readiness(t, h)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", status.Checks["database"])
+	assert.Equal(t, "ok", status.Checks["upload"])
+
+	// requests are passed on to the application
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+
+	// readiness fails once the shutdown is started
+	health.Shutdown()
+	code, status = readiness(t, h)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "unavailable", status.Status)
+
+	// liveness is not affected
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, LivenessPath, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func Test_Health_FailingChecks(t *testing.T) {
+	db := persistence.MustCreateSqliteConn("file::memory:")
+	db.Close()
+
+	health := NewHealth()
+	health.Register("database", SqliteCheck(db))
+	health.Register("upload", WritableDirCheck(filepath.Join(t.TempDir(), "missing")))
+	health.Register("custom", func(ctx context.Context) error { return fmt.Errorf("error") })
+
+	code, status := readiness(t, withHealth(health, http.NotFoundHandler()))
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Len(t, status.Checks, 3)
+	assert.NotEqual(t, "ok", status.Checks["database"])
+	assert.NotEqual(t, "ok", status.Checks["upload"])
+	assert.Equal(t, "error", status.Checks["custom"])
+}
@@ -0,0 +1,252 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.binggl.net/monorepo/pkg/config"
+	"golang.binggl.net/monorepo/pkg/logging"
+)
+
+const (
+	defaultReadHeaderTimeout    = 10 * time.Second
+	defaultIdleTimeout          = 120 * time.Second
+	defaultCertReloadInterval   = time.Minute
+	defaultSocketMode           = os.FileMode(0660)
+	unixSocketAddressIdentifier = "unix:"
+)
+
+// ListenOptions define how the server accepts connections. Without TLS files and a
+// Unix socket the server listens on hostname:port using plain HTTP/1.1.
+type ListenOptions struct {
+	// CertFile and KeyFile enable TLS; changed files are loaded without a restart
+	CertFile           string
+	KeyFile            string
+	CertReloadInterval time.Duration
+	// HTTP2 is negotiated for TLS connections
+	HTTP2 bool
+	// H2C allows HTTP/2 without TLS, used behind a reverse-proxy
+	H2C bool
+	// UnixSocket is used instead of hostname:port
+	UnixSocket string
+	SocketMode os.FileMode
+	// timeouts of the http.Server, ReadHeaderTimeout and IdleTimeout have defaults
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+}
+
+// NewListenOptions creates the ListenOptions from the server settings of the configuration
+func NewListenOptions(cfg config.ServerSettings) (ListenOptions, error) {
+	opts := ListenOptions{
+		CertFile:   cfg.TLS.CertFile,
+		KeyFile:    cfg.TLS.KeyFile,
+		HTTP2:      cfg.HTTP2,
+		H2C:        cfg.H2C,
+		UnixSocket: cfg.UnixSocket,
+	}
+	if cfg.SocketMode != "" {
+		mode, err := strconv.ParseUint(cfg.SocketMode, 8, 32)
+		if err != nil {
+			return opts, fmt.Errorf("invalid socket mode '%s': %w", cfg.SocketMode, err)
+		}
+		opts.SocketMode = os.FileMode(mode)
+	}
+	durations := []struct {
+		value  string
+		target *time.Duration
+	}{
+		{cfg.TLS.ReloadInterval, &opts.CertReloadInterval},
+		{cfg.ReadTimeout, &opts.ReadTimeout},
+		{cfg.ReadHeaderTimeout, &opts.ReadHeaderTimeout},
+		{cfg.WriteTimeout, &opts.WriteTimeout},
+		{cfg.IdleTimeout, &opts.IdleTimeout},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.value)
+		if err != nil {
+			return opts, fmt.Errorf("invalid duration '%s': %w", d.value, err)
+		}
+		*d.target = v
+	}
+	return opts, nil
+}
+
+func (o ListenOptions) useTLS() bool {
+	return o.CertFile != "" && o.KeyFile != ""
+}
+
+// newHTTPServer creates the http.Server using the protocols and timeouts of the options
+func newHTTPServer(addr string, handler http.Handler, opts ListenOptions, logger logging.Logger) (*http.Server, error) {
+	readHeaderTimeout := opts.ReadHeaderTimeout
+	if readHeaderTimeout == 0 {
+		readHeaderTimeout = defaultReadHeaderTimeout
+	}
+	idleTimeout := opts.IdleTimeout
+	if idleTimeout == 0 {
+		idleTimeout = defaultIdleTimeout
+	}
+
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(opts.HTTP2)
+	protocols.SetUnencryptedHTTP2(opts.H2C)
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		Protocols:         &protocols,
+		ReadTimeout:       opts.ReadTimeout,
+		ReadHeaderTimeout: readHeaderTimeout,
+		WriteTimeout:      opts.WriteTimeout,
+		IdleTimeout:       idleTimeout,
+	}
+	if opts.useTLS() {
+		certs, err := newCertReloader(opts.CertFile, opts.KeyFile, opts.CertReloadInterval, logger)
+		if err != nil {
+			return nil, err
+		}
+		srv.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certs.GetCertificate,
+		}
+	}
+	return srv, nil
+}
+
+// listen creates the listener for the server and returns the address it is bound to
+func listen(addr string, opts ListenOptions) (net.Listener, string, error) {
+	if opts.UnixSocket == "" {
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			return nil, "", fmt.Errorf("could not listen on '%s': %w", addr, err)
+		}
+		return l, l.Addr().String(), nil
+	}
+
+	// a socket file left by a previous process prevents the listener from binding
+	if fi, err := os.Lstat(opts.UnixSocket); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, "", fmt.Errorf("the path '%s' exists and is not a socket", opts.UnixSocket)
+		}
+		if err := os.Remove(opts.UnixSocket); err != nil {
+			return nil, "", fmt.Errorf("could not remove the stale socket '%s': %w", opts.UnixSocket, err)
+		}
+	}
+	l, err := net.Listen("unix", opts.UnixSocket)
+	if err != nil {
+		return nil, "", fmt.Errorf("could not listen on socket '%s': %w", opts.UnixSocket, err)
+	}
+	mode := opts.SocketMode
+	if mode == 0 {
+		mode = defaultSocketMode
+	}
+	if err := os.Chmod(opts.UnixSocket, mode); err != nil {
+		l.Close()
+		return nil, "", fmt.Errorf("could not set the permissions of socket '%s': %w", opts.UnixSocket, err)
+	}
+	return l, unixSocketAddressIdentifier + opts.UnixSocket, nil
+}
+
+// serve accepts connections on the listener until the server is shut down
+func serve(srv *http.Server, l net.Listener) error {
+	if srv.TLSConfig != nil {
+		// the certificate is provided by the TLSConfig
+		return srv.ServeTLS(l, "", "")
+	}
+	return srv.Serve(l)
+}
+
+// certReloader provides the TLS certificate and loads it again if the files have changed.
+// The modification time is checked at most once per interval during the TLS handshake.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+	logger   logging.Logger
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+	checked time.Time
+}
+
+func newCertReloader(certFile, keyFile string, interval time.Duration, logger logging.Logger) (*certReloader, error) {
+	if interval == 0 {
+		interval = defaultCertReloadInterval
+	}
+	c := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: interval,
+		logger:   logger,
+	}
+	modTime, err := c.latestModTime()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.load(modTime); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// GetCertificate is used by the tls.Config to retrieve the current certificate
+func (c *certReloader) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Since(c.checked) < c.interval {
+		return c.cert, nil
+	}
+	c.checked = time.Now()
+	modTime, err := c.latestModTime()
+	if err != nil {
+		c.logger.Warn(fmt.Sprintf("could not check the TLS certificate, keep the current one: %v", err))
+		return c.cert, nil
+	}
+	if modTime.After(c.modTime) {
+		// files might be written one after the other; keep the current certificate until both match
+		if err := c.load(modTime); err != nil {
+			c.logger.Warn(fmt.Sprintf("could not reload the TLS certificate, keep the current one: %v", err))
+			return c.cert, nil
+		}
+		c.logger.Info(fmt.Sprintf("reloaded the TLS certificate '%s'", c.certFile))
+	}
+	return c.cert, nil
+}
+
+func (c *certReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return fmt.Errorf("could not load the TLS certificate: %w", err)
+	}
+	c.cert = &cert
+	c.modTime = modTime
+	c.checked = time.Now()
+	return nil
+}
+
+func (c *certReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, f := range []string{c.certFile, c.keyFile} {
+		fi, err := os.Stat(f)
+		if err != nil {
+			return latest, err
+		}
+		if fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+	}
+	return latest, nil
+}
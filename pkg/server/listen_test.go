@@ -0,0 +1,197 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.binggl.net/monorepo/pkg/config"
+	"golang.binggl.net/monorepo/pkg/logging"
+)
+
+func writeCert(t *testing.T, dir, commonName string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	assert.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return
+}
+
+func commonName(t *testing.T, cert *tls.Certificate) string {
+	c, err := x509.ParseCertificate(cert.Certificate[0])
+	assert.NoError(t, err)
+	return c.Subject.CommonName
+}
+
+// startServer serves a handler reporting the protocol of the request
+func startServer(t *testing.T, opts ListenOptions) string {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	})
+	srv, err := newHTTPServer("127.0.0.1:0", h, opts, logging.NewNop())
+	assert.NoError(t, err)
+	l, addr, err := listen(srv.Addr, opts)
+	assert.NoError(t, err)
+	go serve(srv, l)
+	t.Cleanup(func() { srv.Shutdown(context.Background()) })
+	return addr
+}
+
+func get(t *testing.T, client *http.Client, url string) (*http.Response, string) {
+	res, err := client.Get(url)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+	buf := make([]byte, 64)
+	n, _ := res.Body.Read(buf)
+	return res, string(buf[:n])
+}
+
+func TestNewListenOptions(t *testing.T) {
+	opts, err := NewListenOptions(config.ServerSettings{
+		TLS:          config.TLSSettings{CertFile: "cert.pem", KeyFile: "key.pem", ReloadInterval: "30s"},
+		HTTP2:        true,
+		UnixSocket:   "/run/app.sock",
+		SocketMode:   "0600",
+		ReadTimeout:  "1m",
+		WriteTimeout: "90s",
+	})
+	assert.NoError(t, err)
+	assert.True(t, opts.useTLS())
+	assert.Equal(t, 30*time.Second, opts.CertReloadInterval)
+	assert.Equal(t, os.FileMode(0600), opts.SocketMode)
+	assert.Equal(t, time.Minute, opts.ReadTimeout)
+	assert.Equal(t, 90*time.Second, opts.WriteTimeout)
+	assert.Equal(t, time.Duration(0), opts.IdleTimeout)
+
+	_, err = NewListenOptions(config.ServerSettings{SocketMode: "rw"})
+	assert.Error(t, err)
+	_, err = NewListenOptions(config.ServerSettings{IdleTimeout: "forever"})
+	assert.Error(t, err)
+}
+
+func TestServerTimeouts(t *testing.T) {
+	srv, err := newHTTPServer(":0", http.NotFoundHandler(), ListenOptions{WriteTimeout: time.Second}, logging.NewNop())
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second, srv.WriteTimeout)
+	assert.Equal(t, defaultReadHeaderTimeout, srv.ReadHeaderTimeout)
+	assert.Equal(t, defaultIdleTimeout, srv.IdleTimeout)
+	assert.Nil(t, srv.TLSConfig)
+}
+
+func TestUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "app.sock")
+	// a stale socket of a previous process is replaced
+	stale, err := net.Listen("unix", socket)
+	assert.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	addr := startServer(t, ListenOptions{UnixSocket: socket, SocketMode: 0600})
+	assert.Equal(t, "unix:"+socket, addr)
+
+	fi, err := os.Stat(socket)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	res, proto := get(t, client, "http://unix/")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "HTTP/1.1", proto)
+}
+
+func TestUnixSocketNoSocketFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	assert.NoError(t, os.WriteFile(file, []byte("data"), 0600))
+	_, _, err := listen("", ListenOptions{UnixSocket: file})
+	assert.Error(t, err)
+}
+
+func TestTLSWithHTTP2(t *testing.T) {
+	certFile, keyFile := writeCert(t, t.TempDir(), "localhost")
+	addr := startServer(t, ListenOptions{CertFile: certFile, KeyFile: keyFile, HTTP2: true})
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		ForceAttemptHTTP2: true,
+	}}
+	res, proto := get(t, client, "https://"+addr+"/")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "HTTP/2.0", proto)
+}
+
+func TestH2C(t *testing.T) {
+	addr := startServer(t, ListenOptions{H2C: true})
+
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: &protocols}}
+	res, proto := get(t, client, "http://"+addr+"/")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "HTTP/2.0", proto)
+}
+
+func TestCertReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCert(t, dir, "first")
+	reloader, err := newCertReloader(certFile, keyFile, time.Nanosecond, logging.NewNop())
+	assert.NoError(t, err)
+
+	cert, err := reloader.GetCertificate(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "first", commonName(t, cert))
+
+	writeCert(t, dir, "second")
+	later := time.Now().Add(time.Minute)
+	assert.NoError(t, os.Chtimes(certFile, later, later))
+	assert.NoError(t, os.Chtimes(keyFile, later, later))
+	cert, err = reloader.GetCertificate(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "second", commonName(t, cert))
+
+	// an invalid file keeps the current certificate
+	assert.NoError(t, os.WriteFile(certFile, []byte("invalid"), 0600))
+	later = later.Add(time.Minute)
+	assert.NoError(t, os.Chtimes(certFile, later, later))
+	cert, err = reloader.GetCertificate(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "second", commonName(t, cert))
+}
+
+func TestCertReloadMissingFiles(t *testing.T) {
+	_, err := newCertReloader("missing.pem", "missing.key", 0, logging.NewNop())
+	assert.Error(t, err)
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 	"os/signal"
 	"path"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
@@ -21,6 +23,7 @@ import (
 	"golang.binggl.net/monorepo/pkg/handler"
 	"golang.binggl.net/monorepo/pkg/logging"
 	"golang.binggl.net/monorepo/pkg/security"
+	"golang.binggl.net/monorepo/pkg/tracing"
 )
 
 // PrintServerBanner put some nice emojis on the console
@@ -32,6 +35,8 @@ func PrintServerBanner(name, version, build, env, addr string) {
 	fmt.Printf("%s Ready!\n", "🏁")
 }
 
+const defaultDrainDelay = 2 * time.Second
+
 // RunOptions are used to startup a http.Server
 type RunOptions struct {
 	HostName      string
@@ -42,27 +47,83 @@ type RunOptions struct {
 	Environment   string
 	ServerHandler http.Handler
 	Logger        logging.Logger
+	// Health provides the readiness checks, if nil only the liveness is reported
+	Health *Health
+	// DrainDelay is the time between failing readiness and stopping the server.
+	// If not set, a default delay is used for all environments except Development
+	DrainDelay time.Duration
+	// Listen defines TLS, HTTP/2, the Unix socket and the timeouts of the server
+	Listen ListenOptions
 }
 
 // Run configures and starts the Server
 func Run(opt RunOptions) (err error) {
 	addr := fmt.Sprintf("%s:%d", opt.HostName, opt.Port)
-	httpSrv := &http.Server{Addr: addr, Handler: opt.ServerHandler}
+	health := opt.Health
+	if health == nil {
+		health = NewHealth()
+	}
+	httpSrv, err := newHTTPServer(addr, withHealth(health, opt.ServerHandler), opt.Listen, opt.Logger)
+	if err != nil {
+		return err
+	}
+	l, listenAddr, err := listen(addr, opt.Listen)
+	if err != nil {
+		return err
+	}
+	if opt.Listen.useTLS() {
+		listenAddr = "https://" + listenAddr
+	}
 	go func() {
-		PrintServerBanner(opt.AppName, opt.Version, opt.Build, opt.Environment, httpSrv.Addr)
-		if err := httpSrv.ListenAndServe(); err != http.ErrServerClosed {
-			return
+		PrintServerBanner(opt.AppName, opt.Version, opt.Build, opt.Environment, listenAddr)
+		if err := serve(httpSrv, l); !errors.Is(err, http.ErrServerClosed) {
+			opt.Logger.Error("the server stopped unexpectedly", logging.ErrV(err))
 		}
 	}()
-	return Graceful(httpSrv, 5*time.Second, opt.Logger)
+	drainDelay := opt.DrainDelay
+	if drainDelay == 0 && opt.Environment != string(config.Development) {
+		drainDelay = defaultDrainDelay
+	}
+	return Graceful(httpSrv, 5*time.Second, opt.Logger, func() {
+		// fail readiness first, so that the proxy stops sending traffic
+		health.Shutdown()
+		if drainDelay > 0 {
+			opt.Logger.Info(fmt.Sprintf("Drain requests for: %s", drainDelay))
+			time.Sleep(drainDelay)
+		}
+	})
+}
+
+// withHealth serves the health endpoints in front of the application handler,
+// so probes are not affected by the middleware of the application
+func withHealth(health *Health, next http.Handler) http.Handler {
+	liveness, readiness := health.Liveness(), health.Readiness()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			switch r.URL.Path {
+			case LivenessPath:
+				liveness(w, r)
+				return
+			case ReadinessPath:
+				readiness(w, r)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
 }
 
 // Graceful is used to shutdown a server in a graceful manner
-func Graceful(s *http.Server, timeout time.Duration, logger logging.Logger) error {
+// the supplied hooks are executed before the server is stopped
+func Graceful(s *http.Server, timeout time.Duration, logger logging.Logger, beforeShutdown ...func()) error {
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 	<-stop
 
+	for _, hook := range beforeShutdown {
+		hook()
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 	logger.Info(fmt.Sprintf("Shutdown with timeout: %s", timeout))
@@ -73,11 +134,16 @@ func Graceful(s *http.Server, timeout time.Duration, logger logging.Logger) erro
 	return nil
 }
 
-// ReadConfig parses supplied application parameters and reads the application config file
-func ReadConfig[T any](envPrefix string) (hostname string, port int, basePath string, conf T) {
+// ReadConfig parses supplied application parameters and reads the application config file.
+// Values can be provided by files using environment variables with the suffix "_FILE", e.g.
+// PREFIX_SECURITY__JWTSECRET_FILE=/run/secrets/jwt. The configuration is validated and an error
+// lists all problems found. If started with --check-config the effective configuration is
+// printed and the application exits.
+func ReadConfig[T any](envPrefix string) (hostname string, port int, basePath string, conf T, err error) {
 	flag.String("hostname", "localhost", "the server hostname")
 	flag.Int("port", 3000, "network port to listen")
 	flag.String("basepath", "./", "the base path of the application")
+	flag.Bool("check-config", false, "validate and print the effective configuration, secrets are masked")
 
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
 	pflag.Parse()
@@ -102,21 +168,63 @@ func ReadConfig[T any](envPrefix string) (hostname string, port int, basePath st
 	if err := v.ReadInConfig(); err != nil {
 		panic(fmt.Sprintf("Could not get server configuration values: %v", err))
 	}
+	fileErr := readValuesFromFiles(v, envPrefix, os.Environ())
+
 	var c T
 	if err := v.Unmarshal(&c); err != nil {
 		panic(fmt.Sprintf("Could not unmarshal server configuration values: %v", err))
 	}
 	conf = c
+	err = errors.Join(fileErr, config.Validate(conf))
+
+	if v.GetBool("check-config") {
+		fmt.Printf("%s Configuration file: '%s'\n", "📄", v.ConfigFileUsed())
+		config.Print(os.Stdout, conf)
+		if err != nil {
+			fmt.Printf("%s %v\n", "⛔", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s The configuration is valid\n", "✅")
+		os.Exit(0)
+	}
 	return
 }
 
-// SetupBasicRouter configures typically used middleware components
-func SetupBasicRouter(basePath string, cookieSettings config.ApplicationCookies, corsConfig config.CorsSettings, assets config.AssetSettings, logger logging.Logger) chi.Router {
+// fileSuffix marks environment variables which reference a file holding the value
+const fileSuffix = "_FILE"
+
+// readValuesFromFiles sets the configuration values provided by files. The key is derived from the
+// environment variable, PREFIX_FILESTORE__SECRET_FILE sets the value of filestore__secret.
+func readValuesFromFiles(v *viper.Viper, envPrefix string, environ []string) error {
+	prefix := strings.ToUpper(envPrefix) + "_"
+	var errs []error
+	for _, env := range environ {
+		name, file, found := strings.Cut(env, "=")
+		if !found || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, fileSuffix) || file == "" {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(name, prefix), fileSuffix))
+		content, err := os.ReadFile(file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not read the value of '%s' from file: %w", strings.ReplaceAll(key, "__", "."), err))
+			continue
+		}
+		v.Set(key, strings.TrimRight(string(content), "\r\n"))
+	}
+	return errors.Join(errs...)
+}
+
+// SetupBasicRouter configures typically used middleware components. The CSRF protection
+// does not apply to the csrfExemptPaths, e.g. WebDAV mounts using basic authentication.
+func SetupBasicRouter(basePath string, cookieSettings config.ApplicationCookies, corsConfig config.CorsSettings, headers config.SecurityHeaders, assets config.AssetSettings, logger logging.Logger, csrfExemptPaths ...string) chi.Router {
 	r := chi.NewRouter()
 
 	// A good base middleware stack
 	r.Use(middleware.RequestID)
+	// the rate-limits need the address of the connection, RealIP uses the forwarded headers
+	r.Use(security.PeerAddress)
 	r.Use(middleware.RealIP)
+	r.Use(tracing.Middleware)
 	//r.Use(handler.NewLoggerMiddleware(logger).LoggerContext)
 	r.Use(handler.NewRequestLogger(logger).LoggerContext)
 	// use the default list of "compressible" content-type
@@ -124,6 +232,16 @@ func SetupBasicRouter(basePath string, cookieSettings config.ApplicationCookies,
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Timeout(60 * time.Second))
 
+	// CSP with a nonce per request and additional security headers
+	r.Use(security.NewSecurityHeaders(security.HeaderOptions{
+		ContentSecurityPolicy: headers.ContentSecurityPolicy,
+		FrameAncestors:        headers.FrameAncestors,
+		HSTSMaxAge:            headers.HSTSMaxAge,
+		HSTSIncludeSubdomains: headers.HSTSIncludeSubdomains,
+		ReferrerPolicy:        headers.ReferrerPolicy,
+		PermissionsPolicy:     headers.PermissionsPolicy,
+	}).Handler)
+
 	// setup cors for single frontend
 	cors := cors.New(cors.Options{
 		AllowedOrigins:   corsConfig.Origins,
@@ -134,6 +252,16 @@ func SetupBasicRouter(basePath string, cookieSettings config.ApplicationCookies,
 	})
 	r.Use(cors.Handler)
 
+	// protect the state-changing requests of the htmx UI
+	r.Use(security.NewCSRFMiddleware(security.CSRFOptions{
+		CookieName:     fmt.Sprintf("%s_%s", cookieSettings.Prefix, "csrf"),
+		Domain:         cookieSettings.Domain,
+		Path:           cookieSettings.Path,
+		Secure:         cookieSettings.Secure,
+		TrustedOrigins: corsConfig.Origins,
+		ExemptPaths:    csrfExemptPaths,
+	}, logger).Handler)
+
 	if assets.AssetDir != "" {
 		// serving static content
 		handler.ServeStaticFile(r, "/favicon.ico", filepath.Join(basePath, assets.AssetDir, "favicon.ico"))
@@ -161,3 +289,30 @@ func SetupSecureAPIRouter(errorPath string, jwtOptions config.Security, cookieSe
 
 	return apiRouter
 }
+
+// SetupRateLimit returns a middleware using the named policy of the rate-limit configuration.
+// If no such policy is defined the default policy is used. If rate-limiting is disabled
+// requests are passed on unchanged.
+func SetupRateLimit(cfg config.RateLimitConfig, policy string, logger logging.Logger) func(http.Handler) http.Handler {
+	if !cfg.Enabled {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+	// the keys of maps are lower-case when read by viper
+	p, ok := cfg.Policies[strings.ToLower(policy)]
+	if !ok {
+		p = cfg.Default
+	}
+	// the proxies are checked by the validation of the configuration
+	proxies, err := cfg.Proxies()
+	if err != nil {
+		logger.Error(fmt.Sprintf("the trusted proxies are ignored; %v", err))
+	}
+	return security.NewRateLimiter(security.RateLimitOptions{
+		Name:           policy,
+		Rate:           p.Rate,
+		Burst:          p.Burst,
+		TrustedProxies: proxies,
+	}, logger).Handler
+}
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ReadValuesFromFiles(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(secretFile, []byte("s3cr3t\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	v := viper.NewWithOptions(viper.KeyDelimiter("__"))
+	v.Set("filestore__secret", "value")
+	err := readValuesFromFiles(v, "my", []string{
+		"MY_FILESTORE__SECRET_FILE=" + secretFile,
+		"OTHER_SECURITY__JWTSECRET_FILE=" + secretFile,
+		"MY_FILESTORE__KEY=key",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cr3t", v.GetString("filestore__secret"))
+	assert.False(t, v.IsSet("security__jwtsecret"))
+
+	err = readValuesFromFiles(v, "my", []string{"MY_SECURITY__JWTSECRET_FILE=" + filepath.Join(t.TempDir(), "missing")})
+	assert.ErrorContains(t, err, "security.jwtsecret")
+}
@@ -0,0 +1,80 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"golang.binggl.net/monorepo/pkg/config"
+	"golang.binggl.net/monorepo/pkg/tracing"
+)
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	if _, err := tracing.Setup(tracing.Options{AppName: "test"}); err != nil {
+		t.Fatalf("could not setup tracing: %v", err)
+	}
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	return sr
+}
+
+func Test_Middleware_Propagation(t *testing.T) {
+	sr := setupRecorder(t)
+
+	var childTraceID trace.TraceID
+	r := chi.NewRouter()
+	r.Use(tracing.Middleware)
+	r.Get("/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, span := tracing.Start(r.Context(), "child")
+		childTraceID = span.SpanContext().TraceID()
+		tracing.End(span, nil)
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	spans := sr.Ended()
+	assert.Len(t, spans, 2)
+	server := spans[1]
+	assert.Equal(t, "GET /items/{id}", server.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+	assert.Equal(t, codes.Error, server.Status().Code)
+	assert.Equal(t, server.SpanContext().TraceID(), childTraceID)
+	assert.Equal(t, server.SpanContext().SpanID(), spans[0].Parent().SpanID())
+}
+
+func Test_End_RecordsError(t *testing.T) {
+	sr := setupRecorder(t)
+
+	_, span := tracing.Start(context.Background(), "failing")
+	tracing.End(span, errors.New("error"))
+
+	spans := sr.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Len(t, spans[0].Events(), 1)
+}
+
+func Test_Setup(t *testing.T) {
+	shutdown, err := tracing.Setup(tracing.Options{AppName: "test", Config: config.TraceConfig{Enabled: true, Exporter: "stdout"}})
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+
+	_, err = tracing.Setup(tracing.Options{AppName: "test", Config: config.TraceConfig{Enabled: true, Exporter: "zipkin"}})
+	assert.Error(t, err)
+}
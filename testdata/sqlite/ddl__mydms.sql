@@ -4,15 +4,112 @@ CREATE TABLE "DOCUMENTS" (
 	"filename"	varchar(255) NOT NULL,
 	"alternativeid"	varchar(128),
 	"previewlink"	varchar(128),
-	"amount"	decimal(10 , 0),
+	"amountminor"	integer,
+	"currency"	varchar(3),
 	"created"	date NOT NULL,
 	"modified"	date,
-	"taglist"	text,
-	"senderlist"	text,
 	"invoicenumber"	varchar(128),
+	"needsreview"	integer NOT NULL DEFAULT 0,
+	"contenthash"	varchar(64),
+	"duedate"	date,
+	"reminderdate"	date,
+	"duetype"	varchar(16),
+	"doneat"	date,
+	"remindedat"	date,
 	PRIMARY KEY("id")
 );
 
 CREATE INDEX "IX_DOCUMENTS_PK" ON "DOCUMENTS" (
 	"id"
 );
+
+CREATE INDEX "IX_DOCUMENTS_CONTENTHASH" ON "DOCUMENTS" (
+	"contenthash"
+);
+
+CREATE INDEX "IX_DOCUMENTS_DUEDATE" ON "DOCUMENTS" (
+	"duedate"
+);
+
+CREATE TABLE "TAGS" (
+	"id"	varchar(36) NOT NULL,
+	"name"	varchar(255) NOT NULL COLLATE NOCASE,
+	PRIMARY KEY("id")
+);
+
+CREATE UNIQUE INDEX "IX_TAGS_NAME" ON "TAGS" (
+	"name"
+);
+
+CREATE TABLE "DOCUMENT_TAGS" (
+	"documentid"	varchar(36) NOT NULL,
+	"tagid"	varchar(36) NOT NULL,
+	"position"	integer NOT NULL DEFAULT 0,
+	PRIMARY KEY("documentid","tagid")
+);
+
+CREATE INDEX "IX_DOCUMENT_TAGS_TAG" ON "DOCUMENT_TAGS" (
+	"tagid"
+);
+
+CREATE TABLE "SENDERS" (
+	"id"	varchar(36) NOT NULL,
+	"name"	varchar(255) NOT NULL COLLATE NOCASE,
+	PRIMARY KEY("id")
+);
+
+CREATE UNIQUE INDEX "IX_SENDERS_NAME" ON "SENDERS" (
+	"name"
+);
+
+CREATE TABLE "DOCUMENT_SENDERS" (
+	"documentid"	varchar(36) NOT NULL,
+	"senderid"	varchar(36) NOT NULL,
+	"position"	integer NOT NULL DEFAULT 0,
+	PRIMARY KEY("documentid","senderid")
+);
+
+CREATE INDEX "IX_DOCUMENT_SENDERS_SENDER" ON "DOCUMENT_SENDERS" (
+	"senderid"
+);
+
+CREATE TABLE "DOCUMENT_REVISIONS" (
+	"id"	varchar(36) NOT NULL,
+	"documentid"	varchar(36) NOT NULL,
+	"revision"	integer NOT NULL,
+	"filename"	varchar(255) NOT NULL,
+	"contenthash"	varchar(64),
+	"operation"	varchar(255) NOT NULL,
+	"created"	date NOT NULL,
+	PRIMARY KEY("id")
+);
+
+CREATE UNIQUE INDEX "IX_DOCUMENT_REVISIONS_DOCUMENT" ON "DOCUMENT_REVISIONS" (
+	"documentid",
+	"revision"
+);
+
+CREATE TABLE "RULES" (
+	"id"	varchar(36) NOT NULL,
+	"name"	varchar(255) NOT NULL,
+	"position"	integer NOT NULL DEFAULT 0,
+	"enabled"	integer NOT NULL DEFAULT 1,
+	"conditions"	text NOT NULL,
+	"taglist"	text NOT NULL DEFAULT '',
+	"senderlist"	text NOT NULL DEFAULT '',
+	"titletemplate"	varchar(255) NOT NULL DEFAULT '',
+	"amountminor"	integer,
+	"currency"	varchar(3),
+	"created"	date NOT NULL,
+	"modified"	date,
+	PRIMARY KEY("id")
+);
+
+CREATE TABLE "REMINDER_SUBSCRIBERS" (
+	"username"	varchar(128) NOT NULL,
+	"email"	varchar(255) NOT NULL DEFAULT '',
+	"feedtoken"	varchar(64) NOT NULL UNIQUE,
+	"notify"	integer NOT NULL DEFAULT 0,
+	"created"	date NOT NULL,
+	PRIMARY KEY("username")
+);